@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/bootstrap"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the database",
+}
+
+var migrateDbCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Show, apply or roll back the database migrations, only shows the pending ones by default",
+	Long: `Show, apply or roll back the database migrations.
+Without flags or with --plan the changes of the pending migrations are shown, --apply applies them.
+--rollback rolls back the latest --steps migrations, with --plan it only shows what would be dropped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plan, _ := cmd.Flags().GetBool("plan")
+		apply, _ := cmd.Flags().GetBool("apply")
+		rollback, _ := cmd.Flags().GetBool("rollback")
+		steps, _ := cmd.Flags().GetInt("steps")
+		if apply && (rollback || plan) {
+			return fmt.Errorf("--apply can't be used with --plan or --rollback")
+		}
+		if steps < 1 {
+			return fmt.Errorf("steps must be positive")
+		}
+		bootstrap.InitConfig()
+		bootstrap.Log()
+		bootstrap.InitDBWithoutMigration()
+		defer db.Close()
+		switch {
+		case rollback && plan:
+			plans, err := db.PlanRollback(steps)
+			if err != nil {
+				return fmt.Errorf("failed to plan rollback: %+v", err)
+			}
+			if len(plans) == 0 {
+				fmt.Println("no migration has been applied")
+				return nil
+			}
+			printPlans(plans)
+			return nil
+		case apply:
+			err := db.ApplyMigrations(func(m *db.Migration) {
+				fmt.Printf("applied %s\n", m)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migrations: %+v", err)
+			}
+			return nil
+		case rollback:
+			err := db.RollbackMigrations(steps, func(m *db.Migration) {
+				fmt.Printf("rolled back %s\n", m)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to roll back migrations: %+v", err)
+			}
+			return nil
+		}
+		plans, err := db.PlanMigrations()
+		if err != nil {
+			return fmt.Errorf("failed to plan migrations: %+v", err)
+		}
+		if len(plans) == 0 {
+			fmt.Println("the database is up to date")
+			return nil
+		}
+		printPlans(plans)
+		return nil
+	},
+}
+
+func printPlans(plans []db.MigrationPlan) {
+	for _, plan := range plans {
+		fmt.Println(plan.Migration)
+		if len(plan.Changes) == 0 {
+			fmt.Println("  no changes")
+		}
+		for _, change := range plan.Changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(migrateDbCmd)
+	migrateDbCmd.Flags().Bool("plan", false, "Show the changes without applying them, the default")
+	migrateDbCmd.Flags().Bool("apply", false, "Apply the pending migrations")
+	migrateDbCmd.Flags().Bool("rollback", false, "Roll back the latest applied migrations")
+	migrateDbCmd.Flags().Int("steps", 1, "The number of migrations to roll back")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/relay"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// relayCmd represents the relay command
+var relayCmd = &cobra.Command{
+	Use:   "relay",
+	Short: "Start an edge node streaming the downloads by the fetch instructions of the main instance",
+	Long: `Start an edge node streaming the downloads by the fetch instructions of the main instance.
+The edge node authenticates to the main instance with the relay_token setting of it, and its url
+is put in the down proxy urls of the storages, so that it never holds the credentials of the storages.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		main, _ := cmd.Flags().GetString("main")
+		token, _ := cmd.Flags().GetString("token")
+		listen, _ := cmd.Flags().GetString("listen")
+		if token == "" {
+			token = os.Getenv("OPENLIST_RELAY_TOKEN")
+		}
+		if main == "" || token == "" {
+			return fmt.Errorf("--main and --token are required")
+		}
+		srv := &http.Server{Addr: listen, Handler: relay.NewEdge(main, token)}
+		go func() {
+			utils.Log.Infof("start relay @ %s for %s", listen, main)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				utils.Log.Fatalf("failed to start relay: %s", err.Error())
+			}
+		}()
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(relayCmd)
+	relayCmd.Flags().String("main", "", "The url of the main instance")
+	relayCmd.Flags().String("token", "", "The relay token of the main instance, defaults to $OPENLIST_RELAY_TOKEN")
+	relayCmd.Flags().String("listen", ":5246", "The address to listen on")
+}
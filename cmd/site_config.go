@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/spf13/cobra"
+)
+
+// siteConfigCmd represents the site-config command
+var siteConfigCmd = &cobra.Command{
+	Use:   "site-config",
+	Short: "Export or import the metas, hide rules and shares as a yaml document",
+}
+
+var exportSiteConfigCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export the site config to a file, or to stdout without a file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Init()
+		defer Release()
+		data, err := op.ExportSiteConfig()
+		if err != nil {
+			return fmt.Errorf("failed to export site config: %+v", err)
+		}
+		if len(args) < 1 {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(args[0], data, 0644)
+	},
+}
+
+var importSiteConfigCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import the site config from a file, the server should be restarted afterwards to drop its caches",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("file is required")
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		prune, _ := cmd.Flags().GetBool("prune")
+		Init()
+		defer Release()
+		res, err := op.ImportSiteConfig(data, prune)
+		if err != nil {
+			return fmt.Errorf("failed to import site config: %+v", err)
+		}
+		fmt.Printf("metas: %d created, %d updated, %d deleted\n", res.MetasCreated, res.MetasUpdated, res.MetasDeleted)
+		fmt.Printf("shares: %d created, %d updated, %d deleted\n", res.SharesCreated, res.SharesUpdated, res.SharesDeleted)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(siteConfigCmd)
+	siteConfigCmd.AddCommand(exportSiteConfigCmd)
+	siteConfigCmd.AddCommand(importSiteConfigCmd)
+	importSiteConfigCmd.Flags().Bool("prune", false, "Delete the metas and shares missing from the file")
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/bootstrap"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/charmbracelet/bubbles/table"
@@ -181,6 +182,31 @@ var listStorageCmd = &cobra.Command{
 	},
 }
 
+var encryptStorageCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the additions of the existing storages with the secret key",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bootstrap.Init()
+		defer bootstrap.Release()
+		if conf.Conf.SecretKey == "" && conf.Conf.SecretKeyFile == "" {
+			return fmt.Errorf("secret_key or secret_key_file is required")
+		}
+		storages, _, err := db.GetStorages(1, -1)
+		if err != nil {
+			return fmt.Errorf("failed to query storages: %+v", err)
+		}
+		// the additions are decrypted when loaded and encrypted again when saved
+		for i := range storages {
+			if err = db.UpdateStorage(&storages[i]); err != nil {
+				return fmt.Errorf("failed to update storage [%s]: %+v", storages[i].MountPath, err)
+			}
+		}
+		utils.Log.Infof("The additions of %d storages have been encrypted from CLI", len(storages))
+		fmt.Printf("The additions of %d storages have been encrypted\n", len(storages))
+		return nil
+	},
+}
+
 func init() {
 
 	RootCmd.AddCommand(storageCmd)
@@ -188,6 +214,7 @@ func init() {
 	storageCmd.AddCommand(listStorageCmd)
 	storageCmd.PersistentFlags().IntVarP(&storageTableHeight, "height", "H", 10, "Table height")
 	storageCmd.AddCommand(deleteStorageCmd)
+	storageCmd.AddCommand(encryptStorageCmd)
 	deleteStorageCmd.Flags().BoolP("force", "f", false, "Force delete without confirmation")
 	// Here you will define your flags and configuration settings.
 
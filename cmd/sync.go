@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/sync_client"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync <local> <remote>",
+	Short: "Sync a local directory with a directory of an OpenList server",
+	Long: `Sync a local directory with a directory of an OpenList server by its sync api.
+The push mode makes the remote directory like the local one, the pull mode does the opposite,
+and the both mode syncs the changes of both sides since the last sync, which are known by the state
+kept in the .openlist-sync.json of the local directory. The empty directories are not synced.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, _ := cmd.Flags().GetString("server")
+		token, _ := cmd.Flags().GetString("token")
+		username, _ := cmd.Flags().GetString("username")
+		password, _ := cmd.Flags().GetString("password")
+		bwlimit, _ := cmd.Flags().GetInt("bwlimit")
+		opts := &sync_client.Options{Local: args[0], Remote: args[1]}
+		opts.Mode, _ = cmd.Flags().GetString("mode")
+		opts.Conflict, _ = cmd.Flags().GetString("conflict")
+		opts.Delete, _ = cmd.Flags().GetBool("delete")
+		opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		switch opts.Mode {
+		case sync_client.ModePush, sync_client.ModePull, sync_client.ModeBoth:
+		default:
+			return fmt.Errorf("unknown mode %s, use push, pull or both", opts.Mode)
+		}
+		switch opts.Conflict {
+		case sync_client.ConflictKeepBoth, sync_client.ConflictLocal, sync_client.ConflictRemote, sync_client.ConflictNewer:
+		default:
+			return fmt.Errorf("unknown conflict policy %s, use keep-both, local, remote or newer", opts.Conflict)
+		}
+		if token == "" {
+			token = os.Getenv("OPENLIST_TOKEN")
+		}
+		if password == "" {
+			password = os.Getenv("OPENLIST_PASSWORD")
+		}
+		if server == "" || (token == "" && username == "") {
+			return fmt.Errorf("--server and --token or --username are required")
+		}
+		if info, err := os.Stat(opts.Local); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", opts.Local)
+		}
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		c := &sync_client.Client{Server: server, Token: token}
+		if bwlimit > 0 {
+			c.Limiter = stream.BlockBurstLimiter{Limiter: rate.NewLimiter(rate.Limit(bwlimit)*1024, bwlimit*1024)}
+		}
+		if token == "" {
+			if err := c.Login(ctx, username, password); err != nil {
+				return err
+			}
+		}
+		if err := c.Init(ctx); err != nil {
+			return err
+		}
+		opts.Log = utils.Log.Infof
+		stats, err := sync_client.Run(ctx, c, opts)
+		if stats != nil {
+			utils.Log.Infof("uploaded %d, downloaded %d, deleted %d local and %d remote, %d conflicts, %d failed",
+				stats.Uploaded, stats.Downloaded, stats.DeletedLocal, stats.DeletedRemote, stats.Conflicts, stats.Failed)
+			if err == nil && stats.Failed > 0 {
+				err = fmt.Errorf("%d files failed to sync", stats.Failed)
+			}
+		}
+		return err
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().String("server", "", "The url of the OpenList server")
+	syncCmd.Flags().String("token", "", "The token to authenticate with, defaults to $OPENLIST_TOKEN")
+	syncCmd.Flags().String("username", "", "The username to login with if there is no token")
+	syncCmd.Flags().String("password", "", "The password to login with, defaults to $OPENLIST_PASSWORD")
+	syncCmd.Flags().String("mode", sync_client.ModePush, "The direction of the sync: push, pull or both")
+	syncCmd.Flags().String("conflict", sync_client.ConflictKeepBoth, "How the files changed on both sides are resolved: keep-both, local, remote or newer")
+	syncCmd.Flags().Bool("delete", false, "Delete the files missing from the source of the push or pull")
+	syncCmd.Flags().Bool("dry-run", false, "Print the actions without doing them")
+	syncCmd.Flags().Int("bwlimit", 0, "The bandwidth limit of the uploads and downloads in KB/s, 0 for no limit")
+}
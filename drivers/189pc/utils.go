@@ -854,7 +854,7 @@ func (y *Cloud189PC) FastUpload(ctx context.Context, dstDir model.Obj, file mode
 	)
 	size := file.GetSize()
 	if _, ok := cache.(io.ReaderAt); !ok && size > 0 {
-		tmpF, err = os.CreateTemp(conf.Conf.TempDir, "file-*")
+		tmpF, err = utils.CreateTemp("file-*", size)
 		if err != nil {
 			return nil, err
 		}
@@ -15,7 +15,6 @@ import (
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/drivers/base"
-	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -237,7 +236,7 @@ func (d *AliDrive) Put(ctx context.Context, dstDir model.Obj, streamer model.Fil
 				return err
 			}
 		} else {
-			tempFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+			tempFile, err := utils.CreateTemp("file-*", streamer.GetSize())
 			if err != nil {
 				return err
 			}
@@ -17,7 +17,6 @@ import (
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/drivers/base"
-	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -205,7 +204,7 @@ func (d *BaiduNetdisk) Put(ctx context.Context, dstDir model.Obj, stream model.F
 		err   error
 	)
 	if cache == nil {
-		tmpF, err = os.CreateTemp(conf.Conf.TempDir, "file-*")
+		tmpF, err = utils.CreateTemp("file-*", stream.GetSize())
 		if err != nil {
 			return nil, err
 		}
@@ -498,3 +497,4 @@ func (d *BaiduNetdisk) GetDetails(ctx context.Context) (*model.StorageDetails, e
 }
 
 var _ driver.Driver = (*BaiduNetdisk)(nil)
+var _ driver.PutRapid = (*BaiduNetdisk)(nil)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 )
 
 var (
@@ -66,6 +67,11 @@ func fileToObj(f File) *model.ObjThumb {
 	if f.ServerMtime == 0 {
 		f.ServerMtime = f.Mtime
 	}
+	// the md5 of the api is obfuscated, DecryptMd5 restores the md5 of the content, e.g. for the rapid copy to another account
+	var hashInfo utils.HashInfo
+	if f.Isdir != 1 && len(f.Md5) == utils.MD5.Width {
+		hashInfo = utils.NewHashInfo(utils.MD5, DecryptMd5(f.Md5))
+	}
 	return &model.ObjThumb{
 		Object: model.Object{
 			ID:       strconv.FormatInt(f.FsId, 10),
@@ -75,7 +81,7 @@ func fileToObj(f File) *model.ObjThumb {
 			Modified: time.Unix(f.ServerMtime, 0),
 			Ctime:    time.Unix(f.ServerCtime, 0),
 			IsFolder: f.Isdir == 1,
-			// 百度API返回的MD5不可信，不使用HashInfo
+			HashInfo: hashInfo,
 		},
 		Thumbnail: model.Thumbnail{Thumbnail: f.Thumbs.Url3},
 	}
@@ -0,0 +1,19 @@
+package baidu_netdisk
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+func TestFileToObjHash(t *testing.T) {
+	const md5 = "0cc175b9c0f1b6a831c399e269772661"
+	obj := fileToObj(File{FsId: 1, Path: "/a.txt", Size: 1, Md5: EncryptMd5(md5)})
+	if got := obj.GetHash().GetHash(utils.MD5); got != md5 {
+		t.Errorf("md5 of the file = %q, want %q", got, md5)
+	}
+	dir := fileToObj(File{FsId: 2, Path: "/d", Isdir: 1})
+	if got := dir.GetHash().GetHash(utils.MD5); got != "" {
+		t.Errorf("md5 of the dir = %q, want none", got)
+	}
+}
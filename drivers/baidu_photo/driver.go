@@ -14,7 +14,6 @@ import (
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/drivers/base"
-	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -246,7 +245,7 @@ func (d *BaiduPhoto) Put(ctx context.Context, dstDir model.Obj, stream model.Fil
 		err   error
 	)
 	if _, ok := cache.(io.ReaderAt); !ok {
-		tmpF, err = os.CreateTemp(conf.Conf.TempDir, "file-*")
+		tmpF, err = utils.CreateTemp("file-*", stream.GetSize())
 		if err != nil {
 			return nil, err
 		}
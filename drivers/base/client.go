@@ -1,11 +1,9 @@
 package base
 
 import (
-	"crypto/tls"
 	"net/http"
 	"time"
 
-	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/net"
 	"github.com/go-resty/resty/v2"
 )
@@ -26,12 +24,13 @@ func InitClient() {
 		resty.RedirectPolicyFunc(func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		}),
-	).SetTLSClientConfig(&tls.Config{InsecureSkipVerify: conf.Conf.TlsInsecureSkipVerify})
+	).SetTransport(net.DriverTransport())
 	NoRedirectClient.SetHeader("user-agent", UserAgent)
-	net.SetRestyProxyIfConfigured(NoRedirectClient)
+	NoRedirectClient.OnBeforeRequest(setRequestID)
 
 	RestyClient = NewRestyClient()
 	HttpClient = net.NewHttpClient()
+	HttpClient.Transport = net.DriverTransport()
 }
 
 func NewRestyClient() *resty.Client {
@@ -40,8 +39,15 @@ func NewRestyClient() *resty.Client {
 		SetRetryCount(3).
 		SetRetryResetReaders(true).
 		SetTimeout(DefaultTimeout).
-		SetTLSClientConfig(&tls.Config{InsecureSkipVerify: conf.Conf.TlsInsecureSkipVerify})
-
-	net.SetRestyProxyIfConfigured(client)
+		SetTransport(net.DriverTransport()).
+		OnBeforeRequest(setRequestID)
 	return client
 }
+
+// setRequestID sends the id of the api request which the request of the driver is made for
+func setRequestID(_ *resty.Client, req *resty.Request) error {
+	if id := net.RequestID(req.Context()); id != "" && req.Header.Get(net.RequestIDHeader) == "" {
+		req.SetHeader(net.RequestIDHeader, id)
+	}
+	return nil
+}
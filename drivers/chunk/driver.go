@@ -85,10 +85,15 @@ func (d *Chunk) Get(ctx context.Context, path string) (model.Obj, error) {
 	chunkSizes := []int64{-1}
 	h := make(map[*utils.HashType]string)
 	var first model.Obj
+	var manifest *chunkManifest
 	for _, o := range chunkObjs {
 		if o.IsDir() {
 			continue
 		}
+		if m, ok := parseManifest(o.GetName(), d.CustomExt); ok {
+			manifest = m
+			continue
+		}
 		if after, ok := strings.CutPrefix(o.GetName(), "hash_"); ok {
 			hn, value, ok := strings.Cut(strings.TrimSuffix(after, d.CustomExt), "_")
 			if ok {
@@ -128,6 +133,7 @@ func (d *Chunk) Get(ctx context.Context, path string) (model.Obj, error) {
 			Ctime:    first.CreateTime(),
 		},
 		chunkSizes: chunkSizes,
+		manifest:   manifest,
 	}
 	if len(h) > 0 {
 		objRes.HashInfo = utils.NewHashInfoByMap(h)
@@ -273,6 +279,9 @@ func (d *Chunk) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (
 	if chunkFile.chunkSizes[0] == -1 {
 		return nil, fmt.Errorf("chunk part[%d] are missing", 0)
 	}
+	if err := chunkFile.check(); err != nil {
+		return nil, err
+	}
 	for i, l := 1, len(chunkFile.chunkSizes)-1; i < l; i++ {
 		if chunkFile.chunkSizes[i] == 0 {
 			return nil, fmt.Errorf("chunk part[%d] are missing", i)
@@ -460,7 +469,7 @@ func (d *Chunk) Put(ctx context.Context, dstDir model.Obj, file model.FileStream
 		}
 		partIndex++
 	}
-	err = op.Put(ctx, remoteStorage, dst, &stream.FileStream{
+	err = op.Put(skipHookCtx, remoteStorage, dst, &stream.FileStream{
 		Obj: &model.Object{
 			Name:     d.getPartName(fullPartCount),
 			Size:     tailSize,
@@ -469,6 +478,20 @@ func (d *Chunk) Put(ctx context.Context, dstDir model.Obj, file model.FileStream
 		Mimetype: file.GetMimetype(),
 		Reader:   upReader,
 	}, nil)
+	if err != nil {
+		_ = op.Remove(ctx, remoteStorage, dst)
+		return err
+	}
+	manifest := chunkManifest{Size: file.GetSize(), Parts: fullPartCount + 1}
+	err = op.Put(ctx, remoteStorage, dst, &stream.FileStream{
+		Obj: &model.Object{
+			Name:     manifest.name(d.CustomExt),
+			Size:     1,
+			Modified: file.ModTime(),
+		},
+		Mimetype: "application/octet-stream",
+		Reader:   bytes.NewReader([]byte{0}), // 兼容不支持空文件的驱动
+	}, nil)
 	if err != nil {
 		_ = op.Remove(ctx, remoteStorage, dst)
 	}
@@ -1,8 +1,64 @@
 package chunk
 
-import "github.com/OpenListTeam/OpenList/v4/internal/model"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
 
 type chunkObject struct {
 	model.Object
 	chunkSizes []int64
+	// manifest is nil until all of the parts are uploaded
+	manifest *chunkManifest
+}
+
+// chunkManifest is written into the chunk folder after all of the parts are uploaded,
+// so the interrupted uploads and the lost parts are told from the complete files.
+// It's encoded in the file name like the hashes, so it's known from the listing without downloading
+type chunkManifest struct {
+	Size  int64
+	Parts int
+}
+
+const manifestPrefix = "manifest_"
+
+func (m chunkManifest) name(ext string) string {
+	return fmt.Sprintf("%s%d_%d%s", manifestPrefix, m.Size, m.Parts, ext)
+}
+
+func parseManifest(name, ext string) (*chunkManifest, bool) {
+	after, ok := strings.CutPrefix(strings.TrimSuffix(name, ext), manifestPrefix)
+	if !ok {
+		return nil, false
+	}
+	size, parts, ok := strings.Cut(after, "_")
+	if !ok {
+		return nil, false
+	}
+	m := &chunkManifest{}
+	var err error
+	if m.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+		return nil, false
+	}
+	if m.Parts, err = strconv.Atoi(parts); err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+// check reports the parts which don't match the manifest
+func (o *chunkObject) check() error {
+	if o.manifest == nil {
+		return fmt.Errorf("chunk file is incomplete: the manifest is missing")
+	}
+	if len(o.chunkSizes) != o.manifest.Parts {
+		return fmt.Errorf("chunk file is incomplete: %d of %d parts", len(o.chunkSizes), o.manifest.Parts)
+	}
+	if o.GetSize() != o.manifest.Size {
+		return fmt.Errorf("chunk file is incomplete: %d of %d bytes", o.GetSize(), o.manifest.Size)
+	}
+	return nil
 }
@@ -0,0 +1,475 @@
+package crypt2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdpath "path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/rclone/rclone/fs/config/obscure"
+)
+
+type Crypt2 struct {
+	model.Storage
+	Addition
+	cipherId byte
+	scopes   []*keyScope
+}
+
+const obfuscatedPrefix = "___Obfuscated___"
+
+func (d *Crypt2) Config() driver.Config {
+	return config
+}
+
+func (d *Crypt2) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Crypt2) Init(ctx context.Context) error {
+	// obfuscate credentials if it's updated or just created
+	if err := d.updateObfusParm(&d.Password); err != nil {
+		return fmt.Errorf("failed to obfuscate password: %w", err)
+	}
+	if err := d.updateObfusParm(&d.Salt); err != nil {
+		return fmt.Errorf("failed to obfuscate salt: %w", err)
+	}
+	isCryptExt := regexp.MustCompile(`^[.][A-Za-z0-9-_]{2,}$`).MatchString
+	if !isCryptExt(d.EncryptedSuffix) {
+		return fmt.Errorf("EncryptedSuffix is Illegal")
+	}
+	id, ok := cipherIds[d.Cipher]
+	if !ok {
+		return fmt.Errorf("unknown cipher: %s", d.Cipher)
+	}
+	d.cipherId = id
+	d.FileNameEncoding = utils.GetNoneEmpty(d.FileNameEncoding, "base64")
+	d.RemotePath = utils.FixAndCleanPath(d.RemotePath)
+
+	p, _ := strings.CutPrefix(d.Password, obfuscatedPrefix)
+	password, err := obscure.Reveal(p)
+	if err != nil {
+		return fmt.Errorf("failed to reveal password: %w", err)
+	}
+	s, _ := strings.CutPrefix(d.Salt, obfuscatedPrefix)
+	salt, err := obscure.Reveal(s)
+	if err != nil {
+		return fmt.Errorf("failed to reveal salt: %w", err)
+	}
+	if err := d.initScopes(password, salt); err != nil {
+		return fmt.Errorf("failed to derive keys: %w", err)
+	}
+	return nil
+}
+
+func (d *Crypt2) updateObfusParm(str *string) error {
+	if !strings.HasPrefix(*str, obfuscatedPrefix) {
+		temp, err := obscure.Obscure(*str)
+		if err != nil {
+			return err
+		}
+		*str = obfuscatedPrefix + temp
+	}
+	return nil
+}
+
+func (d *Crypt2) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (a Addition) GetRootPath() string {
+	return a.RemotePath
+}
+
+func (d *Crypt2) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	remoteFullPath := dir.GetPath()
+	plainDir, err := d.plainDirPath(remoteFullPath)
+	if err != nil {
+		return nil, err
+	}
+	names := d.scopeOf(plainDir).names
+	objs, err := fs.List(ctx, remoteFullPath, &fs.ListArgs{NoLog: true, Refresh: args.Refresh})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		size := obj.GetSize()
+		mask := model.GetObjMask(obj)
+		name := obj.GetName()
+		if mask&model.Virtual == 0 {
+			if obj.IsDir() {
+				name, err = names.DecryptDirName(model.UnwrapObjName(obj).GetName())
+			} else {
+				size, err = decryptedSize(size)
+				if err == nil {
+					name, err = names.DecryptFileName(model.UnwrapObjName(obj).GetName())
+				}
+			}
+			if err != nil {
+				// filter illegal files
+				continue
+			}
+		}
+		if !d.ShowHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		result = append(result, &model.Object{
+			Path:     stdpath.Join(remoteFullPath, obj.GetName()),
+			Name:     name,
+			Size:     size,
+			Modified: obj.ModTime(),
+			IsFolder: obj.IsDir(),
+			Ctime:    obj.CreateTime(),
+			Mask:     mask &^ model.Temp,
+			// discarding hash as it's encrypted
+		})
+	}
+	return result, nil
+}
+
+func (d *Crypt2) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{Path: d.RemotePath, Name: "root", IsFolder: true}, nil
+	}
+	var remoteObj model.Obj
+	var err error
+	var remoteFullPath string
+	// try the file then the folder, their names are encrypted differently
+	for _, isFolder := range []bool{false, true} {
+		remoteFullPath = stdpath.Join(d.RemotePath, d.encryptPath(path, isFolder))
+		remoteObj, err = fs.Get(ctx, remoteFullPath, &fs.GetArgs{NoLog: true})
+		if err == nil && remoteObj.IsDir() == isFolder {
+			break
+		}
+		if err == nil {
+			err = errs.ObjectNotFound
+		}
+		if !errs.IsObjectNotFound(err) {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	size := remoteObj.GetSize()
+	if !remoteObj.IsDir() {
+		if size, err = decryptedSize(size); err != nil {
+			return nil, err
+		}
+	}
+	return &model.Object{
+		Path:     remoteFullPath,
+		Name:     stdpath.Base(path),
+		Size:     size,
+		Modified: remoteObj.ModTime(),
+		IsFolder: remoteObj.IsDir(),
+		Ctime:    remoteObj.CreateTime(),
+		Mask:     model.GetObjMask(remoteObj) &^ model.Temp,
+	}, nil
+}
+
+func (d *Crypt2) Link(ctx context.Context, file model.Obj, _ model.LinkArgs) (*model.Link, error) {
+	plainDir, err := d.plainDirPath(stdpath.Dir(file.GetPath()))
+	if err != nil {
+		return nil, err
+	}
+	key := d.scopeOf(plainDir).key
+	remoteStorage, remoteActualPath, err := op.GetStorageAndActualPath(file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	remoteLink, remoteFile, err := op.Link(ctx, remoteStorage, remoteActualPath, model.LinkArgs{})
+	if err != nil {
+		return nil, err
+	}
+	remoteSize := remoteLink.ContentLength
+	if remoteSize <= 0 {
+		remoteSize = remoteFile.GetSize()
+	}
+	size, err := decryptedSize(remoteSize)
+	if err != nil {
+		_ = remoteLink.Close()
+		return nil, err
+	}
+	rrf, err := stream.GetRangeReaderFromLink(remoteSize, remoteLink)
+	if err != nil {
+		_ = remoteLink.Close()
+		return nil, fmt.Errorf("the remote storage driver need to be enhanced to support encrytion")
+	}
+
+	var (
+		mu     sync.Mutex
+		header *fileHeader
+	)
+	getHeader := func(ctx context.Context) (*fileHeader, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if header != nil {
+			return header, nil
+		}
+		rc, err := rrf.RangeRead(ctx, http_range.Range{Start: 0, Length: int64(headerSize)})
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		b := make([]byte, headerSize)
+		if _, err := io.ReadFull(rc, b); err != nil {
+			return nil, fmt.Errorf("failed to read the header: %w", err)
+		}
+		h, err := parseFileHeader(b)
+		if err != nil {
+			return nil, err
+		}
+		header = &h
+		return header, nil
+	}
+	lastChunk := chunkCount(size) - 1
+	return &model.Link{
+		RangeReader: stream.RangeReaderFunc(func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+			start, end := httpRange.Start, size
+			if httpRange.Length >= 0 {
+				end = min(start+httpRange.Length, size)
+			}
+			if start >= end {
+				return io.NopCloser(bytes.NewReader(nil)), nil
+			}
+			h, err := getHeader(ctx)
+			if err != nil {
+				return nil, err
+			}
+			aead, err := newAEAD(h.cipherId(), key)
+			if err != nil {
+				return nil, err
+			}
+			first, last := start/chunkSize, (end-1)/chunkSize
+			remoteStart := int64(headerSize) + first*sealedSize
+			remoteEnd := min(int64(headerSize)+(last+1)*sealedSize, remoteSize)
+			rc, err := rrf.RangeRead(ctx, http_range.Range{Start: remoteStart, Length: remoteEnd - remoteStart})
+			if err != nil {
+				return nil, err
+			}
+			return &decryptReader{
+				rc:        rc,
+				aead:      aead,
+				header:    *h,
+				index:     uint64(first),
+				last:      uint64(lastChunk),
+				lastSize:  int(remoteSize - int64(headerSize) - lastChunk*sealedSize),
+				skip:      int(start - first*chunkSize),
+				remaining: end - start,
+				buf:       make([]byte, sealedSize),
+			}, nil
+		}),
+		SyncClosers:      utils.NewSyncClosers(remoteLink),
+		RequireReference: remoteLink.RequireReference,
+	}, nil
+}
+
+func (d *Crypt2) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	plainDir, err := d.plainDirPath(parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	remoteStorage, remoteActualPath, err := op.GetStorageAndActualPath(parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	encryptedName := d.scopeOf(plainDir).names.EncryptDirName(dirName)
+	return op.MakeDir(ctx, remoteStorage, stdpath.Join(remoteActualPath, encryptedName))
+}
+
+// sameScope reports whether the ciphertext of srcObj can be moved or copied into dstDir as it is,
+// otherwise the files have to be re-encrypted by a transfer task
+func (d *Crypt2) sameScope(srcObj, dstDir model.Obj) (bool, error) {
+	srcDir, err := d.plainDirPath(stdpath.Dir(srcObj.GetPath()))
+	if err != nil {
+		return false, err
+	}
+	plainDst, err := d.plainDirPath(dstDir.GetPath())
+	if err != nil {
+		return false, err
+	}
+	if srcObj.IsDir() && d.hasScopeUnder(stdpath.Join(srcDir, srcObj.GetName())) {
+		return false, nil
+	}
+	return d.scopeOf(srcDir) == d.scopeOf(plainDst), nil
+}
+
+func (d *Crypt2) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if ok, err := d.sameScope(srcObj, dstDir); err != nil || !ok {
+		return errors.Join(err, errs.NotSupport)
+	}
+	_, err := fs.Move(ctx, srcObj.GetPath(), dstDir.GetPath())
+	return err
+}
+
+func (d *Crypt2) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	plainDir, err := d.plainDirPath(stdpath.Dir(srcObj.GetPath()))
+	if err != nil {
+		return err
+	}
+	if srcObj.IsDir() && d.hasScopeUnder(stdpath.Join(plainDir, srcObj.GetName())) {
+		return fmt.Errorf("the directories with their own passwords can't be renamed")
+	}
+	remoteStorage, remoteActualPath, err := op.GetStorageAndActualPath(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	names := d.scopeOf(plainDir).names
+	var newEncryptedName string
+	if srcObj.IsDir() {
+		newEncryptedName = names.EncryptDirName(newName)
+	} else {
+		newEncryptedName = names.EncryptFileName(newName)
+	}
+	return op.Rename(ctx, remoteStorage, remoteActualPath, newEncryptedName)
+}
+
+func (d *Crypt2) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if ok, err := d.sameScope(srcObj, dstDir); err != nil || !ok {
+		return errors.Join(err, errs.NotSupport)
+	}
+	_, err := fs.Copy(ctx, srcObj.GetPath(), dstDir.GetPath())
+	return err
+}
+
+func (d *Crypt2) Remove(ctx context.Context, obj model.Obj) error {
+	remoteStorage, remoteActualPath, err := op.GetStorageAndActualPath(obj.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Remove(ctx, remoteStorage, remoteActualPath)
+}
+
+func (d *Crypt2) Put(ctx context.Context, dstDir model.Obj, streamer model.FileStreamer, up driver.UpdateProgress) error {
+	plainDir, err := d.plainDirPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	scope := d.scopeOf(plainDir)
+	remoteStorage, remoteActualPath, err := op.GetStorageAndActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	wrappedIn, err := newEncryptReader(streamer, d.cipherId, scope.key, streamer.GetSize())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt data: %w", err)
+	}
+	// doesn't support seekableStream, since rapid-upload is not working for encrypted data
+	streamOut := &stream.FileStream{
+		Obj: &model.Object{
+			ID:       streamer.GetID(),
+			Path:     streamer.GetPath(),
+			Name:     scope.names.EncryptFileName(streamer.GetName()),
+			Size:     encryptedSize(streamer.GetSize()),
+			Modified: streamer.ModTime(),
+			IsFolder: streamer.IsDir(),
+		},
+		Reader:            wrappedIn,
+		Mimetype:          "application/octet-stream",
+		ForceStreamUpload: true,
+		Exist:             streamer.GetExist(),
+	}
+	return op.Put(ctx, remoteStorage, remoteActualPath, streamOut, up)
+}
+
+func (d *Crypt2) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	remoteStorage, _, err := op.GetStorageAndActualPath(d.RemotePath)
+	if err != nil {
+		return nil, errs.NotImplement
+	}
+	remoteDetails, err := op.GetStorageDetails(ctx, remoteStorage)
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		DiskUsage: remoteDetails.DiskUsage,
+	}, nil
+}
+
+type MigrateReq struct {
+	SrcPath  string `json:"src_path"`
+	Password string `json:"password"`
+}
+
+// Other supports the method migrate, which copies a file or directory of a Crypt storage into the directory,
+// the files are decrypted with the old format and encrypted with this one by the copy tasks
+func (d *Crypt2) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	if args.Method != "migrate" {
+		return nil, errs.NotSupport
+	}
+	var req MigrateReq
+	data, err := utils.Json.Marshal(args.Data)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.Json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	if !args.Obj.IsDir() {
+		return nil, errs.NotFolder
+	}
+	srcPath, dstPath := req.SrcPath, utils.GetFullPath(d.MountPath, args.Obj.GetPath())
+	if user, ok := ctx.Value(conf.UserKey).(*model.User); ok {
+		if srcPath, err = user.JoinPath(srcPath); err != nil {
+			return nil, err
+		}
+		if err = checkMigrate(user, req.Password, srcPath, dstPath); err != nil {
+			return nil, err
+		}
+	}
+	srcStorage, _, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	if srcStorage.Config().Name != "Crypt" {
+		return nil, fmt.Errorf("[%s] is not in a Crypt storage", req.SrcPath)
+	}
+	plainDir, err := d.plainDirPath(args.Obj.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	t, err := fs.Copy(ctx, utils.FixAndCleanPath(srcPath), utils.GetFullPath(d.MountPath, plainDir))
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, nil
+	}
+	return map[string]string{"task_id": t.GetID()}, nil
+}
+
+// checkMigrate checks that the user may copy from and write to the paths of a migration
+func checkMigrate(user *model.User, password string, paths ...string) error {
+	if !user.CanCopy() {
+		return errs.PermissionDenied
+	}
+	for _, path := range paths {
+		meta, err := op.GetNearestMeta(path)
+		if err != nil && !errors.Is(err, errs.MetaNotFound) {
+			return err
+		}
+		if !common.CanAccess(user, meta, path, password) || !common.CanWrite(user, meta, path) {
+			return errs.PermissionDenied
+		}
+	}
+	return nil
+}
+
+var _ driver.Driver = (*Crypt2)(nil)
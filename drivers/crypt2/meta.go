@@ -0,0 +1,39 @@
+package crypt2
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	RemotePath string `json:"remote_path" required:"true" help:"This is where the encrypted data stores"`
+	Cipher     string `json:"cipher" type:"select" required:"true" options:"aes-256-gcm,chacha20-poly1305" default:"aes-256-gcm" help:"the cipher of the new files, the existing files are read with the cipher they are written with"`
+
+	Password string `json:"password" required:"true" confidential:"true" help:"the main password"`
+	Salt     string `json:"salt" confidential:"true" help:"If you don't know what is salt, treat it as a second password. Optional but recommended"`
+	// DirKeys gives the directories their own passwords, so that the password of a shared directory doesn't reveal the others
+	DirKeys string `json:"dir_keys" type:"text" confidential:"true" help:"one directory per line in the form of /path:password, the files under the directory are encrypted with its own password"`
+
+	FileNameEnc      string `json:"filename_encryption" type:"select" required:"true" options:"off,standard,obfuscate" default:"standard"`
+	DirNameEnc       string `json:"directory_name_encryption" type:"select" required:"true" options:"false,true" default:"true"`
+	FileNameEncoding string `json:"filename_encoding" type:"select" required:"true" options:"base64,base32,base32768" default:"base64" help:"for advanced user only!"`
+	EncryptedSuffix  string `json:"encrypted_suffix" required:"true" default:".bin" help:"for advanced user only! the suffix of the files when the file names aren't encrypted"`
+
+	ShowHidden bool `json:"show_hidden" default:"true" required:"false" help:"show hidden directories and files"`
+}
+
+var config = driver.Config{
+	Name:        "Crypt V2",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+	NoLinkURL:   true,
+	CheckStatus: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Crypt2{}
+	})
+}
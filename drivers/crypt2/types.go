@@ -0,0 +1,113 @@
+package crypt2
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// The data of a file is
+//
+//	header | sealed chunk 0 | sealed chunk 1 | ... | sealed chunk n-1
+//
+// where the header is the magic, the cipher id and the random nonce seed of the file, and every chunk but the last
+// holds chunkSize bytes of plain text. Each chunk is sealed independently, with the nonce of the seed xor the chunk
+// index, and the additional data of the header, the chunk index and whether it's the last chunk, so a range is read
+// by decrypting the chunks it covers only, and the reordered or truncated chunks fail to open.
+// An empty file has a single empty chunk, so the truncation to the header is detected too
+const (
+	magic      = "OLCRYPT2"
+	nonceSize  = 12
+	headerSize = len(magic) + 1 + 3 + nonceSize // magic, cipher id, reserved, nonce seed
+	chunkSize  = 64 * 1024
+	tagSize    = 16
+	sealedSize = chunkSize + tagSize
+)
+
+const (
+	cipherAESGCM byte = iota + 1
+	cipherChaCha20Poly1305
+)
+
+var cipherIds = map[string]byte{
+	"aes-256-gcm":       cipherAESGCM,
+	"chacha20-poly1305": cipherChaCha20Poly1305,
+}
+
+func newAEAD(id byte, key []byte) (cipher.AEAD, error) {
+	switch id {
+	case cipherAESGCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case cipherChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	}
+	return nil, fmt.Errorf("unknown cipher id %d", id)
+}
+
+type fileHeader [headerSize]byte
+
+func newFileHeader(cipherId byte, seed []byte) fileHeader {
+	var h fileHeader
+	copy(h[:], magic)
+	h[len(magic)] = cipherId
+	copy(h[headerSize-nonceSize:], seed)
+	return h
+}
+
+func parseFileHeader(b []byte) (fileHeader, error) {
+	var h fileHeader
+	if len(b) < headerSize || string(b[:len(magic)]) != magic {
+		return h, fmt.Errorf("not a crypt v2 file")
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+func (h *fileHeader) cipherId() byte {
+	return h[len(magic)]
+}
+
+func (h *fileHeader) nonce(index uint64) []byte {
+	nonce := make([]byte, nonceSize)
+	copy(nonce, h[headerSize-nonceSize:])
+	binary.BigEndian.PutUint64(nonce[nonceSize-8:], binary.BigEndian.Uint64(nonce[nonceSize-8:])^index)
+	return nonce
+}
+
+func (h *fileHeader) additionalData(index uint64, last bool) []byte {
+	ad := make([]byte, 0, headerSize+9)
+	ad = append(ad, h[:]...)
+	ad = binary.BigEndian.AppendUint64(ad, index)
+	if last {
+		return append(ad, 1)
+	}
+	return append(ad, 0)
+}
+
+// chunkCount is the number of the chunks of a file with size bytes of plain text
+func chunkCount(size int64) int64 {
+	return max(1, (size+chunkSize-1)/chunkSize)
+}
+
+func encryptedSize(size int64) int64 {
+	return int64(headerSize) + size + chunkCount(size)*tagSize
+}
+
+func decryptedSize(size int64) (int64, error) {
+	size -= int64(headerSize)
+	if size < tagSize {
+		return 0, fmt.Errorf("file is too short")
+	}
+	n := (size + sealedSize - 1) / sealedSize
+	if size-(n-1)*sealedSize < tagSize {
+		return 0, fmt.Errorf("file is truncated")
+	}
+	return size - n*tagSize, nil
+}
@@ -0,0 +1,242 @@
+package crypt2
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	stdpath "path"
+	"sort"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	rcCrypt "github.com/rclone/rclone/backend/crypt"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/config/obscure"
+	"golang.org/x/crypto/scrypt"
+)
+
+// do others that not defined in Driver interface
+
+const defaultSalt = "openlist crypt v2"
+
+// keyScope is the keys of the files under a directory
+type keyScope struct {
+	path  string // the plain path of the directory, / for the main password
+	key   []byte
+	names *rcCrypt.Cipher
+}
+
+func (d *Crypt2) newKeyScope(path, password, salt string) (*keyScope, error) {
+	key, err := scrypt.Key([]byte(password), []byte(utils.GetNoneEmpty(salt, defaultSalt)), 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	config := configmap.Simple{
+		"password":                  obscure.MustObscure(password),
+		"filename_encryption":       d.FileNameEnc,
+		"directory_name_encryption": d.DirNameEnc,
+		"filename_encoding":         d.FileNameEncoding,
+		"suffix":                    d.EncryptedSuffix,
+		"pass_bad_blocks":           "",
+	}
+	if salt != "" {
+		config["password2"] = obscure.MustObscure(salt)
+	}
+	names, err := rcCrypt.NewCipher(config)
+	if err != nil {
+		return nil, err
+	}
+	return &keyScope{path: path, key: key, names: names}, nil
+}
+
+// initScopes derives the keys of the main password and the directory passwords
+func (d *Crypt2) initScopes(password, salt string) error {
+	main, err := d.newKeyScope("/", password, salt)
+	if err != nil {
+		return err
+	}
+	scopes := []*keyScope{main}
+	for _, line := range strings.Split(d.DirKeys, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		dir, pwd, ok := strings.Cut(line, ":")
+		if !ok || pwd == "" {
+			return fmt.Errorf("invalid dir key: %s", dir)
+		}
+		s, err := d.newKeyScope(utils.FixAndCleanPath(dir), pwd, salt)
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, s)
+	}
+	// the deepest directory takes precedence
+	sort.SliceStable(scopes, func(i, j int) bool {
+		return len(scopes[i].path) > len(scopes[j].path)
+	})
+	d.scopes = scopes
+	return nil
+}
+
+// scopeOf returns the keys of the files in the plain directory dirPath
+func (d *Crypt2) scopeOf(dirPath string) *keyScope {
+	for _, s := range d.scopes {
+		if utils.IsSubPath(s.path, dirPath) {
+			return s
+		}
+	}
+	return d.scopes[len(d.scopes)-1]
+}
+
+// hasScopeUnder reports whether a directory password is given to dirPath or a directory under it,
+// such directories can't be moved without re-encrypting their files
+func (d *Crypt2) hasScopeUnder(dirPath string) bool {
+	for _, s := range d.scopes {
+		if s.path != "/" && utils.IsSubPath(dirPath, s.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// encryptPath returns the remote path of the plain path relative to the remote path
+func (d *Crypt2) encryptPath(path string, isFolder bool) string {
+	path = strings.Trim(utils.FixAndCleanPath(path), "/")
+	if path == "" {
+		return ""
+	}
+	segs := strings.Split(path, "/")
+	parent := "/"
+	for i, seg := range segs {
+		names := d.scopeOf(parent).names
+		parent = stdpath.Join(parent, seg)
+		if i == len(segs)-1 && !isFolder {
+			segs[i] = names.EncryptFileName(seg)
+		} else {
+			segs[i] = names.EncryptDirName(seg)
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// plainDirPath returns the plain path of the remote directory
+func (d *Crypt2) plainDirPath(remotePath string) (string, error) {
+	rel, ok := strings.CutPrefix(utils.FixAndCleanPath(remotePath), d.RemotePath)
+	if !ok {
+		return "", fmt.Errorf("[%s] is not under the remote path", remotePath)
+	}
+	rel = strings.Trim(rel, "/")
+	plain := "/"
+	if rel == "" {
+		return plain, nil
+	}
+	for _, seg := range strings.Split(rel, "/") {
+		name, err := d.scopeOf(plain).names.DecryptDirName(seg)
+		if err != nil {
+			return "", err
+		}
+		plain = stdpath.Join(plain, name)
+	}
+	return plain, nil
+}
+
+// encryptReader encrypts size bytes read from src
+type encryptReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	header fileHeader
+	index  uint64
+	chunks uint64
+	size   int64
+	buf    []byte
+	out    []byte
+}
+
+func newEncryptReader(src io.Reader, cipherId byte, key []byte, size int64) (*encryptReader, error) {
+	seed := make([]byte, nonceSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(cipherId, key)
+	if err != nil {
+		return nil, err
+	}
+	r := &encryptReader{
+		src:    src,
+		aead:   aead,
+		header: newFileHeader(cipherId, seed),
+		chunks: uint64(chunkCount(size)),
+		size:   size,
+		buf:    make([]byte, chunkSize, sealedSize),
+	}
+	r.out = r.header[:]
+	return r, nil
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.index >= r.chunks {
+			return 0, io.EOF
+		}
+		n := int64(chunkSize)
+		last := r.index == r.chunks-1
+		if last {
+			n = r.size - int64(r.index)*chunkSize
+		}
+		if _, err := io.ReadFull(r.src, r.buf[:n]); err != nil {
+			return 0, fmt.Errorf("failed read chunk %d: %w", r.index, err)
+		}
+		r.out = r.aead.Seal(r.buf[:0], r.header.nonce(r.index), r.buf[:n], r.header.additionalData(r.index, last))
+		r.index++
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// decryptReader decrypts the sealed chunks read from rc, starting at the chunk index
+type decryptReader struct {
+	rc        io.ReadCloser
+	aead      cipher.AEAD
+	header    fileHeader
+	index     uint64
+	last      uint64 // the index of the last chunk of the file
+	lastSize  int    // the sealed size of the last chunk
+	skip      int    // the bytes to skip in the first chunk
+	remaining int64  // the plain bytes to return
+	buf       []byte
+	out       []byte
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	for len(r.out) == 0 {
+		n := sealedSize
+		if r.index == r.last {
+			n = r.lastSize
+		}
+		if _, err := io.ReadFull(r.rc, r.buf[:n]); err != nil {
+			return 0, fmt.Errorf("failed read chunk %d: %w", r.index, err)
+		}
+		plain, err := r.aead.Open(r.buf[:0], r.header.nonce(r.index), r.buf[:n], r.header.additionalData(r.index, r.index == r.last))
+		if err != nil {
+			return 0, fmt.Errorf("failed decrypt chunk %d: %w", r.index, err)
+		}
+		r.out = plain[min(r.skip, len(plain)):]
+		r.skip = 0
+		r.index++
+	}
+	n := copy(p, r.out)
+	n = int(min(int64(n), r.remaining))
+	r.out = r.out[n:]
+	r.remaining -= int64(n)
+	return n, nil
+}
+
+func (r *decryptReader) Close() error {
+	return r.rc.Close()
+}
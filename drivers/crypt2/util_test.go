@@ -0,0 +1,93 @@
+package crypt2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	for _, size := range []int64{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 100} {
+		plain := make([]byte, size)
+		_, _ = rand.Read(plain)
+		for _, cipherId := range cipherIds {
+			r, err := newEncryptReader(bytes.NewReader(plain), cipherId, key, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sealed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(sealed)) != encryptedSize(size) {
+				t.Fatalf("size %d: encrypted size %d != %d", size, len(sealed), encryptedSize(size))
+			}
+			if got, err := decryptedSize(int64(len(sealed))); err != nil || got != size {
+				t.Fatalf("size %d: decrypted size %d, %v", size, got, err)
+			}
+			for _, rng := range [][2]int64{{0, size}, {size / 3, size / 2}, {chunkSize + 7, 10}} {
+				start, end := rng[0], min(rng[0]+rng[1], size)
+				if start >= end {
+					continue
+				}
+				got := decryptRange(t, sealed, key, start, end)
+				if !bytes.Equal(got, plain[start:end]) {
+					t.Fatalf("size %d: range [%d, %d) mismatch", size, start, end)
+				}
+			}
+		}
+	}
+}
+
+func TestDecryptTruncated(t *testing.T) {
+	key := make([]byte, 32)
+	size := int64(2*chunkSize + 10)
+	r, _ := newEncryptReader(bytes.NewReader(make([]byte, size)), cipherAESGCM, key, size)
+	sealed, _ := io.ReadAll(r)
+	// cutting the last chunk makes the second one look like the last
+	sealed = sealed[:headerSize+2*sealedSize]
+	if _, err := io.ReadAll(newTestDecryptReader(t, sealed, key, 0, 2*chunkSize)); err == nil {
+		t.Fatal("the truncated file is decrypted")
+	}
+}
+
+func decryptRange(t *testing.T, sealed, key []byte, start, end int64) []byte {
+	t.Helper()
+	got, err := io.ReadAll(newTestDecryptReader(t, sealed, key, start, end))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func newTestDecryptReader(t *testing.T, sealed, key []byte, start, end int64) *decryptReader {
+	t.Helper()
+	h, err := parseFileHeader(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := newAEAD(h.cipherId(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := decryptedSize(int64(len(sealed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastChunk := chunkCount(size) - 1
+	first := start / chunkSize
+	return &decryptReader{
+		rc:        io.NopCloser(bytes.NewReader(sealed[int64(headerSize)+first*sealedSize:])),
+		aead:      aead,
+		header:    h,
+		index:     uint64(first),
+		last:      uint64(lastChunk),
+		lastSize:  int(int64(len(sealed)) - int64(headerSize) - lastChunk*sealedSize),
+		skip:      int(start - first*chunkSize),
+		remaining: end - start,
+		buf:       make([]byte, sealedSize),
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/drivers/base"
@@ -231,4 +232,46 @@ func (d *Dropbox) Put(ctx context.Context, dstDir model.Obj, stream model.FileSt
 	return err
 }
 
+// Watch reports the changes by the longpoll of the cursor of the root folder
+func (d *Dropbox) Watch(ctx context.Context, notify func(event model.FsEvent)) error {
+	root := strings.TrimSuffix(d.GetRootPath(), "/")
+	var latest LatestCursorResp
+	_, err := d.request("/2/files/list_folder/get_latest_cursor", http.MethodPost, func(req *resty.Request) {
+		req.SetContext(ctx).SetBody(base.Json{
+			"path":            root,
+			"recursive":       true,
+			"include_deleted": true,
+		}).SetResult(&latest)
+	})
+	if err != nil {
+		return err
+	}
+	cursor := latest.Cursor
+	// dropbox adds up to 90 seconds to the timeout of the longpoll
+	client := base.NewRestyClient().SetTimeout(time.Duration(longpollTimeout+90) * time.Second)
+	for {
+		var resp LongpollResp
+		_, err := client.R().SetContext(ctx).SetBody(base.Json{
+			"cursor":  cursor,
+			"timeout": longpollTimeout,
+		}).SetResult(&resp).Post(notifyBase + "/2/files/list_folder/longpoll")
+		if err != nil {
+			return err
+		}
+		if resp.Changes {
+			if cursor, err = d.notifyChanges(ctx, root, cursor, notify); err != nil {
+				return err
+			}
+		}
+		if resp.Backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(resp.Backoff) * time.Second):
+			}
+		}
+	}
+}
+
 var _ driver.Driver = (*Dropbox)(nil)
+var _ driver.Watcher = (*Dropbox)(nil)
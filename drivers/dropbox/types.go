@@ -51,6 +51,15 @@ type ListResp struct {
 	HasMore bool   `json:"has_more"`
 }
 
+type LatestCursorResp struct {
+	Cursor string `json:"cursor"`
+}
+
+type LongpollResp struct {
+	Changes bool `json:"changes"`
+	Backoff int  `json:"backoff"`
+}
+
 type UploadCursor struct {
 	Offset    int64  `json:"offset"`
 	SessionID string `json:"session_id"`
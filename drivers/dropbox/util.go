@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/go-resty/resty/v2"
@@ -166,6 +167,39 @@ func (d *Dropbox) getFiles(ctx context.Context, path string) ([]File, error) {
 	return res, nil
 }
 
+const (
+	notifyBase      = "https://notify.dropboxapi.com"
+	longpollTimeout = 480
+)
+
+// notifyChanges reports the entries changed since the cursor and returns the new cursor
+func (d *Dropbox) notifyChanges(ctx context.Context, root, cursor string, notify func(event model.FsEvent)) (string, error) {
+	for {
+		resp, err := d.list(ctx, base.Json{"cursor": cursor}, true)
+		if err != nil {
+			return cursor, err
+		}
+		for _, f := range resp.Entries {
+			if len(f.PathDisplay) < len(root) || !strings.EqualFold(f.PathDisplay[:len(root)], root) {
+				continue
+			}
+			event := model.FsEvent{
+				Type:  model.FsEventCreate,
+				Path:  f.PathDisplay[len(root):],
+				IsDir: f.Tag == "folder",
+			}
+			if f.Tag == "deleted" {
+				event.Type = model.FsEventDelete
+			}
+			notify(event)
+		}
+		cursor = resp.Cursor
+		if !resp.HasMore {
+			return cursor, nil
+		}
+	}
+}
+
 func (d *Dropbox) finishUploadSession(ctx context.Context, toPath string, offset int64, sessionId string) error {
 	url := d.contentBase + "/2/files/upload_session/finish"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
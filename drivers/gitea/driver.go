@@ -0,0 +1,152 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	stdpath "path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+const (
+	releasesDir = "releases"
+	branchesDir = "branches"
+)
+
+// Gitea exposes the releases of a Gitea repository as tag folders with the assets in them,
+// and optionally the raw files of its branches
+type Gitea struct {
+	model.Storage
+	Addition
+}
+
+func (d *Gitea) Config() driver.Config {
+	return config
+}
+
+func (d *Gitea) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Gitea) Init(ctx context.Context) error {
+	d.Address = strings.TrimSuffix(d.Address, "/")
+	_, err := d.request("", nil, nil)
+	return err
+}
+
+func (d *Gitea) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Gitea) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	parts := strings.Split(strings.Trim(dir.GetPath(), "/"), "/")
+	if parts[0] == "" {
+		parts = nil
+	}
+	if !d.ShowBranches {
+		return d.listReleases(dir.GetPath(), parts)
+	}
+	if len(parts) == 0 {
+		return []model.Obj{
+			&model.Object{Name: releasesDir, Path: stdpath.Join(dir.GetPath(), releasesDir), IsFolder: true},
+			&model.Object{Name: branchesDir, Path: stdpath.Join(dir.GetPath(), branchesDir), IsFolder: true},
+		}, nil
+	}
+	switch parts[0] {
+	case releasesDir:
+		return d.listReleases(dir.GetPath(), parts[1:])
+	case branchesDir:
+		return d.listBranches(dir.GetPath(), parts[1:])
+	}
+	return nil, errs.ObjectNotFound
+}
+
+// listReleases lists the tags, or the assets of the tag in parts
+func (d *Gitea) listReleases(dirPath string, parts []string) ([]model.Obj, error) {
+	switch len(parts) {
+	case 0:
+		releases, err := d.getReleases()
+		if err != nil {
+			return nil, err
+		}
+		return utils.SliceConvert(releases, func(r Release) (model.Obj, error) {
+			return &model.Object{
+				Name:     r.TagName,
+				Path:     stdpath.Join(dirPath, r.TagName),
+				Modified: r.PublishedAt,
+				Ctime:    r.CreatedAt,
+				IsFolder: true,
+			}, nil
+		})
+	case 1:
+		release, err := d.getRelease(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		return utils.SliceConvert(release.Assets, func(a Asset) (model.Obj, error) {
+			return &model.Object{
+				ID:       a.BrowserDownloadURL,
+				Name:     a.Name,
+				Path:     stdpath.Join(dirPath, a.Name),
+				Size:     a.Size,
+				Modified: a.CreatedAt,
+			}, nil
+		})
+	}
+	return nil, errs.ObjectNotFound
+}
+
+// listBranches lists the branches, or the raw files in the branch of parts
+func (d *Gitea) listBranches(dirPath string, parts []string) ([]model.Obj, error) {
+	if len(parts) == 0 {
+		branches, err := d.getBranches()
+		if err != nil {
+			return nil, err
+		}
+		return utils.SliceConvert(branches, func(b Branch) (model.Obj, error) {
+			return &model.Object{
+				Name:     b.Name,
+				Path:     stdpath.Join(dirPath, b.Name),
+				Modified: b.Commit.Timestamp,
+				IsFolder: true,
+			}, nil
+		})
+	}
+	contents, err := d.getContents(parts[0], strings.Join(parts[1:], "/"))
+	if err != nil {
+		return nil, err
+	}
+	var res []model.Obj
+	for _, c := range contents {
+		if c.Type != "file" && c.Type != "dir" {
+			continue
+		}
+		res = append(res, &model.Object{
+			ID:       c.DownloadURL,
+			Name:     c.Name,
+			Path:     stdpath.Join(dirPath, c.Name),
+			Size:     c.Size,
+			IsFolder: c.Type == "dir",
+		})
+	}
+	return res, nil
+}
+
+func (d *Gitea) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	// the download url of an asset or a raw file is kept as its id
+	if file.GetID() == "" {
+		return nil, errs.NotFile
+	}
+	link := &model.Link{URL: file.GetID()}
+	// the driver is only proxied, so the token is never sent to the clients
+	if d.Token != "" {
+		link.Header = http.Header{"Authorization": []string{"token " + d.Token}}
+	}
+	return link, nil
+}
+
+var _ driver.Driver = (*Gitea)(nil)
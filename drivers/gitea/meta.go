@@ -0,0 +1,29 @@
+package gitea
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	driver.RootPath
+	Address      string `json:"address" required:"true" default:"https://gitea.com"`
+	Owner        string `json:"owner" required:"true"`
+	Repo         string `json:"repo" required:"true"`
+	Token        string `json:"token" help:"The access token, for the private repositories"`
+	ShowBranches bool   `json:"show_branches" default:"false" help:"Show the raw files of the branches in a branches folder, the releases are put in a releases folder then."`
+}
+
+var config = driver.Config{
+	Name:        "Gitea",
+	DefaultRoot: "/",
+	LocalSort:   true,
+	NoUpload:    true,
+	OnlyProxy:   true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Gitea{}
+	})
+}
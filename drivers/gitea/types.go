@@ -0,0 +1,37 @@
+package gitea
+
+import "time"
+
+type Asset struct {
+	ID                 int64     `json:"id"`
+	Name               string    `json:"name"`
+	Size               int64     `json:"size"`
+	CreatedAt          time.Time `json:"created_at"`
+	BrowserDownloadURL string    `json:"browser_download_url"`
+}
+
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+}
+
+type Branch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"commit"`
+}
+
+type Content struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Type        string `json:"type"` // file, dir, symlink or submodule
+	Size        int64  `json:"size"`
+	DownloadURL string `json:"download_url"`
+}
+
+type ErrResp struct {
+	Message string `json:"message"`
+}
@@ -0,0 +1,93 @@
+package gitea
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+const pageLimit = 50
+
+func (d *Gitea) request(pathname string, callback base.ReqCallback, resp interface{}) (*resty.Response, error) {
+	req := base.RestyClient.R()
+	if d.Token != "" {
+		req.SetHeader("Authorization", "token "+d.Token)
+	}
+	if callback != nil {
+		callback(req)
+	}
+	if resp != nil {
+		req.SetResult(resp)
+	}
+	var e ErrResp
+	req.SetError(&e)
+	u := fmt.Sprintf("%s/api/v1/repos/%s/%s%s", d.Address, url.PathEscape(d.Owner), url.PathEscape(d.Repo), pathname)
+	res, err := req.Execute(http.MethodGet, u)
+	if err != nil {
+		return nil, err
+	}
+	if res.IsError() {
+		if e.Message != "" {
+			return nil, errors.New(e.Message)
+		}
+		return nil, fmt.Errorf("request %s failed: %s", pathname, res.Status())
+	}
+	return res, nil
+}
+
+// getPages requests all the pages of a list api
+func getPages[T any](d *Gitea, pathname string, callback base.ReqCallback) ([]T, error) {
+	var res []T
+	for page := 1; ; page++ {
+		var items []T
+		_, err := d.request(pathname, func(req *resty.Request) {
+			req.SetQueryParams(map[string]string{
+				"page":  strconv.Itoa(page),
+				"limit": strconv.Itoa(pageLimit),
+			})
+			if callback != nil {
+				callback(req)
+			}
+		}, &items)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, items...)
+		if len(items) < pageLimit {
+			return res, nil
+		}
+	}
+}
+
+func (d *Gitea) getReleases() ([]Release, error) {
+	return getPages[Release](d, "/releases", nil)
+}
+
+func (d *Gitea) getRelease(tag string) (*Release, error) {
+	var release Release
+	_, err := d.request("/releases/tags/"+url.PathEscape(tag), nil, &release)
+	if err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (d *Gitea) getBranches() ([]Branch, error) {
+	return getPages[Branch](d, "/branches", nil)
+}
+
+func (d *Gitea) getContents(branch, path string) ([]Content, error) {
+	var contents []Content
+	_, err := d.request("/contents/"+utils.EncodePath(path, true), func(req *resty.Request) {
+		req.SetQueryParam("ref", branch)
+	}, &contents)
+	return contents, err
+}
@@ -0,0 +1,63 @@
+package jellyfin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// Jellyfin lists the libraries of a Jellyfin or an Emby server read-only and streams the original files
+type Jellyfin struct {
+	model.Storage
+	Addition
+}
+
+func (d *Jellyfin) Config() driver.Config {
+	return config
+}
+
+func (d *Jellyfin) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Jellyfin) Init(ctx context.Context) error {
+	d.Address = strings.TrimSuffix(d.Address, "/")
+	if d.UserID != "" {
+		return d.request("/Users/"+d.UserID, nil, nil)
+	}
+	id, err := d.getUserID()
+	if err != nil {
+		return err
+	}
+	d.UserID = id
+	return nil
+}
+
+func (d *Jellyfin) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Jellyfin) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	items, err := d.getItems(dir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(items, func(src Item) (model.Obj, error) {
+		return itemToObj(src), nil
+	})
+}
+
+func (d *Jellyfin) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	return &model.Link{
+		URL: d.Address + "/Items/" + file.GetID() + "/Download",
+		Header: http.Header{
+			"X-Emby-Token": []string{d.APIKey},
+		},
+	}, nil
+}
+
+var _ driver.Driver = (*Jellyfin)(nil)
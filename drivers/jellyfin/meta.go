@@ -0,0 +1,28 @@
+package jellyfin
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	driver.RootID
+	Address string `json:"address" required:"true" help:"The address of the Jellyfin or Emby server, e.g. http://127.0.0.1:8096"`
+	APIKey  string `json:"api_key" required:"true"`
+	UserID  string `json:"user_id" help:"The user whose libraries are listed, the first user of the server if empty."`
+}
+
+var config = driver.Config{
+	Name:        "Jellyfin",
+	DefaultRoot: "",
+	LocalSort:   true,
+	NoUpload:    true,
+	// the download requires the api key in the header, which must not be sent to the clients
+	OnlyProxy: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Jellyfin{}
+	})
+}
@@ -0,0 +1,54 @@
+package jellyfin
+
+import (
+	"path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+type User struct {
+	Id   string `json:"Id"`
+	Name string `json:"Name"`
+}
+
+type MediaSource struct {
+	Path string `json:"Path"`
+	Size int64  `json:"Size"`
+}
+
+type Item struct {
+	Id           string        `json:"Id"`
+	Name         string        `json:"Name"`
+	IsFolder     bool          `json:"IsFolder"`
+	Path         string        `json:"Path"`
+	DateCreated  time.Time     `json:"DateCreated"`
+	MediaSources []MediaSource `json:"MediaSources"`
+}
+
+type ItemsResp struct {
+	Items            []Item `json:"Items"`
+	TotalRecordCount int    `json:"TotalRecordCount"`
+}
+
+func itemToObj(item Item) *model.Object {
+	obj := &model.Object{
+		ID:       item.Id,
+		Name:     item.Name,
+		Modified: item.DateCreated,
+		IsFolder: item.IsFolder,
+	}
+	if item.IsFolder {
+		return obj
+	}
+	// the files are named as the originals, as the item name of a movie or an episode is its title
+	p := item.Path
+	if len(item.MediaSources) > 0 {
+		p = item.MediaSources[0].Path
+		obj.Size = item.MediaSources[0].Size
+	}
+	if p != "" {
+		obj.Name = path.Base(filepathToSlash(p))
+	}
+	return obj
+}
@@ -0,0 +1,78 @@
+package jellyfin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+func (d *Jellyfin) request(pathname string, callback base.ReqCallback, resp interface{}) error {
+	req := base.RestyClient.R()
+	req.SetHeader("X-Emby-Token", d.APIKey)
+	if callback != nil {
+		callback(req)
+	}
+	if resp != nil {
+		req.SetResult(resp)
+	}
+	res, err := req.Execute(http.MethodGet, d.Address+pathname)
+	if err != nil {
+		return err
+	}
+	if res.IsError() {
+		return fmt.Errorf("request %s failed: %s", pathname, res.Status())
+	}
+	return nil
+}
+
+func (d *Jellyfin) getUserID() (string, error) {
+	var users []User
+	if err := d.request("/Users", nil, &users); err != nil {
+		return "", err
+	}
+	if len(users) == 0 {
+		return "", errors.New("no user found on the server")
+	}
+	return users[0].Id, nil
+}
+
+// getItems lists the children of parentID, the library views if it's empty
+func (d *Jellyfin) getItems(parentID string) ([]Item, error) {
+	if parentID == "" {
+		var resp ItemsResp
+		err := d.request("/Users/"+d.UserID+"/Views", nil, &resp)
+		return resp.Items, err
+	}
+	const limit = 500
+	var res []Item
+	for start := 0; ; start += limit {
+		var resp ItemsResp
+		err := d.request("/Users/"+d.UserID+"/Items", func(req *resty.Request) {
+			req.SetQueryParams(map[string]string{
+				"ParentId":   parentID,
+				"Fields":     "Path,MediaSources,DateCreated",
+				"StartIndex": strconv.Itoa(start),
+				"Limit":      strconv.Itoa(limit),
+			})
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Items...)
+		if len(resp.Items) < limit || start+limit >= resp.TotalRecordCount {
+			return res, nil
+		}
+	}
+}
+
+// filepathToSlash converts the paths of the servers running on Windows
+func filepathToSlash(p string) string {
+	return strings.ReplaceAll(p, "\\", "/")
+}
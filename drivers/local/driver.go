@@ -1,10 +1,12 @@
 package local
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"os"
@@ -14,12 +16,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/KarpelesLab/reflink"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/delta"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/OpenListTeam/times"
@@ -356,6 +360,45 @@ func (d *Local) Copy(_ context.Context, srcObj, dstDir model.Obj) error {
 	return nil
 }
 
+func (d *Local) LocalPath(obj model.Obj) string {
+	return obj.GetPath()
+}
+
+func (d *Local) PutLocal(ctx context.Context, dstDir model.Obj, srcPath string, move bool) (model.Obj, error) {
+	dstPath := filepath.Join(dstDir.GetPath(), filepath.Base(srcPath))
+	if utils.IsSubPath(srcPath, dstPath) {
+		return nil, fmt.Errorf("the destination folder is a subfolder of the source folder")
+	}
+	var err error
+	if move {
+		err = os.Rename(srcPath, dstPath)
+		if isCrossDeviceError(err) {
+			return nil, errs.NotImplement
+		}
+	} else {
+		// reflink if the filesystem supports it, otherwise copy_file_range
+		err = reflink.Auto(srcPath, dstPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if d.directoryMap.Has(dstDir.GetPath()) {
+		d.directoryMap.UpdateDirSize(dstDir.GetPath())
+		d.directoryMap.UpdateDirParents(dstDir.GetPath())
+	}
+	f, err := os.Stat(dstPath)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Object{
+		Path:     dstPath,
+		Name:     f.Name(),
+		Modified: f.ModTime(),
+		Size:     f.Size(),
+		IsFolder: f.IsDir(),
+	}, nil
+}
+
 func (d *Local) Remove(ctx context.Context, obj model.Obj) error {
 	var err error
 	if utils.SliceContains([]string{"", "delete permanently"}, d.RecycleBinPath) {
@@ -417,7 +460,18 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 			_ = os.Remove(fullPath)
 		}
 	}()
-	err = utils.CopyWithCtx(ctx, out, stream, stream.GetSize(), up)
+	if d.SparseFiles {
+		w := utils.NewSparseWriter(out)
+		err = utils.CopyWithCtx(ctx, w, stream, stream.GetSize(), up)
+		if err == nil {
+			err = w.Close()
+		}
+	} else {
+		if e := utils.Preallocate(out, stream.GetSize()); e != nil {
+			log.Debugf("[local] failed to preallocate %s: %s", fullPath, e)
+		}
+		err = utils.CopyWithCtx(ctx, out, stream, stream.GetSize(), up)
+	}
 	if err != nil {
 		return err
 	}
@@ -433,6 +487,113 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	return nil
 }
 
+func (d *Local) PutTar(ctx context.Context, dstDir model.Obj, tarStream io.Reader) error {
+	tr := tar.NewReader(tarStream)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Name != stdpath.Base(hdr.Name) || hdr.Name == "." || hdr.Name == ".." || strings.Contains(hdr.Name, "\\") {
+			return errs.RelativePath
+		}
+		fullPath := filepath.Join(dstDir.GetPath(), hdr.Name)
+		out, err := os.Create(fullPath)
+		if err != nil {
+			return err
+		}
+		err = utils.CopyWithCtx(ctx, out, tr, hdr.Size, func(float64) {})
+		_ = out.Close()
+		if err != nil {
+			_ = os.Remove(fullPath)
+			return err
+		}
+		if err = os.Chtimes(fullPath, hdr.ModTime, hdr.ModTime); err != nil {
+			log.Errorf("[local] failed to change time of %s: %s", fullPath, err)
+		}
+	}
+	if d.directoryMap.Has(dstDir.GetPath()) {
+		d.directoryMap.UpdateDirSize(dstDir.GetPath())
+		d.directoryMap.UpdateDirParents(dstDir.GetPath())
+	}
+	return nil
+}
+
+func (d *Local) PutDelta(ctx context.Context, obj model.Obj, ops []delta.Op, data io.Reader, modified time.Time) error {
+	fullPath := obj.GetPath()
+	old, err := os.Open(fullPath)
+	if err != nil {
+		return err
+	}
+	defer old.Close()
+	tmpPath := filepath.Join(filepath.Dir(fullPath), "."+filepath.Base(fullPath)+".delta")
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	err = delta.Apply(out, old, ops, &driver.ReaderWithCtx{Reader: data, Ctx: ctx})
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, fullPath)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err = os.Chtimes(fullPath, modified, modified); err != nil {
+		log.Errorf("[local] failed to change time of %s: %s", fullPath, err)
+	}
+	if dir := filepath.Dir(fullPath); d.directoryMap.Has(dir) {
+		d.directoryMap.UpdateDirSize(dir)
+		d.directoryMap.UpdateDirParents(dir)
+	}
+	return nil
+}
+
+func (d *Local) AppendPut(ctx context.Context, obj model.Obj, data io.Reader, size int64) error {
+	return d.writeAt(ctx, obj.GetPath(), -1, data, size)
+}
+
+func (d *Local) WriteRange(ctx context.Context, obj model.Obj, offset int64, data io.Reader, size int64) error {
+	return d.writeAt(ctx, obj.GetPath(), offset, data, size)
+}
+
+// writeAt writes the data of size at offset of the file in place, a negative offset appends it
+func (d *Local) writeAt(ctx context.Context, fullPath string, offset int64, data io.Reader, size int64) error {
+	flag := os.O_WRONLY
+	if offset < 0 {
+		flag |= os.O_APPEND
+	}
+	f, err := os.OpenFile(fullPath, flag, 0)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = f
+	if offset >= 0 {
+		w = io.NewOffsetWriter(f, offset)
+	}
+	_, err = io.CopyN(w, &driver.ReaderWithCtx{Reader: data, Ctx: ctx}, size)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(fullPath); d.directoryMap.Has(dir) {
+		d.directoryMap.UpdateDirSize(dir)
+		d.directoryMap.UpdateDirParents(dir)
+	}
+	return nil
+}
+
 func (d *Local) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
 	du, err := getDiskUsage(d.RootFolderPath)
 	if err != nil {
@@ -444,3 +605,8 @@ func (d *Local) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
 }
 
 var _ driver.Driver = (*Local)(nil)
+var _ driver.PutTar = (*Local)(nil)
+var _ driver.PutDelta = (*Local)(nil)
+var _ driver.AppendPut = (*Local)(nil)
+var _ driver.WriteRange = (*Local)(nil)
+var _ driver.LocalTransfer = (*Local)(nil)
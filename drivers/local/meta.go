@@ -15,6 +15,7 @@ type Addition struct {
 	ShowHidden       bool   `json:"show_hidden" default:"true" required:"false" help:"show hidden directories and files"`
 	MkdirPerm        string `json:"mkdir_perm" default:"777"`
 	RecycleBinPath   string `json:"recycle_bin_path" default:"delete permanently" help:"path to recycle bin, delete permanently if empty or keep 'delete permanently'"`
+	SparseFiles      bool   `json:"sparse_files" default:"false" help:"write the blocks full of zeros of uploaded files as holes, saves space for disk images"`
 }
 
 var config = driver.Config{
@@ -24,6 +25,7 @@ var config = driver.Config{
 	NoCache:     true,
 	DefaultRoot: "/",
 	NoLinkURL:   true,
+	LocalFS:     true,
 }
 
 func init() {
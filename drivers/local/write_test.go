@@ -0,0 +1,40 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestWrite(t *testing.T) {
+	d := &Local{}
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	obj := &model.Object{Path: path}
+	ctx := context.Background()
+	if err := d.AppendPut(ctx, obj, strings.NewReader(" world"), 6); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.WriteRange(ctx, obj, 0, strings.NewReader("J"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.WriteRange(ctx, obj, 13, strings.NewReader("!"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AppendPut(ctx, obj, strings.NewReader("ab"), 3); err == nil {
+		t.Error("expect an error for the data shorter than the size")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Jello world\x00\x00!ab"; string(b) != expect {
+		t.Errorf("expect %q, got %q", expect, b)
+	}
+}
@@ -109,6 +109,35 @@ func (d *OpenList) List(ctx context.Context, dir model.Obj, args model.ListArgs)
 	return files, nil
 }
 
+func (d *OpenList) Get(ctx context.Context, objPath string) (model.Obj, error) {
+	var resp common.Resp[FsGetResp]
+	fullPath := path.Join(d.GetRootPath(), objPath)
+	_, _, err := d.request("/fs/get", http.MethodPost, func(req *resty.Request) {
+		req.SetResult(&resp).SetBody(FsGetReq{
+			Path:     fullPath,
+			Password: d.MetaPassword,
+		})
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), errs.ObjectNotFound.Error()) {
+			return nil, errs.ObjectNotFound
+		}
+		return nil, err
+	}
+	return &model.ObjThumb{
+		Object: model.Object{
+			Name:     resp.Data.Name,
+			Path:     fullPath,
+			Modified: resp.Data.Modified,
+			Ctime:    resp.Data.Created,
+			Size:     resp.Data.Size,
+			IsFolder: resp.Data.IsDir,
+			HashInfo: utils.FromString(resp.Data.HashInfo),
+		},
+		Thumbnail: model.Thumbnail{Thumbnail: resp.Data.Thumb},
+	}, nil
+}
+
 func (d *OpenList) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
 	var resp common.Resp[FsGetResp]
 	headers := map[string]string{
@@ -138,8 +167,14 @@ func (d *OpenList) Link(ctx context.Context, file model.Obj, args model.LinkArgs
 	if err != nil {
 		return nil, err
 	}
+	// the range requests to the raw url are sent with the same user-agent and ip as the client
+	header := http.Header{}
+	for k, v := range headers {
+		header.Set(k, v)
+	}
 	return &model.Link{
-		URL: resp.Data.RawURL,
+		URL:    resp.Data.RawURL,
+		Header: header,
 	}, nil
 }
 
@@ -379,3 +414,4 @@ func (d *OpenList) ResolveLinkCacheMode(_ string) driver.LinkCacheMode {
 }
 
 var _ driver.Driver = (*OpenList)(nil)
+var _ driver.Getter = (*OpenList)(nil)
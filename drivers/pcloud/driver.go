@@ -0,0 +1,218 @@
+package pcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+type PCloud struct {
+	model.Storage
+	Addition
+}
+
+func (d *PCloud) Config() driver.Config {
+	return config
+}
+
+func (d *PCloud) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *PCloud) Init(ctx context.Context) error {
+	if d.AccessToken == "" {
+		if err := d.getToken(); err != nil {
+			return err
+		}
+	}
+	var resp Resp
+	return d.request("userinfo", nil, &resp)
+}
+
+func (d *PCloud) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *PCloud) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	files, err := d.getFiles(dir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *PCloud) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	var resp FileLinkResp
+	err := d.request("getfilelink", func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{
+			"fileid":        file.GetID(),
+			"forcedownload": "1",
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Hosts) == 0 {
+		return nil, errs.ObjectNotFound
+	}
+	link := &model.Link{URL: "https://" + resp.Hosts[0] + resp.Path}
+	// the link is only cached until it expires
+	if expires, err := time.Parse(time.RFC1123Z, resp.Expires); err == nil {
+		exp := time.Until(expires)
+		link.Expiration = &exp
+	}
+	return link, nil
+}
+
+func (d *PCloud) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) (model.Obj, error) {
+	var resp MetadataResp
+	err := d.request("createfolder", func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{
+			"folderid": parentDir.GetID(),
+			"name":     dirName,
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return fileToObj(resp.Metadata), nil
+}
+
+func (d *PCloud) Move(ctx context.Context, srcObj, dstDir model.Obj) (model.Obj, error) {
+	method, idKey := "renamefile", "fileid"
+	if srcObj.IsDir() {
+		method, idKey = "renamefolder", "folderid"
+	}
+	var resp MetadataResp
+	err := d.request(method, func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{
+			idKey:        srcObj.GetID(),
+			"tofolderid": dstDir.GetID(),
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return fileToObj(resp.Metadata), nil
+}
+
+func (d *PCloud) Rename(ctx context.Context, srcObj model.Obj, newName string) (model.Obj, error) {
+	method, idKey := "renamefile", "fileid"
+	if srcObj.IsDir() {
+		method, idKey = "renamefolder", "folderid"
+	}
+	var resp MetadataResp
+	err := d.request(method, func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{
+			idKey:    srcObj.GetID(),
+			"toname": newName,
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return fileToObj(resp.Metadata), nil
+}
+
+func (d *PCloud) Copy(ctx context.Context, srcObj, dstDir model.Obj) (model.Obj, error) {
+	method, idKey := "copyfile", "fileid"
+	if srcObj.IsDir() {
+		method, idKey = "copyfolder", "folderid"
+	}
+	var resp MetadataResp
+	err := d.request(method, func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{
+			idKey:        srcObj.GetID(),
+			"tofolderid": dstDir.GetID(),
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return fileToObj(resp.Metadata), nil
+}
+
+func (d *PCloud) Remove(ctx context.Context, obj model.Obj) error {
+	method, idKey := "deletefile", "fileid"
+	if obj.IsDir() {
+		method, idKey = "deletefolderrecursive", "folderid"
+	}
+	var resp Resp
+	return d.request(method, func(req *resty.Request) {
+		req.SetQueryParam(idKey, obj.GetID())
+	}, &resp)
+}
+
+func (d *PCloud) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) (model.Obj, error) {
+	reader := driver.NewLimitedUploadStream(ctx, &driver.ReaderUpdatingProgress{
+		Reader:         s,
+		UpdateProgress: up,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.apiURL()+"/uploadfile", reader)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Set("folderid", dstDir.GetID())
+	q.Set("filename", s.GetName())
+	q.Set("nopartial", "1")
+	q.Set("mtime", strconv.FormatInt(s.ModTime().Unix(), 10))
+	req.URL.RawQuery = q.Encode()
+	req.ContentLength = s.GetSize()
+	req.Header.Set("Authorization", "Bearer "+d.AccessToken)
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var resp UploadResp
+	if err := utils.Json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Result != 0 {
+		return nil, fmt.Errorf("uploadfile failed: [%d] %s", resp.Result, resp.Error)
+	}
+	if len(resp.Metadata) == 0 {
+		return nil, nil
+	}
+	return fileToObj(resp.Metadata[0]), nil
+}
+
+// Other supports the method checksum, which returns the checksums of a file computed by pCloud
+func (d *PCloud) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	if args.Method != "checksum" {
+		return nil, errs.NotSupport
+	}
+	var resp ChecksumResp
+	err := d.request("checksumfile", func(req *resty.Request) {
+		req.SetQueryParam("fileid", args.Obj.GetID())
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"sha1":   resp.SHA1,
+		"md5":    resp.MD5,
+		"sha256": resp.SHA256,
+	}, nil
+}
+
+var _ driver.Driver = (*PCloud)(nil)
+var _ driver.MkdirResult = (*PCloud)(nil)
+var _ driver.MoveResult = (*PCloud)(nil)
+var _ driver.RenameResult = (*PCloud)(nil)
+var _ driver.CopyResult = (*PCloud)(nil)
+var _ driver.Remove = (*PCloud)(nil)
+var _ driver.PutResult = (*PCloud)(nil)
+var _ driver.Other = (*PCloud)(nil)
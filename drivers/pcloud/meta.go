@@ -0,0 +1,27 @@
+package pcloud
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	driver.RootID
+	Region       string `json:"region" type:"select" options:"us,eu" default:"us" help:"The API host of the account, eu for the accounts registered in the European data region."`
+	AccessToken  string `json:"access_token" help:"The OAuth access token, it doesn't expire. Leave it empty to exchange the authorization code for it."`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Code         string `json:"code" help:"The authorization code of the OAuth app, it's used once to get the access token."`
+}
+
+var config = driver.Config{
+	Name:        "pCloud",
+	DefaultRoot: "0",
+	LocalSort:   true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &PCloud{}
+	})
+}
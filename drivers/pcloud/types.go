@@ -0,0 +1,72 @@
+package pcloud
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+type Resp struct {
+	Result int    `json:"result"`
+	Error  string `json:"error"`
+}
+
+type File struct {
+	Name     string `json:"name"`
+	IsFolder bool   `json:"isfolder"`
+	FolderID int64  `json:"folderid"`
+	FileID   int64  `json:"fileid"`
+	Size     int64  `json:"size"`
+	Created  string `json:"created"`
+	Modified string `json:"modified"`
+	Contents []File `json:"contents"`
+}
+
+func fileToObj(f File) *model.Object {
+	id := f.FileID
+	if f.IsFolder {
+		id = f.FolderID
+	}
+	// the times are in the format of RFC 1123, e.g. Thu, 21 Mar 2013 18:31:44 +0000
+	modified, _ := time.Parse(time.RFC1123Z, f.Modified)
+	created, _ := time.Parse(time.RFC1123Z, f.Created)
+	return &model.Object{
+		ID:       strconv.FormatInt(id, 10),
+		Name:     f.Name,
+		Size:     f.Size,
+		Modified: modified,
+		Ctime:    created,
+		IsFolder: f.IsFolder,
+	}
+}
+
+type MetadataResp struct {
+	Resp
+	Metadata File `json:"metadata"`
+}
+
+type UploadResp struct {
+	Resp
+	Metadata []File `json:"metadata"`
+}
+
+type FileLinkResp struct {
+	Resp
+	Path    string   `json:"path"`
+	Hosts   []string `json:"hosts"`
+	Expires string   `json:"expires"`
+}
+
+type ChecksumResp struct {
+	Resp
+	SHA1   string `json:"sha1"`
+	MD5    string `json:"md5"`    // only on the US API host
+	SHA256 string `json:"sha256"` // only on the EU API host
+}
+
+type TokenResp struct {
+	Resp
+	AccessToken string `json:"access_token"`
+	LocationID  int    `json:"locationid"` // 1 for us, 2 for eu
+}
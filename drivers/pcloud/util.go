@@ -0,0 +1,80 @@
+package pcloud
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+func (d *PCloud) apiURL() string {
+	if d.Region == "eu" {
+		return "https://eapi.pcloud.com"
+	}
+	return "https://api.pcloud.com"
+}
+
+func (d *PCloud) getToken() error {
+	if d.ClientID == "" || d.ClientSecret == "" || d.Code == "" {
+		return errors.New("empty access token, or client id, client secret and code to get it")
+	}
+	var resp TokenResp
+	_, err := base.RestyClient.R().SetResult(&resp).SetQueryParams(map[string]string{
+		"client_id":     d.ClientID,
+		"client_secret": d.ClientSecret,
+		"code":          d.Code,
+	}).Get(d.apiURL() + "/oauth2_token")
+	if err != nil {
+		return err
+	}
+	if resp.Result != 0 {
+		return fmt.Errorf("failed get token: %s", resp.Error)
+	}
+	d.AccessToken, d.Code = resp.AccessToken, ""
+	if resp.LocationID == 2 {
+		d.Region = "eu"
+	} else {
+		d.Region = "us"
+	}
+	op.MustSaveDriverStorage(d)
+	return nil
+}
+
+// request calls the method of the API, the result is checked by the result code in the response
+func (d *PCloud) request(method string, callback base.ReqCallback, resp interface {
+	result() (int, string)
+}) error {
+	req := base.RestyClient.R()
+	req.SetHeader("Authorization", "Bearer "+d.AccessToken)
+	if callback != nil {
+		callback(req)
+	}
+	req.SetResult(resp)
+	_, err := req.Post(d.apiURL() + "/" + method)
+	if err != nil {
+		return err
+	}
+	if code, msg := resp.result(); code != 0 {
+		return fmt.Errorf("%s failed: [%d] %s", method, code, msg)
+	}
+	return nil
+}
+
+func (r *Resp) result() (int, string) {
+	return r.Result, r.Error
+}
+
+func (d *PCloud) getFiles(folderID string) ([]File, error) {
+	var resp MetadataResp
+	err := d.request("listfolder", func(req *resty.Request) {
+		req.SetQueryParam("folderid", folderID)
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Metadata.Contents, nil
+}
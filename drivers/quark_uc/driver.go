@@ -3,6 +3,7 @@ package quark
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"hash"
 	"io"
 	"net/http"
@@ -126,6 +127,25 @@ func (d *QuarkOrUC) Remove(ctx context.Context, obj model.Obj) error {
 	return err
 }
 
+func (d *QuarkOrUC) PutRapid(ctx context.Context, dstDir model.Obj, stream model.FileStreamer) (model.Obj, error) {
+	md5Str, sha1Str := stream.GetHash().GetHash(utils.MD5), stream.GetHash().GetHash(utils.SHA1)
+	if len(md5Str) != utils.MD5.Width || len(sha1Str) != utils.SHA1.Width {
+		return nil, errs.NotSupport
+	}
+	pre, err := d.upPre(stream, dstDir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	finish, err := d.upHash(md5Str, sha1Str, pre.Data.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	if !finish {
+		return nil, errors.New("rapid upload fail")
+	}
+	return nil, nil
+}
+
 func (d *QuarkOrUC) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
 	md5Str, sha1Str := stream.GetHash().GetHash(utils.MD5), stream.GetHash().GetHash(utils.SHA1)
 	var (
@@ -233,3 +253,4 @@ func (d *QuarkOrUC) GetDetails(ctx context.Context) (*model.StorageDetails, erro
 }
 
 var _ driver.Driver = (*QuarkOrUC)(nil)
+var _ driver.PutRapid = (*QuarkOrUC)(nil)
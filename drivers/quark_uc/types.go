@@ -1,6 +1,9 @@
 package quark
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
@@ -39,6 +42,9 @@ type File struct {
 	File      bool  `json:"file"`
 	CreatedAt int64 `json:"created_at"`
 	UpdatedAt int64 `json:"updated_at"`
+	// the hashes of the content, used to copy the file to another account by rapid upload
+	Md5  string `json:"md5"`
+	Sha1 string `json:"sha1"`
 	// PrivateExtra struct {} `json:"_private_extra"`
 	// ObjCategory string `json:"obj_category,omitempty"`
 	// Thumbnail string `json:"thumbnail,omitempty"`
@@ -76,7 +82,27 @@ func (f *File) IsDir() bool {
 }
 
 func (f *File) GetHash() utils.HashInfo {
-	return utils.HashInfo{}
+	hashes := make(map[*utils.HashType]string)
+	for ht, sum := range map[*utils.HashType]string{utils.MD5: f.Md5, utils.SHA1: f.Sha1} {
+		if sum = hexHash(sum, ht); sum != "" {
+			hashes[ht] = sum
+		}
+	}
+	return utils.NewHashInfoByMap(hashes)
+}
+
+// hexHash returns the hash of the api, given in hex or base64, in hex, empty if it's not a hash of ht
+func hexHash(sum string, ht *utils.HashType) string {
+	if len(sum) == ht.Width {
+		if _, err := hex.DecodeString(sum); err == nil {
+			return strings.ToLower(sum)
+		}
+		return ""
+	}
+	if b, err := base64.StdEncoding.DecodeString(sum); err == nil && len(b)*2 == ht.Width {
+		return hex.EncodeToString(b)
+	}
+	return ""
 }
 
 func (f *File) GetID() string {
@@ -0,0 +1,37 @@
+package quark
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+func TestFileHash(t *testing.T) {
+	const (
+		md5  = "0cc175b9c0f1b6a831c399e269772661"
+		sha1 = "86f7e437faa5a7fce15d1ddcb9eaeaea377667b8"
+	)
+	var resp SortResp
+	body := `{"data":{"list":[
+		{"fid":"1","file_name":"a.txt","file":true,"md5":"` + md5 + `","sha1":"` + sha1 + `"},
+		{"fid":"2","file_name":"b.txt","file":true,"md5":"DMF1ucDxtqgxw5niaXcmYQ==","sha1":"not a hash"}
+	]}}`
+	if err := utils.Json.UnmarshalFromString(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		md5, sha1 string
+	}{
+		{md5, sha1},
+		{md5, ""},
+	}
+	for i, tt := range tests {
+		hash := resp.Data.List[i].GetHash()
+		if got := hash.GetHash(utils.MD5); got != tt.md5 {
+			t.Errorf("md5 of %s = %q, want %q", resp.Data.List[i].FileName, got, tt.md5)
+		}
+		if got := hash.GetHash(utils.SHA1); got != tt.sha1 {
+			t.Errorf("sha1 of %s = %q, want %q", resp.Data.List[i].FileName, got, tt.sha1)
+		}
+	}
+}
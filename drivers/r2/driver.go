@@ -0,0 +1,73 @@
+package r2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/s3"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+)
+
+// R2 is the S3 driver preset for Cloudflare R2, which links the files of a public bucket by its public URL
+// and the others by presigned URLs, so the downloads never pass through OpenList unless it's configured to proxy
+type R2 struct {
+	model.Storage
+	Addition
+	*s3.S3
+}
+
+func (d *R2) Config() driver.Config {
+	return config
+}
+
+func (d *R2) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *R2) Init(ctx context.Context) error {
+	d.PublicURL = strings.TrimSuffix(d.PublicURL, "/")
+	d.S3 = &s3.S3{
+		Storage: d.Storage,
+		Addition: s3.Addition{
+			RootPath:                 d.RootPath,
+			Bucket:                   d.Bucket,
+			Endpoint:                 d.endpoint(),
+			Region:                   "auto",
+			AccessKeyID:              d.AccessKeyID,
+			SecretAccessKey:          d.SecretAccessKey,
+			SignURLExpire:            d.SignURLExpire,
+			Placeholder:              d.Placeholder,
+			ListObjectVersion:        "v2",
+			AddFilenameToDisposition: d.AddFilenameToDisposition,
+			EnableDirectUpload:       d.EnableDirectUpload,
+		},
+	}
+	return d.S3.Init(ctx)
+}
+
+func (d *R2) endpoint() string {
+	if d.Jurisdiction == "" || d.Jurisdiction == "default" {
+		return fmt.Sprintf("https://%s.r2.cloudflarestorage.com", d.AccountID)
+	}
+	return fmt.Sprintf("https://%s.%s.r2.cloudflarestorage.com", d.AccountID, d.Jurisdiction)
+}
+
+func (d *R2) Drop(ctx context.Context) error {
+	if d.S3 == nil {
+		return nil
+	}
+	return d.S3.Drop(ctx)
+}
+
+func (d *R2) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	if d.PublicURL != "" && !common.ShouldProxy(d, file.GetName()) {
+		return &model.Link{URL: d.PublicURL + utils.EncodePath(file.GetPath(), true)}, nil
+	}
+	return d.S3.Link(ctx, file, args)
+}
+
+var _ driver.Driver = (*R2)(nil)
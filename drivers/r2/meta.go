@@ -0,0 +1,33 @@
+package r2
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	driver.RootPath
+	AccountID                string `json:"account_id" required:"true"`
+	Jurisdiction             string `json:"jurisdiction" type:"select" options:"default,eu,fedramp" default:"default" help:"The jurisdiction the bucket is created in."`
+	Bucket                   string `json:"bucket" required:"true"`
+	AccessKeyID              string `json:"access_key_id" required:"true"`
+	SecretAccessKey          string `json:"secret_access_key" required:"true"`
+	PublicURL                string `json:"public_url" help:"The r2.dev or custom domain of a public bucket, files are linked by it without presigning."`
+	SignURLExpire            int    `json:"sign_url_expire" type:"number" default:"4"`
+	Placeholder              string `json:"placeholder"`
+	AddFilenameToDisposition bool   `json:"add_filename_to_disposition" help:"Add filename to Content-Disposition header."`
+	EnableDirectUpload       bool   `json:"enable_direct_upload" default:"false"`
+}
+
+var config = driver.Config{
+	Name:        "Cloudflare R2",
+	DefaultRoot: "/",
+	LocalSort:   true,
+	CheckStatus: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &R2{}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	stdpath "path"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -91,6 +93,9 @@ func (d *S3) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*mo
 		Key:    &path,
 		//ResponseContentDisposition: &disposition,
 	}
+	if v, ok := file.(*versionObj); ok {
+		input.VersionId = &v.VersionID
+	}
 
 	if d.CustomHost == "" {
 		disposition := fmt.Sprintf(`attachment; filename*=UTF-8''%s`, url.PathEscape(fileName))
@@ -214,6 +219,34 @@ func (d *S3) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up
 	return err
 }
 
+// AppendPut rewrites the object with the data appended if it's too small to be a part of a multipart upload,
+// otherwise composes the new object by a multipart upload copying the object as the first parts
+func (d *S3) AppendPut(ctx context.Context, obj model.Obj, data io.Reader, size int64) error {
+	if size == 0 {
+		return nil
+	}
+	key := getKey(obj.GetPath(), false)
+	head, err := d.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: &d.Bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return err
+	}
+	if aws.Int64Value(head.ContentLength) < minPartSize {
+		return d.rewriteAppend(ctx, key, head.ContentType, data)
+	}
+	return d.composeAppend(ctx, key, head.ContentType, aws.Int64Value(head.ContentLength), data, size)
+}
+
+func (d *S3) ListAt(ctx context.Context, dirPath string, at time.Time) ([]model.Obj, error) {
+	return d.listAt(stdpath.Join(d.GetRootPath(), dirPath), at)
+}
+
+func (d *S3) LinkAt(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	return d.Link(ctx, file, args)
+}
+
 func (d *S3) GetDirectUploadTools() []string {
 	if !d.EnableDirectUpload {
 		return nil
@@ -244,3 +277,4 @@ func (d *S3) GetDirectUploadInfo(ctx context.Context, _ string, dstDir model.Obj
 }
 
 var _ driver.Driver = (*S3)(nil)
+var _ driver.AppendPut = (*S3)(nil)
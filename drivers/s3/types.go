@@ -1 +1,9 @@
 package s3
+
+import "github.com/OpenListTeam/OpenList/v4/internal/model"
+
+// versionObj is a revision of an object listed by ListAt
+type versionObj struct {
+	model.Object
+	VersionID string
+}
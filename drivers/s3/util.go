@@ -3,11 +3,16 @@ package s3
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
@@ -16,6 +21,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -207,6 +213,91 @@ func (d *S3) listV2(dirPath string, args model.ListArgs) ([]model.Obj, error) {
 	return files, nil
 }
 
+// listAt lists the latest versions of the objects in dirPath before the time at,
+// it works only for the buckets with versioning enabled
+func (d *S3) listAt(dirPath string, at time.Time) ([]model.Obj, error) {
+	prefix := getKey(dirPath, true)
+	type latest struct {
+		version  *s3.ObjectVersion // nil for a delete marker
+		modified time.Time
+	}
+	files := make([]model.Obj, 0)
+	dirs := make(map[string]struct{})
+	versions := make(map[string]*latest)
+	var keys []string
+	update := func(key string, modified time.Time, version *s3.ObjectVersion) {
+		if modified.After(at) {
+			return
+		}
+		cur, ok := versions[key]
+		if !ok {
+			keys = append(keys, key)
+		} else if !modified.After(cur.modified) {
+			return
+		}
+		versions[key] = &latest{version: version, modified: modified}
+	}
+	var keyMarker, versionIdMarker *string
+	for {
+		res, err := d.client.ListObjectVersions(&s3.ListObjectVersionsInput{
+			Bucket:          &d.Bucket,
+			Prefix:          &prefix,
+			Delimiter:       aws.String("/"),
+			KeyMarker:       keyMarker,
+			VersionIdMarker: versionIdMarker,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range res.CommonPrefixes {
+			name := path.Base(strings.Trim(*object.Prefix, "/"))
+			if _, ok := dirs[name]; ok {
+				continue
+			}
+			dirs[name] = struct{}{}
+			files = append(files, &model.Object{
+				Path:     path.Join(dirPath, name),
+				Name:     name,
+				Modified: d.Modified,
+				IsFolder: true,
+			})
+		}
+		for _, version := range res.Versions {
+			if strings.HasSuffix(*version.Key, "/") {
+				continue
+			}
+			update(*version.Key, aws.TimeValue(version.LastModified), version)
+		}
+		for _, marker := range res.DeleteMarkers {
+			update(*marker.Key, aws.TimeValue(marker.LastModified), nil)
+		}
+		if !aws.BoolValue(res.IsTruncated) {
+			break
+		}
+		keyMarker, versionIdMarker = res.NextKeyMarker, res.NextVersionIdMarker
+	}
+	for _, key := range keys {
+		v := versions[key]
+		if v.version == nil {
+			continue
+		}
+		name := path.Base(key)
+		if name == getPlaceholderName(d.Placeholder) || name == d.Placeholder {
+			continue
+		}
+		files = append(files, &versionObj{
+			Object: model.Object{
+				Path:     path.Join(dirPath, name),
+				Name:     name,
+				Size:     aws.Int64Value(v.version.Size),
+				Modified: v.modified,
+			},
+			VersionID: aws.StringValue(v.version.VersionId),
+		})
+	}
+	return files, nil
+}
+
 func (d *S3) copy(ctx context.Context, src string, dst string, isDir bool) error {
 	if isDir {
 		return d.copyDir(ctx, src, dst)
@@ -277,3 +368,104 @@ func (d *S3) removeFile(src string) error {
 	_, err := d.client.DeleteObject(input)
 	return err
 }
+
+const (
+	// minPartSize is the min size of the parts of a multipart upload except the last one
+	minPartSize = 5 * 1024 * 1024
+	// maxPartSize is the max size of a part of a multipart upload
+	maxPartSize = 5 * 1024 * 1024 * 1024
+)
+
+// rewriteAppend puts the object of key again with the data appended
+func (d *S3) rewriteAppend(ctx context.Context, key string, contentType *string, data io.Reader) error {
+	old, err := d.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: &d.Bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return err
+	}
+	defer old.Body.Close()
+	uploader := s3manager.NewUploader(d.Session)
+	_, err = uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      &d.Bucket,
+		Key:         &key,
+		Body:        io.MultiReader(old.Body, driver.NewLimitedUploadStream(ctx, data)),
+		ContentType: contentType,
+	})
+	return err
+}
+
+// composeAppend makes the object of key and oldSize with the data of size appended by a multipart upload,
+// of which the first parts are copied from the object at the server side
+func (d *S3) composeAppend(ctx context.Context, key string, contentType *string, oldSize int64, data io.Reader, size int64) error {
+	// the bodies of the parts have to be seekable
+	f, err := utils.CreateTempFile(io.LimitReader(driver.NewLimitedUploadStream(ctx, data), size), size)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	upload, err := d.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      &d.Bucket,
+		Key:         &key,
+		ContentType: contentType,
+	})
+	if err != nil {
+		return err
+	}
+	var parts []*s3.CompletedPart
+	err = func() error {
+		source := strings.ReplaceAll(url.PathEscape(d.Bucket+"/"+key), "+", "%2B")
+		n := (oldSize + maxPartSize - 1) / maxPartSize
+		partSize := (oldSize + n - 1) / n
+		for start := int64(0); start < oldSize; start += partSize {
+			num := int64(len(parts) + 1)
+			res, err := d.client.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+				Bucket:          &d.Bucket,
+				Key:             &key,
+				UploadId:        upload.UploadId,
+				PartNumber:      &num,
+				CopySource:      aws.String(source),
+				CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, min(start+partSize, oldSize)-1)),
+			})
+			if err != nil {
+				return err
+			}
+			parts = append(parts, &s3.CompletedPart{ETag: res.CopyPartResult.ETag, PartNumber: &num})
+		}
+		for start := int64(0); start < size; start += maxPartSize {
+			num := int64(len(parts) + 1)
+			length := min(maxPartSize, size-start)
+			res, err := d.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+				Bucket:        &d.Bucket,
+				Key:           &key,
+				UploadId:      upload.UploadId,
+				PartNumber:    &num,
+				Body:          io.NewSectionReader(f, start, length),
+				ContentLength: &length,
+			})
+			if err != nil {
+				return err
+			}
+			parts = append(parts, &s3.CompletedPart{ETag: res.ETag, PartNumber: &num})
+		}
+		_, err := d.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          &d.Bucket,
+			Key:             &key,
+			UploadId:        upload.UploadId,
+			MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+		})
+		return err
+	}()
+	if err != nil {
+		_, _ = d.client.AbortMultipartUploadWithContext(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   &d.Bucket,
+			Key:      &key,
+			UploadId: upload.UploadId,
+		})
+	}
+	return err
+}
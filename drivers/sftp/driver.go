@@ -1,18 +1,23 @@
 package sftp
 
 import (
+	"archive/tar"
 	"context"
+	"io"
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/delta"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/pkg/sftp"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
 )
 
 type SFTP struct {
@@ -20,6 +25,9 @@ type SFTP struct {
 	Addition
 	client                *sftp.Client
 	clientConnectionError error
+	// conn is the ssh connection of the client, remoteTar is whether the server can extract tar streams by itself
+	conn      *ssh.Client
+	remoteTar bool
 }
 
 func (d *SFTP) Config() driver.Config {
@@ -119,10 +127,115 @@ func (d *SFTP) Put(ctx context.Context, dstDir model.Obj, stream model.FileStrea
 	defer func() {
 		_ = dstFile.Close()
 	}()
+	if d.SparseFiles {
+		w := utils.NewSparseWriter(dstFile)
+		err = utils.CopyWithCtx(ctx, w, driver.NewLimitedUploadStream(ctx, stream), stream.GetSize(), up)
+		if err == nil {
+			err = w.Close()
+		}
+		return err
+	}
 	err = utils.CopyWithCtx(ctx, dstFile, driver.NewLimitedUploadStream(ctx, stream), stream.GetSize(), up)
 	return err
 }
 
+func (d *SFTP) PutTar(ctx context.Context, dstDir model.Obj, tarStream io.Reader) error {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return err
+	}
+	if d.remoteTar {
+		return d.extractTar(ctx, dstDir.GetPath(), tarStream)
+	}
+	// the server can't run tar, the files are created one by one
+	return readTar(tarStream, func(hdr *tar.Header, r io.Reader) error {
+		fullPath := path.Join(dstDir.GetPath(), hdr.Name)
+		dstFile, err := d.client.Create(fullPath)
+		if err != nil {
+			return err
+		}
+		err = utils.CopyWithCtx(ctx, dstFile, driver.NewLimitedUploadStream(ctx, r), hdr.Size, func(float64) {})
+		_ = dstFile.Close()
+		if err != nil {
+			return err
+		}
+		if err = d.client.Chtimes(fullPath, hdr.ModTime, hdr.ModTime); err != nil {
+			log.Errorf("[sftp] failed to change time of %s: %s", fullPath, err)
+		}
+		return nil
+	})
+}
+
+func (d *SFTP) PutDelta(ctx context.Context, obj model.Obj, ops []delta.Op, data io.Reader, modified time.Time) error {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return err
+	}
+	fullPath := obj.GetPath()
+	old, err := d.client.Open(fullPath)
+	if err != nil {
+		return err
+	}
+	defer old.Close()
+	tmpPath := path.Join(path.Dir(fullPath), "."+path.Base(fullPath)+".delta")
+	out, err := d.client.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	err = delta.Apply(out, old, ops, driver.NewLimitedUploadStream(ctx, data))
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = d.client.PosixRename(tmpPath, fullPath)
+		if err != nil {
+			// the server doesn't support the posix-rename extension
+			if err = d.client.Remove(fullPath); err == nil {
+				err = d.client.Rename(tmpPath, fullPath)
+			}
+		}
+	}
+	if err != nil {
+		_ = d.client.Remove(tmpPath)
+		return err
+	}
+	if err = d.client.Chtimes(fullPath, modified, modified); err != nil {
+		log.Errorf("[sftp] failed to change time of %s: %s", fullPath, err)
+	}
+	return nil
+}
+
+func (d *SFTP) AppendPut(ctx context.Context, obj model.Obj, data io.Reader, size int64) error {
+	return d.writeAt(ctx, obj.GetPath(), -1, data, size)
+}
+
+func (d *SFTP) WriteRange(ctx context.Context, obj model.Obj, offset int64, data io.Reader, size int64) error {
+	return d.writeAt(ctx, obj.GetPath(), offset, data, size)
+}
+
+// writeAt writes the data of size at offset of the file in place, a negative offset appends it.
+// The end of the file is got by stat as the servers handle the append flag differently
+func (d *SFTP) writeAt(ctx context.Context, fullPath string, offset int64, data io.Reader, size int64) error {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return err
+	}
+	f, err := d.client.OpenFile(fullPath, os.O_WRONLY)
+	if err != nil {
+		return err
+	}
+	if offset < 0 {
+		var info os.FileInfo
+		if info, err = f.Stat(); err != nil {
+			_ = f.Close()
+			return err
+		}
+		offset = info.Size()
+	}
+	_, err = io.CopyN(io.NewOffsetWriter(f, offset), driver.NewLimitedUploadStream(ctx, data), size)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
 func (d *SFTP) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
 	stat, err := d.client.StatVFS(d.RootFolderPath)
 	if err != nil {
@@ -142,3 +255,7 @@ func (d *SFTP) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
 }
 
 var _ driver.Driver = (*SFTP)(nil)
+var _ driver.PutTar = (*SFTP)(nil)
+var _ driver.PutDelta = (*SFTP)(nil)
+var _ driver.AppendPut = (*SFTP)(nil)
+var _ driver.WriteRange = (*SFTP)(nil)
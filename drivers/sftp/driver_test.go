@@ -0,0 +1,148 @@
+package sftp
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// serveSSH serves the sftp subsystem and the exec of the commands by sh, like an ssh server having a shell
+func serveSSH(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChan := range chans {
+					ch, reqs, err := newChan.Accept()
+					if err != nil {
+						continue
+					}
+					go serveSession(ch, reqs)
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func serveSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	for req := range reqs {
+		switch req.Type {
+		case "subsystem":
+			_ = req.Reply(true, nil)
+			server, err := sftp.NewServer(ch)
+			if err == nil {
+				_ = server.Serve()
+			}
+			return
+		case "exec":
+			_ = req.Reply(true, nil)
+			cmd := exec.Command("sh", "-c", string(req.Payload[4:]))
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = ch, ch, ch.Stderr()
+			status := 0
+			if err := cmd.Run(); err != nil {
+				status = 1
+			}
+			_, _ = ch.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, uint32(status)))
+			return
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+func TestPutTar(t *testing.T) {
+	if _, err := exec.LookPath("tar"); err != nil {
+		t.Skip("tar is not installed")
+	}
+	d := &SFTP{Addition: Addition{Address: serveSSH(t), Username: "test"}}
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Drop(context.Background())
+	if !d.remoteTar {
+		t.Fatal("expected the server to run tar")
+	}
+
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"a.txt", "b c.txt"} {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(name)), Mode: 0o644, ModTime: modTime}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "it's")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.PutTar(context.Background(), &model.Object{Path: filepath.ToSlash(dir), IsFolder: true}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b c.txt"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != int64(len(name)) || !info.ModTime().Equal(modTime) {
+			t.Errorf("%s: size %d, modified %v", name, info.Size(), info.ModTime())
+		}
+	}
+
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	_ = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "../escape.txt", Mode: 0o644})
+	_ = tw.Close()
+	if err := d.PutTar(context.Background(), &model.Object{Path: filepath.ToSlash(dir), IsFolder: true}, &buf); !errors.Is(err, errs.RelativePath) {
+		t.Errorf("expected the relative path to fail, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.txt")); err == nil {
+		t.Error("the relative path is extracted")
+	}
+}
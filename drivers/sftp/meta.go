@@ -13,6 +13,7 @@ type Addition struct {
 	Passphrase string `json:"passphrase"`
 	driver.RootPath
 	IgnoreSymlinkError bool `json:"ignore_symlink_error" default:"false" info:"Ignore symlink error"`
+	SparseFiles        bool `json:"sparse_files" default:"false" help:"skip sending the blocks full of zeros of uploaded files, saves traffic and space for disk images"`
 }
 
 var config = driver.Config{
@@ -1,10 +1,19 @@
 package sftp
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"path"
+	"strings"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/pkg/singleflight"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
 	"github.com/pkg/sftp"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
@@ -46,6 +55,8 @@ func (d *SFTP) _initClient() error {
 	}
 	d.client, err = sftp.NewClient(conn)
 	if err == nil {
+		d.conn = conn
+		d.remoteTar = hasRemoteTar(conn)
 		d.clientConnectionError = nil
 		go func(d *SFTP) {
 			d.clientConnectionError = d.client.Wait()
@@ -54,6 +65,98 @@ func (d *SFTP) _initClient() error {
 	return err
 }
 
+// hasRemoteTar reports whether tar can be run by the ssh server, which isn't the case of the sftp only servers
+func hasRemoteTar(conn *ssh.Client) bool {
+	session, err := conn.NewSession()
+	if err != nil {
+		return false
+	}
+	defer session.Close()
+	return session.Run("tar --version") == nil
+}
+
+// extractTar extracts the tar stream into dir by the tar of the server, so that the files aren't created
+// one by one over sftp. The entries are checked and written again as readTar does, so that only the regular
+// files of plain names are extracted
+func (d *SFTP) extractTar(ctx context.Context, dir string, tarStream io.Reader) error {
+	session, err := d.conn.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	pr, pw := io.Pipe()
+	readErr := make(chan error, 1)
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := readTar(tarStream, func(hdr *tar.Header, r io.Reader) error {
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     hdr.Name,
+				Size:     hdr.Size,
+				Mode:     0o644,
+				ModTime:  hdr.ModTime,
+			}); err != nil {
+				return err
+			}
+			_, err := utils.CopyWithBuffer(tw, r)
+			return err
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		_ = pw.CloseWithError(err)
+		readErr <- err
+	}()
+	session.Stdin = driver.NewLimitedUploadStream(ctx, pr)
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+	stop := context.AfterFunc(ctx, func() {
+		_ = session.Close()
+	})
+	defer stop()
+	err = session.Run("tar -x -f - -C " + shellQuote(dir))
+	// the writing of the tar fails by err too if the server stops reading it
+	_ = pr.CloseWithError(err)
+	readE := <-readErr
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if readE != nil && !errors.Is(readE, err) {
+			return readE
+		}
+		return errors.Wrapf(err, "failed extract the tar into %s: %s", dir, strings.TrimSpace(stderr.String()))
+	}
+	return readE
+}
+
+// readTar calls put with the regular files of the tar stream, whose names must be plain file names
+func readTar(tarStream io.Reader, put func(hdr *tar.Header, r io.Reader) error) error {
+	tr := tar.NewReader(tarStream)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Name != path.Base(hdr.Name) || hdr.Name == "." || hdr.Name == ".." {
+			return errs.RelativePath
+		}
+		if err = put(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 func (d *SFTP) clientReconnectOnConnectionError() error {
 	err := d.clientConnectionError
 	if err == nil {
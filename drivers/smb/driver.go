@@ -3,14 +3,19 @@ package smb
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/delta"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/cloudsoda/go-smb2"
 )
@@ -201,6 +206,78 @@ func (d *SMB) Put(ctx context.Context, dstDir model.Obj, stream model.FileStream
 	return nil
 }
 
+func (d *SMB) PutDelta(ctx context.Context, obj model.Obj, ops []delta.Op, data io.Reader, modified time.Time) error {
+	if err := d.checkConn(ctx); err != nil {
+		return err
+	}
+	fullPath := obj.GetPath()
+	old, err := d.fs.Open(fullPath)
+	if err != nil {
+		d.cleanLastConnTime()
+		return err
+	}
+	d.updateLastConnTime()
+	tmpPath := filepath.Join(filepath.Dir(fullPath), "."+filepath.Base(fullPath)+".delta")
+	out, err := d.fs.Create(tmpPath)
+	if err != nil {
+		_ = old.Close()
+		return err
+	}
+	err = delta.Apply(out, old, ops, driver.NewLimitedUploadStream(ctx, data))
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	// the existing file can't be removed while it's open, nor renamed over
+	_ = old.Close()
+	if err == nil {
+		if err = d.fs.Remove(fullPath); err == nil {
+			err = d.fs.Rename(tmpPath, fullPath)
+		}
+	}
+	if err != nil {
+		_ = d.fs.Remove(tmpPath)
+		return err
+	}
+	if err = d.fs.Chtimes(fullPath, modified, modified); err != nil {
+		log.Errorf("[smb] failed to change time of %s: %s", fullPath, err)
+	}
+	return nil
+}
+
+func (d *SMB) AppendPut(ctx context.Context, obj model.Obj, data io.Reader, size int64) error {
+	return d.writeAt(ctx, obj.GetPath(), -1, data, size)
+}
+
+func (d *SMB) WriteRange(ctx context.Context, obj model.Obj, offset int64, data io.Reader, size int64) error {
+	return d.writeAt(ctx, obj.GetPath(), offset, data, size)
+}
+
+// writeAt writes the data of size at offset of the file in place, a negative offset appends it
+func (d *SMB) writeAt(ctx context.Context, fullPath string, offset int64, data io.Reader, size int64) error {
+	if err := d.checkConn(ctx); err != nil {
+		return err
+	}
+	f, err := d.fs.OpenFile(fullPath, os.O_WRONLY, 0)
+	if err != nil {
+		d.cleanLastConnTime()
+		return err
+	}
+	d.updateLastConnTime()
+	if offset < 0 {
+		var info os.FileInfo
+		if info, err = f.Stat(); err != nil {
+			_ = f.Close()
+			return err
+		}
+		offset = info.Size()
+	}
+	_, err = io.CopyN(io.NewOffsetWriter(f, offset), driver.NewLimitedUploadStream(ctx, data), size)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
 func (d *SMB) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
 	if err := d.checkConn(ctx); err != nil {
 		return nil, err
@@ -224,3 +301,6 @@ func (d *SMB) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
 //}
 
 var _ driver.Driver = (*SMB)(nil)
+var _ driver.PutDelta = (*SMB)(nil)
+var _ driver.AppendPut = (*SMB)(nil)
+var _ driver.WriteRange = (*SMB)(nil)
@@ -0,0 +1,103 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+type Snapshot struct {
+	model.Storage
+	Addition
+	at time.Time
+}
+
+func (d *Snapshot) Config() driver.Config {
+	return config
+}
+
+func (d *Snapshot) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Snapshot) Init(ctx context.Context) error {
+	d.Path = utils.FixAndCleanPath(d.Path)
+	at, err := time.ParseInLocation(time.DateTime, d.At, time.Local)
+	if err != nil {
+		if at, err = time.Parse(time.RFC3339, d.At); err != nil {
+			return fmt.Errorf("invalid snapshot time: %s", d.At)
+		}
+	}
+	if at.After(time.Now()) {
+		return fmt.Errorf("the snapshot time %s is in the future", d.At)
+	}
+	d.at = at
+	return nil
+}
+
+func (d *Snapshot) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (Addition) GetRootPath() string {
+	return "/"
+}
+
+// remote returns the storage keeping the revisions and the actual path of path in it
+func (d *Snapshot) remote(path string) (driver.Snapshot, string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(d.Path, path))
+	if err != nil {
+		return nil, "", err
+	}
+	s, ok := storage.(driver.Snapshot)
+	if !ok {
+		return nil, "", fmt.Errorf("the storage of [%s] doesn't keep the revisions of files", d.Path)
+	}
+	return s, actualPath, nil
+}
+
+func (d *Snapshot) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	s, actualPath, err := d.remote(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	objs, err := s.ListAt(ctx, actualPath, d.at)
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(objs, func(obj model.Obj) (model.Obj, error) {
+		return &snapshotObj{
+			Object: model.Object{
+				Path:     stdpath.Join(dir.GetPath(), obj.GetName()),
+				Name:     obj.GetName(),
+				Size:     obj.GetSize(),
+				Modified: obj.ModTime(),
+				Ctime:    obj.CreateTime(),
+				IsFolder: obj.IsDir(),
+				HashInfo: obj.GetHash(),
+			},
+			remote: obj,
+		}, nil
+	})
+}
+
+func (d *Snapshot) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	obj, ok := file.(*snapshotObj)
+	if !ok {
+		return nil, errs.NotFile
+	}
+	s, _, err := d.remote(obj.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return s.LinkAt(ctx, obj.remote, args)
+}
+
+var _ driver.Driver = (*Snapshot)(nil)
@@ -0,0 +1,24 @@
+package snapshot
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	Path string `json:"path" required:"true" help:"the path in a storage keeping the revisions of files, e.g. a S3 bucket with versioning enabled"`
+	At   string `json:"at" required:"true" help:"the time of the snapshot, 2006-01-02 15:04:05 in the server time zone or RFC3339"`
+}
+
+var config = driver.Config{
+	Name:        "Snapshot",
+	LocalSort:   true,
+	NoUpload:    true,
+	DefaultRoot: "/",
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Snapshot{}
+	})
+}
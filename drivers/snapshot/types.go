@@ -0,0 +1,9 @@
+package snapshot
+
+import "github.com/OpenListTeam/OpenList/v4/internal/model"
+
+// snapshotObj is a revision of an object, Path is relative to the snapshot root
+type snapshotObj struct {
+	model.Object
+	remote model.Obj // the object returned by the remote storage
+}
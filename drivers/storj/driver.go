@@ -0,0 +1,227 @@
+package storj
+
+import (
+	"bytes"
+	"context"
+	"io"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/errgroup"
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/avast/retry-go"
+	"github.com/pkg/errors"
+	"storj.io/uplink"
+)
+
+type Storj struct {
+	model.Storage
+	Addition
+	project *uplink.Project
+}
+
+func (d *Storj) Config() driver.Config {
+	return config
+}
+
+func (d *Storj) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Storj) Init(ctx context.Context) error {
+	access, err := uplink.ParseAccess(strings.TrimSpace(d.AccessGrant))
+	if err != nil {
+		return errors.Wrap(err, "invalid access grant")
+	}
+	d.project, err = uplink.OpenProject(ctx, access)
+	if err != nil {
+		return errors.Wrap(err, "failed open project")
+	}
+	if _, err = d.project.StatBucket(ctx, d.Bucket); err != nil {
+		return errors.Wrapf(err, "failed stat bucket %s", d.Bucket)
+	}
+	return nil
+}
+
+func (d *Storj) Drop(ctx context.Context) error {
+	if d.project != nil {
+		return d.project.Close()
+	}
+	return nil
+}
+
+func (d *Storj) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	prefix := getKey(dir.GetPath(), true)
+	it := d.project.ListObjects(ctx, d.Bucket, &uplink.ListObjectsOptions{
+		Prefix: prefix,
+		System: true,
+	})
+	var res []model.Obj
+	for it.Next() {
+		item := it.Item()
+		name := strings.TrimSuffix(strings.TrimPrefix(item.Key, prefix), "/")
+		if name == "" || (!item.IsPrefix && name == d.placeholderName()) {
+			continue
+		}
+		res = append(res, &model.Object{
+			Path:     stdpath.Join(dir.GetPath(), name),
+			Name:     name,
+			Size:     item.System.ContentLength,
+			Modified: item.System.Created,
+			IsFolder: item.IsPrefix,
+		})
+	}
+	return res, errors.WithStack(it.Err())
+}
+
+func (d *Storj) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	key := getKey(file.GetPath(), false)
+	size := file.GetSize()
+	rangeReader := func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		length := httpRange.Length
+		if length < 0 || httpRange.Start+length > size {
+			length = size - httpRange.Start
+		}
+		download, err := d.project.DownloadObject(ctx, d.Bucket, key, &uplink.DownloadOptions{
+			Offset: httpRange.Start,
+			Length: length,
+		})
+		return download, errors.WithStack(err)
+	}
+	// the parts are fetched from the storage nodes in parallel
+	return &model.Link{
+		RangeReader: stream.RateLimitRangeReaderFunc(rangeReader),
+		Concurrency: max(d.DownloadThread, 1),
+		PartSize:    int(d.partSize()),
+	}, nil
+}
+
+func (d *Storj) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return d.Put(ctx, &model.Object{
+		Path: stdpath.Join(parentDir.GetPath(), dirName),
+	}, &stream.FileStream{
+		Obj: &model.Object{
+			Name:     d.placeholderName(),
+			Modified: time.Now(),
+		},
+		Reader: bytes.NewReader([]byte{}),
+	}, func(float64) {})
+}
+
+func (d *Storj) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.transfer(ctx, srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()), srcObj.IsDir(), true)
+}
+
+func (d *Storj) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.transfer(ctx, srcObj.GetPath(), stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName), srcObj.IsDir(), true)
+}
+
+func (d *Storj) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.transfer(ctx, srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()), srcObj.IsDir(), false)
+}
+
+func (d *Storj) Remove(ctx context.Context, obj model.Obj) error {
+	if !obj.IsDir() {
+		_, err := d.project.DeleteObject(ctx, d.Bucket, getKey(obj.GetPath(), false))
+		return errors.WithStack(err)
+	}
+	return d.walkKeys(ctx, getKey(obj.GetPath(), true), func(key string) error {
+		_, err := d.project.DeleteObject(ctx, d.Bucket, key)
+		return errors.WithStack(err)
+	})
+}
+
+func (d *Storj) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	key := getKey(stdpath.Join(dstDir.GetPath(), s.GetName()), false)
+	partSize := d.partSize()
+	if s.GetSize() <= partSize {
+		upload, err := d.project.UploadObject(ctx, d.Bucket, key, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		_, err = utils.CopyWithBuffer(upload, driver.NewLimitedUploadStream(ctx, &driver.ReaderUpdatingProgress{
+			Reader:         s,
+			UpdateProgress: up,
+		}))
+		if err != nil {
+			_ = upload.Abort()
+			return err
+		}
+		return errors.WithStack(upload.Commit())
+	}
+	return d.putParts(ctx, key, s, partSize, up)
+}
+
+// putParts uploads the parts of the stream in parallel as a multipart upload
+func (d *Storj) putParts(ctx context.Context, key string, s model.FileStreamer, partSize int64, up driver.UpdateProgress) error {
+	info, err := d.project.BeginUpload(ctx, d.Bucket, key, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ss, err := stream.NewStreamSectionReader(s, int(partSize), &up)
+	if err != nil {
+		_ = d.project.AbortUpload(ctx, d.Bucket, key, info.UploadID)
+		return err
+	}
+	size := s.GetSize()
+	totalParts := (size + partSize - 1) / partSize
+	threadG, uploadCtx := errgroup.NewOrderedGroupWithContext(ctx, max(d.UploadThread, 1),
+		retry.Attempts(3),
+		retry.Delay(time.Second),
+		retry.DelayType(retry.BackOffDelay))
+	for i := range totalParts {
+		if utils.IsCanceled(uploadCtx) {
+			break
+		}
+		offset := i * partSize
+		length := min(partSize, size-offset)
+		partNumber := uint32(i + 1)
+		var reader io.ReadSeeker
+		threadG.GoWithLifecycle(errgroup.Lifecycle{
+			Before: func(ctx context.Context) (err error) {
+				reader, err = ss.GetSectionReader(offset, length)
+				return
+			},
+			Do: func(ctx context.Context) error {
+				if _, err := reader.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+				part, err := d.project.UploadPart(ctx, d.Bucket, key, info.UploadID, partNumber)
+				if err != nil {
+					return err
+				}
+				if _, err = utils.CopyWithBuffer(part, driver.NewLimitedUploadStream(ctx, reader)); err != nil {
+					_ = part.Abort()
+					return err
+				}
+				if err = part.Commit(); err != nil {
+					return err
+				}
+				up(100 * float64(threadG.Success()+1) / float64(totalParts))
+				return nil
+			},
+			After: func(err error) {
+				ss.FreeSectionReader(reader)
+			},
+		})
+	}
+	if err = threadG.Wait(); err != nil {
+		_ = d.project.AbortUpload(context.Background(), d.Bucket, key, info.UploadID)
+		return err
+	}
+	_, err = d.project.CommitUpload(ctx, d.Bucket, key, info.UploadID, nil)
+	return errors.WithStack(err)
+}
+
+var _ driver.Driver = (*Storj)(nil)
+var _ driver.Mkdir = (*Storj)(nil)
+var _ driver.Move = (*Storj)(nil)
+var _ driver.Rename = (*Storj)(nil)
+var _ driver.Copy = (*Storj)(nil)
+var _ driver.Remove = (*Storj)(nil)
+var _ driver.Put = (*Storj)(nil)
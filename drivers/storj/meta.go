@@ -0,0 +1,31 @@
+package storj
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	driver.RootPath
+	AccessGrant    string `json:"access_grant" type:"text" required:"true" help:"The serialized access grant, it carries the satellite address, the API key and the encryption passphrase."`
+	Bucket         string `json:"bucket" required:"true"`
+	Placeholder    string `json:"placeholder" help:"The name of the empty object keeping the new directories, .openlist by default."`
+	PartSize       int    `json:"part_size" type:"number" default:"64" help:"The size of the parts in MB, the default is the segment size of Storj."`
+	UploadThread   int    `json:"upload_thread" type:"number" default:"4" help:"The number of the parts uploaded in parallel."`
+	DownloadThread int    `json:"download_thread" type:"number" default:"4" help:"The number of the parts downloaded in parallel."`
+}
+
+var config = driver.Config{
+	Name:        "Storj",
+	DefaultRoot: "/",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoLinkURL:   true,
+	CheckStatus: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Storj{}
+	})
+}
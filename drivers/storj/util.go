@@ -0,0 +1,74 @@
+package storj
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"storj.io/uplink"
+)
+
+const defaultPlaceholderName = ".openlist"
+
+// getKey returns the object key of path, the keys of the directories end with a slash
+func getKey(path string, dir bool) string {
+	path = strings.TrimPrefix(path, "/")
+	if path != "" && dir {
+		path += "/"
+	}
+	return path
+}
+
+func (d *Storj) placeholderName() string {
+	if d.Placeholder == "" {
+		return defaultPlaceholderName
+	}
+	return d.Placeholder
+}
+
+func (d *Storj) partSize() int64 {
+	return int64(max(d.PartSize, 5)) * 1024 * 1024
+}
+
+// walkKeys calls fn with the keys of all the objects under the prefix
+func (d *Storj) walkKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	it := d.project.ListObjects(ctx, d.Bucket, &uplink.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+	for it.Next() {
+		if err := fn(it.Item().Key); err != nil {
+			return err
+		}
+	}
+	return errors.WithStack(it.Err())
+}
+
+// transfer moves or copies the object or all the objects of the directory at src to dst
+func (d *Storj) transfer(ctx context.Context, src, dst string, dir, move bool) error {
+	do := func(oldKey, newKey string) error {
+		if move {
+			return d.project.MoveObject(ctx, d.Bucket, oldKey, d.Bucket, newKey, nil)
+		}
+		_, err := d.project.CopyObject(ctx, d.Bucket, oldKey, d.Bucket, newKey, nil)
+		return err
+	}
+	if !dir {
+		return errors.WithStack(do(getKey(src, false), getKey(dst, false)))
+	}
+	srcPrefix, dstPrefix := getKey(src, true), getKey(dst, true)
+	// the keys are collected first, moving them while listing would skip some
+	var keys []string
+	if err := d.walkKeys(ctx, srcPrefix, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := do(key, dstPrefix+strings.TrimPrefix(key, srcPrefix)); err != nil {
+			return errors.Wrapf(err, "failed transfer %s", key)
+		}
+	}
+	return nil
+}
@@ -34,6 +34,7 @@ var config = driver.Config{
 	NoUpload:    true,
 	DefaultRoot: "/",
 	NoLinkURL:   true,
+	LocalFS:     true,
 }
 
 func init() {
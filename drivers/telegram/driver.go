@@ -0,0 +1,260 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+const defaultAPIURL = "https://api.telegram.org"
+
+// Telegram stores the files as documents in a chat, the tree is mapped from the paths in their captions.
+// The Bot API can't read the history of a chat, so only the documents posted since the bot has been added are indexed
+type Telegram struct {
+	model.Storage
+	Addition
+	mu sync.Mutex
+}
+
+func (d *Telegram) Config() driver.Config {
+	return config
+}
+
+func (d *Telegram) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Telegram) Init(ctx context.Context) error {
+	d.APIURL = strings.TrimSuffix(d.APIURL, "/")
+	if d.APIURL == "" {
+		d.APIURL = defaultAPIURL
+	}
+	_, err := request[any](d, "getMe", nil)
+	return err
+}
+
+func (d *Telegram) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Telegram) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	if err := d.sync(); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dirPath := dir.GetPath()
+	seen := make(map[string]bool)
+	var res []model.Obj
+	addDir := func(p string) {
+		rel := strings.TrimPrefix(p, utils.PathAddSeparatorSuffix(dirPath))
+		if rel == p || rel == "" {
+			return
+		}
+		name := strings.Split(rel, "/")[0]
+		if !seen[name] {
+			seen[name] = true
+			res = append(res, &model.Object{Name: name, Path: stdpath.Join(dirPath, name), IsFolder: true})
+		}
+	}
+	for _, f := range d.Files {
+		if stdpath.Dir(f.Path) == dirPath {
+			res = append(res, &model.Object{
+				ID:       f.FileID,
+				Name:     stdpath.Base(f.Path),
+				Path:     f.Path,
+				Size:     f.Size,
+				Modified: f.Date,
+			})
+			continue
+		}
+		addDir(stdpath.Dir(f.Path))
+	}
+	for _, p := range d.Dirs {
+		addDir(p)
+	}
+	return res, nil
+}
+
+func (d *Telegram) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	f, err := request[TGFile](d, "getFile", func(req *resty.Request) {
+		req.SetFormData(map[string]string{"file_id": file.GetID()})
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the download link is valid for at least one hour
+	exp := 50 * time.Minute
+	return &model.Link{
+		URL:        d.APIURL + "/file/bot" + d.BotToken + "/" + f.FilePath,
+		Expiration: &exp,
+	}, nil
+}
+
+func (d *Telegram) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Dirs = append(d.Dirs, stdpath.Join(parentDir.GetPath(), dirName))
+	op.MustSaveDriverStorage(d)
+	return nil
+}
+
+func (d *Telegram) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.rename(srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+}
+
+func (d *Telegram) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.rename(srcObj.GetPath(), stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName))
+}
+
+// rename moves the file or the tree at src to dst by editing the captions of the documents
+func (d *Telegram) rename(src, dst string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer op.MustSaveDriverStorage(d)
+	for i, f := range d.Files {
+		if !utils.IsSubPath(src, f.Path) {
+			continue
+		}
+		newPath := dst + strings.TrimPrefix(f.Path, src)
+		_, err := request[any](d, "editMessageCaption", func(req *resty.Request) {
+			req.SetFormData(map[string]string{
+				"chat_id":    d.ChatID,
+				"message_id": strconv.FormatInt(f.MessageID, 10),
+				"caption":    newPath,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		d.Files[i].Path = newPath
+	}
+	for i, p := range d.Dirs {
+		if utils.IsSubPath(src, p) {
+			d.Dirs[i] = dst + strings.TrimPrefix(p, src)
+		}
+	}
+	return nil
+}
+
+func (d *Telegram) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	src, dst := srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName())
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer op.MustSaveDriverStorage(d)
+	for _, f := range d.Files {
+		if !utils.IsSubPath(src, f.Path) {
+			continue
+		}
+		newPath := dst + strings.TrimPrefix(f.Path, src)
+		m, err := request[MessageID](d, "copyMessage", func(req *resty.Request) {
+			req.SetFormData(map[string]string{
+				"chat_id":      d.ChatID,
+				"from_chat_id": d.ChatID,
+				"message_id":   strconv.FormatInt(f.MessageID, 10),
+				"caption":      newPath,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		f.MessageID, f.Path, f.Date = m.MessageID, newPath, time.Now()
+		d.Files = append(d.Files, f)
+	}
+	for _, p := range d.Dirs {
+		if utils.IsSubPath(src, p) {
+			d.Dirs = append(d.Dirs, dst+strings.TrimPrefix(p, src))
+		}
+	}
+	return nil
+}
+
+func (d *Telegram) Remove(ctx context.Context, obj model.Obj) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer op.MustSaveDriverStorage(d)
+	return d.remove(obj.GetPath())
+}
+
+// remove deletes the messages of the file or the tree at path
+func (d *Telegram) remove(path string) error {
+	files := d.Files[:0]
+	var err error
+	for _, f := range d.Files {
+		if err == nil && utils.IsSubPath(path, f.Path) {
+			err = d.deleteMessage(f.MessageID)
+			if err == nil {
+				continue
+			}
+		}
+		files = append(files, f)
+	}
+	d.Files = files
+	if err != nil {
+		return err
+	}
+	d.Dirs = utils.SliceFilter(d.Dirs, func(p string) bool {
+		return !utils.IsSubPath(path, p)
+	})
+	return nil
+}
+
+func (d *Telegram) deleteMessage(messageID int64) error {
+	_, err := request[any](d, "deleteMessage", func(req *resty.Request) {
+		req.SetFormData(map[string]string{
+			"chat_id":    d.ChatID,
+			"message_id": strconv.FormatInt(messageID, 10),
+		})
+	})
+	return err
+}
+
+func (d *Telegram) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	if d.APIURL == defaultAPIURL && s.GetSize() > 50*utils.MB {
+		return errors.New("files larger than 50MB can only be uploaded via a local Bot API server")
+	}
+	path := stdpath.Join(dstDir.GetPath(), s.GetName())
+	reader := driver.NewLimitedUploadStream(ctx, &driver.ReaderUpdatingProgress{
+		Reader:         s,
+		UpdateProgress: up,
+	})
+	m, err := request[Message](d, "sendDocument", func(req *resty.Request) {
+		req.SetContext(ctx)
+		req.SetFormData(map[string]string{
+			"chat_id":                        d.ChatID,
+			"caption":                        path,
+			"disable_content_type_detection": "true",
+		})
+		req.SetFileReader("document", s.GetName(), reader)
+	})
+	if err != nil {
+		return err
+	}
+	if m.Document == nil {
+		return errs.NotFile
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer op.MustSaveDriverStorage(d)
+	// replace the old document of the same path
+	if old, ok := d.getFile(path); ok {
+		if err := d.remove(old.Path); err != nil {
+			return err
+		}
+	}
+	d.index(&m)
+	return nil
+}
+
+var _ driver.Driver = (*Telegram)(nil)
@@ -0,0 +1,31 @@
+package telegram
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	driver.RootPath
+	BotToken string `json:"bot_token" required:"true"`
+	ChatID   string `json:"chat_id" required:"true" help:"The id of the chat or the @username of the channel, the bot must be able to post and edit messages in it."`
+	APIURL   string `json:"api_url" default:"https://api.telegram.org" help:"A local Bot API server lifts the 20MB download and 50MB upload limits."`
+	// the index of the documents, a document is put at the path in its caption, or at the root by its file name
+	Files        []File   `json:"files" ignore:"true"`
+	Dirs         []string `json:"dirs" ignore:"true"`
+	UpdateOffset int64    `json:"update_offset" ignore:"true"`
+}
+
+var config = driver.Config{
+	Name:        "Telegram",
+	DefaultRoot: "/",
+	LocalSort:   true,
+	// the download url contains the bot token
+	OnlyProxy: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Telegram{}
+	})
+}
@@ -0,0 +1,53 @@
+package telegram
+
+import "time"
+
+// File is a document in the chat indexed at Path
+type File struct {
+	MessageID int64     `json:"message_id"`
+	FileID    string    `json:"file_id"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Date      time.Time `json:"date"`
+}
+
+type Resp[T any] struct {
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+	Result      T      `json:"result"`
+}
+
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	FileSize int64  `json:"file_size"`
+}
+
+type Chat struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type Message struct {
+	MessageID int64     `json:"message_id"`
+	Date      int64     `json:"date"`
+	Chat      Chat      `json:"chat"`
+	Caption   string    `json:"caption"`
+	Document  *Document `json:"document"`
+}
+
+type Update struct {
+	UpdateID          int64    `json:"update_id"`
+	Message           *Message `json:"message"`
+	ChannelPost       *Message `json:"channel_post"`
+	EditedMessage     *Message `json:"edited_message"`
+	EditedChannelPost *Message `json:"edited_channel_post"`
+}
+
+type TGFile struct {
+	FilePath string `json:"file_path"`
+}
+
+type MessageID struct {
+	MessageID int64 `json:"message_id"`
+}
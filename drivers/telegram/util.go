@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+func request[T any](d *Telegram, method string, callback base.ReqCallback) (T, error) {
+	var resp Resp[T]
+	req := base.RestyClient.R().SetResult(&resp).SetError(&resp)
+	if callback != nil {
+		callback(req)
+	}
+	_, err := req.Post(d.APIURL + "/bot" + d.BotToken + "/" + method)
+	if err != nil {
+		return resp.Result, err
+	}
+	if !resp.Ok {
+		return resp.Result, errors.New(method + " failed: " + resp.Description)
+	}
+	return resp.Result, nil
+}
+
+// isChat reports whether the message is posted in the chat of the storage
+func (d *Telegram) isChat(chat Chat) bool {
+	return strconv.FormatInt(chat.ID, 10) == d.ChatID || (chat.Username != "" && "@"+chat.Username == d.ChatID)
+}
+
+// captionPath maps a document to its path, the caption if it's a path, else the file name at the root
+func captionPath(m *Message) string {
+	if p := strings.TrimSpace(m.Caption); strings.HasPrefix(p, "/") {
+		return utils.FixAndCleanPath(p)
+	}
+	return "/" + m.Document.FileName
+}
+
+// sync indexes the documents posted into the chat since the last sync, the bot only receives the updates
+// of the last 24 hours, and none at all if a webhook is set for it
+func (d *Telegram) sync() error {
+	updates, err := request[[]Update](d, "getUpdates", func(req *resty.Request) {
+		req.SetFormData(map[string]string{
+			"offset":          strconv.FormatInt(d.UpdateOffset, 10),
+			"allowed_updates": `["message","channel_post","edited_message","edited_channel_post"]`,
+		})
+	})
+	if err != nil || len(updates) == 0 {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, u := range updates {
+		d.UpdateOffset = u.UpdateID + 1
+		for _, m := range []*Message{u.Message, u.ChannelPost, u.EditedMessage, u.EditedChannelPost} {
+			if m == nil || m.Document == nil || !d.isChat(m.Chat) {
+				continue
+			}
+			d.index(m)
+		}
+	}
+	op.MustSaveDriverStorage(d)
+	return nil
+}
+
+// index puts the document of m into the index, replacing the entry of the same message
+func (d *Telegram) index(m *Message) {
+	f := File{
+		MessageID: m.MessageID,
+		FileID:    m.Document.FileID,
+		Path:      captionPath(m),
+		Size:      m.Document.FileSize,
+		Date:      time.Unix(m.Date, 0),
+	}
+	for i := range d.Files {
+		if d.Files[i].MessageID == f.MessageID {
+			d.Files[i] = f
+			return
+		}
+	}
+	d.Files = append(d.Files, f)
+}
+
+func (d *Telegram) getFile(path string) (File, bool) {
+	for _, f := range d.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return File{}, false
+}
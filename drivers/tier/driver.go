@@ -0,0 +1,220 @@
+package tier
+
+import (
+	"context"
+	"errors"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+type Tier struct {
+	model.Storage
+	Addition
+	// accessed is the last read time of the files since the start, the modified time is used for the others
+	accessed sync.Map
+	cancel   context.CancelFunc
+}
+
+func (d *Tier) Config() driver.Config {
+	return config
+}
+
+func (d *Tier) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Tier) Init(ctx context.Context) error {
+	d.HotPath = utils.FixAndCleanPath(d.HotPath)
+	d.ColdPath = utils.FixAndCleanPath(d.ColdPath)
+	if utils.IsSubPath(d.HotPath, d.ColdPath) || utils.IsSubPath(d.ColdPath, d.HotPath) {
+		return errors.New("the hot path and the cold path can't contain each other")
+	}
+	if d.MigrateInterval <= 0 {
+		d.MigrateInterval = 24
+	}
+	if d.ColdAfterDays > 0 {
+		migrateCtx, cancel := context.WithCancel(context.Background())
+		d.cancel = cancel
+		go d.migrateLoop(migrateCtx)
+	}
+	return nil
+}
+
+func (d *Tier) Drop(ctx context.Context) error {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	return nil
+}
+
+func (Addition) GetRootPath() string {
+	return "/"
+}
+
+func (d *Tier) Get(ctx context.Context, path string) (model.Obj, error) {
+	var res *tierObj
+	for _, hot := range []bool{true, false} {
+		obj, err := fs.Get(ctx, d.tierPath(hot, path), &fs.GetArgs{NoLog: true})
+		if err != nil {
+			continue
+		}
+		if res == nil {
+			res = newTierObj(path, obj)
+		} else if res.IsDir() != obj.IsDir() {
+			continue
+		}
+		res.hot, res.cold = res.hot || hot, res.cold || !hot
+	}
+	if res == nil {
+		return nil, errs.ObjectNotFound
+	}
+	return res, nil
+}
+
+func (d *Tier) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var result []model.Obj
+	index := make(map[string]*tierObj)
+	var firstErr error
+	listed := false
+	for _, hot := range []bool{true, false} {
+		objs, err := fs.List(ctx, d.tierPath(hot, dir.GetPath()), &fs.ListArgs{NoLog: true, Refresh: args.Refresh})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		listed = true
+		for _, obj := range objs {
+			o, ok := index[obj.GetName()]
+			if !ok {
+				o = newTierObj(stdpath.Join(dir.GetPath(), obj.GetName()), obj)
+				index[obj.GetName()] = o
+				result = append(result, o)
+			} else if o.IsDir() != obj.IsDir() {
+				// the hot one shadows the cold one
+				continue
+			}
+			o.hot, o.cold = o.hot || hot, o.cold || !hot
+		}
+	}
+	if !listed {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+func (d *Tier) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	obj, ok := file.(*tierObj)
+	if !ok {
+		return nil, errs.NotFile
+	}
+	d.accessed.Store(obj.GetPath(), time.Now())
+	storage, actualPath, err := op.GetStorageAndActualPath(d.tierPath(obj.hot, obj.GetPath()))
+	if err != nil {
+		return nil, err
+	}
+	l, _, err := op.Link(ctx, storage, actualPath, args)
+	if err != nil {
+		return nil, err
+	}
+	resultLink := *l
+	resultLink.SyncClosers = utils.NewSyncClosers(l)
+	return &resultLink, nil
+}
+
+func (d *Tier) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return fs.MakeDir(ctx, d.tierPath(true, stdpath.Join(parentDir.GetPath(), dirName)))
+}
+
+// tiers returns the tiers where obj exists
+func tiers(obj model.Obj) []bool {
+	o, ok := obj.(*tierObj)
+	if !ok {
+		return []bool{true, false}
+	}
+	var res []bool
+	if o.hot {
+		res = append(res, true)
+	}
+	if o.cold {
+		res = append(res, false)
+	}
+	return res
+}
+
+func (d *Tier) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	for _, hot := range tiers(srcObj) {
+		dstDirPath := d.tierPath(hot, dstDir.GetPath())
+		if err := fs.MakeDir(ctx, dstDirPath); err != nil {
+			return err
+		}
+		if _, err := fs.Move(ctx, d.tierPath(hot, srcObj.GetPath()), dstDirPath); err != nil {
+			return err
+		}
+	}
+	d.accessed.Delete(srcObj.GetPath())
+	return nil
+}
+
+func (d *Tier) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	for _, hot := range tiers(srcObj) {
+		if err := fs.Rename(ctx, d.tierPath(hot, srcObj.GetPath()), newName); err != nil {
+			return err
+		}
+	}
+	d.accessed.Delete(srcObj.GetPath())
+	return nil
+}
+
+func (d *Tier) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	// copied by the stream into the hot tier
+	return errs.NotImplement
+}
+
+func (d *Tier) Remove(ctx context.Context, obj model.Obj) error {
+	for _, hot := range tiers(obj) {
+		if err := fs.Remove(ctx, d.tierPath(hot, obj.GetPath())); err != nil && !errs.IsObjectNotFound(err) {
+			return err
+		}
+	}
+	d.accessed.Delete(obj.GetPath())
+	return nil
+}
+
+func (d *Tier) Put(ctx context.Context, dstDir model.Obj, file model.FileStreamer, up driver.UpdateProgress) error {
+	hotDirPath := d.tierPath(true, dstDir.GetPath())
+	if err := fs.MakeDir(ctx, hotDirPath); err != nil {
+		return err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(hotDirPath)
+	if err != nil {
+		return err
+	}
+	if err := op.Put(ctx, storage, actualPath, file, up); err != nil {
+		return err
+	}
+	// the overwritten file in the cold tier would come back once the new one is migrated
+	coldPath := d.tierPath(false, stdpath.Join(dstDir.GetPath(), file.GetName()))
+	if _, err := fs.Get(ctx, coldPath, &fs.GetArgs{NoLog: true}); err == nil {
+		if err := fs.Remove(ctx, coldPath); err != nil {
+			log.Warnf("failed remove the overwritten [%s]: %+v", coldPath, err)
+		}
+	}
+	return nil
+}
+
+func (d *Tier) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	return nil, errs.NotImplement
+}
+
+var _ driver.Driver = (*Tier)(nil)
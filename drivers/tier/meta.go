@@ -0,0 +1,27 @@
+package tier
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	HotPath         string `json:"hot_path" required:"true" help:"the fast storage where the new files are written"`
+	ColdPath        string `json:"cold_path" required:"true" help:"the storage where the unaccessed files are migrated to"`
+	ColdAfterDays   int    `json:"cold_after_days" type:"number" default:"30" help:"the files neither modified nor read for this many days are migrated to the cold path, 0 to disable the migration"`
+	MigrateInterval int    `json:"migrate_interval" type:"number" default:"24" help:"the hours between the migrations"`
+}
+
+var config = driver.Config{
+	Name:        "Tier",
+	LocalSort:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+	NoLinkURL:   true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Tier{}
+	})
+}
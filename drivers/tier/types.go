@@ -0,0 +1,10 @@
+package tier
+
+import "github.com/OpenListTeam/OpenList/v4/internal/model"
+
+// tierObj is an object of the unified namespace, Path is relative to the root of the tiers
+type tierObj struct {
+	model.Object
+	hot  bool // whether the object exists in the hot tier
+	cold bool // whether the object exists in the cold tier
+}
@@ -0,0 +1,86 @@
+package tier
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// do others that not defined in Driver interface
+
+func (d *Tier) tierPath(hot bool, path string) string {
+	if hot {
+		return stdpath.Join(d.HotPath, path)
+	}
+	return stdpath.Join(d.ColdPath, path)
+}
+
+func newTierObj(path string, obj model.Obj) *tierObj {
+	return &tierObj{
+		Object: model.Object{
+			Path:     path,
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			Ctime:    obj.CreateTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		},
+	}
+}
+
+func (d *Tier) migrateLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(d.MigrateInterval) * time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cutoff := time.Now().AddDate(0, 0, -d.ColdAfterDays)
+			if err := d.migrate(ctx, "/", cutoff); err != nil && ctx.Err() == nil {
+				log.Errorf("failed migrate [%s] to the cold tier: %+v", d.MountPath, err)
+			}
+		}
+	}
+}
+
+// migrate moves the files under dirPath of the hot tier, which are neither modified nor read since cutoff,
+// to the cold tier. The moves between two storages are done by the move tasks
+func (d *Tier) migrate(ctx context.Context, dirPath string, cutoff time.Time) error {
+	objs, err := fs.List(ctx, d.tierPath(true, dirPath), &fs.ListArgs{NoLog: true, Refresh: true})
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		path := stdpath.Join(dirPath, obj.GetName())
+		if obj.IsDir() {
+			if err := d.migrate(ctx, path, cutoff); err != nil {
+				return err
+			}
+			continue
+		}
+		if obj.ModTime().After(cutoff) {
+			continue
+		}
+		if accessed, ok := d.accessed.Load(path); ok && accessed.(time.Time).After(cutoff) {
+			continue
+		}
+		coldDirPath := d.tierPath(false, dirPath)
+		if err := fs.MakeDir(ctx, coldDirPath); err != nil {
+			return err
+		}
+		if _, err := fs.Move(ctx, d.tierPath(true, path), coldDirPath); err != nil {
+			return err
+		}
+		d.accessed.Delete(path)
+	}
+	return nil
+}
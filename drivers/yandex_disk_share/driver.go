@@ -0,0 +1,71 @@
+package yandex_disk_share
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/yandex_disk"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// YandexDiskShare mounts a public folder of Yandex Disk read-only
+type YandexDiskShare struct {
+	model.Storage
+	Addition
+}
+
+func (d *YandexDiskShare) Config() driver.Config {
+	return config
+}
+
+func (d *YandexDiskShare) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *YandexDiskShare) Init(ctx context.Context) error {
+	var resp yandex_disk.FilesResp
+	_, err := d.request("", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{
+			"path":  d.GetRootPath(),
+			"limit": "0",
+		})
+	}, &resp)
+	return err
+}
+
+func (d *YandexDiskShare) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *YandexDiskShare) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	files, err := d.getFiles(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src yandex_disk.File) (model.Obj, error) {
+		return &model.Object{
+			Path:     path.Join(dir.GetPath(), src.Name),
+			Name:     src.Name,
+			Size:     src.Size,
+			Modified: src.Modified,
+			IsFolder: src.Type == "dir",
+		}, nil
+	})
+}
+
+func (d *YandexDiskShare) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	var resp yandex_disk.DownResp
+	_, err := d.request("/download", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("path", file.GetPath())
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Link{URL: resp.Href}, nil
+}
+
+var _ driver.Driver = (*YandexDiskShare)(nil)
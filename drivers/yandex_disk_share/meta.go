@@ -0,0 +1,25 @@
+package yandex_disk_share
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	ShareURL       string `json:"share_url" required:"true" help:"The public link of the folder, e.g. https://disk.yandex.com/d/xxxx"`
+	OrderBy        string `json:"order_by" type:"select" options:"name,path,created,modified,size" default:"name"`
+	OrderDirection string `json:"order_direction" type:"select" options:"asc,desc" default:"asc"`
+	driver.RootPath
+}
+
+var config = driver.Config{
+	Name:        "YandexDiskShare",
+	DefaultRoot: "/",
+	NoUpload:    true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &YandexDiskShare{}
+	})
+}
@@ -0,0 +1,66 @@
+package yandex_disk_share
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/drivers/yandex_disk"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+func (d *YandexDiskShare) request(pathname string, method string, callback base.ReqCallback, resp interface{}) ([]byte, error) {
+	u := "https://cloud-api.yandex.net/v1/disk/public/resources" + pathname
+	req := base.RestyClient.R()
+	req.SetQueryParam("public_key", d.ShareURL)
+	if callback != nil {
+		callback(req)
+	}
+	if resp != nil {
+		req.SetResult(resp)
+	}
+	var e yandex_disk.ErrResp
+	req.SetError(&e)
+	res, err := req.Execute(method, u)
+	if err != nil {
+		return nil, err
+	}
+	if e.Error != "" {
+		return nil, errors.New(e.Description)
+	}
+	return res.Body(), nil
+}
+
+func (d *YandexDiskShare) getFiles(path string) ([]yandex_disk.File, error) {
+	limit := 100
+	res := make([]yandex_disk.File, 0)
+	for offset := 0; ; offset += limit {
+		query := map[string]string{
+			"path":   path,
+			"limit":  strconv.Itoa(limit),
+			"offset": strconv.Itoa(offset),
+		}
+		if d.OrderBy != "" {
+			if d.OrderDirection == "desc" {
+				query["sort"] = "-" + d.OrderBy
+			} else {
+				query["sort"] = d.OrderBy
+			}
+		}
+		var resp yandex_disk.FilesResp
+		_, err := d.request("", http.MethodGet, func(req *resty.Request) {
+			req.SetQueryParams(query)
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Embedded.Items...)
+		if resp.Embedded.Total <= offset+limit {
+			break
+		}
+	}
+	return res, nil
+}
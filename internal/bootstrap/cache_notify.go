@@ -0,0 +1,86 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	log "github.com/sirupsen/logrus"
+)
+
+var stopCacheNotify context.CancelFunc
+
+func InitCacheNotify() {
+	if !conf.Conf.Database.CacheNotify {
+		return
+	}
+	if conf.Conf.Database.Type != "postgres" {
+		log.Warnf("cache_notify needs a postgres database, ignored for %s", conf.Conf.Database.Type)
+		return
+	}
+	channel := conf.Conf.Database.TablePrefix + "cache_invalidation"
+	origin := random.String(16)
+	op.SetInvalidationPublisher(func(inv model.CacheInvalidation) {
+		inv.Origin = origin
+		payload, err := utils.Json.MarshalToString(inv)
+		if err == nil {
+			err = db.Notify(channel, payload)
+		}
+		if err != nil {
+			log.Errorf("failed notify cache invalidation %+v: %+v", inv, err)
+		}
+	})
+	var ctx context.Context
+	ctx, stopCacheNotify = context.WithCancel(context.Background())
+	go listenCacheNotify(ctx, channel, origin)
+}
+
+func listenCacheNotify(ctx context.Context, channel, origin string) {
+	reconnect := false
+	for {
+		err := db.Listen(ctx, channel, func() {
+			if !reconnect {
+				return
+			}
+			// the invalidations sent while disconnected are lost
+			if err := op.RefreshCaches(); err != nil {
+				log.Errorf("failed refresh caches: %+v", err)
+			}
+		}, func(payload string) {
+			var inv model.CacheInvalidation
+			if err := utils.Json.UnmarshalFromString(payload, &inv); err != nil {
+				log.Warnf("invalid cache invalidation %s: %+v", payload, err)
+				return
+			}
+			if inv.Origin == origin {
+				return
+			}
+			log.Debugf("cache invalidation from %s: %+v", inv.Origin, inv)
+			if err := op.ApplyInvalidation(ctx, inv); err != nil {
+				log.Errorf("failed apply cache invalidation %+v: %+v", inv, err)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		log.Errorf("lost the cache invalidation channel, reconnecting: %+v", err)
+		reconnect = true
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func StopCacheNotify() {
+	op.SetInvalidationPublisher(nil)
+	if stopCacheNotify != nil {
+		stopCacheNotify()
+	}
+}
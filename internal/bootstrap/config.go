@@ -111,6 +111,12 @@ func InitConfig() {
 		conf.MaxBufferLimit = conf.Conf.MaxBufferLimit * utils.MB
 	}
 	log.Infof("max buffer limit: %dMB", conf.MaxBufferLimit/utils.MB)
+	if conf.Conf.MaxBufferTotal < 0 {
+		conf.MaxBufferTotal = 4 * conf.MaxBufferLimit
+	} else {
+		conf.MaxBufferTotal = conf.Conf.MaxBufferTotal * utils.MB
+	}
+	log.Infof("max buffer total: %dMB", conf.MaxBufferTotal/utils.MB)
 	if conf.Conf.MmapThreshold > 0 {
 		conf.MmapThreshold = conf.Conf.MmapThreshold * utils.MB
 	} else {
@@ -133,12 +139,17 @@ func InitConfig() {
 	convertAbsPath(&conf.Conf.Scheme.UnixFile)
 	convertAbsPath(&conf.Conf.Log.Name)
 	convertAbsPath(&conf.Conf.TempDir)
+	for i := range conf.Conf.ExtraTempDirs {
+		convertAbsPath(&conf.Conf.ExtraTempDirs[i].Path)
+	}
 	convertAbsPath(&conf.Conf.BleveDir)
+	convertAbsPath(&conf.Conf.SecretsDir)
 	convertAbsPath(&conf.Conf.DistDir)
 
-	err := os.MkdirAll(conf.Conf.TempDir, 0o777)
-	if err != nil {
-		log.Fatalf("create temp dir error: %+v", err)
+	for _, dir := range utils.TempDirs() {
+		if err := os.MkdirAll(dir.Path, 0o777); err != nil {
+			log.Fatalf("create temp dir error: %+v", err)
+		}
 	}
 	log.Debugf("config: %+v", conf.Conf)
 
@@ -174,13 +185,15 @@ func initURL() {
 }
 
 func CleanTempDir() {
-	files, err := os.ReadDir(conf.Conf.TempDir)
-	if err != nil {
-		log.Errorln("failed list temp file: ", err)
-	}
-	for _, file := range files {
-		if err := os.RemoveAll(filepath.Join(conf.Conf.TempDir, file.Name())); err != nil {
-			log.Errorln("failed delete temp file: ", err)
+	for _, dir := range utils.TempDirs() {
+		files, err := os.ReadDir(dir.Path)
+		if err != nil {
+			log.Errorln("failed list temp file: ", err)
+		}
+		for _, file := range files {
+			if err := os.RemoveAll(filepath.Join(dir.Path, file.Name())); err != nil {
+				log.Errorln("failed delete temp file: ", err)
+			}
 		}
 	}
 }
@@ -25,6 +25,8 @@ func InitialTasks() []model.TaskItem {
 		{Key: "move", PersistData: "[]"},
 		{Key: "download", PersistData: "[]"},
 		{Key: "transfer", PersistData: "[]"},
+		{Key: "upload", PersistData: "[]"},
+		{Key: "decompress_upload", PersistData: "[]"},
 	}
 	return initialTaskItems
 }
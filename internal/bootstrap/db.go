@@ -19,6 +19,19 @@ import (
 )
 
 func InitDB() {
+	db.Init(openDB())
+	if conf.Conf.Database.ReplicaDSN != "" && !flags.Dev {
+		db.SetReplica(openReplica())
+	}
+}
+
+// InitDBWithoutMigration connects the database without applying the pending migrations,
+// for the commands which manage the migrations
+func InitDBWithoutMigration() {
+	db.Open(openDB())
+}
+
+func newGormConfig() *gorm.Config {
 	logLevel := logger.Silent
 	if flags.Debug || flags.Dev {
 		logLevel = logger.Info
@@ -32,12 +45,16 @@ func InitDB() {
 			Colorful:                  true,
 		},
 	)
-	gormConfig := &gorm.Config{
+	return &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{
 			TablePrefix: conf.Conf.Database.TablePrefix,
 		},
 		Logger: newLogger,
 	}
+}
+
+func openDB() *gorm.DB {
+	gormConfig := newGormConfig()
 	var dB *gorm.DB
 	var err error
 	if flags.Dev {
@@ -51,7 +68,8 @@ func InitDB() {
 				if !(strings.HasSuffix(database.DBFile, ".db") && len(database.DBFile) > 3) {
 					log.Fatalf("db name error.")
 				}
-				dB, err = gorm.Open(sqlite.Open(fmt.Sprintf("%s?_journal=WAL&_vacuum=incremental",
+				// NORMAL is durable enough with WAL, the busy timeout lets the writers wait for each other instead of failing
+				dB, err = gorm.Open(sqlite.Open(fmt.Sprintf("%s?_journal=WAL&_vacuum=incremental&_synchronous=NORMAL&_busy_timeout=5000&_cache_size=-16000",
 					database.DBFile)), gormConfig)
 			}
 		case "mysql":
@@ -85,5 +103,25 @@ func InitDB() {
 	if err != nil {
 		log.Fatalf("failed to connect database:%s", err.Error())
 	}
-	db.Init(dB)
+	return dB
+}
+
+// openReplica connects the read-only replica, the server goes on with the primary only if it fails
+func openReplica() *gorm.DB {
+	var dialector gorm.Dialector
+	switch conf.Conf.Database.Type {
+	case "mysql":
+		dialector = mysql.Open(conf.Conf.Database.ReplicaDSN)
+	case "postgres":
+		dialector = postgres.Open(conf.Conf.Database.ReplicaDSN)
+	default:
+		log.Warnf("replica_dsn is not supported by %s, ignored", conf.Conf.Database.Type)
+		return nil
+	}
+	replica, err := gorm.Open(dialector, newGormConfig())
+	if err != nil {
+		log.Errorf("failed to connect replica database, reading from the primary: %s", err.Error())
+		return nil
+	}
+	return replica
 }
@@ -0,0 +1,30 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+)
+
+var downProxyCheckCron *cron.Cron
+
+func InitDownProxyCheck() {
+	interval := setting.GetInt(conf.DownProxyCheckInterval, 60)
+	if interval <= 0 {
+		return
+	}
+	downProxyCheckCron = cron.NewCron(time.Duration(interval) * time.Second)
+	downProxyCheckCron.Do(func() {
+		op.CheckDownProxies(context.Background())
+	})
+}
+
+func StopDownProxyCheck() {
+	if downProxyCheckCron != nil {
+		downProxyCheckCron.Stop()
+	}
+}
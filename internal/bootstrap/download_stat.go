@@ -0,0 +1,52 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+var (
+	downloadStatsCron      *cron.Cron
+	downloadStatsCleanCron *cron.Cron
+)
+
+func InitDownloadStats() {
+	downloadStatsCron = cron.NewCron(time.Minute)
+	downloadStatsCron.Do(func() {
+		op.FlushDownloadStats()
+		op.FlushUploadStats()
+		op.FlushStorageTraffic()
+	})
+	downloadStatsCleanCron = cron.NewCron(24 * time.Hour)
+	downloadStatsCleanCron.Do(cleanDownloadStats)
+	cleanDownloadStats()
+}
+
+func cleanDownloadStats() {
+	if err := op.CleanDownloadStats(setting.GetInt(conf.DownloadStatsKeepDays, 90)); err != nil {
+		utils.Log.Errorf("failed clean download stats: %+v", err)
+	}
+	if err := op.CleanUploadStats(setting.GetInt(conf.UploadStatsKeepDays, 365)); err != nil {
+		utils.Log.Errorf("failed clean upload stats: %+v", err)
+	}
+	// the traffic of the storages is kept as long as the download stats
+	if err := op.CleanStorageTraffic(setting.GetInt(conf.DownloadStatsKeepDays, 90)); err != nil {
+		utils.Log.Errorf("failed clean storage traffic: %+v", err)
+	}
+}
+
+// StopDownloadStats stops the periodic jobs and saves the pending download and upload stats and storage traffic
+func StopDownloadStats() {
+	if downloadStatsCron != nil {
+		downloadStatsCron.Stop()
+		downloadStatsCleanCron.Stop()
+	}
+	op.FlushDownloadStats()
+	op.FlushUploadStats()
+	op.FlushStorageTraffic()
+}
@@ -0,0 +1,21 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+)
+
+var indexExportCron *cron.Cron
+
+func InitIndexExport() {
+	indexExportCron = cron.NewCron(time.Minute)
+	indexExportCron.Do(fs.ExportDueIndexes)
+}
+
+func StopIndexExport() {
+	if indexExportCron != nil {
+		indexExportCron.Stop()
+	}
+}
@@ -0,0 +1,21 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+)
+
+var ingestCron *cron.Cron
+
+func InitIngest() {
+	ingestCron = cron.NewCron(time.Minute)
+	ingestCron.Do(fs.RunDueIngestRules)
+}
+
+func StopIngest() {
+	if ingestCron != nil {
+		ingestCron.Stop()
+	}
+}
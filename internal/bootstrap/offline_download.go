@@ -1,7 +1,11 @@
 package bootstrap
 
 import (
+	"time"
+
 	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 )
 
@@ -15,3 +19,31 @@ func InitOfflineDownloadTools() {
 		}
 	}
 }
+
+var speedScheduleCron *cron.Cron
+
+func InitSpeedSchedule() {
+	tool.ApplySpeedSchedule()
+	op.RegisterSettingChangingCallback(tool.ApplySpeedSchedule)
+	speedScheduleCron = cron.NewCron(time.Minute)
+	speedScheduleCron.Do(tool.ApplySpeedSchedule)
+}
+
+func StopSpeedSchedule() {
+	if speedScheduleCron != nil {
+		speedScheduleCron.Stop()
+	}
+}
+
+var watchFoldersCron *cron.Cron
+
+func InitWatchFolders() {
+	watchFoldersCron = cron.NewCron(time.Minute)
+	watchFoldersCron.Do(tool.ScanWatchFolders)
+}
+
+func StopWatchFolders() {
+	if watchFoldersCron != nil {
+		watchFoldersCron.Stop()
+	}
+}
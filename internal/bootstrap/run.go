@@ -40,6 +40,19 @@ func Init() {
 }
 
 func Release() {
+	StopDownloadStats()
+	StopTaskArchive()
+	StopTaskHistory()
+	StopDownProxyCheck()
+	StopIndexExport()
+	StopIngest()
+	StopSchedule()
+	StopScrub()
+	StopStoragePurge()
+	StopSQLiteMaintenance()
+	StopSpeedSchedule()
+	StopWatchFolders()
+	StopCacheNotify()
 	db.Close()
 }
 
@@ -91,17 +104,40 @@ func Start() {
 	}
 	InitOfflineDownloadTools()
 	LoadStorages()
+	InitCacheNotify()
 	InitTaskManager()
+	InitTaskArchive()
+	InitTaskHistory()
+	InitDownProxyCheck()
+	InitDownloadStats()
+	InitIndexExport()
+	InitIngest()
+	InitSchedule()
+	InitScrub()
+	InitStoragePurge()
+	InitSQLiteMaintenance()
+	InitSpeedSchedule()
+	InitWatchFolders()
 	if !flags.Debug && !flags.Dev {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	r := gin.New()
+	// the client ip decides the proxy rules of the storages, so it's only taken from the trusted proxies if they are set
+	if conf.Conf.TrustedProxies != nil {
+		if err := r.SetTrustedProxies(conf.Conf.TrustedProxies); err != nil {
+			log.Fatalf("invalid trusted proxies: %+v", err)
+		}
+	}
 
+	r.Use(middlewares.RequestID)
 	// gin log
 	if conf.Conf.Log.Filter.Enable {
 		r.Use(middlewares.FilteredLogger())
 	} else {
-		r.Use(gin.LoggerWithWriter(log.StandardLogger().Out))
+		r.Use(gin.LoggerWithConfig(gin.LoggerConfig{
+			Output:    log.StandardLogger().Out,
+			Formatter: middlewares.LogFormatter,
+		}))
 	}
 	r.Use(gin.RecoveryWithWriter(log.StandardLogger().Out))
 
@@ -200,6 +236,11 @@ func Start() {
 	}
 	if conf.Conf.S3.Port != -1 && conf.Conf.S3.Enable {
 		s3r := gin.New()
+		if conf.Conf.TrustedProxies != nil {
+			if err := s3r.SetTrustedProxies(conf.Conf.TrustedProxies); err != nil {
+				log.Fatalf("invalid trusted proxies: %+v", err)
+			}
+		}
 		s3r.Use(gin.LoggerWithWriter(log.StandardLogger().Out), gin.RecoveryWithWriter(log.StandardLogger().Out))
 		server.InitS3(s3r)
 		s3Base := fmt.Sprintf("%s:%d", conf.Conf.Scheme.Address, conf.Conf.S3.Port)
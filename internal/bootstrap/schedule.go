@@ -0,0 +1,21 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+)
+
+var scheduleCron *cron.Cron
+
+func InitSchedule() {
+	scheduleCron = cron.NewCron(time.Minute)
+	scheduleCron.Do(fs.RunDueSchedules)
+}
+
+func StopSchedule() {
+	if scheduleCron != nil {
+		scheduleCron.Stop()
+	}
+}
@@ -0,0 +1,21 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+)
+
+var scrubCron *cron.Cron
+
+func InitScrub() {
+	scrubCron = cron.NewCron(time.Hour)
+	scrubCron.Do(fs.RunScheduledScrub)
+}
+
+func StopScrub() {
+	if scrubCron != nil {
+		scrubCron.Stop()
+	}
+}
@@ -0,0 +1,30 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	log "github.com/sirupsen/logrus"
+)
+
+var sqliteMaintenanceCron *cron.Cron
+
+func InitSQLiteMaintenance() {
+	if conf.Conf.Database.Type != "sqlite3" {
+		return
+	}
+	sqliteMaintenanceCron = cron.NewCron(24 * time.Hour)
+	sqliteMaintenanceCron.Do(func() {
+		if err := db.OptimizeSQLite(); err != nil {
+			log.Errorf("failed optimize sqlite: %+v", err)
+		}
+	})
+}
+
+func StopSQLiteMaintenance() {
+	if sqliteMaintenanceCron != nil {
+		sqliteMaintenanceCron.Stop()
+	}
+}
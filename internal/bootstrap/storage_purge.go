@@ -0,0 +1,24 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+)
+
+var storagePurgeCron *cron.Cron
+
+func InitStoragePurge() {
+	storagePurgeCron = cron.NewCron(time.Hour)
+	storagePurgeCron.Do(func() {
+		op.PurgeDeletedStorages(context.Background())
+	})
+}
+
+func StopStoragePurge() {
+	if storagePurgeCron != nil {
+		storagePurgeCron.Stop()
+	}
+}
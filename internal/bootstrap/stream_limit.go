@@ -1,34 +1,14 @@
 package bootstrap
 
 import (
-	"context"
-
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"golang.org/x/time/rate"
 )
 
-type blockBurstLimiter struct {
-	*rate.Limiter
-}
-
-func (l blockBurstLimiter) WaitN(ctx context.Context, total int) error {
-	for total > 0 {
-		n := l.Burst()
-		if l.Limiter.Limit() == rate.Inf || n > total {
-			n = total
-		}
-		err := l.Limiter.WaitN(ctx, n)
-		if err != nil {
-			return err
-		}
-		total -= n
-	}
-	return nil
-}
-
 func streamFilterNegative(limit int) (rate.Limit, int) {
 	if limit < 0 {
 		return rate.Inf, 0
@@ -38,7 +18,7 @@ func streamFilterNegative(limit int) (rate.Limit, int) {
 
 func initLimiter(limiter *stream.Limiter, s string) {
 	clientDownLimit, burst := streamFilterNegative(setting.GetInt(s, -1))
-	*limiter = blockBurstLimiter{Limiter: rate.NewLimiter(clientDownLimit, burst)}
+	*limiter = stream.BlockBurstLimiter{Limiter: rate.NewLimiter(clientDownLimit, burst)}
 	op.RegisterSettingChangingCallback(func() {
 		newLimit, newBurst := streamFilterNegative(setting.GetInt(s, -1))
 		(*limiter).SetLimit(newLimit)
@@ -51,4 +31,26 @@ func InitStreamLimit() {
 	initLimiter(&stream.ClientUploadLimit, conf.StreamMaxClientUploadSpeed)
 	initLimiter(&stream.ServerDownloadLimit, conf.StreamMaxServerDownloadSpeed)
 	initLimiter(&stream.ServerUploadLimit, conf.StreamMaxServerUploadSpeed)
+	setConnectionDownloadLimit()
+	op.RegisterSettingChangingCallback(setConnectionDownloadLimit)
+	initTaskSpeedLimit("upload", conf.TaskUploadSpeedLimit)
+	initTaskSpeedLimit("copy", conf.TaskCopySpeedLimit)
+	initTaskSpeedLimit("move", conf.TaskMoveSpeedLimit)
+	initTaskSpeedLimit("offline_download_transfer", conf.TaskOfflineDownloadTransferSpeedLimit)
+}
+
+// initTaskSpeedLimit sets the speed limit of the tasks of the type, the running ones follow the changes of the setting
+func initTaskSpeedLimit(typ, s string) {
+	task.SetTypeSpeedLimit(typ, setting.GetInt(s, -1))
+	op.RegisterSettingChangingCallback(func() {
+		task.SetTypeSpeedLimit(typ, setting.GetInt(s, -1))
+	})
+}
+
+func setConnectionDownloadLimit() {
+	limit, burst := streamFilterNegative(setting.GetInt(conf.StreamMaxConnectionDownloadSpeed, -1))
+	if b := setting.GetInt(conf.StreamConnectionDownloadBurst, 0); b > 0 && limit != rate.Inf {
+		burst = b * 1024
+	}
+	stream.SetConnectionDownloadLimit(limit, burst)
 }
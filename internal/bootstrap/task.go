@@ -4,9 +4,11 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/notify"
 	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"github.com/OpenListTeam/tache"
 )
 
@@ -17,36 +19,68 @@ func taskFilterNegative(num int) int64 {
 	return int64(num)
 }
 
+// the upload, copy, move and offline download tasks are dispatched by priority, their workers are limited by the slots
 func InitTaskManager() {
-	fs.UploadTaskManager = tache.NewManager[*fs.UploadTask](tache.WithWorks(setting.GetInt(conf.TaskUploadThreadsNum, conf.Conf.Tasks.Upload.Workers)), tache.WithMaxRetry(conf.Conf.Tasks.Upload.MaxRetry)) //upload will not support persist
+	task.SetPersistFailedLogs(setting.GetBool(conf.TaskPersistFailedLogs))
 	op.RegisterSettingChangingCallback(func() {
-		fs.UploadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskUploadThreadsNum, conf.Conf.Tasks.Upload.Workers)))
+		task.SetPersistFailedLogs(setting.GetBool(conf.TaskPersistFailedLogs))
 	})
-	fs.CopyTaskManager = tache.NewManager[*fs.FileTransferTask](tache.WithWorks(setting.GetInt(conf.TaskCopyThreadsNum, conf.Conf.Tasks.Copy.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("copy", conf.Conf.Tasks.Copy.TaskPersistant), db.UpdateTaskDataFunc("copy", conf.Conf.Tasks.Copy.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Copy.MaxRetry))
+	fs.UploadTaskSlots = task.NewSlots(setting.GetInt(conf.TaskUploadThreadsNum, conf.Conf.Tasks.Upload.Workers))
+	fs.UploadTaskManager = tache.NewManager[*fs.UploadTask](tache.WithWorks(task.DispatchWorkers), tache.WithPersistFunction(db.GetTaskDataFunc[*fs.UploadTask]("upload", conf.Conf.Tasks.Upload.TaskPersistant), db.UpdateTaskDataFunc("upload", conf.Conf.Tasks.Upload.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Upload.MaxRetry))
 	op.RegisterSettingChangingCallback(func() {
-		fs.CopyTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskCopyThreadsNum, conf.Conf.Tasks.Copy.Workers)))
+		fs.UploadTaskSlots.SetSize(setting.GetInt(conf.TaskUploadThreadsNum, conf.Conf.Tasks.Upload.Workers))
 	})
-	fs.MoveTaskManager = tache.NewManager[*fs.FileTransferTask](tache.WithWorks(setting.GetInt(conf.TaskMoveThreadsNum, conf.Conf.Tasks.Move.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("move", conf.Conf.Tasks.Move.TaskPersistant), db.UpdateTaskDataFunc("move", conf.Conf.Tasks.Move.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Move.MaxRetry))
+	fs.CopyTaskSlots = task.NewSlots(setting.GetInt(conf.TaskCopyThreadsNum, conf.Conf.Tasks.Copy.Workers))
+	fs.CopyTaskManager = tache.NewManager[*fs.FileTransferTask](tache.WithWorks(task.DispatchWorkers), tache.WithPersistFunction(db.GetTaskDataFunc[*fs.FileTransferTask]("copy", conf.Conf.Tasks.Copy.TaskPersistant), db.UpdateTaskDataFunc("copy", conf.Conf.Tasks.Copy.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Copy.MaxRetry))
 	op.RegisterSettingChangingCallback(func() {
-		fs.MoveTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskMoveThreadsNum, conf.Conf.Tasks.Move.Workers)))
+		fs.CopyTaskSlots.SetSize(setting.GetInt(conf.TaskCopyThreadsNum, conf.Conf.Tasks.Copy.Workers))
 	})
-	tool.DownloadTaskManager = tache.NewManager[*tool.DownloadTask](tache.WithWorks(setting.GetInt(conf.TaskOfflineDownloadThreadsNum, conf.Conf.Tasks.Download.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("download", conf.Conf.Tasks.Download.TaskPersistant), db.UpdateTaskDataFunc("download", conf.Conf.Tasks.Download.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Download.MaxRetry))
+	fs.MoveTaskSlots = task.NewSlots(setting.GetInt(conf.TaskMoveThreadsNum, conf.Conf.Tasks.Move.Workers))
+	fs.MoveTaskManager = tache.NewManager[*fs.FileTransferTask](tache.WithWorks(task.DispatchWorkers), tache.WithPersistFunction(db.GetTaskDataFunc[*fs.FileTransferTask]("move", conf.Conf.Tasks.Move.TaskPersistant), db.UpdateTaskDataFunc("move", conf.Conf.Tasks.Move.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Move.MaxRetry))
 	op.RegisterSettingChangingCallback(func() {
-		tool.DownloadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskOfflineDownloadThreadsNum, conf.Conf.Tasks.Download.Workers)))
+		fs.MoveTaskSlots.SetSize(setting.GetInt(conf.TaskMoveThreadsNum, conf.Conf.Tasks.Move.Workers))
 	})
-	tool.TransferTaskManager = tache.NewManager[*tool.TransferTask](tache.WithWorks(setting.GetInt(conf.TaskOfflineDownloadTransferThreadsNum, conf.Conf.Tasks.Transfer.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("transfer", conf.Conf.Tasks.Transfer.TaskPersistant), db.UpdateTaskDataFunc("transfer", conf.Conf.Tasks.Transfer.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Transfer.MaxRetry))
+	tool.DownloadTaskSlots = task.NewSlots(setting.GetInt(conf.TaskOfflineDownloadThreadsNum, conf.Conf.Tasks.Download.Workers))
+	tool.DownloadTaskManager = tache.NewManager[*tool.DownloadTask](tache.WithWorks(task.DispatchWorkers), tache.WithPersistFunction(db.GetTaskDataFunc[*tool.DownloadTask]("download", conf.Conf.Tasks.Download.TaskPersistant), db.UpdateTaskDataFunc("download", conf.Conf.Tasks.Download.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Download.MaxRetry))
 	op.RegisterSettingChangingCallback(func() {
-		tool.TransferTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskOfflineDownloadTransferThreadsNum, conf.Conf.Tasks.Transfer.Workers)))
+		tool.DownloadTaskSlots.SetSize(setting.GetInt(conf.TaskOfflineDownloadThreadsNum, conf.Conf.Tasks.Download.Workers))
 	})
-	if len(tool.TransferTaskManager.GetAll()) == 0 { //prevent offline downloaded files from being deleted
-		CleanTempDir()
-	}
-	fs.ArchiveDownloadTaskManager = tache.NewManager[*fs.ArchiveDownloadTask](tache.WithWorks(setting.GetInt(conf.TaskDecompressDownloadThreadsNum, conf.Conf.Tasks.Decompress.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("decompress", conf.Conf.Tasks.Decompress.TaskPersistant), db.UpdateTaskDataFunc("decompress", conf.Conf.Tasks.Decompress.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Decompress.MaxRetry))
+	tool.TransferTaskSlots = task.NewSlots(setting.GetInt(conf.TaskOfflineDownloadTransferThreadsNum, conf.Conf.Tasks.Transfer.Workers))
+	tool.TransferTaskManager = tache.NewManager[*tool.TransferTask](tache.WithWorks(task.DispatchWorkers), tache.WithPersistFunction(db.GetTaskDataFunc[*tool.TransferTask]("transfer", conf.Conf.Tasks.Transfer.TaskPersistant), db.UpdateTaskDataFunc("transfer", conf.Conf.Tasks.Transfer.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Transfer.MaxRetry))
+	op.RegisterSettingChangingCallback(func() {
+		tool.TransferTaskSlots.SetSize(setting.GetInt(conf.TaskOfflineDownloadTransferThreadsNum, conf.Conf.Tasks.Transfer.Workers))
+	})
+	fs.ArchiveDownloadTaskManager = tache.NewManager[*fs.ArchiveDownloadTask](tache.WithWorks(setting.GetInt(conf.TaskDecompressDownloadThreadsNum, conf.Conf.Tasks.Decompress.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc[*fs.ArchiveDownloadTask]("decompress", conf.Conf.Tasks.Decompress.TaskPersistant), db.UpdateTaskDataFunc("decompress", conf.Conf.Tasks.Decompress.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Decompress.MaxRetry))
 	op.RegisterSettingChangingCallback(func() {
 		fs.ArchiveDownloadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDecompressDownloadThreadsNum, conf.Conf.Tasks.Decompress.Workers)))
 	})
-	fs.ArchiveContentUploadTaskManager.Manager = tache.NewManager[*fs.ArchiveContentUploadTask](tache.WithWorks(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)), tache.WithMaxRetry(conf.Conf.Tasks.DecompressUpload.MaxRetry)) //decompress upload will not support persist
+	fs.ArchiveContentUploadTaskManager.Manager = tache.NewManager[*fs.ArchiveContentUploadTask](tache.WithWorks(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc[*fs.ArchiveContentUploadTask]("decompress_upload", conf.Conf.Tasks.DecompressUpload.TaskPersistant), db.UpdateTaskDataFunc("decompress_upload", conf.Conf.Tasks.DecompressUpload.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.DecompressUpload.MaxRetry))
 	op.RegisterSettingChangingCallback(func() {
 		fs.ArchiveContentUploadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)))
 	})
+	//prevent offline downloaded and extracted files from being deleted
+	if len(tool.TransferTaskManager.GetAll()) == 0 && len(fs.ArchiveContentUploadTaskManager.GetAll()) == 0 {
+		CleanTempDir()
+	}
+	fs.PublishTaskManager = tache.NewManager[*fs.PublishTask](tache.WithWorks(setting.GetInt(conf.TaskPublishThreadsNum, conf.Conf.Tasks.Publish.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc[*fs.PublishTask]("publish", conf.Conf.Tasks.Publish.TaskPersistant), db.UpdateTaskDataFunc("publish", conf.Conf.Tasks.Publish.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Publish.MaxRetry))
+	op.RegisterSettingChangingCallback(func() {
+		fs.PublishTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskPublishThreadsNum, conf.Conf.Tasks.Publish.Workers)))
+	})
+	fs.IngestTaskManager = tache.NewManager[*fs.IngestTask](tache.WithWorks(setting.GetInt(conf.TaskIngestThreadsNum, conf.Conf.Tasks.Ingest.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc[*fs.IngestTask]("ingest", conf.Conf.Tasks.Ingest.TaskPersistant), db.UpdateTaskDataFunc("ingest", conf.Conf.Tasks.Ingest.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Ingest.MaxRetry))
+	op.RegisterSettingChangingCallback(func() {
+		fs.IngestTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskIngestThreadsNum, conf.Conf.Tasks.Ingest.Workers)))
+	})
+	fs.ScrubTaskManager = tache.NewManager[*fs.ScrubTask](tache.WithWorks(conf.Conf.Tasks.Scrub.Workers), tache.WithPersistFunction(db.GetTaskDataFunc[*fs.ScrubTask]("scrub", conf.Conf.Tasks.Scrub.TaskPersistant), db.UpdateTaskDataFunc("scrub", conf.Conf.Tasks.Scrub.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Scrub.MaxRetry))
+
+	task.RegisterDoneHook(notify.TaskDone)
+	task.WatchDone("upload", fs.UploadTaskManager)
+	task.WatchDone("copy", fs.CopyTaskManager)
+	task.WatchDone("move", fs.MoveTaskManager)
+	task.WatchDone("offline_download", tool.DownloadTaskManager)
+	task.WatchDone("offline_download_transfer", tool.TransferTaskManager)
+	task.WatchDone("decompress", fs.ArchiveDownloadTaskManager)
+	task.WatchDone("decompress_upload", fs.ArchiveContentUploadTaskManager)
+	task.WatchDone("publish", fs.PublishTaskManager)
+	task.WatchDone("ingest", fs.IngestTaskManager)
+	task.WatchDone("scrub", fs.ScrubTaskManager)
 }
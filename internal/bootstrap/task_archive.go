@@ -0,0 +1,63 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	log "github.com/sirupsen/logrus"
+)
+
+var taskArchiveCron *cron.Cron
+
+func InitTaskArchive() {
+	taskArchiveCron = cron.NewCron(24 * time.Hour)
+	taskArchiveCron.Do(archiveTasks)
+	archiveTasks()
+}
+
+func archiveTasks() {
+	days := setting.GetInt(conf.TaskArchiveDays, 0)
+	if days <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	archive := func(typ string, n int, err error) {
+		if err != nil {
+			log.Errorf("failed archive %s tasks: %+v", typ, err)
+		} else if n > 0 {
+			log.Infof("archived %d %s tasks", n, typ)
+		}
+	}
+	// the types are the names of the task routes
+	n, err := task.ArchiveDone("upload", fs.UploadTaskManager, before)
+	archive("upload", n, err)
+	n, err = task.ArchiveDone("copy", fs.CopyTaskManager, before)
+	archive("copy", n, err)
+	n, err = task.ArchiveDone("move", fs.MoveTaskManager, before)
+	archive("move", n, err)
+	n, err = task.ArchiveDone("offline_download", tool.DownloadTaskManager, before)
+	archive("offline_download", n, err)
+	n, err = task.ArchiveDone("offline_download_transfer", tool.TransferTaskManager, before)
+	archive("offline_download_transfer", n, err)
+	n, err = task.ArchiveDone("decompress", fs.ArchiveDownloadTaskManager, before)
+	archive("decompress", n, err)
+	n, err = task.ArchiveDone("decompress_upload", fs.ArchiveContentUploadTaskManager, before)
+	archive("decompress_upload", n, err)
+	n, err = task.ArchiveDone("publish", fs.PublishTaskManager, before)
+	archive("publish", n, err)
+	n, err = task.ArchiveDone("ingest", fs.IngestTaskManager, before)
+	archive("ingest", n, err)
+	n, err = task.ArchiveDone("scrub", fs.ScrubTaskManager, before)
+	archive("scrub", n, err)
+}
+
+func StopTaskArchive() {
+	if taskArchiveCron != nil {
+		taskArchiveCron.Stop()
+	}
+}
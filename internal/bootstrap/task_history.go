@@ -0,0 +1,46 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	log "github.com/sirupsen/logrus"
+)
+
+var taskHistoryCron *cron.Cron
+
+func InitTaskHistory() {
+	task.RegisterDoneHook(func(typ string, t task.TaskExtensionInfo) {
+		if setting.GetInt(conf.TaskHistoryDays, 30) >= 0 {
+			task.RecordHistory(typ, t)
+		}
+	})
+	taskHistoryCron = cron.NewCron(24 * time.Hour)
+	taskHistoryCron.Do(pruneTaskHistory)
+	pruneTaskHistory()
+}
+
+// pruneTaskHistory deletes the task history older than the retention days
+func pruneTaskHistory() {
+	days := setting.GetInt(conf.TaskHistoryDays, 30)
+	if days <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	n, err := db.DeleteTaskHistoriesBefore(before.Format(time.DateOnly))
+	if err != nil {
+		log.Errorf("failed prune the task history: %+v", err)
+	} else if n > 0 {
+		log.Infof("pruned %d tasks from the task history", n)
+	}
+}
+
+func StopTaskHistory() {
+	if taskHistoryCron != nil {
+		taskHistoryCron.Stop()
+	}
+}
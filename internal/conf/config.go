@@ -17,6 +17,14 @@ type Database struct {
 	TablePrefix string `json:"table_prefix" env:"TABLE_PREFIX"`
 	SSLMode     string `json:"ssl_mode" env:"SSL_MODE"`
 	DSN         string `json:"dsn" env:"DSN"`
+	// ManualMigrate refuses to start with pending migrations instead of applying them
+	ManualMigrate bool `json:"manual_migrate" env:"MANUAL_MIGRATE"`
+	// CacheNotify refreshes the caches of the instances sharing the postgres database
+	// by LISTEN/NOTIFY when the settings, storages, metas or users are changed by one of them
+	CacheNotify bool `json:"cache_notify" env:"CACHE_NOTIFY"`
+	// ReplicaDSN is a read-only replica of the mysql or postgres database for the search and the stats queries,
+	// the writes and the other reads stay on the primary
+	ReplicaDSN string `json:"replica_dsn" env:"REPLICA_DSN"`
 }
 
 type Meilisearch struct {
@@ -73,6 +81,9 @@ type TasksConfig struct {
 	Move               TaskConfig `json:"move" envPrefix:"MOVE_"`
 	Decompress         TaskConfig `json:"decompress" envPrefix:"DECOMPRESS_"`
 	DecompressUpload   TaskConfig `json:"decompress_upload" envPrefix:"DECOMPRESS_UPLOAD_"`
+	Publish            TaskConfig `json:"publish" envPrefix:"PUBLISH_"`
+	Ingest             TaskConfig `json:"ingest" envPrefix:"INGEST_"`
+	Scrub              TaskConfig `json:"scrub" envPrefix:"SCRUB_"`
 	AllowRetryCanceled bool       `json:"allow_retry_canceled" env:"ALLOW_RETRY_CANCELED"`
 }
 
@@ -106,25 +117,50 @@ type SFTP struct {
 	Listen string `json:"listen" env:"LISTEN"`
 }
 
+// HttpClient tunes the transport shared by the http clients of the drivers,
+// zero values fall back to the defaults
+type HttpClient struct {
+	MaxIdleConns        int  `json:"max_idle_conns" env:"MAX_IDLE_CONNS"`
+	MaxIdleConnsPerHost int  `json:"max_idle_conns_per_host" env:"MAX_IDLE_CONNS_PER_HOST"`
+	MaxConnsPerHost     int  `json:"max_conns_per_host" env:"MAX_CONNS_PER_HOST"`
+	IdleConnTimeout     int  `json:"idle_conn_timeout" env:"IDLE_CONN_TIMEOUT"`
+	KeepAlive           int  `json:"keep_alive" env:"KEEP_ALIVE"`
+	DisableHTTP2        bool `json:"disable_http2" env:"DISABLE_HTTP2"`
+}
+
+// TempDir is an extra dir used to cache the streams, it's skipped when its free space
+// is below MinFreeMB, and its content is removed on start like the temp dir
+type TempDir struct {
+	Path      string `json:"path"`
+	MinFreeMB int64  `json:"min_free_mb"`
+}
+
 type Config struct {
 	Force                 bool        `json:"force" env:"FORCE"`
 	SiteURL               string      `json:"site_url" env:"SITE_URL"`
 	Cdn                   string      `json:"cdn" env:"CDN"`
 	JwtSecret             string      `json:"jwt_secret" env:"JWT_SECRET"`
+	SecretKey             string      `json:"secret_key" env:"SECRET_KEY"`           // the master key encrypting the storage additions in the database
+	SecretKeyFile         string      `json:"secret_key_file" env:"SECRET_KEY_FILE"` // the file of the master key, preferred over secret_key
+	SecretsDir            string      `json:"secrets_dir" env:"SECRETS_DIR"`         // the dir of the files the storages may reference by file: secrets
 	TokenExpiresIn        int         `json:"token_expires_in" env:"TOKEN_EXPIRES_IN"`
 	Database              Database    `json:"database" envPrefix:"DB_"`
 	Meilisearch           Meilisearch `json:"meilisearch" envPrefix:"MEILISEARCH_"`
 	Scheme                Scheme      `json:"scheme"`
 	TempDir               string      `json:"temp_dir" env:"TEMP_DIR"`
+	TempDirMinFreeMB      int64       `json:"temp_dir_min_free_mb" env:"TEMP_DIR_MIN_FREE_MB"`
+	ExtraTempDirs         []TempDir   `json:"extra_temp_dirs"`
 	BleveDir              string      `json:"bleve_dir" env:"BLEVE_DIR"`
 	DistDir               string      `json:"dist_dir"`
 	Log                   LogConfig   `json:"log" envPrefix:"LOG_"`
 	DelayedStart          int         `json:"delayed_start" env:"DELAYED_START"`
 	MaxBufferLimit        int         `json:"max_buffer_limitMB" env:"MAX_BUFFER_LIMIT_MB"`
+	MaxBufferTotal        int         `json:"max_buffer_totalMB" env:"MAX_BUFFER_TOTAL_MB"`
 	MmapThreshold         int         `json:"mmap_thresholdMB" env:"MMAP_THRESHOLD_MB"`
 	MaxConnections        int         `json:"max_connections" env:"MAX_CONNECTIONS"`
 	MaxConcurrency        int         `json:"max_concurrency" env:"MAX_CONCURRENCY"`
 	TlsInsecureSkipVerify bool        `json:"tls_insecure_skip_verify" env:"TLS_INSECURE_SKIP_VERIFY"`
+	HttpClient            HttpClient  `json:"http_client" envPrefix:"HTTP_CLIENT_"`
 	Tasks                 TasksConfig `json:"tasks" envPrefix:"TASKS_"`
 	Cors                  Cors        `json:"cors" envPrefix:"CORS_"`
 	S3                    S3          `json:"s3" envPrefix:"S3_"`
@@ -132,6 +168,7 @@ type Config struct {
 	SFTP                  SFTP        `json:"sftp" envPrefix:"SFTP_"`
 	LastLaunchedVersion   string      `json:"last_launched_version"`
 	ProxyAddress          string      `json:"proxy_address" env:"PROXY_ADDRESS"`
+	TrustedProxies        []string    `json:"trusted_proxies" env:"TRUSTED_PROXIES"` // the reverse proxies whose X-Forwarded-For gives the client ip, unset to trust any like before
 }
 
 func DefaultConfig(dataDir string) *Config {
@@ -139,6 +176,7 @@ func DefaultConfig(dataDir string) *Config {
 	indexDir := filepath.Join(dataDir, "bleve")
 	logPath := filepath.Join(dataDir, "log/log.log")
 	dbPath := filepath.Join(dataDir, "data.db")
+	secretsDir := filepath.Join(dataDir, "secrets")
 	return &Config{
 		Scheme: Scheme{
 			Address:    "0.0.0.0",
@@ -152,6 +190,7 @@ func DefaultConfig(dataDir string) *Config {
 		JwtSecret:      random.String(16),
 		TokenExpiresIn: 48,
 		TempDir:        tempDir,
+		SecretsDir:     secretsDir,
 		Database: Database{
 			Type:        "sqlite3",
 			Port:        0,
@@ -179,10 +218,19 @@ func DefaultConfig(dataDir string) *Config {
 			},
 		},
 		MaxBufferLimit:        -1,
+		MaxBufferTotal:        -1,
 		MmapThreshold:         4,
 		MaxConnections:        0,
 		MaxConcurrency:        64,
 		TlsInsecureSkipVerify: false,
+		HttpClient: HttpClient{
+			MaxIdleConns:        256,
+			MaxIdleConnsPerHost: 32,
+			MaxConnsPerHost:     0,
+			IdleConnTimeout:     90,
+			KeepAlive:           30,
+			DisableHTTP2:        false,
+		},
 		Tasks: TasksConfig{
 			Download: TaskConfig{
 				Workers:  5,
@@ -196,6 +244,7 @@ func DefaultConfig(dataDir string) *Config {
 			},
 			Upload: TaskConfig{
 				Workers: 5,
+				// TaskPersistant: true,
 			},
 			Copy: TaskConfig{
 				Workers:  5,
@@ -215,6 +264,18 @@ func DefaultConfig(dataDir string) *Config {
 			DecompressUpload: TaskConfig{
 				Workers:  5,
 				MaxRetry: 2,
+				// TaskPersistant: true,
+			},
+			Publish: TaskConfig{
+				Workers:  1,
+				MaxRetry: 1,
+			},
+			Ingest: TaskConfig{
+				Workers:  1,
+				MaxRetry: 1,
+			},
+			Scrub: TaskConfig{
+				Workers: 1,
 			},
 			AllowRetryCanceled: false,
 		},
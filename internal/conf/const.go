@@ -30,6 +30,7 @@ const (
 	ImageTypes                    = "image_types"
 	ProxyTypes                    = "proxy_types"
 	ProxyIgnoreHeaders            = "proxy_ignore_headers"
+	MimeTypes                     = "mime_types"
 	AudioAutoplay                 = "audio_autoplay"
 	VideoAutoplay                 = "video_autoplay"
 	PreviewDownloadByDefault      = "preview_download_by_default"
@@ -59,20 +60,60 @@ const (
 	HandleHookAfterWriting  = "handle_hook_after_writing"
 	HandleHookRateLimit     = "handle_hook_rate_limit"
 	IgnoreSystemFiles       = "ignore_system_files"
+	RecentFilesLimit        = "recent_files_limit"
+	DownloadStatsKeepDays   = "download_stats_keep_days"
+	UploadStatsKeepDays     = "upload_stats_keep_days"
+	WebdavTaskFolder        = "webdav_task_folder"
+	RemoveConfirmFiles      = "remove_confirm_files"
+	RemoveConfirmSize       = "remove_confirm_size"
+	StorageDeleteGraceHours = "storage_delete_grace_hours"
+	RoleFeatureFlags        = "role_feature_flags"
+	RoleStorageGroups       = "role_storage_groups"
+	HomeDirTemplate         = "home_dir_template"
+	TaskArchiveDays         = "task_archive_days"
+	TaskHistoryDays         = "task_history_days"
+	TaskPersistFailedLogs   = "task_persist_failed_logs"
+	DownProxyCheckInterval  = "down_proxy_check_interval"
+	RelayToken              = "relay_token"
+	UserTaskLimits          = "user_task_limits"
+	PutURLMaxSize           = "put_url_max_size"
+	PutURLContentTypes      = "put_url_content_types"
+	PutURLAllowLocal        = "put_url_allow_local"
+	PasteMaxSize            = "paste_max_size"
+	PasteNameTemplate       = "paste_name_template"
+	ImageVariantSizes       = "image_variant_sizes"
+	ImageVariantDir         = "image_variant_dir"
+	ImageVariantPaths       = "image_variant_paths"
+	NotifyTaskTypes         = "notify_task_types"
+	NotifyTaskStates        = "notify_task_states"
+	NotifyWebhookURL        = "notify_webhook_url"
+	NotifyTelegramToken     = "notify_telegram_token"
+	NotifyTelegramChatID    = "notify_telegram_chat_id"
+	NotifyBarkURL           = "notify_bark_url"
+	NotifyEmailURL          = "notify_email_url"
 
 	// index
 	SearchIndex     = "search_index"
 	AutoUpdateIndex = "auto_update_index"
 	IgnorePaths     = "ignore_paths"
 	MaxIndexDepth   = "max_index_depth"
+	ObjectIndex     = "object_index"
 
 	// aria2
-	Aria2Uri    = "aria2_uri"
-	Aria2Secret = "aria2_secret"
+	Aria2Uri      = "aria2_uri"
+	Aria2Secret   = "aria2_secret"
+	Aria2TempPath = "aria2_temp_path"
 
 	// transmission
 	TransmissionUri      = "transmission_uri"
 	TransmissionSeedtime = "transmission_seedtime"
+	TransmissionTempPath = "transmission_temp_path"
+
+	// simple http
+	SimpleHttpTempPath = "simple_http_temp_path"
+
+	OfflineDownloadSpeedSchedule = "offline_download_speed_schedule"
+	OfflineDownloadWatchRules    = "offline_download_watch_rules"
 
 	// 115
 	Pan115TempDir = "115_temp_dir"
@@ -135,6 +176,12 @@ const (
 	// qbittorrent
 	QbittorrentUrl      = "qbittorrent_url"
 	QbittorrentSeedtime = "qbittorrent_seedtime"
+	QbittorrentTempPath = "qbittorrent_temp_path"
+
+	// sabnzbd
+	SabnzbdUrl      = "sabnzbd_url"
+	SabnzbdApiKey   = "sabnzbd_api_key"
+	SabnzbdCategory = "sabnzbd_category"
 
 	// 123 open offline download
 	Pan123OpenOfflineDownloadCallbackUrl = "123_open_callback_url"
@@ -155,12 +202,24 @@ const (
 	TaskUploadThreadsNum                  = "upload_task_threads_num"
 	TaskCopyThreadsNum                    = "copy_task_threads_num"
 	TaskMoveThreadsNum                    = "move_task_threads_num"
+	TaskCopyFilesInFlight                 = "copy_task_files_in_flight"
+	TaskCopySmallFileSize                 = "copy_task_small_file_size"
 	TaskDecompressDownloadThreadsNum      = "decompress_download_task_threads_num"
 	TaskDecompressUploadThreadsNum        = "decompress_upload_task_threads_num"
+	TaskPublishThreadsNum                 = "publish_task_threads_num"
+	TaskIngestThreadsNum                  = "ingest_task_threads_num"
+	TaskScrubFilesPerHour                 = "scrub_task_files_per_hour"
+	TaskUploadSpeedLimit                  = "upload_task_speed_limit"
+	TaskCopySpeedLimit                    = "copy_task_speed_limit"
+	TaskMoveSpeedLimit                    = "move_task_speed_limit"
+	TaskOfflineDownloadTransferSpeedLimit = "offline_download_transfer_task_speed_limit"
 	StreamMaxClientDownloadSpeed          = "max_client_download_speed"
 	StreamMaxClientUploadSpeed            = "max_client_upload_speed"
 	StreamMaxServerDownloadSpeed          = "max_server_download_speed"
 	StreamMaxServerUploadSpeed            = "max_server_upload_speed"
+	StreamProxyResumeRetries              = "proxy_resume_retries"
+	StreamMaxConnectionDownloadSpeed      = "max_connection_download_speed"
+	StreamConnectionDownloadBurst         = "connection_download_burst"
 )
 
 const (
@@ -191,4 +250,19 @@ const (
 	PathKey
 	SharingIDKey
 	SkipHookKey
+	TransactionalKey
+	VerifyKey
+	ProtocolKey
+	PauseTasksKey
+	RequestIDKey
+	TaskPriorityKey
+	TaskSpeedLimitKey
+	TaskLimitersKey
+	TaskLoggerKey
+	// NestedPutKey marks the puts of the drivers into the storages they wrap, counted by the outer put
+	NestedPutKey
+	// ServerPutKey marks the puts of the data copied or fetched by the server, which aren't the uploads of the user
+	ServerPutKey
+	// NetworkOptionsKey carries the network options of the storage the driver makes its requests for
+	NetworkOptionsKey
 )
@@ -27,6 +27,8 @@ var PrivacyReg []*regexp.Regexp
 var (
 	// 单个Buffer最大限制
 	MaxBufferLimit = 16 * 1024 * 1024
+	// 所有流缓存在内存中的总量限制，超出后使用临时文件，0为不限制
+	MaxBufferTotal = 0
 	// 超过该阈值的Buffer将使用 mmap 分配，可主动释放内存
 	MmapThreshold = 4 * 1024 * 1024
 )
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+// GetAccessGrants returns the grants of the user, or of all users if userId is 0, the latest first
+func GetAccessGrants(userId uint, pageIndex, pageSize int) (grants []model.AccessGrant, count int64, err error) {
+	grantDB := db.Model(&model.AccessGrant{})
+	if userId != 0 {
+		grantDB = grantDB.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId)
+	}
+	if err := grantDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get access grants count")
+	}
+	if err := grantDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&grants).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find access grants")
+	}
+	return grants, count, nil
+}
+
+// GetActiveAccessGrants returns the grants of the user which are neither revoked nor expired
+func GetActiveAccessGrants(userId uint) (grants []model.AccessGrant, err error) {
+	err = db.Where(fmt.Sprintf("%s = ? AND %s IS NULL AND %s > ?", columnName("user_id"), columnName("revoked"), columnName("expires")),
+		userId, time.Now()).Find(&grants).Error
+	return grants, errors.Wrapf(err, "failed find active access grants")
+}
+
+func GetAccessGrantById(id uint) (*model.AccessGrant, error) {
+	var g model.AccessGrant
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find access grant")
+	}
+	return &g, nil
+}
+
+func CreateAccessGrant(g *model.AccessGrant) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateAccessGrant(g *model.AccessGrant) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+func DeleteAccessGrantsByUserId(userId uint) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId).Delete(&model.AccessGrant{}).Error)
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetAccessHistoriesByUserId(userId uint, pageIndex, pageSize int) (histories []model.AccessHistory, count int64, err error) {
+	historyDB := db.Model(&model.AccessHistory{})
+	query := model.AccessHistory{UserId: userId}
+	if err := historyDB.Where(query).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get user's access histories count")
+	}
+	if err := historyDB.Where(query).Order(columnName("accessed") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&histories).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get find user's access histories")
+	}
+	return histories, count, nil
+}
+
+// RecordAccessHistory counts an access of the user to the path at the time, the path is added if it's new
+func RecordAccessHistory(userId uint, path string, accessed time.Time) error {
+	h := model.AccessHistory{UserId: userId, Path: path, Count: 1, Accessed: accessed}
+	return errors.WithStack(db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "path"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count":    gorm.Expr(columnName("count") + " + 1"),
+			"accessed": accessed,
+		}),
+	}).Create(&h).Error)
+}
+
+// TrimAccessHistories keeps only the latest max access histories of the user
+func TrimAccessHistories(userId uint, max int) error {
+	var ids []uint
+	err := db.Model(&model.AccessHistory{}).Where(model.AccessHistory{UserId: userId}).
+		Order(columnName("accessed")+" desc").Offset(max).Limit(1<<16).Pluck("id", &ids).Error
+	if err != nil {
+		return errors.Wrapf(err, "failed find outdated access histories")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return errors.WithStack(db.Delete(&model.AccessHistory{}, ids).Error)
+}
+
+func DeleteAccessHistoryByUserPath(userId uint, path string) error {
+	return errors.WithStack(db.Where(model.AccessHistory{UserId: userId, Path: path}).Delete(&model.AccessHistory{}).Error)
+}
+
+func DeleteAccessHistoriesByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.AccessHistory{}).Error)
+}
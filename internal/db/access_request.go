@@ -0,0 +1,56 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+// GetAccessRequests returns the requests of the user, or of all users if userId is 0,
+// with the status if it isn't empty, the latest first
+func GetAccessRequests(userId uint, status string, pageIndex, pageSize int) (requests []model.AccessRequest, count int64, err error) {
+	requestDB := db.Model(&model.AccessRequest{})
+	if userId != 0 {
+		requestDB = requestDB.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId)
+	}
+	if status != "" {
+		requestDB = requestDB.Where(fmt.Sprintf("%s = ?", columnName("status")), status)
+	}
+	if err := requestDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get access requests count")
+	}
+	if err := requestDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&requests).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find access requests")
+	}
+	return requests, count, nil
+}
+
+func GetAccessRequestById(id uint) (*model.AccessRequest, error) {
+	var r model.AccessRequest
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find access request")
+	}
+	return &r, nil
+}
+
+// GetPendingAccessRequest returns the pending request of the user for the path
+func GetPendingAccessRequest(userId uint, path string, write bool) (*model.AccessRequest, error) {
+	r := model.AccessRequest{UserId: userId, Path: path, Write: write, Status: model.AccessRequestPending}
+	if err := db.Where(&r, "UserId", "Path", "Write", "Status").First(&r).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find access request")
+	}
+	return &r, nil
+}
+
+func CreateAccessRequest(r *model.AccessRequest) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateAccessRequest(r *model.AccessRequest) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func DeleteAccessRequestsByUserId(userId uint) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId).Delete(&model.AccessRequest{}).Error)
+}
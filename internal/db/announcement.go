@@ -0,0 +1,44 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetAnnouncementById(id uint) (*model.Announcement, error) {
+	var a model.Announcement
+	if err := db.First(&a, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get announcement")
+	}
+	return &a, nil
+}
+
+func GetAnnouncements(pageIndex, pageSize int) (announcements []model.Announcement, count int64, err error) {
+	announcementDB := db.Model(&model.Announcement{})
+	if err := announcementDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get announcements count")
+	}
+	if err := announcementDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&announcements).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get find announcements")
+	}
+	return announcements, count, nil
+}
+
+func GetEnabledAnnouncements() (announcements []model.Announcement, err error) {
+	if err := db.Where(map[string]any{"disabled": false}).Order(columnName("id") + " desc").Find(&announcements).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get enabled announcements")
+	}
+	return announcements, nil
+}
+
+func CreateAnnouncement(a *model.Announcement) error {
+	return errors.WithStack(db.Create(a).Error)
+}
+
+func UpdateAnnouncement(a *model.Announcement) error {
+	return errors.WithStack(db.Save(a).Error)
+}
+
+func DeleteAnnouncementById(id uint) error {
+	return errors.WithStack(db.Delete(&model.Announcement{}, id).Error)
+}
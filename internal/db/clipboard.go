@@ -0,0 +1,22 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetClipboardByUserId(userId uint) (*model.Clipboard, error) {
+	var c model.Clipboard
+	if err := db.Where("user_id = ?", userId).First(&c).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find clipboard")
+	}
+	return &c, nil
+}
+
+func SaveClipboard(c *model.Clipboard) error {
+	return errors.WithStack(db.Save(c).Error)
+}
+
+func DeleteClipboardByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.Clipboard{}).Error)
+}
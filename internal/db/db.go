@@ -4,36 +4,73 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
-	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"gorm.io/gorm"
 )
 
 var db *gorm.DB
 
+// replica serves the heavy reads which can lag behind db, nil if there is no replica
+var replica *gorm.DB
+
 func Init(d *gorm.DB) {
-	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.SharingDB))
+	Open(d)
+	pending, err := PendingMigrations()
 	if err != nil {
-		log.Fatalf("failed migrate database: %s", err.Error())
+		log.Fatalf("failed get the pending migrations: %+v", err)
+	}
+	if len(pending) == 0 {
+		return
+	}
+	if conf.Conf.Database.ManualMigrate {
+		log.Fatalf("the database has %d pending migrations, review them with `openlist db migrate --plan` and apply them with `openlist db migrate --apply`", len(pending))
+	}
+	if err = ApplyMigrations(nil); err != nil {
+		log.Fatalf("failed migrate database: %+v", err)
+	}
+}
+
+// Open uses d as the database without migrating it
+func Open(d *gorm.DB) {
+	db = d
+	if err := initMasterKey(); err != nil {
+		log.Fatalf("failed init secret key: %+v", err)
 	}
 }
 
 func AutoMigrate(dst ...interface{}) error {
-	var err error
+	return autoMigrate(db, dst...)
+}
+
+func autoMigrate(tx *gorm.DB, dst ...interface{}) error {
 	if conf.Conf.Database.Type == "mysql" {
-		err = db.Set("gorm:table_options", "ENGINE=InnoDB CHARSET=utf8mb4").AutoMigrate(dst...)
-	} else {
-		err = db.AutoMigrate(dst...)
+		return tx.Set("gorm:table_options", "ENGINE=InnoDB CHARSET=utf8mb4").AutoMigrate(dst...)
 	}
-	return err
+	return tx.AutoMigrate(dst...)
 }
 
 func GetDb() *gorm.DB {
 	return db
 }
 
+func SetReplica(r *gorm.DB) {
+	replica = r
+}
+
+// readDB returns the replica if there is one, for the reads which don't need the latest writes
+func readDB() *gorm.DB {
+	if replica != nil {
+		return replica
+	}
+	return db
+}
+
 func Close() {
 	log.Info("closing db")
+	if replica != nil {
+		if sqlDB, err := replica.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("failed to get db: %s", err.Error())
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// AddDownloadStat adds the count and bytes of s to the existing rollup, or creates it
+func AddDownloadStat(s *model.DownloadStat) error {
+	var old model.DownloadStat
+	err := db.Where(fmt.Sprintf("%s = ? AND %s = ? AND %s = ?", columnName("day"), columnName("path"), columnName("user_id")),
+		s.Day, s.Path, s.UserId).First(&old).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.WithStack(db.Create(s).Error)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed get download stat")
+	}
+	old.Count += s.Count
+	old.Bytes += s.Bytes
+	old.Storage = s.Storage
+	return errors.WithStack(db.Save(&old).Error)
+}
+
+// GetDownloadStats aggregates the download stats between from and to by the column groupBy
+func GetDownloadStats(from, to, groupBy, orderBy string, limit int) (items []model.DownloadStatsItem, err error) {
+	key := columnName(groupBy)
+	query := readDB().Model(&model.DownloadStat{}).
+		Select(fmt.Sprintf("%s as %s, sum(%s) as %s, sum(%s) as %s", key, columnName("key"),
+			columnName("count"), columnName("count"), columnName("bytes"), columnName("bytes")))
+	if from != "" {
+		query = query.Where(fmt.Sprintf("%s >= ?", columnName("day")), from)
+	}
+	if to != "" {
+		query = query.Where(fmt.Sprintf("%s <= ?", columnName("day")), to)
+	}
+	err = query.Group(key).Order(columnName(orderBy) + " desc").Limit(limit).Scan(&items).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get download stats")
+	}
+	return items, nil
+}
+
+func DeleteDownloadStatsBefore(day string) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s < ?", columnName("day")), day).Delete(&model.DownloadStat{}).Error)
+}
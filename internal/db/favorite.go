@@ -0,0 +1,45 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetFavoritesByUserId(userId uint, pageIndex, pageSize int) (favorites []model.Favorite, count int64, err error) {
+	favoriteDB := db.Model(&model.Favorite{})
+	query := model.Favorite{UserId: userId}
+	if err := favoriteDB.Where(query).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get user's favorites count")
+	}
+	if err := favoriteDB.Where(query).Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&favorites).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get find user's favorites")
+	}
+	return favorites, count, nil
+}
+
+func GetAllFavoritePathsByUserId(userId uint) (paths []string, err error) {
+	if err := db.Model(&model.Favorite{}).Where(model.Favorite{UserId: userId}).Pluck("path", &paths).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get user's favorite paths")
+	}
+	return paths, nil
+}
+
+func GetFavoriteByUserPath(userId uint, path string) (*model.Favorite, error) {
+	f := model.Favorite{UserId: userId, Path: path}
+	if err := db.Where(f).First(&f).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find favorite")
+	}
+	return &f, nil
+}
+
+func CreateFavorite(f *model.Favorite) error {
+	return errors.WithStack(db.Create(f).Error)
+}
+
+func DeleteFavoriteByUserPath(userId uint, path string) error {
+	return errors.WithStack(db.Where(model.Favorite{UserId: userId, Path: path}).Delete(&model.Favorite{}).Error)
+}
+
+func DeleteFavoritesByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.Favorite{}).Error)
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetIndexExportById(id uint) (*model.IndexExport, error) {
+	var e model.IndexExport
+	if err := db.First(&e, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get index export")
+	}
+	return &e, nil
+}
+
+func GetIndexExports() (exports []model.IndexExport, err error) {
+	if err := db.Order(columnName("id")).Find(&exports).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get index exports")
+	}
+	return exports, nil
+}
+
+func CreateIndexExport(e *model.IndexExport) error {
+	return errors.WithStack(db.Create(e).Error)
+}
+
+func UpdateIndexExport(e *model.IndexExport) error {
+	return errors.WithStack(db.Save(e).Error)
+}
+
+func DeleteIndexExportById(id uint) error {
+	return errors.WithStack(db.Delete(&model.IndexExport{}, id).Error)
+}
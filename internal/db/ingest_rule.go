@@ -0,0 +1,33 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetIngestRuleById(id uint) (*model.IngestRule, error) {
+	var r model.IngestRule
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get ingest rule")
+	}
+	return &r, nil
+}
+
+func GetIngestRules() (rules []model.IngestRule, err error) {
+	if err := db.Order(columnName("id")).Find(&rules).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get ingest rules")
+	}
+	return rules, nil
+}
+
+func CreateIngestRule(r *model.IngestRule) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateIngestRule(r *model.IngestRule) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func DeleteIngestRuleById(id uint) error {
+	return errors.WithStack(db.Delete(&model.IngestRule{}, id).Error)
+}
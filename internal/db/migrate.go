@@ -0,0 +1,402 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Migration is a versioned change of the schema, the migrations are applied in the order of migrations
+// and the applied ones are recorded in the schema_migrations table
+type Migration struct {
+	Version string
+	Name    string
+	// Models are auto migrated before Up
+	Models []interface{}
+	Up     func(tx *gorm.DB) error
+	// DropOnRollback drops the tables of Models when the migration is rolled back,
+	// the migrations which neither drop them nor have Down can't be rolled back
+	DropOnRollback bool
+	Down           func(tx *gorm.DB) error
+}
+
+func (m *Migration) String() string {
+	return m.Version + "_" + m.Name
+}
+
+func (m *Migration) Reversible() bool {
+	return m.DropOnRollback || m.Down != nil
+}
+
+// migrations must only be appended to, the new tables and columns need a new migration
+var migrations = []Migration{
+	{
+		Version: "0001",
+		Name:    "baseline",
+		Models: []interface{}{new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode),
+			new(model.TaskItem), new(model.SSHPublicKey), new(model.SharingDB), new(model.Announcement), new(model.Favorite),
+			new(model.AccessHistory), new(model.DownloadStat), new(model.Clipboard), new(model.IndexExport), new(model.IngestRule),
+			new(model.ScrubFile), new(model.Tag)},
+	},
+	{
+		Version:        "0002",
+		Name:           "upload_stats",
+		Models:         []interface{}{new(model.UploadStat)},
+		DropOnRollback: true,
+	},
+	{
+		Version:        "0003",
+		Name:           "access_grants",
+		Models:         []interface{}{new(model.AccessGrant)},
+		DropOnRollback: true,
+	},
+	{
+		Version:        "0004",
+		Name:           "access_requests",
+		Models:         []interface{}{new(model.AccessRequest)},
+		DropOnRollback: true,
+	},
+	{
+		Version:        "0005",
+		Name:           "task_archives",
+		Models:         []interface{}{new(model.TaskArchive)},
+		DropOnRollback: true,
+	},
+	{
+		Version:        "0006",
+		Name:           "object_indexes",
+		Models:         []interface{}{new(model.ObjectIndex)},
+		DropOnRollback: true,
+	},
+	{
+		Version: "0007",
+		Name:    "storage_proxy_rules",
+		Models:  []interface{}{new(model.Storage)},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(new(model.Storage), "proxy_rules"); err != nil {
+				return errors.WithStack(err)
+			}
+			// sqlite drops the columns by recreating the table without its indexes
+			return restoreIndexes(tx, new(model.Storage))
+		},
+	},
+	{
+		Version:        "0008",
+		Name:           "storage_traffics",
+		Models:         []interface{}{new(model.StorageTraffic)},
+		DropOnRollback: true,
+	},
+	{
+		Version: "0009",
+		Name:    "storage_egress_cost",
+		Models:  []interface{}{new(model.Storage)},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(new(model.Storage), "egress_cost"); err != nil {
+				return errors.WithStack(err)
+			}
+			return restoreIndexes(tx, new(model.Storage))
+		},
+	},
+	{
+		Version: "0010",
+		Name:    "task_archive_throughput",
+		Models:  []interface{}{new(model.TaskArchive)},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"transferred_bytes", "speed"} {
+				if err := tx.Migrator().DropColumn(new(model.TaskArchive), column); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			return restoreIndexes(tx, new(model.TaskArchive))
+		},
+	},
+	{
+		Version:        "0011",
+		Name:           "schedules",
+		Models:         []interface{}{new(model.Schedule)},
+		DropOnRollback: true,
+	},
+	{
+		Version:        "0012",
+		Name:           "task_histories",
+		Models:         []interface{}{new(model.TaskHistory)},
+		DropOnRollback: true,
+	},
+	{
+		Version: "0013",
+		Name:    "access_history_unique_path",
+		Up: func(tx *gorm.DB) error {
+			if err := mergeAccessHistories(tx); err != nil {
+				return err
+			}
+			return restoreIndexes(tx, new(model.AccessHistory))
+		},
+		Down: func(tx *gorm.DB) error {
+			return errors.WithStack(tx.Migrator().DropIndex(new(model.AccessHistory), "idx_access_histories_user_path"))
+		},
+	},
+}
+
+// mergeAccessHistories merges the access histories of the same user and path recorded twice by concurrent accesses,
+// so that they can be unique
+func mergeAccessHistories(tx *gorm.DB) error {
+	var dups []struct {
+		UserId uint
+		Path   string
+	}
+	err := tx.Model(&model.AccessHistory{}).Select("user_id, path").Group("user_id, path").
+		Having("COUNT(*) > 1").Scan(&dups).Error
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, dup := range dups {
+		var histories []model.AccessHistory
+		err = tx.Where(model.AccessHistory{UserId: dup.UserId, Path: dup.Path}).
+			Order(columnName("accessed") + " desc").Find(&histories).Error
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		latest, ids := histories[0], make([]uint, 0, len(histories)-1)
+		for _, h := range histories[1:] {
+			latest.Count += h.Count
+			ids = append(ids, h.ID)
+		}
+		if err = tx.Delete(&model.AccessHistory{}, ids).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if err = tx.Model(&latest).Update("count", latest.Count).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// restoreIndexes creates the indexes of the model missing from the database
+func restoreIndexes(tx *gorm.DB, v interface{}) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(v); err != nil {
+		return errors.WithStack(err)
+	}
+	for name := range stmt.Schema.ParseIndexes() {
+		if tx.Migrator().HasIndex(v, name) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(v, name); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// MigrationPlan is what applying or rolling back a migration changes
+type MigrationPlan struct {
+	Migration *Migration
+	Changes   []string
+}
+
+// GetAppliedMigrations returns the applied migrations, the latest last
+func GetAppliedMigrations() ([]model.SchemaMigration, error) {
+	if !db.Migrator().HasTable(&model.SchemaMigration{}) {
+		return nil, nil
+	}
+	var applied []model.SchemaMigration
+	err := db.Order(columnName("version")).Find(&applied).Error
+	return applied, errors.Wrap(err, "failed get the applied migrations")
+}
+
+func PendingMigrations() ([]*Migration, error) {
+	applied, err := GetAppliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[string]struct{}, len(applied))
+	for _, m := range applied {
+		versions[m.Version] = struct{}{}
+	}
+	var pending []*Migration
+	for i := range migrations {
+		if _, ok := versions[migrations[i].Version]; !ok {
+			pending = append(pending, &migrations[i])
+		}
+	}
+	return pending, nil
+}
+
+func tableName(v interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(v); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// planModels lists the tables, columns and indexes of the models missing from the database
+func planModels(models []interface{}) ([]string, error) {
+	var changes []string
+	migrator := db.Migrator()
+	for _, v := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(v); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		table := stmt.Schema.Table
+		if !migrator.HasTable(v) {
+			changes = append(changes, "create table "+table)
+			continue
+		}
+		for _, name := range stmt.Schema.DBNames {
+			if !migrator.HasColumn(v, name) {
+				changes = append(changes, fmt.Sprintf("add column %s.%s", table, name))
+			}
+		}
+		for name := range stmt.Schema.ParseIndexes() {
+			if !migrator.HasIndex(v, name) {
+				changes = append(changes, fmt.Sprintf("create index %s on %s", name, table))
+			}
+		}
+	}
+	return changes, nil
+}
+
+// PlanMigrations returns the changes of the pending migrations without applying them
+func PlanMigrations() ([]MigrationPlan, error) {
+	pending, err := PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var plans []MigrationPlan
+	for _, m := range pending {
+		changes, err := planModels(m.Models)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed plan migration %s", m)
+		}
+		if m.Up != nil {
+			changes = append(changes, "run the data migration")
+		}
+		plans = append(plans, MigrationPlan{Migration: m, Changes: changes})
+	}
+	return plans, nil
+}
+
+// ApplyMigrations applies the pending migrations in order, applied is called after each of them
+func ApplyMigrations(applied func(m *Migration)) error {
+	if err := autoMigrate(db, new(model.SchemaMigration)); err != nil {
+		return errors.Wrap(err, "failed create the schema_migrations table")
+	}
+	pending, err := PendingMigrations()
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		if len(m.Models) > 0 {
+			if err = autoMigrate(db, m.Models...); err != nil {
+				return errors.Wrapf(err, "failed apply migration %s", m)
+			}
+		}
+		if m.Up != nil {
+			if err = m.Up(db); err != nil {
+				return errors.WithMessagef(err, "failed apply migration %s", m)
+			}
+		}
+		record := model.SchemaMigration{Version: m.Version, Name: m.Name, Applied: time.Now()}
+		if err = db.Create(&record).Error; err != nil {
+			return errors.Wrapf(err, "failed record migration %s", m)
+		}
+		log.Infof("applied migration %s", m)
+		if applied != nil {
+			applied(m)
+		}
+	}
+	return nil
+}
+
+// lastAppliedMigrations returns the latest n applied migrations, the latest first
+func lastAppliedMigrations(n int) ([]*Migration, error) {
+	applied, err := GetAppliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var last []*Migration
+	for i := len(applied) - 1; i >= 0 && len(last) < n; i-- {
+		found := false
+		for j := range migrations {
+			if migrations[j].Version == applied[i].Version {
+				last = append(last, &migrations[j])
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("migration %s_%s is unknown to this version", applied[i].Version, applied[i].Name)
+		}
+	}
+	return last, nil
+}
+
+// PlanRollback returns the changes of rolling back the latest n migrations without rolling them back
+func PlanRollback(n int) ([]MigrationPlan, error) {
+	last, err := lastAppliedMigrations(n)
+	if err != nil {
+		return nil, err
+	}
+	var plans []MigrationPlan
+	for _, m := range last {
+		if !m.Reversible() {
+			return nil, errors.Errorf("migration %s can't be rolled back", m)
+		}
+		var changes []string
+		if m.Down != nil {
+			changes = append(changes, "run the data rollback")
+		}
+		if m.DropOnRollback {
+			for i := len(m.Models) - 1; i >= 0; i-- {
+				table, err := tableName(m.Models[i])
+				if err != nil {
+					return nil, err
+				}
+				changes = append(changes, "drop table "+table)
+			}
+		}
+		plans = append(plans, MigrationPlan{Migration: m, Changes: changes})
+	}
+	return plans, nil
+}
+
+// RollbackMigrations rolls back the latest n migrations, the latest first,
+// rolledBack is called after each of them
+func RollbackMigrations(n int, rolledBack func(m *Migration)) error {
+	// check that all of them can be rolled back before changing anything
+	if _, err := PlanRollback(n); err != nil {
+		return err
+	}
+	last, err := lastAppliedMigrations(n)
+	if err != nil {
+		return err
+	}
+	for _, m := range last {
+		if m.Down != nil {
+			if err = m.Down(db); err != nil {
+				return errors.WithMessagef(err, "failed roll back migration %s", m)
+			}
+		}
+		if m.DropOnRollback {
+			for i := len(m.Models) - 1; i >= 0; i-- {
+				if err = db.Migrator().DropTable(m.Models[i]); err != nil {
+					return errors.Wrapf(err, "failed roll back migration %s", m)
+				}
+			}
+		}
+		if err = db.Delete(&model.SchemaMigration{Version: m.Version}).Error; err != nil {
+			return errors.Wrapf(err, "failed unrecord migration %s", m)
+		}
+		log.Infof("rolled back migration %s", m)
+		if rolledBack != nil {
+			rolledBack(m)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pkg/errors"
+)
+
+// Notify sends the payload to the listeners of the postgres channel
+func Notify(channel, payload string) error {
+	return errors.WithStack(db.Exec("SELECT pg_notify(?, ?)", channel, payload).Error)
+}
+
+// Listen passes the payloads sent to the postgres channel to handle until ctx is done or the connection is lost,
+// ready is called once the channel is listened. It holds a connection of the pool the whole time
+func Listen(ctx context.Context, channel string, ready func(), handle func(payload string)) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer conn.Close()
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("listening needs a postgres database")
+		}
+		pgConn := c.Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return errors.WithStack(err)
+		}
+		// the connection goes back to the pool afterwards
+		defer pgConn.Exec(context.Background(), "UNLISTEN *")
+		ready()
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			handle(notification.Payload)
+		}
+	})
+}
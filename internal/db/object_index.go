@@ -0,0 +1,56 @@
+package db
+
+import (
+	"fmt"
+	stdpath "path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ReplaceObjectIndexes replaces the indexed objects directly in parent with objs
+func ReplaceObjectIndexes(parent string, objs []model.ObjectIndex) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(fmt.Sprintf("%s = ?", columnName("parent")), parent).Delete(&model.ObjectIndex{}).Error; err != nil {
+			return err
+		}
+		if len(objs) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(objs, 500).Error
+	}))
+}
+
+// DeleteObjectIndexesByPath deletes the indexed object of the path and everything under it
+func DeleteObjectIndexesByPath(path string) error {
+	if err := db.Where(whereInParent(path)).Delete(&model.ObjectIndex{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	dir, name := stdpath.Split(path)
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ? AND %s = ?", columnName("parent"), columnName("name")),
+		stdpath.Clean(dir), name).Delete(&model.ObjectIndex{}).Error)
+}
+
+func ClearObjectIndexes() error {
+	return errors.WithStack(db.Where("1 = 1").Delete(&model.ObjectIndex{}).Error)
+}
+
+// GetObjectIndexes returns the indexed objects under req.Parent filtered and sorted by req
+func GetObjectIndexes(req model.ObjectIndexReq) (objs []model.ObjectIndex, count int64, err error) {
+	indexDB := readDB().Model(&model.ObjectIndex{}).Where(whereInParent(req.Parent))
+	if len(req.Exts) > 0 {
+		indexDB = indexDB.Where(fmt.Sprintf("%s IN ?", columnName("ext")), req.Exts)
+	}
+	if req.Scope != 0 {
+		indexDB = indexDB.Where(fmt.Sprintf("%s = ?", columnName("is_dir")), req.Scope == 1)
+	}
+	if err := indexDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get object indexes count")
+	}
+	order := fmt.Sprintf("%s %s, %s", columnName(req.OrderBy), req.OrderDirection, columnName("id"))
+	if err := indexDB.Order(order).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).Find(&objs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find object indexes")
+	}
+	return objs, count, nil
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetScheduleById(id uint) (*model.Schedule, error) {
+	var s model.Schedule
+	if err := db.First(&s, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get schedule")
+	}
+	return &s, nil
+}
+
+func GetSchedules() (schedules []model.Schedule, err error) {
+	if err := db.Order(columnName("id")).Find(&schedules).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get schedules")
+	}
+	return schedules, nil
+}
+
+func CreateSchedule(s *model.Schedule) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateSchedule(s *model.Schedule) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+func DeleteScheduleById(id uint) error {
+	return errors.WithStack(db.Delete(&model.Schedule{}, id).Error)
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetScrubFileByPath(path string) (*model.ScrubFile, error) {
+	f := model.ScrubFile{Path: path}
+	if err := db.Where(f).First(&f).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find scrub file")
+	}
+	return &f, nil
+}
+
+func SaveScrubFile(f *model.ScrubFile) error {
+	return errors.WithStack(db.Save(f).Error)
+}
+
+func GetScrubFiles(pageIndex, pageSize int, failedOnly bool) (files []model.ScrubFile, count int64, err error) {
+	scrubDB := db.Model(&model.ScrubFile{})
+	if failedOnly {
+		scrubDB = scrubDB.Where(columnName("error") + " <> ''")
+	}
+	if err := scrubDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get scrub files count")
+	}
+	if err := scrubDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&files).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find scrub files")
+	}
+	return files, count, nil
+}
+
+// GetScrubFilesToVerify returns the files which are never scrubbed or scrubbed the longest time ago
+func GetScrubFilesToVerify(limit int) (files []model.ScrubFile, err error) {
+	lastScrub := columnName("last_scrub")
+	if err := db.Order(lastScrub + " IS NOT NULL").Order(lastScrub).Limit(limit).Find(&files).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find scrub files to verify")
+	}
+	return files, nil
+}
+
+func DeleteScrubFileById(id uint) error {
+	return errors.WithStack(db.Delete(&model.ScrubFile{}, id).Error)
+}
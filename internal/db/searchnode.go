@@ -61,14 +61,14 @@ func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int6
 		for _, keyword := range strings.Fields(req.Keywords) {
 			keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
 		}
-		searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).Where(keywordsClause)
+		searchDB = readDB().Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).Where(keywordsClause)
 	} else {
 		switch conf.Conf.Database.Type {
 		case "mysql":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
+			searchDB = readDB().Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
 				Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
 		case "postgres":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
+			searchDB = readDB().Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
 				Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
 		}
 	}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/pkg/errors"
+	"gorm.io/gorm/schema"
+)
+
+// secretPrefix marks the values encrypted by the secret serializer,
+// the values without it are plaintext written before the master key was set
+const secretPrefix = "enc:v1:"
+
+// masterKey encrypts the data keys of the secret values, nil if no master key is configured
+var masterKey []byte
+
+func init() {
+	schema.RegisterSerializer("secret", SecretSerializer{})
+}
+
+// initMasterKey loads the master key from the secret_key config or the secret_key_file
+func initMasterKey() error {
+	key := conf.Conf.SecretKey
+	if conf.Conf.SecretKeyFile != "" {
+		b, err := os.ReadFile(conf.Conf.SecretKeyFile)
+		if err != nil {
+			return errors.Wrap(err, "failed read secret key file")
+		}
+		key = strings.TrimSpace(string(b))
+	}
+	if key == "" {
+		masterKey = nil
+		return nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	masterKey = sum[:]
+	return nil
+}
+
+func seal(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func unseal(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("invalid sealed data")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+// EncryptSecret encrypts s with a random data key which is encrypted with the master key,
+// s is returned as is if no master key is configured
+func EncryptSecret(s string) (string, error) {
+	if masterKey == nil || s == "" {
+		return s, nil
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", errors.WithStack(err)
+	}
+	wrappedKey, err := seal(masterKey, dataKey)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	data, err := seal(dataKey, []byte(s))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return fmt.Sprintf("%s%s:%s", secretPrefix,
+		base64.StdEncoding.EncodeToString(wrappedKey),
+		base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// DecryptSecret decrypts the value encrypted by EncryptSecret, the plaintext values are returned as is
+func DecryptSecret(s string) (string, error) {
+	if !strings.HasPrefix(s, secretPrefix) {
+		return s, nil
+	}
+	if masterKey == nil {
+		return "", errors.New("the value is encrypted but no secret key is configured")
+	}
+	wrapped, data, ok := strings.Cut(strings.TrimPrefix(s, secretPrefix), ":")
+	if !ok {
+		return "", errors.New("invalid encrypted value")
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	dataKey, err := unseal(masterKey, wrappedKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed decrypt the data key, is the secret key right?")
+	}
+	plaintext, err := unseal(dataKey, sealed)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(plaintext), nil
+}
+
+// SecretSerializer encrypts the string fields tagged with `serializer:secret` when they are written
+// and decrypts them when they are read, so that the rest of the code only sees the plaintext
+type SecretSerializer struct{}
+
+func (SecretSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var s string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return errors.Errorf("unsupported secret value type %T", dbValue)
+	}
+	plaintext, err := DecryptSecret(s)
+	if err != nil {
+		return errors.WithMessagef(err, "failed decrypt %s", field.Name)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+func (SecretSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	s, ok := fieldValue.(string)
+	if !ok {
+		return nil, errors.Errorf("unsupported secret field type %T", fieldValue)
+	}
+	return EncryptSecret(s)
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/pkg/errors"
+)
+
+func isSQLite() bool {
+	return conf.Conf.Database.Type == "sqlite3"
+}
+
+// OptimizeSQLite refreshes the query planner statistics, returns the free pages to the file system
+// and truncates the write-ahead log, it's a no-op for the other databases
+func OptimizeSQLite() error {
+	if !isSQLite() {
+		return nil
+	}
+	for _, pragma := range []string{"PRAGMA optimize", "PRAGMA incremental_vacuum", "PRAGMA wal_checkpoint(TRUNCATE)"} {
+		if err := db.Exec(pragma).Error; err != nil {
+			return errors.Wrapf(err, "failed %s", pragma)
+		}
+	}
+	return nil
+}
+
+// BackupSQLite writes a consistent copy of the database into the new file dst while it's in use
+func BackupSQLite(dst string) error {
+	if !isSQLite() {
+		return errors.Errorf("the online backup only supports sqlite3, not %s", conf.Conf.Database.Type)
+	}
+	return errors.Wrap(db.Exec("VACUUM INTO ?", dst).Error, "failed backup database")
+}
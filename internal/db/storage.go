@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/pkg/errors"
@@ -68,3 +69,38 @@ func GetEnabledStorages() ([]model.Storage, error) {
 	}
 	return storages, nil
 }
+
+// GetStoragesDeletedBefore Get the deleted storages to be dropped before t
+func GetStoragesDeletedBefore(t time.Time) ([]model.Storage, error) {
+	var storages []model.Storage
+	err := db.Where(fmt.Sprintf("%s IS NOT NULL AND %s <= ?", columnName("delete_at"), columnName("delete_at")), t).Find(&storages).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return storages, nil
+}
+
+// GetStoragesByGroup Get all storages of a group, including the disabled ones
+func GetStoragesByGroup(group string) ([]model.Storage, error) {
+	var storages []model.Storage
+	err := addStorageOrder(db).Where(fmt.Sprintf("%s = ?", columnName("group")), group).Find(&storages).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return storages, nil
+}
+
+// GetStorageGroups Get the groups with the number of their storages
+func GetStorageGroups() ([]model.StorageGroup, error) {
+	var groups []model.StorageGroup
+	err := db.Model(&model.Storage{}).
+		Select(fmt.Sprintf("%s AS name, COUNT(*) AS storages, SUM(CASE WHEN %s THEN 0 ELSE 1 END) AS enabled", columnName("group"), columnName("disabled"))).
+		Where(fmt.Sprintf("%s <> ?", columnName("group")), "").
+		Group(columnName("group")).
+		Order("name").
+		Scan(&groups).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return groups, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+// AddStorageTraffic adds the bytes of t to the existing rollup, or creates it
+func AddStorageTraffic(t *model.StorageTraffic) error {
+	var old model.StorageTraffic
+	err := db.Where(fmt.Sprintf("%s = ? AND %s = ?", columnName("day"), columnName("storage")), t.Day, t.Storage).First(&old).Error
+	if err != nil {
+		return errors.WithStack(db.Create(t).Error)
+	}
+	old.Download += t.Download
+	old.Upload += t.Upload
+	return errors.WithStack(db.Save(&old).Error)
+}
+
+// ListStorageTraffic returns the rollups between from and to
+func ListStorageTraffic(from, to, storage string) (traffic []model.StorageTraffic, err error) {
+	query := readDB().Model(&model.StorageTraffic{})
+	if from != "" {
+		query = query.Where(fmt.Sprintf("%s >= ?", columnName("day")), from)
+	}
+	if to != "" {
+		query = query.Where(fmt.Sprintf("%s <= ?", columnName("day")), to)
+	}
+	if storage != "" {
+		query = query.Where(fmt.Sprintf("%s = ?", columnName("storage")), storage)
+	}
+	if err = query.Find(&traffic).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed list storage traffic")
+	}
+	return traffic, nil
+}
+
+// GetStorageTraffic aggregates the traffic between from and to by the column groupBy
+func GetStorageTraffic(from, to, groupBy, storage string) (items []model.StorageTrafficItem, err error) {
+	key := columnName(groupBy)
+	query := readDB().Model(&model.StorageTraffic{}).
+		Select(fmt.Sprintf("%s as %s, sum(%s) as %s, sum(%s) as %s", key, columnName("key"),
+			columnName("download"), columnName("download"), columnName("upload"), columnName("upload")))
+	if from != "" {
+		query = query.Where(fmt.Sprintf("%s >= ?", columnName("day")), from)
+	}
+	if to != "" {
+		query = query.Where(fmt.Sprintf("%s <= ?", columnName("day")), to)
+	}
+	if storage != "" {
+		query = query.Where(fmt.Sprintf("%s = ?", columnName("storage")), storage)
+	}
+	err = query.Group(key).Order(key).Scan(&items).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get storage traffic")
+	}
+	return items, nil
+}
+
+func DeleteStorageTrafficBefore(day string) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s < ?", columnName("day")), day).Delete(&model.StorageTraffic{}).Error)
+}
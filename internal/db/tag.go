@@ -0,0 +1,76 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// whereUnderPath matches the tags of path and the objects under it
+func whereUnderPath(path string) *gorm.DB {
+	if path == "/" {
+		return db.Where("1 = 1")
+	}
+	return db.Where(fmt.Sprintf("%s LIKE ?", columnName("path")), fmt.Sprintf("%s/%%", path)).
+		Or(fmt.Sprintf("%s = ?", columnName("path")), path)
+}
+
+func GetTagsByPaths(paths []string) (tags []model.Tag, err error) {
+	if err := db.Where(columnName("path")+" IN ?", paths).Order(columnName("name")).Find(&tags).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find tags by paths")
+	}
+	return tags, nil
+}
+
+func GetTagsByHash(hash string) (tags []model.Tag, err error) {
+	if err := db.Where(model.Tag{Hash: hash}).Order(columnName("name")).Find(&tags).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find tags by hash")
+	}
+	return tags, nil
+}
+
+// GetTagsUnder returns the tags of the names on path and the objects under it
+func GetTagsUnder(path string, names []string) (tags []model.Tag, err error) {
+	if err := db.Where(columnName("name")+" IN ?", names).Where(whereUnderPath(path)).
+		Order(columnName("path")).Find(&tags).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find tags under path")
+	}
+	return tags, nil
+}
+
+func GetTagsUnderPath(path string) (tags []model.Tag, err error) {
+	if err := db.Where(whereUnderPath(path)).Find(&tags).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find tags under path")
+	}
+	return tags, nil
+}
+
+func CreateTag(t *model.Tag) error {
+	return errors.WithStack(db.Create(t).Error)
+}
+
+func SaveTags(tags []model.Tag) error {
+	return errors.WithStack(db.Save(&tags).Error)
+}
+
+func DeleteTag(name, path string) error {
+	return errors.WithStack(db.Where(model.Tag{Name: name, Path: path}).Delete(&model.Tag{}).Error)
+}
+
+func DeleteTagsByIds(ids []uint) error {
+	return errors.WithStack(db.Delete(&model.Tag{}, ids).Error)
+}
+
+func DeleteTagsUnderPath(path string) error {
+	return errors.WithStack(db.Where(whereUnderPath(path)).Delete(&model.Tag{}).Error)
+}
+
+func RenameTag(name, newName string) error {
+	return errors.WithStack(db.Model(&model.Tag{}).Where(model.Tag{Name: name}).Update("name", newName).Error)
+}
+
+func DeleteTagByName(name string) error {
+	return errors.WithStack(db.Where(model.Tag{Name: name}).Delete(&model.Tag{}).Error)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateTaskArchives(archives []model.TaskArchive) error {
+	return errors.WithStack(db.CreateInBatches(archives, 500).Error)
+}
+
+// GetTaskArchives returns the archived tasks of the creator, or of all users if creatorId is 0, the latest first
+func GetTaskArchives(req model.TaskArchiveReq, creatorId uint) ([]model.TaskArchive, int64, error) {
+	archiveDB := readDB().Model(&model.TaskArchive{})
+	if req.Month != "" {
+		archiveDB = archiveDB.Where(fmt.Sprintf("%s = ?", columnName("month")), req.Month)
+	}
+	return findTaskRecords[model.TaskArchive](archiveDB, req.PageReq, creatorId, req.Type)
+}
+
+// findTaskRecords pages the task records of the query of the creator, or of all users if creatorId is 0,
+// and of the task type if it's set, the latest first
+func findTaskRecords[T any](query *gorm.DB, page model.PageReq, creatorId uint, typ string) (records []T, count int64, err error) {
+	if creatorId != 0 {
+		query = query.Where(fmt.Sprintf("%s = ?", columnName("creator_id")), creatorId)
+	}
+	if typ != "" {
+		query = query.Where(fmt.Sprintf("%s = ?", columnName("type")), typ)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get task records count")
+	}
+	if err := query.Order(columnName("id") + " desc").Offset((page.Page - 1) * page.PerPage).Limit(page.PerPage).Find(&records).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find task records")
+	}
+	return records, count, nil
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateTaskHistory(h *model.TaskHistory) error {
+	return errors.WithStack(db.Create(h).Error)
+}
+
+// GetTaskHistories returns the task history of the creator, or of all users if creatorId is 0, the latest first
+func GetTaskHistories(req model.TaskHistoryReq, creatorId uint) ([]model.TaskHistory, int64, error) {
+	historyDB := readDB().Model(&model.TaskHistory{})
+	if req.From != "" {
+		historyDB = historyDB.Where(fmt.Sprintf("%s >= ?", columnName("day")), req.From)
+	}
+	if req.To != "" {
+		historyDB = historyDB.Where(fmt.Sprintf("%s <= ?", columnName("day")), req.To)
+	}
+	return findTaskRecords[model.TaskHistory](historyDB, req.PageReq, creatorId, req.Type)
+}
+
+// DeleteTaskHistoriesBefore deletes the task history of the tasks which ended before the day
+func DeleteTaskHistoriesBefore(day string) (int64, error) {
+	res := db.Where(fmt.Sprintf("%s < ?", columnName("day")), day).Delete(&model.TaskHistory{})
+	return res.RowsAffected, errors.WithStack(res.Error)
+}
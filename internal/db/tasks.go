@@ -1,9 +1,16 @@
 package db
 
 import (
+	"encoding/json"
+	"fmt"
+	"time"
+
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 func GetTaskDataByType(type_s string) (*model.TaskItem, error) {
@@ -14,15 +21,22 @@ func GetTaskDataByType(type_s string) (*model.TaskItem, error) {
 	return &task, nil
 }
 
+// UpdateTaskData replaces the persisted data of the tasks in a transaction, creating the item if it doesn't exist
 func UpdateTaskData(t *model.TaskItem) error {
-	return errors.WithStack(db.Model(&model.TaskItem{}).Where("key = ?", t.Key).Update("persist_data", t.PersistData).Error)
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.TaskItem{}).Where("key = ?", t.Key).Update("persist_data", t.PersistData)
+		if res.Error != nil || res.RowsAffected > 0 {
+			return res.Error
+		}
+		return tx.Create(t).Error
+	}))
 }
 
 func CreateTaskData(t *model.TaskItem) error {
 	return errors.WithStack(db.Create(t).Error)
 }
 
-func GetTaskDataFunc(type_s string, enabled bool) func() ([]byte, error) {
+func GetTaskDataFunc[T any](type_s string, enabled bool) func() ([]byte, error) {
 	if !enabled {
 		return nil
 	}
@@ -32,7 +46,46 @@ func GetTaskDataFunc(type_s string, enabled bool) func() ([]byte, error) {
 	}
 	return func() ([]byte, error) {
 		<-conf.StoragesLoadSignal()
-		return []byte(task.PersistData), nil
+		return checkTaskData[T](type_s, []byte(task.PersistData)), nil
+	}
+}
+
+// checkTaskData drops the tasks that can't be decoded from the persisted data, so that they don't fail
+// the recovery of all the others, they are quarantined in a separate item for inspection
+func checkTaskData[T any](type_s string, data []byte) []byte {
+	var raws []json.RawMessage
+	if err := utils.Json.Unmarshal(data, &raws); err != nil {
+		quarantineTaskData(type_s, data, err)
+		return []byte("[]")
+	}
+	valid := make([]json.RawMessage, 0, len(raws))
+	var corrupt []json.RawMessage
+	var firstErr error
+	for _, raw := range raws {
+		var t T
+		if err := utils.Json.Unmarshal(raw, &t); err != nil {
+			corrupt = append(corrupt, raw)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		valid = append(valid, raw)
+	}
+	if len(corrupt) == 0 {
+		return data
+	}
+	b, _ := utils.Json.Marshal(corrupt)
+	quarantineTaskData(type_s, b, firstErr)
+	b, _ = utils.Json.Marshal(valid)
+	return b
+}
+
+func quarantineTaskData(type_s string, data []byte, cause error) {
+	key := fmt.Sprintf("%s_corrupt_%d", type_s, time.Now().Unix())
+	log.Errorf("failed recover %s tasks: %+v, the corrupt data is kept as task item %s", type_s, cause, key)
+	if err := CreateTaskData(&model.TaskItem{Key: key, PersistData: string(data)}); err != nil {
+		log.Errorf("failed quarantine corrupt %s tasks: %+v", type_s, err)
 	}
 }
 
@@ -45,6 +98,10 @@ func UpdateTaskDataFunc(type_s string, enabled bool) func([]byte) error {
 		if s == "null" || s == "" {
 			s = "[]"
 		}
+		if !utils.Json.Valid([]byte(s)) {
+			// keep the last good snapshot
+			return errors.Errorf("refuse to persist invalid %s tasks", type_s)
+		}
 		return UpdateTaskData(&model.TaskItem{Key: type_s, PersistData: s})
 	}
 }
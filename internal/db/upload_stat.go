@@ -0,0 +1,48 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+// AddUploadStat adds the count and bytes of s to the existing rollup, or creates it
+func AddUploadStat(s *model.UploadStat) error {
+	var old model.UploadStat
+	err := db.Where(fmt.Sprintf("%s = ? AND %s = ? AND %s = ?", columnName("day"), columnName("user_id"), columnName("protocol")),
+		s.Day, s.UserId, s.Protocol).First(&old).Error
+	if err != nil {
+		return errors.WithStack(db.Create(s).Error)
+	}
+	old.Count += s.Count
+	old.Bytes += s.Bytes
+	return errors.WithStack(db.Save(&old).Error)
+}
+
+// GetUploadStats aggregates the upload stats between from and to by the column groupBy,
+// limited to the user if userId isn't 0
+func GetUploadStats(from, to, groupBy, orderBy string, limit int, userId uint) (items []model.UploadStatsItem, err error) {
+	key := columnName(groupBy)
+	query := readDB().Model(&model.UploadStat{}).
+		Select(fmt.Sprintf("%s as %s, sum(%s) as %s, sum(%s) as %s", key, columnName("key"),
+			columnName("count"), columnName("count"), columnName("bytes"), columnName("bytes")))
+	if from != "" {
+		query = query.Where(fmt.Sprintf("%s >= ?", columnName("day")), from)
+	}
+	if to != "" {
+		query = query.Where(fmt.Sprintf("%s <= ?", columnName("day")), to)
+	}
+	if userId != 0 {
+		query = query.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId)
+	}
+	err = query.Group(key).Order(columnName(orderBy) + " desc").Limit(limit).Scan(&items).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get upload stats")
+	}
+	return items, nil
+}
+
+func DeleteUploadStatsBefore(day string) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s < ?", columnName("day")), day).Delete(&model.UploadStat{}).Error)
+}
@@ -26,6 +26,8 @@ type Config struct {
 	OnlyIndices bool `json:"only_indices"`
 	// prefer proxy download even if direct link is available
 	PreferProxy bool `json:"prefer_proxy"`
+	// if the driver reads or writes the files of the server, only the admin can add its storages
+	LocalFS bool `json:"local_fs"`
 }
 type LinkCacheMode int8
 
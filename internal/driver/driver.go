@@ -2,8 +2,11 @@ package driver
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/delta"
 )
 
 type Driver interface {
@@ -103,6 +106,48 @@ type Put interface {
 	Put(ctx context.Context, dstDir model.Obj, file model.FileStreamer, up UpdateProgress) error
 }
 
+type PutRapid interface {
+	// PutRapid creates a file in dstDir from the hashes of the stream only, without reading its content,
+	// and fails if the storage doesn't have the content already
+	// Used to copy files between the storages of the same provider without any data flowing through the server
+	PutRapid(ctx context.Context, dstDir model.Obj, file model.FileStreamer) (model.Obj, error)
+}
+
+type PutTar interface {
+	// PutTar extracts the regular files of a tar stream into dstDir, overwriting the existing ones
+	// Used to transfer many small files at once, the names of the entries are plain file names
+	PutTar(ctx context.Context, dstDir model.Obj, tarStream io.Reader) error
+}
+
+type PutDelta interface {
+	// PutDelta replaces the file obj with the one made by the ops, which copy the parts of obj or read the new data in order.
+	// The new file is written aside and renamed over obj once it's complete
+	// Used to update a large file by uploading its changed parts only
+	PutDelta(ctx context.Context, obj model.Obj, ops []delta.Op, data io.Reader, modified time.Time) error
+}
+
+type AppendPut interface {
+	// AppendPut appends the data of size to the end of the file obj
+	// Used to write a growing file incrementally, e.g. by a log shipper
+	AppendPut(ctx context.Context, obj model.Obj, data io.Reader, size int64) error
+}
+
+type WriteRange interface {
+	// WriteRange writes the data of size at offset of the file obj in place, the file is extended if the range is beyond its end.
+	// A failed write may leave a part of the data written
+	// Used to write the parts of a file in any order, e.g. by a download manager
+	WriteRange(ctx context.Context, obj model.Obj, offset int64, data io.Reader, size int64) error
+}
+
+type LocalTransfer interface {
+	// LocalPath returns the path of obj on the host filesystem
+	LocalPath(obj model.Obj) string
+	// PutLocal moves (by renaming) or copies (by reflink, falling back to an in-kernel copy) the host file at srcPath into dstDir,
+	// returns errs.NotImplement if it can't be moved without copying, e.g. across filesystems
+	// Used to transfer files between storages on the host filesystem without streaming them through userspace
+	PutLocal(ctx context.Context, dstDir model.Obj, srcPath string, move bool) (model.Obj, error)
+}
+
 type PutURL interface {
 	// PutURL directly put a URL into the storage
 	// Applicable to index-based drivers like URL-Tree or drivers that support uploading files as URLs
@@ -201,6 +246,20 @@ type WithDetails interface {
 	GetDetails(ctx context.Context) (*model.StorageDetails, error)
 }
 
+type Snapshot interface {
+	// ListAt lists the directory as it was at the time, including the deleted files,
+	// the path haven't been joined with root path
+	ListAt(ctx context.Context, dirPath string, at time.Time) ([]model.Obj, error)
+	// LinkAt get url/filepath/reader of the revision of a file returned by ListAt
+	LinkAt(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error)
+}
+
+type Watcher interface {
+	// Watch reports the changes of the files made outside OpenList until ctx is done,
+	// the paths of the events haven't been joined with mount path
+	Watch(ctx context.Context, notify func(event model.FsEvent)) error
+}
+
 type Reference interface {
 	InitReference(storage Driver) error
 }
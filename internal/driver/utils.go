@@ -36,7 +36,11 @@ type RateLimitWriter = stream.RateLimitWriter
 
 type RateLimitFile = stream.RateLimitFile
 
+// NewLimitedUploadStream limits r by the server upload limit and the upload limit of the task of ctx
 func NewLimitedUploadStream(ctx context.Context, r io.Reader) *RateLimitReader {
+	if l := stream.TaskUploadLimiter(ctx); l != nil {
+		r = &RateLimitReader{Reader: r, Limiter: l, Ctx: ctx}
+	}
 	return &RateLimitReader{
 		Reader:  r,
 		Limiter: stream.ServerUploadLimit,
@@ -45,6 +49,9 @@ func NewLimitedUploadStream(ctx context.Context, r io.Reader) *RateLimitReader {
 }
 
 func NewLimitedUploadFile(ctx context.Context, f model.File) *RateLimitFile {
+	if l := stream.TaskUploadLimiter(ctx); l != nil {
+		f = &RateLimitFile{File: f, Limiter: l, Ctx: ctx}
+	}
 	return &RateLimitFile{
 		File:    f,
 		Limiter: stream.ServerUploadLimit,
@@ -53,6 +60,11 @@ func NewLimitedUploadFile(ctx context.Context, f model.File) *RateLimitFile {
 }
 
 func ServerUploadLimitWaitN(ctx context.Context, n int) error {
+	if l := stream.TaskUploadLimiter(ctx); l != nil {
+		if err := l.WaitN(ctx, n); err != nil {
+			return err
+		}
+	}
 	return stream.ServerUploadLimit.WaitN(ctx, n)
 }
 
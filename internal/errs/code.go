@@ -0,0 +1,116 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	stdnet "net"
+	"strings"
+)
+
+// the machine-readable codes of the errors in the api responses and the task infos,
+// so that the clients can branch on the failures and localize the messages
+const (
+	CodeInvalidRequest      = "INVALID_REQUEST"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodePermissionDenied    = "PERMISSION_DENIED"
+	CodeNotFound            = "NOT_FOUND"
+	CodeAlreadyExists       = "ALREADY_EXISTS"
+	CodeWrongPassword       = "WRONG_PASSWORD"
+	CodeNotSupported        = "NOT_SUPPORTED"
+	CodeStorageNotReady     = "STORAGE_NOT_READY"
+	CodeStorageUnreachable  = "STORAGE_UNREACHABLE"
+	CodeProviderRateLimited = "PROVIDER_RATE_LIMITED"
+	CodeRateLimited         = "RATE_LIMITED"
+	CodeQuotaExceeded       = "QUOTA_EXCEEDED"
+	CodeNoTempSpace         = "NO_TEMP_SPACE"
+	CodeHashMismatch        = "HASH_MISMATCH"
+	CodeStreamIncomplete    = "STREAM_INCOMPLETE"
+	CodeTimeout             = "TIMEOUT"
+	CodeCanceled            = "CANCELED"
+	CodeInternal            = "INTERNAL"
+)
+
+// HTTPStatusError is implemented by the errors of the failed upstream http requests
+type HTTPStatusError interface {
+	HTTPStatus() int
+}
+
+var codes = []struct {
+	code string
+	errs []error
+}{
+	{CodeNotFound, []error{ObjectNotFound, StorageNotFound, MetaNotFound, SharingNotFound}},
+	{CodeAlreadyExists, []error{ObjectAlreadyExists}},
+	{CodePermissionDenied, []error{PermissionDenied, InvalidSharing}},
+	{CodeWrongPassword, []error{WrongPassword, WrongShareCode, WrongArchivePassword}},
+	{CodeUnauthorized, []error{EmptyToken}},
+	{CodeNotSupported, []error{NotSupport, NotImplement, UploadNotSupported, DriverExtractNotSupported, UnknownArchiveFormat}},
+	{CodeStorageNotReady, []error{StorageNotInit, StorageInUse}},
+	{CodeQuotaExceeded, []error{QuotaExceeded}},
+	{CodeNoTempSpace, []error{NoTempSpace}},
+	{CodeHashMismatch, []error{VerifyFailed}},
+	{CodeStreamIncomplete, []error{StreamIncomplete}},
+	{CodeInvalidRequest, []error{RelativePath, EmptyUsername, EmptyPassword, NotFolder, NotFile}},
+	{CodeTimeout, []error{context.DeadlineExceeded}},
+	{CodeCanceled, []error{context.Canceled}},
+}
+
+// Code returns the code of err, or the code of the http status if err has no known cause
+func Code(err error, status int) string {
+	if err != nil {
+		for _, c := range codes {
+			for _, e := range c.errs {
+				if errors.Is(err, e) {
+					return c.code
+				}
+			}
+		}
+		var statusErr HTTPStatusError
+		if errors.As(err, &statusErr) {
+			return upstreamCode(statusErr.HTTPStatus())
+		}
+		var netErr stdnet.Error
+		if errors.As(err, &netErr) {
+			if netErr.Timeout() {
+				return CodeTimeout
+			}
+			return CodeStorageUnreachable
+		}
+		msg := strings.ToLower(err.Error())
+		if strings.Contains(msg, "too many requests") || strings.Contains(msg, "rate limit") {
+			return CodeProviderRateLimited
+		}
+	}
+	return StatusCode(status)
+}
+
+func upstreamCode(status int) string {
+	switch {
+	case status == 429:
+		return CodeProviderRateLimited
+	case status == 401 || status == 403:
+		return CodePermissionDenied
+	case status == 404:
+		return CodeNotFound
+	case status >= 500:
+		return CodeStorageUnreachable
+	}
+	return CodeInternal
+}
+
+// StatusCode returns the code of the status of a response
+func StatusCode(status int) string {
+	switch status {
+	case 400:
+		return CodeInvalidRequest
+	case 401:
+		return CodeUnauthorized
+	case 403:
+		return CodePermissionDenied
+	case 404:
+		return CodeNotFound
+	case 429:
+		return CodeRateLimited
+	}
+	return CodeInternal
+}
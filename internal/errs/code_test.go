@@ -0,0 +1,37 @@
+package errs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+type statusErr int
+
+func (e statusErr) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusErr) HTTPStatus() int { return int(e) }
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		want   string
+	}{
+		{pkgerr.WithMessage(ObjectNotFound, "failed get"), 500, CodeNotFound},
+		{NewErr(QuotaExceeded, "upload to /home"), 500, CodeQuotaExceeded},
+		{pkgerr.WithStack(VerifyFailed), 500, CodeHashMismatch},
+		{pkgerr.Wrap(context.DeadlineExceeded, "failed link"), 500, CodeTimeout},
+		{fmt.Errorf("request failure, status: %w", statusErr(429)), 500, CodeProviderRateLimited},
+		{fmt.Errorf("request failure, status: %w", statusErr(502)), 500, CodeStorageUnreachable},
+		{pkgerr.New("something else"), 400, CodeInvalidRequest},
+		{nil, 403, CodePermissionDenied},
+		{pkgerr.New("something else"), 500, CodeInternal},
+	}
+	for _, tt := range tests {
+		if got := Code(tt.err, tt.status); got != tt.want {
+			t.Errorf("Code(%v, %d) = %s, want %s", tt.err, tt.status, got, tt.want)
+		}
+	}
+}
@@ -16,8 +16,12 @@ var (
 	MetaNotFound       = errors.New("meta not found")
 	StorageNotFound    = errors.New("storage not found")
 	StorageNotInit     = errors.New("storage not init")
+	StorageInUse       = errors.New("storage is used by running tasks")
 	StreamIncomplete   = errors.New("upload/download stream incomplete, possible network issue")
 	StreamPeekFail     = errors.New("StreamPeekFail")
+	VerifyFailed       = errors.New("transferred file does not match the source")
+	NoTempSpace        = errors.New("no temp dir has enough free space")
+	QuotaExceeded      = errors.New("storage quota exceeded")
 
 	UnknownArchiveFormat      = errors.New("unknown archive format")
 	WrongArchivePassword      = errors.New("wrong archive password")
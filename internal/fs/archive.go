@@ -16,6 +16,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
@@ -38,6 +39,9 @@ func (t *ArchiveDownloadTask) GetName() string {
 }
 
 func (t *ArchiveDownloadTask) Run() error {
+	if err := t.WaitStorages(); err != nil {
+		return err
+	}
 	if t.SrcStorage == nil {
 		if srcStorage, _, err := op.GetStorageAndActualPath(t.SrcStorageMp); err == nil {
 			t.SrcStorage = srcStorage
@@ -57,9 +61,10 @@ func (t *ArchiveDownloadTask) Run() error {
 	if err != nil {
 		return err
 	}
-	uploadTask.groupID = stdpath.Join(uploadTask.DstStorageMp, uploadTask.DstActualPath)
+	uploadTask.groupID = task_group.TransferGroupID(stdpath.Join(uploadTask.DstStorageMp, uploadTask.DstActualPath))
 	task_group.TransferCoordinator.AddTask(uploadTask.groupID, nil)
 	ArchiveContentUploadTaskManager.Add(uploadTask)
+	t.Logf("decompressed, added the upload task %s", uploadTask.GetID())
 	return nil
 }
 
@@ -78,6 +83,7 @@ func (t *ArchiveDownloadTask) RunWithoutPushUploadTask() (*ArchiveContentUploadT
 		}
 	}()
 	var decompressUp model.UpdateProgress
+	up := t.PausableProgress(t.SetProgress, t.StoragesPaused)
 	if t.CacheFull {
 		total := int64(0)
 		for _, s := range ss {
@@ -85,6 +91,7 @@ func (t *ArchiveDownloadTask) RunWithoutPushUploadTask() (*ArchiveContentUploadT
 		}
 		t.SetTotalBytes(total)
 		t.Status = "getting src object"
+		t.Logf("caching %d parts of the archive", len(ss))
 		part := 100 / float64(len(ss)+1)
 		for i, s := range ss {
 			if s.GetFile() != nil {
@@ -94,18 +101,19 @@ func (t *ArchiveDownloadTask) RunWithoutPushUploadTask() (*ArchiveContentUploadT
 			if err != nil {
 				return nil, err
 			} else {
-				t.SetProgress(float64(i+1) * part)
+				up(float64(i+1) * part)
 			}
 		}
-		decompressUp = model.UpdateProgressWithRange(t.SetProgress, 100-part, 100)
+		decompressUp = model.UpdateProgressWithRange(up, 100-part, 100)
 	} else {
-		decompressUp = t.SetProgress
+		decompressUp = up
 	}
 	t.Status = "walking and decompressing"
 	dir, err := os.MkdirTemp(conf.Conf.TempDir, "dir-*")
 	if err != nil {
 		return nil, err
 	}
+	t.Logf("decompressing into %s", dir)
 	err = tool.Decompress(ss, dir, t.ArchiveInnerArgs, decompressUp)
 	if err != nil {
 		return nil, err
@@ -113,8 +121,9 @@ func (t *ArchiveDownloadTask) RunWithoutPushUploadTask() (*ArchiveContentUploadT
 	baseName := strings.TrimSuffix(srcObj.GetName(), stdpath.Ext(srcObj.GetName()))
 	uploadTask := &ArchiveContentUploadTask{
 		TaskExtension: task.TaskExtension{
-			Creator: t.Creator,
-			ApiUrl:  t.ApiUrl,
+			Creator:   t.Creator,
+			ApiUrl:    t.ApiUrl,
+			RequestID: t.RequestID,
 		},
 		ObjName:       baseName,
 		InPlace:       !t.PutIntoNewDir,
@@ -122,7 +131,7 @@ func (t *ArchiveDownloadTask) RunWithoutPushUploadTask() (*ArchiveContentUploadT
 		DstActualPath: t.DstActualPath,
 		dstStorage:    t.DstStorage,
 		DstStorageMp:  t.DstStorageMp,
-		overwrite:     t.Overwrite,
+		Overwrite:     t.Overwrite,
 	}
 	return uploadTask, nil
 }
@@ -140,7 +149,7 @@ type ArchiveContentUploadTask struct {
 	DstStorageMp  string
 	finalized     bool
 	groupID       string
-	overwrite     bool
+	Overwrite     bool `json:"overwrite"`
 }
 
 func (t *ArchiveContentUploadTask) GetName() string {
@@ -151,7 +160,18 @@ func (t *ArchiveContentUploadTask) GetStatus() string {
 	return t.status
 }
 
+func (t *ArchiveContentUploadTask) UsesStorage(mountPath string) bool {
+	return t.DstStorageMp == mountPath
+}
+
 func (t *ArchiveContentUploadTask) Run() error {
+	if waited, err := op.WaitStorage(t.Ctx(), t.DstStorageMp); err != nil {
+		return err
+	} else if waited || t.dstStorage == nil {
+		if t.dstStorage, err = op.GetStorageByMountPath(t.DstStorageMp); err != nil {
+			return err
+		}
+	}
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
@@ -175,7 +195,7 @@ func (t *ArchiveContentUploadTask) SetRetry(retry int, maxRetry int) {
 	if retry == 0 &&
 		(len(t.groupID) == 0 || // 重启恢复
 			(t.GetErr() == nil && t.GetState() != tache.StatePending)) { // 手动重试
-		t.groupID = stdpath.Join(t.DstStorageMp, t.DstActualPath)
+		t.groupID = task_group.TransferGroupID(stdpath.Join(t.DstStorageMp, t.DstActualPath))
 		task_group.TransferCoordinator.AddTask(t.groupID, nil)
 	}
 }
@@ -216,8 +236,9 @@ func (t *ArchiveContentUploadTask) RunWithNextTaskCallback(f func(nextTask *Arch
 			}
 			err = f(&ArchiveContentUploadTask{
 				TaskExtension: task.TaskExtension{
-					Creator: t.Creator,
-					ApiUrl:  t.ApiUrl,
+					Creator:   t.Creator,
+					ApiUrl:    t.ApiUrl,
+					RequestID: t.RequestID,
 				},
 				ObjName:       entry.Name(),
 				InPlace:       false,
@@ -226,7 +247,7 @@ func (t *ArchiveContentUploadTask) RunWithNextTaskCallback(f func(nextTask *Arch
 				dstStorage:    t.dstStorage,
 				DstStorageMp:  t.DstStorageMp,
 				groupID:       t.groupID,
-				overwrite:     t.overwrite,
+				Overwrite:     t.Overwrite,
 			})
 			if err != nil {
 				es = stderrors.Join(es, err)
@@ -236,9 +257,10 @@ func (t *ArchiveContentUploadTask) RunWithNextTaskCallback(f func(nextTask *Arch
 			return es
 		}
 	} else {
-		if !t.overwrite {
+		if !t.Overwrite {
 			dstPath := stdpath.Join(t.DstActualPath, t.ObjName)
 			if res, _ := op.Get(t.Ctx(), t.dstStorage, dstPath); res != nil {
+				t.Logf("%s exists", dstPath)
 				return errs.ObjectAlreadyExists
 			}
 		}
@@ -259,7 +281,11 @@ func (t *ArchiveContentUploadTask) RunWithNextTaskCallback(f func(nextTask *Arch
 		}
 		fs.Closers.Add(file)
 		t.status = "uploading"
-		err = op.Put(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), t.dstStorage, t.DstActualPath, fs, t.SetProgress)
+		t.Logf("uploading %s to [%s](%s)", t.ObjName, t.DstStorageMp, t.DstActualPath)
+		err = op.Put(context.WithValue(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), conf.ServerPutKey, struct{}{}), t.dstStorage, t.DstActualPath, fs,
+			t.PausableProgress(t.SetProgress, func() <-chan struct{} {
+				return op.StoragePaused(t.DstStorageMp)
+			}))
 		if err != nil {
 			return err
 		}
@@ -408,7 +434,7 @@ func archiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args
 			return e
 		}
 		uploadTask.Base.SetCtx(ctx)
-		uploadTask.groupID = stdpath.Join(uploadTask.DstStorageMp, uploadTask.DstActualPath)
+		uploadTask.groupID = task_group.TransferGroupID(stdpath.Join(uploadTask.DstStorageMp, uploadTask.DstActualPath))
 		task_group.TransferCoordinator.AddTask(uploadTask.groupID, nil)
 		err = uploadTask.RunWithNextTaskCallback(callback)
 		task_group.TransferCoordinator.Done(context.WithoutCancel(ctx), uploadTask.groupID, hasSuccess)
@@ -416,6 +442,7 @@ func archiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args
 	} else {
 		tsk.Creator, _ = ctx.Value(conf.UserKey).(*model.User)
 		tsk.ApiUrl = common.GetApiUrl(ctx)
+		tsk.RequestID = net.RequestID(ctx)
 		ArchiveDownloadTaskManager.Add(tsk)
 		return tsk, nil
 	}
@@ -0,0 +1,156 @@
+package fs
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	stdpath "path"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task_group"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	smallFilesBatchCount = 1000
+	smallFilesBatchSize  = 256 * utils.MB
+)
+
+// progressTracker combines the progress of the files transferred by a dir task
+type progressTracker struct {
+	mu    sync.Mutex
+	total int64
+	done  float64
+	up    model.UpdateProgress
+}
+
+func newProgressTracker(up model.UpdateProgress, objs ...[]model.Obj) *progressTracker {
+	p := &progressTracker{up: up}
+	for _, list := range objs {
+		for _, obj := range list {
+			p.total += obj.GetSize()
+		}
+	}
+	return p
+}
+
+// add reports n more bytes transferred
+func (p *progressTracker) add(n float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	if p.total > 0 {
+		p.up(p.done / float64(p.total) * 100)
+	}
+}
+
+type progressWriter struct {
+	io.Writer
+	progress *progressTracker
+}
+
+func (w progressWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.progress.add(float64(n))
+	return n, err
+}
+
+// putSmallFiles packs the small files of the src dir into tar streams extracted by the dst storage,
+// saving the overhead of uploading each of them
+func (t *FileTransferTask) putSmallFiles(files []model.Obj, dstDirActualPath string, progress *progressTracker) error {
+	ctx := context.WithValue(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), conf.ServerPutKey, struct{}{})
+	for start := 0; start < len(files); {
+		end, size := start, int64(0)
+		for end < len(files) && end-start < smallFilesBatchCount &&
+			(end == start || size+files[end].GetSize() <= smallFilesBatchSize) {
+			size += files[end].GetSize()
+			end++
+		}
+		batch := files[start:end]
+		start = end
+
+		overwritten := make(map[string]bool)
+		if t.Transactional {
+			for _, obj := range batch {
+				if _, err := op.Get(ctx, t.DstStorage, stdpath.Join(dstDirActualPath, obj.GetName())); err == nil {
+					overwritten[obj.GetName()] = true
+				}
+			}
+		}
+		pr, pw := io.Pipe()
+		writeErr := make(chan error, 1)
+		go func() {
+			err := t.writeTar(pw, batch, progress)
+			_ = pw.CloseWithError(err)
+			writeErr <- err
+		}()
+		err := op.PutTar(ctx, t.DstStorage, dstDirActualPath, pr, size)
+		_ = pr.CloseWithError(err)
+		if e := <-writeErr; e != nil && err == nil {
+			err = e
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "failed put %d small files into [%s]", len(batch), dstDirActualPath)
+		}
+
+		for _, obj := range batch {
+			dstObjActualPath := stdpath.Join(dstDirActualPath, obj.GetName())
+			if t.Verify {
+				if err = t.verify(ctx, obj, dstObjActualPath); err != nil {
+					return err
+				}
+			}
+			if t.Transactional {
+				if overwritten[obj.GetName()] {
+					task_group.TransferCoordinator.AppendPayload(t.groupID, task_group.DstPathOverwritten(dstObjActualPath))
+				} else {
+					task_group.TransferCoordinator.AppendPayload(t.groupID, task_group.DstPathCreated(dstObjActualPath))
+				}
+			}
+		}
+		task_group.TransferCoordinator.MarkSucceeded(t.groupID)
+	}
+	return nil
+}
+
+func (t *FileTransferTask) writeTar(w io.Writer, files []model.Obj, progress *progressTracker) error {
+	tw := tar.NewWriter(w)
+	for _, obj := range files {
+		if err := t.Ctx().Err(); err != nil {
+			return err
+		}
+		srcActualPath := stdpath.Join(t.SrcActualPath, obj.GetName())
+		link, srcObj, err := op.Link(t.Ctx(), t.SrcStorage, srcActualPath, model.LinkArgs{})
+		if err != nil {
+			return errors.WithMessagef(err, "failed get [%s] link", srcActualPath)
+		}
+		ss, err := stream.NewSeekableStream(&stream.FileStream{
+			Obj: srcObj,
+			Ctx: t.Ctx(),
+		}, link)
+		if err != nil {
+			_ = link.Close()
+			return errors.WithMessagef(err, "failed get [%s] stream", srcActualPath)
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     srcObj.GetName(),
+			Size:     ss.GetSize(),
+			Mode:     0o644,
+			ModTime:  srcObj.ModTime(),
+		})
+		if err == nil {
+			_, err = utils.CopyWithBuffer(progressWriter{Writer: tw, progress: progress}, ss)
+		}
+		_ = ss.Close()
+		if err != nil {
+			return errors.WithMessagef(err, "failed pack [%s]", srcActualPath)
+		}
+	}
+	return tw.Close()
+}
@@ -0,0 +1,42 @@
+package fs
+
+import (
+	"context"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/pkg/errors"
+)
+
+// CopyEstimate is what would be copied with a path
+type CopyEstimate struct {
+	*RemoveStat
+	// CrossStorage is set if the files are read from the source storage, otherwise the storage copies them itself
+	CrossStorage bool `json:"cross_storage"`
+	// Cost is estimated from the egress cost of the source storage
+	Cost float64 `json:"cost"`
+}
+
+// EstimateCopy counts the files and the bytes copying srcPath to dstDir would read, with their cost.
+// The files existing in dstDir are counted too, so it's the upper bound of a merge
+func EstimateCopy(ctx context.Context, srcPath, dstDir string) (*CopyEstimate, error) {
+	srcStorage, _, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, _, err := op.GetStorageAndActualPath(dstDir)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	stat, err := StatRemove(ctx, srcPath, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &CopyEstimate{
+		RemoveStat:   stat,
+		CrossStorage: srcStorage.GetStorage() != dstStorage.GetStorage(),
+	}
+	if estimate.CrossStorage {
+		estimate.Cost = op.EgressCost(srcStorage.GetStorage().MountPath, stat.Size)
+	}
+	return estimate, nil
+}
@@ -4,12 +4,18 @@ import (
 	"context"
 	"fmt"
 	stdpath "path"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"github.com/OpenListTeam/OpenList/v4/internal/task_group"
@@ -17,6 +23,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/OpenListTeam/tache"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 type taskType uint8
@@ -43,14 +50,38 @@ const (
 type FileTransferTask struct {
 	TaskData
 	TaskType taskType
+	// Transactional moves keep the src files until every file of the group is transferred,
+	// and remove the transferred files if any of them fails
+	Transactional bool
+	// Verify compares the hashes, or the sizes, of each transferred file with its source
+	Verify   bool
 	groupID  string
+	rollback atomic.Pointer[task_group.RollbackReport]
 }
 
 func (t *FileTransferTask) GetName() string {
 	return fmt.Sprintf("%s [%s](%s) to [%s](%s)", t.TaskType, t.SrcStorageMp, t.SrcActualPath, t.DstStorageMp, t.DstActualPath)
 }
 
+// GetRollbackReport returns the report of the rollback of the group of the transactional move, nil if it's not rolled back
+func (t *FileTransferTask) GetRollbackReport() *task_group.RollbackReport {
+	return t.rollback.Load()
+}
+
+// receiveRollback has the report of the rollback of the group stored into the transactional task
+func (t *FileTransferTask) receiveRollback() {
+	if t.Transactional {
+		task_group.TransferCoordinator.AppendPayload(t.groupID, task_group.OnRollback(func(report *task_group.RollbackReport) {
+			t.rollback.Store(report)
+		}))
+	}
+}
+
 func (t *FileTransferTask) Run() error {
+	t.SetSpeedLimitType(t.TaskType.String())
+	if err := t.WaitStorages(); err != nil {
+		return err
+	}
 	if t.SrcStorage == nil {
 		if srcStorage, _, err := op.GetStorageAndActualPath(t.SrcStorageMp); err == nil {
 			t.SrcStorage = srcStorage
@@ -64,11 +95,24 @@ func (t *FileTransferTask) Run() error {
 		}
 	}
 
+	slots := MoveTaskSlots
+	if t.TaskType == copy || t.TaskType == merge {
+		slots = CopyTaskSlots
+	}
+	if err := t.WaitResume(); err != nil {
+		return err
+	}
+	release, err := t.WaitSlot(slots)
+	if err != nil {
+		return err
+	}
+	defer release()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
 	return t.RunWithNextTaskCallback(func(nextTask *FileTransferTask) error {
 		task_group.TransferCoordinator.AddTask(t.groupID, nil)
+		nextTask.receiveRollback()
 		if t.TaskType == copy || t.TaskType == merge {
 			CopyTaskManager.Add(nextTask)
 		} else {
@@ -91,12 +135,16 @@ func (t *FileTransferTask) SetRetry(retry int, maxRetry int) {
 	if retry == 0 &&
 		(len(t.groupID) == 0 || // 重启恢复
 			(t.GetErr() == nil && t.GetState() != tache.StatePending)) { // 手动重试
-		t.groupID = stdpath.Join(t.DstStorageMp, t.DstActualPath)
+		t.groupID = task_group.TransferGroupID(stdpath.Join(t.DstStorageMp, t.DstActualPath))
 		var payload any
 		if t.TaskType == move {
 			payload = task_group.SrcPathToRemove(stdpath.Join(t.SrcStorageMp, t.SrcActualPath))
 		}
 		task_group.TransferCoordinator.AddTask(t.groupID, payload)
+		if t.Transactional {
+			task_group.TransferCoordinator.AppendPayload(t.groupID, task_group.Transactional{})
+		}
+		t.receiveRollback()
 	}
 }
 
@@ -127,6 +175,19 @@ func transfer(ctx context.Context, taskType taskType, srcObjPath, dstDirPath str
 		}
 	}
 
+	// storages on the same host filesystem, try to rename instead of copying the data.
+	// An existing dst is merged into by the transfer task instead, as renaming onto it fails or replaces it
+	if taskType == move && ctx.Value(conf.TransactionalKey) == nil && isLocalTransfer(srcStorage, dstStorage) &&
+		!dstExists(ctx, dstStorage, stdpath.Join(dstDirActualPath, stdpath.Base(srcObjActualPath))) {
+		if utils.IsBool(skipHook...) {
+			ctx = context.WithValue(ctx, conf.SkipHookKey, struct{}{})
+		}
+		err = op.PutLocal(ctx, srcStorage, srcObjActualPath, dstStorage, dstDirActualPath, true)
+		if !errors.Is(err, errs.NotImplement) && !errors.Is(err, errs.NotSupport) {
+			return nil, err
+		}
+	}
+
 	// not in the same storage
 	t := &FileTransferTask{
 		TaskData: TaskData{
@@ -137,11 +198,17 @@ func transfer(ctx context.Context, taskType taskType, srcObjPath, dstDirPath str
 			SrcStorageMp:  srcStorage.GetStorage().MountPath,
 			DstStorageMp:  dstStorage.GetStorage().MountPath,
 		},
-		TaskType: taskType,
+		TaskType:      taskType,
+		Transactional: taskType == move && ctx.Value(conf.TransactionalKey) != nil,
+		Verify:        ctx.Value(conf.VerifyKey) != nil,
 	}
 
-	t.groupID = stdpath.Join(t.DstStorageMp, t.DstActualPath)
+	t.groupID = task_group.TransferGroupID(stdpath.Join(t.DstStorageMp, t.DstActualPath))
 	task_group.TransferCoordinator.AddTask(t.groupID, nil)
+	if t.Transactional {
+		task_group.TransferCoordinator.AppendPayload(t.groupID, task_group.Transactional{})
+	}
+	t.receiveRollback()
 	if ctx.Value(conf.NoTaskKey) != nil {
 		var callback func(nextTask *FileTransferTask) error
 		hasSuccess := false
@@ -157,6 +224,8 @@ func transfer(ctx context.Context, taskType taskType, srcObjPath, dstDirPath str
 		err = t.RunWithNextTaskCallback(callback)
 		if err == nil {
 			hasSuccess = true
+		} else {
+			task_group.TransferCoordinator.MarkFailed(t.groupID)
 		}
 		if taskType == move {
 			task_group.TransferCoordinator.AppendPayload(t.groupID, task_group.SrcPathToRemove(srcObjPath))
@@ -167,6 +236,9 @@ func transfer(ctx context.Context, taskType taskType, srcObjPath, dstDirPath str
 
 	t.Creator, _ = ctx.Value(conf.UserKey).(*model.User)
 	t.ApiUrl = common.GetApiUrl(ctx)
+	t.RequestID = net.RequestID(ctx)
+	t.Priority = task.CtxPriority(ctx)
+	t.SpeedLimit = task.CtxSpeedLimit(ctx)
 	if taskType == copy || taskType == merge {
 		CopyTaskManager.Add(t)
 	} else {
@@ -210,6 +282,15 @@ func (t *FileTransferTask) RunWithNextTaskCallback(f func(nextTask *FileTransfer
 			}
 		}
 
+		// with files in flight configured, the files of the dir are transferred by this task
+		// instead of a task for each of them
+		filesInFlight := setting.GetInt(conf.TaskCopyFilesInFlight, 0)
+		// small files are packed into tar streams if the dst storage can extract them
+		var smallFileSize int64
+		if _, ok := t.DstStorage.(driver.PutTar); ok && !isLocalTransfer(t.SrcStorage, t.DstStorage) {
+			smallFileSize = int64(setting.GetInt(conf.TaskCopySmallFileSize, 0))
+		}
+		var files, smallFiles []model.Obj
 		for _, obj := range objs {
 			if err := t.Ctx().Err(); err != nil {
 				return err
@@ -219,13 +300,25 @@ func (t *FileTransferTask) RunWithNextTaskCallback(f func(nextTask *FileTransfer
 				// skip existed file
 				continue
 			}
-
+			if smallFileSize > 0 && !obj.IsDir() && obj.GetSize() <= smallFileSize {
+				smallFiles = append(smallFiles, obj)
+				continue
+			}
+			if filesInFlight > 1 && !obj.IsDir() {
+				files = append(files, obj)
+				continue
+			}
 			err = f(&FileTransferTask{
-				TaskType: t.TaskType,
+				TaskType:      t.TaskType,
+				Transactional: t.Transactional,
+				Verify:        t.Verify,
 				TaskData: TaskData{
 					TaskExtension: task.TaskExtension{
-						Creator: t.Creator,
-						ApiUrl:  t.ApiUrl,
+						Creator:    t.Creator,
+						ApiUrl:     t.ApiUrl,
+						RequestID:  t.RequestID,
+						Priority:   t.Priority,
+						SpeedLimit: t.SpeedLimit,
 					},
 					SrcStorage:    t.SrcStorage,
 					DstStorage:    t.DstStorage,
@@ -240,30 +333,209 @@ func (t *FileTransferTask) RunWithNextTaskCallback(f func(nextTask *FileTransfer
 				return err
 			}
 		}
+		if len(files) > 0 || len(smallFiles) > 0 {
+			progress := newProgressTracker(t.PausableProgress(t.SetProgress, t.StoragesPaused), files, smallFiles)
+			t.SetTotalBytes(progress.total)
+			if len(smallFiles) > 0 {
+				t.Status = fmt.Sprintf("src object is dir, packing %d small files", len(smallFiles))
+				if err = t.putSmallFiles(smallFiles, dstActualPath, progress); err != nil {
+					return err
+				}
+			}
+			if len(files) > 0 {
+				t.Status = fmt.Sprintf("src object is dir, transferring %d files", len(files))
+				if err = t.putFiles(files, dstActualPath, filesInFlight, progress); err != nil {
+					return err
+				}
+			}
+			t.SetProgress(100)
+		}
 		t.Status = fmt.Sprintf("src object is dir, added all %s tasks of objs", t.TaskType)
 		return nil
 	}
 
-	t.Status = "getting src object link"
-	link, srcObj, err := op.Link(t.Ctx(), t.SrcStorage, t.SrcActualPath, model.LinkArgs{})
+	t.SetTotalBytes(srcObj.GetSize())
+	t.Status = "uploading"
+	return t.putFile(t.Ctx(), t.SrcActualPath, t.DstActualPath, t.PausableProgress(t.SetProgress, t.StoragesPaused))
+}
+
+// putFile transfers the file at srcActualPath into dstDirActualPath
+func (t *FileTransferTask) putFile(ctx context.Context, srcActualPath, dstDirActualPath string, up model.UpdateProgress) error {
+	srcObj, err := op.Get(ctx, t.SrcStorage, srcActualPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] file", srcActualPath)
+	}
+	dstObjActualPath := stdpath.Join(dstDirActualPath, srcObj.GetName())
+	var overwritten bool
+	if t.Transactional {
+		_, err = op.Get(ctx, t.DstStorage, dstObjActualPath)
+		overwritten = err == nil
+	}
+	putCtx := context.WithValue(context.WithValue(ctx, conf.SkipHookKey, struct{}{}), conf.ServerPutKey, struct{}{})
+	if isLocalTransfer(t.SrcStorage, t.DstStorage) {
+		// the src file of a move is removed after all of the transfers like the others
+		err = op.PutLocal(putCtx, t.SrcStorage, srcActualPath, t.DstStorage, dstDirActualPath, false)
+	} else {
+		err = t.putRapid(putCtx, srcObj, dstDirActualPath)
+	}
+	if err == nil {
+		up(100)
+	} else {
+		err = t.putStream(putCtx, srcActualPath, dstDirActualPath, up)
+		if err == nil {
+			op.RecordStorageTraffic(t.SrcStorage.GetStorage().MountPath, srcObj.GetSize(), 0)
+		}
+	}
+	if err == nil && t.Verify {
+		err = t.verify(ctx, srcObj, dstObjActualPath)
+	}
+	if err == nil && t.Transactional {
+		if overwritten {
+			task_group.TransferCoordinator.AppendPayload(t.groupID, task_group.DstPathOverwritten(dstObjActualPath))
+		} else {
+			task_group.TransferCoordinator.AppendPayload(t.groupID, task_group.DstPathCreated(dstObjActualPath))
+		}
+	}
+	return err
+}
+
+// putRapid creates the dst file from the hashes of the src file if both storages are of the same provider,
+// so that no data flows through the server
+func (t *FileTransferTask) putRapid(ctx context.Context, srcObj model.Obj, dstDirActualPath string) error {
+	if _, ok := t.DstStorage.(driver.PutRapid); !ok || t.SrcStorage.Config().Name != t.DstStorage.Config().Name {
+		return errs.NotSupport
+	}
+	return op.PutRapid(ctx, t.DstStorage, dstDirActualPath, &stream.FileStream{
+		Obj: srcObj,
+		Ctx: ctx,
+	})
+}
+
+// dstExists reports whether the obj may exist at the dst, i.e. unless getting it fails with not found
+func dstExists(ctx context.Context, dstStorage driver.Driver, dstObjActualPath string) bool {
+	_, err := op.Get(ctx, dstStorage, dstObjActualPath)
+	return !errs.IsObjectNotFound(err)
+}
+
+// isLocalTransfer reports whether the files can be transferred between the storages on the host filesystem
+func isLocalTransfer(srcStorage, dstStorage driver.Driver) bool {
+	_, srcOk := srcStorage.(driver.LocalTransfer)
+	_, dstOk := dstStorage.(driver.LocalTransfer)
+	return srcOk && dstOk
+}
+
+func (t *FileTransferTask) putStream(ctx context.Context, srcActualPath, dstDirActualPath string, up model.UpdateProgress) error {
+	link, srcObj, err := op.Link(ctx, t.SrcStorage, srcActualPath, model.LinkArgs{})
 	if err != nil {
-		return errors.WithMessagef(err, "failed get [%s] link", t.SrcActualPath)
+		return errors.WithMessagef(err, "failed get [%s] link", srcActualPath)
 	}
 	// any link provided is seekable
 	ss, err := stream.NewSeekableStream(&stream.FileStream{
 		Obj: srcObj,
-		Ctx: t.Ctx(),
+		Ctx: ctx,
 	}, link)
 	if err != nil {
 		_ = link.Close()
-		return errors.WithMessagef(err, "failed get [%s] stream", t.SrcActualPath)
+		return errors.WithMessagef(err, "failed get [%s] stream", srcActualPath)
 	}
-	t.SetTotalBytes(ss.GetSize())
-	t.Status = "uploading"
-	return op.Put(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), t.DstStorage, t.DstActualPath, ss, t.SetProgress)
+	return op.Put(ctx, t.DstStorage, dstDirActualPath, ss, up)
+}
+
+// putFiles transfers the files of the src dir with at most concurrency of them in flight
+func (t *FileTransferTask) putFiles(files []model.Obj, dstDirActualPath string, concurrency int, progress *progressTracker) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failed   int
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+loop:
+	for _, obj := range files {
+		select {
+		case <-t.Ctx().Done():
+			break loop
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			size := float64(obj.GetSize())
+			var last float64
+			err := t.putFile(t.Ctx(), stdpath.Join(t.SrcActualPath, obj.GetName()), dstDirActualPath, func(percentage float64) {
+				cur := size * percentage / 100
+				progress.add(cur - last)
+				last = cur
+			})
+			if err != nil {
+				mu.Lock()
+				failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			} else {
+				// the transferred files are removed from the src, or rolled back, even if the others fail
+				task_group.TransferCoordinator.MarkSucceeded(t.groupID)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := t.Ctx().Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed to %s: %w", failed, len(files), t.TaskType, firstErr)
+	}
+	return nil
+}
+
+func (t *FileTransferTask) verify(ctx context.Context, srcObj model.Obj, dstObjActualPath string) error {
+	dstObj, err := op.Get(ctx, t.DstStorage, dstObjActualPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get dst [%s] file to verify", dstObjActualPath)
+	}
+	if err = verifyTransfer(srcObj, dstObj); err == nil {
+		if e := op.RecordScrubFile(utils.GetFullPath(t.DstStorage.GetStorage().MountPath, dstObjActualPath), srcObj); e != nil {
+			log.Warnf("failed record [%s] to scrub: %+v", dstObjActualPath, e)
+		}
+		return nil
+	}
+	if t.TaskType == move {
+		// the src file is removed once a file with the same name is found at the dst,
+		// so the mismatched file must not be kept
+		if e := op.Remove(ctx, t.DstStorage, dstObjActualPath); e != nil {
+			return errors.WithMessagef(err, "failed remove mismatched [%s]: %v", dstObjActualPath, e)
+		}
+	}
+	return err
+}
+
+// verifyTransfer compares the hashes that both objs provide, or their sizes if they share none
+func verifyTransfer(src, dst model.Obj) error {
+	dstHash := dst.GetHash()
+	for ht, srcSum := range src.GetHash().All() {
+		dstSum := dstHash.GetHash(ht)
+		if srcSum == "" || dstSum == "" {
+			continue
+		}
+		if !strings.EqualFold(srcSum, dstSum) {
+			return errs.NewErr(errs.VerifyFailed, "%s mismatch: %s != %s", ht.Name, srcSum, dstSum)
+		}
+		return nil
+	}
+	if src.GetSize() != dst.GetSize() {
+		return errs.NewErr(errs.VerifyFailed, "size mismatch: %d != %d", src.GetSize(), dst.GetSize())
+	}
+	return nil
 }
 
 var (
 	CopyTaskManager *tache.Manager[*FileTransferTask]
 	MoveTaskManager *tache.Manager[*FileTransferTask]
+	CopyTaskSlots   *task.Slots
+	MoveTaskSlots   *task.Slots
 )
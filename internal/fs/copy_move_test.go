@@ -0,0 +1,108 @@
+package fs_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/OpenListTeam/OpenList/v4/drivers/local"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file:fs_test?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig("data")
+	db.Init(dB)
+}
+
+// mountLocal mounts a local storage of a temp dir with the files at mountPath, a file ending with / is a dir
+func mountLocal(t *testing.T, mountPath string, files ...string) string {
+	root := t.TempDir()
+	for _, file := range files {
+		p := filepath.Join(root, filepath.FromSlash(file))
+		if file[len(file)-1] == '/' {
+			if err := os.MkdirAll(p, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(file), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	return root
+}
+
+func assertExists(t *testing.T, root string, files map[string]bool) {
+	t.Helper()
+	for file, want := range files {
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(file)))
+		if got := err == nil; got != want {
+			t.Errorf("%s exists: %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestMoveIntoExistingDir(t *testing.T) {
+	src := mountLocal(t, "/merge_src", "d/a.txt")
+	dst := mountLocal(t, "/merge_dst", "d/b.txt")
+	ctx := context.WithValue(context.Background(), conf.NoTaskKey, struct{}{})
+	if _, err := fs.Move(ctx, "/merge_src/d", "/merge_dst"); err != nil {
+		t.Fatalf("failed to move: %+v", err)
+	}
+	assertExists(t, dst, map[string]bool{"d/a.txt": true, "d/b.txt": true})
+	assertExists(t, src, map[string]bool{"d/a.txt": false})
+}
+
+func TestMoveWithFailedFileInFlight(t *testing.T) {
+	err := op.SaveSettingItem(&model.SettingItem{Key: conf.TaskCopyFilesInFlight, Value: "4", Type: conf.TypeNumber, Group: model.TRAFFIC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = op.SaveSettingItem(&model.SettingItem{Key: conf.TaskCopyFilesInFlight, Value: "0", Type: conf.TypeNumber, Group: model.TRAFFIC})
+	}()
+	// b.txt can't be put as a dir of its name is in the way
+	files := []string{"d/a.txt", "d/b.txt", "d/c.txt"}
+	ctx := context.WithValue(context.Background(), conf.NoTaskKey, struct{}{})
+
+	src := mountLocal(t, "/inflight_src", files...)
+	dst := mountLocal(t, "/inflight_dst", "d/b.txt/")
+	if _, err = fs.Move(ctx, "/inflight_src/d", "/inflight_dst"); err == nil {
+		t.Fatal("expected the move to fail")
+	}
+	// the moved files are removed from the src
+	assertExists(t, src, map[string]bool{"d/a.txt": false, "d/b.txt": true, "d/c.txt": false})
+	assertExists(t, dst, map[string]bool{"d/a.txt": true, "d/c.txt": true})
+
+	src = mountLocal(t, "/inflight_tx_src", files...)
+	dst = mountLocal(t, "/inflight_tx_dst", "d/b.txt/")
+	if _, err = fs.Move(context.WithValue(ctx, conf.TransactionalKey, struct{}{}), "/inflight_tx_src/d", "/inflight_tx_dst"); err == nil {
+		t.Fatal("expected the move to fail")
+	}
+	// the moved files are rolled back
+	assertExists(t, src, map[string]bool{"d/a.txt": true, "d/b.txt": true, "d/c.txt": true})
+	assertExists(t, dst, map[string]bool{"d/a.txt": false, "d/c.txt": false})
+}
@@ -0,0 +1,59 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// BackupDB copies the sqlite database while it's in use into dstDirPath, and returns the name of the backup
+func BackupDB(ctx context.Context, dstDirPath string) (string, error) {
+	dir, err := utils.PickTempDir(0)
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("openlist-%s.db", time.Now().Format("20060102-150405"))
+	tmpPath := filepath.Join(dir, name)
+	if err = db.BackupSQLite(tmpPath); err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := os.Remove(tmpPath); err != nil {
+			log.Warnf("failed remove database backup %s: %+v", tmpPath, err)
+		}
+	}()
+	f, err := os.Open(tmpPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return "", errors.WithStack(err)
+	}
+	s := &stream.FileStream{
+		Ctx: ctx,
+		Obj: &model.Object{
+			Name:     name,
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+		},
+		Reader:   f,
+		Mimetype: "application/vnd.sqlite3",
+	}
+	s.Closers.Add(f)
+	if err = putDirectly(ctx, dstDirPath, s, true); err != nil {
+		return "", errors.WithMessage(err, "failed upload database backup")
+	}
+	log.Infof("backed up database to %s", utils.GetFullPath(dstDirPath, name))
+	return name, nil
+}
@@ -0,0 +1,114 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/delta"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	"github.com/OpenListTeam/go-cache"
+	"github.com/pkg/errors"
+)
+
+// the delta uploads update a file of a storage implementing driver.PutDelta by the changed parts only:
+// the client sends the signatures of the blocks of the new file, the plan tells the ranges of the new file
+// the existing one doesn't have, and the client uploads these ranges to apply the plan
+
+const (
+	DeltaMinBlockSize = 4 * 1024
+	DeltaMaxBlockSize = 16 * 1024 * 1024
+	DeltaMaxBlocks    = 1 << 20
+	deltaPlanExpire   = time.Hour
+)
+
+var deltaPlans = cache.NewMemCache[*DeltaPlan]()
+
+type DeltaPlan struct {
+	ID   string
+	Path string
+	// Size of the new file
+	Size int64
+	// the existing file the plan is made with, it's applied only if the file isn't changed meanwhile
+	oldSize     int64
+	oldModified time.Time
+	ops         []delta.Op
+}
+
+// Missing returns the ranges of the new file to upload, in order
+func (p *DeltaPlan) Missing() []delta.Range {
+	return delta.Missing(p.ops)
+}
+
+// DataSize returns the bytes to upload
+func (p *DeltaPlan) DataSize() int64 {
+	return delta.DataSize(p.ops)
+}
+
+// PlanDelta matches the blocks of the new file of size with the existing file at path
+func PlanDelta(ctx context.Context, path string, size int64, blockSize int, blocks []delta.Block) (*DeltaPlan, error) {
+	if blockSize < DeltaMinBlockSize || blockSize > DeltaMaxBlockSize {
+		return nil, errors.Errorf("the block size has to be between %d and %d", DeltaMinBlockSize, DeltaMaxBlockSize)
+	}
+	if len(blocks) > DeltaMaxBlocks {
+		return nil, errors.Errorf("too many blocks, the max is %d", DeltaMaxBlocks)
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	if _, ok := storage.(driver.PutDelta); !ok {
+		return nil, errors.WithStack(errs.NotSupport)
+	}
+	link, obj, err := op.Link(ctx, storage, actualPath, model.LinkArgs{})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		_ = link.Close()
+		return nil, errors.WithMessage(err, "failed get stream")
+	}
+	matches, err := delta.Match(ss, blockSize, size, blocks)
+	_ = ss.Close()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed match blocks")
+	}
+	p := &DeltaPlan{
+		ID:          random.String(16),
+		Path:        path,
+		Size:        size,
+		oldSize:     obj.GetSize(),
+		oldModified: obj.ModTime(),
+		ops:         delta.Ops(matches, blockSize, size),
+	}
+	deltaPlans.Set(p.ID, p, cache.WithEx[*DeltaPlan](deltaPlanExpire))
+	return p, nil
+}
+
+// ApplyDelta applies the plan of the id to the file at path, data is the missing ranges of the new file in order.
+// A plan is applied once
+func ApplyDelta(ctx context.Context, id, path string, data io.Reader, modified time.Time) error {
+	p, ok := deltaPlans.Get(id)
+	if !ok || p.Path != path {
+		return errors.New("the delta plan is not found or expired")
+	}
+	deltaPlans.Del(id)
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get file")
+	}
+	if obj.GetSize() != p.oldSize || !obj.ModTime().Equal(p.oldModified) {
+		return errors.New("the file is changed since the delta plan is made")
+	}
+	return op.PutDelta(ctx, storage, actualPath, p.ops, data, p.Size, modified)
+}
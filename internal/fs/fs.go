@@ -3,6 +3,7 @@ package fs
 import (
 	"context"
 	"io"
+	"net/http"
 
 	log "github.com/sirupsen/logrus"
 
@@ -22,6 +23,7 @@ type ListArgs struct {
 	Refresh            bool
 	NoLog              bool
 	WithStorageDetails bool
+	SkipHook           bool
 }
 
 func List(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error) {
@@ -124,6 +126,15 @@ func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 	return t, err
 }
 
+// PutURLAsTask adds the upload task fetching the url with the header into dstDirPath as name
+func PutURLAsTask(ctx context.Context, dstDirPath, name, url string, header http.Header) (task.TaskExtensionInfo, error) {
+	t, err := putURLAsTask(ctx, dstDirPath, name, url, header)
+	if err != nil {
+		log.Errorf("failed put %s from %s: %+v", dstDirPath, url, err)
+	}
+	return t, err
+}
+
 func ArchiveMeta(ctx context.Context, path string, args model.ArchiveMetaArgs) (*model.ArchiveMetaProvider, error) {
 	meta, err := archiveMeta(ctx, path, args)
 	if err != nil {
@@ -196,7 +207,15 @@ func PutURL(ctx context.Context, path, dstName, urlStr string) error {
 	if !ok && !okResult {
 		return errs.NotImplement
 	}
-	return op.PutURL(ctx, storage, dstDirActualPath, dstName, urlStr)
+	// the storage fetches the file itself, so the quota is reserved by the size the url reports,
+	// the file of an unknown size is fetched here instead
+	var size int64
+	if quotaApplies(ctx, path) {
+		if size, err = urlSize(ctx, urlStr); err != nil || size < 0 {
+			return errs.NotImplement
+		}
+	}
+	return op.PutURL(ctx, storage, dstDirActualPath, dstName, urlStr, size)
 }
 
 func GetDirectUploadInfo(ctx context.Context, tool, path, dstName string, fileSize int64) (any, error) {
@@ -0,0 +1,205 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	stdpath "path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/disintegration/imaging"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the resized variants of the uploaded images are kept next to them as <dir>/<variant dir>/<width>/<name>,
+// so they are stored and served by any storage like the other files
+
+const (
+	imageVariantMaxSize   = 64 << 20
+	imageVariantMaxPixels = 50_000_000
+)
+
+var (
+	imageVariantQueue = make(chan model.FsEvent, 256)
+	imageVariantOnce  sync.Once
+)
+
+// imageVariantSizes returns the widths of the image_variant_sizes setting in ascending order
+func imageVariantSizes() []int {
+	var sizes []int
+	for _, s := range strings.Split(setting.GetStr(conf.ImageVariantSizes), ",") {
+		if size, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && size > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	slices.Sort(sizes)
+	return slices.Compact(sizes)
+}
+
+func imageVariantPath(path string, size int) string {
+	dir := setting.GetStr(conf.ImageVariantDir, ".variants")
+	return stdpath.Join(stdpath.Dir(path), dir, strconv.Itoa(size), stdpath.Base(path))
+}
+
+// needImageVariants reports whether the variants of the image at path are made,
+// the animated gifs are left out as only their first frame would be kept
+func needImageVariants(path string) bool {
+	if format, err := imaging.FormatFromFilename(path); err != nil || format == imaging.GIF {
+		return false
+	}
+	dir := setting.GetStr(conf.ImageVariantDir, ".variants")
+	if slices.Contains(strings.Split(path, "/"), dir) {
+		return false
+	}
+	paths := setting.GetStr(conf.ImageVariantPaths)
+	if strings.TrimSpace(paths) == "" {
+		return true
+	}
+	for _, p := range strings.Split(paths, ",") {
+		if p = strings.TrimSpace(p); p != "" && utils.IsSubPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageVariantOnEvent queues the uploaded and removed images, their variants are made or removed
+// by a single worker so that the uploads aren't slowed down
+func imageVariantOnEvent(event model.FsEvent) {
+	if event.IsDir || event.Remote || (event.Type != model.FsEventCreate && event.Type != model.FsEventDelete) {
+		return
+	}
+	if len(imageVariantSizes()) == 0 || !needImageVariants(event.Path) {
+		return
+	}
+	imageVariantOnce.Do(func() { go imageVariantWorker() })
+	select {
+	case imageVariantQueue <- event:
+	default:
+		log.Warnf("image variant queue is full, dropped event: %+v", event)
+	}
+}
+
+func imageVariantWorker() {
+	for event := range imageVariantQueue {
+		ctx := context.Background()
+		if event.Type == model.FsEventDelete {
+			removeImageVariants(ctx, event.Path)
+			continue
+		}
+		if err := makeImageVariants(ctx, event.Path); err != nil {
+			log.Errorf("failed make the variants of [%s]: %+v", event.Path, err)
+		}
+	}
+}
+
+// makeImageVariants puts a variant of the image at path for each of the sizes,
+// the images narrower than a size are re-encoded without being enlarged
+func makeImageVariants(ctx context.Context, path string) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if storage.Config().NoUpload {
+		return nil
+	}
+	link, obj, err := op.Link(ctx, storage, actualPath, model.LinkArgs{})
+	if err != nil {
+		return errors.WithMessage(err, "failed get link")
+	}
+	if obj.GetSize() > imageVariantMaxSize {
+		_ = link.Close()
+		return errors.Errorf("the image of %d bytes exceeds the max size of %d bytes", obj.GetSize(), imageVariantMaxSize)
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		_ = link.Close()
+		return errors.WithMessage(err, "failed get stream")
+	}
+	data, err := io.ReadAll(ss)
+	_ = ss.Close()
+	if err != nil {
+		return errors.WithMessage(err, "failed read image")
+	}
+	// checked before decoding as a small file may be a huge image
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "failed decode image")
+	}
+	if cfg.Width*cfg.Height > imageVariantMaxPixels {
+		return errors.Errorf("the image of %dx%d pixels is too large", cfg.Width, cfg.Height)
+	}
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return errors.Wrap(err, "failed decode image")
+	}
+	format, _ := imaging.FormatFromFilename(path)
+	name := stdpath.Base(actualPath)
+	ctx = context.WithValue(context.WithValue(ctx, conf.SkipHookKey, struct{}{}), conf.ServerPutKey, struct{}{})
+	for _, size := range imageVariantSizes() {
+		variant := img
+		if img.Bounds().Dx() > size {
+			variant = imaging.Resize(img, size, 0, imaging.Lanczos)
+		}
+		var buf bytes.Buffer
+		if err = imaging.Encode(&buf, variant, format, imaging.JPEGQuality(85)); err != nil {
+			return errors.Wrap(err, "failed encode image")
+		}
+		s := &stream.FileStream{
+			Ctx: ctx,
+			Obj: &model.Object{
+				Name:     name,
+				Size:     int64(buf.Len()),
+				Modified: obj.ModTime(),
+			},
+			Reader:   bytes.NewReader(buf.Bytes()),
+			Mimetype: utils.GetMimeType(name),
+		}
+		if err = op.Put(ctx, storage, stdpath.Dir(imageVariantPath(actualPath, size)), s, nil); err != nil {
+			return errors.WithMessagef(err, "failed put the variant of width %d", size)
+		}
+	}
+	return nil
+}
+
+func removeImageVariants(ctx context.Context, path string) {
+	for _, size := range imageVariantSizes() {
+		variant := imageVariantPath(path, size)
+		if _, err := Get(ctx, variant, &GetArgs{NoLog: true}); err != nil {
+			continue
+		}
+		_ = Remove(ctx, variant)
+	}
+}
+
+// ImageVariant returns the path of the narrowest variant of the image at path which is at least size wide,
+// or of the widest one if none is
+func ImageVariant(ctx context.Context, path string, size int) (string, bool) {
+	sizes := imageVariantSizes()
+	if len(sizes) == 0 {
+		return "", false
+	}
+	i, _ := slices.BinarySearch(sizes, size)
+	for i = min(i, len(sizes)-1); i < len(sizes); i++ {
+		variant := imageVariantPath(path, sizes[i])
+		if obj, err := Get(ctx, variant, &GetArgs{NoLog: true}); err == nil && !obj.IsDir() {
+			return variant, true
+		}
+	}
+	return "", false
+}
+
+func init() {
+	op.RegisterFsEventHook(imageVariantOnEvent)
+}
@@ -0,0 +1,172 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/sign"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var indexHTML = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Index of {{.Path}}</title></head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<p>Generated at {{.Generated.Format "2006-01-02 15:04:05 MST"}}</p>
+{{template "nodes" .Content}}
+</body>
+</html>
+{{define "nodes"}}<ul>
+{{range .}}<li>{{if .IsDir}}{{if .URL}}<a href="{{.URL}}">{{.Name}}/</a>{{else}}{{.Name}}/{{end}}{{if .Children}}{{template "nodes" .Children}}{{end}}{{else}}<a href="{{.URL}}">{{.Name}}</a> ({{.Size}} bytes){{end}}</li>
+{{end}}</ul>{{end}}`))
+
+// BuildIndex builds the snapshot of the tree under path as a guest sees it,
+// the files have signed download links
+func BuildIndex(ctx context.Context, path string, maxDepth int) (*model.Index, error) {
+	guest, err := op.GetGuest()
+	if err != nil {
+		return nil, err
+	}
+	path = utils.FixAndCleanPath(path)
+	b := &indexBuilder{
+		ctx:      context.WithValue(ctx, conf.UserKey, guest),
+		guest:    guest,
+		maxDepth: maxDepth,
+		apiUrl:   common.GetApiUrl(ctx),
+	}
+	if b.apiUrl == "" && strings.HasPrefix(conf.Conf.SiteURL, "http") {
+		b.apiUrl = strings.TrimSuffix(conf.Conf.SiteURL, "/")
+	}
+	content, err := b.build(path, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Index{Path: path, Generated: time.Now(), Content: content}, nil
+}
+
+type indexBuilder struct {
+	ctx      context.Context
+	guest    *model.User
+	maxDepth int
+	apiUrl   string
+}
+
+func (b *indexBuilder) build(dirPath string, depth int) ([]*model.IndexNode, error) {
+	if err := b.ctx.Err(); err != nil {
+		return nil, err
+	}
+	meta, err := op.GetNearestMeta(dirPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	// the password protected and hidden directories are left out of a public index
+	if !common.CanAccess(b.guest, meta, dirPath, "") {
+		return nil, nil
+	}
+	objs, err := List(context.WithValue(b.ctx, conf.MetaKey, meta), dirPath, &ListArgs{NoLog: true})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed list [%s]", dirPath)
+	}
+	nodes := make([]*model.IndexNode, 0, len(objs))
+	for _, obj := range objs {
+		objPath := stdpath.Join(dirPath, obj.GetName())
+		node := &model.IndexNode{
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			IsDir:    obj.IsDir(),
+			Modified: obj.ModTime(),
+		}
+		if obj.IsDir() {
+			if b.maxDepth > 0 && depth >= b.maxDepth {
+				continue
+			}
+			if node.Children, err = b.build(objPath, depth+1); err != nil {
+				return nil, err
+			}
+		} else {
+			node.URL = b.apiUrl + "/d" + utils.EncodePath(objPath, true) + "?sign=" + sign.Sign(objPath)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// ExportIndex writes the index of e.Path into e.DstPath and records the result in e
+func ExportIndex(ctx context.Context, e *model.IndexExport) error {
+	err := exportIndex(ctx, e)
+	now := time.Now()
+	e.LastExported = &now
+	e.LastError = ""
+	if err != nil {
+		e.LastError = err.Error()
+		log.Errorf("failed export index of %s: %+v", e.Path, err)
+	}
+	if err := op.SaveIndexExportResult(e); err != nil {
+		log.Errorf("failed save index export result: %+v", err)
+	}
+	return err
+}
+
+func exportIndex(ctx context.Context, e *model.IndexExport) error {
+	index, err := BuildIndex(ctx, e.Path, e.MaxDepth)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := putIndexFile(ctx, e.DstPath, "index.json", "application/json", data); err != nil {
+		return err
+	}
+	if !e.HTML {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := indexHTML.Execute(&buf, index); err != nil {
+		return errors.WithStack(err)
+	}
+	return putIndexFile(ctx, e.DstPath, "index.html", "text/html; charset=utf-8", buf.Bytes())
+}
+
+func putIndexFile(ctx context.Context, dstDirPath, name, mimetype string, data []byte) error {
+	return putDirectly(ctx, dstDirPath, &stream.FileStream{
+		Ctx: ctx,
+		Obj: &model.Object{
+			Name:     name,
+			Size:     int64(len(data)),
+			Modified: time.Now(),
+		},
+		Reader:   bytes.NewReader(data),
+		Mimetype: mimetype,
+	}, true)
+}
+
+// ExportDueIndexes runs the scheduled index exports which are due
+func ExportDueIndexes() {
+	exports, err := op.GetIndexExports()
+	if err != nil {
+		log.Errorf("failed get index exports: %+v", err)
+		return
+	}
+	now := time.Now()
+	for i := range exports {
+		if exports[i].Due(now) {
+			_ = ExportIndex(context.Background(), &exports[i])
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	stdpath "path"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/tache"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// IngestItem is a file matched by an ingest rule and what is done to it
+type IngestItem struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Action   string    `json:"action"`
+	Dst      string    `json:"dst,omitempty"`
+	// Skipped is the reason why nothing is done, e.g. the destination exists
+	Skipped string `json:"skipped,omitempty"`
+
+	obj model.Obj
+}
+
+// PlanIngest walks the scope of the rule and returns the matched files without touching them,
+// it's the dry run of the rule
+func PlanIngest(ctx context.Context, rule *model.IngestRule) ([]IngestItem, error) {
+	var dst *template.Template
+	if rule.Action == model.IngestMove || rule.Action == model.IngestCopy {
+		var err error
+		dst, err = template.New("dst").Parse(rule.Dst)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	var items []IngestItem
+	now := time.Now()
+	err := walkIngestScope(ctx, rule, rule.Path, func(dirPath string, objs []model.Obj) error {
+		var files []model.Obj
+		for _, obj := range objs {
+			if !obj.IsDir() && rule.MatchName(obj.GetName(), utils.GetMimeType(obj.GetName())) {
+				files = append(files, obj)
+			}
+		}
+		if rule.KeepNewest > 0 {
+			if len(files) <= rule.KeepNewest {
+				return nil
+			}
+			slices.SortStableFunc(files, func(a, b model.Obj) int {
+				return b.ModTime().Compare(a.ModTime())
+			})
+			files = files[rule.KeepNewest:]
+		}
+		for _, obj := range files {
+			if !rule.Match(obj, utils.GetMimeType(obj.GetName()), now) {
+				continue
+			}
+			item := IngestItem{
+				Path:     stdpath.Join(dirPath, obj.GetName()),
+				Size:     obj.GetSize(),
+				Modified: obj.ModTime(),
+				Action:   rule.Action,
+				obj:      obj,
+			}
+			if dst != nil {
+				var buf bytes.Buffer
+				if err := dst.Execute(&buf, newIngestFile(rule, dirPath, obj, now)); err != nil {
+					return errors.WithMessagef(err, "failed render the destination of [%s]", item.Path)
+				}
+				item.Dst = utils.FixAndCleanPath(strings.TrimSpace(buf.String()))
+				if item.Dst == item.Path {
+					continue
+				}
+				if _, err := Get(ctx, item.Dst, &GetArgs{NoLog: true}); err == nil {
+					item.Skipped = "destination exists"
+				}
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+// walkIngestScope calls fn with the objects of each directory in the scope of the rule
+func walkIngestScope(ctx context.Context, rule *model.IngestRule, dirPath string, fn func(dirPath string, objs []model.Obj) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// the hooks are skipped, or the listing would trigger the rule again
+	objs, err := List(ctx, dirPath, &ListArgs{NoLog: true, Refresh: true, SkipHook: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", dirPath)
+	}
+	if err := fn(dirPath, objs); err != nil {
+		return err
+	}
+	if !rule.Recursive {
+		return nil
+	}
+	for _, obj := range objs {
+		if obj.IsDir() {
+			if err := walkIngestScope(ctx, rule, stdpath.Join(dirPath, obj.GetName()), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newIngestFile(rule *model.IngestRule, dirPath string, obj model.Obj, now time.Time) model.IngestFile {
+	name := obj.GetName()
+	modified := obj.ModTime()
+	return model.IngestFile{
+		FileName: name,
+		Name:     strings.TrimSuffix(name, stdpath.Ext(name)),
+		Ext:      utils.Ext(name),
+		Path:     strings.TrimPrefix(strings.TrimPrefix(dirPath, rule.Path), "/"),
+		Year:     modified.Format("2006"),
+		Month:    modified.Format("01"),
+		Day:      modified.Format("02"),
+		Now:      now,
+	}
+}
+
+// IngestTask runs an ingest rule once
+type IngestTask struct {
+	task.TaskExtension
+	Rule   model.IngestRule `json:"rule"`
+	Status string           `json:"-"`
+}
+
+func (t *IngestTask) GetName() string {
+	if t.Rule.Action == model.IngestMove || t.Rule.Action == model.IngestCopy {
+		return fmt.Sprintf("ingest [%s] by %s to [%s]", t.Rule.Path, t.Rule.Action, t.Rule.Dst)
+	}
+	return fmt.Sprintf("ingest [%s] by %s", t.Rule.Path, t.Rule.Action)
+}
+
+func (t *IngestTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *IngestTask) Run() error {
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	done, skipped, err := t.ingest()
+	t.saveResult(err)
+	if err != nil {
+		return err
+	}
+	t.Status = fmt.Sprintf("%s %d files, skipped %d files", t.Rule.Action, done, skipped)
+	return nil
+}
+
+func (t *IngestTask) saveResult(err error) {
+	r, e := op.GetIngestRuleById(t.Rule.ID)
+	if e != nil {
+		// the rule has been deleted
+		return
+	}
+	now := time.Now()
+	r.LastRun = &now
+	r.LastError = ""
+	if err != nil {
+		r.LastError = err.Error()
+	}
+	if e := op.SaveIngestRuleResult(r); e != nil {
+		log.Errorf("failed save ingest rule result: %+v", e)
+	}
+}
+
+func (t *IngestTask) ingest() (done, skipped int, err error) {
+	t.Status = "scanning " + t.Rule.Path
+	items, err := PlanIngest(t.Ctx(), &t.Rule)
+	if err != nil {
+		return 0, 0, err
+	}
+	var todo []IngestItem
+	for _, item := range items {
+		if item.Skipped != "" {
+			skipped++
+			continue
+		}
+		todo = append(todo, item)
+	}
+	if len(todo) == 0 {
+		return 0, skipped, nil
+	}
+	if t.Rule.Action == model.IngestNotify {
+		return len(todo), skipped, t.notify(todo)
+	}
+	for i, item := range todo {
+		if err = t.Ctx().Err(); err != nil {
+			break
+		}
+		t.Status = "ingesting " + item.Path
+		if err = t.ingestItem(item); err != nil {
+			break
+		}
+		done++
+		t.SetProgress(float64(i+1) * 100 / float64(len(todo)))
+	}
+	// the files handled before an error are reported too
+	if t.Rule.NotifyURL != "" && done > 0 {
+		if e := t.notify(todo[:done]); e != nil && err == nil {
+			err = e
+		}
+	}
+	return done, skipped, err
+}
+
+func (t *IngestTask) notify(items []IngestItem) error {
+	res, err := base.RestyClient.R().SetContext(t.Ctx()).SetBody(map[string]any{
+		"rule_id": t.Rule.ID,
+		"path":    t.Rule.Path,
+		"action":  t.Rule.Action,
+		"files":   items,
+	}).Post(t.Rule.NotifyURL)
+	if err != nil {
+		return errors.WithMessage(err, "failed notify")
+	}
+	if res.IsError() {
+		return errors.Errorf("failed notify: %s", res.Status())
+	}
+	return nil
+}
+
+// ingestItem moves, copies or deletes the file of the item
+func (t *IngestTask) ingestItem(item IngestItem) error {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(item.Path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	ctx := context.WithValue(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), conf.ServerPutKey, struct{}{})
+	if t.Rule.Action == model.IngestDelete {
+		if err := op.Remove(ctx, srcStorage, srcActualPath); err != nil {
+			return errors.WithMessagef(err, "failed remove [%s]", item.Path)
+		}
+		return nil
+	}
+	dstDirPath, dstName := stdpath.Split(item.Dst)
+	if err := makeDir(t.Ctx(), dstDirPath); err != nil {
+		return errors.WithMessagef(err, "failed make dir [%s]", dstDirPath)
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	move := t.Rule.Action == model.IngestMove
+	if move && srcStorage.GetStorage().MountPath == dstStorage.GetStorage().MountPath {
+		return t.moveItem(ctx, item, srcStorage, srcActualPath, dstDirActualPath, dstName)
+	}
+	link, obj, err := op.Link(ctx, srcStorage, srcActualPath, model.LinkArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", item.Path)
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{
+		Obj: &model.ObjWrapName{Name: dstName, Obj: obj},
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		_ = link.Close()
+		return errors.WithMessagef(err, "failed get [%s] stream", item.Path)
+	}
+	if err := op.Put(ctx, dstStorage, dstDirActualPath, ss, nil); err != nil {
+		return errors.WithMessagef(err, "failed copy [%s]", item.Path)
+	}
+	if move {
+		if err := op.Remove(ctx, srcStorage, srcActualPath); err != nil {
+			return errors.WithMessagef(err, "failed remove [%s]", item.Path)
+		}
+	}
+	return nil
+}
+
+// moveItem moves the file of the item within its storage, the existing files of the destination dir are never
+// overwritten, so the file is renamed before the move if the destination dir has a file of its current name
+func (t *IngestTask) moveItem(ctx context.Context, item IngestItem, storage driver.Driver, srcActualPath, dstDirActualPath, dstName string) error {
+	exists := func(actualPath string) bool {
+		_, err := op.Get(ctx, storage, actualPath)
+		return err == nil
+	}
+	if exists(stdpath.Join(dstDirActualPath, dstName)) {
+		return errors.Errorf("failed move [%s]: [%s] exists", item.Path, item.Dst)
+	}
+	name := item.obj.GetName()
+	if name != dstName && exists(stdpath.Join(dstDirActualPath, name)) {
+		srcDirActualPath := stdpath.Dir(srcActualPath)
+		if exists(stdpath.Join(srcDirActualPath, dstName)) {
+			return errors.Errorf("failed move [%s]: [%s] exists in the destination dir and [%s] in the source dir",
+				item.Path, name, dstName)
+		}
+		if err := op.Rename(ctx, storage, srcActualPath, dstName); err != nil {
+			return errors.WithMessagef(err, "failed rename [%s]", item.Path)
+		}
+		srcActualPath, name = stdpath.Join(srcDirActualPath, dstName), dstName
+	}
+	if err := op.Move(ctx, storage, srcActualPath, dstDirActualPath); err != nil {
+		return errors.WithMessagef(err, "failed move [%s]", item.Path)
+	}
+	if name != dstName {
+		if err := op.Rename(ctx, storage, stdpath.Join(dstDirActualPath, name), dstName); err != nil {
+			return errors.WithMessagef(err, "failed rename [%s]", item.Dst)
+		}
+	}
+	return nil
+}
+
+var IngestTaskManager *tache.Manager[*IngestTask]
+
+// Ingest adds a task which runs the ingest rule once
+func Ingest(ctx context.Context, rule *model.IngestRule) (task.TaskExtensionInfo, error) {
+	if _, _, err := op.GetStorageAndActualPath(rule.Path); err != nil {
+		return nil, errors.WithMessage(err, "failed get src storage")
+	}
+	creator, _ := ctx.Value(conf.UserKey).(*model.User)
+	if creator == nil {
+		admin, err := op.GetAdmin()
+		if err != nil {
+			return nil, err
+		}
+		creator = admin
+	}
+	t := &IngestTask{
+		TaskExtension: task.TaskExtension{
+			Creator: creator,
+		},
+		Rule: *rule,
+	}
+	IngestTaskManager.Add(t)
+	return t, nil
+}
+
+// ingestQueued reports whether a task of the rule is waiting or running, so that the events don't pile up tasks
+func ingestQueued(ruleID uint) bool {
+	return len(IngestTaskManager.GetByCondition(func(t *IngestTask) bool {
+		if t.Rule.ID != ruleID {
+			return false
+		}
+		switch t.GetState() {
+		case tache.StatePending, tache.StateRunning, tache.StateWaitingRetry, tache.StateBeforeRetry:
+			return true
+		}
+		return false
+	})) > 0
+}
+
+// RunDueIngestRules adds the tasks of the ingest rules which are due
+func RunDueIngestRules() {
+	rules, err := op.GetIngestRules()
+	if err != nil {
+		log.Errorf("failed get ingest rules: %+v", err)
+		return
+	}
+	now := time.Now()
+	for i := range rules {
+		if !rules[i].Due(now) || ingestQueued(rules[i].ID) {
+			continue
+		}
+		// the next scan is counted from now rather than the end of the task, so a slow scan isn't queued twice
+		rules[i].LastRun = &now
+		if err := op.SaveIngestRuleResult(&rules[i]); err != nil {
+			log.Errorf("failed save ingest rule: %+v", err)
+			continue
+		}
+		if _, err := Ingest(context.Background(), &rules[i]); err != nil {
+			log.Errorf("failed add ingest task of rule %d: %+v", rules[i].ID, err)
+		}
+	}
+}
+
+// ingestOnUpdate runs the event driven rules whose scope contains the updated directory
+func ingestOnUpdate(ctx context.Context, parent string, objs []model.Obj) {
+	if IngestTaskManager == nil {
+		return
+	}
+	rules, err := op.GetIngestRules()
+	if err != nil {
+		log.Errorf("failed get ingest rules: %+v", err)
+		return
+	}
+	for i := range rules {
+		r := &rules[i]
+		if r.Disabled || !r.OnEvent || !r.InScope(parent) || ingestQueued(r.ID) {
+			continue
+		}
+		if _, err := Ingest(context.Background(), r); err != nil {
+			log.Errorf("failed add ingest task of rule %d: %+v", r.ID, err)
+		}
+	}
+}
+
+func init() {
+	op.RegisterObjsUpdateHook(ingestOnUpdate)
+}
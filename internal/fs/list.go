@@ -28,6 +28,7 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 			ReqPath:            path,
 			Refresh:            args.Refresh,
 			WithStorageDetails: args.WithStorageDetails,
+			SkipHook:           args.SkipHook,
 		})
 		if err != nil {
 			if !args.NoLog {
@@ -40,14 +41,38 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	}
 
 	om := model.NewObjMerge()
-	if whetherHide(user, meta, path) {
-		om.InitHideReg(meta.Hide)
+	if rule := common.HideRule(user, meta, path, common.Protocol(ctx)); rule != nil {
+		om.AddHideRule(rule)
+	}
+	if storage != nil {
+		applyListOptions(om, user, storage.GetStorage().ListOptions)
 	}
 	objs := om.Merge(_objs, virtualFiles...)
 	objs, err = filterReadableObjs(objs, user, path, meta)
+	sortObjs(objs, meta, path)
 	return objs, err
 }
 
+// applyListOptions applies the storage level list options, so that all protocols see the same view
+func applyListOptions(om *model.ObjMerge, user *model.User, opts model.ListOptions) {
+	if opts.HideSystemFiles {
+		om.SkipSystemFiles()
+	}
+	if opts.HidePatterns != "" && (user == nil || !user.CanSeeHides()) {
+		om.AddHideReg(opts.HidePatterns)
+	}
+}
+
+// sortObjs sorts objs by the sort defaults of the nearest meta which covers the path,
+// it overrides the order given by the storage
+func sortObjs(objs []model.Obj, meta *model.Meta, path string) {
+	if meta == nil || !common.MetaCoversPath(meta.Path, path, meta.SortSub) {
+		return
+	}
+	model.SortFiles(objs, meta.OrderBy, meta.OrderDirection)
+	model.ExtractFolder(objs, meta.ExtractFolder)
+}
+
 func filterReadableObjs(objs []model.Obj, user *model.User, reqPath string, parentMeta *model.Meta) ([]model.Obj, error) {
 	var result []model.Obj
 	for _, obj := range objs {
@@ -68,24 +93,3 @@ func filterReadableObjs(objs []model.Obj, user *model.User, reqPath string, pare
 	}
 	return result, nil
 }
-
-func whetherHide(user *model.User, meta *model.Meta, path string) bool {
-	// if is admin, don't hide
-	if user == nil || user.CanSeeHides() {
-		return false
-	}
-	// if meta is nil, don't hide
-	if meta == nil {
-		return false
-	}
-	// if meta.Hide is empty, don't hide
-	if meta.Hide == "" {
-		return false
-	}
-	// if meta doesn't apply to sub_folder, don't hide
-	if !common.MetaCoversPath(meta.Path, path, meta.HSub) {
-		return false
-	}
-	// if is guest, hide
-	return true
-}
@@ -0,0 +1,99 @@
+package fs
+
+import (
+	"context"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var objectIndexRebuild struct {
+	sync.Mutex
+	cancel   context.CancelFunc
+	progress model.ObjectIndexProgress
+}
+
+// RebuildObjectIndex lists the paths recursively from their storages in the background to index all of their objects
+func RebuildObjectIndex(paths []string) error {
+	if !op.ObjectIndexEnabled() {
+		return errors.New("the object index is disabled")
+	}
+	objectIndexRebuild.Lock()
+	defer objectIndexRebuild.Unlock()
+	if objectIndexRebuild.progress.Running {
+		return errors.New("the object index is being rebuilt")
+	}
+	for i := range paths {
+		paths[i] = utils.FixAndCleanPath(paths[i])
+	}
+	now := time.Now()
+	objectIndexRebuild.progress = model.ObjectIndexProgress{Running: true, Paths: paths, Started: &now}
+	var ctx context.Context
+	ctx, objectIndexRebuild.cancel = context.WithCancel(context.Background())
+	go func() {
+		var err error
+		for _, path := range paths {
+			if err = rebuildObjectIndex(ctx, path); err != nil {
+				break
+			}
+		}
+		objectIndexRebuild.Lock()
+		defer objectIndexRebuild.Unlock()
+		finished := time.Now()
+		objectIndexRebuild.progress.Running = false
+		objectIndexRebuild.progress.Finished = &finished
+		objectIndexRebuild.cancel()
+		if err != nil {
+			objectIndexRebuild.progress.Error = err.Error()
+			log.Errorf("failed rebuild object index: %+v", err)
+			return
+		}
+		log.Infof("rebuilt object index of %v: %d objects", paths, objectIndexRebuild.progress.ObjCount)
+	}()
+	return nil
+}
+
+func rebuildObjectIndex(ctx context.Context, dirPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	objs, err := List(ctx, dirPath, &ListArgs{NoLog: true, Refresh: true, SkipHook: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", dirPath)
+	}
+	if err = op.UpdateObjectIndex(dirPath, objs); err != nil {
+		return err
+	}
+	objectIndexRebuild.Lock()
+	objectIndexRebuild.progress.DirCount++
+	objectIndexRebuild.progress.ObjCount += uint64(len(objs))
+	objectIndexRebuild.Unlock()
+	for _, obj := range objs {
+		if obj.IsDir() {
+			if err = rebuildObjectIndex(ctx, stdpath.Join(dirPath, obj.GetName())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func StopObjectIndexRebuild() {
+	objectIndexRebuild.Lock()
+	defer objectIndexRebuild.Unlock()
+	if objectIndexRebuild.progress.Running {
+		objectIndexRebuild.cancel()
+	}
+}
+
+func GetObjectIndexProgress() model.ObjectIndexProgress {
+	objectIndexRebuild.Lock()
+	defer objectIndexRebuild.Unlock()
+	return objectIndexRebuild.progress
+}
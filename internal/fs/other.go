@@ -62,3 +62,8 @@ type TaskData struct {
 func (t *TaskData) GetStatus() string {
 	return t.Status
 }
+
+// UsesStorage reports whether the task reads or writes the storage mounted at mountPath
+func (t *TaskData) UsesStorage(mountPath string) bool {
+	return t.SrcStorageMp == mountPath || t.DstStorageMp == mountPath
+}
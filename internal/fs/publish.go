@@ -0,0 +1,202 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	stdpath "path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/OpenListTeam/tache"
+	"github.com/pkg/errors"
+)
+
+const publishIndexName = "index.html"
+
+// PublishTask mirrors a directory tree into another directory, e.g. one on a storage which backs a static site,
+// and writes an index.html with relative links into every directory which has none
+type PublishTask struct {
+	task.TaskExtension
+	SrcPath   string `json:"src_path"`
+	DstPath   string `json:"dst_path"`
+	Status    string `json:"-"`
+	Published int    `json:"-"`
+	Skipped   int    `json:"-"`
+	// the tree is published as the guest sees it, without the hidden and password protected content
+	guest *model.User
+}
+
+func (t *PublishTask) GetName() string {
+	return fmt.Sprintf("publish [%s] to [%s]", t.SrcPath, t.DstPath)
+}
+
+func (t *PublishTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *PublishTask) Run() error {
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	t.Published, t.Skipped = 0, 0
+	guest, err := op.GetGuest()
+	if err != nil {
+		return err
+	}
+	t.guest = guest
+	meta, err := op.GetNearestMeta(t.SrcPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return err
+	}
+	if !common.CanAccess(guest, meta, t.SrcPath, "") {
+		return errors.Errorf("[%s] can't be accessed by the guest", t.SrcPath)
+	}
+	if err := t.publishDir(t.SrcPath, t.DstPath); err != nil {
+		return err
+	}
+	t.Status = fmt.Sprintf("published %d files, skipped %d unchanged files", t.Published, t.Skipped)
+	return nil
+}
+
+func (t *PublishTask) publishDir(srcDirPath, dstDirPath string) error {
+	if err := t.Ctx().Err(); err != nil {
+		return err
+	}
+	t.Status = "publishing " + srcDirPath
+	meta, err := op.GetNearestMeta(srcDirPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return err
+	}
+	listCtx := context.WithValue(context.WithValue(t.Ctx(), conf.UserKey, t.guest), conf.MetaKey, meta)
+	objs, err := List(listCtx, srcDirPath, &ListArgs{NoLog: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", srcDirPath)
+	}
+	if err = makeDir(t.Ctx(), dstDirPath); err != nil {
+		return errors.WithMessagef(err, "failed make dir [%s]", dstDirPath)
+	}
+	existing := make(map[string]model.Obj)
+	dstObjs, err := List(t.Ctx(), dstDirPath, &ListArgs{NoLog: true, Refresh: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", dstDirPath)
+	}
+	for _, obj := range dstObjs {
+		existing[obj.GetName()] = obj
+	}
+
+	hasIndex := false
+	index := &model.Index{Path: srcDirPath, Generated: time.Now()}
+	for _, obj := range objs {
+		srcObjPath := stdpath.Join(srcDirPath, obj.GetName())
+		dstObjPath := stdpath.Join(dstDirPath, obj.GetName())
+		node := &model.IndexNode{
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			IsDir:    obj.IsDir(),
+			Modified: obj.ModTime(),
+			URL:      utils.EncodePath(obj.GetName(), true),
+		}
+		if obj.IsDir() {
+			objMeta, err := op.GetNearestMeta(srcObjPath)
+			if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+				return err
+			}
+			// the password protected and hidden directories are not published
+			if !common.CanAccess(t.guest, objMeta, srcObjPath, "") {
+				continue
+			}
+			if err := t.publishDir(srcObjPath, dstObjPath); err != nil {
+				return err
+			}
+			node.URL += "/"
+		} else {
+			if obj.GetName() == publishIndexName {
+				hasIndex = true
+			}
+			if err := t.publishFile(srcObjPath, dstDirPath, obj, existing[obj.GetName()]); err != nil {
+				return err
+			}
+		}
+		index.Content = append(index.Content, node)
+	}
+	if hasIndex {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := indexHTML.Execute(&buf, index); err != nil {
+		return errors.WithStack(err)
+	}
+	return putIndexFile(t.Ctx(), dstDirPath, publishIndexName, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// publishFile copies the file unless the existing dst file has the same size and is not older
+func (t *PublishTask) publishFile(srcPath, dstDirPath string, srcObj, dstObj model.Obj) error {
+	if dstObj != nil && !dstObj.IsDir() && dstObj.GetSize() == srcObj.GetSize() && !dstObj.ModTime().Before(srcObj.ModTime()) {
+		t.Skipped++
+		return nil
+	}
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	link, obj, err := op.Link(t.Ctx(), srcStorage, srcActualPath, model.LinkArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", srcPath)
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{
+		Obj: obj,
+		Ctx: t.Ctx(),
+	}, link)
+	if err != nil {
+		_ = link.Close()
+		return errors.WithMessagef(err, "failed get [%s] stream", srcPath)
+	}
+	ctx := context.WithValue(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), conf.ServerPutKey, struct{}{})
+	if err := op.Put(ctx, dstStorage, dstDirActualPath, ss, nil); err != nil {
+		return errors.WithMessagef(err, "failed publish [%s]", srcPath)
+	}
+	t.Published++
+	return nil
+}
+
+var PublishTaskManager *tache.Manager[*PublishTask]
+
+// Publish adds a task which mirrors srcPath into dstPath
+func Publish(ctx context.Context, srcPath, dstPath string) (task.TaskExtensionInfo, error) {
+	srcPath, dstPath = utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath)
+	if utils.IsSubPath(srcPath, dstPath) || utils.IsSubPath(dstPath, srcPath) {
+		return nil, errors.New("the source and the destination can't contain each other")
+	}
+	dstStorage, _, err := op.GetStorageAndActualPath(dstPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	if dstStorage.Config().NoUpload {
+		return nil, errors.WithStack(errs.UploadNotSupported)
+	}
+	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User)
+	t := &PublishTask{
+		TaskExtension: task.TaskExtension{
+			Creator:   taskCreator,
+			ApiUrl:    common.GetApiUrl(ctx),
+			RequestID: net.RequestID(ctx),
+		},
+		SrcPath: srcPath,
+		DstPath: dstPath,
+	}
+	PublishTaskManager.Add(t)
+	return t, nil
+}
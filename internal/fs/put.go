@@ -3,6 +3,7 @@ package fs
 import (
 	"context"
 	"fmt"
+	"net/http"
 	stdpath "path"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"github.com/OpenListTeam/OpenList/v4/internal/task_group"
@@ -20,45 +22,90 @@ import (
 	"github.com/pkg/errors"
 )
 
+// UploadTask is persisted without its file, so the restored unfinished uploads fail as interrupted,
+// except the uploads by url which fetch the url again
 type UploadTask struct {
 	task.TaskExtension
-	storage          driver.Driver
-	dstDirActualPath string
-	file             model.FileStreamer
+	StorageMp        string `json:"storage_mp"`
+	DstDirActualPath string `json:"dst_dir_actual_path"`
+	FileName         string `json:"file_name"`
+	// URL is fetched as the file named FileName when the task runs, for the uploads by url
+	URL string `json:"url,omitempty"`
+	// Header is sent with the request of the URL
+	Header  http.Header `json:"header,omitempty"`
+	storage driver.Driver
+	file    model.FileStreamer
 }
 
 func (t *UploadTask) GetName() string {
-	return fmt.Sprintf("upload %s to [%s](%s)", t.file.GetName(), t.storage.GetStorage().MountPath, t.dstDirActualPath)
+	return fmt.Sprintf("upload %s to [%s](%s)", t.fileName(), t.StorageMp, t.DstDirActualPath)
 }
 
 func (t *UploadTask) GetStatus() string {
 	return "uploading"
 }
 
+func (t *UploadTask) UsesStorage(mountPath string) bool {
+	return t.StorageMp == mountPath
+}
+
 func (t *UploadTask) Run() error {
+	if t.file == nil && t.URL == "" {
+		return tache.Unrecoverable(errors.New("the upload is interrupted by a restart, upload the file again"))
+	}
+	t.SetSpeedLimitType("upload")
+	if waited, err := op.WaitStorage(t.Ctx(), t.StorageMp); err != nil {
+		return err
+	} else if waited || t.storage == nil {
+		if t.storage, err = op.GetStorageByMountPath(t.StorageMp); err != nil {
+			return err
+		}
+	}
+	if err := t.WaitResume(); err != nil {
+		return err
+	}
+	release, err := t.WaitSlot(UploadTaskSlots)
+	if err != nil {
+		return err
+	}
+	defer release()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
-	return op.Put(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), t.storage, t.dstDirActualPath, t.file, t.SetProgress)
+	file := t.file
+	ctx := context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{})
+	if t.URL != "" {
+		if file, err = t.openURL(); err != nil {
+			return err
+		}
+		ctx = context.WithValue(ctx, conf.ServerPutKey, struct{}{})
+	}
+	return op.Put(ctx, t.storage, t.DstDirActualPath, file, t.PausableProgress(t.SetProgress, func() <-chan struct{} {
+		return op.StoragePaused(t.StorageMp)
+	}))
 }
 
 func (t *UploadTask) OnSucceeded() {
-	task_group.TransferCoordinator.Done(context.WithoutCancel(t.Ctx()), stdpath.Join(t.storage.GetStorage().MountPath, t.dstDirActualPath), true)
+	task_group.TransferCoordinator.Done(context.WithoutCancel(t.Ctx()), stdpath.Join(t.StorageMp, t.DstDirActualPath), true)
 }
 
 func (t *UploadTask) OnFailed() {
-	task_group.TransferCoordinator.Done(context.WithoutCancel(t.Ctx()), stdpath.Join(t.storage.GetStorage().MountPath, t.dstDirActualPath), false)
+	task_group.TransferCoordinator.Done(context.WithoutCancel(t.Ctx()), stdpath.Join(t.StorageMp, t.DstDirActualPath), false)
 }
 
 func (t *UploadTask) SetRetry(retry int, maxRetry int) {
 	t.TaskExtension.SetRetry(retry, maxRetry)
 	if retry == 0 &&
-		(t.GetErr() == nil && t.GetState() != tache.StatePending) { // 手动重试
-		task_group.TransferCoordinator.AddTask(stdpath.Join(t.storage.GetStorage().MountPath, t.dstDirActualPath), nil)
+		(t.storage == nil || // 重启恢复
+			(t.GetErr() == nil && t.GetState() != tache.StatePending)) { // 手动重试
+		task_group.TransferCoordinator.AddTask(stdpath.Join(t.StorageMp, t.DstDirActualPath), nil)
 	}
 }
 
-var UploadTaskManager *tache.Manager[*UploadTask]
+var (
+	UploadTaskManager *tache.Manager[*UploadTask]
+	UploadTaskSlots   *task.Slots
+)
 
 // putAsTask add as a put task and return immediately
 func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskExtensionInfo, error) {
@@ -69,6 +116,9 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 	if storage.Config().NoUpload {
 		return nil, errors.WithStack(errs.UploadNotSupported)
 	}
+	if err := checkQuota(ctx, dstDirPath, file.GetSize()); err != nil {
+		return nil, err
+	}
 	if file.NeedStore() {
 		_, err := file.CacheFullAndWriter(nil, nil)
 		if err != nil {
@@ -80,11 +130,16 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User) // taskCreator is nil when convert failed
 	t := &UploadTask{
 		TaskExtension: task.TaskExtension{
-			Creator: taskCreator,
-			ApiUrl:  common.GetApiUrl(ctx),
+			Creator:    taskCreator,
+			ApiUrl:     common.GetApiUrl(ctx),
+			RequestID:  net.RequestID(ctx),
+			Priority:   task.CtxPriority(ctx),
+			SpeedLimit: task.CtxSpeedLimit(ctx),
 		},
+		StorageMp:        storage.GetStorage().MountPath,
+		DstDirActualPath: dstDirActualPath,
+		FileName:         file.GetName(),
 		storage:          storage,
-		dstDirActualPath: dstDirActualPath,
 		file:             file,
 	}
 	t.SetTotalBytes(file.GetSize())
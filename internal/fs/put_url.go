@@ -0,0 +1,218 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	stdnet "net"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/task_group"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/pkg/errors"
+)
+
+// the uploads by url fetch the file straight into the storage when the upload task runs,
+// unlike the offline downloads they are limited to the files of the allowed size and content types
+
+var putURLClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&stdnet.Dialer{
+			Timeout: 30 * time.Second,
+			Control: checkPutURLAddr,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	},
+}
+
+// checkPutURLAddr refuses the local addresses unless they are allowed, it's checked when dialing
+// so that the redirects and the hosts resolving to them are refused too
+func checkPutURLAddr(network, address string, _ syscall.RawConn) error {
+	if setting.GetBool(conf.PutURLAllowLocal) {
+		return nil
+	}
+	host, _, err := stdnet.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := stdnet.ParseIP(host)
+	if ip == nil || utils.IsLocalIP(ip) || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return errors.Errorf("the local address %s is not allowed", host)
+	}
+	return nil
+}
+
+// checkPutURLContentType reports whether the content type is one of the put_url_content_types,
+// which are mime types or prefixes of them ending with a slash
+func checkPutURLContentType(contentType string) error {
+	allowed := setting.GetStr(conf.PutURLContentTypes)
+	if strings.TrimSpace(allowed) == "" {
+		return nil
+	}
+	mimetype, _, _ := mime.ParseMediaType(contentType)
+	for _, t := range strings.Split(allowed, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if mimetype == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mimetype, t)) {
+			return nil
+		}
+	}
+	return errors.Errorf("content type [%s] is not allowed", contentType)
+}
+
+// sizeLimitReader fails once more than n bytes are read, for the responses without their length
+type sizeLimitReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n -= int64(n)
+	if r.n < 0 {
+		return n, errors.New("the file exceeds the max size")
+	}
+	return n, err
+}
+
+// urlSize returns the size of the remote file by a HEAD request, it's -1 if the server doesn't report it
+func urlSize(ctx context.Context, u string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	req.Header.Set("User-Agent", base.UserAgent)
+	res, err := putURLClient.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed head url")
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, errors.Errorf("failed head url: %s", res.Status)
+	}
+	return res.ContentLength, nil
+}
+
+// fetchURL opens the remote file as the stream to put
+func fetchURL(ctx context.Context, u, name string, header http.Header) (model.FileStreamer, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("User-Agent", base.UserAgent)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := putURLClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed fetch url")
+	}
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, errors.Errorf("failed fetch url: %s", res.Status)
+	}
+	contentType := res.Header.Get("Content-Type")
+	if err = checkPutURLContentType(contentType); err != nil {
+		_ = res.Body.Close()
+		return nil, err
+	}
+	var body io.Reader = res.Body
+	if maxSize := int64(setting.GetInt(conf.PutURLMaxSize, 0)) << 20; maxSize > 0 {
+		if res.ContentLength > maxSize {
+			_ = res.Body.Close()
+			return nil, errors.Errorf("the file of %d bytes exceeds the max size of %d bytes", res.ContentLength, maxSize)
+		}
+		body = &sizeLimitReader{Reader: body, n: maxSize}
+	}
+	if contentType == "" {
+		contentType = utils.GetMimeType(name)
+	}
+	modified := time.Now()
+	if t, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		modified = t
+	}
+	return &stream.FileStream{
+		Ctx: ctx,
+		Obj: &model.Object{
+			Name:     name,
+			Size:     res.ContentLength,
+			Modified: modified,
+		},
+		Reader:       body,
+		Mimetype:     contentType,
+		WebPutAsTask: true,
+		Closers:      utils.Closers{res.Body},
+	}, nil
+}
+
+// putURLAsTask adds the upload task fetching the url into dstDirPath as name
+func putURLAsTask(ctx context.Context, dstDirPath, name, u string, header http.Header) (task.TaskExtensionInfo, error) {
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	if storage.Config().NoUpload {
+		return nil, errors.WithStack(errs.UploadNotSupported)
+	}
+	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User)
+	t := &UploadTask{
+		TaskExtension: task.TaskExtension{
+			Creator:    taskCreator,
+			ApiUrl:     common.GetApiUrl(ctx),
+			RequestID:  net.RequestID(ctx),
+			Priority:   task.CtxPriority(ctx),
+			SpeedLimit: task.CtxSpeedLimit(ctx),
+		},
+		StorageMp:        storage.GetStorage().MountPath,
+		DstDirActualPath: dstDirActualPath,
+		FileName:         name,
+		URL:              u,
+		Header:           header,
+		storage:          storage,
+	}
+	task_group.TransferCoordinator.AddTask(stdpath.Join(storage.GetStorage().MountPath, dstDirActualPath), nil)
+	UploadTaskManager.Add(t)
+	return t, nil
+}
+
+// openURL fetches the url of the task
+func (t *UploadTask) openURL() (model.FileStreamer, error) {
+	file, err := fetchURL(t.Ctx(), t.URL, t.FileName, t.Header)
+	if err != nil {
+		return nil, err
+	}
+	t.SetTotalBytes(max(file.GetSize(), 0))
+	return file, nil
+}
+
+func (t *UploadTask) fileName() string {
+	if t.URL != "" {
+		return fmt.Sprintf("%s from %s", t.FileName, t.URL)
+	}
+	return t.FileName
+}
@@ -0,0 +1,55 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	_ "github.com/OpenListTeam/OpenList/v4/drivers/url_tree"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+func TestPutURLQuota(t *testing.T) {
+	err := op.SaveSettingItem(&model.SettingItem{Key: conf.PutURLAllowLocal, Value: "true", Type: conf.TypeBool, Group: model.GLOBAL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = op.SaveSettingItem(&model.SettingItem{Key: conf.PutURLAllowLocal, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL})
+	}()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", r.URL.Query().Get("size"))
+	}))
+	defer server.Close()
+	_, err = op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "UrlTree",
+		MountPath: "/put_url_quota",
+		Addition:  `{"url_structure":"a.txt:100:http://example.com/a.txt","writable":true}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+
+	user := &model.User{BasePath: "/", Quota: 150, QuotaPath: "/put_url_quota"}
+	ctx := context.WithValue(context.Background(), conf.UserKey, user)
+	putURL := func(name string, size int) error {
+		return fs.PutURL(ctx, "/put_url_quota", name, server.URL+"/"+name+"?size="+strconv.Itoa(size))
+	}
+	if err = putURL("b.txt", 100); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("expected the quota to be exceeded, got %v", err)
+	}
+	if err = putURL("c.txt", 40); err != nil {
+		t.Errorf("failed to put the url within the quota: %+v", err)
+	}
+	// the bytes of c.txt are reserved
+	if err = putURL("d.txt", 40); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("expected the quota to be exceeded after the reserved bytes, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package fs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/go-cache"
+	"github.com/pkg/errors"
+)
+
+// the usage of a quota path is counted by walking it, so it's cached for a while
+// and the accepted uploads are added to it until it's counted again
+const quotaUsageExpire = time.Minute
+
+var (
+	quotaUsageCache = cache.NewMemCache[int64]()
+	quotaUsageMu    sync.Mutex
+)
+
+func quotaPath(user *model.User) (string, error) {
+	return user.JoinPath(user.QuotaPath)
+}
+
+func countQuotaUsage(ctx context.Context, path string) (int64, error) {
+	if v, ok := quotaUsageCache.Get(path); ok {
+		return v, nil
+	}
+	stat, err := StatRemove(ctx, path, 0, 0)
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	quotaUsageCache.Set(path, stat.Size, cache.WithEx[int64](quotaUsageExpire))
+	return stat.Size, nil
+}
+
+// QuotaUsage returns the bytes stored under the quota path of the user
+func QuotaUsage(ctx context.Context, user *model.User) (int64, error) {
+	path, err := quotaPath(user)
+	if err != nil {
+		return 0, err
+	}
+	quotaUsageMu.Lock()
+	defer quotaUsageMu.Unlock()
+	return countQuotaUsage(ctx, path)
+}
+
+// checkQuota fails with errs.QuotaExceeded if putting size bytes in dstDirPath exceeds the quota of the user of ctx,
+// it rejects the uploads as tasks early, their bytes are reserved by the put
+func checkQuota(ctx context.Context, dstDirPath string, size int64) error {
+	return quota(ctx, dstDirPath, size, false)
+}
+
+// reserveQuota is the op.QuotaFunc, the accepted bytes are added to the usage
+func reserveQuota(ctx context.Context, dstDirPath string, size int64) error {
+	return quota(ctx, dstDirPath, size, true)
+}
+
+func quota(ctx context.Context, dstDirPath string, size int64, reserve bool) error {
+	user, ok := ctx.Value(conf.UserKey).(*model.User)
+	if !ok || user == nil || user.Quota <= 0 {
+		return nil
+	}
+	path, err := quotaPath(user)
+	if err != nil {
+		return err
+	}
+	if !utils.IsSubPath(path, dstDirPath) {
+		return nil
+	}
+	quotaUsageMu.Lock()
+	defer quotaUsageMu.Unlock()
+	used, err := countQuotaUsage(ctx, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed count quota usage")
+	}
+	if size > 0 && used+size > user.Quota {
+		return errors.WithStack(errs.QuotaExceeded)
+	}
+	if reserve {
+		quotaUsageCache.Set(path, max(used+size, 0), cache.WithEx[int64](quotaUsageExpire))
+	}
+	return nil
+}
+
+// quotaApplies reports whether the writes to dstDirPath are limited by the quota of the user of ctx
+func quotaApplies(ctx context.Context, dstDirPath string) bool {
+	user, ok := ctx.Value(conf.UserKey).(*model.User)
+	if !ok || user == nil || user.Quota <= 0 {
+		return false
+	}
+	path, err := quotaPath(user)
+	return err != nil || utils.IsSubPath(path, dstDirPath)
+}
+
+func init() {
+	op.RegisterQuotaFunc(reserveQuota)
+}
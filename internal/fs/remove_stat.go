@@ -0,0 +1,52 @@
+package fs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+// RemoveStat is what would be removed with a path
+type RemoveStat struct {
+	Path  string `json:"path"`
+	Files int64  `json:"files"`
+	Dirs  int64  `json:"dirs"`
+	Size  int64  `json:"size"`
+	// Exceeded is set if the counting stopped at the limits
+	Exceeded bool `json:"exceeded"`
+}
+
+var errRemoveStatExceeded = errors.New("remove stat exceeded")
+
+// StatRemove counts the files and the bytes under path. The counting stops once the files exceed maxFiles
+// or the bytes exceed maxSize, if they are positive
+func StatRemove(ctx context.Context, path string, maxFiles, maxSize int64) (*RemoveStat, error) {
+	obj, err := Get(ctx, path, &GetArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	stat := &RemoveStat{Path: path}
+	err = WalkFS(ctx, -1, path, obj, func(reqPath string, info model.Obj) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if reqPath != path {
+				stat.Dirs++
+			}
+			return nil
+		}
+		stat.Files++
+		stat.Size += info.GetSize()
+		if (maxFiles > 0 && stat.Files > maxFiles) || (maxSize > 0 && stat.Size > maxSize) {
+			return errRemoveStatExceeded
+		}
+		return nil
+	})
+	if errors.Is(err, errRemoveStatExceeded) {
+		stat.Exceeded = true
+		return stat, nil
+	}
+	return stat, err
+}
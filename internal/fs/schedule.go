@@ -0,0 +1,130 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/tache"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// scheduleTasks are the ids of the tasks added by the latest run of each schedule,
+// so that a run isn't started while the previous one is not done
+var scheduleTasks sync.Map
+
+func scheduleQueued(id uint) bool {
+	v, ok := scheduleTasks.Load(id)
+	if !ok {
+		return false
+	}
+	for _, tid := range v.([]string) {
+		for _, m := range []*tache.Manager[*FileTransferTask]{CopyTaskManager, MoveTaskManager} {
+			if t, ok := m.GetByID(tid); ok {
+				switch t.GetState() {
+				case tache.StateSucceeded, tache.StateFailed, tache.StateCanceled:
+				default:
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// RunSchedule adds the transfer tasks of the schedule, the objects are skipped, overwritten or merged
+// into the existing ones by the policy of the schedule
+func RunSchedule(ctx context.Context, s *model.Schedule) ([]task.TaskExtensionInfo, error) {
+	var user *model.User
+	var err error
+	if s.UserId == 0 {
+		user, err = op.GetAdmin()
+	} else {
+		user, err = op.GetUserById(s.UserId)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get the user of the schedule")
+	}
+	ctx = context.WithValue(ctx, conf.UserKey, user)
+	names := s.Names
+	if len(names) == 0 {
+		objs, err := List(ctx, s.SrcDir, &ListArgs{NoLog: true, Refresh: true})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed list [%s]", s.SrcDir)
+		}
+		for _, obj := range objs {
+			names = append(names, obj.GetName())
+		}
+	}
+	var tasks []task.TaskExtensionInfo
+	var errs []string
+	for i, name := range names {
+		srcPath := stdpath.Join(s.SrcDir, name)
+		skipHook := len(names) > i+1
+		var t task.TaskExtensionInfo
+		dst, _ := Get(ctx, stdpath.Join(s.DstDir, name), &GetArgs{NoLog: true})
+		switch {
+		case dst == nil || s.Policy == model.ScheduleOverwrite:
+			if s.Op == model.ScheduleMove {
+				t, err = Move(ctx, srcPath, s.DstDir, skipHook)
+			} else {
+				t, err = Copy(ctx, srcPath, s.DstDir, skipHook)
+			}
+		case s.Policy == model.ScheduleMerge && dst.IsDir():
+			t, err = Merge(ctx, srcPath, s.DstDir, skipHook)
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+		if t != nil {
+			tasks = append(tasks, t)
+		}
+	}
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.GetID())
+	}
+	scheduleTasks.Store(s.ID, ids)
+	if len(errs) > 0 {
+		return tasks, errors.New(strings.Join(errs, "\n"))
+	}
+	return tasks, nil
+}
+
+// RunDueSchedules adds the tasks of the schedules which are due
+func RunDueSchedules() {
+	schedules, err := op.GetSchedules()
+	if err != nil {
+		log.Errorf("failed get schedules: %+v", err)
+		return
+	}
+	now := time.Now()
+	for i := range schedules {
+		s := &schedules[i]
+		if !s.Due(now) {
+			continue
+		}
+		s.LastRun = &now
+		if scheduleQueued(s.ID) {
+			s.LastError = "skipped as the tasks of the previous run are not done"
+		} else if _, err := RunSchedule(context.Background(), s); err != nil {
+			log.Errorf("failed run schedule %d: %+v", s.ID, err)
+			s.LastError = err.Error()
+		} else {
+			s.LastError = ""
+		}
+		if err := op.SaveScheduleResult(s); err != nil {
+			log.Errorf("failed save schedule: %+v", err)
+		}
+	}
+}
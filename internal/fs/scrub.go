@@ -0,0 +1,158 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/tache"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ScrubTask re-verifies the recorded files which are scrubbed the longest time ago
+type ScrubTask struct {
+	task.TaskExtension
+	Limit  int    `json:"limit"`
+	Status string `json:"-"`
+}
+
+func (t *ScrubTask) GetName() string {
+	return fmt.Sprintf("scrub %d files", t.Limit)
+}
+
+func (t *ScrubTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *ScrubTask) Run() error {
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	files, err := op.GetScrubFilesToVerify(t.Limit)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for i := range files {
+		if err := t.Ctx().Err(); err != nil {
+			return err
+		}
+		f := &files[i]
+		t.Status = "verifying " + f.Path
+		err := scrubFile(t.Ctx(), f)
+		if err != nil && t.Ctx().Err() != nil {
+			return t.Ctx().Err()
+		}
+		now := time.Now()
+		f.LastScrub = &now
+		f.Error = ""
+		if err != nil {
+			failed++
+			f.Error = err.Error()
+			log.Errorf("scrub [%s] failed: %+v", f.Path, err)
+		}
+		if err := op.SaveScrubResult(f); err != nil {
+			return err
+		}
+		t.SetProgress(float64(i+1) * 100 / float64(len(files)))
+	}
+	t.Status = fmt.Sprintf("verified %d files, %d failed", len(files), failed)
+	if failed > 0 {
+		return errors.Errorf("%d of %d files failed to verify", failed, len(files))
+	}
+	return nil
+}
+
+// scrubHashTypes are the hashes which can be computed from the content alone
+var scrubHashTypes = []*utils.HashType{utils.SHA256, utils.SHA1, utils.MD5}
+
+// scrubFile compares the file with the recorded size and hashes, the content is read and hashed
+// so that the corruptions which the storage doesn't know about are found too
+func scrubFile(ctx context.Context, f *model.ScrubFile) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(f.Path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get file")
+	}
+	if obj.GetSize() != f.Size {
+		return errs.NewErr(errs.VerifyFailed, "size mismatch: %d != %d", obj.GetSize(), f.Size)
+	}
+	expected := utils.FromString(f.Hash)
+	if err := verifyTransfer(&model.Object{Size: f.Size, HashInfo: expected}, obj); err != nil {
+		return err
+	}
+	for _, ht := range scrubHashTypes {
+		sum := expected.GetHash(ht)
+		if sum == "" {
+			continue
+		}
+		link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{})
+		if err != nil {
+			return errors.WithMessage(err, "failed get link")
+		}
+		ss, err := stream.NewSeekableStream(&stream.FileStream{Obj: obj, Ctx: ctx}, link)
+		if err != nil {
+			_ = link.Close()
+			return errors.WithMessage(err, "failed get stream")
+		}
+		defer ss.Close()
+		actual, err := utils.HashReader(ht, ss)
+		if err != nil {
+			return errors.WithMessage(err, "failed read content")
+		}
+		if !strings.EqualFold(actual, sum) {
+			return errs.NewErr(errs.VerifyFailed, "%s of the content mismatch: %s != %s", ht.Name, actual, sum)
+		}
+		return nil
+	}
+	return nil
+}
+
+var ScrubTaskManager *tache.Manager[*ScrubTask]
+
+// Scrub adds a task which re-verifies limit files
+func Scrub(ctx context.Context, limit int) (task.TaskExtensionInfo, error) {
+	creator, _ := ctx.Value(conf.UserKey).(*model.User)
+	if creator == nil {
+		admin, err := op.GetAdmin()
+		if err != nil {
+			return nil, err
+		}
+		creator = admin
+	}
+	t := &ScrubTask{
+		TaskExtension: task.TaskExtension{
+			Creator: creator,
+		},
+		Limit: limit,
+	}
+	ScrubTaskManager.Add(t)
+	return t, nil
+}
+
+// RunScheduledScrub adds the hourly scrub task unless it's disabled or the last one is still running
+func RunScheduledScrub() {
+	limit := setting.GetInt(conf.TaskScrubFilesPerHour, 0)
+	if limit <= 0 {
+		return
+	}
+	if len(ScrubTaskManager.GetByState(tache.StatePending, tache.StateRunning)) > 0 {
+		return
+	}
+	if _, err := Scrub(context.Background(), limit); err != nil {
+		log.Errorf("failed add scrub task: %+v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package fs
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/tache"
+)
+
+type StorageTask interface {
+	tache.TaskWithInfo
+	UsesStorage(mountPath string) bool
+}
+
+// ActiveStorageTasks returns the names of the unfinished tasks of tasks using the storage mounted at mountPath
+func ActiveStorageTasks[T StorageTask](tasks []T, mountPath string) []string {
+	var names []string
+	for _, t := range tasks {
+		switch t.GetState() {
+		case tache.StateSucceeded, tache.StateCanceled, tache.StateFailed:
+			continue
+		}
+		if t.UsesStorage(mountPath) {
+			names = append(names, t.GetName())
+		}
+	}
+	return names
+}
+
+// WaitStorages blocks while the storages of the task are paused,
+// the drivers are looked up again after they are resumed since they may be reloaded
+func (t *TaskData) WaitStorages() error {
+	for _, src := range []bool{true, false} {
+		mountPath := t.DstStorageMp
+		if src {
+			mountPath = t.SrcStorageMp
+		}
+		if mountPath == "" {
+			continue
+		}
+		waited, err := op.WaitStorage(t.Ctx(), mountPath)
+		if err != nil {
+			return err
+		}
+		if !waited {
+			continue
+		}
+		storage, err := op.GetStorageByMountPath(mountPath)
+		if err != nil {
+			return err
+		}
+		if src {
+			t.SrcStorage = storage
+		} else {
+			t.DstStorage = storage
+		}
+	}
+	return nil
+}
+
+// StoragesPaused returns the channel closed when the paused storage of the task is resumed,
+// nil if none of its storages is paused. A transfer held by it goes on with the driver it started with
+func (t *TaskData) StoragesPaused() <-chan struct{} {
+	for _, mountPath := range []string{t.SrcStorageMp, t.DstStorageMp} {
+		if mountPath == "" {
+			continue
+		}
+		if ch := op.StoragePaused(mountPath); ch != nil {
+			return ch
+		}
+	}
+	return nil
+}
+
+func init() {
+	op.RegisterStorageTasksFunc(func(mountPath string) []string {
+		names := ActiveStorageTasks(CopyTaskManager.GetAll(), mountPath)
+		names = append(names, ActiveStorageTasks(MoveTaskManager.GetAll(), mountPath)...)
+		names = append(names, ActiveStorageTasks(UploadTaskManager.GetAll(), mountPath)...)
+		names = append(names, ActiveStorageTasks(ArchiveDownloadTaskManager.GetAll(), mountPath)...)
+		return append(names, ActiveStorageTasks(ArchiveContentUploadTaskManager.GetAll(), mountPath)...)
+	})
+}
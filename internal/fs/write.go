@@ -0,0 +1,70 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	stdpath "path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the incremental writes update a file of a storage implementing driver.AppendPut or driver.WriteRange in place,
+// a missing file is created by the first write
+
+// AppendPut appends the data of size to the file at path, the data is put as the file if it doesn't exist
+func AppendPut(ctx context.Context, path string, data io.Reader, size int64) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if _, ok := storage.(driver.AppendPut); !ok {
+		return errors.WithStack(errs.NotSupport)
+	}
+	if _, err = op.Get(ctx, storage, actualPath); errs.IsObjectNotFound(err) {
+		return createFile(ctx, path, data, size)
+	} else if err != nil {
+		return errors.WithMessage(err, "failed get file")
+	}
+	return op.AppendPut(ctx, storage, actualPath, data, size)
+}
+
+// WriteRange writes the data of size at offset of the file at path, an empty file is created first if it doesn't exist
+func WriteRange(ctx context.Context, path string, offset int64, data io.Reader, size int64) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if _, ok := storage.(driver.WriteRange); !ok {
+		return errors.WithStack(errs.NotSupport)
+	}
+	if _, err = op.Get(ctx, storage, actualPath); errs.IsObjectNotFound(err) {
+		if err = createFile(ctx, path, bytes.NewReader(nil), 0); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return errors.WithMessage(err, "failed get file")
+	}
+	return op.WriteRange(ctx, storage, actualPath, offset, data, size)
+}
+
+// createFile puts the data of size as the file at path
+func createFile(ctx context.Context, path string, data io.Reader, size int64) error {
+	dir, name := stdpath.Split(path)
+	return putDirectly(ctx, dir, &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     size,
+			Modified: time.Now(),
+		},
+		Reader:   data,
+		Mimetype: utils.GetMimeType(name),
+	})
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// AccessGrant elevates the permissions of a user, or lets the user past the read and write users
+// of the metas under a path, until it expires or is revoked. The grants are kept after they end,
+// as the record of who was granted what
+type AccessGrant struct {
+	ID         uint  `json:"id" gorm:"primaryKey"`
+	UserId     uint  `json:"user_id" gorm:"index"`
+	Permission int32 `json:"permission"` // the permission bits added to the user's
+	// the path the user can read, or write if Write is set, regardless of the read and write users of the metas
+	Path      string     `json:"path" gorm:"type:text"`
+	Write     bool       `json:"write"`
+	Reason    string     `json:"reason"`
+	GrantedBy string     `json:"granted_by"`
+	Created   time.Time  `json:"created"`
+	Expires   time.Time  `json:"expires"`
+	RevokedBy string     `json:"revoked_by"`
+	Revoked   *time.Time `json:"revoked"`
+}
+
+func (g *AccessGrant) Active(now time.Time) bool {
+	return g.Revoked == nil && now.Before(g.Expires)
+}
+
+// covers reports whether the grant lets the user read, or write if write is set, the path
+func (g *AccessGrant) covers(path string, write bool) bool {
+	return g.Path != "" && (g.Write || !write) && utils.IsSubPath(g.Path, path)
+}
+
+// EffectivePermission is the permission of the user with the bits of the active grants
+func (u *User) EffectivePermission() int32 {
+	p := u.Permission
+	now := time.Now()
+	for i := range u.Grants {
+		if u.Grants[i].Active(now) {
+			p |= u.Grants[i].Permission
+		}
+	}
+	return p
+}
+
+// HasPathGrant reports whether an active grant lets the user read, or write if write is set, the path
+func (u *User) HasPathGrant(path string, write bool) bool {
+	now := time.Now()
+	for i := range u.Grants {
+		if u.Grants[i].Active(now) && u.Grants[i].covers(path, write) {
+			return true
+		}
+	}
+	return false
+}
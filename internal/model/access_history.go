@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type AccessHistory struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	UserId   uint      `json:"-" gorm:"index;uniqueIndex:idx_access_histories_user_path"`
+	Path     string    `json:"path" gorm:"type:text;uniqueIndex:idx_access_histories_user_path,length:255"` // full path, including the base path of the user
+	Count    int       `json:"count"`
+	Accessed time.Time `json:"accessed" gorm:"index"`
+}
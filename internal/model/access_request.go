@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+const (
+	AccessRequestPending  = "pending"
+	AccessRequestApproved = "approved"
+	AccessRequestDenied   = "denied"
+)
+
+// AccessRequest is the request of a user to read, or write if Write is set, a path the user is denied,
+// approving it adds the user to the read or write users of the meta of the path
+type AccessRequest struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserId     uint       `json:"user_id" gorm:"index"`
+	Username   string     `json:"username" gorm:"-"`
+	Path       string     `json:"path" gorm:"type:text"` // full path, including the base path of the user
+	Write      bool       `json:"write"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status" gorm:"index"`
+	Created    time.Time  `json:"created"`
+	ReviewedBy string     `json:"reviewed_by"`
+	Reviewed   *time.Time `json:"reviewed"`
+	Comment    string     `json:"comment"` // the reply of the reviewer
+}
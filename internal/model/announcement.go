@@ -0,0 +1,46 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	AnnouncementInfo    = "info"
+	AnnouncementWarning = "warning"
+	AnnouncementDanger  = "danger"
+)
+
+type Announcement struct {
+	ID       uint       `json:"id" gorm:"primaryKey"`
+	Title    string     `json:"title" binding:"required"`
+	Content  string     `json:"content" gorm:"type:text"` // markdown
+	Severity string     `json:"severity"`                 // info, warning, danger
+	StartAt  *time.Time `json:"start_at"`
+	EndAt    *time.Time `json:"end_at"`
+	// Roles limits who can see the announcement, empty means everyone
+	Roles    []int     `json:"roles" gorm:"serializer:json"`
+	Disabled bool      `json:"disabled"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+}
+
+func (a *Announcement) Active(now time.Time) bool {
+	if a.Disabled {
+		return false
+	}
+	if a.StartAt != nil && !a.StartAt.IsZero() && now.Before(*a.StartAt) {
+		return false
+	}
+	if a.EndAt != nil && !a.EndAt.IsZero() && now.After(*a.EndAt) {
+		return false
+	}
+	return true
+}
+
+func (a *Announcement) VisibleTo(user *User) bool {
+	if len(a.Roles) == 0 {
+		return true
+	}
+	return user != nil && slices.Contains(a.Roles, user.Role)
+}
@@ -0,0 +1,16 @@
+package model
+
+const (
+	InvalidateSettings = "settings"
+	InvalidateStorage  = "storage"
+	InvalidateMetas    = "metas" // the metas of Keys as paths
+	InvalidateUsers    = "users" // the users of Keys as usernames
+)
+
+// CacheInvalidation tells the other instances sharing the database to refresh their caches after a change
+type CacheInvalidation struct {
+	Origin string   `json:"origin"` // the instance which made the change
+	Kind   string   `json:"kind"`
+	ID     uint     `json:"id,omitempty"` // the id of the storage
+	Keys   []string `json:"keys,omitempty"`
+}
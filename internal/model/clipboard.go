@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+const (
+	ClipboardCopy = "copy"
+	ClipboardMove = "move"
+)
+
+// Clipboard holds the pending cut or copy of a user,
+// so that it can be pasted from another session or device
+type Clipboard struct {
+	ID       uint      `json:"-" gorm:"primaryKey"`
+	UserId   uint      `json:"-" gorm:"uniqueIndex"`
+	Op       string    `json:"op"`
+	SrcDir   string    `json:"src_dir" gorm:"type:text"` // full path, including the base path of the user
+	Names    []string  `json:"names" gorm:"type:text;serializer:json"`
+	Modified time.Time `json:"modified"`
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DownloadStat is the daily rollup of the downloads of a path by a user,
+// UserId is 0 for anonymous downloads
+type DownloadStat struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Day     string `json:"day" gorm:"index;size:10"` // 2006-01-02
+	Path    string `json:"path" gorm:"type:text"`
+	Storage string `json:"storage"` // mount path of the storage
+	UserId  uint   `json:"user_id"`
+	Count   int64  `json:"count"`
+	Bytes   int64  `json:"bytes"`
+}
+
+type DownloadStatsReq struct {
+	From    string `json:"from" form:"from"` // 2006-01-02, inclusive
+	To      string `json:"to" form:"to"`     // 2006-01-02, inclusive
+	GroupBy string `json:"group_by" form:"group_by"`
+	OrderBy string `json:"order_by" form:"order_by"` // count or bytes
+	Limit   int    `json:"limit" form:"limit"`
+}
+
+type DownloadStatsItem struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+func (r *DownloadStatsReq) Validate() error {
+	switch r.GroupBy {
+	case "":
+		r.GroupBy = "path"
+	case "path", "storage", "user_id", "day":
+	default:
+		return errors.Errorf("invalid group by: %s", r.GroupBy)
+	}
+	switch r.OrderBy {
+	case "":
+		r.OrderBy = "count"
+	case "count", "bytes":
+	default:
+		return errors.Errorf("invalid order by: %s", r.OrderBy)
+	}
+	if r.Limit <= 0 || r.Limit > 1000 {
+		r.Limit = 100
+	}
+	for _, day := range []string{r.From, r.To} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, day); err != nil {
+			return errors.Errorf("invalid day: %s", day)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type Favorite struct {
+	ID      uint      `json:"id" gorm:"primaryKey"`
+	UserId  uint      `json:"-" gorm:"index"`
+	Path    string    `json:"path" gorm:"type:text"` // full path, including the base path of the user
+	Created time.Time `json:"created"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+const (
+	FsEventCreate = "create" // a file uploaded or a directory made
+	FsEventDelete = "delete"
+	FsEventRename = "rename"
+	FsEventMove   = "move"
+	FsEventCopy   = "copy"
+)
+
+// FsEvent is a mutation of the files, the paths are the full paths in OpenList
+type FsEvent struct {
+	Type    string    `json:"type"`
+	Path    string    `json:"path"`
+	DstPath string    `json:"dst_path,omitempty"` // the new path of rename, move and copy
+	IsDir   bool      `json:"is_dir"`
+	Remote  bool      `json:"remote"` // whether the change is made outside OpenList and reported by the driver
+	Time    time.Time `json:"time"`
+}
+
+// Under reports whether the event touches a path under prefix
+func (e *FsEvent) Under(prefix string) bool {
+	return utils.IsSubPath(prefix, e.Path) || (e.DstPath != "" && utils.IsSubPath(prefix, e.DstPath))
+}
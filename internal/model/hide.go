@@ -0,0 +1,66 @@
+package model
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/dlclark/regexp2"
+	"github.com/pkg/errors"
+)
+
+// the protocols which the hide rules can be scoped to
+const (
+	ProtocolWeb    = "web"
+	ProtocolWebdav = "webdav"
+	ProtocolFTP    = "ftp"
+	ProtocolSFTP   = "sftp"
+)
+
+var Protocols = []string{ProtocolWeb, ProtocolWebdav, ProtocolFTP, ProtocolSFTP}
+
+// HideRule is the compiled hide rule of a meta
+type HideRule struct {
+	patterns    []*regexp2.Regexp
+	exemptUsers []uint
+	protocols   []string
+}
+
+// NewHideRule compiles the non-empty lines of hide as regexps,
+// the rule applies to all protocols if protocols is empty
+func NewHideRule(hide string, exemptUsers []uint, protocols []string) (*HideRule, error) {
+	r := &HideRule{exemptUsers: exemptUsers, protocols: protocols}
+	for _, p := range protocols {
+		if !slices.Contains(Protocols, p) {
+			return nil, errors.Errorf("unknown protocol: %s", p)
+		}
+	}
+	for _, line := range strings.Split(hide, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		re, err := regexp2.Compile(line, regexp2.None)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s is illegal", line)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// AppliesTo reports whether the rule hides objs from user accessing via protocol
+func (r *HideRule) AppliesTo(user *User, protocol string) bool {
+	if user == nil || user.CanSeeHides() || slices.Contains(r.exemptUsers, user.ID) {
+		return false
+	}
+	return len(r.protocols) == 0 || slices.Contains(r.protocols, protocol)
+}
+
+// Hides reports whether an obj named name matches any pattern of the rule
+func (r *HideRule) Hides(name string) bool {
+	for _, re := range r.patterns {
+		if isMatch, _ := re.MatchString(name); isMatch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// IndexExport is a static snapshot of the public view of a directory tree,
+// written as index.json (and index.html) into another directory, e.g. one backed by a CDN
+type IndexExport struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Path     string `json:"path" binding:"required"`     // the directory to export
+	DstPath  string `json:"dst_path" binding:"required"` // the directory to write the index files into
+	MaxDepth int    `json:"max_depth"`                   // 0 means unlimited
+	HTML     bool   `json:"html"`                        // also write an index.html
+	// Interval is the minutes between the scheduled exports, 0 means exporting on demand only
+	Interval     int        `json:"interval"`
+	Disabled     bool       `json:"disabled"`
+	LastExported *time.Time `json:"last_exported"`
+	LastError    string     `json:"last_error" gorm:"type:text"`
+}
+
+// Due reports whether the scheduled export should run at now
+func (e *IndexExport) Due(now time.Time) bool {
+	if e.Disabled || e.Interval <= 0 {
+		return false
+	}
+	return e.LastExported == nil || !now.Before(e.LastExported.Add(time.Duration(e.Interval)*time.Minute))
+}
+
+type IndexNode struct {
+	Name     string       `json:"name"`
+	Size     int64        `json:"size"`
+	IsDir    bool         `json:"is_dir"`
+	Modified time.Time    `json:"modified"`
+	URL      string       `json:"url,omitempty"` // the signed download link of a file
+	Children []*IndexNode `json:"children,omitempty"`
+}
+
+type Index struct {
+	Path      string       `json:"path"`
+	Generated time.Time    `json:"generated"`
+	Content   []*IndexNode `json:"content"`
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	IngestMove   = "move"
+	IngestCopy   = "copy"
+	IngestDelete = "delete"
+	IngestNotify = "notify"
+)
+
+// IngestRule sorts the files in a watched scope, e.g. the output of a scanner, by moving or copying them
+// to the destinations rendered from a template, deleting them or notifying a webhook of them.
+// A rule runs on its schedule, on demand, and on the listing updates of the scope if OnEvent is set
+type IngestRule struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Path      string `json:"path" binding:"required"` // the watched directory
+	Recursive bool   `json:"recursive"`               // whether the sub directories are in the scope
+	// the conditions, the zero values are not checked
+	Pattern string `json:"pattern"`  // the glob of the file names
+	Mime    string `json:"mime"`     // the glob of the mime types, e.g. image/*
+	MinSize int64  `json:"min_size"` // in bytes
+	MaxSize int64  `json:"max_size"` // in bytes
+	MinAge  int    `json:"min_age"`  // in minutes since the modified time
+	MaxAge  int    `json:"max_age"`  // in minutes since the modified time
+	// KeepNewest is the number of the newest files matching Pattern and Mime in each directory which are never touched,
+	// e.g. the last versions of the logs or recordings
+	KeepNewest int    `json:"keep_newest"`
+	Action     string `json:"action"` // move, copy, delete or notify
+	// Dst is the text/template of the destination path of a file for move and copy, e.g. /sorted/{{.Year}}/{{.Month}}/{{.Ext}}/{{.FileName}},
+	// the fields are FileName, Name, Ext, Path, Year, Month, Day of the modified time and Now
+	Dst string `json:"dst"`
+	// NotifyURL is the webhook posted with the matched files for notify, or with the handled files for the other actions
+	NotifyURL string `json:"notify_url"`
+	// Interval is the minutes between the scans, 0 means no scheduled scan
+	Interval  int        `json:"interval"`
+	OnEvent   bool       `json:"on_event"`
+	Disabled  bool       `json:"disabled"`
+	LastRun   *time.Time `json:"last_run"`
+	LastError string     `json:"last_error" gorm:"type:text"`
+}
+
+// Due reports whether the scheduled scan should run at now
+func (r *IngestRule) Due(now time.Time) bool {
+	if r.Disabled || r.Interval <= 0 {
+		return false
+	}
+	return r.LastRun == nil || !now.Before(r.LastRun.Add(time.Duration(r.Interval)*time.Minute))
+}
+
+// InScope reports whether the files in the directory dirPath are in the scope of the rule
+func (r *IngestRule) InScope(dirPath string) bool {
+	if dirPath == r.Path {
+		return true
+	}
+	return r.Recursive && strings.HasPrefix(dirPath, strings.TrimSuffix(r.Path, "/")+"/")
+}
+
+// Match reports whether the file obj with the mime type meets the conditions of the rule at now
+func (r *IngestRule) Match(obj Obj, mime string, now time.Time) bool {
+	if obj.IsDir() || !r.MatchName(obj.GetName(), mime) {
+		return false
+	}
+	size := obj.GetSize()
+	if (r.MinSize > 0 && size < r.MinSize) || (r.MaxSize > 0 && size > r.MaxSize) {
+		return false
+	}
+	age := now.Sub(obj.ModTime())
+	if (r.MinAge > 0 && age < time.Duration(r.MinAge)*time.Minute) || (r.MaxAge > 0 && age > time.Duration(r.MaxAge)*time.Minute) {
+		return false
+	}
+	return true
+}
+
+// MatchName reports whether the name and the mime type meet the conditions of the rule
+func (r *IngestRule) MatchName(name, mime string) bool {
+	if ok, _ := path.Match(r.Pattern, name); r.Pattern != "" && !ok {
+		return false
+	}
+	if ok, _ := path.Match(r.Mime, mime); r.Mime != "" && !ok {
+		return false
+	}
+	return true
+}
+
+// IngestFile is the template data of the destination of a file
+type IngestFile struct {
+	FileName string // the name with the extension
+	Name     string // the name without the extension
+	Ext      string // the extension without the dot, lower cased
+	Path     string // the directory relative to the watched directory
+	Year     string
+	Month    string
+	Day      string
+	Now      time.Time
+}
@@ -1,20 +1,34 @@
 package model
 
 type Meta struct {
-	ID            uint   `json:"id" gorm:"primaryKey"`
-	Path          string `json:"path" gorm:"unique" binding:"required"`
-	ReadUsers     []uint `json:"read_users" gorm:"serializer:json"`
-	ReadUsersSub  bool   `json:"read_users_sub"`
-	WriteUsers    []uint `json:"write_users" gorm:"serializer:json"`
-	WriteUsersSub bool   `json:"write_users_sub"`
-	Password      string `json:"password"`
-	PSub          bool   `json:"p_sub"`
-	Write         bool   `json:"write"`
-	WSub          bool   `json:"w_sub"`
-	Hide          string `json:"hide"`
-	HSub          bool   `json:"h_sub"`
-	Readme        string `json:"readme"`
-	RSub          bool   `json:"r_sub"`
-	Header        string `json:"header"`
-	HeaderSub     bool   `json:"header_sub"`
+	ID            uint     `json:"id" gorm:"primaryKey"`
+	Path          string   `json:"path" gorm:"unique" binding:"required"`
+	ReadUsers     []uint   `json:"read_users" gorm:"serializer:json"`
+	ReadUsersSub  bool     `json:"read_users_sub"`
+	WriteUsers    []uint   `json:"write_users" gorm:"serializer:json"`
+	WriteUsersSub bool     `json:"write_users_sub"`
+	Password      string   `json:"password"`
+	PSub          bool     `json:"p_sub"`
+	PInherit      bool     `json:"p_inherit"` // inherit the password of the parent metas if the password is empty
+	PExemptUsers  []uint   `json:"p_exempt_users" gorm:"serializer:json"`
+	PExemptRoles  []int    `json:"p_exempt_roles" gorm:"serializer:json"`
+	Write         bool     `json:"write"`
+	WSub          bool     `json:"w_sub"`
+	Hide          string   `json:"hide"`
+	HSub          bool     `json:"h_sub"`
+	HExemptUsers  []uint   `json:"h_exempt_users" gorm:"serializer:json"`
+	HProtocols    []string `json:"h_protocols" gorm:"serializer:json"` // hide only via these protocols, empty means all
+	Readme        string   `json:"readme" gorm:"type:text"`
+	RSub          bool     `json:"r_sub"`
+	Header        string   `json:"header" gorm:"type:text"`
+	HeaderSub     bool     `json:"header_sub"`
+	Footer        string   `json:"footer" gorm:"type:text"`
+	FooterSub     bool     `json:"footer_sub"`
+	Sort
+	SortSub bool `json:"sort_sub"`
+}
+
+// HideRule compiles the hide rule of the meta
+func (m *Meta) HideRule() (*HideRule, error) {
+	return NewHideRule(m.Hide, m.HExemptUsers, m.HProtocols)
 }
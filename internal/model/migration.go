@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// SchemaMigration records a migration applied to the database
+type SchemaMigration struct {
+	Version string    `json:"version" gorm:"primaryKey;size:64"`
+	Name    string    `json:"name"`
+	Applied time.Time `json:"applied"`
+}
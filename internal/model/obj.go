@@ -13,6 +13,7 @@ import (
 	mapset "github.com/deckarep/golang-set/v2"
 
 	"github.com/maruel/natural"
+	log "github.com/sirupsen/logrus"
 )
 
 type ObjUnwrap interface {
@@ -196,8 +197,9 @@ func NewObjMerge() *ObjMerge {
 }
 
 type ObjMerge struct {
-	regs []*regexp2.Regexp
-	set  mapset.Set[string]
+	regs            []*regexp2.Regexp
+	set             mapset.Set[string]
+	skipSystemFiles bool
 }
 
 func (om *ObjMerge) Merge(objs []Obj, objs_ ...Obj) []Obj {
@@ -216,6 +218,9 @@ func (om *ObjMerge) insertObjs(objs []Obj, objs_ ...Obj) []Obj {
 }
 
 func (om *ObjMerge) clickObj(obj Obj) bool {
+	if om.skipSystemFiles && utils.IsSystemFile(obj.GetName()) {
+		return false
+	}
 	for _, reg := range om.regs {
 		if isMatch, _ := reg.MatchString(obj.GetName()); isMatch {
 			return false
@@ -228,8 +233,36 @@ func (om *ObjMerge) InitHideReg(hides string) {
 	rs := strings.Split(hides, "\n")
 	om.regs = make([]*regexp2.Regexp, 0, len(rs))
 	for _, r := range rs {
-		om.regs = append(om.regs, regexp2.MustCompile(r, regexp2.None))
+		om.addHideReg(r)
+	}
+}
+
+// AddHideReg appends the non-empty lines of hides to the hide regexps
+func (om *ObjMerge) AddHideReg(hides string) {
+	for _, r := range strings.Split(hides, "\n") {
+		if r = strings.TrimSpace(r); r != "" {
+			om.addHideReg(r)
+		}
+	}
+}
+
+// addHideReg appends the hide regexp r, an invalid one is skipped instead of failing the listing
+func (om *ObjMerge) addHideReg(r string) {
+	reg, err := regexp2.Compile(r, regexp2.None)
+	if err != nil {
+		log.Warnf("skip the invalid hide pattern %s: %v", r, err)
+		return
 	}
+	om.regs = append(om.regs, reg)
+}
+
+// AddHideRule appends the patterns of rule to the hide regexps
+func (om *ObjMerge) AddHideRule(rule *HideRule) {
+	om.regs = append(om.regs, rule.patterns...)
+}
+
+func (om *ObjMerge) SkipSystemFiles() {
+	om.skipSystemFiles = true
 }
 
 func (om *ObjMerge) Reset() {
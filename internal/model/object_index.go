@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// ObjectIndex is the metadata of an object as last listed from its storage,
+// so that the objects can be sorted and filtered without listing the storages again
+type ObjectIndex struct {
+	ID       uint      `json:"-" gorm:"primaryKey"`
+	Parent   string    `json:"parent" gorm:"index"`
+	Name     string    `json:"name"`
+	Storage  string    `json:"storage" gorm:"index"` // the mount path
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size" gorm:"index"`
+	Modified time.Time `json:"modified" gorm:"index"`
+	Ext      string    `json:"ext" gorm:"index"`
+	Mime     string    `json:"mime"`
+	Hash     string    `json:"hash"` // the hashes as HashInfo.String, empty if there is none
+}
+
+func NewObjectIndex(parent, storage string, obj Obj) ObjectIndex {
+	o := ObjectIndex{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		Storage:  storage,
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+	}
+	if !o.IsDir {
+		o.Ext = utils.Ext(o.Name)
+		o.Mime = utils.GetMimeType(o.Name)
+		if hash := obj.GetHash(); len(hash.Export()) > 0 {
+			o.Hash = hash.String()
+		}
+	}
+	return o
+}
+
+type ObjectIndexReq struct {
+	PageReq
+	Parent string   `json:"parent"`
+	Exts   []string `json:"exts"`
+	// 0 for all, 1 for dir, 2 for file
+	Scope          int    `json:"scope"`
+	OrderBy        string `json:"order_by"`
+	OrderDirection string `json:"order_direction"`
+}
+
+func (r *ObjectIndexReq) Validate() error {
+	r.PageReq.Validate()
+	switch r.OrderBy {
+	case "":
+		r.OrderBy = "name"
+	case "name", "size", "modified":
+	default:
+		return fmt.Errorf("invalid order_by: %s", r.OrderBy)
+	}
+	switch r.OrderDirection {
+	case "":
+		r.OrderDirection = "asc"
+	case "asc", "desc":
+	default:
+		return fmt.Errorf("invalid order_direction: %s", r.OrderDirection)
+	}
+	return nil
+}
+
+type ObjectIndexProgress struct {
+	Running  bool       `json:"running"`
+	Paths    []string   `json:"paths"`
+	DirCount uint64     `json:"dir_count"`
+	ObjCount uint64     `json:"obj_count"`
+	Started  *time.Time `json:"started"`
+	Finished *time.Time `json:"finished"`
+	Error    string     `json:"error"`
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	ProxyActionRedirect = "302"
+	ProxyActionProxy    = "proxy"
+)
+
+// ProxyRule decides whether a download is redirected to the link of the provider or proxied by the server,
+// a rule matches if all of its conditions match, the empty conditions match everything
+type ProxyRule struct {
+	Action string   `json:"action"`
+	CIDRs  []string `json:"cidrs,omitempty"` // the client ip ranges
+	Exts   []string `json:"exts,omitempty"`
+	// the size limits in bytes, 0 for no limit
+	MinSize int64 `json:"min_size,omitempty"`
+	MaxSize int64 `json:"max_size,omitempty"`
+
+	nets []*net.IPNet
+}
+
+func (r *ProxyRule) parse() error {
+	if r.Action != ProxyActionRedirect && r.Action != ProxyActionProxy {
+		return errors.Errorf("invalid action: %s", r.Action)
+	}
+	for _, cidr := range r.CIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return errors.Errorf("invalid cidr: %s", cidr)
+		}
+		r.nets = append(r.nets, ipNet)
+	}
+	for i := range r.Exts {
+		r.Exts[i] = strings.ToLower(strings.TrimPrefix(r.Exts[i], "."))
+	}
+	return nil
+}
+
+func (r *ProxyRule) match(ip net.IP, ext string, size func() int64) bool {
+	if len(r.nets) > 0 {
+		if ip == nil {
+			return false
+		}
+		matched := false
+		for _, ipNet := range r.nets {
+			if ipNet.Contains(ip) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Exts) > 0 && !utils.SliceContains(r.Exts, ext) {
+		return false
+	}
+	if r.MinSize > 0 || r.MaxSize > 0 {
+		s := size()
+		if s < 0 || (r.MinSize > 0 && s < r.MinSize) || (r.MaxSize > 0 && s > r.MaxSize) {
+			return false
+		}
+	}
+	return true
+}
+
+func ParseProxyRules(s string) ([]ProxyRule, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var rules []ProxyRule
+	if err := json.Unmarshal([]byte(s), &rules); err != nil {
+		return nil, errors.Wrap(err, "invalid proxy rules")
+	}
+	for i := range rules {
+		if err := rules[i].parse(); err != nil {
+			return nil, errors.WithMessagef(err, "invalid proxy rule %d", i+1)
+		}
+	}
+	return rules, nil
+}
+
+// LoadProxyRules parses the proxy rules when the storage is loaded, instead of on every download
+func (p *Proxy) LoadProxyRules() error {
+	rules, err := ParseProxyRules(p.ProxyRules)
+	p.proxyRules = rules
+	return err
+}
+
+// MatchProxyRule returns whether the download should be proxied by the first matching rule,
+// ok is false if no rule matches. size is only called for the rules limited by the size
+func (p Proxy) MatchProxyRule(clientIP, filename string, size func() int64) (proxy bool, ok bool) {
+	ip, ext := net.ParseIP(clientIP), utils.Ext(filename)
+	for i := range p.proxyRules {
+		if p.proxyRules[i].match(ip, ext, size) {
+			return p.proxyRules[i].Action == ProxyActionProxy, true
+		}
+	}
+	return false, false
+}
+
+// HasProxyRule reports whether any rule may proxy the downloads
+func (p Proxy) HasProxyRule() bool {
+	for _, r := range p.proxyRules {
+		if r.Action == ProxyActionProxy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+)
+
+const (
+	ScheduleCopy = "copy"
+	ScheduleMove = "move"
+)
+
+// the policies of the objects existing in the destination of a schedule
+const (
+	ScheduleSkip      = "skip"
+	ScheduleOverwrite = "overwrite"
+	// ScheduleMerge copies the missing files into the existing directories, for the copies only
+	ScheduleMerge = "merge"
+)
+
+// Schedule is a recurring copy or move of the objects of a directory. At the times of the cron expression
+// the transfer tasks are added to the copy or move task manager as the tasks of the user
+type Schedule struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+	Cron string `json:"cron" binding:"required"` // e.g. 0 3 * * * or @daily
+	Op   string `json:"op"`                      // copy or move
+	// SrcDir and DstDir are full paths rather than relative to the base path of the user
+	SrcDir string `json:"src_dir" binding:"required"`
+	// Names are the objects of SrcDir to transfer, empty for all of them
+	Names     []string   `json:"names" gorm:"type:text;serializer:json"`
+	DstDir    string     `json:"dst_dir" binding:"required"`
+	Policy    string     `json:"policy"`  // skip, overwrite or merge
+	UserId    uint       `json:"user_id"` // the creator of the tasks, the admin if 0
+	Disabled  bool       `json:"disabled"`
+	Created   time.Time  `json:"created"`
+	LastRun   *time.Time `json:"last_run"`
+	LastError string     `json:"last_error" gorm:"type:text"`
+}
+
+// Due reports whether the schedule should run at now, the cron expression is in the local time.
+// The runs missed while the server was down are made up by a single run
+func (s *Schedule) Due(now time.Time) bool {
+	if s.Disabled {
+		return false
+	}
+	expr, err := cron.ParseExpr(s.Cron)
+	if err != nil {
+		return false
+	}
+	last := s.Created
+	if s.LastRun != nil {
+		last = *s.LastRun
+	}
+	next := expr.Next(last.Local())
+	return !next.IsZero() && !now.Before(next)
+}
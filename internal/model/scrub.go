@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// ScrubFile is a file copied with verification, the scrub tasks re-verify it against the hashes
+// recorded at the copy to find the corruptions of the storage
+type ScrubFile struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Path string `json:"path" gorm:"unique" binding:"required"`
+	Size int64  `json:"size"`
+	// Hash is the HashInfo of the source in the string form, empty if the source provides none
+	Hash      string     `json:"hash"`
+	Recorded  time.Time  `json:"recorded"`
+	LastScrub *time.Time `json:"last_scrub"`
+	Error     string     `json:"error" gorm:"type:text"` // the mismatch found by the last scrub
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// SiteConfig is the declarative document of the metas, hide rules and shares of a site,
+// the users are referred to by their usernames so that it can be applied to another instance
+type SiteConfig struct {
+	Metas  []SiteMeta  `yaml:"metas,omitempty"`
+	Hides  []SiteHide  `yaml:"hides,omitempty"`
+	Shares []SiteShare `yaml:"shares,omitempty"`
+}
+
+// SiteMeta is a meta without its hide rule
+type SiteMeta struct {
+	Path          string   `yaml:"path"`
+	ReadUsers     []string `yaml:"read_users,omitempty"`
+	ReadUsersSub  bool     `yaml:"read_users_sub,omitempty"`
+	WriteUsers    []string `yaml:"write_users,omitempty"`
+	WriteUsersSub bool     `yaml:"write_users_sub,omitempty"`
+	Password      string   `yaml:"password,omitempty"`
+	PSub          bool     `yaml:"p_sub,omitempty"`
+	PInherit      bool     `yaml:"p_inherit,omitempty"`
+	PExemptUsers  []string `yaml:"p_exempt_users,omitempty"`
+	PExemptRoles  []int    `yaml:"p_exempt_roles,omitempty"`
+	Write         bool     `yaml:"write,omitempty"`
+	WSub          bool     `yaml:"w_sub,omitempty"`
+	Readme        string   `yaml:"readme,omitempty"`
+	RSub          bool     `yaml:"r_sub,omitempty"`
+	Header        string   `yaml:"header,omitempty"`
+	HeaderSub     bool     `yaml:"header_sub,omitempty"`
+	Footer        string   `yaml:"footer,omitempty"`
+	FooterSub     bool     `yaml:"footer_sub,omitempty"`
+	OrderBy       string   `yaml:"order_by,omitempty"`
+	OrderDir      string   `yaml:"order_direction,omitempty"`
+	ExtractFolder string   `yaml:"extract_folder,omitempty"`
+	SortSub       bool     `yaml:"sort_sub,omitempty"`
+}
+
+// SiteHide is the hide rule of the meta of the path
+type SiteHide struct {
+	Path        string   `yaml:"path"`
+	Patterns    []string `yaml:"patterns"`
+	Sub         bool     `yaml:"sub,omitempty"`
+	ExemptUsers []string `yaml:"exempt_users,omitempty"`
+	Protocols   []string `yaml:"protocols,omitempty"`
+}
+
+type SiteShare struct {
+	ID          string     `yaml:"id"`
+	Creator     string     `yaml:"creator"`
+	Files       []string   `yaml:"files"`
+	Expires     *time.Time `yaml:"expires,omitempty"`
+	Pwd         string     `yaml:"pwd,omitempty"`
+	MaxAccessed int        `yaml:"max_accessed,omitempty"`
+	Disabled    bool       `yaml:"disabled,omitempty"`
+	Remark      string     `yaml:"remark,omitempty"`
+	Readme      string     `yaml:"readme,omitempty"`
+	Header      string     `yaml:"header,omitempty"`
+	OrderBy     string     `yaml:"order_by,omitempty"`
+	OrderDir    string     `yaml:"order_direction,omitempty"`
+}
+
+// SiteConfigResult counts the changes of applying a SiteConfig
+type SiteConfigResult struct {
+	MetasCreated  int `json:"metas_created"`
+	MetasUpdated  int `json:"metas_updated"`
+	MetasDeleted  int `json:"metas_deleted"`
+	SharesCreated int `json:"shares_created"`
+	SharesUpdated int `json:"shares_updated"`
+	SharesDeleted int `json:"shares_deleted"`
+}
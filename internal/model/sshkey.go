@@ -13,6 +13,8 @@ type SSHPublicKey struct {
 	KeyStr       string    `gorm:"type:text" json:"-"`
 	AddedTime    time.Time `json:"added_time"`
 	LastUsedTime time.Time `json:"last_used_time"`
+	// ExpiresAt is the time after which the key is refused, nil means never
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 func (k *SSHPublicKey) GetKey() (ssh.PublicKey, error) {
@@ -26,3 +28,7 @@ func (k *SSHPublicKey) GetKey() (ssh.PublicKey, error) {
 func (k *SSHPublicKey) UpdateLastUsedTime() {
 	k.LastUsedTime = time.Now()
 }
+
+func (k *SSHPublicKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StorageTraffic is the daily rollup of the bytes read from and written to a storage,
+// for the providers metering the traffic
+type StorageTraffic struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Day      string `json:"day" gorm:"index;size:10"` // 2006-01-02
+	Storage  string `json:"storage"`                  // mount path of the storage
+	Download int64  `json:"download"`
+	Upload   int64  `json:"upload"`
+}
+
+type StorageTrafficReq struct {
+	From    string `json:"from" form:"from"` // 2006-01-02, inclusive
+	To      string `json:"to" form:"to"`     // 2006-01-02, inclusive
+	GroupBy string `json:"group_by" form:"group_by"`
+	// Storage limits the traffic to a storage, empty for all storages
+	Storage string `json:"storage" form:"storage"`
+}
+
+type StorageTrafficItem struct {
+	Key      string `json:"key"`
+	Download int64  `json:"download"`
+	Upload   int64  `json:"upload"`
+	// Cost is estimated from the downloads and the egress costs of the storages
+	Cost float64 `json:"cost"`
+}
+
+func (r *StorageTrafficReq) Validate() error {
+	switch r.GroupBy {
+	case "":
+		r.GroupBy = "storage"
+	case "storage", "day":
+	default:
+		return errors.Errorf("invalid group by: %s", r.GroupBy)
+	}
+	for _, day := range []string{r.From, r.To} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, day); err != nil {
+			return errors.Errorf("invalid day: %s", day)
+		}
+	}
+	return nil
+}
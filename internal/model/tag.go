@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Tag is a label assigned to a file or directory. Path is the full path, and Hash is the HashInfo of the file
+// in the string form at the tagging, which finds the tags again after the file is moved outside OpenList
+type Tag struct {
+	ID      uint      `json:"id" gorm:"primaryKey"`
+	Name    string    `json:"name" gorm:"uniqueIndex:idx_tag_name_path;size:64"`
+	Path    string    `json:"path" gorm:"uniqueIndex:idx_tag_name_path;size:512"`
+	Hash    string    `json:"hash" gorm:"index;size:512"`
+	IsDir   bool      `json:"is_dir"`
+	Creator uint      `json:"creator"`
+	Created time.Time `json:"created"`
+}
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
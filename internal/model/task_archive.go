@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// TaskRecord is the state of a finished task kept by the task archive and the task history
+type TaskRecord struct {
+	Type       string     `json:"type" gorm:"index"` // the task manager, e.g. copy or offline_download
+	TaskID     string     `json:"task_id"`
+	Name       string     `json:"name"`
+	CreatorId  uint       `json:"creator_id" gorm:"index"`
+	Creator    string     `json:"creator"`
+	State      int        `json:"state"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error"`
+	TotalBytes int64      `json:"total_bytes"`
+	StartTime  *time.Time `json:"start_time"`
+	EndTime    *time.Time `json:"end_time"`
+	// TransferredBytes and Speed, the average bytes per second, are the throughput of the task
+	TransferredBytes int64   `json:"transferred_bytes"`
+	Speed            float64 `json:"speed"`
+}
+
+// TaskArchive is a finished task moved out of its task manager, so that the managers and their persisted data stay small
+type TaskArchive struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	TaskRecord
+	Month string `json:"month" gorm:"index"` // the month of EndTime as 2006-01
+}
+
+type TaskArchiveReq struct {
+	PageReq
+	Type  string `json:"type" form:"type"`
+	Month string `json:"month" form:"month"`
+}
+
+func (r *TaskArchiveReq) Validate() error {
+	r.PageReq.Validate()
+	if r.Month != "" {
+		if _, err := time.Parse("2006-01", r.Month); err != nil {
+			return err
+		}
+	}
+	return nil
+}
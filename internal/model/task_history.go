@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TaskHistory is the record of a finished task, it's kept for the retention days even if the task is cleared
+// from its task manager or lost by a restart. A task retried after it's done is recorded again as it ends
+type TaskHistory struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	TaskRecord
+	Day string `json:"day" gorm:"index"` // the day of EndTime as 2006-01-02
+}
+
+type TaskHistoryReq struct {
+	PageReq
+	Type string `json:"type" form:"type"`
+	From string `json:"from" form:"from"` // 2006-01-02, inclusive
+	To   string `json:"to" form:"to"`     // 2006-01-02, inclusive
+}
+
+func (r *TaskHistoryReq) Validate() error {
+	r.PageReq.Validate()
+	for _, day := range []string{r.From, r.To} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, day); err != nil {
+			return errors.Errorf("invalid day: %s", day)
+		}
+	}
+	return nil
+}
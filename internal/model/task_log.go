@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+)
+
+// TaskLogger records the log lines of a task, a task puts itself into its ctx by conf.TaskLoggerKey
+type TaskLogger interface {
+	Logf(format string, args ...any)
+}
+
+// TaskLogf records the log line into the task running with ctx, if any
+func TaskLogf(ctx context.Context, format string, args ...any) {
+	if l, ok := ctx.Value(conf.TaskLoggerKey).(TaskLogger); ok {
+		l.Logf(format, args...)
+	}
+}
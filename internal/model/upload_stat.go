@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// UploadStat is the daily rollup of the bytes written by a user through a protocol
+type UploadStat struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Day      string `json:"day" gorm:"index;size:10"` // 2006-01-02
+	UserId   uint   `json:"user_id" gorm:"index"`
+	Protocol string `json:"protocol"`
+	Count    int64  `json:"count"`
+	Bytes    int64  `json:"bytes"`
+}
+
+type UploadStatsReq struct {
+	From    string `json:"from" form:"from"` // 2006-01-02, inclusive
+	To      string `json:"to" form:"to"`     // 2006-01-02, inclusive
+	GroupBy string `json:"group_by" form:"group_by"`
+	OrderBy string `json:"order_by" form:"order_by"` // count or bytes
+	Limit   int    `json:"limit" form:"limit"`
+	// UserId limits the stats to a user, 0 for all users
+	UserId uint `json:"user_id" form:"user_id"`
+}
+
+type UploadStatsItem struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+// UserUsage is the quota of a user and the uploads of the user by day
+type UserUsage struct {
+	Quota     int64             `json:"quota"`
+	QuotaPath string            `json:"quota_path"`
+	Used      int64             `json:"used"`
+	History   []UploadStatsItem `json:"history"`
+}
+
+func (r *UploadStatsReq) Validate() error {
+	switch r.GroupBy {
+	case "":
+		r.GroupBy = "user_id"
+	case "user_id", "protocol", "day":
+	default:
+		return errors.Errorf("invalid group by: %s", r.GroupBy)
+	}
+	switch r.OrderBy {
+	case "":
+		r.OrderBy = "bytes"
+	case "count", "bytes":
+	default:
+		return errors.Errorf("invalid order by: %s", r.OrderBy)
+	}
+	if r.Limit <= 0 || r.Limit > 1000 {
+		r.Limit = 100
+	}
+	for _, day := range []string{r.From, r.To} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, day); err != nil {
+			return errors.Errorf("invalid day: %s", day)
+		}
+	}
+	return nil
+}
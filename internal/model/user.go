@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -68,8 +69,23 @@ type User struct {
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
 	AllowLdap  bool   `json:"allow_ldap" gorm:"default:true"`
+	// the admin api groups a general user is delegated, see AdminScopeStorage etc.
+	AdminScopes int32 `json:"admin_scopes"`
+	// the bytes the user can store under QuotaPath, 0 for no quota
+	Quota int64 `json:"quota"`
+	// the personal path the quota applies to, relative to the base path
+	QuotaPath string `json:"quota_path"`
+	// the grants of the user which may be active, loaded with the user and never saved with it
+	Grants []AccessGrant `json:"-" gorm:"-"`
 }
 
+// the scopes of the admin api which can be delegated to general users
+const (
+	AdminScopeStorage int32 = 1 << iota // the storages and drivers
+	AdminScopeUser                      // the users other than the admin
+	AdminScopeTask                      // the tasks of all users
+)
+
 func (u *User) IsGuest() bool {
 	return u.Role == GUEST
 }
@@ -78,6 +94,11 @@ func (u *User) IsAdmin() bool {
 	return u.Role == ADMIN
 }
 
+// HasAdminScope reports whether the user can use the admin api of the scope, the admin has all scopes
+func (u *User) HasAdminScope(scope int32) bool {
+	return u.IsAdmin() || (!u.IsGuest() && u.AdminScopes&scope == scope)
+}
+
 func (u *User) ValidateRawPassword(password string) error {
 	return u.ValidatePwdStaticHash(StaticHash(password))
 }
@@ -104,7 +125,7 @@ func CanSeeHides(permission int32) bool {
 }
 
 func (u *User) CanSeeHides() bool {
-	return CanSeeHides(u.Permission)
+	return CanSeeHides(u.EffectivePermission())
 }
 
 func CanAccessWithoutPassword(permission int32) bool {
@@ -112,7 +133,7 @@ func CanAccessWithoutPassword(permission int32) bool {
 }
 
 func (u *User) CanAccessWithoutPassword() bool {
-	return CanAccessWithoutPassword(u.Permission)
+	return CanAccessWithoutPassword(u.EffectivePermission())
 }
 
 func CanAddOfflineDownloadTasks(permission int32) bool {
@@ -120,7 +141,7 @@ func CanAddOfflineDownloadTasks(permission int32) bool {
 }
 
 func (u *User) CanAddOfflineDownloadTasks() bool {
-	return CanAddOfflineDownloadTasks(u.Permission)
+	return CanAddOfflineDownloadTasks(u.EffectivePermission())
 }
 
 func CanWriteContent(permission int32) bool {
@@ -128,7 +149,7 @@ func CanWriteContent(permission int32) bool {
 }
 
 func (u *User) CanWriteContent() bool {
-	return CanWriteContent(u.Permission)
+	return CanWriteContent(u.EffectivePermission())
 }
 
 func CanRename(permission int32) bool {
@@ -136,7 +157,7 @@ func CanRename(permission int32) bool {
 }
 
 func (u *User) CanRename() bool {
-	return CanRename(u.Permission)
+	return CanRename(u.EffectivePermission())
 }
 
 func CanMove(permission int32) bool {
@@ -144,7 +165,7 @@ func CanMove(permission int32) bool {
 }
 
 func (u *User) CanMove() bool {
-	return CanMove(u.Permission)
+	return CanMove(u.EffectivePermission())
 }
 
 func CanCopy(permission int32) bool {
@@ -152,7 +173,7 @@ func CanCopy(permission int32) bool {
 }
 
 func (u *User) CanCopy() bool {
-	return CanCopy(u.Permission)
+	return CanCopy(u.EffectivePermission())
 }
 
 func CanRemove(permission int32) bool {
@@ -160,7 +181,7 @@ func CanRemove(permission int32) bool {
 }
 
 func (u *User) CanRemove() bool {
-	return CanRemove(u.Permission)
+	return CanRemove(u.EffectivePermission())
 }
 
 func CanWebdavRead(permission int32) bool {
@@ -168,7 +189,7 @@ func CanWebdavRead(permission int32) bool {
 }
 
 func (u *User) CanWebdavRead() bool {
-	return CanWebdavRead(u.Permission)
+	return CanWebdavRead(u.EffectivePermission())
 }
 
 func CanWebdavManage(permission int32) bool {
@@ -176,7 +197,7 @@ func CanWebdavManage(permission int32) bool {
 }
 
 func (u *User) CanWebdavManage() bool {
-	return CanWebdavManage(u.Permission)
+	return CanWebdavManage(u.EffectivePermission())
 }
 
 func CanFTPAccess(permission int32) bool {
@@ -184,7 +205,7 @@ func CanFTPAccess(permission int32) bool {
 }
 
 func (u *User) CanFTPAccess() bool {
-	return CanFTPAccess(u.Permission)
+	return CanFTPAccess(u.EffectivePermission())
 }
 
 func CanFTPManage(permission int32) bool {
@@ -192,7 +213,7 @@ func CanFTPManage(permission int32) bool {
 }
 
 func (u *User) CanFTPManage() bool {
-	return CanFTPManage(u.Permission)
+	return CanFTPManage(u.EffectivePermission())
 }
 
 func CanReadArchives(permission int32) bool {
@@ -200,7 +221,7 @@ func CanReadArchives(permission int32) bool {
 }
 
 func (u *User) CanReadArchives() bool {
-	return CanReadArchives(u.Permission)
+	return CanReadArchives(u.EffectivePermission())
 }
 
 func CanDecompress(permission int32) bool {
@@ -208,7 +229,7 @@ func CanDecompress(permission int32) bool {
 }
 
 func (u *User) CanDecompress() bool {
-	return CanDecompress(u.Permission)
+	return CanDecompress(u.EffectivePermission())
 }
 
 func CanShare(permission int32) bool {
@@ -216,7 +237,61 @@ func CanShare(permission int32) bool {
 }
 
 func (u *User) CanShare() bool {
-	return CanShare(u.Permission)
+	return CanShare(u.EffectivePermission())
+}
+
+// the features which can be turned off for all users of a role on top of their permissions
+const (
+	FeatureOfflineDownload = "offline_download"
+	FeatureDecompress      = "decompress"
+	FeatureShare           = "share"
+	FeatureSeeAllTasks     = "see_all_tasks" // see the tasks of the other users without managing them
+)
+
+// RoleFeatures is the feature flags by role, loaded from the role_feature_flags setting
+var RoleFeatures = make(map[int]map[string]bool)
+
+// HasFeature reports whether the role of the user has the feature, the admin has all features.
+// The features not configured for the role are enabled, except seeing the tasks of the other users
+func (u *User) HasFeature(feature string) bool {
+	if u.IsAdmin() {
+		return true
+	}
+	if enabled, ok := RoleFeatures[u.Role][feature]; ok {
+		return enabled
+	}
+	return feature != FeatureSeeAllTasks
+}
+
+// RoleStorageGroups is the storage groups by role, loaded from the role_storage_groups setting
+var RoleStorageGroups = make(map[int][]string)
+
+// CanAccessStorageGroup reports whether the role of the user is assigned the storage group, the admin and
+// the roles without any group assigned can access all groups, and every role can access the storages of no group
+func (u *User) CanAccessStorageGroup(group string) bool {
+	if u.IsAdmin() || group == "" {
+		return true
+	}
+	groups, ok := RoleStorageGroups[u.Role]
+	return !ok || slices.Contains(groups, group)
+}
+
+// the task types limited by the user_task_limits setting
+const (
+	TaskTypeUpload          = "upload"
+	TaskTypeCopy            = "copy"
+	TaskTypeMove            = "move"
+	TaskTypeOfflineDownload = "offline_download"
+	TaskTypeDecompress      = "decompress"
+)
+
+// RoleTaskLimits is the max pending and running tasks of a user by role and task type,
+// loaded from the user_task_limits setting
+var RoleTaskLimits = make(map[int]map[string]int)
+
+// TaskLimit returns the max pending and running tasks of the type the user may have, 0 for no limit
+func (u *User) TaskLimit(taskType string) int {
+	return RoleTaskLimits[u.Role][taskType]
 }
 
 func (u *User) JoinPath(reqPath string) (string, error) {
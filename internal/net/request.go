@@ -387,6 +387,7 @@ func (d *downloader) downloadChunk(ch *chunk) error {
 			}
 			log.Warnf("err chunk_%d, object part download error %s, retrying attempt %d. %v",
 				ch.id, params.URL, retry, err)
+			model.TaskLogf(d.ctx, "chunk %d failed, retrying attempt %d: %v", ch.id, retry, err)
 		} else if err == errInfiniteRetry {
 			retry--
 			continue
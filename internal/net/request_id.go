@@ -0,0 +1,20 @@
+package net
+
+import (
+	"context"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+)
+
+// RequestIDHeader carries the id of the api request which led to a request of a driver,
+// so that the failing upstream call can be found from the id the user reports
+const RequestIDHeader = "X-Request-Id"
+
+// RequestID returns the id of the api request carried by ctx, empty if there is none
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(conf.RequestIDKey).(string)
+	return id
+}
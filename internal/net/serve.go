@@ -3,7 +3,6 @@ package net
 import (
 	"compress/gzip"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -28,6 +27,18 @@ import (
 //	GetReaderForRange RangeReaderFunc
 //}
 
+// sniffContentType detects the content type of the file of which the extension is unknown by its first bytes
+func sniffContentType(ctx context.Context, name string, size int64, rrc model.RangeReadCloserIF) string {
+	rc, err := rrc.RangeRead(ctx, http_range.Range{Start: 0, Length: min(size, 512)})
+	if err != nil {
+		return utils.GetMimeType(name)
+	}
+	defer rc.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(rc, head)
+	return utils.DetectMimeType(name, head[:n])
+}
+
 // ServeHTTP replies to the request using the content in the
 // provided RangeReadCloser. The main benefit of ServeHTTP over io.Copy
 // is that it handles Range requests properly, sets the MIME type, and
@@ -74,6 +85,9 @@ func ServeHTTP(w http.ResponseWriter, r *http.Request, name string, modTime time
 	var contentType string
 	if !haveType {
 		contentType = utils.GetMimeType(name)
+		if !utils.IsMimeTypeKnown(name) && size > 0 {
+			contentType = sniffContentType(r.Context(), name, size, RangeReadCloser)
+		}
 		w.Header().Set("Content-Type", contentType)
 	} else if len(contentTypes) > 0 {
 		contentType = contentTypes[0]
@@ -232,6 +246,13 @@ func RequestHttp(ctx context.Context, httpMethod string, headerOverride http.Hea
 		return nil, err
 	}
 	req.Header = headerOverride
+	if id := RequestID(ctx); id != "" {
+		req.Header = headerOverride.Clone()
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		req.Header.Set(RequestIDHeader, id)
+	}
 	res, err := HttpClient().Do(req)
 	if err != nil {
 		return nil, err
@@ -265,6 +286,10 @@ func (e HttpStatusCodeError) Error() string {
 	return fmt.Sprintf("%d|%s", e, http.StatusText(int(e)))
 }
 
+func (e HttpStatusCodeError) HTTPStatus() int {
+	return int(e)
+}
+
 var once sync.Once
 var httpClient *http.Client
 
@@ -283,15 +308,8 @@ func HttpClient() *http.Client {
 }
 
 func NewHttpClient() *http.Client {
-	transport := &http.Transport{
-		Proxy:           http.ProxyFromEnvironment,
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: conf.Conf.TlsInsecureSkipVerify},
-	}
-
-	SetProxyIfConfigured(transport)
-
 	return &http.Client{
 		Timeout:   time.Hour * 48,
-		Transport: transport,
+		Transport: SharedTransport(),
 	}
 }
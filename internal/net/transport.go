@@ -0,0 +1,125 @@
+package net
+
+import (
+	"context"
+	"crypto/tls"
+	stdnet "net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+var (
+	sharedTransport     *http.Transport
+	sharedTransportOnce sync.Once
+)
+
+// SharedTransport returns the transport shared by the http clients of the drivers,
+// so that the connections to the providers are reused across the clients instead of
+// being dialed and TLS handshaked again for every client
+func SharedTransport() *http.Transport {
+	sharedTransportOnce.Do(func() {
+		sharedTransport = newTransport(false)
+	})
+	return sharedTransport
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+func newDialer() *stdnet.Dialer {
+	return &stdnet.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: time.Duration(orDefault(conf.Conf.HttpClient.KeepAlive, 30)) * time.Second,
+	}
+}
+
+func newTransport(insecureSkipVerify bool) *http.Transport {
+	cfg := conf.Conf.HttpClient
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           newDialer().DialContext,
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: conf.Conf.TlsInsecureSkipVerify || insecureSkipVerify},
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+		MaxIdleConns:          orDefault(cfg.MaxIdleConns, 256),
+		MaxIdleConnsPerHost:   orDefault(cfg.MaxIdleConnsPerHost, 32),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       time.Duration(orDefault(cfg.IdleConnTimeout, 90)) * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	SetProxyIfConfigured(transport)
+	return transport
+}
+
+var storageTransports sync.Map // model.NetworkOptions -> *http.Transport
+
+// StorageTransport returns a transport tuned like SharedTransport with the network options of a storage,
+// it's made once for the same options so that their connections are reused too
+func StorageTransport(opts model.NetworkOptions) (*http.Transport, error) {
+	if t, ok := storageTransports.Load(opts); ok {
+		return t.(*http.Transport), nil
+	}
+	transport := newTransport(opts.TlsInsecureSkipVerify)
+	if opts.ProxyURL != "" {
+		u, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid proxy url %s", opts.ProxyURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, errors.Errorf("unsupported proxy scheme %s", u.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	if dnsServer := opts.DNSServer; dnsServer != "" {
+		if _, _, err := stdnet.SplitHostPort(dnsServer); err != nil {
+			dnsServer = stdnet.JoinHostPort(dnsServer, "53")
+		}
+		dialer := newDialer()
+		dialer.Resolver = &stdnet.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (stdnet.Conn, error) {
+				var d stdnet.Dialer
+				return d.DialContext(ctx, network, dnsServer)
+			},
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	if opts.RequestTimeout > 0 {
+		transport.ResponseHeaderTimeout = time.Duration(opts.RequestTimeout) * time.Second
+	}
+	t, _ := storageTransports.LoadOrStore(opts, transport)
+	return t.(*http.Transport), nil
+}
+
+// driverTransport sends the requests made with the network options of a storage in their context, see
+// conf.NetworkOptionsKey, by the StorageTransport of the options, and the others by the SharedTransport
+type driverTransport struct{}
+
+func (driverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if opts, ok := req.Context().Value(conf.NetworkOptionsKey).(*model.NetworkOptions); ok && opts != nil {
+		transport, err := StorageTransport(*opts)
+		if err != nil {
+			return nil, err
+		}
+		return transport.RoundTrip(req)
+	}
+	return SharedTransport().RoundTrip(req)
+}
+
+// DriverTransport returns the transport of the clients of drivers/base, applying the network options of the storage
+// the request is made for
+func DriverTransport() http.RoundTripper {
+	return driverTransport{}
+}
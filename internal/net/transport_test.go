@@ -0,0 +1,58 @@
+package net
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestDriverTransportNetworkOptions(t *testing.T) {
+	oldConf := conf.Conf
+	conf.Conf = conf.DefaultConfig("data")
+	defer func() {
+		conf.Conf = oldConf
+	}()
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("http_proxy", "")
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "direct")
+	}))
+	defer target.Close()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "proxied")
+	}))
+	defer proxy.Close()
+
+	client := &http.Client{Transport: DriverTransport()}
+	get := func(ctx context.Context) string {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	if got := get(context.Background()); got != "direct" {
+		t.Errorf("got %q without the network options, want direct", got)
+	}
+	opts := &model.NetworkOptions{ProxyURL: proxy.URL}
+	if got := get(context.WithValue(context.Background(), conf.NetworkOptionsKey, opts)); got != "proxied" {
+		t.Errorf("got %q with the proxy of the network options, want proxied", got)
+	}
+
+	if _, err := StorageTransport(model.NetworkOptions{ProxyURL: "ftp://127.0.0.1"}); err == nil {
+		t.Error("expected the unsupported proxy scheme to fail")
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/rclone/rclone/lib/readers"
 
 	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
-	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -332,13 +331,3 @@ func SetProxyIfConfigured(transport *http.Transport) {
 		}
 	}
 }
-
-// SetRestyProxyIfConfigured sets proxy for Resty client if configured
-func SetRestyProxyIfConfigured(client *resty.Client) {
-	// If proxy address is configured, override environment variable settings
-	if conf.Conf.ProxyAddress != "" {
-		if proxyURL, err := url.Parse(conf.Conf.ProxyAddress); err == nil {
-			client.SetProxy(proxyURL.String())
-		}
-	}
-}
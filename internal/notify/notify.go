@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/tache"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// TaskEvent is the done task posted to the webhook
+type TaskEvent struct {
+	Type       string     `json:"type"` // the type of the task manager, e.g. copy
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	State      string     `json:"state"` // succeeded, failed or canceled
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Creator    string     `json:"creator,omitempty"`
+	TotalBytes int64      `json:"total_bytes"`
+	StartTime  *time.Time `json:"start_time"`
+	EndTime    *time.Time `json:"end_time"`
+}
+
+func stateName(state tache.State) string {
+	switch state {
+	case tache.StateSucceeded:
+		return "succeeded"
+	case tache.StateFailed:
+		return "failed"
+	case tache.StateCanceled:
+		return "canceled"
+	}
+	return ""
+}
+
+// enabled reports whether the item is in the comma separated list of the setting
+func enabled(key, item string) bool {
+	return slices.ContainsFunc(strings.Split(setting.GetStr(key), ","), func(s string) bool {
+		return strings.TrimSpace(s) == item
+	})
+}
+
+// TaskDone sends the notifications of the task if its type and state are enabled,
+// it's the done hook of the task managers
+func TaskDone(typ string, t task.TaskExtensionInfo) {
+	state := stateName(t.GetState())
+	if !enabled(conf.NotifyTaskTypes, typ) || !enabled(conf.NotifyTaskStates, state) {
+		return
+	}
+	e := TaskEvent{
+		Type:       typ,
+		ID:         t.GetID(),
+		Name:       t.GetName(),
+		State:      state,
+		Status:     t.GetStatus(),
+		TotalBytes: t.GetTotalBytes(),
+		StartTime:  t.GetStartTime(),
+		EndTime:    t.GetEndTime(),
+	}
+	if err := t.GetErr(); err != nil {
+		e.Error = err.Error()
+	}
+	if creator := t.GetCreator(); creator != nil {
+		e.Creator = creator.Username
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := Send(ctx, &e); err != nil {
+			log.Errorf("failed notify the %s task %s: %+v", typ, e.ID, err)
+		}
+	}()
+}
+
+func (e *TaskEvent) title() string {
+	return fmt.Sprintf("OpenList %s task %s", e.Type, e.State)
+}
+
+func (e *TaskEvent) text() string {
+	var b strings.Builder
+	b.WriteString(e.Name)
+	if e.Creator != "" {
+		fmt.Fprintf(&b, "\ncreator: %s", e.Creator)
+	}
+	if e.Error != "" {
+		fmt.Fprintf(&b, "\nerror: %s", e.Error)
+	}
+	return b.String()
+}
+
+// Send posts the event to the webhook and sends its message to the telegram chat, bark and email
+// which are configured, the errors of them are joined
+func Send(ctx context.Context, e *TaskEvent) error {
+	var errs []string
+	if u := setting.GetStr(conf.NotifyWebhookURL); u != "" {
+		if err := postJSON(ctx, u, e); err != nil {
+			errs = append(errs, "webhook: "+err.Error())
+		}
+	}
+	if token, chatID := setting.GetStr(conf.NotifyTelegramToken), setting.GetStr(conf.NotifyTelegramChatID); token != "" && chatID != "" {
+		err := postJSON(ctx, "https://api.telegram.org/bot"+token+"/sendMessage", map[string]string{
+			"chat_id": chatID,
+			"text":    e.title() + "\n" + e.text(),
+		})
+		if err != nil {
+			errs = append(errs, "telegram: "+strings.ReplaceAll(err.Error(), token, "***"))
+		}
+	}
+	if u := setting.GetStr(conf.NotifyBarkURL); u != "" {
+		err := postJSON(ctx, u, map[string]string{
+			"title": e.title(),
+			"body":  e.text(),
+			"group": "OpenList",
+		})
+		if err != nil {
+			errs = append(errs, "bark: "+err.Error())
+		}
+	}
+	if u := setting.GetStr(conf.NotifyEmailURL); u != "" {
+		if err := sendEmail(u, e.title(), e.text()); err != nil {
+			errs = append(errs, "email: "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, u string, body any) error {
+	res, err := base.RestyClient.R().SetContext(ctx).SetBody(body).Post(u)
+	if err != nil {
+		return err
+	}
+	if res.IsError() {
+		return errors.Errorf("%s: %s", res.Status(), res.String())
+	}
+	return nil
+}
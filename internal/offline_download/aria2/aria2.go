@@ -3,6 +3,7 @@ package aria2
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -36,6 +37,7 @@ func (a *Aria2) Items() []model.SettingItem {
 	return []model.SettingItem{
 		{Key: conf.Aria2Uri, Value: "http://localhost:6800/jsonrpc", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 		{Key: conf.Aria2Secret, Value: "", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.Aria2TempPath, Value: "", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE, Help: `the path of a local storage where the downloads are staged by default, empty to use the temp dir`},
 	}
 }
 
@@ -121,7 +123,62 @@ func (a *Aria2) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	return s, nil
 }
 
+// ResolveMagnet pauses the download following the metadata, whose files are known then
+func (a *Aria2) ResolveMagnet(ctx context.Context, args *tool.AddUrlArgs) (*tool.MagnetInfo, error) {
+	gid, err := a.client.AddURI([]string{args.Url}, map[string]interface{}{
+		"dir":            args.TempDir,
+		"pause-metadata": "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+	gids := []string{gid}
+	defer func() {
+		for _, gid := range gids {
+			_, _ = a.client.ForceRemove(gid)
+			_, _ = a.client.RemoveDownloadResult(gid)
+		}
+	}()
+	return tool.WaitMagnet(ctx, func() (*tool.MagnetInfo, error) {
+		info, err := a.client.TellStatus(gid)
+		if err != nil {
+			return nil, err
+		}
+		if info.Status == "error" {
+			return nil, errors.Errorf("failed to fetch the metadata, error: %s", info.ErrorMessage)
+		}
+		if len(info.FollowedBy) == 0 {
+			return nil, nil
+		}
+		gids = append(gids, info.FollowedBy[0])
+		info, err = a.client.TellStatus(info.FollowedBy[0])
+		if err != nil {
+			return nil, err
+		}
+		res := &tool.MagnetInfo{Name: info.BitTorrent.Info.Name}
+		res.Size, _ = strconv.ParseInt(info.TotalLength, 10, 64)
+		for _, f := range info.Files {
+			size, _ := strconv.ParseInt(f.Length, 10, 64)
+			path, err := filepath.Rel(info.Dir, f.Path)
+			if err != nil {
+				path = filepath.Base(f.Path)
+			}
+			res.Files = append(res.Files, tool.MagnetFile{Path: filepath.ToSlash(path), Size: size})
+		}
+		return res, nil
+	})
+}
+
+func (a *Aria2) SetDownloadLimit(limit int64) error {
+	_, err := a.client.ChangeGlobalOption(rpc.Option{
+		"max-overall-download-limit": strconv.FormatInt(limit, 10),
+	})
+	return err
+}
+
 var _ tool.Tool = (*Aria2)(nil)
+var _ tool.MagnetResolver = (*Aria2)(nil)
+var _ tool.SpeedLimiter = (*Aria2)(nil)
 
 func init() {
 	tool.Tools.Add(&Aria2{})
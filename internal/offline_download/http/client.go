@@ -11,8 +11,10 @@ import (
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 )
@@ -26,7 +28,9 @@ func (s SimpleHttp) Name() string {
 }
 
 func (s SimpleHttp) Items() []model.SettingItem {
-	return nil
+	return []model.SettingItem{
+		{Key: conf.SimpleHttpTempPath, Value: "", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE, Help: `the path of a local storage where the downloads are staged by default, empty to use the temp dir`},
+	}
 }
 
 func (s SimpleHttp) Init() (string, error) {
@@ -60,6 +64,9 @@ func (s SimpleHttp) Run(task *tool.DownloadTask) error {
 		return err
 	}
 	req.Header.Set("User-Agent", base.UserAgent)
+	for k, v := range task.Header {
+		req.Header[k] = v
+	}
 	if streamPut {
 		req.Header.Set("Range", "bytes=0-")
 	}
@@ -98,7 +105,8 @@ func (s SimpleHttp) Run(task *tool.DownloadTask) error {
 		return err
 	}
 	defer file.Close()
-	err = utils.CopyWithCtx(task.Ctx(), file, resp.Body, fileSize, task.SetProgress)
+	body := &stream.RateLimitReader{Reader: resp.Body, Limiter: tool.DownloadLimit, Ctx: task.Ctx()}
+	err = utils.CopyWithCtx(task.Ctx(), file, body, fileSize, task.SetProgress)
 	return err
 }
 
@@ -0,0 +1,196 @@
+package sabnzbd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type SABnzbd struct {
+	url    string
+	apiKey string
+	ready  bool
+}
+
+type errResp struct {
+	Status *bool  `json:"status"`
+	Error  string `json:"error"`
+}
+
+type queueSlot struct {
+	NzoId      string `json:"nzo_id"`
+	Status     string `json:"status"`
+	Percentage string `json:"percentage"`
+	Mb         string `json:"mb"`
+}
+
+type historySlot struct {
+	NzoId       string `json:"nzo_id"`
+	Status      string `json:"status"`
+	Storage     string `json:"storage"`
+	FailMessage string `json:"fail_message"`
+	Bytes       int64  `json:"bytes"`
+}
+
+func (s *SABnzbd) Run(task *tool.DownloadTask) error {
+	return errs.NotSupport
+}
+
+func (s *SABnzbd) Name() string {
+	return "SABnzbd"
+}
+
+func (s *SABnzbd) Items() []model.SettingItem {
+	// sabnzbd settings
+	return []model.SettingItem{
+		{Key: conf.SabnzbdUrl, Value: "http://localhost:8080", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.SabnzbdApiKey, Value: "", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.SabnzbdCategory, Value: "", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE, Help: `the category of the added jobs, empty to use the default one`},
+	}
+}
+
+// request calls the api of sabnzbd with the mode and params, the json output is unmarshalled into resp
+func (s *SABnzbd) request(mode string, params map[string]string, resp interface{}) error {
+	res, err := base.RestyClient.R().
+		SetQueryParams(map[string]string{
+			"mode":   mode,
+			"apikey": s.apiKey,
+			"output": "json",
+		}).
+		SetQueryParams(params).
+		Get(s.url + "/api")
+	if err != nil {
+		return err
+	}
+	if res.IsError() {
+		return errors.Errorf("sabnzbd api responded %s", res.Status())
+	}
+	// sabnzbd reports the errors with a 200 status
+	var e errResp
+	if err = utils.Json.Unmarshal(res.Body(), &e); err == nil && e.Status != nil && !*e.Status {
+		return errors.Errorf("sabnzbd api error: %s", e.Error)
+	}
+	if resp == nil {
+		return nil
+	}
+	return utils.Json.Unmarshal(res.Body(), resp)
+}
+
+func (s *SABnzbd) Init() (string, error) {
+	s.ready = false
+	s.url = strings.TrimSuffix(setting.GetStr(conf.SabnzbdUrl), "/")
+	s.apiKey = setting.GetStr(conf.SabnzbdApiKey)
+	var resp struct {
+		Version string `json:"version"`
+	}
+	// the version api doesn't require the key, the queue one does
+	if err := s.request("version", nil, &resp); err != nil {
+		return "", errors.Wrap(err, "failed get sabnzbd version")
+	}
+	if err := s.request("queue", map[string]string{"limit": "1"}, nil); err != nil {
+		return "", errors.Wrap(err, "failed check sabnzbd api key")
+	}
+	s.ready = true
+	return fmt.Sprintf("sabnzbd version: %s", resp.Version), nil
+}
+
+func (s *SABnzbd) IsReady() bool {
+	return s.ready
+}
+
+// AddURL lets sabnzbd fetch the nzb file, the downloaded files stay in the complete folder of sabnzbd
+// until they are transferred
+func (s *SABnzbd) AddURL(args *tool.AddUrlArgs) (string, error) {
+	var resp struct {
+		NzoIds []string `json:"nzo_ids"`
+	}
+	params := map[string]string{
+		"name":    args.Url,
+		"nzbname": args.UID,
+	}
+	if cat := setting.GetStr(conf.SabnzbdCategory); cat != "" {
+		params["cat"] = cat
+	}
+	if err := s.request("addurl", params, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.NzoIds) == 0 {
+		return "", fmt.Errorf("failed get nzo id")
+	}
+	return resp.NzoIds[0], nil
+}
+
+func (s *SABnzbd) Remove(task *tool.DownloadTask) error {
+	params := map[string]string{
+		"name":      "delete",
+		"value":     task.GID,
+		"del_files": "1",
+	}
+	if err := s.request("queue", params, nil); err != nil {
+		return err
+	}
+	return s.request("history", params, nil)
+}
+
+func (s *SABnzbd) Status(task *tool.DownloadTask) (*tool.Status, error) {
+	var queue struct {
+		Queue struct {
+			Slots []queueSlot `json:"slots"`
+		} `json:"queue"`
+	}
+	if err := s.request("queue", map[string]string{"nzo_ids": task.GID}, &queue); err != nil {
+		return nil, err
+	}
+	if len(queue.Queue.Slots) > 0 {
+		slot := queue.Queue.Slots[0]
+		progress, _ := strconv.ParseFloat(slot.Percentage, 64)
+		mb, _ := strconv.ParseFloat(slot.Mb, 64)
+		return &tool.Status{
+			Progress:   progress,
+			TotalBytes: int64(mb * 1024 * 1024),
+			Status:     slot.Status,
+		}, nil
+	}
+	// the finished jobs, including the post-processing ones, are in the history
+	var history struct {
+		History struct {
+			Slots []historySlot `json:"slots"`
+		} `json:"history"`
+	}
+	if err := s.request("history", map[string]string{"nzo_ids": task.GID}, &history); err != nil {
+		return nil, err
+	}
+	if len(history.History.Slots) == 0 {
+		return nil, fmt.Errorf("failed get status, wrong nzo id: %s", task.GID)
+	}
+	slot := history.History.Slots[0]
+	st := &tool.Status{
+		Progress:   100,
+		TotalBytes: slot.Bytes,
+		Status:     slot.Status,
+	}
+	switch slot.Status {
+	case "Completed":
+		st.Completed = true
+		// transfer the job folder in the complete folder of sabnzbd
+		task.TempDir = slot.Storage
+	case "Failed":
+		st.Err = errors.Errorf("[sabnzbd] failed to download %s: %s", task.GID, slot.FailMessage)
+	}
+	return st, nil
+}
+
+var _ tool.Tool = (*SABnzbd)(nil)
+
+func init() {
+	tool.Tools.Add(&SABnzbd{})
+}
@@ -2,6 +2,7 @@ package tool
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 	stdpath "path"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	_115_open "github.com/OpenListTeam/OpenList/v4/drivers/115_open"
 	_123 "github.com/OpenListTeam/OpenList/v4/drivers/123"
 	_123_open "github.com/OpenListTeam/OpenList/v4/drivers/123_open"
+	"github.com/OpenListTeam/OpenList/v4/drivers/local"
 	"github.com/OpenListTeam/OpenList/v4/drivers/pikpak"
 	"github.com/OpenListTeam/OpenList/v4/drivers/thunder"
 	"github.com/OpenListTeam/OpenList/v4/drivers/thunder_browser"
@@ -18,6 +20,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
@@ -36,11 +39,64 @@ const (
 	UploadDownloadStream  DeletePolicy = "upload_download_stream"
 )
 
+// TempPolicy is where the tools downloading to the local disk stage the downloads
+type TempPolicy string
+
+const (
+	TempDefault TempPolicy = ""         // the temp path setting of the tool
+	TempShared  TempPolicy = "temp_dir" // the shared temp dir
+	TempStorage TempPolicy = "storage"  // the temp path of the task, in a local storage
+)
+
+// tempPathSettings are the settings of the default temp paths of the tools downloading to the local disk
+var tempPathSettings = map[string]string{
+	"aria2":        conf.Aria2TempPath,
+	"qBittorrent":  conf.QbittorrentTempPath,
+	"Transmission": conf.TransmissionTempPath,
+	"SimpleHttp":   conf.SimpleHttpTempPath,
+}
+
 type AddURLArgs struct {
 	URL          string
 	DstDirPath   string
 	Tool         string
 	DeletePolicy DeletePolicy
+	TempPolicy   TempPolicy
+	TempPath     string
+	// Header is sent with the requests of the url, only SimpleHttp supports it
+	Header http.Header
+}
+
+// localTempDir returns the local dir where the tool stages the download, under the shared temp dir
+// or under the root folder of a local storage, so that big downloads can be staged on an attached disk
+func localTempDir(toolName string, policy TempPolicy, tempPath, uid string) (string, error) {
+	switch policy {
+	case TempDefault:
+		tempPath = ""
+		if key, ok := tempPathSettings[toolName]; ok {
+			tempPath = setting.GetStr(key)
+		}
+	case TempShared:
+		tempPath = ""
+	case TempStorage:
+		if tempPath == "" {
+			return "", errors.New("temp path is required")
+		}
+	default:
+		return "", errors.Errorf("invalid temp policy: %s", policy)
+	}
+	if tempPath == "" {
+		return filepath.Join(conf.Conf.TempDir, toolName, uid), nil
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(tempPath)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get temp storage")
+	}
+	l, ok := storage.(*local.Local)
+	if !ok {
+		return "", errors.Errorf("temp path [%s] is not in a local storage", tempPath)
+	}
+	return filepath.Join(l.GetRootPath(), actualPath, toolName, uid), nil
 }
 
 func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, error) {
@@ -65,8 +121,11 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, erro
 			return nil, errors.WithStack(errs.NotFolder)
 		}
 	}
-	// try putting url
-	if args.Tool == "SimpleHttp" {
+	if len(args.Header) > 0 && args.Tool != "SimpleHttp" {
+		return nil, errors.WithMessagef(errs.NotSupport, "%s can't send custom headers", args.Tool)
+	}
+	// try putting url, the storage fetching the url itself can't send the custom headers
+	if args.Tool == "SimpleHttp" && len(args.Header) == 0 {
 		err = tryPutUrl(ctx, args.DstDirPath, args.URL)
 		if err == nil || !errors.Is(err, errs.NotImplement) {
 			return nil, err
@@ -87,7 +146,10 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, erro
 	}
 
 	uid := uuid.NewString()
-	tempDir := filepath.Join(conf.Conf.TempDir, args.Tool, uid)
+	tempDir, err := localTempDir(args.Tool, args.TempPolicy, args.TempPath, uid)
+	if err != nil {
+		return nil, err
+	}
 	deletePolicy := args.DeletePolicy
 
 	// 如果当前 storage 是对应网盘，则直接下载到目标路径，无需转存
@@ -147,10 +209,14 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, erro
 	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User) // taskCreator is nil when convert failed
 	t := &DownloadTask{
 		TaskExtension: task.TaskExtension{
-			Creator: taskCreator,
-			ApiUrl:  common.GetApiUrl(ctx),
+			Creator:    taskCreator,
+			ApiUrl:     common.GetApiUrl(ctx),
+			RequestID:  net.RequestID(ctx),
+			Priority:   task.CtxPriority(ctx),
+			SpeedLimit: task.CtxSpeedLimit(ctx),
 		},
 		Url:          args.URL,
+		Header:       args.Header,
 		DstDirPath:   args.DstDirPath,
 		TempDir:      tempDir,
 		DeletePolicy: deletePolicy,
@@ -2,6 +2,7 @@ package tool
 
 import (
 	"fmt"
+	"net/http"
 	"path"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 type DownloadTask struct {
 	task.TaskExtension
 	Url               string       `json:"url"`
+	Header            http.Header  `json:"header,omitempty"`
 	DstDirPath        string       `json:"dst_dir_path"`
 	TempDir           string       `json:"temp_dir"`
 	DeletePolicy      DeletePolicy `json:"delete_policy"`
@@ -30,9 +32,16 @@ type DownloadTask struct {
 	GID               string       `json:"-"`
 	tool              Tool
 	callStatusRetried int
+	// lastStatus is the last status of the tool recorded into the log lines
+	lastStatus string
 }
 
 func (t *DownloadTask) Run() error {
+	release, err := t.WaitSlot(DownloadTaskSlots)
+	if err != nil {
+		return err
+	}
+	defer release()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
@@ -43,6 +52,7 @@ func (t *DownloadTask) Run() error {
 		}
 		t.tool = tool
 	}
+	t.Logf("downloading %s by %s", t.Url, t.tool.Name())
 	if err := t.tool.Run(t); !errs.IsNotSupportError(err) {
 		if err == nil {
 			return t.Transfer()
@@ -63,6 +73,7 @@ func (t *DownloadTask) Run() error {
 		return err
 	}
 	t.GID = gid
+	t.Logf("added to %s as %s", t.tool.Name(), gid)
 	var ok bool
 outer:
 	for {
@@ -118,6 +129,7 @@ outer:
 		seedTime := setting.GetInt(conf.QbittorrentSeedtime, 0)
 		if seedTime >= 0 {
 			t.Status = "offline download completed, waiting for seeding"
+			t.Logf("seeding for %d minutes", seedTime)
 			<-time.After(time.Minute * time.Duration(seedTime))
 			err := t.tool.Remove(t)
 			if err != nil {
@@ -131,6 +143,7 @@ outer:
 		seedTime := setting.GetInt(conf.TransmissionSeedtime, 0)
 		if seedTime >= 0 {
 			t.Status = "offline download completed, waiting for seeding"
+			t.Logf("seeding for %d minutes", seedTime)
 			<-time.After(time.Minute * time.Duration(seedTime))
 			err := t.tool.Remove(t)
 			if err != nil {
@@ -147,6 +160,7 @@ func (t *DownloadTask) Update() (bool, error) {
 	if err != nil {
 		t.callStatusRetried++
 		log.Errorf("failed to get status of %s, retried %d times", t.ID, t.callStatusRetried)
+		t.Logf("failed to get the status from %s, retried %d times: %v", t.tool.Name(), t.callStatusRetried, err)
 		return false, nil
 	}
 	if t.callStatusRetried > 5 {
@@ -156,13 +170,19 @@ func (t *DownloadTask) Update() (bool, error) {
 	t.SetProgress(info.Progress)
 	t.SetTotalBytes(info.TotalBytes)
 	t.Status = fmt.Sprintf("[%s]: %s", t.tool.Name(), info.Status)
+	if t.Status != t.lastStatus {
+		t.lastStatus = t.Status
+		t.Logf("%s", t.Status)
+	}
 	if info.NewGID != "" {
 		log.Debugf("followen by: %+v", info.NewGID)
+		t.Logf("followed by %s", info.NewGID)
 		t.GID = info.NewGID
 		return false, nil
 	}
 	// if download completed
 	if info.Completed {
+		t.Logf("downloaded, transferring to %s", t.DstDirPath)
 		err := t.Transfer()
 		return true, errors.WithMessage(err, "failed to transfer file")
 	}
@@ -191,8 +211,11 @@ func (t *DownloadTask) Transfer() error {
 		tsk := &TransferTask{
 			TaskData: fs.TaskData{
 				TaskExtension: task.TaskExtension{
-					Creator: taskCreator,
-					ApiUrl:  t.ApiUrl,
+					Creator:    taskCreator,
+					ApiUrl:     t.ApiUrl,
+					RequestID:  t.RequestID,
+					Priority:   t.Priority,
+					SpeedLimit: t.SpeedLimit,
 				},
 				SrcActualPath: t.TempDir,
 				DstActualPath: dstDirActualPath,
@@ -201,11 +224,13 @@ func (t *DownloadTask) Transfer() error {
 			},
 			DeletePolicy: t.DeletePolicy,
 			Url:          t.Url,
+			Header:       t.Header,
 		}
 		tsk.SetTotalBytes(t.GetTotalBytes())
 		tsk.groupID = path.Join(tsk.DstStorageMp, tsk.DstActualPath)
 		task_group.TransferCoordinator.AddTask(tsk.groupID, nil)
 		TransferTaskManager.Add(tsk)
+		t.Logf("added the transfer task %s", tsk.GetID())
 		return nil
 	}
 	return transferStd(t.Ctx(), t.TempDir, t.DstDirPath, t.DeletePolicy)
@@ -219,4 +244,7 @@ func (t *DownloadTask) GetStatus() string {
 	return t.Status
 }
 
-var DownloadTaskManager *tache.Manager[*DownloadTask]
+var (
+	DownloadTaskManager *tache.Manager[*DownloadTask]
+	DownloadTaskSlots   *task.Slots
+)
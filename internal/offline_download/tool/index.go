@@ -0,0 +1,206 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+)
+
+type IndexSource string
+
+const (
+	IndexHTML     IndexSource = "index"    // an apache or nginx style index page
+	IndexOpenList IndexSource = "openlist" // another openlist instance, listed with its api
+)
+
+const (
+	// maxIndexDepth and maxIndexFiles bound the scraping of a remote tree
+	maxIndexDepth = 16
+	maxIndexFiles = 10000
+)
+
+// IndexFile is a file of a remote tree, Dir is the path of its folder relative to the root of the tree
+type IndexFile struct {
+	Url string
+	Dir string
+}
+
+// ScrapeIndex walks the remote tree at rawUrl and returns its files
+func ScrapeIndex(ctx context.Context, rawUrl string, source IndexSource) ([]IndexFile, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid index url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("index url must be http or https")
+	}
+	var files []IndexFile
+	switch source {
+	case IndexHTML, "":
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		err = scrapeHTMLIndex(ctx, u, "", 0, &files)
+	case IndexOpenList:
+		origin := &url.URL{Scheme: u.Scheme, Host: u.Host}
+		err = scrapeOpenList(ctx, origin, utils.FixAndCleanPath(u.Path), "", 0, &files)
+	default:
+		return nil, errors.Errorf("invalid index source: %s", source)
+	}
+	return files, err
+}
+
+// indexLinks returns the hrefs of the anchors of the page
+func indexLinks(ctx context.Context, u *url.URL) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", base.UserAgent)
+	resp, err := base.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed get index %s: http status code %d", u, resp.StatusCode)
+	}
+	var links []string
+	z := html.NewTokenizer(resp.Body)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), context.Canceled) || errors.Is(z.Err(), context.DeadlineExceeded) {
+				return nil, z.Err()
+			}
+			return links, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			if t.Data != "a" {
+				continue
+			}
+			for _, attr := range t.Attr {
+				if attr.Key == "href" {
+					links = append(links, attr.Val)
+				}
+			}
+		}
+	}
+}
+
+// indexChildren returns the urls of the entries under the page u, the folders end with a slash,
+// which skips the parent, the sorting and the absolute links of the index pages
+func indexChildren(u *url.URL, links []string) []*url.URL {
+	var children []*url.URL
+	seen := make(map[string]struct{})
+	for _, link := range links {
+		ref, err := url.Parse(link)
+		if err != nil || ref.RawQuery != "" {
+			continue
+		}
+		child := u.ResolveReference(ref)
+		child.Fragment = ""
+		if child.Host != u.Host || !strings.HasPrefix(child.Path, u.Path) {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(child.Path, u.Path), "/")
+		if !validIndexName(name) {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		children = append(children, child)
+	}
+	return children
+}
+
+// validIndexName reports whether the name of a remote entry is a single path segment,
+// the names are joined to the dir the files are downloaded to
+func validIndexName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\")
+}
+
+func scrapeHTMLIndex(ctx context.Context, u *url.URL, dir string, depth int, files *[]IndexFile) error {
+	if depth > maxIndexDepth {
+		return nil
+	}
+	links, err := indexLinks(ctx, u)
+	if err != nil {
+		return err
+	}
+	for _, child := range indexChildren(u, links) {
+		if strings.HasSuffix(child.Path, "/") {
+			name := stdpath.Base(child.Path)
+			if err := scrapeHTMLIndex(ctx, child, stdpath.Join(dir, name), depth+1, files); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(*files) >= maxIndexFiles {
+			return errors.Errorf("too many files, at most %d", maxIndexFiles)
+		}
+		*files = append(*files, IndexFile{Url: child.String(), Dir: dir})
+	}
+	return nil
+}
+
+type openListObj struct {
+	Name  string `json:"name"`
+	IsDir bool   `json:"is_dir"`
+	Sign  string `json:"sign"`
+}
+
+func scrapeOpenList(ctx context.Context, origin *url.URL, path, dir string, depth int, files *[]IndexFile) error {
+	if depth > maxIndexDepth {
+		return nil
+	}
+	var resp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Content []openListObj `json:"content"`
+		} `json:"data"`
+	}
+	_, err := base.RestyClient.R().
+		SetContext(ctx).
+		SetBody(base.Json{"path": path, "page": 1, "per_page": 0}).
+		SetResult(&resp).
+		Post(origin.String() + "/api/fs/list")
+	if err != nil {
+		return err
+	}
+	if resp.Code != 200 {
+		return errors.Errorf("failed list %s: %s", path, resp.Message)
+	}
+	for _, obj := range resp.Data.Content {
+		if !validIndexName(obj.Name) {
+			continue
+		}
+		objPath := stdpath.Join(path, obj.Name)
+		if obj.IsDir {
+			if err := scrapeOpenList(ctx, origin, objPath, stdpath.Join(dir, obj.Name), depth+1, files); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(*files) >= maxIndexFiles {
+			return errors.Errorf("too many files, at most %d", maxIndexFiles)
+		}
+		fileUrl := origin.String() + "/d" + utils.EncodePath(objPath, true)
+		if obj.Sign != "" {
+			fileUrl += "?sign=" + obj.Sign
+		}
+		*files = append(*files, IndexFile{Url: fileUrl, Dir: dir})
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package tool
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestIndexChildren(t *testing.T) {
+	u, _ := url.Parse("http://example.com/pub/")
+	links := []string{"../", "..%2F", "a%5Cb.txt", "?C=N;O=D", "/other/x", "http://other.com/pub/y", "docs/", "a%20b.txt", "a%20b.txt#top", "/pub/", "/pub/c.pdf"}
+	var got []string
+	for _, child := range indexChildren(u, links) {
+		got = append(got, child.String())
+	}
+	want := []string{"http://example.com/pub/docs/", "http://example.com/pub/a%20b.txt", "http://example.com/pub/c.pdf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("indexChildren() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type MagnetFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+type MagnetInfo struct {
+	Name  string       `json:"name"`
+	Size  int64        `json:"size"`
+	Files []MagnetFile `json:"files"`
+}
+
+// MagnetResolver is a tool able to fetch the metadata of a magnet link without downloading its files,
+// the tool should remove whatever it has added before returning
+type MagnetResolver interface {
+	ResolveMagnet(ctx context.Context, args *AddUrlArgs) (*MagnetInfo, error)
+}
+
+// magnetPollInterval is the interval to check whether the metadata is fetched
+const magnetPollInterval = time.Second
+
+// WaitMagnet calls check every magnetPollInterval until it returns the metadata or an error
+func WaitMagnet(ctx context.Context, check func() (*MagnetInfo, error)) (*MagnetInfo, error) {
+	for {
+		info, err := check()
+		if err != nil || info != nil {
+			return info, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "timed out fetching the metadata")
+		case <-time.After(magnetPollInterval):
+		}
+	}
+}
+
+// ResolveMagnet fetches the name, size and files of the magnet link with the tool
+func ResolveMagnet(ctx context.Context, toolName, magnet string) (*MagnetInfo, error) {
+	if !strings.HasPrefix(strings.ToLower(magnet), "magnet:?") {
+		return nil, errors.New("not a magnet link")
+	}
+	tool, err := Tools.Get(toolName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get offline download tool")
+	}
+	resolver, ok := tool.(MagnetResolver)
+	if !ok {
+		return nil, errors.WithMessagef(errs.NotSupport, "%s can't resolve magnet links", toolName)
+	}
+	if !tool.IsReady() {
+		if _, err := tool.Init(); err != nil {
+			return nil, errors.Wrapf(err, "failed init offline download tool %s", toolName)
+		}
+	}
+	tempDir := filepath.Join(conf.Conf.TempDir, toolName, "magnet-"+uuid.NewString())
+	defer os.RemoveAll(tempDir)
+	return resolver.ResolveMagnet(ctx, &AddUrlArgs{
+		Url:     magnet,
+		UID:     uuid.NewString(),
+		TempDir: tempDir,
+	})
+}
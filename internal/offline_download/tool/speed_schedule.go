@@ -0,0 +1,138 @@
+package tool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// SpeedLimiter is a tool whose download speed can be limited
+type SpeedLimiter interface {
+	// SetDownloadLimit sets the overall download speed of the tool in bytes per second, 0 for unlimited
+	SetDownloadLimit(limit int64) error
+}
+
+// DownloadLimit limits the downloads of the built-in downloader
+var DownloadLimit stream.Limiter = stream.BlockBurstLimiter{Limiter: rate.NewLimiter(rate.Inf, 0)}
+
+type speedRule struct {
+	start, end int // minutes of the day, the rule applies in [start, end), over midnight if end <= start
+	limit      int64
+}
+
+func (r speedRule) contains(minute int) bool {
+	if r.start < r.end {
+		return minute >= r.start && minute < r.end
+	}
+	return minute >= r.start || minute < r.end
+}
+
+func parseMinute(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time: %s", s)
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid time: %s", s)
+	}
+	minute, err := strconv.Atoi(m)
+	if err != nil || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("invalid time: %s", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// parseSpeedSchedule parses the lines like `08:00-23:00 2048`, the KB/s limit during the time of the day,
+// the first matching line wins and the speed is unlimited out of the lines
+func parseSpeedSchedule(s string) ([]speedRule, error) {
+	var rules []speedRule
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid speed schedule: %s", line)
+		}
+		from, to, ok := strings.Cut(fields[0], "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid speed schedule: %s", line)
+		}
+		start, err := parseMinute(from)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseMinute(to)
+		if err != nil {
+			return nil, err
+		}
+		limit, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid speed limit: %s", fields[1])
+		}
+		rules = append(rules, speedRule{start: start, end: end, limit: limit * 1024})
+	}
+	return rules, nil
+}
+
+// speedLimitAt returns the limit in bytes per second at t, 0 for unlimited
+func speedLimitAt(rules []speedRule, t time.Time) int64 {
+	minute := t.Hour()*60 + t.Minute()
+	for _, r := range rules {
+		if r.contains(minute) {
+			return r.limit
+		}
+	}
+	return 0
+}
+
+var (
+	speedMu sync.Mutex
+	// appliedSpeeds is the limit last pushed to each tool
+	appliedSpeeds = make(map[string]int64)
+)
+
+// ApplySpeedSchedule pushes the current limit of the speed schedule to the tools and the built-in downloader
+func ApplySpeedSchedule() {
+	rules, err := parseSpeedSchedule(setting.GetStr(conf.OfflineDownloadSpeedSchedule))
+	if err != nil {
+		log.Errorf("failed parse offline download speed schedule: %+v", err)
+		return
+	}
+	limit := speedLimitAt(rules, time.Now())
+	speedMu.Lock()
+	defer speedMu.Unlock()
+	if limit > 0 {
+		DownloadLimit.SetLimit(rate.Limit(limit))
+		DownloadLimit.SetBurst(int(limit))
+	} else {
+		DownloadLimit.SetLimit(rate.Inf)
+		DownloadLimit.SetBurst(0)
+	}
+	for name, t := range Tools {
+		l, ok := t.(SpeedLimiter)
+		if !ok || !t.IsReady() {
+			continue
+		}
+		applied, ok := appliedSpeeds[name]
+		// the limits of the tools are left alone without a schedule
+		if (ok && applied == limit) || (!ok && len(rules) == 0) {
+			continue
+		}
+		if err := l.SetDownloadLimit(limit); err != nil {
+			log.Warnf("failed set the download speed of %s: %+v", name, err)
+			continue
+		}
+		appliedSpeeds[name] = limit
+	}
+}
@@ -0,0 +1,35 @@
+package tool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpeedLimitAt(t *testing.T) {
+	rules, err := parseSpeedSchedule("# days\n08:00-23:00 2048\n23:00-01:30 512\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		at   string
+		want int64
+	}{
+		{"07:59", 0},
+		{"08:00", 2048 * 1024},
+		{"22:59", 2048 * 1024},
+		{"23:00", 512 * 1024},
+		{"01:29", 512 * 1024},
+		{"01:30", 0},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse("15:04", tt.at)
+		if got := speedLimitAt(rules, at); got != tt.want {
+			t.Errorf("speedLimitAt(%s) = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+	for _, s := range []string{"08:00 2048", "08:00-25:00 1", "08:00-09:00 -1", "8-9 1"} {
+		if _, err := parseSpeedSchedule(s); err == nil {
+			t.Errorf("parseSpeedSchedule(%q) should fail", s)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package tool
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path"
 	stdpath "path"
@@ -12,6 +13,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
@@ -28,10 +30,20 @@ type TransferTask struct {
 	fs.TaskData
 	DeletePolicy DeletePolicy `json:"delete_policy"`
 	Url          string       `json:"url"`
+	Header       http.Header  `json:"header,omitempty"`
 	groupID      string       `json:"-"`
 }
 
+// putCtx is the ctx of the puts of the transfer, which aren't counted as the uploads of the user
+func (t *TransferTask) putCtx() context.Context {
+	return context.WithValue(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), conf.ServerPutKey, struct{}{})
+}
+
 func (t *TransferTask) Run() error {
+	t.SetSpeedLimitType("offline_download_transfer")
+	if err := t.WaitStorages(); err != nil {
+		return err
+	}
 	if t.SrcStorage == nil && t.SrcStorageMp != "" {
 		if srcStorage, _, err := op.GetStorageAndActualPath(t.SrcStorageMp); err == nil {
 			t.SrcStorage = srcStorage
@@ -46,12 +58,21 @@ func (t *TransferTask) Run() error {
 			}
 		}
 	}
+	if err := t.WaitResume(); err != nil {
+		return err
+	}
+	release, err := t.WaitSlot(TransferTaskSlots)
+	if err != nil {
+		return err
+	}
+	defer release()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
+	t.Logf("transferring to [%s](%s)", t.DstStorageMp, t.DstActualPath)
 	if t.SrcStorage == nil {
 		if t.DeletePolicy == UploadDownloadStream {
-			rr, err := stream.GetRangeReaderFromLink(t.GetTotalBytes(), &model.Link{URL: t.Url})
+			rr, err := stream.GetRangeReaderFromLink(t.GetTotalBytes(), &model.Link{URL: t.Url, Header: t.Header})
 			if err != nil {
 				return err
 			}
@@ -73,7 +94,7 @@ func (t *TransferTask) Run() error {
 				Mimetype: mimetype,
 				Closers:  utils.NewClosers(r),
 			}
-			return op.Put(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), t.DstStorage, t.DstActualPath, s, t.SetProgress)
+			return op.Put(t.putCtx(), t.DstStorage, t.DstActualPath, s, t.PausableProgress(t.SetProgress, t.StoragesPaused))
 		}
 		return transferStdPath(t)
 	}
@@ -121,8 +142,15 @@ func (t *TransferTask) SetRetry(retry int, maxRetry int) {
 
 var (
 	TransferTaskManager *tache.Manager[*TransferTask]
+	TransferTaskSlots   *task.Slots
 )
 
+func init() {
+	op.RegisterStorageTasksFunc(func(mountPath string) []string {
+		return fs.ActiveStorageTasks(TransferTaskManager.GetAll(), mountPath)
+	})
+}
+
 func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy DeletePolicy) error {
 	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
@@ -137,8 +165,11 @@ func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy D
 		t := &TransferTask{
 			TaskData: fs.TaskData{
 				TaskExtension: task.TaskExtension{
-					Creator: taskCreator,
-					ApiUrl:  common.GetApiUrl(ctx),
+					Creator:    taskCreator,
+					ApiUrl:     common.GetApiUrl(ctx),
+					RequestID:  net.RequestID(ctx),
+					Priority:   task.CtxPriority(ctx),
+					SpeedLimit: task.CtxSpeedLimit(ctx),
 				},
 				SrcActualPath: stdpath.Join(tempDir, entry.Name()),
 				DstActualPath: dstDirActualPath,
@@ -173,8 +204,11 @@ func transferStdPath(t *TransferTask) error {
 			task := &TransferTask{
 				TaskData: fs.TaskData{
 					TaskExtension: task.TaskExtension{
-						Creator: t.Creator,
-						ApiUrl:  t.ApiUrl,
+						Creator:    t.Creator,
+						ApiUrl:     t.ApiUrl,
+						RequestID:  t.RequestID,
+						Priority:   t.Priority,
+						SpeedLimit: t.SpeedLimit,
 					},
 					SrcActualPath: srcRawPath,
 					DstActualPath: dstDirActualPath,
@@ -189,6 +223,7 @@ func transferStdPath(t *TransferTask) error {
 			TransferTaskManager.Add(task)
 		}
 		t.Status = "src object is dir, added all transfer tasks of files"
+		t.Logf("added the transfer tasks of %d files", len(entries))
 		return nil
 	}
 	return transferStdFile(t)
@@ -217,7 +252,7 @@ func transferStdFile(t *TransferTask) error {
 		Closers:  utils.NewClosers(rc),
 	}
 	t.SetTotalBytes(info.Size())
-	return op.Put(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), t.DstStorage, t.DstActualPath, s, t.SetProgress)
+	return op.Put(t.putCtx(), t.DstStorage, t.DstActualPath, s, t.PausableProgress(t.SetProgress, t.StoragesPaused))
 }
 
 func removeStdTemp(t *TransferTask) {
@@ -248,8 +283,11 @@ func transferObj(ctx context.Context, tempDir, dstDirPath string, deletePolicy D
 		t := &TransferTask{
 			TaskData: fs.TaskData{
 				TaskExtension: task.TaskExtension{
-					Creator: taskCreator,
-					ApiUrl:  common.GetApiUrl(ctx),
+					Creator:    taskCreator,
+					ApiUrl:     common.GetApiUrl(ctx),
+					RequestID:  net.RequestID(ctx),
+					Priority:   task.CtxPriority(ctx),
+					SpeedLimit: task.CtxSpeedLimit(ctx),
 				},
 				SrcActualPath: stdpath.Join(srcObjActualPath, obj.GetName()),
 				DstActualPath: dstDirActualPath,
@@ -290,8 +328,11 @@ func transferObjPath(t *TransferTask) error {
 			TransferTaskManager.Add(&TransferTask{
 				TaskData: fs.TaskData{
 					TaskExtension: task.TaskExtension{
-						Creator: t.Creator,
-						ApiUrl:  t.ApiUrl,
+						Creator:    t.Creator,
+						ApiUrl:     t.ApiUrl,
+						RequestID:  t.RequestID,
+						Priority:   t.Priority,
+						SpeedLimit: t.SpeedLimit,
 					},
 					SrcActualPath: srcObjPath,
 					DstActualPath: dstDirActualPath,
@@ -305,6 +346,7 @@ func transferObjPath(t *TransferTask) error {
 			})
 		}
 		t.Status = "src object is dir, added all transfer tasks of objs"
+		t.Logf("added the transfer tasks of %d objs", len(objs))
 		return nil
 	}
 	return transferObjFile(t)
@@ -329,7 +371,7 @@ func transferObjFile(t *TransferTask) error {
 		return errors.WithMessagef(err, "failed get [%s] stream", t.SrcActualPath)
 	}
 	t.SetTotalBytes(ss.GetSize())
-	return op.Put(context.WithValue(t.Ctx(), conf.SkipHookKey, struct{}{}), t.DstStorage, t.DstActualPath, ss, t.SetProgress)
+	return op.Put(t.putCtx(), t.DstStorage, t.DstActualPath, ss, t.PausableProgress(t.SetProgress, t.StoragesPaused))
 }
 
 func removeObjTemp(t *TransferTask) {
@@ -0,0 +1,137 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/sign"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// watchProcessedDir and watchFailedDir are the folders under a watch folder
+	// where the picked up control files are moved to
+	watchProcessedDir = "processed"
+	watchFailedDir    = "failed"
+)
+
+// watchExts are the control files picked up from the watch folders
+var watchExts = []string{"torrent", "nzb"}
+
+type watchRule struct {
+	watchDir string
+	dstDir   string
+	tool     string
+}
+
+// parseWatchRules parses the lines like `/nas/watch/movies | /nas/movies | qBittorrent`,
+// the control files dropped into the first path are downloaded into the second one by the tool
+func parseWatchRules(s string) ([]watchRule, error) {
+	var rules []watchRule
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid watch rule: %s", line)
+		}
+		rule := watchRule{
+			watchDir: utils.FixAndCleanPath(strings.TrimSpace(fields[0])),
+			dstDir:   utils.FixAndCleanPath(strings.TrimSpace(fields[1])),
+			tool:     strings.TrimSpace(fields[2]),
+		}
+		if rule.watchDir == "/" || utils.IsSubPath(rule.watchDir, rule.dstDir) {
+			return nil, fmt.Errorf("invalid watch rule: %s", line)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// watchApiUrl is the url the tools fetch the control files from
+func watchApiUrl() string {
+	if strings.HasPrefix(conf.Conf.SiteURL, "http") {
+		return strings.TrimSuffix(conf.Conf.SiteURL, "/")
+	}
+	return strings.TrimSuffix(fmt.Sprintf("http://127.0.0.1:%d%s", conf.Conf.Scheme.HttpPort, conf.Conf.SiteURL), "/")
+}
+
+// ScanWatchFolders adds the offline download tasks of the control files in the watch folders
+func ScanWatchFolders() {
+	rules, err := parseWatchRules(setting.GetStr(conf.OfflineDownloadWatchRules))
+	if err != nil {
+		log.Errorf("failed parse offline download watch rules: %+v", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+	admin, err := op.GetAdmin()
+	if err != nil {
+		log.Errorf("failed get admin for the watch folders: %+v", err)
+		return
+	}
+	apiUrl := watchApiUrl()
+	ctx := context.WithValue(context.Background(), conf.UserKey, admin)
+	ctx = context.WithValue(ctx, conf.ApiUrlKey, apiUrl)
+	for _, rule := range rules {
+		if err := scanWatchFolder(ctx, rule, apiUrl); err != nil {
+			log.Errorf("failed scan watch folder [%s]: %+v", rule.watchDir, err)
+		}
+	}
+}
+
+func scanWatchFolder(ctx context.Context, rule watchRule, apiUrl string) error {
+	objs, err := fs.List(ctx, rule.watchDir, &fs.ListArgs{NoLog: true, Refresh: true})
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if obj.IsDir() || !utils.SliceContains(watchExts, utils.Ext(obj.GetName())) {
+			continue
+		}
+		srcPath := stdpath.Join(rule.watchDir, obj.GetName())
+		// the control file is moved before the task is added, the tool fetches it from the processed folder
+		processedDir := stdpath.Join(rule.watchDir, watchProcessedDir)
+		if err := moveControlFile(ctx, srcPath, processedDir); err != nil {
+			return err
+		}
+		filePath := stdpath.Join(processedDir, obj.GetName())
+		url := apiUrl + "/d" + utils.EncodePath(filePath, true) + "?sign=" + sign.Sign(filePath)
+		_, err := AddURL(ctx, &AddURLArgs{
+			URL:          url,
+			DstDirPath:   rule.dstDir,
+			Tool:         rule.tool,
+			DeletePolicy: DeleteOnUploadSucceed,
+		})
+		if err == nil {
+			log.Infof("added offline download of [%s] into [%s]", srcPath, rule.dstDir)
+			continue
+		}
+		log.Errorf("failed add offline download of [%s]: %+v", srcPath, err)
+		if err := moveControlFile(ctx, filePath, stdpath.Join(rule.watchDir, watchFailedDir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func moveControlFile(ctx context.Context, srcPath, dstDir string) error {
+	if err := fs.MakeDir(ctx, dstDir); err != nil {
+		return errors.WithMessagef(err, "failed make dir [%s]", dstDir)
+	}
+	if _, err := fs.Move(ctx, srcPath, dstDir); err != nil {
+		return errors.WithMessagef(err, "failed move [%s] to [%s]", srcPath, dstDir)
+	}
+	return nil
+}
@@ -37,6 +37,7 @@ func (t *Transmission) Items() []model.SettingItem {
 	return []model.SettingItem{
 		{Key: conf.TransmissionUri, Value: "http://localhost:9091/transmission/rpc", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 		{Key: conf.TransmissionSeedtime, Value: "0", Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.TransmissionTempPath, Value: "", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE, Help: `the path of a local storage where the downloads are staged by default, empty to use the temp dir`},
 	}
 }
 
@@ -170,7 +171,64 @@ func (t *Transmission) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	return s, nil
 }
 
+// ResolveMagnet adds the magnet link and removes it with its data once the metadata is fetched
+func (t *Transmission) ResolveMagnet(ctx context.Context, args *tool.AddUrlArgs) (*tool.MagnetInfo, error) {
+	torrent, err := t.client.TorrentAdd(ctx, transmissionrpc.TorrentAddPayload{
+		DownloadDir: &args.TempDir,
+		Filename:    &args.Url,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if torrent.ID == nil {
+		return nil, fmt.Errorf("failed get torrent ID")
+	}
+	id := *torrent.ID
+	defer func() {
+		_ = t.client.TorrentRemove(context.WithoutCancel(ctx), transmissionrpc.TorrentRemovePayload{
+			IDs:             []int64{id},
+			DeleteLocalData: true,
+		})
+	}()
+	return tool.WaitMagnet(ctx, func() (*tool.MagnetInfo, error) {
+		infos, err := t.client.TorrentGetAllFor(ctx, []int64{id})
+		if err != nil {
+			return nil, err
+		}
+		if len(infos) < 1 {
+			return nil, fmt.Errorf("failed get status, wrong id: %d", id)
+		}
+		info := infos[0]
+		if info.MetadataPercentComplete == nil || *info.MetadataPercentComplete < 1 {
+			return nil, nil
+		}
+		res := &tool.MagnetInfo{}
+		if info.Name != nil {
+			res.Name = *info.Name
+		}
+		if info.TotalSize != nil {
+			res.Size = int64(*info.TotalSize / 8)
+		}
+		for _, f := range info.Files {
+			res.Files = append(res.Files, tool.MagnetFile{Path: f.Name, Size: f.Length})
+		}
+		return res, nil
+	})
+}
+
+func (t *Transmission) SetDownloadLimit(limit int64) error {
+	enabled := limit > 0
+	// transmission limits the speed in KB/s
+	kbps := max((limit+1023)/1024, 1)
+	return t.client.SessionArgumentsSet(context.TODO(), transmissionrpc.SessionArguments{
+		SpeedLimitDownEnabled: &enabled,
+		SpeedLimitDown:        &kbps,
+	})
+}
+
 var _ tool.Tool = (*Transmission)(nil)
+var _ tool.MagnetResolver = (*Transmission)(nil)
+var _ tool.SpeedLimiter = (*Transmission)(nil)
 
 func init() {
 	tool.Tools.Add(&Transmission{})
@@ -0,0 +1,71 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetAccessGrants(userId uint, pageIndex, pageSize int) ([]model.AccessGrant, int64, error) {
+	return db.GetAccessGrants(userId, pageIndex, pageSize)
+}
+
+func GetAccessGrantById(id uint) (*model.AccessGrant, error) {
+	return db.GetAccessGrantById(id)
+}
+
+// CreateAccessGrant grants the user of g until g.Expires, the cached user is dropped
+// so that the grant applies to the next request
+func CreateAccessGrant(g *model.AccessGrant) error {
+	user, err := db.GetUserById(g.UserId)
+	if err != nil {
+		return err
+	}
+	if user.IsAdmin() || user.IsGuest() {
+		return errors.New("the admin and the guest can't be granted")
+	}
+	if g.Permission == 0 && g.Path == "" {
+		return errors.New("the grant has neither permission nor path")
+	}
+	if !g.Expires.After(time.Now()) {
+		return errors.New("the grant has expired")
+	}
+	if g.Path != "" {
+		g.Path = utils.FixAndCleanPath(g.Path)
+	}
+	g.ID = 0
+	g.Created = time.Now()
+	g.Revoked, g.RevokedBy = nil, ""
+	if err = db.CreateAccessGrant(g); err != nil {
+		return err
+	}
+	invalidateUser(user.Username)
+	log.Infof("access grant %d: %s granted [%s] permission %d and path %s (write: %t) until %s for: %s",
+		g.ID, g.GrantedBy, user.Username, g.Permission, g.Path, g.Write, g.Expires.Format(time.RFC3339), g.Reason)
+	return nil
+}
+
+// RevokeAccessGrant ends the grant before it expires
+func RevokeAccessGrant(id uint, revokedBy string) error {
+	g, err := db.GetAccessGrantById(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if !g.Active(now) {
+		return errors.New("the grant has already ended")
+	}
+	g.Revoked, g.RevokedBy = &now, revokedBy
+	if err = db.UpdateAccessGrant(g); err != nil {
+		return err
+	}
+	if user, err := db.GetUserById(g.UserId); err == nil {
+		invalidateUser(user.Username)
+	}
+	log.Infof("access grant %d: revoked by %s", g.ID, revokedBy)
+	return nil
+}
@@ -0,0 +1,33 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// RecordAccess records that the user accessed the path, at most max
+// histories are kept for each user and max <= 0 disables the recording
+func RecordAccess(userId uint, path string, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	if err := db.RecordAccessHistory(userId, utils.FixAndCleanPath(path), time.Now()); err != nil {
+		return err
+	}
+	return db.TrimAccessHistories(userId, max)
+}
+
+func GetAccessHistoriesByUserId(userId uint, pageIndex, pageSize int) ([]model.AccessHistory, int64, error) {
+	return db.GetAccessHistoriesByUserId(userId, pageIndex, pageSize)
+}
+
+func DeleteAccessHistory(userId uint, path string) error {
+	return db.DeleteAccessHistoryByUserPath(userId, utils.FixAndCleanPath(path))
+}
+
+func DeleteAccessHistoriesByUserId(userId uint) error {
+	return db.DeleteAccessHistoriesByUserId(userId)
+}
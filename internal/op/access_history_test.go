@@ -0,0 +1,47 @@
+package op_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+func TestRecordAccess(t *testing.T) {
+	const userId = 1000
+	if err := db.DeleteAccessHistoriesByUserId(userId); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := op.RecordAccess(userId, "/recent/a", 2); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, p := range []string{"/recent/b", "/recent/c"} {
+		if err := op.RecordAccess(userId, p, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	histories, total, err := op.GetAccessHistoriesByUserId(userId, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the concurrent accesses are counted into one history, which is trimmed as the oldest
+	if total != 2 || histories[0].Path != "/recent/c" || histories[1].Path != "/recent/b" {
+		t.Fatalf("unexpected histories: %+v", histories)
+	}
+	if err = op.RecordAccess(userId, "/recent/b", 2); err != nil {
+		t.Fatal(err)
+	}
+	histories, _, _ = op.GetAccessHistoriesByUserId(userId, 1, 10)
+	if histories[0].Path != "/recent/b" || histories[0].Count != 2 {
+		t.Fatalf("unexpected histories: %+v", histories)
+	}
+}
@@ -0,0 +1,104 @@
+package op
+
+import (
+	"slices"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetAccessRequests(userId uint, status string, pageIndex, pageSize int) ([]model.AccessRequest, int64, error) {
+	requests, count, err := db.GetAccessRequests(userId, status, pageIndex, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	names := make(map[uint]string)
+	for i := range requests {
+		name, ok := names[requests[i].UserId]
+		if !ok {
+			if user, err := db.GetUserById(requests[i].UserId); err == nil {
+				name = user.Username
+			}
+			names[requests[i].UserId] = name
+		}
+		requests[i].Username = name
+	}
+	return requests, count, nil
+}
+
+// CreateAccessRequest submits the request, the pending request of the user for the same access is returned instead
+func CreateAccessRequest(r *model.AccessRequest) (*model.AccessRequest, error) {
+	r.Path = utils.FixAndCleanPath(r.Path)
+	if old, err := db.GetPendingAccessRequest(r.UserId, r.Path, r.Write); err == nil {
+		return old, nil
+	}
+	r.ID = 0
+	r.Status = model.AccessRequestPending
+	r.Created = time.Now()
+	r.ReviewedBy, r.Reviewed = "", nil
+	if err := db.CreateAccessRequest(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ReviewAccessRequest approves or denies the pending request. Approving it adds the user
+// to the read users, and the write users if it asks to write, of the nearest meta of the path
+// which restricts them
+func ReviewAccessRequest(id uint, approve bool, reviewedBy, comment string) error {
+	r, err := db.GetAccessRequestById(id)
+	if err != nil {
+		return err
+	}
+	if r.Status != model.AccessRequestPending {
+		return errors.Errorf("the request has already been %s", r.Status)
+	}
+	if approve {
+		if err = grantMetaUser(r.Path, r.UserId, r.Write); err != nil {
+			return err
+		}
+		r.Status = model.AccessRequestApproved
+	} else {
+		r.Status = model.AccessRequestDenied
+	}
+	now := time.Now()
+	r.ReviewedBy, r.Reviewed, r.Comment = reviewedBy, &now, comment
+	if err = db.UpdateAccessRequest(r); err != nil {
+		return err
+	}
+	log.Infof("access request %d: %s %s user %d to access %s (write: %t)", r.ID, reviewedBy, r.Status, r.UserId, r.Path, r.Write)
+	return nil
+}
+
+func grantMetaUser(path string, userId uint, write bool) error {
+	nearest, err := GetNearestMeta(path)
+	if err != nil {
+		if errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return nil
+		}
+		return err
+	}
+	// the cached meta is shared, so change a copy from the database
+	meta, err := GetMetaById(nearest.ID)
+	if err != nil {
+		return err
+	}
+	changed := false
+	if len(meta.ReadUsers) > 0 && !slices.Contains(meta.ReadUsers, userId) {
+		meta.ReadUsers = append(meta.ReadUsers, userId)
+		changed = true
+	}
+	if write && len(meta.WriteUsers) > 0 && !slices.Contains(meta.WriteUsers, userId) {
+		meta.WriteUsers = append(meta.WriteUsers, userId)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return UpdateMeta(meta)
+}
@@ -0,0 +1,100 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/singleflight"
+	"github.com/OpenListTeam/go-cache"
+	"github.com/pkg/errors"
+)
+
+const announcementCacheKey = "enabled"
+
+// the web UI polls the active announcements, so the enabled ones are cached
+var announcementCache = cache.NewMemCache[[]model.Announcement]()
+var announcementG singleflight.Group[[]model.Announcement]
+
+func getEnabledAnnouncements() ([]model.Announcement, error) {
+	if announcements, ok := announcementCache.Get(announcementCacheKey); ok {
+		return announcements, nil
+	}
+	announcements, err, _ := announcementG.Do(announcementCacheKey, func() ([]model.Announcement, error) {
+		announcements, err := db.GetEnabledAnnouncements()
+		if err != nil {
+			return nil, err
+		}
+		announcementCache.Set(announcementCacheKey, announcements, cache.WithEx[[]model.Announcement](time.Minute*10))
+		return announcements, nil
+	})
+	return announcements, err
+}
+
+// GetActiveAnnouncements returns the announcements which are currently in their
+// display window and targeted to the role of the given user
+func GetActiveAnnouncements(user *model.User) ([]model.Announcement, error) {
+	announcements, err := getEnabledAnnouncements()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	res := make([]model.Announcement, 0, len(announcements))
+	for i := range announcements {
+		if announcements[i].Active(now) && announcements[i].VisibleTo(user) {
+			res = append(res, announcements[i])
+		}
+	}
+	return res, nil
+}
+
+func GetAnnouncements(pageIndex, pageSize int) ([]model.Announcement, int64, error) {
+	return db.GetAnnouncements(pageIndex, pageSize)
+}
+
+func GetAnnouncementById(id uint) (*model.Announcement, error) {
+	return db.GetAnnouncementById(id)
+}
+
+func CreateAnnouncement(a *model.Announcement) error {
+	if err := validateAnnouncement(a); err != nil {
+		return err
+	}
+	a.Created = time.Now()
+	a.Modified = a.Created
+	announcementCache.Del(announcementCacheKey)
+	return db.CreateAnnouncement(a)
+}
+
+func UpdateAnnouncement(a *model.Announcement) error {
+	if err := validateAnnouncement(a); err != nil {
+		return err
+	}
+	old, err := db.GetAnnouncementById(a.ID)
+	if err != nil {
+		return err
+	}
+	a.Created = old.Created
+	a.Modified = time.Now()
+	announcementCache.Del(announcementCacheKey)
+	return db.UpdateAnnouncement(a)
+}
+
+func DeleteAnnouncementById(id uint) error {
+	announcementCache.Del(announcementCacheKey)
+	return db.DeleteAnnouncementById(id)
+}
+
+func validateAnnouncement(a *model.Announcement) error {
+	switch a.Severity {
+	case "":
+		a.Severity = model.AnnouncementInfo
+	case model.AnnouncementInfo, model.AnnouncementWarning, model.AnnouncementDanger:
+	default:
+		return errors.Errorf("invalid severity: %s", a.Severity)
+	}
+	if a.StartAt != nil && a.EndAt != nil && !a.StartAt.IsZero() && !a.EndAt.IsZero() && a.EndAt.Before(*a.StartAt) {
+		return errors.New("end time is before start time")
+	}
+	return nil
+}
@@ -60,6 +60,7 @@ func GetArchiveMeta(ctx context.Context, storage driver.Driver, path string, arg
 }
 
 func GetArchiveToolAndStream(ctx context.Context, storage driver.Driver, path string, args model.LinkArgs) (model.Obj, tool.Tool, []*stream.SeekableStream, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	l, obj, err := Link(ctx, storage, path, args)
 	if err != nil {
 		return nil, nil, nil, errors.WithMessagef(err, "failed get [%s] link", path)
@@ -150,6 +151,7 @@ func GetArchiveToolAndStream(ctx context.Context, storage driver.Driver, path st
 }
 
 func getArchiveMeta(ctx context.Context, storage driver.Driver, path string, args model.ArchiveMetaArgs) (model.Obj, *model.ArchiveMetaProvider, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	storageAr, ok := storage.(driver.ArchiveReader)
 	if ok {
 		obj, err := GetUnwrap(ctx, storage, path)
@@ -281,6 +283,7 @@ func _listArchive(ctx context.Context, storage driver.Driver, path string, args
 }
 
 func listArchive(ctx context.Context, storage driver.Driver, path string, args model.ArchiveListArgs) ([]model.Obj, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	files, err := _listArchive(ctx, storage, path, args)
 	if errors.Is(err, errs.NotSupport) {
 		var meta model.ArchiveMeta
@@ -343,6 +346,7 @@ func splitPath(path string) []string {
 }
 
 func ArchiveGet(ctx context.Context, storage driver.Driver, path string, args model.ArchiveListArgs) (model.Obj, model.Obj, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, nil, errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -435,6 +439,7 @@ func DriverExtract(ctx context.Context, storage driver.Driver, path string, args
 }
 
 func driverExtract(ctx context.Context, storage driver.Driver, path string, args model.ArchiveInnerArgs) (*objWithLink, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	storageAr, ok := storage.(driver.ArchiveReader)
 	if !ok {
 		return nil, errs.DriverExtractNotSupported
@@ -471,6 +476,7 @@ func (s *streamWithParent) Close() error {
 }
 
 func InternalExtract(ctx context.Context, storage driver.Driver, path string, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	_, t, ss, err := GetArchiveToolAndStream(ctx, storage, path, args.LinkArgs)
 	if err != nil {
 		return nil, 0, err
@@ -491,6 +497,7 @@ func InternalExtract(ctx context.Context, storage driver.Driver, path string, ar
 }
 
 func ArchiveDecompress(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string, args model.ArchiveDecompressArgs, lazyCache ...bool) error {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
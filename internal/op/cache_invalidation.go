@@ -0,0 +1,107 @@
+package op
+
+import (
+	"context"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// invalidationPublisher sends the invalidations to the other instances, nil if there is only one
+var invalidationPublisher func(inv model.CacheInvalidation)
+
+// SetInvalidationPublisher lets the changes of the settings, storages, metas and users refresh the caches of the other instances
+func SetInvalidationPublisher(publisher func(inv model.CacheInvalidation)) {
+	invalidationPublisher = publisher
+}
+
+func publishInvalidation(inv model.CacheInvalidation) {
+	if invalidationPublisher != nil {
+		invalidationPublisher(inv)
+	}
+}
+
+func invalidateUser(username string) {
+	Cache.DeleteUser(username)
+	publishInvalidation(model.CacheInvalidation{Kind: model.InvalidateUsers, Keys: []string{username}})
+}
+
+func publishStorage(id uint) {
+	publishInvalidation(model.CacheInvalidation{Kind: model.InvalidateStorage, ID: id})
+}
+
+// ApplyInvalidation refreshes the caches changed by another instance, without publishing it again
+func ApplyInvalidation(ctx context.Context, inv model.CacheInvalidation) error {
+	switch inv.Kind {
+	case model.InvalidateSettings:
+		return reloadSettings()
+	case model.InvalidateStorage:
+		return reloadStorage(ctx, inv.ID)
+	case model.InvalidateMetas:
+		for _, path := range inv.Keys {
+			metaCache.Del(path)
+		}
+	case model.InvalidateUsers:
+		adminUser, guestUser = nil, nil
+		for _, username := range inv.Keys {
+			Cache.DeleteUser(username)
+		}
+	default:
+		return errors.Errorf("unknown cache invalidation: %s", inv.Kind)
+	}
+	return nil
+}
+
+// RefreshCaches drops the caches which may have missed the invalidations, e.g. while the connection was lost.
+// The storages are kept as reloading them interrupts their uses
+func RefreshCaches() error {
+	metaCache.Clear()
+	adminUser, guestUser = nil, nil
+	return reloadSettings()
+}
+
+// reloadSettings reruns the setting hooks with the values in the database, as they change the global variables
+func reloadSettings() error {
+	items, err := db.GetSettingItems()
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		if _, err := HandleSettingItemHook(&items[i]); err != nil {
+			log.Errorf("failed to execute hook on %s: %+v", items[i].Key, err)
+		}
+	}
+	settingCacheUpdate()
+	return nil
+}
+
+// reloadStorage drops the storage of the id and loads it again from the database
+func reloadStorage(ctx context.Context, id uint) error {
+	for _, storageDriver := range GetAllStorages() {
+		if storageDriver.GetStorage().ID != id {
+			continue
+		}
+		if err := storageDriver.Drop(ctx); err != nil {
+			log.Warnf("failed drop storage [%s]: %+v", storageDriver.GetStorage().MountPath, err)
+		}
+		storagesMap.Delete(storageDriver.GetStorage().MountPath)
+		Cache.DeleteDirectoryTree(storageDriver, "/")
+		Cache.InvalidateStorageDetails(storageDriver)
+		go callStorageHooks("del", storageDriver)
+	}
+	storageSecretRefs.Delete(id)
+	storage, err := db.GetStorageById(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if storage.Disabled {
+		return nil
+	}
+	return LoadStorage(ctx, *storage)
+}
@@ -0,0 +1,42 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func GetClipboardByUserId(userId uint) (*model.Clipboard, error) {
+	return db.GetClipboardByUserId(userId)
+}
+
+// SetClipboard replaces the clipboard of the user
+func SetClipboard(userId uint, op, srcDir string, names []string) (*model.Clipboard, error) {
+	if op != model.ClipboardCopy && op != model.ClipboardMove {
+		return nil, errors.Errorf("invalid clipboard op: %s", op)
+	}
+	if len(names) == 0 {
+		return nil, errors.New("empty file names")
+	}
+	c := &model.Clipboard{
+		UserId:   userId,
+		Op:       op,
+		SrcDir:   utils.FixAndCleanPath(srcDir),
+		Names:    names,
+		Modified: time.Now(),
+	}
+	if old, err := db.GetClipboardByUserId(userId); err == nil {
+		c.ID = old.ID
+	}
+	if err := db.SaveClipboard(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func DeleteClipboardByUserId(userId uint) error {
+	return db.DeleteClipboardByUserId(userId)
+}
@@ -0,0 +1,139 @@
+package op
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the down_proxy_url of a storage holds one proxy or cdn base url per line, optionally followed by its weight:
+//
+//	https://cdn1.example.com 3
+//	https://cdn2.example.com
+//
+// a url is picked by weight for each download among the urls which passed the last health check,
+// the urls of weight 0 are only picked if all the others are unhealthy
+type downProxy struct {
+	url    string
+	weight int
+}
+
+// downProxyUnhealthy holds the urls which failed the last health check
+var downProxyUnhealthy sync.Map
+
+var downProxyClient = &http.Client{Timeout: 10 * time.Second}
+
+func parseDownProxies(s string) []downProxy {
+	var proxies []downProxy
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		p := downProxy{url: strings.TrimSuffix(fields[0], "/"), weight: 1}
+		if len(fields) > 1 {
+			if w, err := strconv.Atoi(fields[1]); err == nil && w >= 0 {
+				p.weight = w
+			}
+		}
+		proxies = append(proxies, p)
+	}
+	return proxies
+}
+
+// PickDownProxyURL returns one of the down proxy urls by weight, skipping the unhealthy ones
+// unless all of them are unhealthy, "" if there is none
+func PickDownProxyURL(s string) string {
+	proxies := parseDownProxies(s)
+	if len(proxies) <= 1 {
+		if len(proxies) == 0 {
+			return ""
+		}
+		return proxies[0].url
+	}
+	healthy := make([]downProxy, 0, len(proxies))
+	for _, p := range proxies {
+		if _, ok := downProxyUnhealthy.Load(p.url); !ok {
+			healthy = append(healthy, p)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = proxies
+	}
+	total := 0
+	for _, p := range healthy {
+		total += p.weight
+	}
+	if total == 0 {
+		return healthy[0].url
+	}
+	n := rand.Intn(total)
+	for _, p := range healthy {
+		if n < p.weight {
+			return p.url
+		}
+		n -= p.weight
+	}
+	return healthy[len(healthy)-1].url
+}
+
+func checkDownProxy(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url+"/", nil)
+	if err != nil {
+		return err
+	}
+	res, err := downProxyClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	// any response but a server error means the proxy is up, the root may not be served
+	if res.StatusCode >= 500 {
+		return errors.Errorf("status %s", res.Status)
+	}
+	return nil
+}
+
+// CheckDownProxies checks the down proxy urls of the storages having more than one of them
+func CheckDownProxies(ctx context.Context) {
+	urls := make(map[string]struct{})
+	for _, storage := range GetAllStorages() {
+		proxies := parseDownProxies(storage.GetStorage().DownProxyURL)
+		if len(proxies) <= 1 {
+			continue
+		}
+		for _, p := range proxies {
+			urls[p.url] = struct{}{}
+		}
+	}
+	// forget the urls which are not used anymore
+	downProxyUnhealthy.Range(func(key, _ any) bool {
+		if _, ok := urls[key.(string)]; !ok {
+			downProxyUnhealthy.Delete(key)
+		}
+		return true
+	})
+	var wg sync.WaitGroup
+	for url := range urls {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			err := checkDownProxy(ctx, url)
+			if err != nil {
+				if _, loaded := downProxyUnhealthy.LoadOrStore(url, struct{}{}); !loaded {
+					log.Warnf("down proxy %s is unhealthy: %v", url, err)
+				}
+			} else if _, loaded := downProxyUnhealthy.LoadAndDelete(url); loaded {
+				log.Infof("down proxy %s is healthy again", url)
+			}
+		}(url)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,23 @@
+package op
+
+import "testing"
+
+func TestPickDownProxyURL(t *testing.T) {
+	if got := PickDownProxyURL("https://a.example.com/\n"); got != "https://a.example.com" {
+		t.Errorf("PickDownProxyURL() = %s, want https://a.example.com", got)
+	}
+	s := "https://a.example.com 0\nhttps://b.example.com 2\n\nhttps://c.example.com"
+	downProxyUnhealthy.Store("https://c.example.com", struct{}{})
+	defer downProxyUnhealthy.Delete("https://c.example.com")
+	for i := 0; i < 20; i++ {
+		if got := PickDownProxyURL(s); got != "https://b.example.com" {
+			t.Fatalf("PickDownProxyURL() = %s, want https://b.example.com", got)
+		}
+	}
+	downProxyUnhealthy.Store("https://b.example.com", struct{}{})
+	defer downProxyUnhealthy.Delete("https://b.example.com")
+	// the url of weight 0 is only picked when the others are unhealthy
+	if got := PickDownProxyURL(s); got != "https://a.example.com" {
+		t.Errorf("PickDownProxyURL() = %s, want https://a.example.com", got)
+	}
+}
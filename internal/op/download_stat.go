@@ -0,0 +1,72 @@
+package op
+
+import (
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+type downloadStatKey struct {
+	day    string
+	path   string
+	userId uint
+}
+
+// downloads are counted in memory and flushed to the database periodically,
+// so that a download never waits for a database write
+var (
+	downloadStatsMu sync.Mutex
+	downloadStats   = make(map[downloadStatKey]*model.DownloadStat)
+	// serializes the read-modify-write of the rollups
+	downloadStatsFlushMu sync.Mutex
+)
+
+// RecordDownload counts count downloads of the path and the bytes sent of it
+func RecordDownload(path, storage string, userId uint, count, bytes int64) {
+	path = utils.FixAndCleanPath(path)
+	key := downloadStatKey{
+		day:    time.Now().Format(time.DateOnly),
+		path:   path,
+		userId: userId,
+	}
+	downloadStatsMu.Lock()
+	defer downloadStatsMu.Unlock()
+	s, ok := downloadStats[key]
+	if !ok {
+		s = &model.DownloadStat{Day: key.day, Path: path, Storage: storage, UserId: userId}
+		downloadStats[key] = s
+	}
+	s.Count += count
+	s.Bytes += bytes
+}
+
+func FlushDownloadStats() {
+	downloadStatsFlushMu.Lock()
+	defer downloadStatsFlushMu.Unlock()
+	downloadStatsMu.Lock()
+	stats := downloadStats
+	downloadStats = make(map[downloadStatKey]*model.DownloadStat)
+	downloadStatsMu.Unlock()
+	for _, s := range stats {
+		if err := db.AddDownloadStat(s); err != nil {
+			log.Errorf("failed save download stat of %s: %+v", s.Path, err)
+		}
+	}
+}
+
+func GetDownloadStats(req model.DownloadStatsReq) ([]model.DownloadStatsItem, error) {
+	FlushDownloadStats()
+	return db.GetDownloadStats(req.From, req.To, req.GroupBy, req.OrderBy, req.Limit)
+}
+
+// CleanDownloadStats removes the rollups older than keepDays days
+func CleanDownloadStats(keepDays int) error {
+	if keepDays <= 0 {
+		return nil
+	}
+	return db.DeleteDownloadStatsBefore(time.Now().AddDate(0, 0, -keepDays).Format(time.DateOnly))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/pkg/errors"
 )
 
@@ -51,6 +52,16 @@ func registerDriverItems(config driver.Config, addition driver.Additional) {
 	}
 	mainItems := getMainItems(config)
 	additionalItems := getAdditionalItems(tAddition, config.DefaultRoot)
+	// the network options are in the addition of every storage, unless the driver has an item of the same name
+	names := make(map[string]struct{}, len(additionalItems))
+	for _, item := range additionalItems {
+		names[item.Name] = struct{}{}
+	}
+	for _, item := range getAdditionalItems(reflect.TypeOf(model.NetworkOptions{}), config.DefaultRoot) {
+		if _, ok := names[item.Name]; !ok {
+			additionalItems = append(additionalItems, item)
+		}
+	}
 	driverInfoMap[config.Name] = driver.Info{
 		Common:     mainItems,
 		Additional: additionalItems,
@@ -71,6 +82,11 @@ func getMainItems(config driver.Config) []driver.Item {
 	}, {
 		Name: "remark",
 		Type: conf.TypeText,
+	}, {
+		Name:    "egress_cost",
+		Type:    conf.TypeNumber,
+		Default: "0",
+		Help:    "The cost per GB read from the storage, used to estimate the costs of the traffic and the copies",
 	}}
 	if !config.NoCache {
 		items = append(items, driver.Item{
@@ -138,6 +154,7 @@ func getMainItems(config driver.Config) []driver.Item {
 	items = append(items, driver.Item{
 		Name: "down_proxy_url",
 		Type: conf.TypeText,
+		Help: "one url per line, optionally followed by its weight, a healthy url is picked by weight for each download",
 	})
 	items = append(items, driver.Item{
 		Name:    "disable_proxy_sign",
@@ -145,6 +162,17 @@ func getMainItems(config driver.Config) []driver.Item {
 		Default: "false",
 		Help:    "Disable sign for Download proxy URL",
 	})
+	items = append(items, driver.Item{
+		Name:    "read_ahead",
+		Type:    conf.TypeNumber,
+		Default: "0",
+		Help:    "MB of the proxied downloads prefetched ahead of the client, 0 to disable",
+	})
+	items = append(items, driver.Item{
+		Name: "proxy_rules",
+		Type: conf.TypeText,
+		Help: `json array of rules choosing between the redirect and the proxy, the first matching rule wins, e.g. [{"action":"302","cidrs":["192.168.0.0/16"]},{"action":"proxy","exts":["mp4"],"max_size":104857600}]`,
+	})
 	if config.LocalSort {
 		items = append(items, []driver.Item{{
 			Name:    "order_by",
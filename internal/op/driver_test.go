@@ -15,3 +15,17 @@ func TestDriverItemsMap(t *testing.T) {
 		t.Errorf("expected driverInfoMap not empty, but got empty")
 	}
 }
+
+func TestDriverNetworkOptionItems(t *testing.T) {
+	for name, info := range op.GetDriverInfoMap() {
+		count := map[string]int{}
+		for _, item := range info.Additional {
+			count[item.Name]++
+		}
+		for _, item := range []string{"proxy_url", "dns_server", "tls_insecure_skip_verify", "request_timeout"} {
+			if count[item] != 1 {
+				t.Errorf("driver %s has %d %s items, want 1", name, count[item], item)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package op
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/singleflight"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/go-cache"
+	"github.com/pkg/errors"
+)
+
+// favoritePathsCache caches the favorite paths of each user,
+// so that the starred status can be added to list responses cheaply
+var favoritePathsCache = cache.NewMemCache(cache.WithShards[map[string]struct{}](8))
+var favoritePathsG singleflight.Group[map[string]struct{}]
+
+func GetFavoritePathSet(userId uint) (map[string]struct{}, error) {
+	key := strconv.FormatUint(uint64(userId), 10)
+	if set, ok := favoritePathsCache.Get(key); ok {
+		return set, nil
+	}
+	set, err, _ := favoritePathsG.Do(key, func() (map[string]struct{}, error) {
+		paths, err := db.GetAllFavoritePathsByUserId(userId)
+		if err != nil {
+			return nil, err
+		}
+		set := make(map[string]struct{}, len(paths))
+		for _, p := range paths {
+			set[p] = struct{}{}
+		}
+		favoritePathsCache.Set(key, set, cache.WithEx[map[string]struct{}](time.Hour))
+		return set, nil
+	})
+	return set, err
+}
+
+func GetFavoritesByUserId(userId uint, pageIndex, pageSize int) ([]model.Favorite, int64, error) {
+	return db.GetFavoritesByUserId(userId, pageIndex, pageSize)
+}
+
+func CreateFavorite(userId uint, path string) error {
+	path = utils.FixAndCleanPath(path)
+	if _, err := db.GetFavoriteByUserPath(userId, path); err == nil {
+		return errors.New("the path is already starred")
+	}
+	favoritePathsCache.Del(strconv.FormatUint(uint64(userId), 10))
+	return db.CreateFavorite(&model.Favorite{
+		UserId:  userId,
+		Path:    path,
+		Created: time.Now(),
+	})
+}
+
+func DeleteFavorite(userId uint, path string) error {
+	favoritePathsCache.Del(strconv.FormatUint(uint64(userId), 10))
+	return db.DeleteFavoriteByUserPath(userId, utils.FixAndCleanPath(path))
+}
+
+func DeleteFavoritesByUserId(userId uint) error {
+	favoritePathsCache.Del(strconv.FormatUint(uint64(userId), 10))
+	return db.DeleteFavoritesByUserId(userId)
+}
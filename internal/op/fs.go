@@ -2,6 +2,7 @@ package op
 
 import (
 	"context"
+	"io"
 	stdpath "path"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/delta"
 	"github.com/OpenListTeam/OpenList/v4/pkg/singleflight"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/bmatcuk/doublestar/v4"
@@ -28,6 +30,7 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 }
 
 func list(ctx context.Context, storage driver.Driver, path string, args model.ListArgs, resultValidator func([]model.Obj) error) ([]model.Obj, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -127,6 +130,7 @@ func list(ctx context.Context, storage driver.Driver, path string, args model.Li
 
 // Get object from list of files
 func Get(ctx context.Context, storage driver.Driver, path string, excludeTempObj ...bool) (model.Obj, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -226,6 +230,7 @@ var linkG = singleflight.Group[*objWithLink]{}
 
 // Link get link, if is an url. should have an expiry time
 func Link(ctx context.Context, storage driver.Driver, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, nil, errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -245,11 +250,16 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 	if ol, exists := Cache.linkCache.GetType(key, typeKey); exists {
 		if ol.link.Expiration != nil ||
 			ol.link.SyncClosers.AcquireReference() || !ol.link.RequireReference {
+			linkCacheHits.Add(1)
 			return ol.link, ol.obj, nil
 		}
 	}
 
+	// whether the link is requested by this call instead of a concurrent one of the same link
+	requested := false
 	fn := func() (*objWithLink, error) {
+		requested = true
+		linkCacheMisses.Add(1)
 		file, err := GetUnwrap(ctx, storage, path)
 		if err != nil {
 			return nil, errors.WithMessage(err, "failed to get file")
@@ -271,7 +281,11 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return ol, nil
 	}
 	for {
+		requested = false
 		ol, err, _ := linkG.Do(key+"/"+typeKey, fn)
+		if !requested {
+			linkCacheShared.Add(1)
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -283,6 +297,7 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 
 // Other api
 func Other(ctx context.Context, storage driver.Driver, args model.FsOtherArgs) (any, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -304,6 +319,7 @@ func Other(ctx context.Context, storage driver.Driver, args model.FsOtherArgs) (
 var mkdirG singleflight.Group[any]
 
 func MakeDir(ctx context.Context, storage driver.Driver, path string) error {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -346,6 +362,7 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string) error {
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
+		publishFsEvent(storage, model.FsEventCreate, path, "", true)
 		if storage.Config().NoCache {
 			return nil, nil
 		}
@@ -368,6 +385,7 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string) error {
 }
 
 func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string) error {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -408,6 +426,7 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	publishFsEvent(storage, model.FsEventMove, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()), srcObj.IsDir())
 
 	srcKey := Key(storage, srcDirPath)
 	dstKey := Key(storage, dstDirPath)
@@ -444,6 +463,7 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 }
 
 func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string) error {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -472,6 +492,7 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	publishFsEvent(storage, model.FsEventRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName), srcObj.IsDir())
 
 	dirKey := Key(storage, stdpath.Dir(srcPath))
 	if !srcRawObj.IsDir() {
@@ -505,6 +526,7 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string)
 
 // Copy Just copy file[s] in a storage
 func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string) error {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -541,6 +563,7 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	publishFsEvent(storage, model.FsEventCopy, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()), srcObj.IsDir())
 
 	dstKey := Key(storage, dstDirPath)
 	if !srcRawObj.IsDir() {
@@ -569,6 +592,7 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 }
 
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -595,6 +619,7 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 		err = s.Remove(ctx, model.UnwrapObjName(rawObj))
 		if err == nil {
 			Cache.removeDirectoryObject(storage, dirPath, rawObj)
+			publishFsEvent(storage, model.FsEventDelete, path, "", rawObj.IsDir())
 		}
 	default:
 		return errs.NotImplement
@@ -603,6 +628,7 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 }
 
 func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer, up driver.UpdateProgress) error {
+	ctx = withNetworkOptions(ctx, storage)
 	defer func() {
 		if err := file.Close(); err != nil {
 			log.Errorf("failed to close file streamer, %v", err)
@@ -662,16 +688,27 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 		file.CacheFullAndWriter(nil, nil)
 	}
 
+	// the quota is reserved once the size is known, less the size of the overwritten file
+	size := file.GetSize()
+	if fi != nil {
+		size -= fi.GetSize()
+	}
+	err = reserveQuota(ctx, storage, dstDirPath, size)
 	var newObj model.Obj
-	switch s := storage.(type) {
-	case driver.PutResult:
-		newObj, err = s.Put(ctx, parentDir, file, up)
-	case driver.Put:
-		err = s.Put(ctx, parentDir, file, up)
-	default:
-		return errs.NotImplement
+	if err == nil {
+		switch s := storage.(type) {
+		case driver.PutResult:
+			newObj, err = s.Put(nestedPutCtx(ctx), parentDir, file, up)
+		case driver.Put:
+			err = s.Put(nestedPutCtx(ctx), parentDir, file, up)
+		default:
+			return errs.NotImplement
+		}
 	}
 	if err == nil {
+		RecordUpload(ctx, file.GetSize())
+		RecordStorageTraffic(storage.GetStorage().MountPath, 0, file.GetSize())
+		publishFsEvent(storage, model.FsEventCreate, dstPath, "", false)
 		Cache.linkCache.DeleteKey(Key(storage, dstPath))
 		if !storage.Config().NoCache {
 			if cache, exist := Cache.dirCache.Get(Key(storage, dstDirPath)); exist {
@@ -709,7 +746,304 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	return errors.WithStack(err)
 }
 
-func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url string) error {
+// PutRapid creates a file from the hashes of the stream in dstDirPath of a storage implementing driver.PutRapid
+func PutRapid(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer) error {
+	ctx = withNetworkOptions(ctx, storage)
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.PutRapid)
+	if !ok {
+		return errors.WithStack(errs.NotImplement)
+	}
+	dstDirPath = utils.FixAndCleanPath(dstDirPath)
+	dstPath := stdpath.Join(dstDirPath, file.GetName())
+	err := MakeDir(ctx, storage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to make dir [%s]", dstDirPath)
+	}
+	dstDir, err := GetUnwrap(ctx, storage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get dir [%s]", dstDirPath)
+	}
+	if model.ObjHasMask(dstDir, model.NoWrite) {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	if err = reserveQuota(ctx, storage, dstDirPath, file.GetSize()); err != nil {
+		return err
+	}
+	newObj, err := s.PutRapid(nestedPutCtx(ctx), dstDir, file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	RecordUpload(ctx, file.GetSize())
+	publishFsEvent(storage, model.FsEventCreate, dstPath, "", false)
+	Cache.linkCache.DeleteKey(Key(storage, dstPath))
+	if !storage.Config().NoCache {
+		if cache, exist := Cache.dirCache.Get(Key(storage, dstDirPath)); exist {
+			if newObj == nil {
+				newObj = &model.Object{
+					Name:     file.GetName(),
+					Size:     file.GetSize(),
+					Modified: file.ModTime(),
+					Ctime:    file.CreateTime(),
+					Mask:     model.Temp,
+				}
+			}
+			newObj = wrapObjName(storage, newObj)
+			cache.UpdateObject(newObj.GetName(), newObj)
+		}
+	}
+	if ctx.Value(conf.SkipHookKey) == nil && needHandleObjsUpdateHook() {
+		go objsUpdateHook(context.WithoutCancel(ctx), storage, dstDirPath, false)
+	}
+	return nil
+}
+
+// PutTar extracts the files of a tar stream into dstDirPath of a storage implementing driver.PutTar,
+// size is the total size of the files
+func PutTar(ctx context.Context, storage driver.Driver, dstDirPath string, tarStream io.Reader, size int64) error {
+	ctx = withNetworkOptions(ctx, storage)
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.PutTar)
+	if !ok {
+		return errors.WithStack(errs.NotImplement)
+	}
+	dstDirPath = utils.FixAndCleanPath(dstDirPath)
+	err := MakeDir(ctx, storage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to make dir [%s]", dstDirPath)
+	}
+	dstDir, err := GetUnwrap(ctx, storage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get dir [%s]", dstDirPath)
+	}
+	if model.ObjHasMask(dstDir, model.NoWrite) {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	if err = reserveQuota(ctx, storage, dstDirPath, size); err != nil {
+		return err
+	}
+	err = s.PutTar(nestedPutCtx(ctx), dstDir, tarStream)
+	// some files may be extracted even if it failed
+	Cache.DeleteDirectory(storage, dstDirPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// the writer of the stream may still be writing the padding after the end of the archive
+	_, _ = io.Copy(io.Discard, tarStream)
+	// the extracted files are reported as a whole
+	publishFsEvent(storage, model.FsEventCreate, dstDirPath, "", true)
+	if ctx.Value(conf.SkipHookKey) == nil && needHandleObjsUpdateHook() {
+		go objsUpdateHook(context.WithoutCancel(ctx), storage, dstDirPath, false)
+	}
+	return nil
+}
+
+// PutDelta replaces the file at path of a storage implementing driver.PutDelta with the one of size made by the ops
+func PutDelta(ctx context.Context, storage driver.Driver, path string, ops []delta.Op, data io.Reader, size int64, modified time.Time) error {
+	ctx = withNetworkOptions(ctx, storage)
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.PutDelta)
+	if !ok {
+		return errors.WithStack(errs.NotImplement)
+	}
+	path = utils.FixAndCleanPath(path)
+	dirPath := stdpath.Dir(path)
+	dir, err := GetUnwrap(ctx, storage, dirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get dir [%s]", dirPath)
+	}
+	if model.ObjHasMask(dir, model.NoWrite) {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	obj, err := GetUnwrap(ctx, storage, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get file")
+	}
+	if obj.IsDir() {
+		return errors.WithStack(errs.NotFile)
+	}
+	if err = reserveQuota(ctx, storage, dirPath, size-obj.GetSize()); err != nil {
+		return err
+	}
+	err = s.PutDelta(nestedPutCtx(ctx), obj, ops, data, modified)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	RecordUpload(ctx, delta.DataSize(ops))
+	RecordStorageTraffic(storage.GetStorage().MountPath, 0, delta.DataSize(ops))
+	publishFsEvent(storage, model.FsEventCreate, path, "", false)
+	Cache.linkCache.DeleteKey(Key(storage, path))
+	if !storage.Config().NoCache {
+		if cache, exist := Cache.dirCache.Get(Key(storage, dirPath)); exist {
+			newObj := wrapObjName(storage, &model.Object{
+				Name:     obj.GetName(),
+				Size:     size,
+				Modified: modified,
+				Ctime:    obj.CreateTime(),
+				Mask:     model.Temp,
+			})
+			cache.UpdateObject(newObj.GetName(), newObj)
+		}
+	}
+	if ctx.Value(conf.SkipHookKey) == nil && needHandleObjsUpdateHook() {
+		go objsUpdateHook(context.WithoutCancel(ctx), storage, dirPath, false)
+	}
+	return nil
+}
+
+// AppendPut appends the data of size to the file at path of a storage implementing driver.AppendPut
+func AppendPut(ctx context.Context, storage driver.Driver, path string, data io.Reader, size int64) error {
+	ctx = withNetworkOptions(ctx, storage)
+	s, ok := storage.(driver.AppendPut)
+	if !ok {
+		return errors.WithStack(errs.NotImplement)
+	}
+	return writeFile(ctx, storage, path, size, func(obj model.Obj) int64 {
+		return obj.GetSize() + size
+	}, func(ctx context.Context, obj model.Obj) error {
+		return s.AppendPut(ctx, obj, data, size)
+	})
+}
+
+// WriteRange writes the data of size at offset of the file at path of a storage implementing driver.WriteRange
+func WriteRange(ctx context.Context, storage driver.Driver, path string, offset int64, data io.Reader, size int64) error {
+	ctx = withNetworkOptions(ctx, storage)
+	s, ok := storage.(driver.WriteRange)
+	if !ok {
+		return errors.WithStack(errs.NotImplement)
+	}
+	if offset < 0 {
+		return errors.New("the offset can't be negative")
+	}
+	return writeFile(ctx, storage, path, size, func(obj model.Obj) int64 {
+		return max(obj.GetSize(), offset+size)
+	}, func(ctx context.Context, obj model.Obj) error {
+		return s.WriteRange(ctx, obj, offset, data, size)
+	})
+}
+
+// writeFile calls write with the existing file at path, which writes the data of size to it making it of newSize
+func writeFile(ctx context.Context, storage driver.Driver, path string, size int64, newSize func(obj model.Obj) int64,
+	write func(ctx context.Context, obj model.Obj) error) error {
+	ctx = withNetworkOptions(ctx, storage)
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
+	}
+	path = utils.FixAndCleanPath(path)
+	dirPath := stdpath.Dir(path)
+	dir, err := GetUnwrap(ctx, storage, dirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get dir [%s]", dirPath)
+	}
+	if model.ObjHasMask(dir, model.NoWrite) {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	obj, err := GetUnwrap(ctx, storage, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get file")
+	}
+	if obj.IsDir() {
+		return errors.WithStack(errs.NotFile)
+	}
+	if err = reserveQuota(ctx, storage, dirPath, newSize(obj)-obj.GetSize()); err != nil {
+		return err
+	}
+	if err = write(nestedPutCtx(ctx), obj); err != nil {
+		return errors.WithStack(err)
+	}
+	RecordUpload(ctx, size)
+	RecordStorageTraffic(storage.GetStorage().MountPath, 0, size)
+	publishFsEvent(storage, model.FsEventCreate, path, "", false)
+	Cache.linkCache.DeleteKey(Key(storage, path))
+	if !storage.Config().NoCache {
+		if cache, exist := Cache.dirCache.Get(Key(storage, dirPath)); exist {
+			newObj := wrapObjName(storage, &model.Object{
+				Name:     obj.GetName(),
+				Size:     newSize(obj),
+				Modified: time.Now(),
+				Ctime:    obj.CreateTime(),
+				Mask:     model.Temp,
+			})
+			cache.UpdateObject(newObj.GetName(), newObj)
+		}
+	}
+	if ctx.Value(conf.SkipHookKey) == nil && needHandleObjsUpdateHook() {
+		go objsUpdateHook(context.WithoutCancel(ctx), storage, dirPath, false)
+	}
+	return nil
+}
+
+// PutLocal transfers the file at srcPath of srcStorage into dstDirPath of dstStorage on the host filesystem,
+// both storages have to implement driver.LocalTransfer
+func PutLocal(ctx context.Context, srcStorage driver.Driver, srcPath string, dstStorage driver.Driver, dstDirPath string, move bool) error {
+	for _, storage := range []driver.Driver{srcStorage, dstStorage} {
+		if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+			return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
+		}
+	}
+	src, ok := srcStorage.(driver.LocalTransfer)
+	if !ok {
+		return errors.WithStack(errs.NotImplement)
+	}
+	dst, ok := dstStorage.(driver.LocalTransfer)
+	if !ok {
+		return errors.WithStack(errs.NotImplement)
+	}
+	srcPath = utils.FixAndCleanPath(srcPath)
+	srcObj, err := Get(ctx, srcStorage, srcPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get src object [%s]", srcPath)
+	}
+	if move && model.ObjHasMask(srcObj, model.NoMove) {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	dstDirPath = utils.FixAndCleanPath(dstDirPath)
+	dstPath := stdpath.Join(dstDirPath, srcObj.GetName())
+	err = MakeDir(ctx, dstStorage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to make dir [%s]", dstDirPath)
+	}
+	dstDir, err := GetUnwrap(ctx, dstStorage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get dir [%s]", dstDirPath)
+	}
+	if model.ObjHasMask(dstDir, model.NoWrite) {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	if err = reserveQuota(ctx, dstStorage, dstDirPath, srcObj.GetSize()); err != nil {
+		return err
+	}
+	newObj, err := dst.PutLocal(nestedPutCtx(ctx), dstDir, src.LocalPath(model.UnwrapObjName(srcObj)), move)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if move {
+		Cache.removeDirectoryObject(srcStorage, stdpath.Dir(srcPath), srcObj)
+		publishFsEvent(srcStorage, model.FsEventDelete, srcPath, "", srcObj.IsDir())
+	}
+	publishFsEvent(dstStorage, model.FsEventCreate, dstPath, "", srcObj.IsDir())
+	Cache.linkCache.DeleteKey(Key(dstStorage, dstPath))
+	if !dstStorage.Config().NoCache {
+		if cache, exist := Cache.dirCache.Get(Key(dstStorage, dstDirPath)); exist {
+			cache.UpdateObject(srcObj.GetName(), wrapObjName(dstStorage, newObj))
+		}
+	}
+	if ctx.Value(conf.SkipHookKey) == nil && needHandleObjsUpdateHook() {
+		go objsUpdateHook(context.WithoutCancel(ctx), dstStorage, dstDirPath, false)
+	}
+	return nil
+}
+
+// PutURL makes the storage fetch the url into dstDirPath as dstName, size is the size of the file of the url
+// reserved in the quota of the user
+func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url string, size int64) error {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -730,6 +1064,9 @@ func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url
 	if model.ObjHasMask(dstDir, model.NoWrite) {
 		return errors.WithStack(errs.PermissionDenied)
 	}
+	if err = reserveQuota(ctx, storage, dstDirPath, size); err != nil {
+		return err
+	}
 	var newObj model.Obj
 	switch s := storage.(type) {
 	case driver.PutURLResult:
@@ -740,6 +1077,7 @@ func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url
 		return errors.WithStack(errs.NotImplement)
 	}
 	if err == nil {
+		publishFsEvent(storage, model.FsEventCreate, dstPath, "", false)
 		Cache.linkCache.DeleteKey(Key(storage, dstPath))
 		if !storage.Config().NoCache {
 			if cache, exist := Cache.dirCache.Get(Key(storage, dstDirPath)); exist {
@@ -777,6 +1115,7 @@ func GetDirectUploadTools(storage driver.Driver) []string {
 }
 
 func GetDirectUploadInfo(ctx context.Context, tool string, storage driver.Driver, dstDirPath, dstName string, fileSize int64) (any, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	du, ok := storage.(driver.DirectUploader)
 	if !ok {
 		return nil, errors.WithStack(errs.NotImplement)
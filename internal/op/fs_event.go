@@ -0,0 +1,150 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// FsEventSubscriber receives the events under Prefix from C,
+// the events are dropped instead of blocking the mutations if it falls behind
+type FsEventSubscriber struct {
+	Prefix string
+	C      chan model.FsEvent
+}
+
+var fsEventSubscribers = struct {
+	sync.RWMutex
+	m map[*FsEventSubscriber]struct{}
+}{m: make(map[*FsEventSubscriber]struct{})}
+
+func SubscribeFsEvents(prefix string) *FsEventSubscriber {
+	s := &FsEventSubscriber{
+		Prefix: utils.FixAndCleanPath(prefix),
+		C:      make(chan model.FsEvent, 64),
+	}
+	fsEventSubscribers.Lock()
+	fsEventSubscribers.m[s] = struct{}{}
+	fsEventSubscribers.Unlock()
+	return s
+}
+
+func UnsubscribeFsEvents(s *FsEventSubscriber) {
+	fsEventSubscribers.Lock()
+	defer fsEventSubscribers.Unlock()
+	if _, ok := fsEventSubscribers.m[s]; ok {
+		delete(fsEventSubscribers.m, s)
+		close(s.C)
+	}
+}
+
+// FsEventHook is called with every event in order, unlike the subscribers it misses no event,
+// but it blocks the mutation so it has to be quick
+type FsEventHook func(event model.FsEvent)
+
+var fsEventHooks []FsEventHook
+
+func RegisterFsEventHook(hook FsEventHook) {
+	fsEventHooks = append(fsEventHooks, hook)
+}
+
+func PublishFsEvent(event model.FsEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, hook := range fsEventHooks {
+		hook(event)
+	}
+	fsEventSubscribers.RLock()
+	defer fsEventSubscribers.RUnlock()
+	for s := range fsEventSubscribers.m {
+		if !event.Under(s.Prefix) {
+			continue
+		}
+		select {
+		case s.C <- event:
+		default:
+			log.Warnf("fs event subscriber of [%s] falls behind, dropped event: %+v", s.Prefix, event)
+		}
+	}
+}
+
+// publishFsEvent publishes the event of the paths in the storage
+func publishFsEvent(storage driver.Driver, typ, path, dstPath string, isDir bool) {
+	mountPath := storage.GetStorage().MountPath
+	event := model.FsEvent{
+		Type:  typ,
+		Path:  utils.GetFullPath(mountPath, path),
+		IsDir: isDir,
+	}
+	if dstPath != "" {
+		event.DstPath = utils.GetFullPath(mountPath, dstPath)
+	}
+	PublishFsEvent(event)
+}
+
+var fsWatchers = struct {
+	sync.Mutex
+	m map[uint]context.CancelFunc
+}{m: make(map[uint]context.CancelFunc)}
+
+// watchStorage keeps the change API of the storage watched while it is loaded
+func watchStorage(typ string, storage driver.Driver) {
+	id := storage.GetStorage().ID
+	fsWatchers.Lock()
+	defer fsWatchers.Unlock()
+	if cancel, ok := fsWatchers.m[id]; ok {
+		cancel()
+		delete(fsWatchers.m, id)
+	}
+	w, ok := storage.(driver.Watcher)
+	if typ == "del" || !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	fsWatchers.m[id] = cancel
+	go func() {
+		for {
+			err := w.Watch(ctx, func(event model.FsEvent) {
+				handleRemoteFsEvent(storage, event)
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warnf("failed watch the changes of [%s], retry in a minute: %+v", storage.GetStorage().MountPath, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Minute):
+			}
+		}
+	}()
+}
+
+// handleRemoteFsEvent drops the stale caches of a change made outside OpenList and publishes it
+func handleRemoteFsEvent(storage driver.Driver, event model.FsEvent) {
+	event.Path = utils.FixAndCleanPath(event.Path)
+	Cache.DeleteDirectory(storage, stdpath.Dir(event.Path))
+	if event.IsDir {
+		Cache.DeleteDirectoryTree(storage, event.Path)
+	}
+	mountPath := storage.GetStorage().MountPath
+	if event.DstPath != "" {
+		event.DstPath = utils.FixAndCleanPath(event.DstPath)
+		Cache.DeleteDirectory(storage, stdpath.Dir(event.DstPath))
+		event.DstPath = utils.GetFullPath(mountPath, event.DstPath)
+	}
+	event.Path = utils.GetFullPath(mountPath, event.Path)
+	event.Remote = true
+	PublishFsEvent(event)
+}
+
+func init() {
+	RegisterStorageHook(watchStorage)
+}
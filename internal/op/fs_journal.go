@@ -0,0 +1,71 @@
+package op
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+)
+
+// the fs journal keeps the latest events in memory for the sync clients polling the changes by a token.
+// A token is the sequence number of the last event seen prefixed by the id of the journal,
+// which changes on restart so that the tokens of the lost events are refused
+
+const fsJournalSize = 10000
+
+var fsJournal = struct {
+	sync.Mutex
+	id     string
+	events []model.FsEvent
+	// seq is the sequence number of the last event, the ones of the events are seq-len(events)+1 to seq
+	seq uint64
+}{id: random.String(8)}
+
+func journalFsEvent(event model.FsEvent) {
+	fsJournal.Lock()
+	defer fsJournal.Unlock()
+	fsJournal.events = append(fsJournal.events, event)
+	if len(fsJournal.events) >= 2*fsJournalSize {
+		fsJournal.events = append([]model.FsEvent(nil), fsJournal.events[fsJournalSize:]...)
+	}
+	fsJournal.seq++
+}
+
+// FsJournalToken is the token of the changes made from now
+func FsJournalToken() string {
+	fsJournal.Lock()
+	defer fsJournal.Unlock()
+	return fmt.Sprintf("%s.%d", fsJournal.id, fsJournal.seq)
+}
+
+// FsChangesSince returns at most limit events under prefix made after the token and the token of the next changes,
+// ok is false if the token is of another journal or the events after it are dropped, so a full sync is needed
+func FsChangesSince(token, prefix string, limit int) (events []model.FsEvent, next string, ok bool) {
+	id, seqStr, _ := strings.Cut(token, ".")
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	fsJournal.Lock()
+	defer fsJournal.Unlock()
+	first := fsJournal.seq - uint64(len(fsJournal.events)) + 1
+	if err != nil || id != fsJournal.id || seq > fsJournal.seq || seq+1 < first {
+		return nil, fmt.Sprintf("%s.%d", fsJournal.id, fsJournal.seq), false
+	}
+	prefix = utils.FixAndCleanPath(prefix)
+	for _, event := range fsJournal.events[seq+1-first:] {
+		if limit > 0 && len(events) >= limit {
+			break
+		}
+		seq++
+		if event.Under(prefix) {
+			events = append(events, event)
+		}
+	}
+	return events, fmt.Sprintf("%s.%d", fsJournal.id, seq), true
+}
+
+func init() {
+	RegisterFsEventHook(journalFsEvent)
+}
@@ -0,0 +1,40 @@
+package op
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestFsChangesSince(t *testing.T) {
+	token := FsJournalToken()
+	journalFsEvent(model.FsEvent{Type: model.FsEventCreate, Path: "/a/1.txt"})
+	journalFsEvent(model.FsEvent{Type: model.FsEventCreate, Path: "/b/2.txt"})
+	journalFsEvent(model.FsEvent{Type: model.FsEventMove, Path: "/b/3.txt", DstPath: "/a/3.txt"})
+
+	events, next, ok := FsChangesSince(token, "/a", 0)
+	if !ok || len(events) != 2 || events[0].Path != "/a/1.txt" || events[1].DstPath != "/a/3.txt" {
+		t.Fatalf("FsChangesSince() = %v, %v", events, ok)
+	}
+	if next != FsJournalToken() {
+		t.Errorf("next token = %s, want %s", next, FsJournalToken())
+	}
+	if events, _, ok = FsChangesSince(next, "/", 0); !ok || len(events) != 0 {
+		t.Errorf("FsChangesSince(next) = %v, %v, want no changes", events, ok)
+	}
+
+	// the events after the limit are returned with the next token
+	events, next, _ = FsChangesSince(token, "/", 1)
+	if len(events) != 1 || events[0].Path != "/a/1.txt" {
+		t.Fatalf("FsChangesSince() with limit = %v", events)
+	}
+	if events, _, _ = FsChangesSince(next, "/", 0); len(events) != 2 {
+		t.Errorf("FsChangesSince() after limit = %v, want 2 events", events)
+	}
+
+	for _, token := range []string{"", "other.0", fsJournal.id + ".999999"} {
+		if _, _, ok := FsChangesSince(token, "/", 0); ok {
+			t.Errorf("FsChangesSince(%q) should need a reset", token)
+		}
+	}
+}
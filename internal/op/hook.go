@@ -2,6 +2,7 @@ package op
 
 import (
 	"context"
+	"mime"
 	"regexp"
 	"strings"
 
@@ -58,6 +59,25 @@ var settingItemHooks = map[string]SettingItemHook{
 		conf.SlicesMap[conf.ProxyIgnoreHeaders] = strings.Split(item.Value, ",")
 		return nil
 	},
+	conf.MimeTypes: func(item *model.SettingItem) error {
+		var types map[string]string
+		if err := utils.Json.UnmarshalFromString(item.Value, &types); err != nil {
+			return errors.WithStack(err)
+		}
+		overrides := make(map[string]string, len(types))
+		for ext, typ := range types {
+			if _, _, err := mime.ParseMediaType(typ); err != nil {
+				return errors.Errorf("invalid mime type of %s: %s", ext, typ)
+			}
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			overrides[ext] = typ
+		}
+		utils.SetMimeTypeOverrides(overrides)
+		return nil
+	},
 	conf.PrivacyRegs: func(item *model.SettingItem) error {
 		regStrs := strings.Split(item.Value, "\n")
 		regs := make([]*regexp.Regexp, 0, len(regStrs))
@@ -83,6 +103,91 @@ var settingItemHooks = map[string]SettingItemHook{
 		conf.SlicesMap[conf.IgnoreDirectLinkParams] = strings.Split(item.Value, ",")
 		return nil
 	},
+	conf.RoleFeatureFlags: func(item *model.SettingItem) error {
+		var flags map[string]map[string]bool
+		if err := utils.Json.UnmarshalFromString(item.Value, &flags); err != nil {
+			return errors.WithStack(err)
+		}
+		features := make(map[int]map[string]bool, len(flags))
+		for name, f := range flags {
+			var role int
+			switch name {
+			case "general":
+				role = model.GENERAL
+			case "guest":
+				role = model.GUEST
+			default:
+				return errors.Errorf("unknown role: %s", name)
+			}
+			for feature := range f {
+				switch feature {
+				case model.FeatureOfflineDownload, model.FeatureDecompress, model.FeatureShare, model.FeatureSeeAllTasks:
+				default:
+					return errors.Errorf("unknown feature: %s", feature)
+				}
+			}
+			features[role] = f
+		}
+		model.RoleFeatures = features
+		return nil
+	},
+	conf.RoleStorageGroups: func(item *model.SettingItem) error {
+		var groups map[string][]string
+		if err := utils.Json.UnmarshalFromString(item.Value, &groups); err != nil {
+			return errors.WithStack(err)
+		}
+		roleGroups := make(map[int][]string, len(groups))
+		for name, g := range groups {
+			switch name {
+			case "general":
+				roleGroups[model.GENERAL] = g
+			case "guest":
+				roleGroups[model.GUEST] = g
+			default:
+				return errors.Errorf("unknown role: %s", name)
+			}
+		}
+		model.RoleStorageGroups = roleGroups
+		return nil
+	},
+	conf.UserTaskLimits: func(item *model.SettingItem) error {
+		var limits map[string]map[string]int
+		if err := utils.Json.UnmarshalFromString(item.Value, &limits); err != nil {
+			return errors.WithStack(err)
+		}
+		for name, l := range limits {
+			for taskType, limit := range l {
+				switch taskType {
+				case model.TaskTypeUpload, model.TaskTypeCopy, model.TaskTypeMove, model.TaskTypeOfflineDownload, model.TaskTypeDecompress:
+				default:
+					return errors.Errorf("unknown task type: %s", taskType)
+				}
+				if limit < 0 {
+					return errors.Errorf("invalid limit of %s: %d", taskType, limit)
+				}
+			}
+			switch name {
+			case "default", "general", "guest", "admin":
+			default:
+				return errors.Errorf("unknown role: %s", name)
+			}
+		}
+		roleLimits := map[int]map[string]int{
+			model.GENERAL: limits["default"],
+			model.GUEST:   limits["default"],
+		}
+		if l, ok := limits["general"]; ok {
+			roleLimits[model.GENERAL] = l
+		}
+		if l, ok := limits["guest"]; ok {
+			roleLimits[model.GUEST] = l
+		}
+		if l, ok := limits["admin"]; ok {
+			roleLimits[model.ADMIN] = l
+		}
+		model.RoleTaskLimits = roleLimits
+		return nil
+	},
 }
 
 func RegisterSettingItemHook(key string, hook SettingItemHook) {
@@ -0,0 +1,57 @@
+package op
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+func TestUserTaskLimitsHook(t *testing.T) {
+	defer func() { model.RoleTaskLimits = make(map[int]map[string]int) }()
+	item := &model.SettingItem{Key: conf.UserTaskLimits, Value: `{"default":{"copy":10,"move":5},"guest":{"copy":2}}`}
+	if _, err := HandleSettingItemHook(item); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		role     int
+		taskType string
+		want     int
+	}{
+		{model.GENERAL, model.TaskTypeCopy, 10},
+		{model.GENERAL, model.TaskTypeMove, 5},
+		{model.GUEST, model.TaskTypeCopy, 2},
+		{model.GUEST, model.TaskTypeMove, 0},
+		{model.ADMIN, model.TaskTypeCopy, 0},
+	}
+	for _, tt := range tests {
+		u := &model.User{Role: tt.role}
+		if got := u.TaskLimit(tt.taskType); got != tt.want {
+			t.Errorf("TaskLimit(%s) of role %d = %d, want %d", tt.taskType, tt.role, got, tt.want)
+		}
+	}
+	for _, value := range []string{`{"default":{"scrub":1}}`, `{"owner":{"copy":1}}`, `{"general":{"copy":-1}}`} {
+		item.Value = value
+		if _, err := HandleSettingItemHook(item); err == nil {
+			t.Errorf("expected error for %s", value)
+		}
+	}
+}
+
+func TestMimeTypesHook(t *testing.T) {
+	defer utils.SetMimeTypeOverrides(nil)
+	item := &model.SettingItem{Key: conf.MimeTypes, Value: `{"MD":"text/markdown; charset=utf-8",".m4b":"audio/mp4"}`}
+	if _, err := HandleSettingItemHook(item); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"a.md": "text/markdown; charset=utf-8", "b.M4B": "audio/mp4"} {
+		if got := utils.GetMimeType(name); got != want {
+			t.Errorf("GetMimeType(%s) = %s, want %s", name, got, want)
+		}
+	}
+	item.Value = `{"md":"not a type;"}`
+	if _, err := HandleSettingItemHook(item); err == nil {
+		t.Errorf("expected error for %s", item.Value)
+	}
+}
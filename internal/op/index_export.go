@@ -0,0 +1,57 @@
+package op
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func GetIndexExports() ([]model.IndexExport, error) {
+	return db.GetIndexExports()
+}
+
+func GetIndexExportById(id uint) (*model.IndexExport, error) {
+	return db.GetIndexExportById(id)
+}
+
+func CreateIndexExport(e *model.IndexExport) error {
+	if err := validateIndexExport(e); err != nil {
+		return err
+	}
+	return db.CreateIndexExport(e)
+}
+
+func UpdateIndexExport(e *model.IndexExport) error {
+	if err := validateIndexExport(e); err != nil {
+		return err
+	}
+	old, err := db.GetIndexExportById(e.ID)
+	if err != nil {
+		return err
+	}
+	e.LastExported = old.LastExported
+	e.LastError = old.LastError
+	return db.UpdateIndexExport(e)
+}
+
+// SaveIndexExportResult records the result of an export run
+func SaveIndexExportResult(e *model.IndexExport) error {
+	return db.UpdateIndexExport(e)
+}
+
+func DeleteIndexExportById(id uint) error {
+	return db.DeleteIndexExportById(id)
+}
+
+func validateIndexExport(e *model.IndexExport) error {
+	e.Path = utils.FixAndCleanPath(e.Path)
+	e.DstPath = utils.FixAndCleanPath(e.DstPath)
+	if utils.IsSubPath(e.Path, e.DstPath) {
+		return errors.New("the destination can't be inside the exported directory")
+	}
+	if e.MaxDepth < 0 || e.Interval < 0 {
+		return errors.New("max depth and interval can't be negative")
+	}
+	return nil
+}
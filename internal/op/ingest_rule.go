@@ -0,0 +1,88 @@
+package op
+
+import (
+	"net/url"
+	"path"
+	"text/template"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func GetIngestRules() ([]model.IngestRule, error) {
+	return db.GetIngestRules()
+}
+
+func GetIngestRuleById(id uint) (*model.IngestRule, error) {
+	return db.GetIngestRuleById(id)
+}
+
+func CreateIngestRule(r *model.IngestRule) error {
+	if err := ValidateIngestRule(r); err != nil {
+		return err
+	}
+	return db.CreateIngestRule(r)
+}
+
+func UpdateIngestRule(r *model.IngestRule) error {
+	if err := ValidateIngestRule(r); err != nil {
+		return err
+	}
+	old, err := db.GetIngestRuleById(r.ID)
+	if err != nil {
+		return err
+	}
+	r.LastRun = old.LastRun
+	r.LastError = old.LastError
+	return db.UpdateIngestRule(r)
+}
+
+// SaveIngestRuleResult records the result of a scan
+func SaveIngestRuleResult(r *model.IngestRule) error {
+	return db.UpdateIngestRule(r)
+}
+
+func DeleteIngestRuleById(id uint) error {
+	return db.DeleteIngestRuleById(id)
+}
+
+// ValidateIngestRule checks and normalizes the rule
+func ValidateIngestRule(r *model.IngestRule) error {
+	r.Path = utils.FixAndCleanPath(r.Path)
+	if r.Action == "" {
+		r.Action = model.IngestMove
+	}
+	switch r.Action {
+	case model.IngestMove, model.IngestCopy:
+		if r.Dst == "" {
+			return errors.New("destination is required")
+		}
+		if _, err := template.New("dst").Parse(r.Dst); err != nil {
+			return errors.WithMessage(err, "invalid destination template")
+		}
+	case model.IngestDelete:
+	case model.IngestNotify:
+		if r.NotifyURL == "" {
+			return errors.New("notify url is required")
+		}
+	default:
+		return errors.Errorf("invalid action: %s", r.Action)
+	}
+	if r.NotifyURL != "" {
+		if u, err := url.Parse(r.NotifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.New("invalid notify url")
+		}
+	}
+	if _, err := path.Match(r.Pattern, ""); err != nil {
+		return errors.WithMessage(err, "invalid pattern")
+	}
+	if _, err := path.Match(r.Mime, ""); err != nil {
+		return errors.WithMessage(err, "invalid mime pattern")
+	}
+	if r.Interval < 0 || r.MinSize < 0 || r.MaxSize < 0 || r.MinAge < 0 || r.MaxAge < 0 || r.KeepNewest < 0 {
+		return errors.New("interval, sizes, ages and kept files can't be negative")
+	}
+	return nil
+}
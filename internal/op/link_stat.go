@@ -0,0 +1,23 @@
+package op
+
+import "sync/atomic"
+
+// LinkCacheStats counts how the links requested since the start were served
+type LinkCacheStats struct {
+	// served from the link cache
+	Hits uint64 `json:"hits"`
+	// waited for a concurrent request of the same link
+	Shared uint64 `json:"shared"`
+	// requested from the storage
+	Misses uint64 `json:"misses"`
+}
+
+var linkCacheHits, linkCacheShared, linkCacheMisses atomic.Uint64
+
+func GetLinkCacheStats() LinkCacheStats {
+	return LinkCacheStats{
+		Hits:   linkCacheHits.Load(),
+		Shared: linkCacheShared.Load(),
+		Misses: linkCacheMisses.Load(),
+	}
+}
@@ -68,7 +68,9 @@ func DeleteMetaById(id uint) error {
 		return err
 	}
 	metaCache.Del(old.Path)
-	return db.DeleteMetaById(id)
+	err = db.DeleteMetaById(id)
+	publishInvalidation(model.CacheInvalidation{Kind: model.InvalidateMetas, Keys: []string{old.Path}})
+	return err
 }
 
 func UpdateMeta(u *model.Meta) error {
@@ -78,13 +80,18 @@ func UpdateMeta(u *model.Meta) error {
 		return err
 	}
 	metaCache.Del(old.Path)
-	return db.UpdateMeta(u)
+	metaCache.Del(u.Path)
+	err = db.UpdateMeta(u)
+	publishInvalidation(model.CacheInvalidation{Kind: model.InvalidateMetas, Keys: []string{old.Path, u.Path}})
+	return err
 }
 
 func CreateMeta(u *model.Meta) error {
 	u.Path = utils.FixAndCleanPath(u.Path)
 	metaCache.Del(u.Path)
-	return db.CreateMeta(u)
+	err := db.CreateMeta(u)
+	publishInvalidation(model.CacheInvalidation{Kind: model.InvalidateMetas, Keys: []string{u.Path}})
+	return err
 }
 
 func GetMetaById(id uint) (*model.Meta, error) {
@@ -0,0 +1,67 @@
+package op
+
+import (
+	"context"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+func ObjectIndexEnabled() bool {
+	item, _ := GetSettingItemByKey(conf.ObjectIndex)
+	return item != nil && (item.Value == "true" || item.Value == "1")
+}
+
+// UpdateObjectIndex replaces the indexed objects of the directory with the objects just listed
+func UpdateObjectIndex(parent string, objs []model.Obj) error {
+	parent = utils.FixAndCleanPath(parent)
+	mountPath := ""
+	if storage, _, err := GetStorageAndActualPath(parent); err == nil {
+		mountPath = storage.GetStorage().MountPath
+	}
+	indexes := make([]model.ObjectIndex, 0, len(objs))
+	for _, obj := range objs {
+		indexes = append(indexes, model.NewObjectIndex(parent, mountPath, obj))
+	}
+	return db.ReplaceObjectIndexes(parent, indexes)
+}
+
+func GetObjectIndexes(req model.ObjectIndexReq) ([]model.ObjectIndex, int64, error) {
+	return db.GetObjectIndexes(req)
+}
+
+func ClearObjectIndexes() error {
+	return db.ClearObjectIndexes()
+}
+
+func objectIndexOnUpdate(ctx context.Context, parent string, objs []model.Obj) {
+	if !ObjectIndexEnabled() {
+		return
+	}
+	if err := UpdateObjectIndex(parent, objs); err != nil {
+		log.Errorf("failed update object index of %s: %+v", parent, err)
+	}
+}
+
+// objectIndexOnEvent drops the moved and deleted objects, the new ones are indexed when their directory is listed
+func objectIndexOnEvent(event model.FsEvent) {
+	if event.Type != model.FsEventDelete && event.Type != model.FsEventRename && event.Type != model.FsEventMove {
+		return
+	}
+	if !ObjectIndexEnabled() {
+		return
+	}
+	go func() {
+		if err := db.DeleteObjectIndexesByPath(event.Path); err != nil {
+			log.Errorf("failed delete object index of %s: %+v", event.Path, err)
+		}
+	}()
+}
+
+func init() {
+	RegisterObjsUpdateHook(objectIndexOnUpdate)
+	RegisterFsEventHook(objectIndexOnEvent)
+}
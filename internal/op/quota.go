@@ -0,0 +1,34 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+)
+
+// QuotaFunc reserves size more bytes put in the full path dstDirPath for the user of ctx, it fails with
+// errs.QuotaExceeded if they exceed the quota of the user. size is negative if the usage decreases
+type QuotaFunc func(ctx context.Context, dstDirPath string, size int64) error
+
+var quotaFunc QuotaFunc
+
+func RegisterQuotaFunc(f QuotaFunc) {
+	quotaFunc = f
+}
+
+// reserveQuota reserves the bytes of a write to dstDirPath of the storage,
+// the nested puts of the drivers wrapping other storages are counted by the outer one
+func reserveQuota(ctx context.Context, storage driver.Driver, dstDirPath string, size int64) error {
+	if quotaFunc == nil || size == 0 || ctx.Value(conf.NestedPutKey) != nil {
+		return nil
+	}
+	return quotaFunc(ctx, stdpath.Join(storage.GetStorage().MountPath, dstDirPath), size)
+}
+
+// nestedPutCtx is the ctx of the put of the driver, the writes of the driver into the storages it wraps
+// aren't counted again
+func nestedPutCtx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, conf.NestedPutKey, struct{}{})
+}
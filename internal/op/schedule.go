@@ -0,0 +1,92 @@
+package op
+
+import (
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func GetSchedules() ([]model.Schedule, error) {
+	return db.GetSchedules()
+}
+
+func GetScheduleById(id uint) (*model.Schedule, error) {
+	return db.GetScheduleById(id)
+}
+
+func CreateSchedule(s *model.Schedule) error {
+	if err := ValidateSchedule(s); err != nil {
+		return err
+	}
+	s.Created = time.Now()
+	return db.CreateSchedule(s)
+}
+
+func UpdateSchedule(s *model.Schedule) error {
+	if err := ValidateSchedule(s); err != nil {
+		return err
+	}
+	old, err := db.GetScheduleById(s.ID)
+	if err != nil {
+		return err
+	}
+	s.Created = old.Created
+	s.LastRun = old.LastRun
+	s.LastError = old.LastError
+	return db.UpdateSchedule(s)
+}
+
+// SaveScheduleResult records the result of a run
+func SaveScheduleResult(s *model.Schedule) error {
+	return db.UpdateSchedule(s)
+}
+
+func DeleteScheduleById(id uint) error {
+	return db.DeleteScheduleById(id)
+}
+
+// ValidateSchedule checks and normalizes the schedule
+func ValidateSchedule(s *model.Schedule) error {
+	if _, err := cron.ParseExpr(s.Cron); err != nil {
+		return errors.WithMessage(err, "invalid cron expression")
+	}
+	if s.Op == "" {
+		s.Op = model.ScheduleCopy
+	}
+	if s.Op != model.ScheduleCopy && s.Op != model.ScheduleMove {
+		return errors.Errorf("invalid op: %s", s.Op)
+	}
+	if s.Policy == "" {
+		s.Policy = model.ScheduleSkip
+	}
+	switch s.Policy {
+	case model.ScheduleSkip, model.ScheduleOverwrite:
+	case model.ScheduleMerge:
+		if s.Op != model.ScheduleCopy {
+			return errors.New("only the copies can be merged")
+		}
+	default:
+		return errors.Errorf("invalid policy: %s", s.Policy)
+	}
+	s.SrcDir = utils.FixAndCleanPath(s.SrcDir)
+	s.DstDir = utils.FixAndCleanPath(s.DstDir)
+	if utils.IsSubPath(s.SrcDir, s.DstDir) {
+		return errors.New("the destination can't be in the source")
+	}
+	for _, name := range s.Names {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+			return errors.Errorf("invalid name: %s", name)
+		}
+	}
+	if s.UserId != 0 {
+		if _, err := GetUserById(s.UserId); err != nil {
+			return errors.WithMessage(err, "invalid user")
+		}
+	}
+	return nil
+}
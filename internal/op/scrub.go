@@ -0,0 +1,39 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+// RecordScrubFile records the file at path, which is verified against src, to be scrubbed later
+func RecordScrubFile(path string, src model.Obj) error {
+	f, err := db.GetScrubFileByPath(path)
+	if err != nil {
+		f = &model.ScrubFile{Path: path}
+	}
+	f.Size = src.GetSize()
+	f.Hash = src.GetHash().String()
+	f.Recorded = time.Now()
+	f.LastScrub = nil
+	f.Error = ""
+	return db.SaveScrubFile(f)
+}
+
+func GetScrubFiles(pageIndex, pageSize int, failedOnly bool) ([]model.ScrubFile, int64, error) {
+	return db.GetScrubFiles(pageIndex, pageSize, failedOnly)
+}
+
+func GetScrubFilesToVerify(limit int) ([]model.ScrubFile, error) {
+	return db.GetScrubFilesToVerify(limit)
+}
+
+// SaveScrubResult records the result of a scrub of the file
+func SaveScrubResult(f *model.ScrubFile) error {
+	return db.SaveScrubFile(f)
+}
+
+func DeleteScrubFileById(id uint) error {
+	return db.DeleteScrubFileById(id)
+}
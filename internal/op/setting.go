@@ -29,6 +29,11 @@ func RegisterSettingChangingCallback(f func()) {
 }
 
 func SettingCacheUpdate() {
+	settingCacheUpdate()
+	publishInvalidation(model.CacheInvalidation{Kind: model.InvalidateSettings})
+}
+
+func settingCacheUpdate() {
 	Cache.ClearAll()
 	for _, cb := range settingChangingCallbacks {
 		cb()
@@ -194,8 +199,9 @@ func DeleteSettingItemByKey(key string) error {
 	if !old.IsDeprecated() {
 		return errors.Errorf("setting [%s] is not deprecated", key)
 	}
+	err = db.DeleteSettingItemByKey(key)
 	SettingCacheUpdate()
-	return db.DeleteSettingItemByKey(key)
+	return err
 }
 
 type MigrationValueItem struct {
@@ -0,0 +1,320 @@
+package op
+
+import (
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type siteUsers struct {
+	names map[uint]string
+	ids   map[string]uint
+}
+
+func getSiteUsers() (*siteUsers, error) {
+	users, _, err := GetUsers(1, -1)
+	if err != nil {
+		return nil, err
+	}
+	u := &siteUsers{names: make(map[uint]string), ids: make(map[string]uint)}
+	for _, user := range users {
+		u.names[user.ID] = user.Username
+		u.ids[user.Username] = user.ID
+	}
+	return u, nil
+}
+
+// toNames drops the users which don't exist anymore
+func (u *siteUsers) toNames(ids []uint) []string {
+	var names []string
+	for _, id := range ids {
+		if name, ok := u.names[id]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (u *siteUsers) toIds(names []string) ([]uint, error) {
+	var ids []uint
+	for _, name := range names {
+		id, ok := u.ids[name]
+		if !ok {
+			return nil, errors.Errorf("user [%s] not found", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ExportSiteConfig returns the metas, hide rules and shares as a yaml document
+func ExportSiteConfig() ([]byte, error) {
+	users, err := getSiteUsers()
+	if err != nil {
+		return nil, err
+	}
+	var cfg model.SiteConfig
+	metas, _, err := db.GetMetas(1, -1)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range metas {
+		cfg.Metas = append(cfg.Metas, model.SiteMeta{
+			Path:          m.Path,
+			ReadUsers:     users.toNames(m.ReadUsers),
+			ReadUsersSub:  m.ReadUsersSub,
+			WriteUsers:    users.toNames(m.WriteUsers),
+			WriteUsersSub: m.WriteUsersSub,
+			Password:      m.Password,
+			PSub:          m.PSub,
+			PInherit:      m.PInherit,
+			PExemptUsers:  users.toNames(m.PExemptUsers),
+			PExemptRoles:  m.PExemptRoles,
+			Write:         m.Write,
+			WSub:          m.WSub,
+			Readme:        m.Readme,
+			RSub:          m.RSub,
+			Header:        m.Header,
+			HeaderSub:     m.HeaderSub,
+			Footer:        m.Footer,
+			FooterSub:     m.FooterSub,
+			OrderBy:       m.OrderBy,
+			OrderDir:      m.OrderDirection,
+			ExtractFolder: m.ExtractFolder,
+			SortSub:       m.SortSub,
+		})
+		var patterns []string
+		for _, line := range strings.Split(m.Hide, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				patterns = append(patterns, line)
+			}
+		}
+		if len(patterns) > 0 {
+			cfg.Hides = append(cfg.Hides, model.SiteHide{
+				Path:        m.Path,
+				Patterns:    patterns,
+				Sub:         m.HSub,
+				ExemptUsers: users.toNames(m.HExemptUsers),
+				Protocols:   m.HProtocols,
+			})
+		}
+	}
+	sharings, _, err := GetSharings(1, -1)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sharings {
+		creator, ok := users.names[s.CreatorId]
+		if !ok {
+			continue
+		}
+		cfg.Shares = append(cfg.Shares, model.SiteShare{
+			ID:          s.ID,
+			Creator:     creator,
+			Files:       s.Files,
+			Expires:     s.Expires,
+			Pwd:         s.Pwd,
+			MaxAccessed: s.MaxAccessed,
+			Disabled:    s.Disabled,
+			Remark:      s.Remark,
+			Readme:      s.Readme,
+			Header:      s.Header,
+			OrderBy:     s.OrderBy,
+			OrderDir:    s.OrderDirection,
+		})
+	}
+	data, err := yaml.Marshal(&cfg)
+	return data, errors.WithStack(err)
+}
+
+// siteMetas merges the metas and hide rules of the document by path
+func siteMetas(cfg *model.SiteConfig, users *siteUsers) (map[string]*model.Meta, error) {
+	metas := make(map[string]*model.Meta)
+	var err error
+	for _, m := range cfg.Metas {
+		path := utils.FixAndCleanPath(m.Path)
+		if _, ok := metas[path]; ok {
+			return nil, errors.Errorf("duplicate meta [%s]", path)
+		}
+		meta := &model.Meta{
+			Path:          path,
+			ReadUsersSub:  m.ReadUsersSub,
+			WriteUsersSub: m.WriteUsersSub,
+			Password:      m.Password,
+			PSub:          m.PSub,
+			PInherit:      m.PInherit,
+			PExemptRoles:  m.PExemptRoles,
+			Write:         m.Write,
+			WSub:          m.WSub,
+			Readme:        m.Readme,
+			RSub:          m.RSub,
+			Header:        m.Header,
+			HeaderSub:     m.HeaderSub,
+			Footer:        m.Footer,
+			FooterSub:     m.FooterSub,
+			Sort: model.Sort{
+				OrderBy:        m.OrderBy,
+				OrderDirection: m.OrderDir,
+				ExtractFolder:  m.ExtractFolder,
+			},
+			SortSub: m.SortSub,
+		}
+		if meta.ReadUsers, err = users.toIds(m.ReadUsers); err != nil {
+			return nil, errors.WithMessagef(err, "invalid meta [%s]", path)
+		}
+		if meta.WriteUsers, err = users.toIds(m.WriteUsers); err != nil {
+			return nil, errors.WithMessagef(err, "invalid meta [%s]", path)
+		}
+		if meta.PExemptUsers, err = users.toIds(m.PExemptUsers); err != nil {
+			return nil, errors.WithMessagef(err, "invalid meta [%s]", path)
+		}
+		metas[path] = meta
+	}
+	hidden := make(map[string]struct{})
+	for _, h := range cfg.Hides {
+		path := utils.FixAndCleanPath(h.Path)
+		if _, ok := hidden[path]; ok {
+			return nil, errors.Errorf("duplicate hide rule [%s]", path)
+		}
+		hidden[path] = struct{}{}
+		meta, ok := metas[path]
+		if !ok {
+			meta = &model.Meta{Path: path}
+			metas[path] = meta
+		}
+		meta.Hide = strings.Join(h.Patterns, "\n")
+		meta.HSub = h.Sub
+		meta.HProtocols = h.Protocols
+		if meta.HExemptUsers, err = users.toIds(h.ExemptUsers); err != nil {
+			return nil, errors.WithMessagef(err, "invalid hide rule [%s]", path)
+		}
+		if _, err = meta.HideRule(); err != nil {
+			return nil, errors.WithMessagef(err, "invalid hide rule [%s]", path)
+		}
+	}
+	return metas, nil
+}
+
+func siteSharings(cfg *model.SiteConfig) (map[string]*model.Sharing, error) {
+	sharings := make(map[string]*model.Sharing)
+	for _, s := range cfg.Shares {
+		if s.ID == "" || len(s.ID) > 12 {
+			return nil, errors.Errorf("invalid share id [%s]", s.ID)
+		}
+		if _, ok := sharings[s.ID]; ok {
+			return nil, errors.Errorf("duplicate share [%s]", s.ID)
+		}
+		if len(s.Files) == 0 {
+			return nil, errors.Errorf("share [%s] has no files", s.ID)
+		}
+		creator, err := GetUserByName(s.Creator)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid creator of share [%s]", s.ID)
+		}
+		sharings[s.ID] = &model.Sharing{
+			SharingDB: &model.SharingDB{
+				ID:          s.ID,
+				Expires:     s.Expires,
+				Pwd:         s.Pwd,
+				MaxAccessed: s.MaxAccessed,
+				Disabled:    s.Disabled,
+				Remark:      s.Remark,
+				Readme:      s.Readme,
+				Header:      s.Header,
+				Sort: model.Sort{
+					OrderBy:        s.OrderBy,
+					OrderDirection: s.OrderDir,
+				},
+			},
+			Files:   s.Files,
+			Creator: creator,
+		}
+	}
+	return sharings, nil
+}
+
+// ImportSiteConfig applies the yaml document, the metas are matched by path and the shares by id,
+// so that importing the same document again changes nothing. The metas and shares missing from
+// the document are deleted if prune is true
+func ImportSiteConfig(data []byte, prune bool) (*model.SiteConfigResult, error) {
+	var cfg model.SiteConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrap(err, "invalid site config")
+	}
+	users, err := getSiteUsers()
+	if err != nil {
+		return nil, err
+	}
+	// validate the whole document before changing anything
+	metas, err := siteMetas(&cfg, users)
+	if err != nil {
+		return nil, err
+	}
+	sharings, err := siteSharings(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	res := &model.SiteConfigResult{}
+	oldMetas, _, err := db.GetMetas(1, -1)
+	if err != nil {
+		return nil, err
+	}
+	for _, old := range oldMetas {
+		meta, ok := metas[old.Path]
+		if !ok {
+			if prune {
+				if err := DeleteMetaById(old.ID); err != nil {
+					return res, err
+				}
+				res.MetasDeleted++
+			}
+			continue
+		}
+		meta.ID = old.ID
+		if err := UpdateMeta(meta); err != nil {
+			return res, err
+		}
+		delete(metas, old.Path)
+		res.MetasUpdated++
+	}
+	for _, meta := range metas {
+		if err := CreateMeta(meta); err != nil {
+			return res, err
+		}
+		res.MetasCreated++
+	}
+	oldSharings, _, err := db.GetSharings(1, -1)
+	if err != nil {
+		return res, err
+	}
+	for _, old := range oldSharings {
+		sharing, ok := sharings[old.ID]
+		if !ok {
+			if prune {
+				if err := DeleteSharing(old.ID); err != nil {
+					return res, err
+				}
+				res.SharesDeleted++
+			}
+			continue
+		}
+		// the access count is the state of the share rather than its config
+		sharing.Accessed = old.Accessed
+		if err := UpdateSharing(sharing); err != nil {
+			return res, err
+		}
+		delete(sharings, old.ID)
+		res.SharesUpdated++
+	}
+	for _, sharing := range sharings {
+		if _, err := CreateSharing(sharing); err != nil {
+			return res, err
+		}
+		res.SharesCreated++
+	}
+	return res, nil
+}
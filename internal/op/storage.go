@@ -2,6 +2,7 @@ package op
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -10,10 +11,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
 	"github.com/OpenListTeam/OpenList/v4/pkg/generic_sync"
 	"github.com/OpenListTeam/OpenList/v4/pkg/singleflight"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
@@ -49,6 +52,9 @@ func CreateStorage(ctx context.Context, storage model.Storage) (uint, error) {
 	storage.Modified = time.Now()
 	storage.MountPath = utils.FixAndCleanPath(storage.MountPath)
 	var err error
+	if err = checkStorageGroup(storage); err != nil {
+		return 0, err
+	}
 	// check driver first
 	driverName := storage.Driver
 	driverNew, err := GetDriver(driverName)
@@ -61,6 +67,7 @@ func CreateStorage(ctx context.Context, storage model.Storage) (uint, error) {
 	if err != nil {
 		return storage.ID, errors.WithMessage(err, "failed create storage in database")
 	}
+	defer publishStorage(storage.ID)
 	// already has an id
 	err = initStorage(ctx, storage, storageDriver)
 	go callStorageHooks("add", storageDriver)
@@ -95,6 +102,31 @@ func getCurrentGoroutineStack() string {
 }
 
 // initStorage initialize the driver and store to storagesMap
+// loadNetworkOptions keeps the network options in the addition of the storage, checking that a transport can be made with them
+func loadNetworkOptions(storage *model.Storage, addition string) error {
+	var opts model.NetworkOptions
+	if err := utils.Json.UnmarshalFromString(addition, &opts); err != nil {
+		return errors.Wrap(err, "invalid network options")
+	}
+	if opts != (model.NetworkOptions{}) {
+		if _, err := net.StorageTransport(opts); err != nil {
+			return err
+		}
+	}
+	storage.SetNetworkOptions(opts)
+	return nil
+}
+
+// withNetworkOptions makes the requests of the driver for the ctx use the network options of its storage,
+// see net.DriverTransport
+func withNetworkOptions(ctx context.Context, storage driver.Driver) context.Context {
+	opts := storage.GetStorage().GetNetworkOptions()
+	if opts == nil || ctx.Value(conf.NetworkOptionsKey) == opts {
+		return ctx
+	}
+	return context.WithValue(ctx, conf.NetworkOptionsKey, opts)
+}
+
 func initStorage(ctx context.Context, storage model.Storage, storageDriver driver.Driver) (err error) {
 	storageDriver.SetStorage(storage)
 	driverStorage := storageDriver.GetStorage()
@@ -107,8 +139,18 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 			storagesMap.Store(driverStorage.MountPath, storageDriver)
 		}
 	}()
+	err = driverStorage.LoadProxyRules()
 	// Unmarshal Addition
-	err = utils.Json.UnmarshalFromString(driverStorage.Addition, storageDriver.GetAddition())
+	var addition string
+	if err == nil {
+		addition, err = resolveSecretRefs(driverStorage.ID, driverStorage.Addition)
+	}
+	if err == nil {
+		err = utils.Json.UnmarshalFromString(addition, storageDriver.GetAddition())
+	}
+	if err == nil {
+		err = loadNetworkOptions(driverStorage, addition)
+	}
 	if err == nil {
 		if ref, ok := storageDriver.(driver.Reference); ok {
 			if strings.HasPrefix(driverStorage.Remark, "ref:/") {
@@ -133,7 +175,7 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 		}
 	}
 	if err == nil {
-		err = storageDriver.Init(ctx)
+		err = storageDriver.Init(withNetworkOptions(ctx, storageDriver))
 	}
 	storagesMap.Store(driverStorage.MountPath, storageDriver)
 	if err != nil {
@@ -176,19 +218,40 @@ func EnableStorage(ctx context.Context, id uint) error {
 	if !storage.Disabled {
 		return errors.Errorf("this storage have enabled")
 	}
+	if storage.DeleteAt != nil {
+		return errors.Errorf("this storage is deleted, restore it first")
+	}
 	storage.Disabled = false
 	err = db.UpdateStorage(storage)
 	if err != nil {
 		return errors.WithMessage(err, "failed update storage in db")
 	}
+	defer publishStorage(storage.ID)
 	err = LoadStorage(ctx, *storage)
 	if err != nil {
 		return errors.WithMessage(err, "failed load storage")
 	}
+	// the tasks paused by disabling the storage go on
+	resumeStorage(storage.MountPath)
 	return nil
 }
 
-func DisableStorage(ctx context.Context, id uint) error {
+// checkStorageTasks refuses to reinitialize the storage while tasks are using it,
+// unless the tasks should be paused by conf.PauseTasksKey in ctx. It reports whether the storage is paused
+func checkStorageTasks(ctx context.Context, mountPath string) (bool, error) {
+	names := GetStorageTasks(mountPath)
+	if len(names) == 0 {
+		return false, nil
+	}
+	if ctx.Value(conf.PauseTasksKey) == nil {
+		return false, errors.WithMessagef(errs.StorageInUse, "%d tasks: %s", len(names), strings.Join(names, "; "))
+	}
+	pauseStorage(mountPath)
+	return true, nil
+}
+
+// DisableStorage drops the storage, the paused tasks using it wait until it's enabled again
+func DisableStorage(ctx context.Context, id uint) (err error) {
 	storage, err := db.GetStorageById(id)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
@@ -196,6 +259,15 @@ func DisableStorage(ctx context.Context, id uint) error {
 	if storage.Disabled {
 		return errors.Errorf("this storage have disabled")
 	}
+	paused, err := checkStorageTasks(ctx, storage.MountPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if paused && err != nil {
+			resumeStorage(storage.MountPath)
+		}
+	}()
 	storageDriver, err := GetStorageByMountPath(storage.MountPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage driver")
@@ -211,11 +283,75 @@ func DisableStorage(ctx context.Context, id uint) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed update storage in db")
 	}
+	publishStorage(storage.ID)
 	storagesMap.Delete(storage.MountPath)
 	go callStorageHooks("del", storageDriver)
 	return nil
 }
 
+// checkStorageGroup makes sure the storage of a group is mounted under the folder of the group,
+// so that each group is a top-level folder which can be used as the base path of its users
+func checkStorageGroup(storage model.Storage) error {
+	if storage.Group == "" {
+		return nil
+	}
+	if strings.ContainsAny(storage.Group, "/\\") || storage.Group == "." || storage.Group == ".." {
+		return errors.Errorf("invalid storage group: %s", storage.Group)
+	}
+	groupPath := "/" + storage.Group
+	if storage.MountPath == groupPath || !utils.IsSubPath(groupPath, storage.MountPath) {
+		return errors.Errorf("the storage of group %s must be mounted under %s", storage.Group, groupPath)
+	}
+	return nil
+}
+
+// GetStorageGroupOfPath returns the storage group whose folder the path is in, empty if it's not in any
+func GetStorageGroupOfPath(path string) string {
+	for _, storage := range storagesMap.Values() {
+		group := storage.GetStorage().Group
+		if group != "" && utils.IsSubPath("/"+group, path) {
+			return group
+		}
+	}
+	return ""
+}
+
+// EnableStorageGroup enables all the disabled storages of a group
+func EnableStorageGroup(ctx context.Context, group string) error {
+	storages, err := db.GetStoragesByGroup(group)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storages")
+	}
+	var failed []error
+	for _, storage := range storages {
+		if !storage.Disabled || storage.DeleteAt != nil {
+			continue
+		}
+		if err = EnableStorage(ctx, storage.ID); err != nil {
+			failed = append(failed, errors.WithMessagef(err, "failed enable [%s]", storage.MountPath))
+		}
+	}
+	return stderrors.Join(failed...)
+}
+
+// DisableStorageGroup disables all the enabled storages of a group
+func DisableStorageGroup(ctx context.Context, group string) error {
+	storages, err := db.GetStoragesByGroup(group)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storages")
+	}
+	var failed []error
+	for _, storage := range storages {
+		if storage.Disabled {
+			continue
+		}
+		if err = DisableStorage(ctx, storage.ID); err != nil {
+			failed = append(failed, errors.WithMessagef(err, "failed disable [%s]", storage.MountPath))
+		}
+	}
+	return stderrors.Join(failed...)
+}
+
 // UpdateStorage update storage
 // get old storage first
 // drop the storage then reinitialize
@@ -229,10 +365,32 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	}
 	storage.Modified = time.Now()
 	storage.MountPath = utils.FixAndCleanPath(storage.MountPath)
+	// a deleted storage stays disabled until it's restored
+	storage.DeleteAt = oldStorage.DeleteAt
+	if storage.DeleteAt != nil {
+		storage.Disabled = true
+	}
+	if err = checkStorageGroup(storage); err != nil {
+		return err
+	}
+	if !oldStorage.Disabled && !storage.Disabled {
+		paused, err := checkStorageTasks(ctx, oldStorage.MountPath)
+		if err != nil {
+			return err
+		}
+		if paused {
+			// the paused tasks go on once the storage is reinitialized
+			defer resumeStorage(oldStorage.MountPath)
+			if oldStorage.MountPath != utils.FixAndCleanPath(storage.MountPath) {
+				return errors.WithMessage(errs.StorageInUse, "the mount path can't be changed")
+			}
+		}
+	}
 	err = db.UpdateStorage(&storage)
 	if err != nil {
 		return errors.WithMessage(err, "failed update storage in database")
 	}
+	defer publishStorage(storage.ID)
 	if storage.Disabled {
 		return nil
 	}
@@ -282,9 +440,70 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 	if err := db.DeleteStorageById(id); err != nil {
 		return errors.WithMessage(err, "failed delete storage in database")
 	}
+	publishStorage(id)
+	storageSecretRefs.Delete(id)
+	// the tasks waiting for the storage fail instead
+	resumeStorage(storage.MountPath)
 	return dropErr
 }
 
+// DeleteStorageLater disables the storage and keeps its configuration until the grace period ends,
+// it can be restored before then
+func DeleteStorageLater(ctx context.Context, id uint, grace time.Duration) error {
+	storage, err := db.GetStorageById(id)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if storage.DeleteAt != nil {
+		return errors.Errorf("this storage have deleted")
+	}
+	if !storage.Disabled {
+		if err = DisableStorage(ctx, id); err != nil {
+			return err
+		}
+		storage.Disabled = true
+		storage.SetStatus(DISABLED)
+	}
+	deleteAt := time.Now().Add(grace)
+	storage.DeleteAt = &deleteAt
+	if err = db.UpdateStorage(storage); err != nil {
+		return errors.WithMessage(err, "failed update storage in db")
+	}
+	return nil
+}
+
+// RestoreStorage cancels the deletion of the storage, it's left disabled
+func RestoreStorage(ctx context.Context, id uint) error {
+	storage, err := db.GetStorageById(id)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if storage.DeleteAt == nil {
+		return errors.Errorf("this storage is not deleted")
+	}
+	storage.DeleteAt = nil
+	if err = db.UpdateStorage(storage); err != nil {
+		return errors.WithMessage(err, "failed update storage in db")
+	}
+	return nil
+}
+
+// PurgeDeletedStorages drops the deleted storages whose grace period has ended
+func PurgeDeletedStorages(ctx context.Context) {
+	storages, err := db.GetStoragesDeletedBefore(time.Now())
+	if err != nil {
+		log.Errorf("failed get deleted storages: %+v", err)
+		return
+	}
+	for _, storage := range storages {
+		if err := DeleteStorageById(ctx, storage.ID); err != nil {
+			log.Errorf("failed purge deleted storage [%s]: %+v", storage.MountPath, err)
+			continue
+		}
+		log.Infof("purged deleted storage [%s]", storage.MountPath)
+	}
+}
+
 // MustSaveDriverStorage call from specific driver
 func MustSaveDriverStorage(driver driver.Driver) {
 	err := saveDriverStorage(driver)
@@ -300,6 +519,9 @@ func saveDriverStorage(driver driver.Driver) error {
 	if err != nil {
 		return errors.Wrap(err, "error while marshal addition")
 	}
+	if str, err = restoreSecretRefs(storage.ID, str); err != nil {
+		return errors.Wrap(err, "error while restore secret references")
+	}
 	storage.Addition = str
 	err = db.UpdateStorage(storage)
 	if err != nil {
@@ -466,6 +688,7 @@ func GetBalancedStorage(path string) driver.Driver {
 var detailsG singleflight.Group[*model.StorageDetails]
 
 func GetStorageDetails(ctx context.Context, storage driver.Driver, refresh ...bool) (*model.StorageDetails, error) {
+	ctx = withNetworkOptions(ctx, storage)
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
 	}
@@ -0,0 +1,227 @@
+package op
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the additions may reference the external secrets instead of holding them:
+//
+//	env:NAME             the environment variable NAME, which must start with OPENLIST_SECRET_
+//	file:/path/to/file   the content of the file in the secrets_dir of the config, without the surrounding spaces
+//	vault:kv/path#field  the field of the secret at path of the kv v2 engine mounted at kv,
+//	                     fetched from VAULT_ADDR with VAULT_TOKEN, the field defaults to "value"
+//
+// the references are resolved when the storage is initialized and written back in place of
+// the resolved values when the storage is saved, so that the secrets never reach the database
+var envNameReg = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretEnvPrefix keeps the other environment variables of the server, e.g. its jwt secret, from the storages
+const secretEnvPrefix = "OPENLIST_SECRET_"
+
+var vaultClient = &http.Client{Timeout: 10 * time.Second}
+
+type secretRef struct {
+	ref   string
+	value string
+}
+
+// storageSecretRefs are the resolved references of the additions, by storage id and field name
+var storageSecretRefs sync.Map
+
+func isSecretRef(s string) bool {
+	return strings.HasPrefix(s, "env:") || strings.HasPrefix(s, "file:") || strings.HasPrefix(s, "vault:")
+}
+
+// HasSecretRefs reports whether any field of the addition references a secret
+func HasSecretRefs(addition string) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(addition), &fields); err != nil {
+		return false
+	}
+	for _, raw := range fields {
+		if s, ok := stringField(raw); ok && isSecretRef(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveSecretRef(ref string) (string, error) {
+	kind, target, _ := strings.Cut(ref, ":")
+	switch kind {
+	case "env":
+		if !envNameReg.MatchString(target) {
+			return "", errors.Errorf("invalid environment variable name: %s", target)
+		}
+		if !strings.HasPrefix(target, secretEnvPrefix) {
+			return "", errors.Errorf("environment variable %s doesn't start with %s", target, secretEnvPrefix)
+		}
+		value, ok := os.LookupEnv(target)
+		if !ok {
+			return "", errors.Errorf("environment variable %s is not set", target)
+		}
+		return value, nil
+	case "file":
+		path, err := secretFilePath(target)
+		if err != nil {
+			return "", err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed read secret file")
+		}
+		return strings.TrimSpace(string(b)), nil
+	case "vault":
+		return resolveVaultSecret(target)
+	}
+	return "", errors.Errorf("unknown secret reference: %s", ref)
+}
+
+// secretFilePath returns the path of the secret file, which must be in the secrets dir,
+// the relative paths are relative to the secrets dir
+func secretFilePath(target string) (string, error) {
+	dir := conf.Conf.SecretsDir
+	if dir == "" {
+		return "", errors.New("secrets_dir is not configured for file secrets")
+	}
+	path := target
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+	// the links are followed so that they don't lead out of the dir
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		path = p
+	}
+	if d, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = d
+	}
+	if !utils.IsSubPath(filepath.ToSlash(filepath.Clean(dir)), filepath.ToSlash(path)) {
+		return "", errors.Errorf("secret file %s is not in the secrets dir", target)
+	}
+	return path, nil
+}
+
+func resolveVaultSecret(target string) (string, error) {
+	addr, token := strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN are required for vault secrets")
+	}
+	path, field, ok := strings.Cut(target, "#")
+	if !ok {
+		field = "value"
+	}
+	mount, secretPath, ok := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok || secretPath == "" {
+		return "", errors.Errorf("invalid vault secret path: %s", path)
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/%s/data/%s", addr, mount, secretPath), nil)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	res, err := vaultClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed request vault")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed read vault secret %s: %s", path, res.Status)
+	}
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err = utils.Json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", errors.WithStack(err)
+	}
+	value, ok := resp.Data.Data[field].(string)
+	if !ok {
+		return "", errors.Errorf("field %s not found in vault secret %s", field, path)
+	}
+	return value, nil
+}
+
+// stringField returns the value of the field if it's a string
+func stringField(raw json.RawMessage) (string, bool) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+func setStringField(fields map[string]json.RawMessage, name, value string) {
+	raw, _ := json.Marshal(value)
+	fields[name] = raw
+}
+
+// resolveSecretRefs returns the addition with the secret references replaced by their values
+func resolveSecretRefs(storageId uint, addition string) (string, error) {
+	storageSecretRefs.Delete(storageId)
+	// the fields are kept raw so that the other values are written back untouched
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(addition), &fields); err != nil {
+		// leave the invalid addition to the unmarshalling of the driver
+		return addition, nil
+	}
+	refs := make(map[string]secretRef)
+	for name, raw := range fields {
+		s, ok := stringField(raw)
+		if !ok || !isSecretRef(s) {
+			continue
+		}
+		value, err := resolveSecretRef(s)
+		if err != nil {
+			return "", errors.WithMessagef(err, "failed resolve the secret of %s", name)
+		}
+		setStringField(fields, name, value)
+		refs[name] = secretRef{ref: s, value: value}
+	}
+	if len(refs) == 0 {
+		return addition, nil
+	}
+	storageSecretRefs.Store(storageId, refs)
+	b, err := json.Marshal(fields)
+	return string(b), errors.WithStack(err)
+}
+
+// restoreSecretRefs puts the secret references back in place of their values in the addition,
+// the values changed by the driver, e.g. the refreshed tokens, are kept as they can't be written back
+func restoreSecretRefs(storageId uint, addition string) (string, error) {
+	v, ok := storageSecretRefs.Load(storageId)
+	if !ok {
+		return addition, nil
+	}
+	refs := v.(map[string]secretRef)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(addition), &fields); err != nil {
+		return "", errors.WithStack(err)
+	}
+	kept := make(map[string]secretRef, len(refs))
+	for name, ref := range refs {
+		if value, _ := stringField(fields[name]); value != ref.value {
+			log.Warnf("the secret of %s referenced by %s has been changed by the driver, saving it in the database", name, ref.ref)
+			continue
+		}
+		setStringField(fields, name, ref.ref)
+		kept[name] = ref
+	}
+	storageSecretRefs.Store(storageId, kept)
+	b, err := json.Marshal(fields)
+	return string(b), errors.WithStack(err)
+}
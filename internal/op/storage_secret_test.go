@@ -0,0 +1,66 @@
+package op
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+)
+
+func TestSecretRefs(t *testing.T) {
+	t.Setenv("OPENLIST_SECRET_TEST", "s3cret")
+	addition := `{"root_folder_id":1234567890123456789,"password":"env:OPENLIST_SECRET_TEST","username":"env"}`
+	resolved, err := resolveSecretRefs(1, addition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"password":"s3cret","root_folder_id":1234567890123456789,"username":"env"}`
+	if resolved != want {
+		t.Errorf("resolveSecretRefs() = %s, want %s", resolved, want)
+	}
+	restored, err := restoreSecretRefs(1, resolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"password":"env:OPENLIST_SECRET_TEST","root_folder_id":1234567890123456789,"username":"env"}`
+	if restored != want {
+		t.Errorf("restoreSecretRefs() = %s, want %s", restored, want)
+	}
+	if _, err = resolveSecretRefs(2, `{"password":"env:OPENLIST_SECRET_MISSING"}`); err == nil {
+		t.Error("resolveSecretRefs() of a missing variable should fail")
+	}
+}
+
+func TestSecretRefScope(t *testing.T) {
+	dataDir, oldConf := t.TempDir(), conf.Conf
+	conf.Conf = conf.DefaultConfig(dataDir)
+	t.Cleanup(func() { conf.Conf = oldConf })
+	if err := os.MkdirAll(conf.Conf.SecretsDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(conf.Conf.SecretsDir, "token"), []byte(" s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "config.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OPENLIST_JWT_SECRET", "jwt")
+	tests := []struct {
+		ref  string
+		want string
+		ok   bool
+	}{
+		{"file:token", "s3cret", true},
+		{"file:" + filepath.Join(conf.Conf.SecretsDir, "token"), "s3cret", true},
+		{"file:../config.json", "", false},
+		{"file:" + filepath.Join(dataDir, "config.json"), "", false},
+		{"env:OPENLIST_JWT_SECRET", "", false},
+	}
+	for _, tt := range tests {
+		got, err := resolveSecretRef(tt.ref)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("resolveSecretRef(%q) = %q, %v", tt.ref, got, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package op
+
+import (
+	"context"
+	"sync"
+)
+
+// StorageTasksFunc returns the names of the unfinished tasks reading or writing the storage mounted at mountPath
+type StorageTasksFunc func(mountPath string) []string
+
+var storageTasksFuncs = make([]StorageTasksFunc, 0)
+
+func RegisterStorageTasksFunc(f StorageTasksFunc) {
+	storageTasksFuncs = append(storageTasksFuncs, f)
+}
+
+// GetStorageTasks returns the names of the unfinished tasks using the storage
+func GetStorageTasks(mountPath string) []string {
+	var names []string
+	for _, f := range storageTasksFuncs {
+		names = append(names, f(mountPath)...)
+	}
+	return names
+}
+
+var (
+	storagePausesMu sync.Mutex
+	// storagePauses is closed when the paused storage is available again
+	storagePauses = make(map[string]chan struct{})
+)
+
+// pauseStorage makes the tasks wait in WaitStorage until the storage is resumed
+func pauseStorage(mountPath string) {
+	storagePausesMu.Lock()
+	defer storagePausesMu.Unlock()
+	if _, ok := storagePauses[mountPath]; !ok {
+		storagePauses[mountPath] = make(chan struct{})
+	}
+}
+
+func resumeStorage(mountPath string) {
+	storagePausesMu.Lock()
+	defer storagePausesMu.Unlock()
+	if ch, ok := storagePauses[mountPath]; ok {
+		close(ch)
+		delete(storagePauses, mountPath)
+	}
+}
+
+// StoragePaused returns the channel closed when the storage is resumed, nil if the storage is not paused
+func StoragePaused(mountPath string) <-chan struct{} {
+	storagePausesMu.Lock()
+	defer storagePausesMu.Unlock()
+	if ch, ok := storagePauses[mountPath]; ok {
+		return ch
+	}
+	return nil
+}
+
+// WaitStorage blocks while the storage is paused, it reports whether it has waited,
+// in which case the driver of the storage may have been reloaded
+func WaitStorage(ctx context.Context, mountPath string) (bool, error) {
+	ch := StoragePaused(mountPath)
+	if ch == nil {
+		return false, nil
+	}
+	select {
+	case <-ch:
+		return true, nil
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
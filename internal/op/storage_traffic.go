@@ -0,0 +1,122 @@
+package op
+
+import (
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+type storageTrafficKey struct {
+	day     string
+	storage string
+}
+
+// the traffic is counted in memory and flushed to the database with the download stats
+var (
+	storageTrafficMu sync.Mutex
+	storageTraffic   = make(map[storageTrafficKey]*model.StorageTraffic)
+	// serializes the read-modify-write of the rollups
+	storageTrafficFlushMu sync.Mutex
+
+	storageDownloadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openlist_storage_download_bytes_total",
+		Help: "Bytes read from the storage by the downloads, the redirected ones counted by their size",
+	}, []string{"storage"})
+	storageUploadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openlist_storage_upload_bytes_total",
+		Help: "Bytes written to the storage",
+	}, []string{"storage"})
+)
+
+// StorageTrafficCollectors are the prometheus metrics of the traffic of the storages
+func StorageTrafficCollectors() []prometheus.Collector {
+	return []prometheus.Collector{storageDownloadBytes, storageUploadBytes}
+}
+
+// RecordStorageTraffic counts the bytes read from and written to the storage mounted at mountPath
+func RecordStorageTraffic(mountPath string, download, upload int64) {
+	if download <= 0 && upload <= 0 {
+		return
+	}
+	if download > 0 {
+		storageDownloadBytes.WithLabelValues(mountPath).Add(float64(download))
+	}
+	if upload > 0 {
+		storageUploadBytes.WithLabelValues(mountPath).Add(float64(upload))
+	}
+	key := storageTrafficKey{day: time.Now().Format(time.DateOnly), storage: mountPath}
+	storageTrafficMu.Lock()
+	defer storageTrafficMu.Unlock()
+	t, ok := storageTraffic[key]
+	if !ok {
+		t = &model.StorageTraffic{Day: key.day, Storage: mountPath}
+		storageTraffic[key] = t
+	}
+	t.Download += max(download, 0)
+	t.Upload += max(upload, 0)
+}
+
+func FlushStorageTraffic() {
+	storageTrafficFlushMu.Lock()
+	defer storageTrafficFlushMu.Unlock()
+	storageTrafficMu.Lock()
+	traffic := storageTraffic
+	storageTraffic = make(map[storageTrafficKey]*model.StorageTraffic)
+	storageTrafficMu.Unlock()
+	for _, t := range traffic {
+		if err := db.AddStorageTraffic(t); err != nil {
+			log.Errorf("failed save traffic of storage %s: %+v", t.Storage, err)
+		}
+	}
+}
+
+// bytesPerGB is the unit of the egress costs
+const bytesPerGB = 1 << 30
+
+// EgressCost estimates the cost of reading size bytes from the storage mounted at mountPath
+func EgressCost(mountPath string, size int64) float64 {
+	storage, err := GetStorageByMountPath(mountPath)
+	if err != nil {
+		return 0
+	}
+	return storage.GetStorage().EgressCost * float64(size) / bytesPerGB
+}
+
+func GetStorageTraffic(req model.StorageTrafficReq) ([]model.StorageTrafficItem, error) {
+	FlushStorageTraffic()
+	items, err := db.GetStorageTraffic(req.From, req.To, req.GroupBy, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if req.GroupBy == "storage" {
+		for i := range items {
+			items[i].Cost = EgressCost(items[i].Key, items[i].Download)
+		}
+		return items, nil
+	}
+	// the costs of a day depend on the storages read that day
+	traffic, err := db.ListStorageTraffic(req.From, req.To, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	costs := make(map[string]float64)
+	for _, t := range traffic {
+		costs[t.Day] += EgressCost(t.Storage, t.Download)
+	}
+	for i := range items {
+		items[i].Cost = costs[items[i].Key]
+	}
+	return items, nil
+}
+
+// CleanStorageTraffic removes the rollups older than keepDays days
+func CleanStorageTraffic(keepDays int) error {
+	if keepDays <= 0 {
+		return nil
+	}
+	return db.DeleteStorageTrafficBefore(time.Now().AddDate(0, 0, -keepDays).Format(time.DateOnly))
+}
@@ -0,0 +1,227 @@
+package op
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// tagHash returns the hash identifying the file of the tags, empty if the object has none
+func tagHash(obj model.Obj) string {
+	if obj.IsDir() {
+		return ""
+	}
+	h := obj.GetHash().String()
+	if h == "{}" || h == "null" {
+		return ""
+	}
+	return h
+}
+
+func ValidateTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("tag name is required")
+	}
+	if strings.Contains(name, "/") {
+		return "", errors.New("tag name can't contain /")
+	}
+	if len(name) > 64 {
+		return "", errors.New("tag name is too long")
+	}
+	return name, nil
+}
+
+// GetTagCounts counts the tags on parent and the objects under it, only the paths passing visible are counted
+func GetTagCounts(parent string, visible func(path string) bool) ([]model.TagCount, error) {
+	tags, err := db.GetTagsUnderPath(utils.FixAndCleanPath(parent))
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64)
+	for _, t := range tags {
+		if visible(t.Path) {
+			counts[t.Name]++
+		}
+	}
+	res := make([]model.TagCount, 0, len(counts))
+	for name, count := range counts {
+		res = append(res, model.TagCount{Name: name, Count: count})
+	}
+	slices.SortFunc(res, func(a, b model.TagCount) int { return strings.Compare(a.Name, b.Name) })
+	return res, nil
+}
+
+// AddTag tags obj at the full path
+func AddTag(name, path string, obj model.Obj, creator uint) error {
+	name, err := ValidateTagName(name)
+	if err != nil {
+		return err
+	}
+	path = utils.FixAndCleanPath(path)
+	if tags, err := db.GetTagsByPaths([]string{path}); err == nil {
+		for _, t := range tags {
+			if t.Name == name {
+				return errors.New("the path is already tagged")
+			}
+		}
+	}
+	return db.CreateTag(&model.Tag{
+		Name:    name,
+		Path:    path,
+		Hash:    tagHash(obj),
+		IsDir:   obj.IsDir(),
+		Creator: creator,
+		Created: time.Now(),
+	})
+}
+
+func RemoveTag(name, path string) error {
+	return db.DeleteTag(name, utils.FixAndCleanPath(path))
+}
+
+// RenameTag renames the tag, it's merged into the tag newName if exists
+func RenameTag(name, newName string) error {
+	newName, err := ValidateTagName(newName)
+	if err != nil {
+		return err
+	}
+	if name == newName {
+		return nil
+	}
+	tags, err := db.GetTagsUnder("/", []string{name, newName})
+	if err != nil {
+		return err
+	}
+	tagged := make(map[string]struct{})
+	for _, t := range tags {
+		if t.Name == newName {
+			tagged[t.Path] = struct{}{}
+		}
+	}
+	var duplicated []uint
+	for _, t := range tags {
+		if _, ok := tagged[t.Path]; ok && t.Name == name {
+			duplicated = append(duplicated, t.ID)
+		}
+	}
+	if len(duplicated) > 0 {
+		if err := db.DeleteTagsByIds(duplicated); err != nil {
+			return err
+		}
+	}
+	return db.RenameTag(name, newName)
+}
+
+func DeleteTagByName(name string) error {
+	return db.DeleteTagByName(name)
+}
+
+// GetTagNamesOfPaths returns the tag names of each full path
+func GetTagNamesOfPaths(paths []string) (map[string][]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	tags, err := db.GetTagsByPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]string)
+	for _, t := range tags {
+		res[t.Path] = append(res[t.Path], t.Name)
+	}
+	return res, nil
+}
+
+// RelinkTags moves the tags of the same file as obj, found by the hash, to the full path if their paths are gone,
+// in case the file was moved outside OpenList
+func RelinkTags(ctx context.Context, path string, obj model.Obj) error {
+	hash := tagHash(obj)
+	if hash == "" {
+		return nil
+	}
+	path = utils.FixAndCleanPath(path)
+	tags, err := db.GetTagsByHash(hash)
+	if err != nil {
+		return err
+	}
+	var moved []model.Tag
+	for _, t := range tags {
+		if t.Path == path || tagPathExists(ctx, t.Path) {
+			continue
+		}
+		t.Path = path
+		moved = append(moved, t)
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+	return db.SaveTags(moved)
+}
+
+func tagPathExists(ctx context.Context, path string) bool {
+	storage, actualPath, err := GetStorageAndActualPath(path)
+	if err != nil {
+		return false
+	}
+	_, err = Get(ctx, storage, actualPath)
+	return err == nil
+}
+
+// GetTagged returns the objects on or under the full path parent having all the tags
+func GetTagged(parent string, names []string) ([]model.Tag, error) {
+	tags, err := db.GetTagsUnder(utils.FixAndCleanPath(parent), names)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	var res []model.Tag
+	for _, t := range tags {
+		counts[t.Path]++
+		if counts[t.Path] == len(names) {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+// moveTags keeps the tags on the objects moved or renamed in OpenList, and drops the tags of the removed ones
+func moveTags(event model.FsEvent) {
+	var err error
+	switch event.Type {
+	case model.FsEventMove, model.FsEventRename:
+		// the old file is renamed to be removed after uploading to a storage not supporting overwrite
+		if strings.HasSuffix(event.DstPath, ".openlist_to_delete") {
+			return
+		}
+		var tags []model.Tag
+		tags, err = db.GetTagsUnderPath(event.Path)
+		if err != nil || len(tags) == 0 {
+			break
+		}
+		// the tags of the overwritten objects
+		if err = db.DeleteTagsUnderPath(event.DstPath); err != nil {
+			break
+		}
+		for i := range tags {
+			tags[i].Path = event.DstPath + strings.TrimPrefix(tags[i].Path, event.Path)
+		}
+		err = db.SaveTags(tags)
+	case model.FsEventDelete:
+		err = db.DeleteTagsUnderPath(event.Path)
+	}
+	if err != nil {
+		log.Errorf("failed update the tags of [%s]: %+v", event.Path, err)
+	}
+}
+
+func init() {
+	RegisterFsEventHook(moveTags)
+}
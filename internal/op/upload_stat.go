@@ -0,0 +1,85 @@
+package op
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+type uploadStatKey struct {
+	day      string
+	userId   uint
+	protocol string
+}
+
+// uploads are counted in memory and flushed to the database periodically like the downloads
+var (
+	uploadStatsMu      sync.Mutex
+	uploadStats        = make(map[uploadStatKey]*model.UploadStat)
+	uploadStatsFlushMu sync.Mutex
+)
+
+// RecordUpload counts the bytes written by the user of ctx, the writes without a user, the nested ones and
+// the ones of the server, such as the copies, are not counted
+func RecordUpload(ctx context.Context, bytes int64) {
+	user, ok := ctx.Value(conf.UserKey).(*model.User)
+	if !ok || user == nil || ctx.Value(conf.NestedPutKey) != nil || ctx.Value(conf.ServerPutKey) != nil {
+		return
+	}
+	protocol, _ := ctx.Value(conf.ProtocolKey).(string)
+	if protocol == "" {
+		protocol = model.ProtocolWeb
+	}
+	key := uploadStatKey{
+		day:      time.Now().Format(time.DateOnly),
+		userId:   user.ID,
+		protocol: protocol,
+	}
+	uploadStatsMu.Lock()
+	defer uploadStatsMu.Unlock()
+	s, ok := uploadStats[key]
+	if !ok {
+		s = &model.UploadStat{Day: key.day, UserId: key.userId, Protocol: protocol}
+		uploadStats[key] = s
+	}
+	s.Count++
+	s.Bytes += bytes
+}
+
+func FlushUploadStats() {
+	uploadStatsFlushMu.Lock()
+	defer uploadStatsFlushMu.Unlock()
+	uploadStatsMu.Lock()
+	stats := uploadStats
+	uploadStats = make(map[uploadStatKey]*model.UploadStat)
+	uploadStatsMu.Unlock()
+	for _, s := range stats {
+		if err := db.AddUploadStat(s); err != nil {
+			log.Errorf("failed save upload stat of user %d: %+v", s.UserId, err)
+		}
+	}
+}
+
+func GetUploadStats(req model.UploadStatsReq) ([]model.UploadStatsItem, error) {
+	FlushUploadStats()
+	return db.GetUploadStats(req.From, req.To, req.GroupBy, req.OrderBy, req.Limit, req.UserId)
+}
+
+// GetUserUploadHistory returns the daily uploads of the user between from and to, the latest first
+func GetUserUploadHistory(userId uint, from, to string) ([]model.UploadStatsItem, error) {
+	FlushUploadStats()
+	return db.GetUploadStats(from, to, "day", "key", 1000, userId)
+}
+
+// CleanUploadStats removes the rollups older than keepDays days
+func CleanUploadStats(keepDays int) error {
+	if keepDays <= 0 {
+		return nil
+	}
+	return db.DeleteUploadStatsBefore(time.Now().AddDate(0, 0, -keepDays).Format(time.DateOnly))
+}
@@ -1,6 +1,10 @@
 package op
 
 import (
+	"context"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -51,6 +55,9 @@ func GetUserByName(username string) (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if _user.Grants, err = db.GetActiveAccessGrants(_user.ID); err != nil {
+			return nil, err
+		}
 		Cache.SetUser(username, _user)
 		return _user, nil
 	})
@@ -67,9 +74,41 @@ func GetUsers(pageIndex, pageSize int) (users []model.User, count int64, err err
 
 func CreateUser(u *model.User) error {
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
+	if err := provisionHomeDir(u); err != nil {
+		return err
+	}
 	return db.CreateUser(u)
 }
 
+// provisionHomeDir creates the home folder of a new general user from the home_dir_template setting,
+// e.g. /homes/{username}, and scopes the user to it. The users with a base path other than the root
+// are kept as they are
+func provisionHomeDir(u *model.User) error {
+	if u.IsAdmin() || u.IsGuest() || u.BasePath != "/" {
+		return nil
+	}
+	item, _ := GetSettingItemByKey(conf.HomeDirTemplate)
+	if item == nil || item.Value == "" {
+		return nil
+	}
+	if u.Username == "" || strings.ContainsAny(u.Username, `/\`) || u.Username == "." || u.Username == ".." {
+		return errors.Errorf("invalid username for the home folder: %s", u.Username)
+	}
+	home := utils.FixAndCleanPath(strings.ReplaceAll(item.Value, "{username}", u.Username))
+	if home == "/" {
+		return nil
+	}
+	storage, actualPath, err := GetStorageAndActualPath(home)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get the storage of the home folder %s", home)
+	}
+	if err = MakeDir(context.Background(), storage, actualPath); err != nil {
+		return errors.WithMessagef(err, "failed create the home folder %s", home)
+	}
+	u.BasePath = home
+	return nil
+}
+
 func DeleteUserById(id uint) error {
 	old, err := db.GetUserById(id)
 	if err != nil {
@@ -78,10 +117,25 @@ func DeleteUserById(id uint) error {
 	if old.IsAdmin() || old.IsGuest() {
 		return errs.DeleteAdminOrGuest
 	}
-	Cache.DeleteUser(old.Username)
+	invalidateUser(old.Username)
 	if err := DeleteSharingsByCreatorId(id); err != nil {
 		return errors.WithMessage(err, "failed to delete user's sharings")
 	}
+	if err := DeleteFavoritesByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's favorites")
+	}
+	if err := DeleteAccessHistoriesByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's access histories")
+	}
+	if err := DeleteClipboardByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's clipboard")
+	}
+	if err := db.DeleteAccessGrantsByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's access grants")
+	}
+	if err := db.DeleteAccessRequestsByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's access requests")
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -96,7 +150,7 @@ func UpdateUser(u *model.User) error {
 	if u.IsGuest() {
 		guestUser = nil
 	}
-	Cache.DeleteUser(old.Username)
+	invalidateUser(old.Username)
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
 	return db.UpdateUser(u)
 }
@@ -125,6 +179,6 @@ func DelUserCache(username string) error {
 	if user.IsGuest() {
 		guestUser = nil
 	}
-	Cache.DeleteUser(username)
+	invalidateUser(username)
 	return nil
 }
@@ -0,0 +1,168 @@
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/go-cache"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the headers of the client passed to the main instance and to the links
+var clientHeaders = []string{"User-Agent", "Range", "If-Range", "If-Modified-Since", "If-None-Match"}
+
+// the headers of the links passed to the client
+var linkHeaders = []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges",
+	"Content-Disposition", "ETag", "Last-Modified", "Cache-Control"}
+
+// Edge serves the down proxy urls by the fetch instructions of the main instance
+type Edge struct {
+	main   string
+	token  string
+	client *http.Client
+	// the instructions by the path, sign and ip of the downloads
+	instructions cache.ICache[*Instruction]
+}
+
+func NewEdge(main, token string) *Edge {
+	return &Edge{
+		main:  strings.TrimSuffix(main, "/"),
+		token: token,
+		// no timeout, the links are streamed as long as the clients read them
+		client:       &http.Client{},
+		instructions: cache.NewMemCache[*Instruction](),
+	}
+}
+
+type linkResp struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    *Instruction `json:"data"`
+}
+
+func (e *Edge) instruction(r *http.Request, path, sign, ip string) (*Instruction, error) {
+	key := path + "\n" + sign + "\n" + ip
+	if inst, ok := e.instructions.Get(key); ok {
+		return inst, nil
+	}
+	header := make(http.Header)
+	for _, h := range clientHeaders {
+		if v := r.Header.Get(h); v != "" {
+			header.Set(h, v)
+		}
+	}
+	body, err := json.Marshal(LinkReq{Path: path, Sign: sign, IP: ip, Header: header})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, e.main+LinkPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Authorization", e.token)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := e.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed request the main instance")
+	}
+	defer res.Body.Close()
+	var resp linkResp
+	if err = json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, errors.Wrapf(err, "invalid response of the main instance: %s", res.Status)
+	}
+	if resp.Code != 200 || resp.Data == nil {
+		return nil, &statusError{code: resp.Code, msg: resp.Message}
+	}
+	inst := resp.Data
+	if err = inst.Verify(e.token); err != nil {
+		return nil, errors.WithMessage(err, "invalid instruction of the main instance")
+	}
+	if ex := time.Until(time.Unix(inst.Expires, 0)); ex > 0 {
+		e.instructions.Set(key, inst, cache.WithEx[*Instruction](ex))
+	}
+	return inst, nil
+}
+
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+func clientIP(r *http.Request) string {
+	if ip := strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-For"), ",")[0]); ip != "" {
+		return ip
+	}
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	host, _, _ := net.SplitHostPort(r.RemoteAddr)
+	return host
+}
+
+func (e *Edge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// the health checks of the down proxy urls
+	if r.URL.Path == "/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	path, sign := r.URL.Path, r.URL.Query().Get("sign")
+	inst, err := e.instruction(r, path, sign, clientIP(r))
+	if err != nil {
+		code := http.StatusBadGateway
+		var se *statusError
+		if errors.As(err, &se) && se.code >= 400 && se.code < 500 {
+			code = se.code
+		}
+		log.Warnf("failed relay %s: %+v", path, err)
+		http.Error(w, err.Error(), code)
+		return
+	}
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, inst.URL, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	for k, v := range inst.Header {
+		req.Header[k] = v
+	}
+	for _, h := range clientHeaders {
+		if v := r.Header.Get(h); v != "" {
+			req.Header.Set(h, v)
+		}
+	}
+	res, err := e.client.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+	for _, h := range linkHeaders {
+		if v := res.Header.Get(h); v != "" {
+			w.Header().Set(h, v)
+		}
+	}
+	if w.Header().Get("Content-Disposition") == "" && res.StatusCode < 300 {
+		w.Header().Set("Content-Disposition", utils.GenerateContentDisposition(stdpath.Base(path)))
+	}
+	w.WriteHeader(res.StatusCode)
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = io.Copy(w, res.Body)
+}
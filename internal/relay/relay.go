@@ -0,0 +1,55 @@
+// Package relay is the protocol between the main instance and its edge nodes. An edge node serves
+// the down proxy urls of the storages: for each download it asks the main instance, authenticated by
+// the relay token, for a signed fetch instruction of the file and streams the file to the client,
+// so that the edge nodes never hold the credentials of the storages
+package relay
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/sign"
+)
+
+// LinkPath is the api of the main instance returning the fetch instructions
+const LinkPath = "/api/relay/link"
+
+// InstructionExpiration is the longest time an edge node may reuse a fetch instruction
+const InstructionExpiration = 5 * time.Minute
+
+// LinkReq asks for the fetch instruction of the download of a client
+type LinkReq struct {
+	// Path and Sign are the path and the sign of the down proxy url requested by the client
+	Path string `json:"path"`
+	Sign string `json:"sign"`
+	// IP and Header are the ones of the client, some links depend on them
+	IP     string      `json:"ip"`
+	Header http.Header `json:"header"`
+}
+
+// Instruction tells the edge node how to fetch a file, it's signed with the relay token
+type Instruction struct {
+	URL     string      `json:"url"`
+	Header  http.Header `json:"header,omitempty"`
+	Expires int64       `json:"expires"`
+	Sign    string      `json:"sign"`
+}
+
+func (i *Instruction) signData() string {
+	// the keys of the map are sorted by json, so the data is the same on both sides
+	header, _ := json.Marshal(i.Header)
+	return i.URL + "\n" + string(header)
+}
+
+func (i *Instruction) SignWith(token string) {
+	i.Sign = sign.NewHMACSign([]byte(token)).Sign(i.signData(), i.Expires)
+}
+
+// Verify checks the sign and the expiration of the instruction
+func (i *Instruction) Verify(token string) error {
+	if i.Expires == 0 {
+		return sign.ErrExpireMissing
+	}
+	return sign.NewHMACSign([]byte(token)).Verify(i.signData(), i.Sign)
+}
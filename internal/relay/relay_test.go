@@ -0,0 +1,65 @@
+package relay
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEdge(t *testing.T) {
+	const token = "relay-token"
+	file := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Cookie") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = io.WriteString(w, "content")
+	}))
+	defer file.Close()
+	calls := 0
+	main := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req LinkReq
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != LinkPath || r.Header.Get("Authorization") != token || req.Path != "/a/b.txt" || req.Sign != "s" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"code": 401, "message": "unauthorized"})
+			return
+		}
+		inst := &Instruction{URL: file.URL, Header: http.Header{"Cookie": {"secret"}}, Expires: time.Now().Add(time.Minute).Unix()}
+		inst.SignWith(token)
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 200, "data": inst})
+	}))
+	defer main.Close()
+	edge := httptest.NewServer(NewEdge(main.URL, token))
+	defer edge.Close()
+	for i := 0; i < 2; i++ {
+		res, err := http.Get(edge.URL + "/a/b.txt?sign=s")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if res.StatusCode != http.StatusOK || string(body) != "content" {
+			t.Fatalf("got %d %q, want 200 %q", res.StatusCode, body, "content")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("the main instance was asked %d times, the instruction should be reused", calls)
+	}
+	res, err := http.Get(edge.URL + "/a/b.txt?sign=wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %d with a wrong sign, want 401", res.StatusCode)
+	}
+	forged := &Instruction{URL: file.URL, Expires: time.Now().Add(time.Minute).Unix()}
+	forged.SignWith("other-token")
+	if forged.Verify(token) == nil {
+		t.Error("Verify() accepted an instruction signed with another token")
+	}
+}
@@ -3,8 +3,10 @@ package stream
 import (
 	"context"
 	"io"
+	"sync/atomic"
 	"time"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
 	"golang.org/x/time/rate"
@@ -34,6 +36,85 @@ var (
 	ServerUploadLimit   Limiter
 )
 
+// TaskLimiters limit the streams of a task besides the server limits, the uploads and the downloads
+// are limited apart so that a copy reading and writing the same bytes is not slowed down twice
+type TaskLimiters struct {
+	Download Limiter
+	Upload   Limiter
+}
+
+func WithTaskLimiters(ctx context.Context, l *TaskLimiters) context.Context {
+	return context.WithValue(ctx, conf.TaskLimitersKey, l)
+}
+
+// TaskUploadLimiter returns the upload limiter of the task of ctx, nil if it's not of a task
+func TaskUploadLimiter(ctx context.Context) Limiter {
+	if l, ok := ctx.Value(conf.TaskLimitersKey).(*TaskLimiters); ok {
+		return l.Upload
+	}
+	return nil
+}
+
+// TaskDownloadLimiter returns the download limiter of the task of ctx, nil if it's not of a task
+func TaskDownloadLimiter(ctx context.Context) Limiter {
+	if l, ok := ctx.Value(conf.TaskLimitersKey).(*TaskLimiters); ok {
+		return l.Download
+	}
+	return nil
+}
+
+// limitDownload limits rc by the server download limit and the download limit of the task of ctx
+func limitDownload(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	if l := TaskDownloadLimiter(ctx); l != nil {
+		rc = &RateLimitReader{Ctx: ctx, Reader: rc, Limiter: l}
+	}
+	if ServerDownloadLimit != nil {
+		rc = &RateLimitReader{Ctx: ctx, Reader: rc, Limiter: ServerDownloadLimit}
+	}
+	return rc
+}
+
+type connectionLimit struct {
+	limit rate.Limit
+	burst int
+}
+
+var connectionDownloadLimit atomic.Pointer[connectionLimit]
+
+// SetConnectionDownloadLimit sets the speed limit of each download connection, rate.Inf for unlimited
+func SetConnectionDownloadLimit(limit rate.Limit, burst int) {
+	connectionDownloadLimit.Store(&connectionLimit{limit: limit, burst: burst})
+}
+
+// newConnectionLimiter returns nil if the download connections are unlimited
+func newConnectionLimiter() Limiter {
+	l := connectionDownloadLimit.Load()
+	if l == nil || l.limit == rate.Inf {
+		return nil
+	}
+	return BlockBurstLimiter{Limiter: rate.NewLimiter(l.limit, l.burst)}
+}
+
+// BlockBurstLimiter waits for n tokens by bursts, so that n is allowed to exceed the burst
+type BlockBurstLimiter struct {
+	*rate.Limiter
+}
+
+func (l BlockBurstLimiter) WaitN(ctx context.Context, total int) error {
+	for total > 0 {
+		n := l.Burst()
+		if l.Limiter.Limit() == rate.Inf || n > total {
+			n = total
+		}
+		err := l.Limiter.WaitN(ctx, n)
+		if err != nil {
+			return err
+		}
+		total -= n
+	}
+	return nil
+}
+
 type RateLimitReader struct {
 	io.Reader
 	Limiter Limiter
@@ -132,16 +213,9 @@ func (r *RateLimitFile) Close() error {
 type RateLimitRangeReaderFunc RangeReaderFunc
 
 func (f RateLimitRangeReaderFunc) RangeRead(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
-	if ServerDownloadLimit == nil {
-		return f(ctx, httpRange)
-	}
 	rc, err := f(ctx, httpRange)
 	if err != nil {
 		return nil, err
 	}
-	return &RateLimitReader{
-		Ctx:     ctx,
-		Reader:  rc,
-		Limiter: ServerDownloadLimit,
-	}, nil
+	return limitDownload(ctx, rc), nil
 }
@@ -0,0 +1,147 @@
+package stream
+
+import (
+	"context"
+	"io"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// readAheadChunks is the number of the ranged requests a read-ahead reader keeps in flight
+const readAheadChunks = 4
+
+// ReadAheadRangeReader wraps rr so that a range is fetched by ranged requests of readAhead/4 bytes,
+// prefetching up to readAhead bytes ahead of the reader to hide the first-byte latency of the storage.
+// The prefetched chunks are taken from the memory buffer budget, the rest of a range is streamed
+// directly once the budget is used up
+func ReadAheadRangeReader(rr model.RangeReaderIF, readAhead int64) model.RangeReaderIF {
+	if readAhead <= 0 {
+		return rr
+	}
+	chunkSize := max(readAhead/readAheadChunks, 256*utils.KB)
+	return RangeReaderFunc(func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		if httpRange.Length < 0 {
+			return rr.RangeRead(ctx, httpRange)
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		r := &readAheadReader{
+			ctx:       ctx,
+			cancel:    cancel,
+			rr:        rr,
+			next:      httpRange.Start,
+			end:       httpRange.Start + httpRange.Length,
+			chunkSize: chunkSize,
+		}
+		if err := r.fill(); err != nil {
+			cancel()
+			return nil, err
+		}
+		return r, nil
+	})
+}
+
+type readAheadChunk struct {
+	size int64 // the reserved memory budget
+	buf  []byte
+	off  int
+	err  error
+	done chan struct{}
+	// rc streams the rest of the range directly when the memory budget is used up
+	rc io.ReadCloser
+}
+
+func (c *readAheadChunk) fetch(ctx context.Context, rr model.RangeReaderIF, httpRange http_range.Range) {
+	defer close(c.done)
+	rc, err := rr.RangeRead(ctx, httpRange)
+	if err != nil {
+		c.err = err
+		return
+	}
+	defer rc.Close()
+	c.buf = make([]byte, httpRange.Length)
+	n, err := io.ReadFull(rc, c.buf)
+	c.buf = c.buf[:n]
+	c.err = err
+}
+
+type readAheadReader struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	rr        model.RangeReaderIF
+	next      int64
+	end       int64
+	chunkSize int64
+	chunks    []*readAheadChunk
+}
+
+// fill queues the next chunks until readAheadChunks are queued, the range is covered or the budget is used up
+func (r *readAheadReader) fill() error {
+	for len(r.chunks) < readAheadChunks && r.next < r.end {
+		size := min(r.chunkSize, r.end-r.next)
+		if !bufferBudget.tryAcquire(size) {
+			if len(r.chunks) > 0 {
+				return nil
+			}
+			rc, err := r.rr.RangeRead(r.ctx, http_range.Range{Start: r.next, Length: r.end - r.next})
+			if err != nil {
+				return err
+			}
+			r.next = r.end
+			r.chunks = append(r.chunks, &readAheadChunk{rc: rc})
+			return nil
+		}
+		c := &readAheadChunk{size: size, done: make(chan struct{})}
+		go c.fetch(r.ctx, r.rr, http_range.Range{Start: r.next, Length: size})
+		r.next += size
+		r.chunks = append(r.chunks, c)
+	}
+	return nil
+}
+
+func (r *readAheadReader) Read(p []byte) (int, error) {
+	for len(r.chunks) > 0 {
+		c := r.chunks[0]
+		if c.rc != nil {
+			return c.rc.Read(p)
+		}
+		select {
+		case <-c.done:
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+		if c.off < len(c.buf) {
+			n := copy(p, c.buf[c.off:])
+			c.off += n
+			return n, nil
+		}
+		if c.err != nil {
+			return 0, c.err
+		}
+		bufferBudget.release(c.size)
+		r.chunks = r.chunks[1:]
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	return 0, io.EOF
+}
+
+func (r *readAheadReader) Close() error {
+	r.cancel()
+	var err error
+	for _, c := range r.chunks {
+		if c.rc != nil {
+			err = c.rc.Close()
+			continue
+		}
+		// release the budget once the fetching stops
+		go func() {
+			<-c.done
+			bufferBudget.release(c.size)
+		}()
+	}
+	r.chunks = nil
+	return err
+}
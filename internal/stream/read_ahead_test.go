@@ -0,0 +1,60 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+func TestReadAheadRangeReader(t *testing.T) {
+	oldTotal := conf.MaxBufferTotal
+	defer func() { conf.MaxBufferTotal = oldTotal }()
+
+	data := make([]byte, 3*utils.MB+123)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	var requests atomic.Int32
+	rr := RangeReaderFunc(func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		requests.Add(1)
+		return io.NopCloser(bytes.NewReader(data[httpRange.Start : httpRange.Start+httpRange.Length])), nil
+	})
+
+	tests := []struct {
+		name     string
+		total    int
+		requests int32
+	}{
+		{name: "unlimited budget", total: 0, requests: 13},
+		{name: "budget of one chunk", total: 256 * utils.KB, requests: 13},
+		{name: "budget used up", total: 100 * utils.KB, requests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf.MaxBufferTotal = tt.total
+			requests.Store(0)
+			rng := http_range.Range{Start: 100, Length: int64(len(data)) - 100}
+			rc, err := ReadAheadRangeReader(rr, utils.MB).RangeRead(context.Background(), rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(rc)
+			_ = rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data[100:]) {
+				t.Error("the read data doesn't match the range")
+			}
+			if n := requests.Load(); n != tt.requests {
+				t.Errorf("requests = %d, want %d", n, tt.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
+	log "github.com/sirupsen/logrus"
+)
+
+// ResumableRangeReader wraps rr so that when the body of a range breaks off before its end,
+// the rest of the range is re-requested transparently, at most retries times per range
+func ResumableRangeReader(rr model.RangeReaderIF, retries int) model.RangeReaderIF {
+	if retries <= 0 {
+		return rr
+	}
+	return RangeReaderFunc(func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		rc, err := rr.RangeRead(ctx, httpRange)
+		if err != nil {
+			return nil, err
+		}
+		return ResumeReadCloser(ctx, rc, rr, httpRange, retries), nil
+	})
+}
+
+// ResumeReadCloser wraps rc, the body of httpRange, so that when it breaks off before the end of httpRange,
+// the rest is re-requested from rr, at most retries times
+func ResumeReadCloser(ctx context.Context, rc io.ReadCloser, rr model.RangeReaderIF, httpRange http_range.Range, retries int) io.ReadCloser {
+	if retries <= 0 {
+		return rc
+	}
+	return &resumeReadCloser{
+		ctx:     ctx,
+		rr:      rr,
+		rng:     httpRange,
+		rc:      rc,
+		retries: retries,
+	}
+}
+
+type resumeReadCloser struct {
+	ctx     context.Context
+	rr      model.RangeReaderIF
+	rng     http_range.Range
+	rc      io.ReadCloser
+	read    int64
+	retries int
+	pending error
+}
+
+func (r *resumeReadCloser) Read(p []byte) (int, error) {
+	for {
+		if r.pending != nil {
+			cause := r.pending
+			r.pending = nil
+			if err := r.resume(cause); err != nil {
+				return 0, err
+			}
+		}
+		n, err := r.rc.Read(p)
+		r.read += int64(n)
+		if err == nil || !r.broken(err) {
+			return n, err
+		}
+		// resume by the next read if something has been read
+		r.pending = err
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// broken reports whether err means the body ended before the range did
+func (r *resumeReadCloser) broken(err error) bool {
+	if r.ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) {
+		return r.rng.Length >= 0 && r.read < r.rng.Length
+	}
+	return true
+}
+
+func (r *resumeReadCloser) resume(cause error) error {
+	_ = r.rc.Close()
+	if r.retries <= 0 {
+		r.rc = io.NopCloser(errReader{cause})
+		return cause
+	}
+	r.retries--
+	rest := http_range.Range{Start: r.rng.Start + r.read, Length: -1}
+	if r.rng.Length >= 0 {
+		rest.Length = r.rng.Length - r.read
+	}
+	log.Warnf("body broke off after %d bytes: %v, resuming from %d", r.read, cause, rest.Start)
+	model.TaskLogf(r.ctx, "body broke off after %d bytes: %v, resuming from %d", r.read, cause, rest.Start)
+	rc, err := r.rr.RangeRead(r.ctx, rest)
+	if err != nil {
+		r.rc = io.NopCloser(errReader{err})
+		return err
+	}
+	r.rc = rc
+	return nil
+}
+
+func (r *resumeReadCloser) Close() error {
+	return r.rc.Close()
+}
+
+type errReader struct{ err error }
+
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
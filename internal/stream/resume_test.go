@@ -0,0 +1,65 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
+)
+
+// flakyReader breaks off with an error after limit bytes
+type flakyReader struct {
+	r     io.Reader
+	limit int
+}
+
+func (f *flakyReader) Read(p []byte) (int, error) {
+	if f.limit <= 0 {
+		return 0, errors.New("connection reset")
+	}
+	if len(p) > f.limit {
+		p = p[:f.limit]
+	}
+	n, err := f.r.Read(p)
+	f.limit -= n
+	return n, err
+}
+
+func TestResumableRangeReader(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	requests := 0
+	rr := RangeReaderFunc(func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		requests++
+		end := int64(len(data))
+		if httpRange.Length >= 0 {
+			end = httpRange.Start + httpRange.Length
+		}
+		return io.NopCloser(&flakyReader{r: bytes.NewReader(data[httpRange.Start:end]), limit: 300}), nil
+	})
+
+	rc, err := ResumableRangeReader(rr, 5).RangeRead(context.Background(), http_range.Range{Start: 100, Length: 800})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if !bytes.Equal(got, data[100:900]) {
+		t.Errorf("got %d bytes not matching the range", len(got))
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+
+	rc, err = ResumableRangeReader(rr, 1).RangeRead(context.Background(), http_range.Range{Start: 0, Length: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.ReadAll(rc); err == nil {
+		t.Error("expected an error when the retries are used up")
+	}
+}
@@ -132,7 +132,7 @@ func (f *FileStream) CacheFullAndWriter(up *model.UpdateProgress, writer io.Writ
 		} else if err != nil {
 			return nil, err
 		}
-		if conf.MaxBufferLimit-n > conf.MmapThreshold && conf.MmapThreshold > 0 {
+		if conf.MaxBufferLimit-n > conf.MmapThreshold && conf.MmapThreshold > 0 && f.reserveBuffer(int64(conf.MaxBufferLimit-n)) {
 			m, err := mmap.Alloc(conf.MaxBufferLimit - n)
 			if err == nil {
 				f.Add(utils.CloseFunc(func() error {
@@ -225,7 +225,7 @@ func (f *FileStream) RangeRead(httpRange http_range.Range) (io.Reader, error) {
 
 // 确保指定大小的数据被缓存
 func (f *FileStream) cache(maxCacheSize int64) (model.File, error) {
-	if maxCacheSize > int64(conf.MaxBufferLimit) {
+	if maxCacheSize > int64(conf.MaxBufferLimit) || !f.reserveBuffer(maxCacheSize-f.peekSize()) {
 		size := f.GetSize()
 		reader := f.Reader
 		if f.peekBuff != nil {
@@ -284,6 +284,60 @@ func (f *FileStream) cache(maxCacheSize int64) (model.File, error) {
 	return f.peekBuff, nil
 }
 
+func (f *FileStream) peekSize() int64 {
+	if f.peekBuff == nil {
+		return 0
+	}
+	return f.peekBuff.Size()
+}
+
+// reserveBuffer reserves size of the memory budget to cache the stream in memory,
+// the reservation is released when the stream is closed
+func (f *FileStream) reserveBuffer(size int64) bool {
+	if size <= 0 {
+		return true
+	}
+	if !bufferBudget.tryAcquire(size) {
+		return false
+	}
+	f.Add(utils.CloseFunc(func() error {
+		bufferBudget.release(size)
+		return nil
+	}))
+	return true
+}
+
+// memoryBudget limits the total size of the streams cached in memory,
+// the streams are cached into temp files once it's used up
+type memoryBudget struct {
+	mu   sync.Mutex
+	used int64
+}
+
+func (b *memoryBudget) tryAcquire(size int64) bool {
+	if conf.MaxBufferTotal <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used+size > int64(conf.MaxBufferTotal) {
+		return false
+	}
+	b.used += size
+	return true
+}
+
+func (b *memoryBudget) release(size int64) {
+	if conf.MaxBufferTotal <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used = max(b.used-size, 0)
+}
+
+var bufferBudget memoryBudget
+
 var _ model.FileStreamer = (*SeekableStream)(nil)
 var _ model.FileStreamer = (*FileStream)(nil)
 
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"testing"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
@@ -107,3 +109,46 @@ func TestFileStream_With_PreHash(t *testing.T) {
 		t.Errorf("fullHash and fileFullHash should match: fullHash=%s fileFullHash=%s", fullHash, fileFullHash)
 	}
 }
+
+func TestFileStream_BufferBudget(t *testing.T) {
+	oldConf, oldTotal := conf.Conf, conf.MaxBufferTotal
+	defer func() { conf.Conf, conf.MaxBufferTotal = oldConf, oldTotal }()
+	conf.Conf = &conf.Config{TempDir: t.TempDir()}
+	conf.MaxBufferTotal = 16
+
+	buf := []byte("github.com/OpenListTeam/OpenList")
+	newStream := func() *FileStream {
+		return &FileStream{
+			Obj: &model.Object{
+				Size: int64(len(buf)),
+			},
+			Reader: io.NopCloser(bytes.NewReader(buf)),
+		}
+	}
+	f1 := newStream()
+	if _, err := f1.RangeRead(http_range.Range{Start: 0, Length: 12}); err != nil {
+		t.Fatalf("FileStream.RangeRead() error = %v", err)
+	}
+	if _, ok := f1.Reader.(*os.File); ok {
+		t.Error("cached into temp file within the budget")
+	}
+
+	f2 := newStream()
+	defer f2.Close()
+	if _, err := f2.RangeRead(http_range.Range{Start: 0, Length: 12}); err != nil {
+		t.Fatalf("FileStream.RangeRead() error = %v", err)
+	}
+	if _, ok := f2.Reader.(*os.File); !ok {
+		t.Error("cached in memory beyond the budget")
+	}
+
+	_ = f1.Close()
+	f3 := newStream()
+	defer f3.Close()
+	if _, err := f3.RangeRead(http_range.Range{Start: 0, Length: 12}); err != nil {
+		t.Fatalf("FileStream.RangeRead() error = %v", err)
+	}
+	if _, ok := f3.Reader.(*os.File); ok {
+		t.Error("budget not released on close")
+	}
+}
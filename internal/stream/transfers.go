@@ -0,0 +1,158 @@
+package stream
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	TransferDownload = "download"
+	TransferUpload   = "upload"
+)
+
+// TransferInfo is the snapshot of an in-flight download or upload
+type TransferInfo struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Path    string    `json:"path"`
+	User    string    `json:"user"`
+	IP      string    `json:"ip"`
+	Started time.Time `json:"started"`
+	Bytes   int64     `json:"bytes"` // the bytes sent to or received from the client
+	Speed   int64     `json:"speed"` // bytes per second of the last second
+}
+
+// Transfer is an in-flight download or upload
+type Transfer struct {
+	info   TransferInfo
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	bytes       int64
+	windowStart time.Time
+	windowBytes int64
+	speed       int64
+}
+
+var (
+	transfers  sync.Map // id -> *Transfer
+	transferID atomic.Uint64
+)
+
+// TrackTransfer registers a transfer of typ, it can be killed by cancelling the returned context,
+// Done must be called once the transfer ends
+func TrackTransfer(ctx context.Context, typ, path, user, ip string) (context.Context, *Transfer) {
+	ctx, cancel := context.WithCancel(ctx)
+	now := time.Now()
+	t := &Transfer{
+		info: TransferInfo{
+			ID:      strconv.FormatUint(transferID.Add(1), 10),
+			Type:    typ,
+			Path:    path,
+			User:    user,
+			IP:      ip,
+			Started: now,
+		},
+		cancel:      cancel,
+		windowStart: now,
+	}
+	transfers.Store(t.info.ID, t)
+	return ctx, t
+}
+
+// Writer wraps w to count the bytes sent and to apply the connection download speed limit
+func (t *Transfer) Writer(ctx context.Context, w io.Writer) io.Writer {
+	return &RateLimitWriter{
+		Writer:  &transferWriter{Writer: w, t: t},
+		Limiter: newConnectionLimiter(),
+		Ctx:     ctx,
+	}
+}
+
+// Reader wraps r to count the bytes received, the reading fails once the transfer is killed
+func (t *Transfer) Reader(ctx context.Context, r io.ReadCloser) io.ReadCloser {
+	return &RateLimitReader{
+		Reader: &transferReader{ReadCloser: r, t: t},
+		Ctx:    ctx,
+	}
+}
+
+func (t *Transfer) add(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bytes += int64(n)
+	t.windowBytes += int64(n)
+	if elapsed := time.Since(t.windowStart); elapsed >= time.Second {
+		t.speed = int64(float64(t.windowBytes) / elapsed.Seconds())
+		t.windowStart = time.Now()
+		t.windowBytes = 0
+	}
+}
+
+func (t *Transfer) snapshot() TransferInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	info := t.info
+	info.Bytes = t.bytes
+	info.Speed = t.speed
+	// the transfer is stalled if nothing has moved for more than a window
+	if elapsed := time.Since(t.windowStart); elapsed >= 2*time.Second {
+		info.Speed = int64(float64(t.windowBytes) / elapsed.Seconds())
+	}
+	return info
+}
+
+func (t *Transfer) Done() {
+	t.cancel()
+	transfers.Delete(t.info.ID)
+}
+
+type transferWriter struct {
+	io.Writer
+	t *Transfer
+}
+
+func (w *transferWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.t.add(n)
+	return n, err
+}
+
+type transferReader struct {
+	io.ReadCloser
+	t *Transfer
+}
+
+func (r *transferReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.t.add(n)
+	return n, err
+}
+
+// GetTransfers returns the in-flight transfers, the oldest first
+func GetTransfers() []TransferInfo {
+	res := make([]TransferInfo, 0)
+	transfers.Range(func(_, value any) bool {
+		res = append(res, value.(*Transfer).snapshot())
+		return true
+	})
+	slices.SortFunc(res, func(a, b TransferInfo) int {
+		return a.Started.Compare(b.Started)
+	})
+	return res
+}
+
+// KillTransfer aborts the transfer of id, returns false if it's not in flight
+func KillTransfer(id string) bool {
+	v, ok := transfers.Load(id)
+	if !ok {
+		return false
+	}
+	v.(*Transfer).cancel()
+	return true
+}
@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestTrackDownload(t *testing.T) {
+	SetConnectionDownloadLimit(rate.Inf, 0)
+	ctx, d := TrackTransfer(context.Background(), TransferDownload, "/d/file", "guest", "127.0.0.1")
+	var buf bytes.Buffer
+	if _, err := d.Writer(ctx, &buf).Write(make([]byte, 100)); err != nil {
+		t.Fatal(err)
+	}
+	transfers := GetTransfers()
+	if len(transfers) != 1 || transfers[0].Bytes != 100 || transfers[0].Path != "/d/file" {
+		t.Fatalf("unexpected transfers: %+v", transfers)
+	}
+	if !KillTransfer(transfers[0].ID) {
+		t.Fatal("failed kill the download")
+	}
+	if _, err := d.Writer(ctx, &buf).Write(make([]byte, 100)); err == nil {
+		t.Error("expected writing to a killed download to fail")
+	}
+	d.Done()
+	if len(GetTransfers()) != 0 {
+		t.Error("the download is still active after done")
+	}
+}
+
+func TestTrackUpload(t *testing.T) {
+	ctx, u := TrackTransfer(context.Background(), TransferUpload, "/file", "admin", "127.0.0.1")
+	defer u.Done()
+	r := u.Reader(ctx, io.NopCloser(bytes.NewReader(make([]byte, 200))))
+	if _, err := r.Read(make([]byte, 50)); err != nil {
+		t.Fatal(err)
+	}
+	transfers := GetTransfers()
+	if len(transfers) != 1 || transfers[0].Type != TransferUpload || transfers[0].Bytes != 50 {
+		t.Fatalf("unexpected transfers: %+v", transfers)
+	}
+	KillTransfer(transfers[0].ID)
+	if _, err := r.Read(make([]byte, 50)); err == nil {
+		t.Error("expected reading a killed upload to fail")
+	}
+}
+
+func TestConnectionDownloadLimit(t *testing.T) {
+	defer SetConnectionDownloadLimit(rate.Inf, 0)
+	SetConnectionDownloadLimit(100*1024, 10*1024)
+	ctx, d := TrackTransfer(context.Background(), TransferDownload, "/d/file", "", "127.0.0.1")
+	defer d.Done()
+	start := time.Now()
+	// the burst is sent at once, the rest at 100KB/s
+	if _, err := d.Writer(ctx, &bytes.Buffer{}).Write(make([]byte, 30*1024)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("written in %v, the connection limit isn't applied", elapsed)
+	}
+}
@@ -56,16 +56,9 @@ func GetRangeReaderFromLink(size int64, link *model.Link) (model.RangeReaderIF,
 			d.Concurrency = link.Concurrency
 			d.PartSize = link.PartSize
 			d.HttpClient = func(ctx context.Context, params *net.HttpRequestParams) (*http.Response, error) {
-				if ServerDownloadLimit == nil {
-					return net.DefaultHttpRequestFunc(ctx, params)
-				}
 				resp, err := net.DefaultHttpRequestFunc(ctx, params)
 				if err == nil && resp.Body != nil {
-					resp.Body = &RateLimitReader{
-						Ctx:     ctx,
-						Reader:  resp.Body,
-						Limiter: ServerDownloadLimit,
-					}
+					resp.Body = limitDownload(ctx, resp.Body)
 				}
 				return resp, err
 			}
@@ -98,13 +91,7 @@ func GetRangeReaderFromLink(size int64, link *model.Link) (model.RangeReaderIF,
 			}
 			return nil, fmt.Errorf("http request failure, err:%w", err)
 		}
-		if ServerDownloadLimit != nil {
-			response.Body = &RateLimitReader{
-				Ctx:     ctx,
-				Reader:  response.Body,
-				Limiter: ServerDownloadLimit,
-			}
-		}
+		response.Body = limitDownload(ctx, response.Body)
 		if httpRange.Start == 0 && httpRange.Length == size ||
 			response.StatusCode == http.StatusPartialContent ||
 			checkContentRange(&response.Header, httpRange.Start) {
@@ -189,7 +176,7 @@ func NewStreamSectionReader(file model.FileStreamer, maxBufferSize int, up *mode
 
 	maxBufferSize = min(maxBufferSize, int(file.GetSize()))
 	if maxBufferSize > conf.MaxBufferLimit {
-		f, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+		f, err := utils.CreateTemp("file-*", file.GetSize())
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,194 @@
+package sync_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Client calls the api of an OpenList server as a user, the paths are relative to the base path of the user
+type Client struct {
+	Server string
+	Token  string
+	// Limiter limits the bytes uploaded and downloaded, nil for no limit
+	Limiter stream.Limiter
+
+	basePath string
+}
+
+type resp[T any] struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    T      `json:"data"`
+}
+
+// Entry is an entry of the manifest of a remote directory
+type Entry struct {
+	Name     string            `json:"name"`
+	IsDir    bool              `json:"is_dir"`
+	Size     int64             `json:"size"`
+	Modified time.Time         `json:"mtime"`
+	Hashes   map[string]string `json:"hashes,omitempty"`
+	Sign     string            `json:"sign,omitempty"`
+}
+
+type manifest struct {
+	Token   string  `json:"token"`
+	Total   int     `json:"total"`
+	Content []Entry `json:"content"`
+}
+
+func call[T any](ctx context.Context, c *Client, method, api string, body any) (T, error) {
+	var r resp[T]
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return r.Data, errors.WithStack(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.Server, "/")+api, reader)
+	if err != nil {
+		return r.Data, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.Token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return r.Data, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	if err = json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return r.Data, errors.Wrapf(err, "failed decode the response of %s: %s", api, res.Status)
+	}
+	if r.Code != 200 {
+		return r.Data, errors.Errorf("%s: %d %s", api, r.Code, r.Message)
+	}
+	return r.Data, nil
+}
+
+// Login gets the token of the user by the password
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	data, err := call[struct {
+		Token string `json:"token"`
+	}](ctx, c, http.MethodPost, "/api/auth/login", map[string]string{"username": username, "password": password})
+	if err != nil {
+		return err
+	}
+	c.Token = data.Token
+	return nil
+}
+
+// Init gets the base path of the user, which the download links are under
+func (c *Client) Init(ctx context.Context) error {
+	me, err := call[struct {
+		BasePath string `json:"base_path"`
+	}](ctx, c, http.MethodGet, "/api/me", nil)
+	if err != nil {
+		return err
+	}
+	c.basePath = me.BasePath
+	return nil
+}
+
+// List returns the entries of the remote directory
+func (c *Client) List(ctx context.Context, dir string) ([]Entry, error) {
+	const perPage = 1000
+	var entries []Entry
+	for page := 1; ; page++ {
+		m, err := call[manifest](ctx, c, http.MethodPost, "/api/fs/sync/manifest", map[string]any{
+			"path":     dir,
+			"page":     page,
+			"per_page": perPage,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, m.Content...)
+		if len(m.Content) < perPage || len(entries) >= m.Total {
+			return entries, nil
+		}
+	}
+}
+
+func (c *Client) limit(ctx context.Context, r io.Reader) io.Reader {
+	if c.Limiter == nil {
+		return r
+	}
+	return &stream.RateLimitReader{Reader: r, Limiter: c.Limiter, Ctx: ctx}
+}
+
+// Upload puts the file to the remote path, overwriting the existing one
+func (c *Client) Upload(ctx context.Context, path string, body io.Reader, size int64, modified time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, strings.TrimSuffix(c.Server, "/")+"/api/fs/put", c.limit(ctx, body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Authorization", c.Token)
+	req.Header.Set("File-Path", url.PathEscape(path))
+	req.Header.Set("Last-Modified", strconv.FormatInt(modified.UnixMilli(), 10))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	var r resp[any]
+	if err = json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return errors.Wrapf(err, "failed decode the response of the upload: %s", res.Status)
+	}
+	if r.Code != 200 {
+		return errors.Errorf("failed upload: %d %s", r.Code, r.Message)
+	}
+	return nil
+}
+
+// Download writes the remote file of the entry to w
+func (c *Client) Download(ctx context.Context, path string, e *Entry, w io.Writer) error {
+	u := strings.TrimSuffix(c.Server, "/") + "/d" + utils.EncodePath(stdpath.Join(c.basePath, path), true)
+	if e.Sign != "" {
+		u += "?sign=" + url.QueryEscape(e.Sign)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("failed download: %s", res.Status)
+	}
+	n, err := io.Copy(w, c.limit(ctx, res.Body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if n != e.Size {
+		return errors.Errorf("downloaded %d bytes of %d", n, e.Size)
+	}
+	return nil
+}
+
+// Remove removes the remote file
+func (c *Client) Remove(ctx context.Context, path string) error {
+	_, err := call[any](ctx, c, http.MethodPost, "/api/fs/remove", map[string]any{
+		"dir":   stdpath.Dir(path),
+		"names": []string{stdpath.Base(path)},
+	})
+	return err
+}
@@ -0,0 +1,62 @@
+package sync_client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StateFile is kept in the root of the local directory, it's never synced
+const StateFile = ".openlist-sync.json"
+
+// FileState is a file as of the last sync, the changes made since on both sides are detected by it
+type FileState struct {
+	Size          int64     `json:"size"`
+	LocalModified time.Time `json:"local_mtime"`
+	// RemoteModified is zero after an upload until the file is listed again,
+	// as the storages may not keep the modified time of the uploads
+	RemoteModified time.Time `json:"remote_mtime"`
+}
+
+// State is the local state database of the sync of a local directory with a remote one
+type State struct {
+	Server string                `json:"server"`
+	Remote string                `json:"remote"`
+	Files  map[string]*FileState `json:"files"`
+}
+
+// LoadState reads the state of the local directory, the state of another server or remote directory is dropped
+func LoadState(local, server, remote string) (*State, error) {
+	s := &State{Server: server, Remote: remote, Files: make(map[string]*FileState)}
+	data, err := os.ReadFile(filepath.Join(local, StateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var saved State
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return nil, errors.Wrapf(err, "failed parse %s", StateFile)
+	}
+	if saved.Server == server && saved.Remote == remote && saved.Files != nil {
+		s.Files = saved.Files
+	}
+	return s, nil
+}
+
+// Save writes the state by a rename, so it's never left half written
+func (s *State) Save(local string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tmp := filepath.Join(local, StateFile+".tmp")
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp, filepath.Join(local, StateFile)))
+}
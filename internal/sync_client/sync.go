@@ -0,0 +1,408 @@
+package sync_client
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ModePush = "push" // local to remote
+	ModePull = "pull" // remote to local
+	ModeBoth = "both"
+)
+
+// the ways the files changed on both sides since the last sync are resolved
+const (
+	// ConflictKeepBoth renames the local file with a conflict suffix, uploads it and downloads the remote one
+	ConflictKeepBoth = "keep-both"
+	ConflictLocal    = "local"
+	ConflictRemote   = "remote"
+	ConflictNewer    = "newer"
+)
+
+const tmpSuffix = ".openlist-sync.tmp"
+
+type Options struct {
+	Local  string
+	Remote string
+	Mode   string
+	// Delete removes the files missing from the source side of the one way syncs,
+	// the two way syncs remove the files removed on the other side since the last sync
+	Delete   bool
+	Conflict string
+	DryRun   bool
+	// Log is called with each action
+	Log func(format string, args ...any)
+}
+
+type Stats struct {
+	Uploaded      int `json:"uploaded"`
+	Downloaded    int `json:"downloaded"`
+	DeletedLocal  int `json:"deleted_local"`
+	DeletedRemote int `json:"deleted_remote"`
+	Conflicts     int `json:"conflicts"`
+	Failed        int `json:"failed"`
+}
+
+type file struct {
+	Size     int64
+	Modified time.Time
+	entry    *Entry
+}
+
+type action int
+
+const (
+	actNone action = iota
+	actUpload
+	actDownload
+	actDeleteLocal
+	actDeleteRemote
+	actConflict
+	actKeepBoth
+)
+
+// sameTime tolerates the storages keeping the modified times in seconds
+func sameTime(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d < time.Second && d > -time.Second
+}
+
+func localChanged(l *file, s *FileState) bool {
+	return l.Size != s.Size || !sameTime(l.Modified, s.LocalModified)
+}
+
+func remoteChanged(r *file, s *FileState) bool {
+	return r.Size != s.Size || (!s.RemoteModified.IsZero() && !sameTime(r.Modified, s.RemoteModified))
+}
+
+// decide returns what is done to the file by its local and remote sides and its state of the last sync,
+// each of which is nil if it's missing
+func decide(mode string, del bool, l, r *file, s *FileState) action {
+	switch mode {
+	case ModePush:
+		switch {
+		case l == nil:
+			if r != nil && del {
+				return actDeleteRemote
+			}
+			return actNone
+		case r == nil || l.Size != r.Size:
+			return actUpload
+		case s == nil:
+			if l.Modified.After(r.Modified.Add(time.Second)) {
+				return actUpload
+			}
+			return actNone
+		case localChanged(l, s) || remoteChanged(r, s):
+			return actUpload
+		}
+		return actNone
+	case ModePull:
+		switch {
+		case r == nil:
+			if l != nil && del {
+				return actDeleteLocal
+			}
+			return actNone
+		case l == nil || l.Size != r.Size:
+			return actDownload
+		case s == nil:
+			if r.Modified.After(l.Modified.Add(time.Second)) {
+				return actDownload
+			}
+			return actNone
+		case localChanged(l, s) || remoteChanged(r, s):
+			return actDownload
+		}
+		return actNone
+	}
+	switch {
+	case l == nil && r == nil:
+		return actNone
+	case r == nil:
+		if s != nil && !localChanged(l, s) {
+			return actDeleteLocal
+		}
+		return actUpload
+	case l == nil:
+		if s != nil && !remoteChanged(r, s) {
+			return actDeleteRemote
+		}
+		return actDownload
+	case s == nil:
+		// the files of the same size are taken as synced by other means, their modified times may differ by the storage
+		if l.Size == r.Size {
+			return actNone
+		}
+		return actConflict
+	}
+	lc, rc := localChanged(l, s), remoteChanged(r, s)
+	switch {
+	case lc && rc:
+		if l.Size == r.Size && sameTime(l.Modified, r.Modified) {
+			return actNone
+		}
+		return actConflict
+	case lc:
+		return actUpload
+	case rc:
+		return actDownload
+	}
+	return actNone
+}
+
+func resolveConflict(policy string, l, r *file) action {
+	switch policy {
+	case ConflictLocal:
+		return actUpload
+	case ConflictRemote:
+		return actDownload
+	case ConflictNewer:
+		if l.Modified.After(r.Modified) {
+			return actUpload
+		}
+		return actDownload
+	}
+	return actKeepBoth
+}
+
+// conflictName is like a.conflict-20060102-150405.txt
+func conflictName(p string, now time.Time) string {
+	ext := stdpath.Ext(p)
+	return strings.TrimSuffix(p, ext) + ".conflict-" + now.Format("20060102-150405") + ext
+}
+
+func walkLocal(root string) (map[string]*file, error) {
+	files := make(map[string]*file)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == StateFile || rel == StateFile+".tmp" || strings.HasSuffix(rel, tmpSuffix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[rel] = &file{Size: info.Size(), Modified: info.ModTime()}
+		return nil
+	})
+	return files, errors.WithStack(err)
+}
+
+func walkRemote(ctx context.Context, c *Client, root string) (map[string]*file, error) {
+	files := make(map[string]*file)
+	dirs := []string{""}
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+		entries, err := c.List(ctx, stdpath.Join(root, dir))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed list [%s]", stdpath.Join(root, dir))
+		}
+		for i := range entries {
+			e := &entries[i]
+			if !validRemoteName(e.Name) {
+				return nil, errors.Errorf("invalid name [%s] in [%s]", e.Name, stdpath.Join(root, dir))
+			}
+			rel := stdpath.Join(dir, e.Name)
+			if !filepath.IsLocal(filepath.FromSlash(rel)) {
+				return nil, errors.Errorf("invalid path [%s]", rel)
+			}
+			if e.IsDir {
+				dirs = append(dirs, rel)
+				continue
+			}
+			files[rel] = &file{Size: e.Size, Modified: e.Modified, entry: e}
+		}
+	}
+	return files, nil
+}
+
+// validRemoteName reports whether the name listed by the server is a single path element,
+// so that it can't lead out of the local directory
+func validRemoteName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+type syncer struct {
+	c     *Client
+	opts  *Options
+	state *State
+	stats Stats
+}
+
+// Run syncs the local directory with the remote one by the options, the state of the local directory is saved
+// unless it's a dry run. A file failed to sync is counted and left to the next run
+func Run(ctx context.Context, c *Client, opts *Options) (*Stats, error) {
+	if opts.Log == nil {
+		opts.Log = func(string, ...any) {}
+	}
+	state, err := LoadState(opts.Local, c.Server, opts.Remote)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := walkLocal(opts.Local)
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := walkRemote(ctx, c, opts.Remote)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(locals)+len(remotes))
+	for p := range locals {
+		paths = append(paths, p)
+	}
+	for p := range remotes {
+		if _, ok := locals[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	slices.Sort(paths)
+	s := &syncer{c: c, opts: opts, state: state}
+	for _, p := range paths {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		l, r := locals[p], remotes[p]
+		act := decide(opts.Mode, opts.Delete, l, r, state.Files[p])
+		if act == actConflict {
+			s.stats.Conflicts++
+			act = resolveConflict(opts.Conflict, l, r)
+		}
+		if e := s.apply(ctx, act, p, l, r); e != nil {
+			s.stats.Failed++
+			opts.Log("failed sync %s: %v", p, e)
+		}
+	}
+	if !opts.DryRun {
+		if e := state.Save(opts.Local); e != nil && err == nil {
+			err = e
+		}
+	}
+	return &s.stats, err
+}
+
+func (s *syncer) localPath(p string) string {
+	return filepath.Join(s.opts.Local, filepath.FromSlash(p))
+}
+
+func (s *syncer) apply(ctx context.Context, act action, p string, l, r *file) error {
+	if act != actNone {
+		names := map[action]string{actUpload: "upload", actDownload: "download", actDeleteLocal: "delete local",
+			actDeleteRemote: "delete remote", actKeepBoth: "keep both"}
+		s.opts.Log("%s %s", names[act], p)
+		if s.opts.DryRun {
+			return nil
+		}
+	}
+	switch act {
+	case actUpload:
+		if err := s.upload(ctx, p, l); err != nil {
+			return err
+		}
+		s.stats.Uploaded++
+	case actDownload:
+		if err := s.download(ctx, p, r); err != nil {
+			return err
+		}
+		s.stats.Downloaded++
+	case actDeleteLocal:
+		if err := os.Remove(s.localPath(p)); err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+		delete(s.state.Files, p)
+		s.stats.DeletedLocal++
+	case actDeleteRemote:
+		if err := s.c.Remove(ctx, stdpath.Join(s.opts.Remote, p)); err != nil {
+			return err
+		}
+		delete(s.state.Files, p)
+		s.stats.DeletedRemote++
+	case actKeepBoth:
+		renamed := conflictName(p, time.Now())
+		if err := os.Rename(s.localPath(p), s.localPath(renamed)); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := s.upload(ctx, renamed, l); err != nil {
+			return err
+		}
+		s.stats.Uploaded++
+		if err := s.download(ctx, p, r); err != nil {
+			return err
+		}
+		s.stats.Downloaded++
+	default:
+		if l != nil && r != nil {
+			s.state.Files[p] = &FileState{Size: l.Size, LocalModified: l.Modified, RemoteModified: r.Modified}
+		} else {
+			delete(s.state.Files, p)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) upload(ctx context.Context, p string, l *file) error {
+	f, err := os.Open(s.localPath(p))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if err = s.c.Upload(ctx, stdpath.Join(s.opts.Remote, p), f, l.Size, l.Modified); err != nil {
+		return err
+	}
+	s.state.Files[p] = &FileState{Size: l.Size, LocalModified: l.Modified}
+	return nil
+}
+
+// download writes the remote file into a temporary file renamed to the local path once it's complete
+func (s *syncer) download(ctx context.Context, p string, r *file) error {
+	dst := s.localPath(p)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return errors.WithStack(err)
+	}
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+tmpSuffix)
+	f, err := os.Create(tmp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = s.c.Download(ctx, stdpath.Join(s.opts.Remote, p), r.entry, f)
+	if e := f.Close(); e != nil && err == nil {
+		err = errors.WithStack(e)
+	}
+	if err == nil {
+		err = errors.WithStack(os.Chtimes(tmp, r.Modified, r.Modified))
+	}
+	if err == nil {
+		err = errors.WithStack(os.Rename(tmp, dst))
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	s.state.Files[p] = &FileState{Size: r.Size, LocalModified: info.ModTime(), RemoteModified: r.Modified}
+	return nil
+}
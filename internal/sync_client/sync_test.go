@@ -0,0 +1,67 @@
+package sync_client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecide(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+	synced := &FileState{Size: 10, LocalModified: t0, RemoteModified: t0}
+	f := func(size int64, modified time.Time) *file { return &file{Size: size, Modified: modified} }
+	tests := []struct {
+		name   string
+		mode   string
+		del    bool
+		l, r   *file
+		s      *FileState
+		expect action
+	}{
+		{"push new", ModePush, false, f(10, t0), nil, nil, actUpload},
+		{"push unchanged", ModePush, false, f(10, t0), f(10, t0), synced, actNone},
+		{"push local changed", ModePush, false, f(10, t1), f(10, t0), synced, actUpload},
+		{"push remote changed", ModePush, false, f(10, t0), f(10, t1), synced, actUpload},
+		{"push remote extra kept", ModePush, false, nil, f(10, t0), nil, actNone},
+		{"push remote extra deleted", ModePush, true, nil, f(10, t0), nil, actDeleteRemote},
+		{"push first sync remote newer", ModePush, false, f(10, t0), f(10, t1), nil, actNone},
+		{"pull new", ModePull, false, nil, f(10, t0), nil, actDownload},
+		{"pull local extra deleted", ModePull, true, f(10, t0), nil, nil, actDeleteLocal},
+		{"both unchanged", ModeBoth, false, f(10, t0), f(10, t0), synced, actNone},
+		{"both local changed", ModeBoth, false, f(12, t1), f(10, t0), synced, actUpload},
+		{"both remote changed", ModeBoth, false, f(10, t0), f(12, t1), synced, actDownload},
+		{"both changed", ModeBoth, false, f(11, t1), f(12, t1), synced, actConflict},
+		{"both changed alike", ModeBoth, false, f(12, t1), f(12, t1), synced, actNone},
+		{"both removed locally", ModeBoth, false, nil, f(10, t0), synced, actDeleteRemote},
+		{"both removed remotely", ModeBoth, false, f(10, t0), nil, synced, actDeleteLocal},
+		{"both removed remotely changed locally", ModeBoth, false, f(12, t1), nil, synced, actUpload},
+		{"both new local", ModeBoth, false, f(10, t0), nil, nil, actUpload},
+		{"both new remote", ModeBoth, false, nil, f(10, t0), nil, actDownload},
+		{"both first sync differ", ModeBoth, false, f(10, t0), f(12, t1), nil, actConflict},
+		// the remote modified time isn't known after an upload
+		{"both uploaded", ModeBoth, false, f(10, t0), f(10, t1), &FileState{Size: 10, LocalModified: t0}, actNone},
+	}
+	for _, tt := range tests {
+		if got := decide(tt.mode, tt.del, tt.l, tt.r, tt.s); got != tt.expect {
+			t.Errorf("%s: decide() = %v, want %v", tt.name, got, tt.expect)
+		}
+	}
+}
+
+func TestConflictName(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	if got := conflictName("a/b.txt", now); got != "a/b.conflict-20240506-070809.txt" {
+		t.Errorf("conflictName() = %s", got)
+	}
+}
+
+func TestValidRemoteName(t *testing.T) {
+	for name, expect := range map[string]bool{
+		"a.txt": true, "a b": true, "": false, ".": false, "..": false,
+		"../a": false, "a/b": false, `a\b`: false, "a..b": true,
+	} {
+		if got := validRemoteName(name); got != expect {
+			t.Errorf("validRemoteName(%q) = %v, want %v", name, got, expect)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/tache"
+)
+
+func isDone(state tache.State) bool {
+	return state == tache.StateSucceeded || state == tache.StateFailed || state == tache.StateCanceled
+}
+
+// newTaskRecord is the record of the finished task of typ kept by the task archive and the task history
+func newTaskRecord(typ string, t TaskExtensionInfo) model.TaskRecord {
+	r := model.TaskRecord{
+		Type:       typ,
+		TaskID:     t.GetID(),
+		Name:       t.GetName(),
+		State:      int(t.GetState()),
+		Status:     t.GetStatus(),
+		TotalBytes: t.GetTotalBytes(),
+		StartTime:  t.GetStartTime(),
+		EndTime:    t.GetEndTime(),
+
+		TransferredBytes: t.GetTransferredBytes(),
+		Speed:            t.GetSpeed(),
+	}
+	if creator := t.GetCreator(); creator != nil {
+		r.CreatorId, r.Creator = creator.ID, creator.Username
+	}
+	if err := t.GetErr(); err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// ArchiveDone moves the finished tasks of the manager which ended before the time into the task archive
+func ArchiveDone[T TaskExtensionInfo](typ string, manager Manager[T], before time.Time) (int, error) {
+	tasks := manager.GetByCondition(func(t T) bool {
+		end := t.GetEndTime()
+		return isDone(t.GetState()) && end != nil && end.Before(before)
+	})
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+	archives := make([]model.TaskArchive, 0, len(tasks))
+	ids := make(map[string]struct{}, len(tasks))
+	for _, t := range tasks {
+		archives = append(archives, model.TaskArchive{
+			TaskRecord: newTaskRecord(typ, t),
+			Month:      t.GetEndTime().Format("2006-01"),
+		})
+		ids[t.GetID()] = struct{}{}
+	}
+	if err := db.CreateTaskArchives(archives); err != nil {
+		return 0, err
+	}
+	manager.RemoveByCondition(func(t T) bool {
+		_, ok := ids[t.GetID()]
+		return ok
+	})
+	return len(archives), nil
+}
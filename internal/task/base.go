@@ -2,10 +2,13 @@ package task
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/tache"
 )
 
@@ -16,6 +19,28 @@ type TaskExtension struct {
 	endTime    *time.Time
 	TotalBytes int64
 	ApiUrl     string
+	// RequestID is the id of the api request which created the task
+	RequestID string
+	// Priority orders the tasks waiting for the slots of their manager, the higher first
+	Priority int `json:"priority,omitempty"`
+	// SpeedLimit overrides the speed limit of the task type in KB/s, negative for no limit
+	SpeedLimit int `json:"speed_limit,omitempty"`
+	// FailedLogs are the log lines of the failed task, persisted if the task_persist_failed_logs setting is on
+	FailedLogs []LogLine `json:"failed_logs,omitempty"`
+
+	pauseMu sync.Mutex
+	// resume is closed when the paused task is resumed, nil if the task is not paused
+	resume chan struct{}
+	speed  speedSampler
+	// doneCalled is set once the done hooks are called
+	doneCalled atomic.Bool
+	speedType  string
+	limiters   *stream.TaskLimiters
+	logsMu     sync.Mutex
+	logs       []LogLine
+	slotsMu    sync.Mutex
+	// slots are the ones of the slot held by the task, nil if it holds none
+	slots *Slots
 }
 
 func (t *TaskExtension) SetCtx(ctx context.Context) {
@@ -25,6 +50,17 @@ func (t *TaskExtension) SetCtx(ctx context.Context) {
 	if len(t.ApiUrl) > 0 {
 		ctx = context.WithValue(ctx, conf.ApiUrlKey, t.ApiUrl)
 	}
+	if len(t.RequestID) > 0 {
+		ctx = context.WithValue(ctx, conf.RequestIDKey, t.RequestID)
+	}
+	if t.Priority != 0 {
+		ctx = context.WithValue(ctx, conf.TaskPriorityKey, t.Priority)
+	}
+	if t.SpeedLimit != 0 {
+		ctx = context.WithValue(ctx, conf.TaskSpeedLimitKey, t.SpeedLimit)
+	}
+	ctx = t.withLimiters(ctx)
+	ctx = context.WithValue(ctx, conf.TaskLoggerKey, t)
 	t.Base.SetCtx(ctx)
 }
 
@@ -65,6 +101,58 @@ func (t *TaskExtension) GetTotalBytes() int64 {
 	return t.TotalBytes
 }
 
+func (t *TaskExtension) GetRequestID() string {
+	return t.RequestID
+}
+
+func (t *TaskExtension) GetPriority() int {
+	return t.Priority
+}
+
+func (t *TaskExtension) SetPriority(priority int) {
+	t.Priority = priority
+	t.Persist()
+}
+
+// WaitSlot waits for a slot of s before running the task, the task is pending meanwhile.
+// The returned func releases the slot
+func (t *TaskExtension) WaitSlot(s *Slots) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	t.SetState(tache.StatePending)
+	if err := s.Acquire(t.Ctx(), t.GetPriority); err != nil {
+		return nil, err
+	}
+	t.SetState(tache.StateRunning)
+	t.slotsMu.Lock()
+	t.slots = s
+	t.slotsMu.Unlock()
+	return func() { t.releaseSlot() }, nil
+}
+
+// releaseSlot releases the slot held by the task and returns its slots, nil if the task holds none
+func (t *TaskExtension) releaseSlot() *Slots {
+	t.slotsMu.Lock()
+	s := t.slots
+	t.slots = nil
+	t.slotsMu.Unlock()
+	if s != nil {
+		s.Release()
+	}
+	return s
+}
+
+// reacquireSlot waits for the slot released by releaseSlot again, the task holds none if it's canceled meanwhile
+func (t *TaskExtension) reacquireSlot(s *Slots) {
+	if s.Acquire(t.Ctx(), t.GetPriority) != nil {
+		return
+	}
+	t.slotsMu.Lock()
+	t.slots = s
+	t.slotsMu.Unlock()
+}
+
 func (t *TaskExtension) SetRetry(retry int, maxRetry int) {
 	t.Base.SetRetry(retry, maxRetry)
 	if retry > 0 || !conf.Conf.Tasks.AllowRetryCanceled || t.Ctx() == nil {
@@ -85,4 +173,16 @@ type TaskExtensionInfo interface {
 	GetStartTime() *time.Time
 	GetEndTime() *time.Time
 	GetTotalBytes() int64
+	GetTransferredBytes() int64
+	GetSpeed() float64
+	GetETA() *time.Time
+	GetRequestID() string
+	GetPriority() int
+	SetPriority(priority int)
+	GetSpeedLimit() int
+	SetSpeedLimit(limit int)
+	Pause() bool
+	Resume() bool
+	IsPaused() bool
+	GetLogs() []LogLine
 }
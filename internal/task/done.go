@@ -0,0 +1,64 @@
+package task
+
+import (
+	"github.com/OpenListTeam/tache"
+)
+
+// tache has no callbacks of the state changes, so the done hooks are called by SetState
+// for the tasks of the managers watched by WatchDone
+
+// DoneHook is called once a task is succeeded, failed or canceled, typ is the type it's watched as.
+// It's called by the goroutine changing the state, so it has to be quick
+type DoneHook func(typ string, t TaskExtensionInfo)
+
+var (
+	doneHooks    []DoneHook
+	doneManagers []func(id string) (string, TaskExtensionInfo, bool)
+)
+
+func RegisterDoneHook(hook DoneHook) {
+	doneHooks = append(doneHooks, hook)
+}
+
+// WatchDone calls the done hooks with the tasks of the manager as the tasks of typ
+func WatchDone[T TaskExtensionInfo](typ string, manager Manager[T]) {
+	doneManagers = append(doneManagers, func(id string) (string, TaskExtensionInfo, bool) {
+		t, ok := manager.GetByID(id)
+		return typ, t, ok
+	})
+}
+
+func callDoneHooks(id string) {
+	for _, get := range doneManagers {
+		if typ, t, ok := get(id); ok {
+			for _, hook := range doneHooks {
+				hook(typ, t)
+			}
+			return
+		}
+	}
+}
+
+// SetState calls the done hooks and releases the speed limit of the type once the task is done. The canceled tasks may be set canceled twice
+// around their OnFailed, so the hooks aren't called again until the task is retried.
+// The failures and the retries are recorded into the log lines of the task
+func (t *TaskExtension) SetState(state tache.State) {
+	if state == tache.StateWaitingRetry && t.GetState() != state {
+		t.Logf("retrying for the error: %v", t.GetErr())
+	}
+	t.Base.SetState(state)
+	switch state {
+	case tache.StateSucceeded, tache.StateFailed, tache.StateCanceled:
+		t.releaseSpeedLimit()
+		if !t.doneCalled.Swap(true) {
+			if state == tache.StateFailed {
+				t.Logf("failed: %v", t.GetErr())
+				t.keepFailedLogs()
+			}
+			callDoneHooks(t.GetID())
+		}
+	case tache.StatePending, tache.StateWaitingRetry, tache.StateBeforeRetry:
+		t.doneCalled.Store(false)
+		t.clearFailedLogs()
+	}
+}
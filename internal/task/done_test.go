@@ -0,0 +1,64 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/tache"
+)
+
+type doneTestTask struct {
+	TaskExtension
+	err error
+	// started is closed when the task runs, then it runs until canceled if err is nil
+	started chan struct{}
+}
+
+func (t *doneTestTask) GetName() string   { return "done test" }
+func (t *doneTestTask) GetStatus() string { return "" }
+func (t *doneTestTask) Run() error {
+	if t.err != nil {
+		return t.err
+	}
+	close(t.started)
+	<-t.Ctx().Done()
+	return t.Ctx().Err()
+}
+
+func TestDoneHooks(t *testing.T) {
+	conf.Conf = conf.DefaultConfig("data")
+	m := tache.NewManager[*doneTestTask](tache.WithWorks(1))
+	WatchDone("test", m)
+	done := make(chan tache.State, 4)
+	RegisterDoneHook(func(typ string, t TaskExtensionInfo) {
+		if typ == "test" {
+			done <- t.GetState()
+		}
+	})
+	wait := func(want tache.State) {
+		t.Helper()
+		select {
+		case got := <-done:
+			if got != want {
+				t.Fatalf("done with state %v, want %v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("done hook not called, want state %v", want)
+		}
+	}
+	m.Add(&doneTestTask{err: errors.New("failed")})
+	wait(tache.StateFailed)
+	// the canceled tasks are set canceled twice by the worker
+	task := &doneTestTask{started: make(chan struct{})}
+	m.Add(task)
+	<-task.started
+	m.Cancel(task.GetID())
+	wait(tache.StateCanceled)
+	select {
+	case got := <-done:
+		t.Fatalf("done hook called again with state %v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,24 @@
+package task
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// RecordHistory is the done hook recording the finished task of typ into the task history
+func RecordHistory(typ string, t TaskExtensionInfo) {
+	h := &model.TaskHistory{TaskRecord: newTaskRecord(typ, t)}
+	if h.EndTime == nil {
+		now := time.Now()
+		h.EndTime = &now
+	}
+	h.Day = h.EndTime.Format(time.DateOnly)
+	go func() {
+		if err := db.CreateTaskHistory(h); err != nil {
+			log.Errorf("failed record the %s task %s into the task history: %+v", typ, h.TaskID, err)
+		}
+	}()
+}
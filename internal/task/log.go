@@ -0,0 +1,66 @@
+package task
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// MaxLogLines is the number of the recent log lines kept by a task
+const MaxLogLines = 200
+
+type LogLine struct {
+	Time time.Time `json:"time"`
+	Msg  string    `json:"msg"`
+}
+
+// Logf records a log line of the task, such as a step, the output of a tool or the reason of a retry
+func (t *TaskExtension) Logf(format string, args ...any) {
+	line := LogLine{Time: time.Now(), Msg: fmt.Sprintf(format, args...)}
+	t.logsMu.Lock()
+	defer t.logsMu.Unlock()
+	if len(t.logs) < MaxLogLines {
+		t.logs = append(t.logs, line)
+		return
+	}
+	copy(t.logs, t.logs[1:])
+	t.logs[len(t.logs)-1] = line
+}
+
+// GetLogs returns the recent log lines of the task, the oldest first.
+// A failed task restored from the persisted data returns the lines it had when it failed
+func (t *TaskExtension) GetLogs() []LogLine {
+	t.logsMu.Lock()
+	defer t.logsMu.Unlock()
+	if len(t.logs) == 0 {
+		return append([]LogLine{}, t.FailedLogs...)
+	}
+	return append([]LogLine{}, t.logs...)
+}
+
+// persistFailedLogs is the task_persist_failed_logs setting
+var persistFailedLogs atomic.Bool
+
+// SetPersistFailedLogs sets whether the log lines of the failed tasks are persisted with their data
+func SetPersistFailedLogs(persist bool) {
+	persistFailedLogs.Store(persist)
+}
+
+// keepFailedLogs persists the log lines of the failed task if it's enabled
+func (t *TaskExtension) keepFailedLogs() {
+	if !persistFailedLogs.Load() {
+		return
+	}
+	logs := t.GetLogs()
+	t.logsMu.Lock()
+	t.FailedLogs = logs
+	t.logsMu.Unlock()
+	t.Persist()
+}
+
+// clearFailedLogs drops the persisted log lines once the failed task is retried
+func (t *TaskExtension) clearFailedLogs() {
+	t.logsMu.Lock()
+	defer t.logsMu.Unlock()
+	t.FailedLogs = nil
+}
@@ -0,0 +1,27 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestLogs(t *testing.T) {
+	task := &TaskExtension{FailedLogs: []LogLine{{Msg: "persisted"}}}
+	if logs := task.GetLogs(); len(logs) != 1 || logs[0].Msg != "persisted" {
+		t.Fatalf("expect the persisted lines before any log, got %+v", logs)
+	}
+	task.SetCtx(context.Background())
+	for i := 0; i < MaxLogLines+10; i++ {
+		model.TaskLogf(task.Ctx(), "line %d", i)
+	}
+	logs := task.GetLogs()
+	if len(logs) != MaxLogLines {
+		t.Fatalf("expect %d lines, got %d", MaxLogLines, len(logs))
+	}
+	if first, last := logs[0].Msg, logs[len(logs)-1].Msg; first != "line 10" || last != fmt.Sprintf("line %d", MaxLogLines+9) {
+		t.Errorf("expect the recent lines, got %s to %s", first, last)
+	}
+}
@@ -0,0 +1,101 @@
+package task
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+// a paused task holds its transfer in the progress reports of the upload, which most drivers make
+// as they read the stream, so that it goes on from the same offset when resumed. There is no offset
+// to resume a transfer from after its connections are closed, so the held transfer is bounded by
+// MaxTransferPause, after which it goes on while the task is still shown paused, and the pause applies
+// again to its next run. The slot of the task is released while the transfer is held, so the other
+// tasks aren't blocked by a paused one. A paused task which hasn't started yet waits before starting.
+// The pause is not persisted, the tasks recovered after a restart are not paused
+
+// MaxTransferPause bounds how long a transfer is held in its progress reports
+var MaxTransferPause = 10 * time.Minute
+
+// Pause pauses the task, it reports false if the task is paused already
+func (t *TaskExtension) Pause() bool {
+	t.pauseMu.Lock()
+	defer t.pauseMu.Unlock()
+	if t.resume != nil {
+		return false
+	}
+	t.resume = make(chan struct{})
+	return true
+}
+
+// Resume resumes the paused task, it reports false if the task is not paused
+func (t *TaskExtension) Resume() bool {
+	t.pauseMu.Lock()
+	defer t.pauseMu.Unlock()
+	if t.resume == nil {
+		return false
+	}
+	close(t.resume)
+	t.resume = nil
+	return true
+}
+
+func (t *TaskExtension) IsPaused() bool {
+	t.pauseMu.Lock()
+	defer t.pauseMu.Unlock()
+	return t.resume != nil
+}
+
+// resumed returns the channel closed when the task is resumed, nil if the task is not paused
+func (t *TaskExtension) resumed() <-chan struct{} {
+	t.pauseMu.Lock()
+	defer t.pauseMu.Unlock()
+	if t.resume == nil {
+		return nil
+	}
+	return t.resume
+}
+
+// WaitResume blocks while the task is paused, until it's resumed or canceled
+func (t *TaskExtension) WaitResume() error {
+	resumed := t.resumed()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-t.Ctx().Done():
+		return t.Ctx().Err()
+	}
+}
+
+// PausableProgress returns up which holds the transfer while the task is paused, or while any of paused
+// returns a channel, which is closed when the transfer may go on, e.g. while a storage of the task is paused
+func (t *TaskExtension) PausableProgress(up model.UpdateProgress, paused ...func() <-chan struct{}) model.UpdateProgress {
+	return func(percentage float64) {
+		up(percentage)
+		t.holdTransfer(t.resumed())
+		for _, p := range paused {
+			t.holdTransfer(p())
+		}
+	}
+}
+
+// holdTransfer blocks until resumed is closed, up to MaxTransferPause, with the slot of the task released
+func (t *TaskExtension) holdTransfer(resumed <-chan struct{}) {
+	if resumed == nil {
+		return
+	}
+	if s := t.releaseSlot(); s != nil {
+		defer t.reacquireSlot(s)
+	}
+	timer := time.NewTimer(MaxTransferPause)
+	defer timer.Stop()
+	select {
+	case <-resumed:
+	case <-t.Ctx().Done():
+	case <-timer.C:
+		t.Logf("the transfer goes on after being held for %s", MaxTransferPause)
+	}
+}
@@ -0,0 +1,82 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPausableProgress(t *testing.T) {
+	var task TaskExtension
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	task.SetCtx(ctx)
+	var reported float64
+	up := task.PausableProgress(func(p float64) { reported = p })
+	up(10)
+	if !task.Pause() || task.Pause() {
+		t.Fatal("Pause() should only succeed once")
+	}
+	done := make(chan struct{})
+	go func() {
+		up(20)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("the progress report of the paused task should block")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !task.Resume() || task.Resume() {
+		t.Fatal("Resume() should only succeed once")
+	}
+	<-done
+	if reported != 20 {
+		t.Errorf("reported progress = %v, want 20", reported)
+	}
+}
+
+func TestHeldTransferReleasesSlot(t *testing.T) {
+	var task TaskExtension
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	task.SetCtx(ctx)
+	slots := NewSlots(1)
+	release, err := task.WaitSlot(slots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	up := task.PausableProgress(func(float64) {})
+	task.Pause()
+	done := make(chan struct{})
+	go func() {
+		up(10)
+		close(done)
+	}()
+	acquireCtx, acquireCancel := context.WithTimeout(ctx, time.Second)
+	defer acquireCancel()
+	if err := slots.Acquire(acquireCtx, func() int { return 0 }); err != nil {
+		t.Fatal("the slot of the held transfer should be released")
+	}
+	task.Resume()
+	select {
+	case <-done:
+		t.Fatal("the resumed transfer should wait for the slot again")
+	case <-time.After(20 * time.Millisecond):
+	}
+	slots.Release()
+	<-done
+}
+
+func TestHeldTransferIsBounded(t *testing.T) {
+	var task TaskExtension
+	task.SetCtx(context.Background())
+	defer func(d time.Duration) { MaxTransferPause = d }(MaxTransferPause)
+	MaxTransferPause = 10 * time.Millisecond
+	task.Pause()
+	task.PausableProgress(func(float64) {})(10)
+	if !task.IsPaused() {
+		t.Error("the task should be still paused after the held transfer goes on")
+	}
+}
@@ -0,0 +1,109 @@
+package task
+
+import (
+	"context"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+)
+
+// DispatchWorkers is the number of the tache workers of the managers dispatching by priority,
+// the tasks hold a worker while waiting for a slot so it only bounds how many of them are considered
+const DispatchWorkers = 1024
+
+// Slots limits the number of the tasks of a manager running at once, a free slot is given to the
+// waiting task of the highest priority, the tasks of the same priority in the order they started waiting
+type Slots struct {
+	mu      sync.Mutex
+	size    int
+	used    int
+	seq     uint64
+	waiters []*slotWaiter
+}
+
+type slotWaiter struct {
+	priority func() int
+	seq      uint64
+	ready    chan struct{}
+}
+
+func NewSlots(size int) *Slots {
+	return &Slots{size: size}
+}
+
+func (s *Slots) SetSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size = size
+	s.dispatch()
+}
+
+// Acquire waits for a free slot, priority is read each time a slot is given so that it can be changed meanwhile
+func (s *Slots) Acquire(ctx context.Context, priority func() int) error {
+	s.mu.Lock()
+	if s.used < s.size && len(s.waiters) == 0 {
+		s.used++
+		s.mu.Unlock()
+		return nil
+	}
+	w := &slotWaiter{priority: priority, seq: s.seq, ready: make(chan struct{})}
+	s.seq++
+	s.waiters = append(s.waiters, w)
+	s.mu.Unlock()
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-w.ready:
+		// the slot has been given meanwhile, pass it on
+		s.used--
+		s.dispatch()
+	default:
+		for i := range s.waiters {
+			if s.waiters[i] == w {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				break
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+func (s *Slots) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used--
+	s.dispatch()
+}
+
+// Waiting returns the number of the tasks waiting for a slot
+func (s *Slots) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiters)
+}
+
+func (s *Slots) dispatch() {
+	for s.used < s.size && len(s.waiters) > 0 {
+		best, bestPriority := 0, s.waiters[0].priority()
+		for i := 1; i < len(s.waiters); i++ {
+			if p := s.waiters[i].priority(); p > bestPriority || (p == bestPriority && s.waiters[i].seq < s.waiters[best].seq) {
+				best, bestPriority = i, p
+			}
+		}
+		w := s.waiters[best]
+		s.waiters = append(s.waiters[:best], s.waiters[best+1:]...)
+		s.used++
+		close(w.ready)
+	}
+}
+
+// CtxPriority returns the priority of the tasks created with ctx
+func CtxPriority(ctx context.Context) int {
+	p, _ := ctx.Value(conf.TaskPriorityKey).(int)
+	return p
+}
@@ -0,0 +1,41 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSlots(t *testing.T) {
+	s := NewSlots(1)
+	if err := s.Acquire(context.Background(), func() int { return 0 }); err != nil {
+		t.Fatal(err)
+	}
+	order := make(chan int, 3)
+	wait := func(id, priority int) {
+		go func() {
+			if err := s.Acquire(context.Background(), func() int { return priority }); err != nil {
+				t.Error(err)
+				return
+			}
+			order <- id
+		}()
+		for s.Waiting() < id {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wait(1, 0)
+	wait(2, 5)
+	wait(3, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Acquire(ctx, func() int { return 10 }); err == nil {
+		t.Error("Acquire() with a canceled context should fail")
+	}
+	for _, want := range []int{2, 3, 1} {
+		s.Release()
+		if got := <-order; got != want {
+			t.Fatalf("got slot of task %d, want task %d", got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package task
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/tache"
+)
+
+// the transferred bytes are the progress of the total bytes, and the speed is sampled from the
+// progress reports of the task, so that the tasks of all managers report them
+const (
+	speedWindow = time.Second
+	// speedStale is how long the speed holds without progress, e.g. while the task is paused
+	speedStale = 10 * time.Second
+)
+
+type speedSampler struct {
+	mu          sync.Mutex
+	sampleTime  time.Time
+	sampleBytes int64
+	speed       float64
+	// updated is the time of the latest progress report
+	updated time.Time
+}
+
+func (s *speedSampler) sample(bytes int64, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updated = now
+	// the transfer started over, e.g. it's retried
+	if s.sampleTime.IsZero() || bytes < s.sampleBytes {
+		s.sampleTime, s.sampleBytes, s.speed = now, bytes, 0
+		return
+	}
+	if dt := now.Sub(s.sampleTime); dt >= speedWindow {
+		s.speed = float64(bytes-s.sampleBytes) / dt.Seconds()
+		s.sampleTime, s.sampleBytes = now, bytes
+	}
+}
+
+func (s *speedSampler) get(now time.Time) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.updated) > speedStale {
+		return 0
+	}
+	return s.speed
+}
+
+func (t *TaskExtension) SetProgress(progress float64) {
+	t.Base.SetProgress(progress)
+	t.speed.sample(t.GetTransferredBytes(), time.Now())
+}
+
+func (t *TaskExtension) GetTransferredBytes() int64 {
+	progress := t.GetProgress()
+	if t.TotalBytes <= 0 || math.IsNaN(progress) {
+		return 0
+	}
+	if t.GetState() == tache.StateSucceeded {
+		return t.TotalBytes
+	}
+	return int64(float64(t.TotalBytes) * min(max(progress, 0), 100) / 100)
+}
+
+// GetSpeed returns the current bytes per second of the running task, or the average of the done one
+func (t *TaskExtension) GetSpeed() float64 {
+	if !isDone(t.GetState()) {
+		return t.speed.get(time.Now())
+	}
+	start, end := t.GetStartTime(), t.GetEndTime()
+	if start == nil || end == nil || !end.After(*start) {
+		return 0
+	}
+	return float64(t.GetTransferredBytes()) / end.Sub(*start).Seconds()
+}
+
+// GetETA estimates the completion time of the running task from its current speed, nil if unknown
+func (t *TaskExtension) GetETA() *time.Time {
+	if t.GetState() != tache.StateRunning || t.TotalBytes <= 0 {
+		return nil
+	}
+	speed := t.speed.get(time.Now())
+	if speed <= 0 {
+		return nil
+	}
+	remaining := float64(t.TotalBytes-t.GetTransferredBytes()) / speed
+	eta := time.Now().Add(time.Duration(remaining * float64(time.Second)))
+	return &eta
+}
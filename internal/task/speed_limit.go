@@ -0,0 +1,111 @@
+package task
+
+import (
+	"context"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// typeSpeedLimits is the speed limit of each task of a type in KB/s, negative for no limit
+	typeSpeedLimits = make(map[string]int)
+	// speedLimited are the tasks of which the limiters follow the speed limit of their type
+	speedLimited  = make(map[*TaskExtension]struct{})
+	speedLimitsMu sync.Mutex
+)
+
+// SetTypeSpeedLimit sets the speed limit of each task of the type in KB/s, negative for no limit.
+// The limiters of the running tasks are adjusted at once
+func SetTypeSpeedLimit(typ string, limit int) {
+	speedLimitsMu.Lock()
+	defer speedLimitsMu.Unlock()
+	typeSpeedLimits[typ] = limit
+	for t := range speedLimited {
+		if t.speedType == typ {
+			t.applySpeedLimit()
+		}
+	}
+}
+
+func speedLimitRate(limit int) (rate.Limit, int) {
+	if limit <= 0 {
+		return rate.Inf, 0
+	}
+	return rate.Limit(limit) * 1024.0, limit * 1024
+}
+
+// withLimiters adds the limiters of the task to ctx, they are unlimited until the task sets its type
+func (t *TaskExtension) withLimiters(ctx context.Context) context.Context {
+	speedLimitsMu.Lock()
+	defer speedLimitsMu.Unlock()
+	if t.limiters == nil {
+		t.limiters = &stream.TaskLimiters{
+			Download: stream.BlockBurstLimiter{Limiter: rate.NewLimiter(rate.Inf, 0)},
+			Upload:   stream.BlockBurstLimiter{Limiter: rate.NewLimiter(rate.Inf, 0)},
+		}
+		t.applySpeedLimit()
+	}
+	return stream.WithTaskLimiters(ctx, t.limiters)
+}
+
+// SetSpeedLimitType makes the limiters of the task follow the speed limit of the type, which is the type
+// the manager of the task is watched as, until the task is done. It's called as the task runs
+func (t *TaskExtension) SetSpeedLimitType(typ string) {
+	speedLimitsMu.Lock()
+	defer speedLimitsMu.Unlock()
+	t.speedType = typ
+	if t.limiters == nil {
+		// run without a manager
+		return
+	}
+	speedLimited[t] = struct{}{}
+	t.applySpeedLimit()
+}
+
+func (t *TaskExtension) GetSpeedLimit() int {
+	return t.SpeedLimit
+}
+
+// SetSpeedLimit overrides the speed limit of the type in KB/s, 0 to follow the type and negative for no limit
+func (t *TaskExtension) SetSpeedLimit(limit int) {
+	speedLimitsMu.Lock()
+	t.SpeedLimit = limit
+	t.applySpeedLimit()
+	speedLimitsMu.Unlock()
+	t.Persist()
+}
+
+// releaseSpeedLimit stops following the speed limit of the type once the task is done
+func (t *TaskExtension) releaseSpeedLimit() {
+	speedLimitsMu.Lock()
+	defer speedLimitsMu.Unlock()
+	delete(speedLimited, t)
+}
+
+// applySpeedLimit sets the limiters by the speed limit of the task, speedLimitsMu is held
+func (t *TaskExtension) applySpeedLimit() {
+	if t.limiters == nil {
+		return
+	}
+	limit := t.SpeedLimit
+	if limit == 0 {
+		limit = -1
+		if l, ok := typeSpeedLimits[t.speedType]; ok && t.speedType != "" {
+			limit = l
+		}
+	}
+	r, burst := speedLimitRate(limit)
+	for _, l := range []stream.Limiter{t.limiters.Download, t.limiters.Upload} {
+		l.SetLimit(r)
+		l.SetBurst(burst)
+	}
+}
+
+// CtxSpeedLimit returns the speed limit of the tasks created with ctx
+func CtxSpeedLimit(ctx context.Context) int {
+	l, _ := ctx.Value(conf.TaskSpeedLimitKey).(int)
+	return l
+}
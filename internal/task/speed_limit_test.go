@@ -0,0 +1,42 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"golang.org/x/time/rate"
+)
+
+func TestSpeedLimit(t *testing.T) {
+	const typ = "speed_limit_test"
+	task := &TaskExtension{}
+	ctx := task.withLimiters(context.Background())
+	up, down := stream.TaskUploadLimiter(ctx), stream.TaskDownloadLimiter(ctx)
+	if up == nil || down == nil {
+		t.Fatal("expect the limiters in the ctx")
+	}
+	expect := func(limit rate.Limit) {
+		t.Helper()
+		if up.Limit() != limit || down.Limit() != limit {
+			t.Errorf("expect limit %v, got %v and %v", limit, up.Limit(), down.Limit())
+		}
+	}
+	SetTypeSpeedLimit(typ, 100)
+	expect(rate.Inf)
+	task.SetSpeedLimitType(typ)
+	expect(100 * 1024)
+	SetTypeSpeedLimit(typ, 200)
+	expect(200 * 1024)
+	task.SetSpeedLimit(50)
+	expect(50 * 1024)
+	SetTypeSpeedLimit(typ, 300)
+	expect(50 * 1024)
+	task.SetSpeedLimit(-1)
+	expect(rate.Inf)
+	task.SetSpeedLimit(0)
+	expect(300 * 1024)
+	task.releaseSpeedLimit()
+	SetTypeSpeedLimit(typ, -1)
+	expect(300 * 1024)
+}
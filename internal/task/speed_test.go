@@ -0,0 +1,28 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpeedSampler(t *testing.T) {
+	var s speedSampler
+	now := time.Now()
+	s.sample(0, now)
+	s.sample(100, now.Add(500*time.Millisecond))
+	if got := s.get(now.Add(500 * time.Millisecond)); got != 0 {
+		t.Errorf("speed within the window = %v, want 0", got)
+	}
+	s.sample(200, now.Add(2*time.Second))
+	if got := s.get(now.Add(2 * time.Second)); got != 100 {
+		t.Errorf("speed = %v, want 100", got)
+	}
+	if got := s.get(now.Add(2*time.Second + speedStale + time.Second)); got != 0 {
+		t.Errorf("stale speed = %v, want 0", got)
+	}
+	// restarted
+	s.sample(50, now.Add(3*time.Second))
+	if got := s.get(now.Add(3 * time.Second)); got != 0 {
+		t.Errorf("speed after restart = %v, want 0", got)
+	}
+}
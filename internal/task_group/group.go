@@ -7,7 +7,8 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-type OnCompletionFunc func(ctx context.Context, groupID string, payloads ...any)
+// hasFailure reports whether any task of the group has failed
+type OnCompletionFunc func(ctx context.Context, groupID string, hasFailure bool, payloads ...any)
 type TaskGroupCoordinator struct {
 	name string
 	mu   sync.Mutex
@@ -20,6 +21,7 @@ type TaskGroupCoordinator struct {
 type groupState struct {
 	pending    int
 	hasSuccess bool
+	hasFailure bool
 }
 
 func NewTaskGroupCoordinator(name string, f OnCompletionFunc) *TaskGroupCoordinator {
@@ -63,16 +65,18 @@ func (tgc *TaskGroupCoordinator) Done(ctx context.Context, groupID string, succe
 	}
 	if success {
 		state.hasSuccess = true
+	} else {
+		state.hasFailure = true
 	}
 	logrus.Debugf("Done:%s ,state=%+v", groupID, state)
 	if state.pending == 1 {
 		payloads := tgc.groupPayloads[groupID]
 		delete(tgc.groupStates, groupID)
 		delete(tgc.groupPayloads, groupID)
-		if tgc.onCompletion != nil && state.hasSuccess {
+		if tgc.onCompletion != nil && (state.hasSuccess || state.hasFailure && completesOnFailure(payloads)) {
 			logrus.Debugf("OnCompletion:%s", groupID)
 			tgc.mu.Unlock()
-			tgc.onCompletion(ctx, groupID, payloads...)
+			tgc.onCompletion(ctx, groupID, state.hasFailure, payloads...)
 			tgc.mu.Lock()
 		}
 		return
@@ -80,3 +84,43 @@ func (tgc *TaskGroupCoordinator) Done(ctx context.Context, groupID string, succe
 	state.pending--
 	tgc.groupStates[groupID] = state
 }
+
+// MarkSucceeded records a success of the group without finishing a task,
+// used when a task transfers several files itself and some of them may fail
+func (tgc *TaskGroupCoordinator) MarkSucceeded(groupID string) {
+	tgc.mu.Lock()
+	defer tgc.mu.Unlock()
+	state, ok := tgc.groupStates[groupID]
+	if !ok {
+		return
+	}
+	state.hasSuccess = true
+	tgc.groupStates[groupID] = state
+}
+
+// failureCompleter is implemented by the payloads of the groups which are completed even if none of their tasks succeeded
+type failureCompleter interface {
+	completeOnFailure()
+}
+
+func completesOnFailure(payloads []any) bool {
+	for _, payload := range payloads {
+		if _, ok := payload.(failureCompleter); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkFailed records a failure of the group without finishing a task,
+// used when several transfers are run synchronously as one task
+func (tgc *TaskGroupCoordinator) MarkFailed(groupID string) {
+	tgc.mu.Lock()
+	defer tgc.mu.Unlock()
+	state, ok := tgc.groupStates[groupID]
+	if !ok {
+		return
+	}
+	state.hasFailure = true
+	tgc.groupStates[groupID] = state
+}
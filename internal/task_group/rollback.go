@@ -0,0 +1,86 @@
+package task_group
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// RollbackReport is the machine-readable state left by a failed transactional transfer,
+// all paths are full paths
+type RollbackReport struct {
+	DstPath     string   `json:"dst_path"`
+	Removed     []string `json:"removed"`
+	Overwritten []string `json:"overwritten"`
+	Failed      []string `json:"failed"`
+}
+
+// OnRollback receives the report of the rolled back group, the transactional tasks append it
+// to the payloads of their group so that their info shows the report
+type OnRollback func(report *RollbackReport)
+
+func isTransactional(payloads []any) bool {
+	for _, payload := range payloads {
+		if _, ok := payload.(Transactional); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// rollback removes the files created by the transfers of the group and then the dirs left empty.
+// The src files are removed only after the whole group succeeded, so removing the created files
+// moves the group back to its original state, except for the overwritten files.
+func rollback(ctx context.Context, dstStorage driver.Driver, dstPath string, payloads []any) {
+	mountPath := dstStorage.GetStorage().MountPath
+	report := RollbackReport{DstPath: dstPath}
+	var dirs []string
+	var receivers []OnRollback
+	for _, payload := range payloads {
+		switch p := payload.(type) {
+		case DstPathCreated:
+			fullPath := utils.GetFullPath(mountPath, string(p))
+			if err := op.Remove(ctx, dstStorage, string(p)); err != nil {
+				log.Errorf("failed rollback %s: %+v", fullPath, err)
+				report.Failed = append(report.Failed, fullPath)
+				continue
+			}
+			report.Removed = append(report.Removed, fullPath)
+		case DstPathOverwritten:
+			report.Overwritten = append(report.Overwritten, utils.GetFullPath(mountPath, string(p)))
+		case DstPathToHook:
+			dirs = append(dirs, string(p))
+		case OnRollback:
+			receivers = append(receivers, p)
+		}
+	}
+	// remove the deepest dirs first, so that the parents can be empty
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+	for _, dir := range dirs {
+		objs, err := op.List(ctx, dstStorage, dir, model.ListArgs{Refresh: true, SkipHook: true})
+		if err != nil || len(objs) > 0 {
+			continue
+		}
+		fullPath := utils.GetFullPath(mountPath, dir)
+		if err = op.Remove(ctx, dstStorage, dir); err != nil {
+			log.Errorf("failed rollback %s: %+v", fullPath, err)
+			report.Failed = append(report.Failed, fullPath)
+			continue
+		}
+		report.Removed = append(report.Removed, fullPath)
+	}
+	data, _ := utils.Json.MarshalToString(report)
+	log.Warnf("transactional transfer to [%s] failed, rolled back: %s", path.Clean(dstPath), data)
+	for _, receive := range receivers {
+		receive(&report)
+	}
+}
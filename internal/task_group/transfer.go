@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
@@ -11,6 +12,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
@@ -21,12 +23,44 @@ type SrcPathToRemove string
 // ActualPath
 type DstPathToHook string
 
-func HookAndRemove(ctx context.Context, dstPath string, payloads ...any) {
+// Transactional marks a group whose transfers are rolled back if any of them fails
+type Transactional struct{}
+
+// the group is rolled back even if none of its transfers succeeded, e.g. a dir task failing after a part of its files
+func (Transactional) completeOnFailure() {}
+
+// ActualPath of a file created by a transactional transfer
+type DstPathCreated string
+
+// ActualPath of a file replaced by a transactional transfer, which can't be rolled back
+type DstPathOverwritten string
+
+// TransferGroupID returns the ID of a new group of the transfers to dstPath,
+// so that the batches to the same dst dir, e.g. a plain and a transactional move, are not mixed up
+func TransferGroupID(dstPath string) string {
+	return random.String(8) + ":" + dstPath
+}
+
+// groupDstPath returns the dst path of a group, whose ID is either the path or a TransferGroupID
+func groupDstPath(groupID string) string {
+	if strings.HasPrefix(groupID, "/") {
+		return groupID
+	}
+	_, dstPath, _ := strings.Cut(groupID, ":")
+	return dstPath
+}
+
+func HookAndRemove(ctx context.Context, groupID string, hasFailure bool, payloads ...any) {
+	dstPath := groupDstPath(groupID)
 	dstStorage, dstActualPath, err := op.GetStorageAndActualPath(dstPath)
 	if err != nil {
 		log.Error(errors.WithMessage(err, "failed get dst storage"))
 		return
 	}
+	if hasFailure && isTransactional(payloads) {
+		rollback(ctx, dstStorage, dstPath, payloads)
+		return
+	}
 	dstNeedHandleHook := setting.GetBool(conf.HandleHookAfterWriting)
 	dstHandleHookLimit := setting.GetFloat(conf.HandleHookRateLimit, .0)
 	var listLimiter *rate.Limiter
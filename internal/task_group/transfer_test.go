@@ -0,0 +1,18 @@
+package task_group
+
+import "testing"
+
+func TestTransferGroupID(t *testing.T) {
+	for _, dstPath := range []string{"/", "/local/a#b", "/local/a:b"} {
+		a, b := TransferGroupID(dstPath), TransferGroupID(dstPath)
+		if a == b {
+			t.Errorf("the groups to %s share the ID %s", dstPath, a)
+		}
+		if got := groupDstPath(a); got != dstPath {
+			t.Errorf("got the dst path %s of %s, want %s", got, a, dstPath)
+		}
+		if got := groupDstPath(dstPath); got != dstPath {
+			t.Errorf("got the dst path %s of the path group %s", got, dstPath)
+		}
+	}
+}
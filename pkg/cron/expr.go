@@ -0,0 +1,127 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr is a parsed cron expression of the standard five fields:
+// minute, hour, day of month, month and day of week (0 or 7 is Sunday).
+// The fields accept *, lists, ranges and steps, e.g. */15 or 1-5,
+// and the macros @hourly, @daily, @weekly, @monthly and @yearly are accepted too
+type Expr struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set if the fields are *, when both are restricted either matches
+	domAny, dowAny bool
+}
+
+var macros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+func ParseExpr(s string) (*Expr, error) {
+	s = strings.TrimSpace(s)
+	if m, ok := macros[s]; ok {
+		s = m
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression needs 5 fields, got %d", len(fields))
+	}
+	e := &Expr{domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	var err error
+	bounds := []struct {
+		dst      *uint64
+		min, max int
+	}{{&e.minute, 0, 59}, {&e.hour, 0, 23}, {&e.dom, 1, 31}, {&e.month, 1, 12}, {&e.dow, 0, 7}}
+	for i, b := range bounds {
+		if *b.dst, err = parseField(fields[i], b.min, b.max); err != nil {
+			return nil, fmt.Errorf("invalid field %q: %w", fields[i], err)
+		}
+	}
+	// 7 is Sunday too
+	if e.dow&(1<<7) != 0 {
+		e.dow |= 1
+	}
+	return e, nil
+}
+
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("invalid value %q", loStr)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("invalid value %q", hiStr)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (e *Expr) matchDay(t time.Time) bool {
+	dom, dow := e.dom&(1<<t.Day()) != 0, e.dow&(1<<int(t.Weekday())) != 0
+	if e.domAny || e.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first time matching the expression after t, in the location of t,
+// or the zero time if there is none in the next 5 years
+func (e *Expr) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		if e.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !e.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if e.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if e.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,39 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExprNext(t *testing.T) {
+	from := time.Date(2026, 1, 30, 10, 7, 30, 0, time.UTC) // a Friday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 1, 30, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 1, 31, 3, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2026, 2, 2, 9, 30, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 3,9 *", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		// either the day of month or the day of week
+		{"0 12 1 * 6", time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		e, err := ParseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseExpr(%q): %v", tt.expr, err)
+		}
+		if got := e.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+	for _, expr := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseExpr(expr); err == nil {
+			t.Errorf("ParseExpr(%q) should fail", expr)
+		}
+	}
+}
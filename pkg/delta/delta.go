@@ -0,0 +1,230 @@
+// Package delta finds the blocks of a new version of a file which the old version already has, like rsync,
+// so that only the changed parts of the new version are transferred
+package delta
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// Block is the signature of a block of a file, the last block may be shorter than the block size
+type Block struct {
+	// Weak is the rolling checksum of the block
+	Weak uint32 `json:"weak"`
+	// Strong is the hex md5 of the block
+	Strong string `json:"strong"`
+}
+
+// Op is a part of the new file, which is copied from the old file or read from the new data
+type Op struct {
+	// Offset in the old file, -1 for the new data
+	Offset int64
+	Length int64
+}
+
+// Range is a range of the new file
+type Range struct {
+	Start  int64 `json:"start"`
+	Length int64 `json:"length"`
+}
+
+// weak is the checksum of rsync, a and b are kept mod 2^32 and only their low 16 bits are used
+func weak(a, b uint32) uint32 {
+	return a&0xffff | (b&0xffff)<<16
+}
+
+func strong(p []byte) string {
+	sum := md5.Sum(p)
+	return hex.EncodeToString(sum[:])
+}
+
+// BlockSignature returns the signature of the block p
+func BlockSignature(p []byte) Block {
+	var a, b uint32
+	for _, c := range p {
+		a += uint32(c)
+		b += a
+	}
+	return Block{Weak: weak(a, b), Strong: strong(p)}
+}
+
+// Signature returns the signatures of the blocks of r
+func Signature(r io.Reader, blockSize int) ([]Block, error) {
+	var blocks []Block
+	buf := make([]byte, blockSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			blocks = append(blocks, BlockSignature(buf[:n]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Match scans the old file for the blocks of the new file of size, and returns the offset in the old file
+// of each block, -1 for the blocks it doesn't have. The last block is only matched with the end of the
+// old file if it's shorter than the block size
+func Match(old io.Reader, blockSize int, size int64, blocks []Block) ([]int64, error) {
+	if blockSize <= 0 || int64(len(blocks)) != (size+int64(blockSize)-1)/int64(blockSize) {
+		return nil, errors.New("the number of the blocks doesn't match the size")
+	}
+	matches := make([]int64, len(blocks))
+	for i := range matches {
+		matches[i] = -1
+	}
+	if len(blocks) == 0 {
+		return matches, nil
+	}
+	full := len(blocks)
+	tailLen := size - int64(len(blocks)-1)*int64(blockSize)
+	if tailLen < int64(blockSize) {
+		full--
+	}
+	index := make(map[uint32][]int, full)
+	for i := 0; i < full; i++ {
+		index[blocks[i].Weak] = append(index[blocks[i].Weak], i)
+	}
+	unmatched := full
+
+	r := bufio.NewReaderSize(old, 64*1024)
+	// the window is a ring starting at start, pos is its offset in the old file
+	window := make([]byte, blockSize)
+	ordered := make([]byte, blockSize)
+	var start, filled int
+	var pos, total int64
+	var a, b uint32
+	// the last tailLen bytes of the old file, for the short last block
+	var tail []byte
+	if full < len(blocks) {
+		tail = make([]byte, tailLen)
+	}
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if tail != nil {
+			tail[total%int64(tailLen)] = c
+		}
+		total++
+		if unmatched == 0 {
+			continue
+		}
+		if filled < blockSize {
+			window[(start+filled)%blockSize] = c
+			filled++
+			a += uint32(c)
+			b += a
+		} else {
+			out := uint32(window[start])
+			window[start] = c
+			start = (start + 1) % blockSize
+			pos++
+			a = a - out + uint32(c)
+			b = b - uint32(blockSize)*out + a
+		}
+		if filled < blockSize {
+			continue
+		}
+		idxs, ok := index[weak(a, b)]
+		if !ok {
+			continue
+		}
+		copy(ordered, window[start:])
+		copy(ordered[blockSize-start:], window[:start])
+		sum := strong(ordered)
+		found := false
+		for _, i := range idxs {
+			if blocks[i].Strong == sum {
+				found = true
+				if matches[i] < 0 {
+					matches[i] = pos
+					unmatched--
+				}
+			}
+		}
+		if found {
+			// the matched block is skipped over
+			pos += int64(blockSize)
+			start, filled, a, b = 0, 0, 0, 0
+		}
+	}
+	if tail != nil && total >= int64(tailLen) {
+		// the ring starts at the oldest byte
+		i := int(total % int64(tailLen))
+		p := append(append(make([]byte, 0, tailLen), tail[i:]...), tail[:i]...)
+		if s := BlockSignature(p); s == blocks[len(blocks)-1] {
+			matches[len(blocks)-1] = total - int64(tailLen)
+		}
+	}
+	return matches, nil
+}
+
+// Ops returns the ops making the new file of size by the matches of its blocks, the adjacent ones merged
+func Ops(matches []int64, blockSize int, size int64) []Op {
+	var ops []Op
+	for i, m := range matches {
+		length := int64(blockSize)
+		if rest := size - int64(i)*int64(blockSize); rest < length {
+			length = rest
+		}
+		if n := len(ops); n > 0 {
+			last := &ops[n-1]
+			if (m < 0 && last.Offset < 0) || (m >= 0 && last.Offset >= 0 && last.Offset+last.Length == m) {
+				last.Length += length
+				continue
+			}
+		}
+		ops = append(ops, Op{Offset: m, Length: length})
+	}
+	return ops
+}
+
+// Missing returns the ranges of the new file read from the new data by the ops
+func Missing(ops []Op) []Range {
+	var ranges []Range
+	var pos int64
+	for _, op := range ops {
+		if op.Offset < 0 {
+			ranges = append(ranges, Range{Start: pos, Length: op.Length})
+		}
+		pos += op.Length
+	}
+	return ranges
+}
+
+// DataSize returns the size of the new data of the ops
+func DataSize(ops []Op) int64 {
+	var size int64
+	for _, op := range ops {
+		if op.Offset < 0 {
+			size += op.Length
+		}
+	}
+	return size
+}
+
+// Apply writes the new file to w by the ops, the missing ranges of the new file are read from data in order
+func Apply(w io.Writer, old io.ReaderAt, ops []Op, data io.Reader) error {
+	for _, op := range ops {
+		var r io.Reader = data
+		if op.Offset >= 0 {
+			r = io.NewSectionReader(old, op.Offset, op.Length)
+		}
+		if _, err := io.CopyN(w, r, op.Length); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package delta
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func roundTrip(t *testing.T, old, new []byte, blockSize int) []Range {
+	t.Helper()
+	blocks, err := Signature(bytes.NewReader(new), blockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := Match(bytes.NewReader(old), blockSize, int64(len(new)), blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := Ops(matches, blockSize, int64(len(new)))
+	missing := Missing(ops)
+	var data bytes.Buffer
+	for _, r := range missing {
+		data.Write(new[r.Start : r.Start+r.Length])
+	}
+	if int64(data.Len()) != DataSize(ops) {
+		t.Fatalf("data size %d, expect %d", data.Len(), DataSize(ops))
+	}
+	var out bytes.Buffer
+	if err = Apply(&out, bytes.NewReader(old), ops, &data); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), new) {
+		t.Fatal("the applied file differs from the new one")
+	}
+	return missing
+}
+
+func missingSize(ranges []Range) int64 {
+	var size int64
+	for _, r := range ranges {
+		size += r.Length
+	}
+	return size
+}
+
+func TestDelta(t *testing.T) {
+	const blockSize = 64
+	rnd := rand.New(rand.NewSource(1))
+	old := make([]byte, 100*blockSize+10)
+	rnd.Read(old)
+
+	if m := roundTrip(t, old, old, blockSize); len(m) != 0 {
+		t.Errorf("the same file needs %v", m)
+	}
+
+	edited := bytes.Clone(old)
+	edited[50*blockSize+3] ^= 0xff
+	if m := roundTrip(t, old, edited, blockSize); missingSize(m) != blockSize {
+		t.Errorf("an edited byte needs %v", m)
+	}
+
+	// the blocks after the inserted bytes are found at their shifted offsets
+	inserted := append(append(bytes.Clone(old[:30*blockSize+7]), []byte("inserted")...), old[30*blockSize+7:]...)
+	if m := roundTrip(t, old, inserted, blockSize); missingSize(m) > 3*blockSize {
+		t.Errorf("the inserted bytes need %v", m)
+	}
+
+	appended := append(bytes.Clone(old), []byte("appended")...)
+	if m := roundTrip(t, old, appended, blockSize); missingSize(m) > 2*blockSize {
+		t.Errorf("the appended bytes need %v", m)
+	}
+
+	roundTrip(t, old, nil, blockSize)
+	roundTrip(t, nil, old, blockSize)
+	roundTrip(t, old, old[:blockSize/2], blockSize)
+}
+
+func TestMatchSize(t *testing.T) {
+	blocks, _ := Signature(bytes.NewReader(make([]byte, 100)), 64)
+	if _, err := Match(bytes.NewReader(nil), 64, 200, blocks); err == nil {
+		t.Error("expect an error of the blocks not matching the size")
+	}
+}
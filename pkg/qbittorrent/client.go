@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
@@ -18,6 +19,7 @@ type Client interface {
 	GetInfo(id string) (TorrentInfo, error)
 	GetFiles(id string) ([]FileInfo, error)
 	Delete(id string, deleteFiles bool) error
+	SetDownloadLimit(limit int64) error
 }
 
 type client struct {
@@ -384,3 +386,22 @@ func (c *client) Delete(id string, deleteFiles bool) error {
 	}
 	return nil
 }
+
+// SetDownloadLimit sets the global download speed in bytes per second, 0 for unlimited
+func (c *client) SetDownloadLimit(limit int64) error {
+	err := c.checkAuthorization()
+	if err != nil {
+		return err
+	}
+	v := url.Values{}
+	v.Set("limit", strconv.FormatInt(limit, 10))
+	resp, err := c.post("/api/v2/transfer/setDownloadLimit", v)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return errors.New("failed to set qbittorrent download limit")
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package utils
 import (
 	"fmt"
 	"io"
-	"mime"
 	"os"
 	"path"
 	"path/filepath"
@@ -117,7 +116,7 @@ func CreateTempFile(r io.Reader, size int64) (*os.File, error) {
 	if f, ok := r.(*os.File); ok {
 		return f, nil
 	}
-	f, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	f, err := CreateTemp("file-*", size)
 	if err != nil {
 		return nil, err
 	}
@@ -163,22 +162,6 @@ func GetObjType(filename string, isDir bool) int {
 	return GetFileType(filename)
 }
 
-var extraMimeTypes = map[string]string{
-	".apk": "application/vnd.android.package-archive",
-}
-
-func GetMimeType(name string) string {
-	ext := path.Ext(name)
-	if m, ok := extraMimeTypes[ext]; ok {
-		return m
-	}
-	m := mime.TypeByExtension(ext)
-	if m != "" {
-		return m
-	}
-	return "application/octet-stream"
-}
-
 const (
 	KB = 1 << (10 * (iota + 1))
 	MB
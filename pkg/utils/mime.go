@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+const defaultMimeType = "application/octet-stream"
+
+var extraMimeTypes = map[string]string{
+	".apk": "application/vnd.android.package-archive",
+}
+
+// mimeTypeOverrides are the mime types of the extensions set by the mime_types setting
+var mimeTypeOverrides atomic.Pointer[map[string]string]
+
+// SetMimeTypeOverrides sets the mime types of the extensions, which win over the builtin ones.
+// The extensions are lowercase with the leading dot
+func SetMimeTypeOverrides(types map[string]string) {
+	mimeTypeOverrides.Store(&types)
+}
+
+// MimeTypeOverride returns the mime type of the extension of name set by SetMimeTypeOverrides
+func MimeTypeOverride(name string) (string, bool) {
+	types := mimeTypeOverrides.Load()
+	if types == nil {
+		return "", false
+	}
+	m, ok := (*types)[strings.ToLower(path.Ext(name))]
+	return m, ok
+}
+
+// mimeTypeByName returns the mime type of the extension of name, empty if it's unknown
+func mimeTypeByName(name string) string {
+	if m, ok := MimeTypeOverride(name); ok {
+		return m
+	}
+	ext := strings.ToLower(path.Ext(name))
+	if m, ok := extraMimeTypes[ext]; ok {
+		return m
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// GetMimeType returns the mime type of the extension of name, application/octet-stream if it's unknown
+func GetMimeType(name string) string {
+	if m := mimeTypeByName(name); m != "" {
+		return m
+	}
+	return defaultMimeType
+}
+
+// IsMimeTypeKnown tells if the mime type of name is known by its extension, otherwise it can be sniffed by DetectMimeType
+func IsMimeTypeKnown(name string) bool {
+	return mimeTypeByName(name) != ""
+}
+
+// DetectMimeType returns the mime type of the extension of name, or sniffs it from head,
+// the first bytes of the content, if the extension is unknown
+func DetectMimeType(name string, head []byte) string {
+	if m := mimeTypeByName(name); m != "" {
+		return m
+	}
+	if len(head) == 0 {
+		return defaultMimeType
+	}
+	return http.DetectContentType(head)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import "testing"
+
+func TestDetectMimeType(t *testing.T) {
+	defer SetMimeTypeOverrides(nil)
+	SetMimeTypeOverrides(map[string]string{".md": "text/markdown; charset=utf-8", ".zip": "application/x-zip"})
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"README.MD", "", "text/markdown; charset=utf-8"},
+		{"a.zip", "PK\x03\x04", "application/x-zip"},
+		{"app.apk", "PK\x03\x04", "application/vnd.android.package-archive"},
+		{"a.png", "", "image/png"},
+		{"noext", "%PDF-1.7", "application/pdf"},
+		{"noext", "", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		if got := DetectMimeType(tt.name, []byte(tt.head)); got != tt.want {
+			t.Errorf("DetectMimeType(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
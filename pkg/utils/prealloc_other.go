@@ -0,0 +1,10 @@
+//go:build !linux
+
+package utils
+
+import "os"
+
+// Preallocate is a no-op on platforms without fallocate
+func Preallocate(_ *os.File, _ int64) error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+)
+
+// SparseBlockSize is the granularity of the holes made by SparseWriter
+const SparseBlockSize = 4 * KB
+
+var zeroBlock = make([]byte, SparseBlockSize)
+
+// SparseFile is a file whose holes can be made by seeking over them
+type SparseFile interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+}
+
+// SparseWriter skips the blocks full of zeros instead of writing them,
+// so they become holes in the file if the file system supports it
+type SparseWriter struct {
+	f       SparseFile
+	offset  int64
+	skipped bool
+}
+
+func NewSparseWriter(f SparseFile) *SparseWriter {
+	return &SparseWriter{f: f}
+}
+
+func (w *SparseWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// align the chunks to the blocks of the file
+		n := min(int(SparseBlockSize-w.offset%SparseBlockSize), len(p))
+		chunk := p[:n]
+		if bytes.Equal(chunk, zeroBlock[:n]) {
+			w.skipped = true
+		} else {
+			if w.skipped {
+				if _, err := w.f.Seek(w.offset, io.SeekStart); err != nil {
+					return written, err
+				}
+				w.skipped = false
+			}
+			if _, err := w.f.Write(chunk); err != nil {
+				return written, err
+			}
+		}
+		w.offset += int64(n)
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Close sets the size of the file in case it ends with a hole, it doesn't close the file
+func (w *SparseWriter) Close() error {
+	if !w.skipped {
+		return nil
+	}
+	return w.f.Truncate(w.offset)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSparseWriter(t *testing.T) {
+	data := make([]byte, 5*SparseBlockSize+100)
+	copy(data[SparseBlockSize+10:], "head")
+	copy(data[3*SparseBlockSize:], "middle")
+	for _, tail := range []bool{false, true} {
+		if tail {
+			data[len(data)-1] = 1
+		}
+		f, err := os.Create(filepath.Join(t.TempDir(), "sparse"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := NewSparseWriter(f)
+		// write in chunks not aligned to the blocks
+		for i := 0; i < len(data); i += 1000 {
+			if _, err = w.Write(data[i:min(i+1000, len(data))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		_ = f.Close()
+		got, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("content mismatch with tail %v: got %d bytes, want %d bytes", tail, len(got), len(data))
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"math"
+	"os"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	log "github.com/sirupsen/logrus"
+)
+
+// TempDirs returns the temp dir and the extra temp dirs
+func TempDirs() []conf.TempDir {
+	dirs := make([]conf.TempDir, 0, len(conf.Conf.ExtraTempDirs)+1)
+	dirs = append(dirs, conf.TempDir{Path: conf.Conf.TempDir, MinFreeMB: conf.Conf.TempDirMinFreeMB})
+	for _, dir := range conf.Conf.ExtraTempDirs {
+		if dir.Path == "" {
+			continue
+		}
+		if dir.MinFreeMB == 0 {
+			dir.MinFreeMB = conf.Conf.TempDirMinFreeMB
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// PickTempDir returns the temp dir with the most free space above its watermark,
+// size is the expected size of the temp file, 0 if unknown
+func PickTempDir(size int64) (string, error) {
+	dirs := TempDirs()
+	if len(dirs) == 1 && dirs[0].MinFreeMB <= 0 {
+		return dirs[0].Path, nil
+	}
+	best, bestAvail := "", int64(math.MinInt64)
+	for _, dir := range dirs {
+		free, err := diskFree(dir.Path)
+		if err != nil {
+			log.Warnf("failed get free space of temp dir %s: %+v", dir.Path, err)
+			continue
+		}
+		avail := free - dir.MinFreeMB*int64(MB)
+		if best == "" || avail > bestAvail {
+			best, bestAvail = dir.Path, avail
+		}
+	}
+	if best == "" {
+		// can't stat any of them, don't block the caching
+		return conf.Conf.TempDir, nil
+	}
+	if bestAvail <= 0 || bestAvail < size {
+		return "", errs.NewErr(errs.NoTempSpace, "need %d bytes, at most %d bytes available", size, max(bestAvail, 0))
+	}
+	return best, nil
+}
+
+// CreateTemp creates a temp file like os.CreateTemp in the temp dir picked by PickTempDir
+func CreateTemp(pattern string, size int64) (*os.File, error) {
+	dir, err := PickTempDir(size)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if err = Preallocate(f, size); err != nil {
+		// not supported by the file system, it's just slower
+		log.Debugf("failed preallocate temp file %s: %+v", f.Name(), err)
+	}
+	return f, nil
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+)
+
+func TestPickTempDir(t *testing.T) {
+	old := conf.Conf
+	defer func() { conf.Conf = old }()
+	tempDir, extraDir := t.TempDir(), t.TempDir()
+
+	conf.Conf = &conf.Config{TempDir: tempDir}
+	if dir, err := PickTempDir(0); err != nil || dir != tempDir {
+		t.Errorf("PickTempDir() = %q, %v, want %q", dir, err, tempDir)
+	}
+
+	// no disk has this much free space
+	conf.Conf = &conf.Config{TempDir: tempDir, TempDirMinFreeMB: 1 << 40}
+	if _, err := PickTempDir(0); !errors.Is(err, errs.NoTempSpace) {
+		t.Errorf("PickTempDir() error = %v, want %v", err, errs.NoTempSpace)
+	}
+
+	conf.Conf = &conf.Config{
+		TempDir:          tempDir,
+		TempDirMinFreeMB: 1 << 40,
+		ExtraTempDirs:    []conf.TempDir{{Path: extraDir, MinFreeMB: 1}},
+	}
+	if dir, err := PickTempDir(1024); err != nil || dir != extraDir {
+		t.Errorf("PickTempDir() = %q, %v, want %q", dir, err, extraDir)
+	}
+	if _, err := PickTempDir(1 << 62); !errors.Is(err, errs.NoTempSpace) {
+		t.Errorf("PickTempDir() error = %v, want %v", err, errs.NoTempSpace)
+	}
+}
@@ -1,16 +1,16 @@
 package common
 
 import (
+	"context"
 	"path"
 	"slices"
-	"strings"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
-	"github.com/dlclark/regexp2"
+	log "github.com/sirupsen/logrus"
 )
 
 func IsStorageSignEnabled(rawPath string) bool {
@@ -23,7 +23,11 @@ func CanRead(user *model.User, meta *model.Meta, path string) bool {
 	if user == nil {
 		return true
 	}
-	if meta != nil && len(meta.ReadUsers) > 0 && !slices.Contains(meta.ReadUsers, user.ID) && MetaCoversPath(meta.Path, path, meta.ReadUsersSub) {
+	if !canAccessStorageGroup(user, path) {
+		return false
+	}
+	if meta != nil && len(meta.ReadUsers) > 0 && !slices.Contains(meta.ReadUsers, user.ID) && MetaCoversPath(meta.Path, path, meta.ReadUsersSub) &&
+		!user.HasPathGrant(path, false) {
 		return false
 	}
 	return true
@@ -34,12 +38,24 @@ func CanWrite(user *model.User, meta *model.Meta, path string) bool {
 	if user == nil {
 		return true
 	}
-	if meta != nil && len(meta.WriteUsers) > 0 && !slices.Contains(meta.WriteUsers, user.ID) && MetaCoversPath(meta.Path, path, meta.WriteUsersSub) {
+	if !canAccessStorageGroup(user, path) {
+		return false
+	}
+	if meta != nil && len(meta.WriteUsers) > 0 && !slices.Contains(meta.WriteUsers, user.ID) && MetaCoversPath(meta.Path, path, meta.WriteUsersSub) &&
+		!user.HasPathGrant(path, true) {
 		return false
 	}
 	return true
 }
 
+// canAccessStorageGroup reports whether the role of the user is assigned the storage group the path is in
+func canAccessStorageGroup(user *model.User, path string) bool {
+	if _, ok := model.RoleStorageGroups[user.Role]; !ok || user.IsAdmin() {
+		return true
+	}
+	return user.CanAccessStorageGroup(op.GetStorageGroupOfPath(path))
+}
+
 func CanWriteContentBypassUserPerms(meta *model.Meta, path string) bool {
 	if meta == nil || !meta.Write {
 		return false
@@ -48,15 +64,15 @@ func CanWriteContentBypassUserPerms(meta *model.Meta, path string) bool {
 }
 
 func CanAccess(user *model.User, meta *model.Meta, reqPath string, password string) bool {
+	return CanAccessVia(user, meta, reqPath, password, model.ProtocolWeb)
+}
+
+// CanAccessVia is CanAccess for the requests via protocol, which the hide rules may be scoped to
+func CanAccessVia(user *model.User, meta *model.Meta, reqPath string, password string, protocol string) bool {
 	// if the reqPath is in hide (only can check the nearest meta) and user can't see hides, can't access
-	if meta != nil && !user.CanSeeHides() && meta.Hide != "" &&
-		MetaCoversPath(meta.Path, path.Dir(reqPath), meta.HSub) { // the meta should apply to the parent of current path
-		for _, hide := range strings.Split(meta.Hide, "\n") {
-			re := regexp2.MustCompile(hide, regexp2.None)
-			if isMatch, _ := re.MatchString(path.Base(reqPath)); isMatch {
-				return false
-			}
-		}
+	// the meta should apply to the parent of current path
+	if rule := HideRule(user, meta, path.Dir(reqPath), protocol); rule != nil && rule.Hides(path.Base(reqPath)) {
+		return false
 	}
 	if !CanRead(user, meta, reqPath) {
 		return false
@@ -65,18 +81,65 @@ func CanAccess(user *model.User, meta *model.Meta, reqPath string, password stri
 	if user.CanAccessWithoutPassword() {
 		return true
 	}
-	// if meta is nil or password is empty, can access
-	if meta == nil || meta.Password == "" {
+	// if no password applies to the path, can access
+	meta = PasswordMeta(meta, reqPath)
+	if meta == nil {
 		return true
 	}
-	// if meta doesn't apply to sub_folder, can access
-	if !MetaCoversPath(meta.Path, reqPath, meta.PSub) {
+	if slices.Contains(meta.PExemptUsers, user.ID) || slices.Contains(meta.PExemptRoles, user.Role) {
 		return true
 	}
 	// validate password
 	return meta.Password == password
 }
 
+// HideRule returns the hide rule of meta which applies to the objs in dirPath, nil if no rule applies
+func HideRule(user *model.User, meta *model.Meta, dirPath string, protocol string) *model.HideRule {
+	if meta == nil || meta.Hide == "" || !MetaCoversPath(meta.Path, dirPath, meta.HSub) {
+		return nil
+	}
+	rule, err := meta.HideRule()
+	if err != nil {
+		log.Warnf("invalid hide rule of meta [%s]: %+v", meta.Path, err)
+		return nil
+	}
+	if !rule.AppliesTo(user, protocol) {
+		return nil
+	}
+	return rule
+}
+
+// Protocol returns the protocol of the request carried by ctx, web by default
+func Protocol(ctx context.Context) string {
+	if p, ok := ctx.Value(conf.ProtocolKey).(string); ok && p != "" {
+		return p
+	}
+	return model.ProtocolWeb
+}
+
+// PasswordMeta returns the meta whose password applies to reqPath, nil if no password applies.
+// meta is the nearest meta of reqPath, if it has no password but inherits the password,
+// the nearest meta of its parent is checked instead
+func PasswordMeta(meta *model.Meta, reqPath string) *model.Meta {
+	for meta != nil {
+		if meta.Password != "" {
+			if MetaCoversPath(meta.Path, reqPath, meta.PSub) {
+				return meta
+			}
+			return nil
+		}
+		if !meta.PInherit || utils.PathEqual(meta.Path, "/") {
+			return nil
+		}
+		parent, err := op.GetNearestMeta(path.Dir(meta.Path))
+		if err != nil {
+			return nil
+		}
+		meta = parent
+	}
+	return nil
+}
+
 func MetaCoversPath(metaPath, reqPath string, applyToSubFolder bool) bool {
 	if utils.PathEqual(metaPath, reqPath) {
 		return true
@@ -98,3 +161,15 @@ func ShouldProxy(storage driver.Driver, filename string) bool {
 	}
 	return false
 }
+
+// ShouldProxyFor is ShouldProxy for the download of the client, the first matching proxy rule of the storage
+// decides between the redirect and the proxy, size is only called if a rule is limited by the size
+func ShouldProxyFor(storage driver.Driver, filename, clientIP string, size func() int64) bool {
+	if storage.Config().MustProxy() {
+		return true
+	}
+	if proxy, ok := storage.GetStorage().MatchProxyRule(clientIP, filename, size); ok {
+		return proxy
+	}
+	return ShouldProxy(storage, filename)
+}
@@ -2,10 +2,25 @@ package common
 
 import (
 	"testing"
+	"time"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file:common_test?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig("data")
+	db.Init(dB)
+}
+
 func TestCoversPath(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -495,6 +510,65 @@ func TestCanWrite(t *testing.T) {
 	}
 }
 
+func TestCanReadWriteWithPathGrant(t *testing.T) {
+	meta := &model.Meta{
+		Path:          "/private",
+		ReadUsers:     []uint{2},
+		ReadUsersSub:  true,
+		WriteUsers:    []uint{2},
+		WriteUsersSub: true,
+	}
+	now := time.Now()
+	tests := []struct {
+		name      string
+		grant     model.AccessGrant
+		wantRead  bool
+		wantWrite bool
+	}{
+		{
+			name:      "read grant on the path",
+			grant:     model.AccessGrant{Path: "/private", Expires: now.Add(time.Hour)},
+			wantRead:  true,
+			wantWrite: false,
+		},
+		{
+			name:      "write grant on a parent path",
+			grant:     model.AccessGrant{Path: "/", Write: true, Expires: now.Add(time.Hour)},
+			wantRead:  true,
+			wantWrite: true,
+		},
+		{
+			name:      "grant on another path",
+			grant:     model.AccessGrant{Path: "/public", Write: true, Expires: now.Add(time.Hour)},
+			wantRead:  false,
+			wantWrite: false,
+		},
+		{
+			name:      "expired grant",
+			grant:     model.AccessGrant{Path: "/private", Write: true, Expires: now.Add(-time.Hour)},
+			wantRead:  false,
+			wantWrite: false,
+		},
+		{
+			name:      "revoked grant",
+			grant:     model.AccessGrant{Path: "/private", Write: true, Expires: now.Add(time.Hour), Revoked: &now},
+			wantRead:  false,
+			wantWrite: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &model.User{ID: 1, Grants: []model.AccessGrant{tt.grant}}
+			if got := CanRead(user, meta, "/private/file"); got != tt.wantRead {
+				t.Errorf("CanRead() = %v, want %v", got, tt.wantRead)
+			}
+			if got := CanWrite(user, meta, "/private/file"); got != tt.wantWrite {
+				t.Errorf("CanWrite() = %v, want %v", got, tt.wantWrite)
+			}
+		})
+	}
+}
+
 func TestCanAccessWithReadPermissions(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -579,6 +653,60 @@ func TestCanAccessWithReadPermissions(t *testing.T) {
 			want:     false,
 			reason:   "user not in ReadUsers list should be denied",
 		},
+		{
+			name: "user exempt from password",
+			user: &model.User{
+				ID:         5,
+				Role:       model.GENERAL,
+				Permission: 0,
+			},
+			meta: &model.Meta{
+				Path:         "/folder",
+				Password:     "secret",
+				PSub:         true,
+				PExemptUsers: []uint{5},
+			},
+			reqPath:  "/folder/file.txt",
+			password: "",
+			want:     true,
+			reason:   "user in PExemptUsers list doesn't need the password",
+		},
+		{
+			name: "role exempt from password",
+			user: &model.User{
+				ID:         5,
+				Role:       model.GENERAL,
+				Permission: 0,
+			},
+			meta: &model.Meta{
+				Path:         "/folder",
+				Password:     "secret",
+				PSub:         true,
+				PExemptRoles: []int{model.GENERAL},
+			},
+			reqPath:  "/folder/file.txt",
+			password: "",
+			want:     true,
+			reason:   "role in PExemptRoles list doesn't need the password",
+		},
+		{
+			name: "role not exempt from password",
+			user: &model.User{
+				ID:         5,
+				Role:       model.GUEST,
+				Permission: 0,
+			},
+			meta: &model.Meta{
+				Path:         "/folder",
+				Password:     "secret",
+				PSub:         true,
+				PExemptRoles: []int{model.GENERAL},
+			},
+			reqPath:  "/folder/file.txt",
+			password: "",
+			want:     false,
+			reason:   "role not in PExemptRoles list needs the password",
+		},
 	}
 
 	for _, tt := range tests {
@@ -592,6 +720,236 @@ func TestCanAccessWithReadPermissions(t *testing.T) {
 	}
 }
 
+func TestHideRule(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   *model.User
+		meta   *model.Meta
+		path   string
+		want   bool
+		reason string
+	}{
+		{
+			name: "nil user",
+			user: nil,
+			meta: &model.Meta{
+				Path: "/folder",
+				Hide: "secret",
+				HSub: true,
+			},
+			path:   "/folder",
+			want:   false,
+			reason: "nil user (treated as admin) should not hide",
+		},
+		{
+			name: "user with can_see_hides permission",
+			user: &model.User{
+				Role:       model.GENERAL,
+				Permission: 1, // bit 0 set = can see hides
+			},
+			meta: &model.Meta{
+				Path: "/folder",
+				Hide: "secret",
+				HSub: true,
+			},
+			path:   "/folder",
+			want:   false,
+			reason: "user with can_see_hides permission should not hide",
+		},
+		{
+			name: "nil meta",
+			user: &model.User{
+				Role: model.GUEST,
+			},
+			meta:   nil,
+			path:   "/folder",
+			want:   false,
+			reason: "nil meta should not hide",
+		},
+		{
+			name: "empty hide string",
+			user: &model.User{
+				Role: model.GUEST,
+			},
+			meta: &model.Meta{
+				Path: "/folder",
+				Hide: "",
+				HSub: true,
+			},
+			path:   "/folder",
+			want:   false,
+			reason: "empty hide string should not hide",
+		},
+		{
+			name: "exact path match with HSub=false",
+			user: &model.User{
+				Role: model.GUEST,
+			},
+			meta: &model.Meta{
+				Path: "/folder",
+				Hide: "secret",
+				HSub: false,
+			},
+			path:   "/folder",
+			want:   true,
+			reason: "exact path match should hide for guest",
+		},
+		{
+			name: "sub path with HSub=true",
+			user: &model.User{
+				Role: model.GUEST,
+			},
+			meta: &model.Meta{
+				Path: "/folder",
+				Hide: "secret",
+				HSub: true,
+			},
+			path:   "/folder/subfolder",
+			want:   true,
+			reason: "sub path with HSub=true should hide for guest",
+		},
+		{
+			name: "sub path with HSub=false",
+			user: &model.User{
+				Role: model.GUEST,
+			},
+			meta: &model.Meta{
+				Path: "/folder",
+				Hide: "secret",
+				HSub: false,
+			},
+			path:   "/folder/subfolder",
+			want:   false,
+			reason: "sub path with HSub=false should not hide",
+		},
+		{
+			name: "non-sub path with HSub=true",
+			user: &model.User{
+				Role: model.GUEST,
+			},
+			meta: &model.Meta{
+				Path: "/folder",
+				Hide: "secret",
+				HSub: true,
+			},
+			path:   "/other",
+			want:   false,
+			reason: "non-sub path should not hide even with HSub=true",
+		},
+		{
+			name: "user without can_see_hides permission",
+			user: &model.User{
+				Role:       model.GENERAL,
+				Permission: 0, // bit 0 not set = cannot see hides
+			},
+			meta: &model.Meta{
+				Path: "/folder",
+				Hide: "secret",
+				HSub: true,
+			},
+			path:   "/folder",
+			want:   true,
+			reason: "user without can_see_hides permission should hide",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HideRule(tt.user, tt.meta, tt.path, model.ProtocolWeb) != nil
+			if got != tt.want {
+				t.Errorf("HideRule() != nil = %v, want %v\nReason: %s",
+					got, tt.want, tt.reason)
+			}
+		})
+	}
+}
+
+func TestCanAccessWithHideRules(t *testing.T) {
+	user := &model.User{ID: 5, Role: model.GENERAL}
+	tests := []struct {
+		name     string
+		meta     *model.Meta
+		reqPath  string
+		protocol string
+		want     bool
+	}{
+		{
+			name:     "regex matches the name",
+			meta:     &model.Meta{Path: "/folder", Hide: `^\.`},
+			reqPath:  "/folder/.secret",
+			protocol: model.ProtocolWeb,
+			want:     false,
+		},
+		{
+			name:     "regex doesn't match the name",
+			meta:     &model.Meta{Path: "/folder", Hide: `^\.`},
+			reqPath:  "/folder/public",
+			protocol: model.ProtocolWeb,
+			want:     true,
+		},
+		{
+			name:     "hidden in sub folder with HSub",
+			meta:     &model.Meta{Path: "/folder", Hide: `\.bak$`, HSub: true},
+			reqPath:  "/folder/sub/a.bak",
+			protocol: model.ProtocolWeb,
+			want:     false,
+		},
+		{
+			name:     "not hidden in sub folder without HSub",
+			meta:     &model.Meta{Path: "/folder", Hide: `\.bak$`},
+			reqPath:  "/folder/sub/a.bak",
+			protocol: model.ProtocolWeb,
+			want:     true,
+		},
+		{
+			name:     "user exempt from the rule",
+			meta:     &model.Meta{Path: "/folder", Hide: `^\.`, HExemptUsers: []uint{5}},
+			reqPath:  "/folder/.secret",
+			protocol: model.ProtocolWeb,
+			want:     true,
+		},
+		{
+			name:     "hidden via the scoped protocol",
+			meta:     &model.Meta{Path: "/folder", Hide: `^\.`, HProtocols: []string{model.ProtocolWebdav}},
+			reqPath:  "/folder/.secret",
+			protocol: model.ProtocolWebdav,
+			want:     false,
+		},
+		{
+			name:     "shown via other protocols",
+			meta:     &model.Meta{Path: "/folder", Hide: `^\.`, HProtocols: []string{model.ProtocolWebdav}},
+			reqPath:  "/folder/.secret",
+			protocol: model.ProtocolWeb,
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CanAccessVia(user, tt.meta, tt.reqPath, "", tt.protocol)
+			if got != tt.want {
+				t.Errorf("CanAccessVia() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHideRule(t *testing.T) {
+	if _, err := model.NewHideRule("(", nil, nil); err == nil {
+		t.Error("expected an error for an illegal regexp")
+	}
+	if _, err := model.NewHideRule("a", nil, []string{"gopher"}); err == nil {
+		t.Error("expected an error for an unknown protocol")
+	}
+	rule, err := model.NewHideRule("a\n\nb", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.Hides("c") {
+		t.Error("empty lines shouldn't hide everything")
+	}
+}
+
 // Helper function to safely get user ID
 func getUserID(user *model.User) uint {
 	if user == nil {
@@ -984,3 +1342,89 @@ func getMetaWriteUsersSub(meta *model.Meta) bool {
 	}
 	return meta.WriteUsersSub
 }
+
+func TestMatchProxyRule(t *testing.T) {
+	p := model.Proxy{ProxyRules: `[
+		{"action": "302", "cidrs": ["192.168.0.0/16", "10.0.0.0/8"]},
+		{"action": "proxy", "exts": [".mp4", "mkv"], "max_size": 1024},
+		{"action": "302", "min_size": 1025}
+	]`}
+	if err := p.LoadProxyRules(); err != nil {
+		t.Fatal(err)
+	}
+	size := func(s int64) func() int64 { return func() int64 { return s } }
+	tests := []struct {
+		name      string
+		ip        string
+		filename  string
+		size      int64
+		wantProxy bool
+		wantOk    bool
+	}{
+		{"lan", "192.168.1.2", "a.mp4", 10, false, true},
+		{"wan small video", "1.2.3.4", "a.MP4", 10, true, true},
+		{"wan large file", "1.2.3.4", "a.mkv", 2048, false, true},
+		{"wan small text", "1.2.3.4", "a.txt", 10, false, false},
+		{"unknown size", "1.2.3.4", "a.txt", -1, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy, ok := p.MatchProxyRule(tt.ip, tt.filename, size(tt.size))
+			if proxy != tt.wantProxy || ok != tt.wantOk {
+				t.Errorf("MatchProxyRule() = %v, %v, want %v, %v", proxy, ok, tt.wantProxy, tt.wantOk)
+			}
+		})
+	}
+	if _, err := model.ParseProxyRules(`[{"action": "redirect"}]`); err == nil {
+		t.Error("ParseProxyRules() accepted an invalid action")
+	}
+	if _, err := model.ParseProxyRules(`[{"action": "proxy", "cidrs": ["10.0.0.1"]}]`); err == nil {
+		t.Error("ParseProxyRules() accepted an invalid cidr")
+	}
+}
+
+func TestCanAccessWithInheritedPassword(t *testing.T) {
+	metas := []model.Meta{
+		{Path: "/inherit", Password: "parent", PSub: true},
+		{Path: "/inherit/child", PInherit: true},
+		{Path: "/inherit/child/grandchild", PInherit: true},
+		{Path: "/inherit/not_inherit"},
+		{Path: "/inherit/override", Password: "child", PSub: true, PInherit: true},
+		{Path: "/no_sub", Password: "parent"},
+		{Path: "/no_sub/child", PInherit: true},
+	}
+	for i := range metas {
+		if err := op.CreateMeta(&metas[i]); err != nil {
+			t.Fatalf("failed to create meta %s: %+v", metas[i].Path, err)
+		}
+	}
+	user := &model.User{ID: 1, Role: model.GENERAL}
+	tests := []struct {
+		name     string
+		reqPath  string
+		password string
+		want     bool
+	}{
+		{"inherited password without password", "/inherit/child/file.txt", "", false},
+		{"inherited password with the parent password", "/inherit/child/file.txt", "parent", true},
+		{"password inherited through the metas", "/inherit/child/grandchild/file.txt", "", false},
+		{"password inherited through the metas with the parent password", "/inherit/child/grandchild/file.txt", "parent", true},
+		{"sub path of the meta not inheriting", "/inherit/not_inherit/file.txt", "", true},
+		{"password of the parent not applying to the sub paths", "/no_sub/child/file.txt", "", true},
+		{"password of the parent on its own path", "/no_sub", "parent", true},
+		{"password of the parent on its own path without password", "/no_sub", "", false},
+		{"overriding meta with the parent password", "/inherit/override/file.txt", "parent", false},
+		{"overriding meta with its password", "/inherit/override/file.txt", "child", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := op.GetNearestMeta(tt.reqPath)
+			if err != nil {
+				t.Fatalf("failed to get the nearest meta of %s: %+v", tt.reqPath, err)
+			}
+			if got := CanAccess(user, meta, tt.reqPath, tt.password); got != tt.want {
+				t.Errorf("CanAccess(%q, %q) with meta %s = %v, want %v", tt.reqPath, tt.password, meta.Path, got, tt.want)
+			}
+		})
+	}
+}
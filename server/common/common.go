@@ -9,6 +9,8 @@ import (
 
 	"github.com/OpenListTeam/OpenList/v4/cmd/flags"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
@@ -77,29 +79,35 @@ func ErrorPage(c *gin.Context, err error, code int, l ...bool) {
 }
 
 func ErrorWithDataResp(c *gin.Context, err error, code int, data interface{}, l ...bool) {
+	id := net.RequestID(c.Request.Context())
 	if len(l) > 0 && l[0] {
 		if flags.Debug || flags.Dev {
-			log.Errorf("%+v", err)
+			log.WithField("request_id", id).Errorf("%+v", err)
 		} else {
-			log.Errorf("%v", err)
+			log.WithField("request_id", id).Errorf("%v", err)
 		}
 	}
 	c.JSON(200, Resp[interface{}]{
-		Code:    code,
-		Message: hidePrivacy(err.Error()),
-		Data:    data,
+		Code:      code,
+		Message:   hidePrivacy(err.Error()),
+		Data:      data,
+		ErrorCode: errs.Code(err, code),
+		RequestID: id,
 	})
 	c.Abort()
 }
 
 func ErrorStrResp(c *gin.Context, str string, code int, l ...bool) {
+	id := net.RequestID(c.Request.Context())
 	if len(l) != 0 && l[0] {
-		log.Error(str)
+		log.WithField("request_id", id).Error(str)
 	}
 	c.JSON(200, Resp[interface{}]{
-		Code:    code,
-		Message: hidePrivacy(str),
-		Data:    nil,
+		Code:      code,
+		Message:   hidePrivacy(str),
+		Data:      nil,
+		ErrorCode: errs.StatusCode(code),
+		RequestID: id,
 	})
 	c.Abort()
 }
@@ -12,8 +12,11 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/net"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 )
 
@@ -31,6 +34,7 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 			size = file.GetSize()
 		}
 		rrf, _ := stream.GetRangeReaderFromLink(size, link)
+		rrf = stream.ResumableRangeReader(rrf, setting.GetInt(conf.StreamProxyResumeRetries, 0))
 		if link.RangeReader == nil {
 			r = r.WithContext(context.WithValue(r.Context(), conf.RequestHeaderKey, r.Header))
 		}
@@ -46,7 +50,7 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 			size = file.GetSize()
 		}
 		return net.ServeHTTP(w, r, file.GetName(), file.ModTime(), size, &model.RangeReadCloser{
-			RangeReader: link.RangeReader,
+			RangeReader: stream.ResumableRangeReader(link.RangeReader, setting.GetInt(conf.StreamProxyResumeRetries, 0)),
 		})
 	}
 
@@ -56,34 +60,91 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
+	body := resumeBody(r, res, header, link.URL)
+	defer body.Close()
 
 	maps.Copy(w.Header(), res.Header)
+	if contentType, ok := utils.MimeTypeOverride(file.GetName()); ok {
+		w.Header().Set("Content-Type", contentType)
+	}
 	w.WriteHeader(res.StatusCode)
 	if r.Method == http.MethodHead {
 		return nil
 	}
 	_, err = utils.CopyWithBuffer(w, &stream.RateLimitReader{
-		Reader:  res.Body,
+		Reader:  body,
 		Limiter: stream.ServerDownloadLimit,
 		Ctx:     r.Context(),
 	})
 	return err
 }
+
+// resumeBody makes the body of a transparently proxied download re-request the rest from url when it breaks off
+func resumeBody(r *http.Request, res *http.Response, header http.Header, url string) io.ReadCloser {
+	retries := setting.GetInt(conf.StreamProxyResumeRetries, 0)
+	if r.Method != http.MethodGet || res.ContentLength <= 0 || retries <= 0 {
+		return res.Body
+	}
+	httpRange := http_range.Range{Length: res.ContentLength}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusPartialContent:
+		start, _, err := http_range.ParseContentRange(res.Header.Get("Content-Range"))
+		if err != nil {
+			return res.Body
+		}
+		httpRange.Start = start
+	default:
+		return res.Body
+	}
+	// the rest is only resumed from the same version of the file, which the server responds by If-Range
+	validator := res.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = res.Header.Get("Last-Modified")
+	}
+	if validator == "" {
+		return res.Body
+	}
+	rr := stream.RangeReaderFunc(func(ctx context.Context, rest http_range.Range) (io.ReadCloser, error) {
+		header := http_range.ApplyRangeToHttpHeader(rest, header.Clone())
+		header.Set("If-Range", validator)
+		res, err := net.RequestHttp(ctx, http.MethodGet, header, url)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode == http.StatusOK {
+			_ = res.Body.Close()
+			return nil, fmt.Errorf("failed resume, the file has changed")
+		}
+		if res.StatusCode != http.StatusPartialContent {
+			_ = res.Body.Close()
+			return nil, fmt.Errorf("failed resume, the server responded %s", res.Status)
+		}
+		return res.Body, nil
+	})
+	return stream.ResumeReadCloser(r.Context(), res.Body, rr, httpRange, retries)
+}
+
 func attachHeader(w http.ResponseWriter, file model.Obj, link *model.Link) {
 	fileName := file.GetName()
 	w.Header().Set("Content-Disposition", utils.GenerateContentDisposition(fileName))
-	w.Header().Set("Content-Type", utils.GetMimeType(fileName))
 	size := link.ContentLength
 	if size <= 0 {
 		size = file.GetSize()
 	}
 	w.Header().Set("Etag", GetEtag(file, size))
-	contentType := link.Header.Get("Content-Type")
+	// the mime types set by the mime_types setting win over the one of the link
+	contentType, ok := utils.MimeTypeOverride(fileName)
+	if !ok {
+		contentType = link.Header.Get("Content-Type")
+	}
 	if len(contentType) > 0 {
 		w.Header().Set("Content-Type", contentType)
-	} else {
+	} else if utils.IsMimeTypeKnown(fileName) {
 		w.Header().Set("Content-Type", utils.GetMimeType(fileName))
+	} else {
+		// net.ServeHTTP sniffs the content of the unknown extensions
+		w.Header().Del("Content-Type")
 	}
 }
 func GetEtag(file model.Obj, size int64) string {
@@ -116,6 +177,24 @@ func ProxyRange(ctx context.Context, link *model.Link, size int64) *model.Link {
 	return link
 }
 
+// ReadAhead makes the proxied download of link prefetch up to readAhead MB ahead of the client
+func ReadAhead(ctx context.Context, link *model.Link, size int64, readAhead int) *model.Link {
+	if readAhead <= 0 || link.RangeReader == nil && strings.HasPrefix(link.URL, GetApiUrl(ctx)+"/") {
+		return link
+	}
+	if link.ContentLength > 0 {
+		size = link.ContentLength
+	}
+	rrf, err := stream.GetRangeReaderFromLink(size, link)
+	if err != nil {
+		return link
+	}
+	return &model.Link{
+		RangeReader:   stream.ReadAheadRangeReader(rrf, int64(readAhead)*utils.MB),
+		ContentLength: size,
+	}
+}
+
 type InterceptResponseWriter struct {
 	http.ResponseWriter
 	io.Writer
@@ -128,10 +207,12 @@ func (iw *InterceptResponseWriter) Write(p []byte) (int, error) {
 type WrittenResponseWriter struct {
 	http.ResponseWriter
 	written bool
+	size    int64
 }
 
 func (ww *WrittenResponseWriter) Write(p []byte) (int, error) {
 	n, err := ww.ResponseWriter.Write(p)
+	ww.size += int64(n)
 	if !ww.written && n > 0 {
 		ww.written = true
 	}
@@ -142,8 +223,14 @@ func (ww *WrittenResponseWriter) IsWritten() bool {
 	return ww.written
 }
 
+// Size returns the number of body bytes written
+func (ww *WrittenResponseWriter) Size() int64 {
+	return ww.size
+}
+
 func GenerateDownProxyURL(storage *model.Storage, reqPath string) string {
-	if storage.DownProxyURL == "" {
+	proxyURL := op.PickDownProxyURL(storage.DownProxyURL)
+	if proxyURL == "" {
 		return ""
 	}
 	query := ""
@@ -151,7 +238,7 @@ func GenerateDownProxyURL(storage *model.Storage, reqPath string) string {
 		query = "?sign=" + sign.Sign(reqPath)
 	}
 	return fmt.Sprintf("%s%s%s",
-		strings.Split(storage.DownProxyURL, "\n")[0],
+		proxyURL,
 		utils.EncodePath(reqPath, true),
 		query,
 	)
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+// brokenBody breaks off with an error after limit bytes
+type brokenBody struct {
+	r     io.Reader
+	limit int
+}
+
+func (b *brokenBody) Read(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return 0, errors.New("connection reset")
+	}
+	if len(p) > b.limit {
+		p = p[:b.limit]
+	}
+	n, err := b.r.Read(p)
+	b.limit -= n
+	return n, err
+}
+
+func (b *brokenBody) Close() error {
+	return nil
+}
+
+func TestResumeBody(t *testing.T) {
+	err := op.SaveSettingItem(&model.SettingItem{Key: conf.StreamProxyResumeRetries, Value: "3", Type: conf.TypeNumber, Group: model.TRAFFIC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	etag := `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	read := func() ([]byte, error) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := &http.Response{
+			StatusCode:    http.StatusOK,
+			ContentLength: int64(len(data)),
+			Header:        http.Header{"Etag": []string{`"v1"`}},
+			Body:          &brokenBody{r: bytes.NewReader(data), limit: 300},
+		}
+		body := resumeBody(r, res, http.Header{}, server.URL)
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	got, err := read()
+	if err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("got %d bytes not matching the file", len(got))
+	}
+
+	// the file changed, the server responds the whole file instead of the rest
+	etag = `"v2"`
+	if _, err = read(); err == nil {
+		t.Error("expected resuming the changed file to fail")
+	}
+}
@@ -4,6 +4,9 @@ type Resp[T any] struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    T      `json:"data"`
+	// ErrorCode and RequestID are set on the errors, see errs.Code
+	ErrorCode string `json:"error_code,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 type PageResp struct {
@@ -149,6 +149,7 @@ func (d *FtpMainDriver) AuthUser(cc ftpserver.ClientContext, user, pass string)
 
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, conf.UserKey, userObj)
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolFTP)
 	if user == "anonymous" || user == "guest" {
 		ctx = context.WithValue(ctx, conf.MetaPassKey, pass)
 	} else {
@@ -31,7 +31,7 @@ func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownl
 		return nil, err
 	}
 	ctx = context.WithValue(ctx, conf.MetaKey, meta)
-	if !common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
+	if !common.CanAccessVia(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string), common.Protocol(ctx)) {
 		return nil, errs.PermissionDenied
 	}
 
@@ -123,7 +123,7 @@ func Stat(ctx context.Context, path string) (os.FileInfo, error) {
 		return nil, err
 	}
 	ctx = context.WithValue(ctx, conf.MetaKey, meta)
-	if !common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
+	if !common.CanAccessVia(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string), common.Protocol(ctx)) {
 		return nil, errs.PermissionDenied
 	}
 	if ret, err := StatStage(reqPath); !errors.Is(err, errs.ObjectNotFound) {
@@ -147,7 +147,7 @@ func List(ctx context.Context, path string) ([]os.FileInfo, error) {
 		return nil, err
 	}
 	ctx = context.WithValue(ctx, conf.MetaKey, meta)
-	if !common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
+	if !common.CanAccessVia(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string), common.Protocol(ctx)) {
 		return nil, errs.PermissionDenied
 	}
 	objs, err := fs.List(ctx, reqPath, &fs.ListArgs{})
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	stdpath "path"
 	"time"
@@ -60,7 +59,7 @@ func OpenUpload(ctx context.Context, path string, trunc bool) (*FileUploadProxy,
 	if setting.GetBool(conf.IgnoreSystemFiles) && utils.IsSystemFile(name) {
 		return nil, errs.IgnoredSystemFile
 	}
-	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	tmpFile, err := utils.CreateTemp("file-*", 0)
 	if err != nil {
 		return nil, err
 	}
@@ -94,10 +93,11 @@ func (f *FileUploadProxy) Close() error {
 		return err
 	}
 	arr := make([]byte, 512)
-	if _, err := f.buffer.Read(arr); err != nil {
+	n, err := f.buffer.Read(arr)
+	if err != nil {
 		return err
 	}
-	contentType := http.DetectContentType(arr)
+	contentType := utils.DetectMimeType(name, arr[:n])
 	if _, err := f.buffer.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
@@ -190,8 +190,8 @@ func (f *FileUploadWithLengthProxy) write(p []byte) (n int, err error) {
 		return len(p), nil
 	} else {
 		copy(f.first512Bytes[f.pFirst:], p[:512-f.pFirst])
-		contentType := http.DetectContentType(f.first512Bytes[:])
 		dir, name := stdpath.Split(f.path)
+		contentType := utils.DetectMimeType(name, f.first512Bytes[:])
 		reader, writer := io.Pipe()
 		f.errChan = make(chan error, 1)
 		s := &stream.FileStream{
@@ -246,8 +246,8 @@ func (f *FileUploadWithLengthProxy) Close() error {
 		return err
 	} else {
 		data := f.first512Bytes[:f.pFirst]
-		contentType := http.DetectContentType(data)
 		dir, name := stdpath.Split(f.path)
+		contentType := utils.DetectMimeType(name, data)
 		s := &stream.FileStream{
 			Obj: &model.Object{
 				Name:     name,
@@ -0,0 +1,105 @@
+package handles
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// the longest window of a grant, the longer needs should change the user instead
+const maxAccessGrantDuration = 30 * 24 * time.Hour
+
+type ListAccessGrantsReq struct {
+	model.PageReq
+	UserId uint `json:"user_id" form:"user_id"`
+}
+
+func ListAccessGrants(c *gin.Context) {
+	var req ListAccessGrantsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	grants, total, err := op.GetAccessGrants(req.UserId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: grants,
+		Total:   total,
+	})
+}
+
+type CreateAccessGrantReq struct {
+	UserId     uint   `json:"user_id" binding:"required"`
+	Permission int32  `json:"permission"`
+	Path       string `json:"path"`
+	Write      bool   `json:"write"`
+	Reason     string `json:"reason"`
+	Duration   int64  `json:"duration" binding:"required"` // seconds
+}
+
+func CreateAccessGrant(c *gin.Context) {
+	var req CreateAccessGrantReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	duration := time.Duration(req.Duration) * time.Second
+	if duration <= 0 || duration > maxAccessGrantDuration {
+		common.ErrorStrResp(c, "the duration must be between 1 second and 30 days", 400)
+		return
+	}
+	user, err := op.GetUserById(req.UserId)
+	if err != nil {
+		common.ErrorStrResp(c, "user invalid", 404)
+		return
+	}
+	if !checkUserManageable(c, user) {
+		return
+	}
+	current := c.Request.Context().Value(conf.UserKey).(*model.User)
+	g := &model.AccessGrant{
+		UserId:     req.UserId,
+		Permission: req.Permission,
+		Path:       req.Path,
+		Write:      req.Write,
+		Reason:     req.Reason,
+		GrantedBy:  current.Username,
+		Expires:    time.Now().Add(duration),
+	}
+	if err = op.CreateAccessGrant(g); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, g)
+}
+
+func RevokeAccessGrant(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "id format invalid", 400)
+		return
+	}
+	g, err := op.GetAccessGrantById(uint(id))
+	if err != nil {
+		common.ErrorStrResp(c, "grant invalid", 404)
+		return
+	}
+	if user, err := op.GetUserById(g.UserId); err == nil && !checkUserManageable(c, user) {
+		return
+	}
+	current := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if err = op.RevokeAccessGrant(uint(id), current.Username); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,104 @@
+package handles
+
+import (
+	stdpath "path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type AccessHistoryResp struct {
+	Path     string    `json:"path"`
+	Name     string    `json:"name"`
+	Count    int       `json:"count"`
+	Accessed time.Time `json:"accessed"`
+}
+
+type AccessHistoryDeleteReq struct {
+	Path string `json:"path"`
+	All  bool   `json:"all"`
+}
+
+// recordAccess records the access asynchronously, so it never slows down the request
+func recordAccess(user *model.User, fullPath string) {
+	if user == nil || user.IsGuest() {
+		return
+	}
+	limit := setting.GetInt(conf.RecentFilesLimit, 50)
+	if limit <= 0 {
+		return
+	}
+	go func() {
+		if err := op.RecordAccess(user.ID, fullPath, limit); err != nil {
+			log.Warnf("failed record access of %s: %+v", fullPath, err)
+		}
+	}()
+}
+
+func ListMyRecent(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	histories, total, err := op.GetAccessHistoriesByUserId(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := make([]AccessHistoryResp, 0, len(histories))
+	for _, h := range histories {
+		resp = append(resp, AccessHistoryResp{
+			Path:     toUserPath(user, h.Path),
+			Name:     stdpath.Base(h.Path),
+			Count:    h.Count,
+			Accessed: h.Accessed,
+		})
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: resp,
+		Total:   total,
+	})
+}
+
+func DeleteMyRecent(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req AccessHistoryDeleteReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	var err error
+	if req.All {
+		err = op.DeleteAccessHistoriesByUserId(user.ID)
+	} else {
+		var reqPath string
+		reqPath, err = user.JoinPath(req.Path)
+		if err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+		err = op.DeleteAccessHistory(user.ID, reqPath)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,114 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAccessRequestReq struct {
+	Path   string `json:"path" binding:"required"`
+	Write  bool   `json:"write"`
+	Reason string `json:"reason"`
+}
+
+// CreateMyAccessRequest lets the user ask for the access to a path the user is denied
+func CreateMyAccessRequest(c *gin.Context) {
+	var req CreateAccessRequestReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "guest user can not request access", 403)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	r, err := op.CreateAccessRequest(&model.AccessRequest{
+		UserId: user.ID,
+		Path:   reqPath,
+		Write:  req.Write,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, r)
+}
+
+func ListMyAccessRequests(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	requests, total, err := op.GetAccessRequests(user.ID, "", req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: requests,
+		Total:   total,
+	})
+}
+
+type ListAccessRequestsReq struct {
+	model.PageReq
+	Status string `json:"status" form:"status"`
+}
+
+func ListAccessRequests(c *gin.Context) {
+	var req ListAccessRequestsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	requests, total, err := op.GetAccessRequests(0, req.Status, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: requests,
+		Total:   total,
+	})
+}
+
+type ReviewAccessRequestReq struct {
+	ID      uint   `json:"id" binding:"required"`
+	Comment string `json:"comment"`
+}
+
+func reviewAccessRequest(c *gin.Context, approve bool) {
+	var req ReviewAccessRequestReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if err := op.ReviewAccessRequest(req.ID, approve, user.Username, req.Comment); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ApproveAccessRequest(c *gin.Context) {
+	reviewAccessRequest(c, true)
+}
+
+func DenyAccessRequest(c *gin.Context) {
+	reviewAccessRequest(c, false)
+}
@@ -242,7 +242,7 @@ func FsArchiveDecompress(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	if !user.CanDecompress() {
+	if !user.CanDecompress() || !user.HasFeature(model.FeatureDecompress) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
 		return
 	}
@@ -269,6 +269,9 @@ func FsArchiveDecompress(c *gin.Context) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
 		return
 	}
+	if !checkTaskLimit(c, fs.ArchiveDownloadTaskManager, model.TaskTypeDecompress, len(srcPaths)) {
+		return
+	}
 	tasks := make([]task.TaskExtensionInfo, 0, len(srcPaths))
 	for _, srcPath := range srcPaths {
 		t, e := fs.ArchiveDecompress(c.Request.Context(), srcPath, dstDir, model.ArchiveDecompressArgs{
@@ -363,7 +366,7 @@ func ArchiveProxy(c *gin.Context) {
 			common.ErrorPage(c, err, 500)
 			return
 		}
-		proxy(c, link, file, storage.GetStorage().ProxyRange)
+		proxy(c, link, file, storage.GetStorage().Proxy)
 	} else {
 		common.ErrorPage(c, errors.New("proxy not allowed"), 403)
 		return
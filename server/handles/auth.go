@@ -94,6 +94,8 @@ func CurrentUser(c *gin.Context) {
 		User: *user,
 	}
 	userResp.Password = ""
+	// show the features of the active grants as well
+	userResp.Permission = user.EffectivePermission()
 	if userResp.OtpSecret != "" {
 		userResp.Otp = true
 	}
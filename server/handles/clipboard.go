@@ -0,0 +1,135 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SetClipboardReq struct {
+	Op     string   `json:"op" binding:"required"`
+	SrcDir string   `json:"src_dir"`
+	Names  []string `json:"names"`
+}
+
+type PasteClipboardReq struct {
+	DstDir       string `json:"dst_dir"`
+	Overwrite    bool   `json:"overwrite"`
+	SkipExisting bool   `json:"skip_existing"`
+	Merge        bool   `json:"merge"`
+	// Transactional only applies to a cut
+	Transactional bool `json:"transactional"`
+	Verify        bool `json:"verify"`
+}
+
+func GetMyClipboard(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	clipboard, err := op.GetClipboardByUserId(user.ID)
+	if err != nil {
+		if errors.Is(errors.Cause(err), gorm.ErrRecordNotFound) {
+			common.SuccessResp(c, nil)
+			return
+		}
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	clipboard.SrcDir = toUserPath(user, clipboard.SrcDir)
+	common.SuccessResp(c, clipboard)
+}
+
+func SetMyClipboard(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req SetClipboardReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	srcDir, err := user.JoinPath(req.SrcDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	for _, name := range req.Names {
+		if err = checkRelativePath(name); err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+	}
+	clipboard, err := op.SetClipboard(user.ID, req.Op, srcDir, req.Names)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	clipboard.SrcDir = toUserPath(user, clipboard.SrcDir)
+	common.SuccessResp(c, clipboard)
+}
+
+func ClearMyClipboard(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	if err := op.DeleteClipboardByUserId(user.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// PasteMyClipboard creates the copy or move tasks of the clipboard to the dst dir,
+// the clipboard is cleared after a successful move, like in a file manager
+func PasteMyClipboard(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req PasteClipboardReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	clipboard, err := op.GetClipboardByUserId(user.ID)
+	if err != nil {
+		if errors.Is(errors.Cause(err), gorm.ErrRecordNotFound) {
+			common.ErrorStrResp(c, "clipboard is empty", 400)
+			return
+		}
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	moveCopyReq := &MoveCopyReq{
+		SrcDir:        toUserPath(user, clipboard.SrcDir),
+		DstDir:        req.DstDir,
+		Names:         clipboard.Names,
+		Overwrite:     req.Overwrite,
+		SkipExisting:  req.SkipExisting,
+		Merge:         req.Merge,
+		Transactional: req.Transactional,
+		Verify:        req.Verify,
+	}
+	if clipboard.Op == model.ClipboardMove {
+		fsMove(c, moveCopyReq)
+		if !c.IsAborted() {
+			if err = op.DeleteClipboardByUserId(user.ID); err != nil {
+				log.Errorf("failed clear clipboard of user %s: %+v", user.Username, err)
+			}
+		}
+	} else {
+		fsCopy(c, moveCopyReq)
+	}
+}
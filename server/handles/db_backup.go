@@ -0,0 +1,27 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type BackupDBReq struct {
+	Path string `json:"path" binding:"required"`
+}
+
+// BackupDB uploads a copy of the sqlite database into the folder of req.Path
+func BackupDB(c *gin.Context) {
+	var req BackupDBReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	name, err := fs.BackupDB(c.Request.Context(), utils.FixAndCleanPath(req.Path))
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{"name": name})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"net/http"
 	stdpath "path"
 	"strconv"
 
@@ -13,7 +14,9 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/net"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
@@ -30,14 +33,21 @@ func Down(c *gin.Context) {
 		common.ErrorPage(c, err, 500)
 		return
 	}
-	if common.ShouldProxy(storage, filename) {
+	size := func() int64 {
+		obj, err := fs.Get(c.Request.Context(), rawPath, &fs.GetArgs{NoLog: true})
+		if err != nil {
+			return -1
+		}
+		return obj.GetSize()
+	}
+	if common.ShouldProxyFor(storage, filename, c.ClientIP(), size) {
 		Proxy(c)
 		return
 	} else {
-		link, _, err := fs.Link(c.Request.Context(), rawPath, model.LinkArgs{
+		link, file, err := fs.Link(c.Request.Context(), rawPath, model.LinkArgs{
 			IP:       c.ClientIP(),
 			Header:   c.Request.Header,
-			Type:     c.Query("type"),
+			Type:     linkType(c),
 			Redirect: true,
 		})
 		if err != nil {
@@ -45,6 +55,11 @@ func Down(c *gin.Context) {
 			return
 		}
 		redirect(c, link)
+		// the bytes sent by the redirected link are unknown, the file is counted by the request of its first range
+		if c.Writer.Status() == 302 && isFirstRange(c, file.GetSize()) {
+			recordDownload(c, rawPath, storage, 1, file.GetSize())
+			recordAccess(downUser(c), rawPath)
+		}
 	}
 }
 
@@ -65,19 +80,54 @@ func Proxy(c *gin.Context) {
 		}
 		link, file, err := fs.Link(c.Request.Context(), rawPath, model.LinkArgs{
 			Header: c.Request.Header,
-			Type:   c.Query("type"),
+			Type:   linkType(c),
 		})
 		if err != nil {
 			common.ErrorPage(c, err, 500)
 			return
 		}
-		proxy(c, link, file, storage.GetStorage().ProxyRange)
+		first := isFirstRange(c, file.GetSize())
+		if written := proxy(c, link, file, storage.GetStorage().Proxy); written > 0 {
+			var count int64
+			if first {
+				count = 1
+				recordAccess(downUser(c), rawPath)
+			}
+			recordDownload(c, rawPath, storage, count, written)
+		}
 	} else {
 		common.ErrorPage(c, errors.New("proxy not allowed"), 403)
 		return
 	}
 }
 
+// ThumbVariant swaps the path of the thumb requests of a size, e.g. /d/a.jpg?type=thumb&size=640,
+// with the resized variant of the image, the thumb of the driver is served if it has no variants
+func ThumbVariant(c *gin.Context) {
+	if c.Query("type") != "thumb" {
+		return
+	}
+	size, err := strconv.Atoi(c.Query("size"))
+	if err != nil || size <= 0 {
+		return
+	}
+	rawPath := c.Request.Context().Value(conf.PathKey).(string)
+	if variant, ok := fs.ImageVariant(c.Request.Context(), rawPath, size); ok {
+		common.GinWithValue(c, conf.PathKey, variant)
+		c.Set(imageVariantKey, true)
+	}
+}
+
+const imageVariantKey = "image_variant"
+
+// linkType is the type of the link to the file, the variants are linked as they are
+func linkType(c *gin.Context) string {
+	if c.GetBool(imageVariantKey) {
+		return ""
+	}
+	return c.Query("type")
+}
+
 func redirect(c *gin.Context, link *model.Link) {
 	defer link.Close()
 	var err error
@@ -97,7 +147,8 @@ func redirect(c *gin.Context, link *model.Link) {
 	c.Redirect(302, link.URL)
 }
 
-func proxy(c *gin.Context, link *model.Link, file model.Obj, proxyRange bool) {
+// proxy returns the number of body bytes written to the client
+func proxy(c *gin.Context, link *model.Link, file model.Obj, opts model.Proxy) int64 {
 	defer link.Close()
 	var err error
 	if link.URL != "" && setting.GetBool(conf.ForwardDirectLinkParams) {
@@ -108,12 +159,13 @@ func proxy(c *gin.Context, link *model.Link, file model.Obj, proxyRange bool) {
 		link.URL, err = utils.InjectQuery(link.URL, query)
 		if err != nil {
 			common.ErrorPage(c, err, 500)
-			return
+			return 0
 		}
 	}
-	if proxyRange {
+	if opts.ProxyRange {
 		link = common.ProxyRange(c, link, file.GetSize())
 	}
+	link = common.ReadAhead(c, link, file.GetSize(), opts.ReadAhead)
 	Writer := &common.WrittenResponseWriter{ResponseWriter: c.Writer}
 	raw, _ := strconv.ParseBool(c.DefaultQuery("raw", "false"))
 	if utils.Ext(file.GetName()) == "md" && setting.GetBool(conf.FilterReadMeScripts) && !raw {
@@ -122,8 +174,8 @@ func proxy(c *gin.Context, link *model.Link, file model.Obj, proxyRange bool) {
 		err = common.Proxy(w, c.Request, link, file)
 		if err == nil && buf.Len() > 0 {
 			if c.Writer.Status() < 200 || c.Writer.Status() > 300 {
-				c.Writer.Write(buf.Bytes())
-				return
+				n, _ := c.Writer.Write(buf.Bytes())
+				return int64(n)
 			}
 
 			var html bytes.Buffer
@@ -143,10 +195,11 @@ func proxy(c *gin.Context, link *model.Link, file model.Obj, proxyRange bool) {
 		err = common.Proxy(Writer, c.Request, link, file)
 	}
 	if err == nil {
-		return
+		return Writer.Size()
 	}
 	if Writer.IsWritten() {
 		log.Errorf("%s %s local proxy error: %+v", c.Request.Method, c.Request.URL.Path, err)
+		return Writer.Size()
 	} else {
 		if statusCode, ok := errs.UnwrapOrSelf(err).(net.HttpStatusCodeError); ok {
 			common.ErrorPage(c, err, int(statusCode), true)
@@ -154,6 +207,33 @@ func proxy(c *gin.Context, link *model.Link, file model.Obj, proxyRange bool) {
 			common.ErrorPage(c, err, 500, true)
 		}
 	}
+	return 0
+}
+
+// downUser returns the user of the token of the download, nil if there's none
+func downUser(c *gin.Context) *model.User {
+	user, _ := c.Request.Context().Value(conf.UserKey).(*model.User)
+	return user
+}
+
+// isFirstRange reports whether the request gets the file from its start, so that a download by ranges,
+// e.g. of a player or a multi-thread downloader, is counted once
+func isFirstRange(c *gin.Context, size int64) bool {
+	ranges, err := http_range.ParseRange(c.GetHeader("Range"), size)
+	return err != nil || len(ranges) == 0 || ranges[0].Start == 0
+}
+
+// recordDownload counts count downloads of the file and the bytes sent of it
+func recordDownload(c *gin.Context, rawPath string, storage driver.Driver, count, bytes int64) {
+	if c.Request.Method != http.MethodGet {
+		return
+	}
+	var userId uint
+	if user := downUser(c); user != nil {
+		userId = user.ID
+	}
+	op.RecordDownload(rawPath, storage.GetStorage().MountPath, userId, count, bytes)
+	op.RecordStorageTraffic(storage.GetStorage().MountPath, bytes, 0)
 }
 
 // TODO need optimize
@@ -167,6 +247,9 @@ func canProxy(storage driver.Driver, filename string) bool {
 	if storage.Config().MustProxy() || storage.GetStorage().WebProxy || storage.GetStorage().WebdavProxyURL() {
 		return true
 	}
+	if storage.GetStorage().HasProxyRule() {
+		return true
+	}
 	if utils.SliceContains(conf.SlicesMap[conf.ProxyTypes], utils.Ext(filename)) {
 		return true
 	}
@@ -0,0 +1,48 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func DownloadStats(c *gin.Context) {
+	var req model.DownloadStatsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	items, err := op.GetDownloadStats(req)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, items)
+}
+
+func LinkCacheStats(c *gin.Context) {
+	common.SuccessResp(c, op.GetLinkCacheStats())
+}
+
+func StorageTraffic(c *gin.Context) {
+	var req model.StorageTrafficReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	items, err := op.GetStorageTraffic(req)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, items)
+}
@@ -0,0 +1,164 @@
+package handles
+
+import (
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type FavoriteReq struct {
+	Path     string `json:"path" binding:"required"`
+	Password string `json:"password"`
+}
+
+type FavoriteResp struct {
+	ID       uint      `json:"id"`
+	Path     string    `json:"path"`
+	Name     string    `json:"name"`
+	Exists   bool      `json:"exists"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Created  time.Time `json:"created"`
+}
+
+func AddMyFavorite(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req FavoriteReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	if _, err = fs.Get(c.Request.Context(), reqPath, &fs.GetArgs{NoLog: true}); err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if err = op.CreateFavorite(user.ID, reqPath); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteMyFavorite(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req FavoriteReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if err = op.DeleteFavorite(user.ID, reqPath); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListMyFavorites(c *gin.Context) {
+	user, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	favorites, total, err := op.GetFavoritesByUserId(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := make([]FavoriteResp, 0, len(favorites))
+	for _, f := range favorites {
+		item := FavoriteResp{
+			ID:      f.ID,
+			Path:    toUserPath(user, f.Path),
+			Name:    stdpath.Base(f.Path),
+			Created: f.Created,
+		}
+		// the paths may be removed or become inaccessible after being starred
+		meta, err := op.GetNearestMeta(f.Path)
+		if err == nil || errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			if common.CanAccess(user, meta, f.Path, "") {
+				if obj, err := fs.Get(c.Request.Context(), f.Path, &fs.GetArgs{NoLog: true}); err == nil {
+					item.Exists = true
+					item.IsDir = obj.IsDir()
+					item.Size = obj.GetSize()
+					item.Modified = obj.ModTime()
+				}
+			}
+		}
+		resp = append(resp, item)
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: resp,
+		Total:   total,
+	})
+}
+
+// markStarred sets the starred status of objs under the parent path,
+// it's cheap since the favorite paths of a user are cached
+func markStarred(user *model.User, parent string, objs []ObjResp) {
+	if user == nil || user.IsGuest() || len(objs) == 0 {
+		return
+	}
+	set, err := op.GetFavoritePathSet(user.ID)
+	if err != nil || len(set) == 0 {
+		return
+	}
+	for i := range objs {
+		_, objs[i].Starred = set[stdpath.Join(parent, objs[i].Name)]
+	}
+}
+
+// toUserPath converts a full path to the path seen by the user
+func toUserPath(user *model.User, fullPath string) string {
+	base := strings.TrimSuffix(user.BasePath, "/")
+	if base == "" {
+		return fullPath
+	}
+	rel := strings.TrimPrefix(fullPath, base)
+	if rel == "" {
+		return "/"
+	}
+	return rel
+}
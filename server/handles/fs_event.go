@@ -0,0 +1,164 @@
+package handles
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type FsEventsReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+const fsEventsHeartbeat = 30 * time.Second
+
+// subscribeFsEvents subscribes the events under the requested path which the user can access,
+// the password of the request is returned to check the paths of the events
+func subscribeFsEvents(c *gin.Context) (*model.User, string, *op.FsEventSubscriber, bool) {
+	var req FsEventsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return nil, "", nil, false
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return nil, "", nil, false
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return nil, "", nil, false
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return nil, "", nil, false
+	}
+	return user, req.Password, op.SubscribeFsEvents(reqPath), true
+}
+
+// canSeeFsEvent reports whether the user can access the paths of the event with the password
+func canSeeFsEvent(user *model.User, password string, event model.FsEvent) bool {
+	paths := []string{event.Path}
+	if event.DstPath != "" {
+		paths = append(paths, event.DstPath)
+	}
+	for _, p := range paths {
+		// the paths of the events are absolute, they must be under the base path of the user
+		if !utils.IsSubPath(user.BasePath, p) {
+			return false
+		}
+		meta, err := op.GetNearestMeta(p)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return false
+		}
+		if !common.CanAccess(user, meta, p, password) {
+			return false
+		}
+	}
+	return true
+}
+
+// toUserFsEvent converts the paths of the event to the paths seen by the user
+func toUserFsEvent(user *model.User, event model.FsEvent) model.FsEvent {
+	event.Path = toUserPath(user, event.Path)
+	if event.DstPath != "" {
+		event.DstPath = toUserPath(user, event.DstPath)
+	}
+	return event
+}
+
+// FsEvents streams the fs events as server-sent events named by the event types
+func FsEvents(c *gin.Context) {
+	user, password, sub, ok := subscribeFsEvents(c)
+	if !ok {
+		return
+	}
+	defer op.UnsubscribeFsEvents(sub)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	heartbeat := time.NewTicker(fsEventsHeartbeat)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			if canSeeFsEvent(user, password, event) {
+				c.SSEvent(event.Type, toUserFsEvent(user, event))
+			}
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+var fsEventsUpgrader = websocket.Upgrader{
+	// the token is required to subscribe, so the origin is not checked
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// FsEventsWs streams the fs events as JSON messages of a WebSocket
+func FsEventsWs(c *gin.Context) {
+	user, password, sub, ok := subscribeFsEvents(c)
+	if !ok {
+		return
+	}
+	defer op.UnsubscribeFsEvents(sub)
+	conn, err := fsEventsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Debugf("failed upgrade fs events websocket: %+v", err)
+		return
+	}
+	defer conn.Close()
+	// the messages from the client are discarded, reading them is needed to notice the close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	heartbeat := time.NewTicker(fsEventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if !canSeeFsEvent(user, password, event) {
+				continue
+			}
+			if err := conn.WriteJSON(toUserFsEvent(user, event)); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package handles
+
+import (
+	"io"
+	"net/url"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/delta"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type DeltaPlanReq struct {
+	// Size of the new file
+	Size      int64 `json:"size" binding:"min=0"`
+	BlockSize int   `json:"block_size" binding:"required"`
+	// Blocks are the signatures of the blocks of the new file, see pkg/delta
+	Blocks []delta.Block `json:"blocks"`
+}
+
+type DeltaPlanResp struct {
+	ID string `json:"id"`
+	// Missing are the ranges of the new file to upload to /fs/delta/apply, in order
+	Missing  []delta.Range `json:"missing"`
+	DataSize int64         `json:"data_size"`
+}
+
+// fileHeaderPath returns the path of the File-Path header, the FsUp middleware has checked the permissions of it
+func fileHeaderPath(c *gin.Context) (string, bool) {
+	path, err := url.PathUnescape(c.GetHeader("File-Path"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return "", false
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	path, err = user.JoinPath(path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return "", false
+	}
+	return path, true
+}
+
+// FsDeltaPlan tells the ranges of the new version of a file the existing one doesn't have
+func FsDeltaPlan(c *gin.Context) {
+	var req DeltaPlanReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	path, ok := fileHeaderPath(c)
+	if !ok {
+		return
+	}
+	p, err := fs.PlanDelta(c.Request.Context(), path, req.Size, req.BlockSize, req.Blocks)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	missing := p.Missing()
+	if missing == nil {
+		missing = []delta.Range{}
+	}
+	common.SuccessResp(c, DeltaPlanResp{
+		ID:       p.ID,
+		Missing:  missing,
+		DataSize: p.DataSize(),
+	})
+}
+
+// FsDeltaApply updates the file by the plan of the Delta-Plan header, the body is the missing ranges in order
+func FsDeltaApply(c *gin.Context) {
+	defer func() {
+		if n, _ := io.ReadFull(c.Request.Body, []byte{0}); n == 1 {
+			_, _ = utils.CopyWithBuffer(io.Discard, c.Request.Body)
+		}
+		_ = c.Request.Body.Close()
+	}()
+	path, ok := fileHeaderPath(c)
+	if !ok {
+		return
+	}
+	id := c.GetHeader("Delta-Plan")
+	if id == "" {
+		common.ErrorStrResp(c, "Delta-Plan is required", 400)
+		return
+	}
+	if err := fs.ApplyDelta(c.Request.Context(), id, path, c.Request.Body, getLastModified(c)); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,179 @@
+package handles
+
+import (
+	"context"
+	"encoding/json"
+	stdpath "path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type LsJSONReq struct {
+	Path      string `json:"path" form:"path"`
+	Password  string `json:"password" form:"password"`
+	Recursive bool   `json:"recursive" form:"recursive"`
+	MaxDepth  int    `json:"max_depth" form:"max_depth"` // 0 means unlimited when recursive
+	DirsOnly  bool   `json:"dirs_only" form:"dirs_only"`
+	FilesOnly bool   `json:"files_only" form:"files_only"`
+}
+
+// LsJSONItem is an entry in the format of `rclone lsjson`
+type LsJSONItem struct {
+	Path     string            `json:"Path"` // relative to the listed directory
+	Name     string            `json:"Name"`
+	Size     int64             `json:"Size"`
+	MimeType string            `json:"MimeType"`
+	ModTime  time.Time         `json:"ModTime"`
+	IsDir    bool              `json:"IsDir"`
+	Hashes   map[string]string `json:"Hashes,omitempty"`
+}
+
+// FsLsJSON streams the listing of a directory as a JSON array in the format of `rclone lsjson`,
+// the items are flushed directory by directory so that huge trees don't have to be held in memory.
+// An error after the first byte is sent truncates the array, so the client fails to parse it
+func FsLsJSON(c *gin.Context) {
+	var req LsJSONReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	obj, err := fs.Get(c.Request.Context(), reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if !obj.IsDir() {
+		common.ErrorStrResp(c, "not a folder", 400)
+		return
+	}
+	maxDepth := 1
+	if req.Recursive {
+		maxDepth = req.MaxDepth
+	}
+	l := &lsJSONLister{
+		ctx:      c.Request.Context(),
+		user:     user,
+		req:      &req,
+		maxDepth: maxDepth,
+		c:        c,
+		first:    true,
+	}
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(200)
+	_, _ = c.Writer.WriteString("[")
+	if err := l.list(reqPath, "", meta, 1); err != nil {
+		utils.Log.Errorf("failed lsjson [%s]: %+v", reqPath, err)
+		return
+	}
+	_, _ = c.Writer.WriteString("\n]\n")
+}
+
+type lsJSONLister struct {
+	ctx      context.Context
+	user     *model.User
+	req      *LsJSONReq
+	maxDepth int
+	c        *gin.Context
+	first    bool
+}
+
+func (l *lsJSONLister) list(dirPath, relPath string, meta *model.Meta, depth int) error {
+	if err := l.ctx.Err(); err != nil {
+		return err
+	}
+	objs, err := fs.List(context.WithValue(l.ctx, conf.MetaKey, meta), dirPath, &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", dirPath)
+	}
+	var subDirs []model.Obj
+	for _, obj := range objs {
+		if obj.IsDir() {
+			subDirs = append(subDirs, obj)
+		}
+		if (obj.IsDir() && l.req.FilesOnly) || (!obj.IsDir() && l.req.DirsOnly) {
+			continue
+		}
+		if err := l.write(relPath, obj); err != nil {
+			return err
+		}
+	}
+	l.c.Writer.Flush()
+	if l.maxDepth > 0 && depth >= l.maxDepth {
+		return nil
+	}
+	for _, obj := range subDirs {
+		subPath := stdpath.Join(dirPath, obj.GetName())
+		subMeta, err := op.GetNearestMeta(subPath)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return err
+		}
+		// the password protected directories are listed but only entered by the password of the request
+		if !common.CanAccess(l.user, subMeta, subPath, l.req.Password) {
+			continue
+		}
+		if err := l.list(subPath, stdpath.Join(relPath, obj.GetName()), subMeta, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *lsJSONLister) write(relPath string, obj model.Obj) error {
+	item := LsJSONItem{
+		Path:     stdpath.Join(relPath, obj.GetName()),
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		MimeType: "inode/directory",
+		ModTime:  obj.ModTime(),
+		IsDir:    obj.IsDir(),
+	}
+	if !obj.IsDir() {
+		item.MimeType = utils.GetMimeType(obj.GetName())
+		for ht, v := range obj.GetHash().All() {
+			if v == "" {
+				continue
+			}
+			if item.Hashes == nil {
+				item.Hashes = make(map[string]string)
+			}
+			item.Hashes[ht.Name] = v
+		}
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sep := ",\n"
+	if l.first {
+		sep, l.first = "\n", false
+	}
+	if _, err := l.c.Writer.WriteString(sep); err != nil {
+		return err
+	}
+	_, err = l.c.Writer.Write(data)
+	return err
+}
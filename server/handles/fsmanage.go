@@ -1,15 +1,19 @@
 package handles
 
 import (
+	"context"
 	"fmt"
 	stdpath "path"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"github.com/OpenListTeam/OpenList/v4/pkg/generic"
@@ -64,6 +68,23 @@ type MoveCopyReq struct {
 	Overwrite    bool     `json:"overwrite"`
 	SkipExisting bool     `json:"skip_existing"`
 	Merge        bool     `json:"merge"`
+	// Transactional rolls back a cross storage move if any file fails
+	Transactional bool `json:"transactional"`
+	// Verify compares each file with its source after a cross storage transfer
+	Verify bool `json:"verify"`
+	// Priority of the tasks, the higher are run first
+	Priority int `json:"priority"`
+	// SpeedLimit of each task in KB/s, overriding the one of the task type
+	SpeedLimit int `json:"speed_limit"`
+	// DryRun reports what would be transferred with the estimated cost instead of creating the tasks
+	DryRun bool `json:"dry_run"`
+}
+
+type CopyDryRunResp struct {
+	Items []*fs.CopyEstimate `json:"items"`
+	Files int64              `json:"files"`
+	Size  int64              `json:"size"`
+	Cost  float64            `json:"cost"`
 }
 
 // FsMove performs batch move (individual item permission checks skipped for performance).
@@ -73,6 +94,10 @@ func FsMove(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	fsMove(c, &req)
+}
+
+func fsMove(c *gin.Context, req *MoveCopyReq) {
 	if len(req.Names) == 0 {
 		common.ErrorStrResp(c, "Empty file names", 400)
 		return
@@ -82,6 +107,9 @@ func FsMove(c *gin.Context) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
 		return
 	}
+	if !setTaskPriority(c, req.Priority) || !setTaskSpeedLimit(c, req.SpeedLimit) {
+		return
+	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -138,11 +166,26 @@ func FsMove(c *gin.Context) {
 		validPaths = append(validPaths, srcPath)
 	}
 
+	if req.DryRun {
+		copyDryRun(c, user, validPaths, dstDir)
+		return
+	}
+	if !checkTaskLimit(c, fs.MoveTaskManager, model.TaskTypeMove, len(validPaths)) {
+		return
+	}
+
 	// Create all tasks immediately without any synchronous validation
 	// All validation will be done asynchronously in the background
+	ctx := c.Request.Context()
+	if req.Transactional {
+		ctx = context.WithValue(ctx, conf.TransactionalKey, struct{}{})
+	}
+	if req.Verify {
+		ctx = context.WithValue(ctx, conf.VerifyKey, struct{}{})
+	}
 	var addedTasks []task.TaskExtensionInfo
 	for i, p := range validPaths {
-		t, err := fs.Move(c.Request.Context(), p, dstDir, len(validPaths) > i+1)
+		t, err := fs.Move(ctx, p, dstDir, len(validPaths) > i+1)
 		if t != nil {
 			addedTasks = append(addedTasks, t)
 		}
@@ -172,6 +215,10 @@ func FsCopy(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	fsCopy(c, &req)
+}
+
+func fsCopy(c *gin.Context, req *MoveCopyReq) {
 	if len(req.Names) == 0 {
 		common.ErrorStrResp(c, "Empty file names", 400)
 		return
@@ -181,6 +228,9 @@ func FsCopy(c *gin.Context) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
 		return
 	}
+	if !setTaskPriority(c, req.Priority) || !setTaskSpeedLimit(c, req.SpeedLimit) {
+		return
+	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -237,15 +287,27 @@ func FsCopy(c *gin.Context) {
 		validPaths = append(validPaths, srcPath)
 	}
 
+	if req.DryRun {
+		copyDryRun(c, user, validPaths, dstDir)
+		return
+	}
+	if !checkTaskLimit(c, fs.CopyTaskManager, model.TaskTypeCopy, len(validPaths)) {
+		return
+	}
+
 	// Create all tasks immediately without any synchronous validation
 	// All validation will be done asynchronously in the background
+	ctx := c.Request.Context()
+	if req.Verify {
+		ctx = context.WithValue(ctx, conf.VerifyKey, struct{}{})
+	}
 	var addedTasks []task.TaskExtensionInfo
 	for i, p := range validPaths {
 		var t task.TaskExtensionInfo
 		if req.Merge {
-			t, err = fs.Merge(c.Request.Context(), p, dstDir, len(validPaths) > i+1)
+			t, err = fs.Merge(ctx, p, dstDir, len(validPaths) > i+1)
 		} else {
-			t, err = fs.Copy(c.Request.Context(), p, dstDir, len(validPaths) > i+1)
+			t, err = fs.Copy(ctx, p, dstDir, len(validPaths) > i+1)
 		}
 		if t != nil {
 			addedTasks = append(addedTasks, t)
@@ -269,6 +331,23 @@ func FsCopy(c *gin.Context) {
 	}
 }
 
+func copyDryRun(c *gin.Context, user *model.User, paths []string, dstDir string) {
+	resp := CopyDryRunResp{Items: make([]*fs.CopyEstimate, 0, len(paths))}
+	for _, path := range paths {
+		estimate, err := fs.EstimateCopy(c.Request.Context(), path, dstDir)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		estimate.Path = toUserPath(user, estimate.Path)
+		resp.Items = append(resp.Items, estimate)
+		resp.Files += estimate.Files
+		resp.Size += estimate.Size
+		resp.Cost += estimate.Cost
+	}
+	common.SuccessResp(c, resp)
+}
+
 type RenameReq struct {
 	Path      string `json:"path"`
 	Name      string `json:"name"`
@@ -330,51 +409,150 @@ func checkRelativePath(path string) error {
 type RemoveReq struct {
 	Dir   string   `json:"dir"`
 	Names []string `json:"names"`
+	// Token is from the remove preview, it's needed if the remove exceeds the confirmation thresholds
+	Token string `json:"token"`
 }
 
-// FsRemove performs batch remove (individual item permission checks skipped for performance).
-func FsRemove(c *gin.Context) {
-	var req RemoveReq
-	if err := c.ShouldBind(&req); err != nil {
-		common.ErrorResp(c, err, 400)
-		return
-	}
+type RemovePreviewResp struct {
+	Items []*fs.RemoveStat `json:"items"`
+	Files int64            `json:"files"`
+	Dirs  int64            `json:"dirs"`
+	Size  int64            `json:"size"`
+	// ConfirmRequired is set if the token is needed to remove them
+	ConfirmRequired bool   `json:"confirm_required"`
+	Token           string `json:"token"`
+}
+
+const removeTokenExpiration = 10 * time.Minute
+
+// removePaths returns the full paths to remove of the request, the response is written if it fails
+func removePaths(c *gin.Context, req *RemoveReq) ([]string, bool) {
 	if len(req.Names) == 0 {
 		common.ErrorStrResp(c, "Empty file names", 400)
-		return
+		return nil, false
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
 	if !user.CanRemove() {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
+		return nil, false
 	}
 	reqPath, err := user.JoinPath(req.Dir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
-		return
+		return nil, false
 	}
 	meta, err := op.GetNearestMeta(reqPath)
 	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 		common.ErrorResp(c, err, 500, true)
-		return
+		return nil, false
 	}
 	if !common.CanWrite(user, meta, reqPath) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
+		return nil, false
 	}
-	for i, name := range req.Names {
+	var paths []string
+	for _, name := range req.Names {
 		fullPath := stdpath.Join(reqPath, name)
 		if !strings.HasPrefix(fullPath+"/", reqPath+"/") {
 			log.Warnf("FsRemove: path traversal attempt skipped: %s (dir: %s)\n", name, req.Dir)
-			req.Names[i] = ""
 			continue
 		}
-		req.Names[i] = fullPath
+		paths = append(paths, fullPath)
+	}
+	return paths, true
+}
+
+// removeTokenData is the data signed by the token confirming the remove of the paths by the user
+func removeTokenData(user *model.User, paths []string) string {
+	sorted := slices.Clone(paths)
+	slices.Sort(sorted)
+	return fmt.Sprintf("remove:%d:%s", user.ID, strings.Join(sorted, "\n"))
+}
+
+// exceedsRemoveThresholds reports whether removing the paths needs the confirmation
+func exceedsRemoveThresholds(ctx context.Context, paths []string) (bool, error) {
+	maxFiles := int64(setting.GetInt(conf.RemoveConfirmFiles, 0))
+	maxSize := int64(setting.GetInt(conf.RemoveConfirmSize, 0))
+	if maxFiles <= 0 && maxSize <= 0 {
+		return false, nil
+	}
+	var files, size int64
+	for _, path := range paths {
+		stat, err := fs.StatRemove(ctx, path, maxFiles, maxSize)
+		if errs.IsObjectNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		files += stat.Files
+		size += stat.Size
+		if stat.Exceeded || (maxFiles > 0 && files > maxFiles) || (maxSize > 0 && size > maxSize) {
+			return true, nil
+		}
 	}
-	for _, path := range req.Names {
-		if path == "" {
+	return false, nil
+}
+
+// FsRemovePreview reports what would be removed, with the token confirming the remove
+func FsRemovePreview(c *gin.Context) {
+	var req RemoveReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	paths, ok := removePaths(c, &req)
+	if !ok {
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	maxFiles := int64(setting.GetInt(conf.RemoveConfirmFiles, 0))
+	maxSize := int64(setting.GetInt(conf.RemoveConfirmSize, 0))
+	resp := RemovePreviewResp{Items: make([]*fs.RemoveStat, 0, len(paths))}
+	for _, path := range paths {
+		stat, err := fs.StatRemove(c.Request.Context(), path, 0, 0)
+		if errs.IsObjectNotFound(err) {
 			continue
 		}
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		stat.Path = toUserPath(user, stat.Path)
+		resp.Items = append(resp.Items, stat)
+		resp.Files += stat.Files
+		resp.Dirs += stat.Dirs
+		resp.Size += stat.Size
+	}
+	resp.ConfirmRequired = (maxFiles > 0 && resp.Files > maxFiles) || (maxSize > 0 && resp.Size > maxSize)
+	resp.Token = sign.WithDuration(removeTokenData(user, paths), removeTokenExpiration)
+	common.SuccessResp(c, resp)
+}
+
+// FsRemove performs batch remove (individual item permission checks skipped for performance).
+func FsRemove(c *gin.Context) {
+	var req RemoveReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	paths, ok := removePaths(c, &req)
+	if !ok {
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if req.Token == "" || sign.Verify(removeTokenData(user, paths), req.Token) != nil {
+		exceeded, err := exceedsRemoveThresholds(c.Request.Context(), paths)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		if exceeded {
+			common.ErrorStrResp(c, "too many files would be removed, confirm them with the token of the remove preview", 428)
+			return
+		}
+	}
+	for _, path := range paths {
 		err := fs.Remove(c.Request.Context(), path)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
@@ -1,6 +1,7 @@
 package handles
 
 import (
+	"context"
 	"fmt"
 	stdpath "path"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 type ListReq struct {
@@ -44,6 +46,8 @@ type ObjResp struct {
 	HashInfoStr  string                     `json:"hashinfo"`
 	HashInfo     map[*utils.HashType]string `json:"hash_info"`
 	MountDetails *model.StorageDetails      `json:"mount_details,omitempty"`
+	Starred      bool                       `json:"starred,omitempty"`
+	Tags         []string                   `json:"tags,omitempty"`
 }
 
 type FsListResp struct {
@@ -51,6 +55,7 @@ type FsListResp struct {
 	Total              int64     `json:"total"`
 	Readme             string    `json:"readme"`
 	Header             string    `json:"header"`
+	Footer             string    `json:"footer"`
 	Write              bool      `json:"write"`
 	WriteContentBypass bool      `json:"write_content_bypass"`
 	Provider           string    `json:"provider"`
@@ -74,6 +79,10 @@ func FsListSplit(c *gin.Context) {
 		common.ErrorStrResp(c, "Guest user is disabled, login please", 401)
 		return
 	}
+	if isTagsPath(req.Path) {
+		TagsList(c, &req, user)
+		return
+	}
 	FsList(c, &req, user)
 }
 
@@ -114,11 +123,15 @@ func FsList(c *gin.Context, req *ListReq, user *model.User) {
 			directUploadTools = op.GetDirectUploadTools(storage)
 		}
 	}
+	content := toObjsResp(objs, reqPath, isEncrypt(meta, reqPath))
+	markStarred(user, reqPath, content)
+	markTags(reqPath, content)
 	common.SuccessResp(c, FsListResp{
-		Content:            toObjsResp(objs, reqPath, isEncrypt(meta, reqPath)),
+		Content:            content,
 		Total:              int64(total),
 		Readme:             getReadme(meta, reqPath),
 		Header:             getHeader(meta, reqPath),
+		Footer:             getFooter(meta, reqPath),
 		Write:              common.CanWrite(user, meta, reqPath),
 		WriteContentBypass: common.CanWriteContentBypassUserPerms(meta, reqPath),
 		Provider:           provider,
@@ -198,17 +211,18 @@ func getHeader(meta *model.Meta, path string) string {
 	return ""
 }
 
+func getFooter(meta *model.Meta, path string) string {
+	if meta != nil && common.MetaCoversPath(meta.Path, path, meta.FooterSub) {
+		return meta.Footer
+	}
+	return ""
+}
+
 func isEncrypt(meta *model.Meta, path string) bool {
 	if common.IsStorageSignEnabled(path) {
 		return true
 	}
-	if meta == nil || meta.Password == "" {
-		return false
-	}
-	if !common.MetaCoversPath(meta.Path, path, meta.PSub) {
-		return false
-	}
-	return true
+	return common.PasswordMeta(meta, path) != nil
 }
 
 func pagination(objs []model.Obj, req *model.PageReq) (int, []model.Obj) {
@@ -257,6 +271,7 @@ type FsGetResp struct {
 	RawURL   string    `json:"raw_url"`
 	Readme   string    `json:"readme"`
 	Header   string    `json:"header"`
+	Footer   string    `json:"footer"`
 	Provider string    `json:"provider"`
 	Related  []ObjResp `json:"related"`
 }
@@ -277,6 +292,10 @@ func FsGetSplit(c *gin.Context) {
 		common.ErrorStrResp(c, "Guest user is disabled, login please", 401)
 		return
 	}
+	if isTagsPath(req.Path) {
+		TagsGet(c, &req, user)
+		return
+	}
 	FsGet(c, &req, user)
 }
 
@@ -347,6 +366,9 @@ func FsGet(c *gin.Context, req *FsGetReq, user *model.User) {
 			}
 		}
 	}
+	if !obj.IsDir() {
+		recordAccess(user, reqPath)
+	}
 	var related []model.Obj
 	parentPath := stdpath.Dir(reqPath)
 	sameLevelFiles, err := fs.List(c.Request.Context(), parentPath, &fs.ListArgs{})
@@ -354,6 +376,15 @@ func FsGet(c *gin.Context, req *FsGetReq, user *model.User) {
 		related = filterRelated(sameLevelFiles, obj)
 	}
 	parentMeta, _ := op.GetNearestMeta(parentPath)
+	tags, _ := op.GetTagNamesOfPaths([]string{reqPath})
+	if len(tags[reqPath]) == 0 && !obj.IsDir() && common.CanWrite(user, meta, reqPath) {
+		// the tags of the file moved outside OpenList are relinked in the background, shown from the next get
+		go func() {
+			if err := op.RelinkTags(context.WithoutCancel(c.Request.Context()), reqPath, obj); err != nil {
+				log.Errorf("failed relink the tags of [%s]: %+v", reqPath, err)
+			}
+		}()
+	}
 	thumb, _ := model.GetThumb(obj)
 	mountDetails, _ := model.GetStorageDetails(obj)
 	common.SuccessResp(c, FsGetResp{
@@ -369,10 +400,12 @@ func FsGet(c *gin.Context, req *FsGetReq, user *model.User) {
 			Type:         utils.GetFileType(obj.GetName()),
 			Thumb:        thumb,
 			MountDetails: mountDetails,
+			Tags:         tags[reqPath],
 		},
 		RawURL:   rawURL,
 		Readme:   getReadme(meta, reqPath),
 		Header:   getHeader(meta, reqPath),
+		Footer:   getFooter(meta, reqPath),
 		Provider: provider,
 		Related:  toObjsResp(related, parentPath, isEncrypt(parentMeta, parentPath)),
 	})
@@ -172,6 +172,67 @@ func TestGetHeader(t *testing.T) {
 	}
 }
 
+func TestGetFooter(t *testing.T) {
+	tests := []struct {
+		name   string
+		meta   *model.Meta
+		path   string
+		want   string
+		reason string
+	}{
+		{
+			name:   "nil meta",
+			meta:   nil,
+			path:   "/any",
+			want:   "",
+			reason: "nil meta should return empty",
+		},
+		{
+			name: "exact path match with FooterSub=false",
+			meta: &model.Meta{
+				Path:      "/folder",
+				Footer:    "Custom Footer",
+				FooterSub: false,
+			},
+			path:   "/folder",
+			want:   "Custom Footer",
+			reason: "exact path should show footer",
+		},
+		{
+			name: "sub path with FooterSub=true",
+			meta: &model.Meta{
+				Path:      "/folder",
+				Footer:    "Custom Footer",
+				FooterSub: true,
+			},
+			path:   "/folder/subfolder",
+			want:   "Custom Footer",
+			reason: "sub path with FooterSub=true should show footer",
+		},
+		{
+			name: "sub path with FooterSub=false",
+			meta: &model.Meta{
+				Path:      "/folder",
+				Footer:    "Custom Footer",
+				FooterSub: false,
+			},
+			path:   "/folder/subfolder",
+			want:   "",
+			reason: "sub path with FooterSub=false should not show footer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getFooter(tt.meta, tt.path)
+			if got != tt.want {
+				t.Errorf("getFooter() = %q, want %q\nReason: %s",
+					got, tt.want, tt.reason)
+			}
+		})
+	}
+}
+
 func TestIsEncrypt(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,196 @@
+package handles
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// the sync api lets the sync clients list the manifests of the directories page by page
+// and then poll the changes made since by a token, instead of walking the whole tree again
+
+type SyncManifestReq struct {
+	model.PageReq
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+	Refresh  bool   `json:"refresh" form:"refresh"`
+	// ETag is the etag of the manifest the client has, the entries aren't sent if it's unchanged
+	ETag string `json:"etag" form:"etag"`
+}
+
+// SyncEntry is an object of a directory, the files moved or renamed can be matched by their hashes
+type SyncEntry struct {
+	Name     string            `json:"name"`
+	IsDir    bool              `json:"is_dir"`
+	Size     int64             `json:"size"`
+	Modified time.Time         `json:"mtime"`
+	Hashes   map[string]string `json:"hashes,omitempty"`
+	// Sign is of the download link /d/path?sign= of the file
+	Sign string `json:"sign,omitempty"`
+}
+
+type SyncManifestResp struct {
+	Path string `json:"path"`
+	// Token is of the changes made since the listing, for the changes api
+	Token       string      `json:"token"`
+	ETag        string      `json:"etag"`
+	NotModified bool        `json:"not_modified"`
+	Total       int         `json:"total"`
+	Content     []SyncEntry `json:"content"`
+}
+
+// syncAccess checks whether the user can access the path and returns its full path and meta
+func syncAccess(c *gin.Context, user *model.User, path, password string) (string, *model.Meta, bool) {
+	reqPath, err := user.JoinPath(path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return "", nil, false
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return "", nil, false
+	}
+	if !common.CanAccess(user, meta, reqPath, password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return "", nil, false
+	}
+	common.GinWithValue(c, conf.MetaKey, meta)
+	return reqPath, meta, true
+}
+
+func toSyncEntry(obj model.Obj, parent string, encrypt bool) SyncEntry {
+	e := SyncEntry{
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime().UTC(),
+		Sign:     common.Sign(obj, parent, encrypt),
+	}
+	if !obj.IsDir() {
+		for ht, v := range obj.GetHash().All() {
+			if v == "" {
+				continue
+			}
+			if e.Hashes == nil {
+				e.Hashes = make(map[string]string)
+			}
+			e.Hashes[ht.Name] = v
+		}
+	}
+	return e
+}
+
+// syncETag is the digest of the names, sizes and modified times of the entries sorted by name
+func syncETag(entries []SyncEntry) string {
+	h := sha1.New()
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(h, "%s\x00%t\x00%d\x00%d\n", e.Name, e.IsDir, e.Size, e.Modified.UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FsSyncManifest lists a page of the entries of a directory sorted by name
+func FsSyncManifest(c *gin.Context) {
+	var req SyncManifestReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	reqPath, meta, ok := syncAccess(c, user, req.Path, req.Password)
+	if !ok {
+		return
+	}
+	if req.Refresh && !user.CanWriteContent() {
+		common.ErrorStrResp(c, "Refresh without permission", 403)
+		return
+	}
+	// taken before listing, so the changes made meanwhile are polled again rather than missed
+	token := op.FsJournalToken()
+	objs, err := fs.List(c.Request.Context(), reqPath, &fs.ListArgs{NoLog: true, Refresh: req.Refresh})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	encrypt := isEncrypt(meta, reqPath)
+	entries := make([]SyncEntry, 0, len(objs))
+	for _, obj := range objs {
+		entries = append(entries, toSyncEntry(obj, reqPath, encrypt))
+	}
+	slices.SortFunc(entries, func(a, b SyncEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	resp := SyncManifestResp{
+		Path:  toUserPath(user, reqPath),
+		Token: token,
+		ETag:  syncETag(entries),
+		Total: len(entries),
+	}
+	if req.ETag != "" && req.ETag == resp.ETag {
+		resp.NotModified = true
+		common.SuccessResp(c, resp)
+		return
+	}
+	start, end := 0, len(entries)
+	if req.PerPage < len(entries) {
+		start = min((req.Page-1)*req.PerPage, len(entries))
+		end = min(start+req.PerPage, len(entries))
+	} else if req.Page > 1 {
+		start = end
+	}
+	resp.Content = entries[start:end]
+	common.SuccessResp(c, resp)
+}
+
+type SyncChangesReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+	Token    string `json:"token" form:"token" binding:"required"`
+	Limit    int    `json:"limit" form:"limit"`
+}
+
+type SyncChangesResp struct {
+	Changes []model.FsEvent `json:"changes"`
+	Token   string          `json:"token"`
+	// Reset is set if the changes since the token are lost, e.g. by a restart, the manifests have to be listed again
+	Reset bool `json:"reset"`
+}
+
+// FsSyncChanges returns the changes under the path made after the token which the user can see
+func FsSyncChanges(c *gin.Context) {
+	var req SyncChangesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 1000 {
+		req.Limit = 1000
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	reqPath, _, ok := syncAccess(c, user, req.Path, req.Password)
+	if !ok {
+		return
+	}
+	events, next, ok := op.FsChangesSince(req.Token, reqPath, req.Limit)
+	resp := SyncChangesResp{Changes: []model.FsEvent{}, Token: next, Reset: !ok}
+	for _, event := range events {
+		if canSeeFsEvent(user, req.Password, event) {
+			resp.Changes = append(resp.Changes, toUserFsEvent(user, event))
+		}
+	}
+	common.SuccessResp(c, resp)
+}
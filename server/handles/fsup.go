@@ -11,12 +11,14 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 func getLastModified(c *gin.Context) time.Time {
@@ -53,6 +55,9 @@ func FsStream(c *gin.Context) {
 	}
 	asTask := c.GetHeader("As-Task") == "true"
 	overwrite := c.GetHeader("Overwrite") != "false"
+	if asTask && (!setUploadTaskPriority(c) || !setUploadTaskSpeedLimit(c) || !checkTaskLimit(c, fs.UploadTaskManager, model.TaskTypeUpload, 1)) {
+		return
+	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
 	path, err = user.JoinPath(path)
 	if err != nil {
@@ -142,6 +147,9 @@ func FsForm(c *gin.Context) {
 	}
 	asTask := c.GetHeader("As-Task") == "true"
 	overwrite := c.GetHeader("Overwrite") != "false"
+	if asTask && (!setUploadTaskPriority(c) || !setUploadTaskSpeedLimit(c) || !checkTaskLimit(c, fs.UploadTaskManager, model.TaskTypeUpload, 1)) {
+		return
+	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
 	path, err = user.JoinPath(path)
 	if err != nil {
@@ -226,3 +234,100 @@ func FsForm(c *gin.Context) {
 		"task": getTaskInfo(t),
 	})
 }
+
+// setUploadTaskPriority sets the priority of the upload task from the Task-Priority header
+func setUploadTaskPriority(c *gin.Context) bool {
+	p := c.GetHeader("Task-Priority")
+	if p == "" {
+		return true
+	}
+	priority, err := strconv.Atoi(p)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return false
+	}
+	return setTaskPriority(c, priority)
+}
+
+// setUploadTaskSpeedLimit sets the speed limit of the upload task from the Task-Speed-Limit header
+func setUploadTaskSpeedLimit(c *gin.Context) bool {
+	l := c.GetHeader("Task-Speed-Limit")
+	if l == "" {
+		return true
+	}
+	limit, err := strconv.Atoi(l)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return false
+	}
+	return setTaskSpeedLimit(c, limit)
+}
+
+type PutByURLReq struct {
+	URL  string `json:"url" binding:"required"`
+	Path string `json:"path"` // the dir to put the file into
+	// Name defaults to the last element of the url path
+	Name       string `json:"name"`
+	Overwrite  bool   `json:"overwrite"`
+	Priority   int    `json:"priority"`
+	SpeedLimit int    `json:"speed_limit"`
+	URLHeaderReq
+}
+
+// FsPutByURL adds an upload task fetching the remote file of the url into the dir
+func FsPutByURL(c *gin.Context) {
+	var req PutByURLReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		common.ErrorStrResp(c, "invalid url", 400)
+		return
+	}
+	name := req.Name
+	if name == "" {
+		name = stdpath.Base(u.Path)
+	}
+	if err = checkRelativePath(name); err != nil {
+		common.ErrorStrResp(c, "invalid file name, set the name of the file", 400)
+		return
+	}
+	if shouldIgnoreSystemFile(name) {
+		common.ErrorStrResp(c, errs.IgnoredSystemFile.Error(), 403)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	dir, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(dir)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if (!user.CanWriteContent() && !common.CanWriteContentBypassUserPerms(meta, dir)) || !common.CanWrite(user, meta, dir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if !req.Overwrite {
+		if res, _ := fs.Get(c.Request.Context(), stdpath.Join(dir, name), &fs.GetArgs{NoLog: true}); res != nil {
+			common.ErrorStrResp(c, "file exists", 403)
+			return
+		}
+	}
+	if !setTaskPriority(c, req.Priority) || !setTaskSpeedLimit(c, req.SpeedLimit) || !checkTaskLimit(c, fs.UploadTaskManager, model.TaskTypeUpload, 1) {
+		return
+	}
+	t, err := fs.PutURLAsTask(c.Request.Context(), dir, name, u.String(), req.header())
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
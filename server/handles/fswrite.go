@@ -0,0 +1,71 @@
+package handles
+
+import (
+	"io"
+	stdpath "path"
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// writePath returns the path of the File-Path header and the size of the body, which has to be known
+func writePath(c *gin.Context) (string, int64, bool) {
+	path, ok := fileHeaderPath(c)
+	if !ok {
+		return "", 0, false
+	}
+	if shouldIgnoreSystemFile(stdpath.Base(path)) {
+		common.ErrorStrResp(c, errs.IgnoredSystemFile.Error(), 403)
+		return "", 0, false
+	}
+	if c.Request.ContentLength < 0 {
+		common.ErrorStrResp(c, "Content-Length is required", 411)
+		return "", 0, false
+	}
+	return path, c.Request.ContentLength, true
+}
+
+func discardBody(c *gin.Context) {
+	if n, _ := io.ReadFull(c.Request.Body, []byte{0}); n == 1 {
+		_, _ = utils.CopyWithBuffer(io.Discard, c.Request.Body)
+	}
+	_ = c.Request.Body.Close()
+}
+
+// FsAppend appends the body to the file of the File-Path header, the file is created if it doesn't exist
+func FsAppend(c *gin.Context) {
+	defer discardBody(c)
+	path, size, ok := writePath(c)
+	if !ok {
+		return
+	}
+	if err := fs.AppendPut(c.Request.Context(), path, c.Request.Body, size); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// FsWriteRange writes the body at the File-Offset header of the file of the File-Path header,
+// the file is created if it doesn't exist
+func FsWriteRange(c *gin.Context) {
+	defer discardBody(c)
+	offset, err := strconv.ParseInt(c.GetHeader("File-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		common.ErrorStrResp(c, "File-Offset is required to be a non-negative integer", 400)
+		return
+	}
+	path, size, ok := writePath(c)
+	if !ok {
+		return
+	}
+	if err := fs.WriteRange(c.Request.Context(), path, offset, c.Request.Body, size); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
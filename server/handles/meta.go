@@ -1,15 +1,17 @@
 package handles
 
 import (
-	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/dlclark/regexp2"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -38,9 +40,8 @@ func CreateMeta(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	r, err := validHide(req.Hide)
-	if err != nil {
-		common.ErrorStrResp(c, fmt.Sprintf("%s is illegal: %s", r, err.Error()), 400)
+	if _, err := req.HideRule(); err != nil {
+		common.ErrorResp(c, err, 400)
 		return
 	}
 	if err := op.CreateMeta(&req); err != nil {
@@ -56,9 +57,8 @@ func UpdateMeta(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	r, err := validHide(req.Hide)
-	if err != nil {
-		common.ErrorStrResp(c, fmt.Sprintf("%s is illegal: %s", r, err.Error()), 400)
+	if _, err := req.HideRule(); err != nil {
+		common.ErrorResp(c, err, 400)
 		return
 	}
 	if err := op.UpdateMeta(&req); err != nil {
@@ -107,3 +107,65 @@ func GetMeta(c *gin.Context) {
 	}
 	common.SuccessResp(c, meta)
 }
+
+type CheckMetaAccessReq struct {
+	Path     string `json:"path" form:"path" binding:"required"`
+	Username string `json:"username" form:"username"` // the guest if empty
+	Password string `json:"password" form:"password"`
+}
+
+type CheckMetaAccessResp struct {
+	Path         string `json:"path"` // joined with the base path of the user
+	Meta         string `json:"meta"` // path of the nearest meta
+	PasswordMeta string `json:"password_meta"`
+	NeedPassword bool   `json:"need_password"`
+	Readable     bool   `json:"readable"`
+	Writable     bool   `json:"writable"`
+	Accessible   bool   `json:"accessible"`
+}
+
+// CheckMetaAccess tests whether a path is accessible for a user with the metas,
+// which are enforced the same way by the api, webdav, ftp and s3
+func CheckMetaAccess(c *gin.Context) {
+	var req CheckMetaAccessReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	var user *model.User
+	var err error
+	if req.Username == "" {
+		user, err = op.GetGuest()
+	} else {
+		user, err = op.GetUserByName(req.Username)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := CheckMetaAccessResp{
+		Path:       reqPath,
+		Readable:   common.CanRead(user, meta, reqPath),
+		Writable:   common.CanWrite(user, meta, reqPath),
+		Accessible: common.CanAccess(user, meta, reqPath, req.Password),
+	}
+	if meta != nil {
+		resp.Meta = meta.Path
+	}
+	if pm := common.PasswordMeta(meta, reqPath); pm != nil {
+		resp.PasswordMeta = pm.Path
+		resp.NeedPassword = !user.CanAccessWithoutPassword() &&
+			!slices.Contains(pm.PExemptUsers, user.ID) && !slices.Contains(pm.PExemptRoles, user.Role)
+	}
+	common.SuccessResp(c, resp)
+}
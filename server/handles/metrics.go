@@ -0,0 +1,32 @@
+package handles
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var metricsHandler = sync.OnceValue(func() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(op.StorageTrafficCollectors()...)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+})
+
+// Metrics serves the prometheus metrics to the holders of the admin token, as a bearer token or as is
+func Metrics(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(setting.GetStr(conf.Token))) != 1 {
+		common.ErrorStrResp(c, "invalid token", 401)
+		return
+	}
+	metricsHandler().ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,119 @@
+package handles
+
+import (
+	"path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ObjectIndexReq struct {
+	model.ObjectIndexReq
+	Password string `json:"password"`
+}
+
+type ObjectIndexResp struct {
+	model.ObjectIndex
+	Type int `json:"type"`
+}
+
+// FsObjects sorts and filters the objects under the parent by the object index instead of listing the storages
+func FsObjects(c *gin.Context) {
+	var (
+		req ObjectIndexReq
+		err error
+	)
+	if err = c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !op.ObjectIndexEnabled() {
+		common.ErrorStrResp(c, "the object index is disabled", 403)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	req.Parent, err = user.JoinPath(req.Parent)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	for i := range req.Exts {
+		req.Exts[i] = strings.ToLower(strings.TrimPrefix(req.Exts[i], "."))
+	}
+	if err = req.Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	objs, total, err := op.GetObjectIndexes(req.ObjectIndexReq)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	content := make([]ObjectIndexResp, 0, len(objs))
+	for _, obj := range objs {
+		if !utils.IsSubPath(user.BasePath, obj.Parent) {
+			continue
+		}
+		meta, err := op.GetNearestMeta(obj.Parent)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			continue
+		}
+		if !common.CanAccess(user, meta, path.Join(obj.Parent, obj.Name), req.Password) {
+			continue
+		}
+		content = append(content, ObjectIndexResp{ObjectIndex: obj, Type: utils.GetObjType(obj.Name, obj.IsDir)})
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: content,
+		Total:   total,
+	})
+}
+
+type RebuildObjectIndexReq struct {
+	Paths []string `json:"paths"`
+}
+
+func RebuildObjectIndex(c *gin.Context) {
+	var req RebuildObjectIndexReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Paths) == 0 {
+		req.Paths = []string{"/"}
+	}
+	if err := fs.RebuildObjectIndex(req.Paths); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func StopObjectIndexRebuild(c *gin.Context) {
+	fs.StopObjectIndexRebuild()
+	common.SuccessResp(c)
+}
+
+func ObjectIndexProgress(c *gin.Context) {
+	common.SuccessResp(c, fs.GetObjectIndexProgress())
+}
+
+func ClearObjectIndex(c *gin.Context) {
+	if fs.GetObjectIndexProgress().Running {
+		common.ErrorStrResp(c, "the object index is being rebuilt", 400)
+		return
+	}
+	if err := op.ClearObjectIndexes(); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -1,7 +1,13 @@
 package handles
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"slices"
 	"strings"
+	"time"
 
 	_115 "github.com/OpenListTeam/OpenList/v4/drivers/115"
 	_115_open "github.com/OpenListTeam/OpenList/v4/drivers/115_open"
@@ -17,6 +23,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -116,6 +123,39 @@ func SetTransmission(c *gin.Context) {
 	common.SuccessResp(c, "ok")
 }
 
+type SetSABnzbdReq struct {
+	Url      string `json:"url" form:"url"`
+	ApiKey   string `json:"api_key" form:"api_key"`
+	Category string `json:"category" form:"category"`
+}
+
+func SetSABnzbd(c *gin.Context) {
+	var req SetSABnzbdReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	items := []model.SettingItem{
+		{Key: conf.SabnzbdUrl, Value: req.Url, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.SabnzbdApiKey, Value: req.ApiKey, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.SabnzbdCategory, Value: req.Category, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+	}
+	if err := op.SaveSettingItems(items); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	_tool, err := tool.Tools.Get("SABnzbd")
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if _, err := _tool.Init(); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, "ok")
+}
+
 type Set115Req struct {
 	TempDir string `json:"temp_dir" form:"temp_dir"`
 }
@@ -482,11 +522,43 @@ type AddOfflineDownloadReq struct {
 	Path         string   `json:"path"`
 	Tool         string   `json:"tool"`
 	DeletePolicy string   `json:"delete_policy"`
+	TempPolicy   string   `json:"temp_policy"`
+	TempPath     string   `json:"temp_path"`
+	URLHeaderReq
+	// Priority of the download tasks and their transfer tasks
+	Priority int `json:"priority"`
+	// SpeedLimit of each transfer task in KB/s, overriding the one of the task type
+	SpeedLimit int `json:"speed_limit"`
+}
+
+// URLHeaderReq is the custom header sent with the requests of the urls
+type URLHeaderReq struct {
+	Headers map[string]string `json:"headers"`
+	Cookie  string            `json:"cookie"`
+	Referer string            `json:"referer"`
+}
+
+// header returns the custom request header of the urls
+func (r *URLHeaderReq) header() http.Header {
+	header := http.Header{}
+	for k, v := range r.Headers {
+		header.Set(k, v)
+	}
+	if r.Cookie != "" {
+		header.Set("Cookie", r.Cookie)
+	}
+	if r.Referer != "" {
+		header.Set("Referer", r.Referer)
+	}
+	if len(header) == 0 {
+		return nil
+	}
+	return header
 }
 
 func AddOfflineDownload(c *gin.Context) {
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	if !user.CanAddOfflineDownloadTasks() {
+	if !user.CanAddOfflineDownloadTasks() || !user.HasFeature(model.FeatureOfflineDownload) {
 		common.ErrorStrResp(c, "permission denied", 403)
 		return
 	}
@@ -496,6 +568,9 @@ func AddOfflineDownload(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	if !setTaskPriority(c, req.Priority) || !setTaskSpeedLimit(c, req.SpeedLimit) {
+		return
+	}
 	reqPath, err := user.JoinPath(req.Path)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -510,6 +585,33 @@ func AddOfflineDownload(c *gin.Context) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
 		return
 	}
+	tempPath := ""
+	if tool.TempPolicy(req.TempPolicy) == tool.TempStorage {
+		tempPath, err = user.JoinPath(req.TempPath)
+		if err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+		tempMeta, err := op.GetNearestMeta(tempPath)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !common.CanWrite(user, tempMeta, tempPath) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	urls := 0
+	for _, url := range req.Urls {
+		if strings.TrimSpace(url) != "" {
+			urls++
+		}
+	}
+	if !checkTaskLimit(c, tool.DownloadTaskManager, model.TaskTypeOfflineDownload, urls) {
+		return
+	}
+	header := req.header()
 	var tasks []task.TaskExtensionInfo
 	for _, url := range req.Urls {
 		// Filter out empty lines and whitespace-only strings
@@ -523,6 +625,85 @@ func AddOfflineDownload(c *gin.Context) {
 			DstDirPath:   reqPath,
 			Tool:         req.Tool,
 			DeletePolicy: tool.DeletePolicy(req.DeletePolicy),
+			TempPolicy:   tool.TempPolicy(req.TempPolicy),
+			TempPath:     tempPath,
+			Header:       header,
+		})
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		if t != nil {
+			tasks = append(tasks, t)
+		}
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos(tasks),
+	})
+}
+
+// indexScrapeTimeout is how long to wait for walking a remote tree
+const indexScrapeTimeout = 5 * time.Minute
+
+type AddIndexDownloadReq struct {
+	Url          string `json:"url" binding:"required"`
+	Source       string `json:"source"`
+	Path         string `json:"path"`
+	Tool         string `json:"tool"`
+	DeletePolicy string `json:"delete_policy"`
+}
+
+// AddIndexDownload adds the offline download tasks of the files of a remote tree,
+// which are downloaded into the same folders under the path
+func AddIndexDownload(c *gin.Context) {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !user.CanAddOfflineDownloadTasks() || !user.HasFeature(model.FeatureOfflineDownload) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	var req AddIndexDownloadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanWrite(user, meta, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), indexScrapeTimeout)
+	defer cancel()
+	files, err := tool.ScrapeIndex(ctx, req.Url, tool.IndexSource(req.Source))
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	// the dirs come from the remote tree, none of them may lead out of the requested path
+	dstDirs := make([]string, len(files))
+	for i, file := range files {
+		dstDirs[i] = stdpath.Join(reqPath, file.Dir)
+		if slices.Contains(strings.Split(strings.ReplaceAll(file.Dir, "\\", "/"), "/"), "..") ||
+			!utils.IsSubPath(reqPath, dstDirs[i]) {
+			common.ErrorStrResp(c, fmt.Sprintf("invalid dir %s of %s", file.Dir, file.Url), 400)
+			return
+		}
+	}
+	var tasks []task.TaskExtensionInfo
+	for i, file := range files {
+		t, err := tool.AddURL(c, &tool.AddURLArgs{
+			URL:          file.Url,
+			DstDirPath:   dstDirs[i],
+			Tool:         req.Tool,
+			DeletePolicy: tool.DeletePolicy(req.DeletePolicy),
 		})
 		if err != nil {
 			common.ErrorResp(c, err, 500)
@@ -536,3 +717,38 @@ func AddOfflineDownload(c *gin.Context) {
 		"tasks": getTaskInfos(tasks),
 	})
 }
+
+// magnetResolveTimeout is how long to wait for the tool to fetch the metadata of a magnet link
+const magnetResolveTimeout = 2 * time.Minute
+
+type ResolveMagnetReq struct {
+	Url  string `json:"url" binding:"required"`
+	Tool string `json:"tool" binding:"required"`
+}
+
+// ResolveMagnet returns the name, size and files of a magnet link without downloading it,
+// so that the contents can be checked before choosing where to download it
+func ResolveMagnet(c *gin.Context) {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !user.CanAddOfflineDownloadTasks() || !user.HasFeature(model.FeatureOfflineDownload) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	var req ResolveMagnetReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), magnetResolveTimeout)
+	defer cancel()
+	info, err := tool.ResolveMagnet(ctx, req.Tool, strings.TrimSpace(req.Url))
+	if err != nil {
+		if errors.Is(err, errs.NotSupport) {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, info)
+}
@@ -0,0 +1,151 @@
+package handles
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type PasteTextReq struct {
+	Path string `form:"path"` // the dir to create the file in
+	// Name is a template like the paste_name_template setting, which it defaults to
+	Name  string `form:"name"`
+	Share bool   `form:"share"`
+	// ExpireHours is the hours until the share expires, 0 for never
+	ExpireHours int `form:"expire_hours"`
+}
+
+type PasteTextResp struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	ShareID  string `json:"share_id,omitempty"`
+	ShareURL string `json:"share_url,omitempty"`
+}
+
+// pasteName fills the placeholders of the name template: {date} as 20060102, {time} as 1504
+// and {ext}, which is json for the json pastes and txt for the others
+func pasteName(template, ext string, now time.Time) string {
+	return strings.NewReplacer("{date}", now.Format("20060102"), "{time}", now.Format("1504"), "{ext}", ext).Replace(template)
+}
+
+// freePasteName returns name, or name with a numbered suffix if a file named so exists in dir
+func freePasteName(c *gin.Context, dir, name string) (string, error) {
+	ext := stdpath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; i <= 100; i++ {
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		if res, _ := fs.Get(c.Request.Context(), stdpath.Join(dir, name), &fs.GetArgs{NoLog: true}); res == nil {
+			return name, nil
+		}
+	}
+	return "", errors.Errorf("too many files named like [%s]", name)
+}
+
+// FsPasteText creates a file of the raw text or json body in the dir, optionally shared
+func FsPasteText(c *gin.Context) {
+	var req PasteTextReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if req.Share && (!user.CanShare() || !user.HasFeature(model.FeatureShare)) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	dir, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(dir)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if (!user.CanWriteContent() && !common.CanWriteContentBypassUserPerms(meta, dir)) || !common.CanWrite(user, meta, dir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	maxSize := int64(setting.GetInt(conf.PasteMaxSize, 1024)) << 10
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSize+1))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if int64(len(body)) > maxSize {
+		common.ErrorStrResp(c, fmt.Sprintf("the paste exceeds the max size of %d bytes", maxSize), 413)
+		return
+	}
+	ext, mimetype := "txt", "text/plain; charset=utf-8"
+	if t, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type")); t == "application/json" {
+		if !json.Valid(body) {
+			common.ErrorStrResp(c, "invalid json", 400)
+			return
+		}
+		ext, mimetype = "json", "application/json"
+	}
+	template := req.Name
+	if template == "" {
+		template = setting.GetStr(conf.PasteNameTemplate, "paste-{date}-{time}.{ext}")
+	}
+	name := pasteName(template, ext, time.Now())
+	if err = checkRelativePath(name); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if name, err = freePasteName(c, dir, name); err != nil {
+		common.ErrorResp(c, err, 409)
+		return
+	}
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     int64(len(body)),
+			Modified: time.Now(),
+		},
+		Reader:   bytes.NewReader(body),
+		Mimetype: mimetype,
+	}
+	if err = fs.PutDirectly(c.Request.Context(), dir, s); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	path := stdpath.Join(dir, name)
+	resp := PasteTextResp{Path: toUserPath(user, path), Size: int64(len(body))}
+	if req.Share {
+		sharing := &model.Sharing{
+			SharingDB: &model.SharingDB{Remark: "paste " + name},
+			Files:     []string{path},
+			Creator:   user,
+		}
+		if req.ExpireHours > 0 {
+			expires := time.Now().Add(time.Duration(req.ExpireHours) * time.Hour)
+			sharing.Expires = &expires
+		}
+		if resp.ShareID, err = op.CreateSharing(sharing); err != nil {
+			common.ErrorResp(c, errors.WithMessage(err, "the file is created but failed share it"), 500)
+			return
+		}
+		resp.ShareURL = common.GetApiUrl(c.Request.Context()) + "/@s/" + resp.ShareID
+	}
+	common.SuccessResp(c, resp)
+}
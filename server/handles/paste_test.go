@@ -0,0 +1,24 @@
+package handles
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasteName(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		template string
+		ext      string
+		want     string
+	}{
+		{"paste-{date}-{time}.{ext}", "txt", "paste-20260304-0506.txt"},
+		{"notes.{ext}", "json", "notes.json"},
+		{"fixed.md", "txt", "fixed.md"},
+	}
+	for _, tt := range tests {
+		if got := pasteName(tt.template, tt.ext, now); got != tt.want {
+			t.Errorf("pasteName(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
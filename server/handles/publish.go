@@ -0,0 +1,29 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type PublishReq struct {
+	SrcPath string `json:"src_path" binding:"required"`
+	DstPath string `json:"dst_path" binding:"required"`
+}
+
+// Publish mirrors a directory into a static hosting target, e.g. a directory on an s3 storage
+func Publish(c *gin.Context) {
+	var req PublishReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	t, err := fs.Publish(c.Request.Context(), req.SrcPath, req.DstPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
@@ -0,0 +1,86 @@
+package handles
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/relay"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/sign"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// RelayLink returns the signed fetch instruction of a download to an edge node authenticated by the relay token,
+// the sign of the down proxy url is checked like the down proxies do
+func RelayLink(c *gin.Context) {
+	token := setting.GetStr(conf.RelayToken)
+	if token == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(token)) != 1 {
+		common.ErrorStrResp(c, "invalid relay token", 401)
+		return
+	}
+	var req relay.LinkReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	reqPath := utils.FixAndCleanPath(req.Path)
+	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if !storage.GetStorage().DisableProxySign {
+		if err = sign.Verify(reqPath, strings.TrimSuffix(req.Sign, "/")); err != nil {
+			common.ErrorResp(c, err, 401)
+			return
+		}
+	}
+	link, _, err := fs.Link(c.Request.Context(), reqPath, model.LinkArgs{
+		IP:       req.IP,
+		Header:   req.Header,
+		Redirect: true,
+	})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	defer link.Close()
+	header, ok := relayHeader(link.Header)
+	if link.URL == "" || !ok {
+		common.ErrorStrResp(c, "the links of the storage can't be relayed", 400)
+		return
+	}
+	expiration := relay.InstructionExpiration
+	if link.Expiration != nil && *link.Expiration < expiration {
+		expiration = *link.Expiration
+	}
+	inst := &relay.Instruction{
+		URL:     link.URL,
+		Header:  header,
+		Expires: time.Now().Add(expiration).Unix(),
+	}
+	inst.SignWith(token)
+	common.SuccessResp(c, inst)
+}
+
+// relayHeaders are the headers of a link which may be handed to the edge nodes
+var relayHeaders = []string{"User-Agent", "Referer", "Accept"}
+
+// relayHeader returns the headers of the link for the edge nodes, ok is false if the link needs any other header,
+// e.g. the authorization or the cookie of the provider, which mustn't leave the main instance
+func relayHeader(h http.Header) (header http.Header, ok bool) {
+	for k := range h {
+		if !slices.Contains(relayHeaders, http.CanonicalHeaderKey(k)) {
+			return nil, false
+		}
+	}
+	return h.Clone(), true
+}
@@ -0,0 +1,94 @@
+package handles
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListSchedules(c *gin.Context) {
+	schedules, err := op.GetSchedules()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, schedules)
+}
+
+func CreateSchedule(c *gin.Context) {
+	var req model.Schedule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ID = 0
+	req.LastRun = nil
+	req.LastError = ""
+	if err := op.CreateSchedule(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, req)
+}
+
+func UpdateSchedule(c *gin.Context) {
+	var req model.Schedule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.UpdateSchedule(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, req)
+}
+
+func DeleteSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteScheduleById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// RunSchedule adds the tasks of the schedule on demand, the run is recorded like the scheduled ones
+func RunSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	s, err := op.GetScheduleById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	tasks, runErr := fs.RunSchedule(c.Request.Context(), s)
+	now := time.Now()
+	s.LastRun, s.LastError = &now, ""
+	if runErr != nil {
+		s.LastError = runErr.Error()
+	}
+	if err = op.SaveScheduleResult(s); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if runErr != nil {
+		common.ErrorResp(c, runErr, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos(tasks),
+	})
+}
@@ -0,0 +1,65 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type ListScrubFilesReq struct {
+	model.PageReq
+	FailedOnly bool `json:"failed_only" form:"failed_only"`
+}
+
+func ListScrubFiles(c *gin.Context) {
+	var req ListScrubFilesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	files, total, err := op.GetScrubFiles(req.Page, req.PerPage, req.FailedOnly)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: files,
+		Total:   total,
+	})
+}
+
+// DeleteScrubFile stops scrubbing the file, e.g. after it's removed on purpose
+func DeleteScrubFile(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteScrubFileById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// RunScrub adds a task which re-verifies the given number of files on demand
+func RunScrub(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		common.ErrorStrResp(c, "invalid limit", 400)
+		return
+	}
+	t, err := fs.Scrub(c.Request.Context(), limit)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/search"
@@ -18,6 +19,8 @@ import (
 type SearchReq struct {
 	model.SearchReq
 	Password string `json:"password"`
+	// the results have to carry all the tags, they are searched from the tags instead of the index
+	Tags []string `json:"tags"`
 }
 
 type SearchResp struct {
@@ -44,6 +47,10 @@ func Search(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	if len(req.Tags) > 0 {
+		searchTagged(c, &req, user)
+		return
+	}
 	nodes, total, err := search.Search(c, req.SearchReq)
 	if err != nil {
 		common.ErrorResp(c, err, 500)
@@ -75,3 +82,58 @@ func nodeToSearchResp(node model.SearchNode) SearchResp {
 		Type:       utils.GetObjType(node.Name, node.IsDir),
 	}
 }
+
+// searchTagged searches the objects having all the tags by the keywords of their names
+func searchTagged(c *gin.Context, req *SearchReq, user *model.User) {
+	tags, err := op.GetTagged(req.Parent, req.Tags)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	keywords := strings.Fields(strings.ToLower(req.Keywords))
+	var nodes []model.SearchNode
+	for _, t := range tags {
+		if (req.Scope == 1 && !t.IsDir) || (req.Scope == 2 && t.IsDir) {
+			continue
+		}
+		name := path.Base(t.Path)
+		if !containsKeywords(name, keywords) {
+			continue
+		}
+		meta, err := op.GetNearestMeta(path.Dir(t.Path))
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			continue
+		}
+		if !common.CanAccess(user, meta, t.Path, req.Password) {
+			continue
+		}
+		nodes = append(nodes, model.SearchNode{
+			Parent: path.Dir(t.Path),
+			Name:   name,
+			IsDir:  t.IsDir,
+		})
+	}
+	total := len(nodes)
+	start := min((req.Page-1)*req.PerPage, total)
+	end := min(start+req.PerPage, total)
+	nodes = nodes[start:end]
+	for i := range nodes {
+		if obj, err := fs.Get(c.Request.Context(), path.Join(nodes[i].Parent, nodes[i].Name), &fs.GetArgs{NoLog: true}); err == nil {
+			nodes[i].Size = obj.GetSize()
+		}
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: utils.MustSliceConvert(nodes, nodeToSearchResp),
+		Total:   int64(total),
+	})
+}
+
+func containsKeywords(name string, keywords []string) bool {
+	name = strings.ToLower(name)
+	for _, keyword := range keywords {
+		if !strings.Contains(name, keyword) {
+			return false
+		}
+	}
+	return true
+}
@@ -231,7 +231,7 @@ func SharingDown(c *gin.Context) {
 			return
 		}
 		_ = countAccess(c.ClientIP(), s)
-		proxy(c, link, obj, storage.GetStorage().ProxyRange)
+		proxy(c, link, obj, storage.GetStorage().Proxy)
 	} else {
 		link, _, err := op.Link(c.Request.Context(), storage, actualPath, model.LinkArgs{
 			IP:       c.ClientIP(),
@@ -297,7 +297,7 @@ func SharingArchiveExtract(c *gin.Context) {
 			if dealErrorPage(c, err) {
 				return
 			}
-			proxy(c, link, obj, storage.GetStorage().ProxyRange)
+			proxy(c, link, obj, storage.GetStorage().Proxy)
 		} else {
 			args.Redirect = true
 			link, _, err := op.DriverExtract(c.Request.Context(), storage, actualPath, args)
@@ -439,7 +439,7 @@ func UpdateSharing(c *gin.Context) {
 		}
 	} else {
 		user = reqUser
-		if !user.CanShare() {
+		if !user.CanShare() || !user.HasFeature(model.FeatureShare) {
 			common.ErrorStrResp(c, "permission denied", 403)
 			return
 		}
@@ -503,7 +503,7 @@ func CreateSharing(c *gin.Context) {
 		}
 	} else {
 		user = reqUser
-		if !user.CanShare() || (!user.IsAdmin() && req.ID != "") {
+		if !user.CanShare() || !user.HasFeature(model.FeatureShare) || (!user.IsAdmin() && req.ID != "") {
 			common.ErrorStrResp(c, "permission denied", 403)
 			return
 		}
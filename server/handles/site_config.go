@@ -0,0 +1,33 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ExportSiteConfig(c *gin.Context) {
+	data, err := op.ExportSiteConfig()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="site_config.yaml"`)
+	c.Data(200, "application/yaml; charset=utf-8", data)
+}
+
+// ImportSiteConfig applies the yaml document in the body, the metas and shares missing from it
+// are deleted with prune=true
+func ImportSiteConfig(c *gin.Context) {
+	data, err := c.GetRawData()
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	res, err := op.ImportSiteConfig(data, c.Query("prune") == "true")
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, res)
+}
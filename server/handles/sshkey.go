@@ -3,6 +3,7 @@ package handles
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -12,8 +13,9 @@ import (
 )
 
 type SSHKeyAddReq struct {
-	Title string `json:"title" binding:"required"`
-	Key   string `json:"key" binding:"required"`
+	Title     string     `json:"title" binding:"required"`
+	Key       string     `json:"key" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 func AddMyPublicKey(c *gin.Context) {
@@ -22,6 +24,28 @@ func AddMyPublicKey(c *gin.Context) {
 		common.ErrorStrResp(c, "user invalid", 401)
 		return
 	}
+	addPublicKey(c, userObj)
+}
+
+// AddPublicKey adds an authorized public key for the SFTP login of the user of uid
+func AddPublicKey(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Query("uid"))
+	if err != nil {
+		common.ErrorStrResp(c, "user id format invalid", 400)
+		return
+	}
+	userObj, err := op.GetUserById(uint(userId))
+	if err != nil || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 404)
+		return
+	}
+	if !checkUserManageable(c, userObj) {
+		return
+	}
+	addPublicKey(c, userObj)
+}
+
+func addPublicKey(c *gin.Context, userObj *model.User) {
 	var req SSHKeyAddReq
 	if err := c.ShouldBind(&req); err != nil {
 		common.ErrorStrResp(c, "request invalid", 400)
@@ -31,10 +55,15 @@ func AddMyPublicKey(c *gin.Context) {
 		common.ErrorStrResp(c, "request invalid", 400)
 		return
 	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		common.ErrorStrResp(c, "expiry must be in the future", 400)
+		return
+	}
 	key := &model.SSHPublicKey{
-		Title:  req.Title,
-		KeyStr: strings.TrimSpace(req.Key),
-		UserId: userObj.ID,
+		Title:     req.Title,
+		KeyStr:    strings.TrimSpace(req.Key),
+		UserId:    userObj.ID,
+		ExpiresAt: req.ExpiresAt,
 	}
 	err, parsed := op.CreateSSHPublicKey(key)
 	if !parsed {
@@ -15,6 +15,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
@@ -148,20 +149,27 @@ func autoRegister(username, userID string, err error) (*model.User, error) {
 	if username == "" {
 		return nil, errors.New("cannot get username from SSO provider")
 	}
+	// the home folder is provisioned by the username, so the taken username is replaced before creating the user
+	if _, err := op.GetUserByName(username); err == nil {
+		username = username + "_" + userID
+	}
+	basePath := setting.GetStr(conf.SSODefaultDir)
 	user := &model.User{
 		ID:         0,
 		Username:   username,
 		Password:   random.String(16),
 		Permission: int32(setting.GetInt(conf.SSODefaultPermission, 0)),
-		BasePath:   setting.GetStr(conf.SSODefaultDir),
+		BasePath:   basePath,
 		Role:       0,
 		Disabled:   false,
 		SsoID:      userID,
 	}
-	if err = db.CreateUser(user); err != nil {
+	if err = op.CreateUser(user); err != nil {
 		if strings.HasPrefix(err.Error(), "UNIQUE constraint failed") && strings.HasSuffix(err.Error(), "username") {
+			// the base path was set to the home folder of the taken username
 			user.Username = user.Username + "_" + userID
-			if err = db.CreateUser(user); err != nil {
+			user.BasePath = basePath
+			if err = op.CreateUser(user); err != nil {
 				return nil, err
 			}
 		} else {
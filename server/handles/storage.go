@@ -3,7 +3,9 @@ package handles
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
@@ -90,12 +92,43 @@ func ListStorages(c *gin.Context) {
 	})
 }
 
+// checkStorageDelegable refuses the users delegated the storage scope the storages which would give them
+// the access of the admin, the ones on the files of the server, e.g. its config, and the ones referencing its secrets
+func checkStorageDelegable(c *gin.Context, storages ...model.Storage) bool {
+	current := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if current.IsAdmin() {
+		return true
+	}
+	for _, storage := range storages {
+		if op.GetDriverInfoMap()[storage.Driver].Config.LocalFS {
+			common.ErrorStrResp(c, fmt.Sprintf("only the admin can manage the %s storages", storage.Driver), 403)
+			return false
+		}
+		if op.HasSecretRefs(storage.Addition) {
+			common.ErrorStrResp(c, "only the admin can manage the storages referencing secrets", 403)
+			return false
+		}
+	}
+	return true
+}
+
 func CreateStorage(c *gin.Context) {
 	var req model.Storage
 	if err := c.ShouldBind(&req); err != nil {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	if r, err := validHide(req.HidePatterns); err != nil {
+		common.ErrorStrResp(c, fmt.Sprintf("%s is illegal: %s", r, err.Error()), 400)
+		return
+	}
+	if _, err := model.ParseProxyRules(req.ProxyRules); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !checkStorageDelegable(c, req) {
+		return
+	}
 	if id, err := op.CreateStorage(c.Request.Context(), req); err != nil {
 		common.ErrorWithDataResp(c, err, 500, gin.H{
 			"id": id,
@@ -107,19 +140,55 @@ func CreateStorage(c *gin.Context) {
 	}
 }
 
+// storageCtx pauses the tasks using the storage until it's reinitialized, instead of refusing the change,
+// if pause_tasks is set
+func storageCtx(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if c.Query("pause_tasks") == "true" {
+		ctx = context.WithValue(ctx, conf.PauseTasksKey, struct{}{})
+	}
+	return ctx
+}
+
+func storageErrorResp(c *gin.Context, err error) {
+	if errors.Is(err, errs.StorageInUse) {
+		common.ErrorResp(c, err, 409)
+		return
+	}
+	common.ErrorResp(c, err, 500, true)
+}
+
 func UpdateStorage(c *gin.Context) {
 	var req model.Storage
 	if err := c.ShouldBind(&req); err != nil {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.UpdateStorage(c.Request.Context(), req); err != nil {
+	if r, err := validHide(req.HidePatterns); err != nil {
+		common.ErrorStrResp(c, fmt.Sprintf("%s is illegal: %s", r, err.Error()), 400)
+		return
+	}
+	if _, err := model.ParseProxyRules(req.ProxyRules); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	old, err := db.GetStorageById(req.ID)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !checkStorageDelegable(c, *old, req) {
+		return
+	}
+	if err := op.UpdateStorage(storageCtx(c), req); err != nil {
+		storageErrorResp(c, err)
 	} else {
 		common.SuccessResp(c)
 	}
 }
 
+// DeleteStorage keeps the storage disabled and restorable for the grace period,
+// it's dropped at once if purge is set, the grace period is 0 or it's deleted already
 func DeleteStorage(c *gin.Context) {
 	idStr := c.Query("id")
 	id, err := strconv.Atoi(idStr)
@@ -127,7 +196,36 @@ func DeleteStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DeleteStorageById(c.Request.Context(), uint(id)); err != nil {
+	storage, err := db.GetStorageById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if names := op.GetStorageTasks(storage.MountPath); len(names) > 0 {
+		common.ErrorStrResp(c, fmt.Sprintf("the storage is used by %d running tasks: %s", len(names), strings.Join(names, "; ")), 409)
+		return
+	}
+	grace := setting.GetInt(conf.StorageDeleteGraceHours, 0)
+	if c.Query("purge") == "true" || grace <= 0 || storage.DeleteAt != nil {
+		err = op.DeleteStorageById(c.Request.Context(), uint(id))
+	} else {
+		err = op.DeleteStorageLater(c.Request.Context(), uint(id), time.Duration(grace)*time.Hour)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func RestoreStorage(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.RestoreStorage(c.Request.Context(), uint(id)); err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -141,8 +239,8 @@ func DisableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DisableStorage(c.Request.Context(), uint(id)); err != nil {
-		common.ErrorResp(c, err, 500, true)
+	if err := op.DisableStorage(storageCtx(c), uint(id)); err != nil {
+		storageErrorResp(c, err)
 		return
 	}
 	common.SuccessResp(c)
@@ -208,3 +306,38 @@ func LoadAllStorages(c *gin.Context) {
 	}(storages)
 	common.SuccessResp(c)
 }
+
+func ListStorageGroups(c *gin.Context) {
+	groups, err := db.GetStorageGroups()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, groups)
+}
+
+func EnableStorageGroup(c *gin.Context) {
+	group := c.Query("group")
+	if group == "" {
+		common.ErrorStrResp(c, "group is required", 400)
+		return
+	}
+	if err := op.EnableStorageGroup(c.Request.Context(), group); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DisableStorageGroup(c *gin.Context) {
+	group := c.Query("group")
+	if group == "" {
+		common.ErrorStrResp(c, "group is required", 400)
+		return
+	}
+	if err := op.DisableStorageGroup(storageCtx(c), group); err != nil {
+		storageErrorResp(c, err)
+		return
+	}
+	common.SuccessResp(c)
+}
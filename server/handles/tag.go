@@ -0,0 +1,306 @@
+package handles
+
+import (
+	stdpath "path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// tagsRoot is the virtual tree browsing the tagged objects, /@tags/<tag>/<name of the tagged object>/...
+const tagsRoot = "/@tags"
+
+type TagReq struct {
+	Path     string `json:"path" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password"`
+}
+
+type RenameTagReq struct {
+	Name    string `json:"name" binding:"required"`
+	NewName string `json:"new_name" binding:"required"`
+}
+
+// visibleTagPath returns whether the tagged path is visible to the user in the tree
+func visibleTagPath(user *model.User) func(path string) bool {
+	return func(path string) bool {
+		meta, err := op.GetNearestMeta(path)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return false
+		}
+		return common.CanAccess(user, meta, path, "")
+	}
+}
+
+// ListTags lists the tags of the objects visible to the user
+func ListTags(c *gin.Context) {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	counts, err := op.GetTagCounts(user.BasePath, visibleTagPath(user))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, counts)
+}
+
+func AddTag(c *gin.Context) {
+	var req TagReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) || !common.CanWrite(user, meta, reqPath) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	obj, err := fs.Get(c.Request.Context(), reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if err = op.AddTag(req.Name, reqPath, obj, user.ID); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func RemoveTag(c *gin.Context) {
+	var req TagReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) || !common.CanWrite(user, meta, reqPath) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	if err = op.RemoveTag(req.Name, reqPath); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func RenameTag(c *gin.Context) {
+	var req RenameTagReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.RenameTag(req.Name, req.NewName); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteTag(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		common.ErrorStrResp(c, "name is required", 400)
+		return
+	}
+	if err := op.DeleteTagByName(name); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// markTags sets the tags of objs under the parent path
+func markTags(parent string, objs []ObjResp) {
+	if len(objs) == 0 {
+		return
+	}
+	paths := make([]string, len(objs))
+	for i := range objs {
+		paths[i] = stdpath.Join(parent, objs[i].Name)
+	}
+	tags, err := op.GetTagNamesOfPaths(paths)
+	if err != nil || len(tags) == 0 {
+		return
+	}
+	for i := range objs {
+		objs[i].Tags = tags[paths[i]]
+	}
+}
+
+// isTagsPath reports whether the path is in the virtual tree of the tags
+func isTagsPath(path string) bool {
+	return path == tagsRoot || strings.HasPrefix(path, tagsRoot+"/")
+}
+
+type taggedEntry struct {
+	path string
+	obj  model.Obj
+}
+
+// taggedEntries returns the objects with the tag which the user can access, the first one wins
+// if the names of the tagged objects conflict
+func taggedEntries(c *gin.Context, user *model.User, tag string) ([]taggedEntry, error) {
+	tags, err := op.GetTagged(user.BasePath, []string{tag})
+	if err != nil {
+		return nil, err
+	}
+	var entries []taggedEntry
+	names := make(map[string]struct{})
+	for _, t := range tags {
+		name := stdpath.Base(t.Path)
+		if _, ok := names[name]; ok {
+			continue
+		}
+		meta, err := op.GetNearestMeta(t.Path)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			continue
+		}
+		if !common.CanAccess(user, meta, t.Path, "") {
+			continue
+		}
+		obj, err := fs.Get(c.Request.Context(), t.Path, &fs.GetArgs{NoLog: true})
+		if err != nil {
+			continue
+		}
+		names[name] = struct{}{}
+		entries = append(entries, taggedEntry{path: t.Path, obj: obj})
+	}
+	return entries, nil
+}
+
+// resolveTagsPath returns the path seen by the user of a path under a tagged object in the virtual tree,
+// empty for the root and the directories of the tags
+func resolveTagsPath(c *gin.Context, user *model.User, path string) (string, string, error) {
+	segs := strings.SplitN(strings.Trim(strings.TrimPrefix(path, tagsRoot), "/"), "/", 3)
+	if len(segs) < 2 {
+		return "", segs[0], nil
+	}
+	entries, err := taggedEntries(c, user, segs[0])
+	if err != nil {
+		return "", segs[0], err
+	}
+	for _, e := range entries {
+		if e.obj.GetName() != segs[1] {
+			continue
+		}
+		rest := ""
+		if len(segs) == 3 {
+			rest = segs[2]
+		}
+		return toUserPath(user, stdpath.Join(e.path, rest)), segs[0], nil
+	}
+	return "", segs[0], errs.ObjectNotFound
+}
+
+// TagsList lists the tags, the objects with a tag, or delegates to FsList under a tagged object
+func TagsList(c *gin.Context, req *ListReq, user *model.User) {
+	realPath, tag, err := resolveTagsPath(c, user, req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if realPath != "" {
+		req.Path = realPath
+		FsList(c, req, user)
+		return
+	}
+	var content []ObjResp
+	if tag == "" {
+		counts, err := op.GetTagCounts(user.BasePath, visibleTagPath(user))
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		for _, tc := range counts {
+			content = append(content, ObjResp{
+				Name:  tc.Name,
+				IsDir: true,
+				Type:  utils.GetObjType(tc.Name, true),
+			})
+		}
+	} else {
+		entries, err := taggedEntries(c, user, tag)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		for _, e := range entries {
+			parent := stdpath.Dir(e.path)
+			meta, _ := op.GetNearestMeta(parent)
+			resp := toObjsResp([]model.Obj{e.obj}, parent, isEncrypt(meta, parent))
+			markTags(parent, resp)
+			content = append(content, resp...)
+		}
+	}
+	total := len(content)
+	start := min((req.Page-1)*req.PerPage, total)
+	end := min(start+req.PerPage, total)
+	common.SuccessResp(c, FsListResp{
+		Content:  content[start:end],
+		Total:    int64(total),
+		Provider: "unknown",
+	})
+}
+
+// TagsGet gets the directories of the tags, or delegates to FsGet under a tagged object
+func TagsGet(c *gin.Context, req *FsGetReq, user *model.User) {
+	realPath, tag, err := resolveTagsPath(c, user, req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if realPath != "" {
+		req.Path = realPath
+		FsGet(c, req, user)
+		return
+	}
+	name := tag
+	if name == "" {
+		name = strings.TrimPrefix(tagsRoot, "/")
+	}
+	common.SuccessResp(c, FsGetResp{
+		ObjResp: ObjResp{
+			Name:  name,
+			IsDir: true,
+			Type:  utils.GetObjType(name, true),
+		},
+		Provider: "unknown",
+	})
+}
@@ -1,12 +1,17 @@
 package handles
 
 import (
+	"fmt"
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/task_group"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
@@ -28,12 +33,26 @@ type TaskInfo struct {
 	EndTime     *time.Time  `json:"end_time"`
 	TotalBytes  int64       `json:"total_bytes"`
 	Error       string      `json:"error"`
+	ErrorCode   string      `json:"error_code,omitempty"`
+	Priority    int         `json:"priority"`
+	SpeedLimit  int         `json:"speed_limit"`
+	Paused      bool        `json:"paused"`
+	// Speed is the bytes per second, the average one of the done tasks
+	TransferredBytes int64      `json:"transferred_bytes"`
+	Speed            float64    `json:"speed"`
+	ETA              *time.Time `json:"eta"`
+	// Rollback is the report of the rollback of a failed transactional move
+	Rollback *task_group.RollbackReport `json:"rollback,omitempty"`
 }
 
 func getTaskInfo[T task.TaskExtensionInfo](task T) TaskInfo {
-	errMsg := ""
+	errMsg, errCode := "", ""
 	if task.GetErr() != nil {
+		errCode = errs.Code(task.GetErr(), 500)
 		errMsg = task.GetErr().Error()
+		if id := task.GetRequestID(); id != "" {
+			errMsg += " (request id: " + id + ")"
+		}
 	}
 	progress := task.GetProgress()
 	// if progress is NaN, set it to 100
@@ -46,6 +65,12 @@ func getTaskInfo[T task.TaskExtensionInfo](task T) TaskInfo {
 		creatorName = task.GetCreator().Username
 		creatorRole = task.GetCreator().Role
 	}
+	var rollback *task_group.RollbackReport
+	if r, ok := any(task).(interface {
+		GetRollbackReport() *task_group.RollbackReport
+	}); ok {
+		rollback = r.GetRollbackReport()
+	}
 	return TaskInfo{
 		ID:          task.GetID(),
 		Name:        task.GetName(),
@@ -58,6 +83,15 @@ func getTaskInfo[T task.TaskExtensionInfo](task T) TaskInfo {
 		EndTime:     task.GetEndTime(),
 		TotalBytes:  task.GetTotalBytes(),
 		Error:       errMsg,
+		ErrorCode:   errCode,
+		Priority:    task.GetPriority(),
+		SpeedLimit:  task.GetSpeedLimit(),
+		Paused:      task.IsPaused(),
+
+		TransferredBytes: task.GetTransferredBytes(),
+		Speed:            task.GetSpeed(),
+		ETA:              task.GetETA(),
+		Rollback:         rollback,
 	}
 }
 
@@ -69,17 +103,20 @@ func argsContains[T comparable](v T, slice ...T) bool {
 	return utils.SliceContains(slice, v)
 }
 
-func getUserInfo(c *gin.Context) (bool, uint, bool) {
+// getUserInfo returns whether the user can access the tasks of all users,
+// view is true for listing the tasks rather than managing them
+func getUserInfo(c *gin.Context, view bool) (bool, uint, bool) {
 	if user, ok := c.Request.Context().Value(conf.UserKey).(*model.User); ok {
-		return user.IsAdmin(), user.ID, true
+		all := user.HasAdminScope(model.AdminScopeTask) || (view && user.HasFeature(model.FeatureSeeAllTasks))
+		return all, user.ID, true
 	} else {
 		return false, 0, false
 	}
 }
 
-func getTargetedHandler[T task.TaskExtensionInfo](manager task.Manager[T], callback func(c *gin.Context, task T)) gin.HandlerFunc {
+func getTargetedHandler[T task.TaskExtensionInfo](manager task.Manager[T], view bool, callback func(c *gin.Context, task T)) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		isAdmin, uid, ok := getUserInfo(c)
+		isAdmin, uid, ok := getUserInfo(c, view)
 		if !ok {
 			// if there is no bug, here is unreachable
 			common.ErrorStrResp(c, "user invalid", 401)
@@ -101,7 +138,7 @@ func getTargetedHandler[T task.TaskExtensionInfo](manager task.Manager[T], callb
 
 func getBatchHandler[T task.TaskExtensionInfo](manager task.Manager[T], callback func(task T)) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		isAdmin, uid, ok := getUserInfo(c)
+		isAdmin, uid, ok := getUserInfo(c, false)
 		if !ok {
 			common.ErrorStrResp(c, "user invalid", 401)
 			return
@@ -126,7 +163,7 @@ func getBatchHandler[T task.TaskExtensionInfo](manager task.Manager[T], callback
 
 func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manager[T]) {
 	g.GET("/undone", func(c *gin.Context) {
-		isAdmin, uid, ok := getUserInfo(c)
+		isAdmin, uid, ok := getUserInfo(c, true)
 		if !ok {
 			// if there is no bug, here is unreachable
 			common.ErrorStrResp(c, "user invalid", 401)
@@ -140,7 +177,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 		})))
 	})
 	g.GET("/done", func(c *gin.Context) {
-		isAdmin, uid, ok := getUserInfo(c)
+		isAdmin, uid, ok := getUserInfo(c, true)
 		if !ok {
 			// if there is no bug, here is unreachable
 			common.ErrorStrResp(c, "user invalid", 401)
@@ -151,18 +188,21 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 				argsContains(task.GetState(), tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)
 		})))
 	})
-	g.POST("/info", getTargetedHandler(manager, func(c *gin.Context, task T) {
+	g.POST("/info", getTargetedHandler(manager, true, func(c *gin.Context, task T) {
 		common.SuccessResp(c, getTaskInfo(task))
 	}))
-	g.POST("/cancel", getTargetedHandler(manager, func(c *gin.Context, task T) {
+	g.POST("/logs", getTargetedHandler(manager, true, func(c *gin.Context, task T) {
+		common.SuccessResp(c, task.GetLogs())
+	}))
+	g.POST("/cancel", getTargetedHandler(manager, false, func(c *gin.Context, task T) {
 		manager.Cancel(task.GetID())
 		common.SuccessResp(c)
 	}))
-	g.POST("/delete", getTargetedHandler(manager, func(c *gin.Context, task T) {
+	g.POST("/delete", getTargetedHandler(manager, false, func(c *gin.Context, task T) {
 		manager.Remove(task.GetID())
 		common.SuccessResp(c)
 	}))
-	g.POST("/retry", getTargetedHandler(manager, func(c *gin.Context, task T) {
+	g.POST("/retry", getTargetedHandler(manager, false, func(c *gin.Context, task T) {
 		manager.Retry(task.GetID())
 		common.SuccessResp(c)
 	}))
@@ -176,7 +216,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 		manager.Retry(task.GetID())
 	}))
 	g.POST("/clear_done", func(c *gin.Context) {
-		isAdmin, uid, ok := getUserInfo(c)
+		isAdmin, uid, ok := getUserInfo(c, false)
 		if !ok {
 			// if there is no bug, here is unreachable
 			common.ErrorStrResp(c, "user invalid", 401)
@@ -189,7 +229,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 		common.SuccessResp(c)
 	})
 	g.POST("/clear_succeeded", func(c *gin.Context) {
-		isAdmin, uid, ok := getUserInfo(c)
+		isAdmin, uid, ok := getUserInfo(c, false)
 		if !ok {
 			// if there is no bug, here is unreachable
 			common.ErrorStrResp(c, "user invalid", 401)
@@ -201,7 +241,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 		common.SuccessResp(c)
 	})
 	g.POST("/retry_failed", func(c *gin.Context) {
-		isAdmin, uid, ok := getUserInfo(c)
+		isAdmin, uid, ok := getUserInfo(c, false)
 		if !ok {
 			// if there is no bug, here is unreachable
 			common.ErrorStrResp(c, "user invalid", 401)
@@ -217,12 +257,188 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 	})
 }
 
+// priorityRoute is for the managers dispatching the tasks by priority
+func priorityRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manager[T]) {
+	g.POST("/set_priority", getTargetedHandler(manager, false, func(c *gin.Context, task T) {
+		if isAdmin, _, _ := getUserInfo(c, false); !isAdmin {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		priority, err := strconv.Atoi(c.Query("priority"))
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		task.SetPriority(priority)
+		common.SuccessResp(c)
+	}))
+}
+
+// speedLimitRoute is for the managers of the transfers limited by the speed limits of the task types
+func speedLimitRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manager[T]) {
+	g.POST("/set_speed_limit", getTargetedHandler(manager, false, func(c *gin.Context, task T) {
+		if isAdmin, _, _ := getUserInfo(c, false); !isAdmin {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		limit, err := strconv.Atoi(c.Query("speed_limit"))
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		task.SetSpeedLimit(limit)
+		common.SuccessResp(c)
+	}))
+}
+
+// pauseRoute is for the managers of the transfers which can be paused
+func pauseRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manager[T]) {
+	g.POST("/pause", getTargetedHandler(manager, false, func(c *gin.Context, task T) {
+		if !pauseTask(task) {
+			common.ErrorStrResp(c, "task can't be paused", 400)
+			return
+		}
+		common.SuccessResp(c)
+	}))
+	g.POST("/resume", getTargetedHandler(manager, false, func(c *gin.Context, task T) {
+		if !task.Resume() {
+			common.ErrorStrResp(c, "task is not paused", 400)
+			return
+		}
+		common.SuccessResp(c)
+	}))
+	g.POST("/pause_some", getBatchHandler(manager, func(task T) {
+		pauseTask(task)
+	}))
+	g.POST("/resume_some", getBatchHandler(manager, func(task T) {
+		task.Resume()
+	}))
+}
+
+// pauseTask pauses the task unless it's done
+func pauseTask[T task.TaskExtensionInfo](t T) bool {
+	if argsContains(t.GetState(), tache.StateCanceled, tache.StateFailed, tache.StateSucceeded) {
+		return false
+	}
+	return t.Pause()
+}
+
+// setTaskPriority sets the priority of the tasks created by the request, only the task admins may set it
+func setTaskPriority(c *gin.Context, priority int) bool {
+	if priority == 0 {
+		return true
+	}
+	if isAdmin, _, _ := getUserInfo(c, false); !isAdmin {
+		common.ErrorStrResp(c, "only the task admins can set the priority", 403)
+		return false
+	}
+	common.GinWithValue(c, conf.TaskPriorityKey, priority)
+	return true
+}
+
+// setTaskSpeedLimit sets the speed limit in KB/s of the tasks created by the request, overriding the one of
+// their type, only the task admins may set it
+func setTaskSpeedLimit(c *gin.Context, limit int) bool {
+	if limit == 0 {
+		return true
+	}
+	if isAdmin, _, _ := getUserInfo(c, false); !isAdmin {
+		common.ErrorStrResp(c, "only the task admins can set the speed limit", 403)
+		return false
+	}
+	common.GinWithValue(c, conf.TaskSpeedLimitKey, limit)
+	return true
+}
+
+// checkTaskLimit reports whether the user may add more tasks of the type, the tasks of the user
+// which are not done yet and the added ones are counted against the user_task_limits setting
+func checkTaskLimit[T task.TaskExtensionInfo](c *gin.Context, manager task.Manager[T], taskType string, adding int) bool {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	limit := user.TaskLimit(taskType)
+	if limit <= 0 {
+		return true
+	}
+	uid := user.ID
+	undone := len(manager.GetByCondition(func(task T) bool {
+		creator := task.GetCreator()
+		return creator != nil && creator.ID == uid &&
+			!argsContains(task.GetState(), tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)
+	}))
+	if undone+adding > limit {
+		common.ErrorStrResp(c, fmt.Sprintf("too many %s tasks, %d of at most %d are not done yet", taskType, undone, limit), 429)
+		return false
+	}
+	return true
+}
+
+// ListTaskArchives lists the archived tasks of all types, limited to the user's own tasks like the task lists
+func ListTaskArchives(c *gin.Context) {
+	listTaskRecords(c, &model.TaskArchiveReq{}, func(req *model.TaskArchiveReq, uid uint) (any, int64, error) {
+		return db.GetTaskArchives(*req, uid)
+	})
+}
+
+// ListTaskHistory lists the finished tasks of all types between the days, limited to the user's own tasks like the task lists
+func ListTaskHistory(c *gin.Context) {
+	listTaskRecords(c, &model.TaskHistoryReq{}, func(req *model.TaskHistoryReq, uid uint) (any, int64, error) {
+		return db.GetTaskHistories(*req, uid)
+	})
+}
+
+// listTaskRecords binds and validates the req, then responds the page of the task records got of the user,
+// or of all users for the admin
+func listTaskRecords[R interface{ Validate() error }](c *gin.Context, req R, get func(R, uint) (any, int64, error)) {
+	if err := c.ShouldBind(req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	isAdmin, uid, ok := getUserInfo(c, true)
+	if !ok {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	if isAdmin {
+		uid = 0
+	}
+	records, total, err := get(req, uid)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: records,
+		Total:   total,
+	})
+}
+
 func SetupTaskRoute(g *gin.RouterGroup) {
+	g.GET("/archive", ListTaskArchives)
+	g.GET("/history", ListTaskHistory)
 	taskRoute(g.Group("/upload"), fs.UploadTaskManager)
 	taskRoute(g.Group("/copy"), fs.CopyTaskManager)
 	taskRoute(g.Group("/move"), fs.MoveTaskManager)
 	taskRoute(g.Group("/offline_download"), tool.DownloadTaskManager)
 	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
+	priorityRoute(g.Group("/upload"), fs.UploadTaskManager)
+	priorityRoute(g.Group("/copy"), fs.CopyTaskManager)
+	priorityRoute(g.Group("/move"), fs.MoveTaskManager)
+	priorityRoute(g.Group("/offline_download"), tool.DownloadTaskManager)
+	priorityRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
+	speedLimitRoute(g.Group("/upload"), fs.UploadTaskManager)
+	speedLimitRoute(g.Group("/copy"), fs.CopyTaskManager)
+	speedLimitRoute(g.Group("/move"), fs.MoveTaskManager)
+	speedLimitRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
+	pauseRoute(g.Group("/upload"), fs.UploadTaskManager)
+	pauseRoute(g.Group("/copy"), fs.CopyTaskManager)
+	pauseRoute(g.Group("/move"), fs.MoveTaskManager)
+	pauseRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
 	taskRoute(g.Group("/decompress"), fs.ArchiveDownloadTaskManager)
 	taskRoute(g.Group("/decompress_upload"), fs.ArchiveContentUploadTaskManager)
+	taskRoute(g.Group("/publish"), fs.PublishTaskManager)
+	taskRoute(g.Group("/ingest"), fs.IngestTaskManager)
+	taskRoute(g.Group("/scrub"), fs.ScrubTaskManager)
 }
@@ -0,0 +1,21 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListTransfers lists the in-flight proxied downloads and uploads with their speed
+func ListTransfers(c *gin.Context) {
+	common.SuccessResp(c, stream.GetTransfers())
+}
+
+// KillTransfer aborts an in-flight download or upload
+func KillTransfer(c *gin.Context) {
+	if !stream.KillTransfer(c.Query("id")) {
+		common.ErrorStrResp(c, "transfer not found", 404)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,64 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func UploadStats(c *gin.Context) {
+	var req model.UploadStatsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	items, err := op.GetUploadStats(req)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, items)
+}
+
+type MyUsageReq struct {
+	From string `form:"from"` // 2006-01-02, inclusive
+	To   string `form:"to"`   // 2006-01-02, inclusive
+}
+
+func MyUsage(c *gin.Context) {
+	var req MyUsageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	// validate the days the same way as the admin report
+	if err := (&model.UploadStatsReq{From: req.From, To: req.To}).Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	history, err := op.GetUserUploadHistory(user.ID, req.From, req.To)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	usage := model.UserUsage{
+		Quota:     user.Quota,
+		QuotaPath: user.QuotaPath,
+		History:   history,
+	}
+	if user.Quota > 0 {
+		if usage.Used, err = fs.QuotaUsage(c.Request.Context(), user); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c, usage)
+}
@@ -3,6 +3,7 @@ package handles
 import (
 	"strconv"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
@@ -29,6 +30,34 @@ func ListUsers(c *gin.Context) {
 	})
 }
 
+// checkAdminScopes refuses the users delegated the user scope to grant the scopes they don't have
+func checkAdminScopes(c *gin.Context, scopes int32) bool {
+	current := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if current.IsAdmin() || scopes&^current.AdminScopes == 0 {
+		return true
+	}
+	common.ErrorStrResp(c, "can not grant the admin scopes you don't have", 403)
+	return false
+}
+
+// checkUserManageable refuses the users delegated the user scope to manage the admin
+// and the users delegated the scopes they don't have
+func checkUserManageable(c *gin.Context, user *model.User) bool {
+	current := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if current.IsAdmin() {
+		return true
+	}
+	if user.IsAdmin() {
+		common.ErrorStrResp(c, "only the admin can manage the admin", 403)
+		return false
+	}
+	if user.AdminScopes&^current.AdminScopes != 0 {
+		common.ErrorStrResp(c, "can not manage the users delegated the admin scopes you don't have", 403)
+		return false
+	}
+	return true
+}
+
 func CreateUser(c *gin.Context) {
 	var req model.User
 	if err := c.ShouldBind(&req); err != nil {
@@ -39,6 +68,9 @@ func CreateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "admin or guest user can not be created", 400, true)
 		return
 	}
+	if !checkAdminScopes(c, req.AdminScopes) {
+		return
+	}
 	req.SetPassword(req.Password)
 	req.Password = ""
 	req.Authn = "[]"
@@ -64,6 +96,9 @@ func UpdateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "role can not be changed", 400)
 		return
 	}
+	if !checkUserManageable(c, user) || !checkAdminScopes(c, req.AdminScopes) {
+		return
+	}
 	if req.Password == "" {
 		req.PwdHash = user.PwdHash
 		req.Salt = user.Salt
@@ -92,6 +127,9 @@ func DeleteUser(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	if user, err := op.GetUserById(uint(id)); err == nil && !checkUserManageable(c, user) {
+		return
+	}
 	if err := op.DeleteUserById(uint(id)); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -121,6 +159,9 @@ func Cancel2FAById(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	if user, err := op.GetUserById(uint(id)); err == nil && !checkUserManageable(c, user) {
+		return
+	}
 	if err := op.Cancel2FAById(uint(id)); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
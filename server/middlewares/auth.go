@@ -75,6 +75,41 @@ func Auth(allowDisabledGuest bool) func(c *gin.Context) {
 	}
 }
 
+// DownAuth sets the user of the token for the downloads, which are allowed without it by their sign,
+// so an invalid token is ignored instead of refused
+func DownAuth(c *gin.Context) {
+	token := c.GetHeader("Authorization")
+	if token == "" {
+		c.Next()
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(setting.GetStr(conf.Token))) == 1 {
+		if admin, err := op.GetAdmin(); err == nil {
+			common.GinWithValue(c, conf.UserKey, admin)
+		}
+		c.Next()
+		return
+	}
+	if userClaims, err := common.ParseToken(token); err == nil {
+		user, err := op.GetUserByName(userClaims.Username)
+		if err == nil && userClaims.PwdTS == user.PwdTS && !user.Disabled {
+			common.GinWithValue(c, conf.UserKey, user)
+		}
+	}
+	c.Next()
+}
+
+// QueryToken takes the token from the query for the clients unable to set the header,
+// such as the EventSource and WebSocket of the browsers
+func QueryToken(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		if token := c.Query("token"); token != "" {
+			c.Request.Header.Set("Authorization", token)
+		}
+	}
+	c.Next()
+}
+
 func Authn(c *gin.Context) {
 	token := c.GetHeader("Authorization")
 	if subtle.ConstantTimeCompare([]byte(token), []byte(setting.GetStr(conf.Token))) == 1 {
@@ -139,6 +174,19 @@ func AuthNotGuest(c *gin.Context) {
 	}
 }
 
+// AuthAdminScope allows the admin and the users delegated the scope
+func AuthAdminScope(scope int32) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.Request.Context().Value(conf.UserKey).(*model.User)
+		if !user.HasAdminScope(scope) {
+			common.ErrorStrResp(c, "You are not allowed to manage this", 403)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 func AuthAdmin(c *gin.Context) {
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
 	if !user.IsAdmin() {
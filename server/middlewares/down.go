@@ -57,11 +57,5 @@ func needSign(meta *model.Meta, path string) bool {
 	if common.IsStorageSignEnabled(path) {
 		return true
 	}
-	if meta == nil || meta.Password == "" {
-		return false
-	}
-	if !meta.PSub && path != meta.Path {
-		return false
-	}
-	return true
+	return common.PasswordMeta(meta, path) != nil
 }
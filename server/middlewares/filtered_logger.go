@@ -93,7 +93,8 @@ func FilteredLogger() gin.HandlerFunc {
 	initFilterList()
 
 	return gin.LoggerWithConfig(gin.LoggerConfig{
-		Output: log.StandardLogger().Out,
-		Skip:   skiperDecider,
+		Output:    log.StandardLogger().Out,
+		Skip:      skiperDecider,
+		Formatter: LogFormatter,
 	})
 }
@@ -1,7 +1,13 @@
 package middlewares
 
 import (
+	"context"
 	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/gin-gonic/gin"
@@ -18,17 +24,38 @@ func MaxAllowed(n int) gin.HandlerFunc {
 	}
 }
 
+// UploadRateLimiter applies the total upload speed limit,
+// and tracks the PUT and POST requests as transfers which can be listed and killed by the admin
 func UploadRateLimiter(limiter stream.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var ctx context.Context = c
+		if c.Request.Method == http.MethodPut || c.Request.Method == http.MethodPost {
+			path := c.Request.URL.Path
+			if p, err := url.PathUnescape(c.GetHeader("File-Path")); err == nil && p != "" {
+				path = p
+			}
+			var t *stream.Transfer
+			ctx, t = stream.TrackTransfer(c.Request.Context(), stream.TransferUpload, path, transferUser(c), c.ClientIP())
+			defer t.Done()
+			c.Request = c.Request.WithContext(ctx)
+			c.Request.Body = t.Reader(ctx, c.Request.Body)
+		}
 		c.Request.Body = &stream.RateLimitReader{
 			Reader:  c.Request.Body,
 			Limiter: limiter,
-			Ctx:     c,
+			Ctx:     ctx,
 		}
 		c.Next()
 	}
 }
 
+func transferUser(c *gin.Context) string {
+	if user, ok := c.Request.Context().Value(conf.UserKey).(*model.User); ok && user != nil {
+		return user.Username
+	}
+	return ""
+}
+
 type ResponseWriterWrapper struct {
 	gin.ResponseWriter
 	WrapWriter io.Writer
@@ -38,14 +65,23 @@ func (w *ResponseWriterWrapper) Write(p []byte) (n int, err error) {
 	return w.WrapWriter.Write(p)
 }
 
+// DownloadRateLimiter applies the total and the per connection download speed limits,
+// and tracks the GET requests as transfers which can be listed and killed by the admin
 func DownloadRateLimiter(limiter stream.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var w io.Writer = c.Writer
+		if c.Request.Method == http.MethodGet {
+			ctx, d := stream.TrackTransfer(c.Request.Context(), stream.TransferDownload, c.Request.URL.Path, transferUser(c), c.ClientIP())
+			defer d.Done()
+			c.Request = c.Request.WithContext(ctx)
+			w = d.Writer(ctx, w)
+		}
 		c.Writer = &ResponseWriterWrapper{
 			ResponseWriter: c.Writer,
 			WrapWriter: &stream.RateLimitWriter{
-				Writer:  c.Writer,
+				Writer:  w,
 				Limiter: limiter,
-				Ctx:     c,
+				Ctx:     c.Request.Context(),
 			},
 		}
 		c.Next()
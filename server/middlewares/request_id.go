@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDKey is the key of the request id in the gin context, for the access log
+const requestIDKey = "request_id"
+
+// the ids of the reverse proxies in front are kept if they look harmless
+var requestIDReg = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID gives every request an id, which is returned in the X-Request-Id header,
+// written in the access log, sent with the upstream requests of the drivers and shown in the errors
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(net.RequestIDHeader)
+	if !requestIDReg.MatchString(id) {
+		id = random.String(16)
+	}
+	c.Set(requestIDKey, id)
+	c.Header(net.RequestIDHeader, id)
+	common.GinWithValue(c, conf.RequestIDKey, id)
+	c.Next()
+}
+
+// LogFormatter is the default log format of gin with the request id
+func LogFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	id, _ := param.Keys[requestIDKey].(string)
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s | %16s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		id,
+		methodColor, param.Method, resetColor,
+		param.Path,
+		param.ErrorMessage,
+	)
+}
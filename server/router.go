@@ -4,6 +4,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/cmd/flags"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/message"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
@@ -34,6 +35,7 @@ func Init(e *gin.Engine) {
 	g.GET("/robots.txt", handles.Robots)
 	g.GET("/manifest.json", static.ManifestJSON)
 	g.GET("/i/:link_name", handles.Plist)
+	g.GET("/metrics", handles.Metrics)
 	common.SecretKey = []byte(conf.Conf.JwtSecret)
 	g.Use(middlewares.StoragesLoaded)
 	if conf.Conf.MaxConnections > 0 {
@@ -44,10 +46,10 @@ func Init(e *gin.Engine) {
 
 	downloadLimiter := middlewares.DownloadRateLimiter(stream.ClientDownloadLimit)
 	signCheck := middlewares.Down(sign.Verify)
-	g.GET("/d/*path", middlewares.PathParse, signCheck, downloadLimiter, handles.Down)
-	g.GET("/p/*path", middlewares.PathParse, signCheck, downloadLimiter, handles.Proxy)
-	g.HEAD("/d/*path", middlewares.PathParse, signCheck, handles.Down)
-	g.HEAD("/p/*path", middlewares.PathParse, signCheck, handles.Proxy)
+	g.GET("/d/*path", middlewares.PathParse, signCheck, middlewares.DownAuth, downloadLimiter, handles.ThumbVariant, handles.Down)
+	g.GET("/p/*path", middlewares.PathParse, signCheck, middlewares.DownAuth, downloadLimiter, handles.ThumbVariant, handles.Proxy)
+	g.HEAD("/d/*path", middlewares.PathParse, signCheck, handles.ThumbVariant, handles.Down)
+	g.HEAD("/p/*path", middlewares.PathParse, signCheck, handles.ThumbVariant, handles.Proxy)
 	archiveSignCheck := middlewares.Down(sign.VerifyArchive)
 	g.GET("/ad/*path", middlewares.PathParse, archiveSignCheck, downloadLimiter, handles.ArchiveDown)
 	g.GET("/ap/*path", middlewares.PathParse, archiveSignCheck, downloadLimiter, handles.ArchiveProxy)
@@ -74,9 +76,22 @@ func Init(e *gin.Engine) {
 	api.POST("/auth/login/ldap", handles.LoginLdap)
 	auth.GET("/me", handles.CurrentUser)
 	auth.POST("/me/update", handles.UpdateCurrent)
+	auth.GET("/me/usage", handles.MyUsage)
+	auth.GET("/me/access_request/list", handles.ListMyAccessRequests)
+	auth.POST("/me/access_request/create", handles.CreateMyAccessRequest)
 	auth.GET("/me/sshkey/list", handles.ListMyPublicKey)
 	auth.POST("/me/sshkey/add", handles.AddMyPublicKey)
 	auth.POST("/me/sshkey/delete", handles.DeleteMyPublicKey)
+	auth.GET("/me/favorite/list", handles.ListMyFavorites)
+	auth.POST("/me/favorite/add", handles.AddMyFavorite)
+	auth.POST("/me/favorite/delete", handles.DeleteMyFavorite)
+	auth.GET("/me/recent", handles.ListMyRecent)
+	auth.GET("/me/recent/list", handles.ListMyRecent)
+	auth.POST("/me/recent/delete", handles.DeleteMyRecent)
+	auth.GET("/me/clipboard/get", handles.GetMyClipboard)
+	auth.POST("/me/clipboard/set", handles.SetMyClipboard)
+	auth.POST("/me/clipboard/clear", handles.ClearMyClipboard)
+	auth.POST("/me/clipboard/paste", handles.PasteMyClipboard)
 	auth.POST("/auth/2fa/generate", handles.Generate2FA)
 	auth.POST("/auth/2fa/verify", handles.Verify2FA)
 	auth.GET("/auth/logout", handles.LogOut)
@@ -100,12 +115,19 @@ func Init(e *gin.Engine) {
 	public.Any("/settings", handles.PublicSettings)
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
 	public.Any("/archive_extensions", handles.ArchiveExtensions)
+	// the edge nodes authenticate by the relay token
+	api.POST("/relay/link", handles.RelayLink)
+
+	api.GET("/announcements", middlewares.Auth(true), handles.ListActiveAnnouncements)
 
 	_fs(auth.Group("/fs"))
+	fsEvents := api.Group("/fs/events", middlewares.QueryToken, middlewares.Auth(false))
+	fsEvents.GET("", handles.FsEvents)
+	fsEvents.GET("/ws", handles.FsEventsWs)
 	fsAndShare(api.Group("/fs", middlewares.Auth(true)))
 	_task(auth.Group("/task", middlewares.AuthNotGuest))
 	_sharing(auth.Group("/share", middlewares.AuthNotGuest))
-	admin(auth.Group("/admin", middlewares.AuthAdmin))
+	admin(auth.Group("/admin"))
 	if flags.Debug || flags.Dev {
 		debug(g.Group("/debug"))
 	}
@@ -114,15 +136,9 @@ func Init(e *gin.Engine) {
 	})
 }
 
-func admin(g *gin.RouterGroup) {
-	meta := g.Group("/meta")
-	meta.GET("/list", handles.ListMetas)
-	meta.GET("/get", handles.GetMeta)
-	meta.POST("/create", handles.CreateMeta)
-	meta.POST("/update", handles.UpdateMeta)
-	meta.POST("/delete", handles.DeleteMeta)
-
-	user := g.Group("/user")
+func admin(adminGroup *gin.RouterGroup) {
+	// the api groups delegated to the users with the admin scopes
+	user := adminGroup.Group("/user", middlewares.AuthAdminScope(model.AdminScopeUser))
 	user.GET("/list", handles.ListUsers)
 	user.GET("/get", handles.GetUser)
 	user.POST("/create", handles.CreateUser)
@@ -131,23 +147,100 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/delete", handles.DeleteUser)
 	user.POST("/del_cache", handles.DelUserCache)
 	user.GET("/sshkey/list", handles.ListPublicKeys)
+	user.POST("/sshkey/add", handles.AddPublicKey)
 	user.POST("/sshkey/delete", handles.DeletePublicKey)
+	user.GET("/grant/list", handles.ListAccessGrants)
+	user.POST("/grant/create", handles.CreateAccessGrant)
+	user.POST("/grant/revoke", handles.RevokeAccessGrant)
 
-	storage := g.Group("/storage")
+	storage := adminGroup.Group("/storage", middlewares.AuthAdminScope(model.AdminScopeStorage))
 	storage.GET("/list", handles.ListStorages)
 	storage.GET("/get", handles.GetStorage)
 	storage.POST("/create", handles.CreateStorage)
 	storage.POST("/update", handles.UpdateStorage)
 	storage.POST("/delete", handles.DeleteStorage)
+	storage.POST("/restore", handles.RestoreStorage)
 	storage.POST("/enable", handles.EnableStorage)
 	storage.POST("/disable", handles.DisableStorage)
 	storage.POST("/load_all", handles.LoadAllStorages)
+	storage.GET("/group/list", handles.ListStorageGroups)
+	storage.POST("/group/enable", handles.EnableStorageGroup)
+	storage.POST("/group/disable", handles.DisableStorageGroup)
 
-	driver := g.Group("/driver")
+	driver := adminGroup.Group("/driver", middlewares.AuthAdminScope(model.AdminScopeStorage))
 	driver.GET("/list", handles.ListDriverInfo)
 	driver.GET("/names", handles.ListDriverNames)
 	driver.GET("/info", handles.GetDriverInfo)
 
+	// retain /admin/task API to ensure compatibility with legacy automation scripts
+	_task(adminGroup.Group("/task", middlewares.AuthAdminScope(model.AdminScopeTask)))
+
+	g := adminGroup.Group("", middlewares.AuthAdmin)
+	meta := g.Group("/meta")
+	meta.GET("/list", handles.ListMetas)
+	meta.GET("/get", handles.GetMeta)
+	meta.POST("/create", handles.CreateMeta)
+	meta.POST("/update", handles.UpdateMeta)
+	meta.POST("/delete", handles.DeleteMeta)
+	meta.POST("/check", handles.CheckMetaAccess)
+
+	accessRequest := g.Group("/access_request")
+	accessRequest.GET("/list", handles.ListAccessRequests)
+	accessRequest.POST("/approve", handles.ApproveAccessRequest)
+	accessRequest.POST("/deny", handles.DenyAccessRequest)
+
+	siteConfig := g.Group("/site_config")
+	siteConfig.GET("/export", handles.ExportSiteConfig)
+	siteConfig.POST("/import", handles.ImportSiteConfig)
+
+	indexExport := g.Group("/index_export")
+	indexExport.GET("/list", handles.ListIndexExports)
+	indexExport.POST("/create", handles.CreateIndexExport)
+	indexExport.POST("/update", handles.UpdateIndexExport)
+	indexExport.POST("/delete", handles.DeleteIndexExport)
+	indexExport.POST("/run", handles.RunIndexExport)
+	g.POST("/publish", handles.Publish)
+
+	ingestRule := g.Group("/ingest_rule")
+	ingestRule.GET("/list", handles.ListIngestRules)
+	ingestRule.POST("/create", handles.CreateIngestRule)
+	ingestRule.POST("/update", handles.UpdateIngestRule)
+	ingestRule.POST("/delete", handles.DeleteIngestRule)
+	ingestRule.POST("/run", handles.RunIngestRule)
+	ingestRule.POST("/test", handles.TestIngestRule)
+
+	schedule := g.Group("/schedules")
+	schedule.GET("/list", handles.ListSchedules)
+	schedule.POST("/create", handles.CreateSchedule)
+	schedule.POST("/update", handles.UpdateSchedule)
+	schedule.POST("/delete", handles.DeleteSchedule)
+	schedule.POST("/run", handles.RunSchedule)
+
+	tag := g.Group("/tag")
+	tag.POST("/rename", handles.RenameTag)
+	tag.POST("/delete", handles.DeleteTag)
+
+	scrub := g.Group("/scrub")
+	scrub.GET("/list", handles.ListScrubFiles)
+	scrub.POST("/delete", handles.DeleteScrubFile)
+	scrub.POST("/run", handles.RunScrub)
+
+	announcement := g.Group("/announcement")
+	announcement.GET("/list", handles.ListAnnouncements)
+	announcement.GET("/get", handles.GetAnnouncement)
+	announcement.POST("/create", handles.CreateAnnouncement)
+	announcement.POST("/update", handles.UpdateAnnouncement)
+	announcement.POST("/delete", handles.DeleteAnnouncement)
+
+	stats := g.Group("/stats")
+	stats.GET("/downloads", handles.DownloadStats)
+	stats.GET("/uploads", handles.UploadStats)
+	stats.GET("/storage_traffic", handles.StorageTraffic)
+	stats.GET("/link_cache", handles.LinkCacheStats)
+	g.GET("/transfers", handles.ListTransfers)
+	g.POST("/db/backup", handles.BackupDB)
+	g.POST("/transfers/kill", handles.KillTransfer)
+
 	setting := g.Group("/setting")
 	setting.GET("/get", handles.GetSetting)
 	setting.GET("/list", handles.ListSettings)
@@ -158,6 +251,7 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/set_aria2", handles.SetAria2)
 	setting.POST("/set_qbit", handles.SetQbittorrent)
 	setting.POST("/set_transmission", handles.SetTransmission)
+	setting.POST("/set_sabnzbd", handles.SetSABnzbd)
 	setting.POST("/set_115", handles.Set115)
 	setting.POST("/set_115_open", handles.Set115Open)
 	setting.POST("/set_123_pan", handles.Set123Pan)
@@ -167,9 +261,6 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/set_thunderx", handles.SetThunderX)
 	setting.POST("/set_thunder_browser", handles.SetThunderBrowser)
 
-	// retain /admin/task API to ensure compatibility with legacy automation scripts
-	_task(g.Group("/task"))
-
 	ms := g.Group("/message")
 	ms.POST("/get", message.HttpInstance.GetHandle)
 	ms.POST("/send", message.HttpInstance.SendHandle)
@@ -181,6 +272,12 @@ func admin(g *gin.RouterGroup) {
 	index.POST("/clear", middlewares.SearchIndex, handles.ClearIndex)
 	index.GET("/progress", middlewares.SearchIndex, handles.GetProgress)
 
+	objectIndex := g.Group("/object_index")
+	objectIndex.POST("/rebuild", handles.RebuildObjectIndex)
+	objectIndex.POST("/stop", handles.StopObjectIndexRebuild)
+	objectIndex.POST("/clear", handles.ClearObjectIndex)
+	objectIndex.GET("/progress", handles.ObjectIndexProgress)
+
 	scan := g.Group("/scan")
 	scan.POST("/start", handles.StartManualScan)
 	scan.POST("/stop", handles.StopManualScan)
@@ -197,8 +294,12 @@ func fsAndShare(g *gin.RouterGroup) {
 
 func _fs(g *gin.RouterGroup) {
 	g.Any("/search", middlewares.SearchIndex, handles.Search)
+	g.POST("/objects", handles.FsObjects)
 	g.Any("/other", handles.FsOther)
 	g.Any("/dirs", handles.FsDirs)
+	g.Any("/lsjson", handles.FsLsJSON)
+	g.Any("/sync/manifest", handles.FsSyncManifest)
+	g.Any("/sync/changes", handles.FsSyncChanges)
 	g.POST("/mkdir", handles.FsMkdir)
 	g.POST("/rename", handles.FsRename)
 	g.POST("/batch_rename", handles.FsBatchRename)
@@ -207,18 +308,30 @@ func _fs(g *gin.RouterGroup) {
 	g.POST("/recursive_move", handles.FsRecursiveMove)
 	g.POST("/copy", handles.FsCopy)
 	g.POST("/remove", handles.FsRemove)
+	g.POST("/remove/preview", handles.FsRemovePreview)
 	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
 	uploadLimiter := middlewares.UploadRateLimiter(stream.ClientUploadLimit)
 	g.PUT("/put", middlewares.FsUp, uploadLimiter, handles.FsStream)
 	g.PUT("/form", middlewares.FsUp, uploadLimiter, handles.FsForm)
+	g.POST("/delta/plan", middlewares.FsUp, handles.FsDeltaPlan)
+	g.PUT("/delta/apply", middlewares.FsUp, uploadLimiter, handles.FsDeltaApply)
+	g.PUT("/append", middlewares.FsUp, uploadLimiter, handles.FsAppend)
+	g.PUT("/write_range", middlewares.FsUp, uploadLimiter, handles.FsWriteRange)
+	g.POST("/put_by_url", handles.FsPutByURL)
+	g.POST("/paste_text", handles.FsPasteText)
 	g.POST("/link", middlewares.AuthAdmin, handles.Link)
 	// g.POST("/add_aria2", handles.AddOfflineDownload)
 	// g.POST("/add_qbit", handles.AddQbittorrent)
 	// g.POST("/add_transmission", handles.SetTransmission)
 	g.POST("/add_offline_download", handles.AddOfflineDownload)
+	g.POST("/resolve_magnet", handles.ResolveMagnet)
+	g.POST("/add_index_download", handles.AddIndexDownload)
 	g.POST("/archive/decompress", handles.FsArchiveDecompress)
 	// Direct upload (client-side upload to storage)
 	g.POST("/get_direct_upload_info", middlewares.FsUp, handles.FsGetDirectUploadInfo)
+	g.GET("/tag/list", handles.ListTags)
+	g.POST("/tag/add", handles.AddTag)
+	g.POST("/tag/remove", handles.RemoveTag)
 }
 
 func _task(g *gin.RouterGroup) {
@@ -43,6 +43,9 @@ func (b *s3Backend) entryListR(bucket, fdPath, name string, addPrefix bool, resp
 		if !strings.HasPrefix(object, name) {
 			continue
 		}
+		if isPasswordProtected(path.Join(fp, object)) {
+			continue
+		}
 
 		if entry.IsDir() {
 			if addPrefix {
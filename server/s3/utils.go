@@ -13,6 +13,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/itsHenry35/gofakes3"
 )
 
@@ -42,6 +43,13 @@ func getBucketByName(name string) (Bucket, error) {
 	return Bucket{}, gofakes3.BucketNotFound(name)
 }
 
+// isPasswordProtected reports whether a meta password applies to the path,
+// the s3 clients can't provide it so the path is not served
+func isPasswordProtected(path string) bool {
+	meta, _ := op.GetNearestMeta(path)
+	return common.PasswordMeta(meta, path) != nil
+}
+
 func getDirEntries(path string) ([]model.Obj, error) {
 	ctx := context.Background()
 	meta, _ := op.GetNearestMeta(path)
@@ -69,6 +69,7 @@ func (d *SftpDriver) GetFileSystem(sc *ssh.ServerConn) (sftpd.FileSystem, error)
 	}
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, conf.UserKey, userObj)
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolSFTP)
 	ctx = context.WithValue(ctx, conf.MetaPassKey, "")
 	ctx = context.WithValue(ctx, conf.ClientIPKey, sc.RemoteAddr().String())
 	ctx = context.WithValue(ctx, conf.ProxyHeaderKey, d.proxyHeader)
@@ -134,14 +135,18 @@ func (d *SftpDriver) PublicKeyAuth(conn ssh.ConnMetadata, key ssh.PublicKey) (*s
 		return nil, err
 	}
 	marshal := string(key.Marshal())
+	now := time.Now()
 	for _, sk := range keys {
+		if sk.Expired(now) {
+			continue
+		}
 		if marshal != sk.KeyStr {
 			pubKey, _, _, _, e := ssh.ParseAuthorizedKey([]byte(sk.KeyStr))
 			if e != nil || marshal != string(pubKey.Marshal()) {
 				continue
 			}
 		}
-		sk.LastUsedTime = time.Now()
+		sk.LastUsedTime = now
 		_ = op.UpdateSSHPublicKey(&sk)
 		return nil, nil
 	}
@@ -48,6 +48,7 @@ func ServeWebDAV(c *gin.Context) {
 }
 
 func WebDAVAuth(c *gin.Context) {
+	common.GinWithValue(c, conf.ProtocolKey, model.ProtocolWebdav)
 	// check count of login
 	ip := c.ClientIP()
 	guest, _ := op.GetGuest()
@@ -0,0 +1,209 @@
+package webdav
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/tache"
+)
+
+// taskFolder is the virtual folder in the root of the WebDAV tree,
+// it holds a read-only status file for each kind of task which lists the tasks of the user
+const taskFolder = "/.tasks"
+
+var taskStateNames = map[tache.State]string{
+	tache.StatePending:      "pending",
+	tache.StateRunning:      "running",
+	tache.StateSucceeded:    "succeeded",
+	tache.StateCanceling:    "canceling",
+	tache.StateCanceled:     "canceled",
+	tache.StateErrored:      "errored",
+	tache.StateFailing:      "failing",
+	tache.StateFailed:       "failed",
+	tache.StateWaitingRetry: "waiting retry",
+	tache.StateBeforeRetry:  "before retry",
+}
+
+type taskStatusFile struct {
+	name   string
+	status func(user *model.User) []byte
+}
+
+func taskStatusFiles() []taskStatusFile {
+	return []taskStatusFile{
+		{"upload.txt", taskStatus(fs.UploadTaskManager)},
+		{"copy.txt", taskStatus(fs.CopyTaskManager)},
+		{"move.txt", taskStatus(fs.MoveTaskManager)},
+		{"offline_download.txt", taskStatus(tool.DownloadTaskManager)},
+		{"offline_download_transfer.txt", taskStatus(tool.TransferTaskManager)},
+		{"decompress.txt", taskStatus(fs.ArchiveDownloadTaskManager)},
+		{"decompress_upload.txt", taskStatus(fs.ArchiveContentUploadTaskManager)},
+		{"publish.txt", taskStatus(fs.PublishTaskManager)},
+		{"ingest.txt", taskStatus(fs.IngestTaskManager)},
+		{"scrub.txt", taskStatus(fs.ScrubTaskManager)},
+	}
+}
+
+func taskStatus[T task.TaskExtensionInfo](manager task.Manager[T]) func(user *model.User) []byte {
+	return func(user *model.User) []byte {
+		isAdmin, uid := user.HasAdminScope(model.AdminScopeTask) || user.HasFeature(model.FeatureSeeAllTasks), user.ID
+		tasks := manager.GetByCondition(func(t T) bool {
+			return isAdmin || (t.GetCreator() != nil && t.GetCreator().ID == uid)
+		})
+		var buf bytes.Buffer
+		for _, t := range tasks {
+			progress := t.GetProgress()
+			if math.IsNaN(progress) {
+				progress = 100
+			}
+			fmt.Fprintf(&buf, "[%s] %s\n", t.GetID(), t.GetName())
+			fmt.Fprintf(&buf, "state: %s, progress: %.1f%%\n", taskStateNames[t.GetState()], progress)
+			if status := t.GetStatus(); status != "" {
+				fmt.Fprintf(&buf, "status: %s\n", status)
+			}
+			if err := t.GetErr(); err != nil {
+				fmt.Fprintf(&buf, "error: %s\n", err.Error())
+				if id := t.GetRequestID(); id != "" {
+					fmt.Fprintf(&buf, "request id: %s\n", id)
+				}
+			}
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes()
+	}
+}
+
+// taskFolderPath returns the path of the request relative to the WebDAV root
+// if it's in the task folder and the task folder is enabled
+func (h *Handler) taskFolderPath(r *http.Request) (string, bool) {
+	reqPath, _, err := h.stripPrefix(r.URL.Path)
+	if err != nil || !isTaskFolderPath(reqPath) {
+		return "", false
+	}
+	return path.Clean("/" + reqPath), true
+}
+
+func isTaskFolderPath(reqPath string) bool {
+	if !setting.GetBool(conf.WebdavTaskFolder) {
+		return false
+	}
+	reqPath = path.Clean("/" + reqPath)
+	return reqPath == taskFolder || strings.HasPrefix(reqPath, taskFolder+"/")
+}
+
+// taskFolderObj returns the object of reqPath in the task folder, and the content if it's a status file
+func taskFolderObj(user *model.User, reqPath string) (model.Obj, []byte, bool) {
+	now := time.Now()
+	if reqPath == taskFolder {
+		return &model.Object{Name: path.Base(taskFolder), Modified: now, IsFolder: true}, nil, true
+	}
+	for _, f := range taskStatusFiles() {
+		if path.Join(taskFolder, f.name) == reqPath {
+			content := f.status(user)
+			return &model.Object{Name: f.name, Size: int64(len(content)), Modified: now}, content, true
+		}
+	}
+	return nil, nil, false
+}
+
+// walkTaskFolder calls walkFn for the task folder at name and, unless depth is 0, its status files
+func walkTaskFolder(user *model.User, depth int, name string, walkFn func(reqPath string, info model.Obj, err error) error) error {
+	fi, _, _ := taskFolderObj(user, taskFolder)
+	if err := walkFn(name, fi, nil); err != nil || depth == 0 {
+		return err
+	}
+	for _, f := range taskStatusFiles() {
+		fi, _, _ := taskFolderObj(user, path.Join(taskFolder, f.name))
+		if err := walkFn(path.Join(name, f.name), fi, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleTaskFolder serves the read-only requests in the task folder
+func (h *Handler) handleTaskFolder(w http.ResponseWriter, r *http.Request, reqPath string) (status int, err error) {
+	ctx := r.Context()
+	user := ctx.Value(conf.UserKey).(*model.User)
+	fi, content, ok := taskFolderObj(user, reqPath)
+	if !ok {
+		if r.Method == "PUT" || r.Method == "MKCOL" || r.Method == "LOCK" {
+			return http.StatusForbidden, errs.PermissionDenied
+		}
+		return http.StatusNotFound, os.ErrNotExist
+	}
+	switch r.Method {
+	case "OPTIONS":
+		allow := "OPTIONS, PROPFIND"
+		if !fi.IsDir() {
+			allow = "OPTIONS, GET, HEAD, PROPFIND"
+		}
+		w.Header().Set("Allow", allow)
+		w.Header().Set("DAV", "1, 2")
+		w.Header().Set("MS-Author-Via", "DAV")
+		return 0, nil
+	case "GET", "HEAD":
+		if fi.IsDir() {
+			if r.Method == http.MethodHead {
+				w.Header().Set("Content-Type", "httpd/unix-directory")
+				w.Header().Set("Content-Length", "0")
+				return http.StatusOK, nil
+			}
+			return http.StatusMethodNotAllowed, nil
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+		return 0, nil
+	case "PROPFIND":
+		depth := infiniteDepth
+		if hdr := r.Header.Get("Depth"); hdr != "" {
+			depth = parseDepth(hdr)
+			if depth == invalidDepth {
+				return http.StatusBadRequest, errInvalidDepth
+			}
+		}
+		pf, status, err := readPropfind(r.Body)
+		if err != nil {
+			return status, err
+		}
+		name, err := user.JoinPath(reqPath)
+		if err != nil {
+			return http.StatusForbidden, err
+		}
+		mw := multistatusWriter{w: w}
+		walkFn := h.propfindWalkFn(ctx, user, pf, &mw)
+		var walkErr error
+		if fi.IsDir() {
+			walkErr = walkTaskFolder(user, depth, name, walkFn)
+		} else {
+			walkErr = walkFn(name, fi, nil)
+		}
+		closeErr := mw.close()
+		if walkErr != nil {
+			return http.StatusInternalServerError, walkErr
+		}
+		if closeErr != nil {
+			return http.StatusInternalServerError, closeErr
+		}
+		return 0, nil
+	}
+	return http.StatusForbidden, errs.PermissionDenied
+}
@@ -57,6 +57,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	useBufferedWriter := true
 	if h.LockSystem == nil {
 		status, err = http.StatusInternalServerError, errNoLockSystem
+	} else if reqPath, ok := h.taskFolderPath(r); ok {
+		status, err = h.handleTaskFolder(brw, r, reqPath)
 	} else {
 		switch r.Method {
 		case "OPTIONS":
@@ -236,7 +238,7 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 		return http.StatusInternalServerError, err
 	}
-	if !common.CanAccess(user, meta, reqPath, password) {
+	if !common.CanAccessVia(user, meta, reqPath, password, model.ProtocolWebdav) {
 		return http.StatusForbidden, errs.PermissionDenied
 	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
@@ -280,6 +282,7 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	if storage.GetStorage().ProxyRange {
 		link = common.ProxyRange(ctx, link, fi.GetSize())
 	}
+	link = common.ReadAhead(ctx, link, fi.GetSize(), storage.GetStorage().ReadAhead)
 	err = common.Proxy(w, r, link, fi)
 	if err != nil {
 		if statusCode, ok := errs.UnwrapOrSelf(err).(net.HttpStatusCodeError); ok {
@@ -507,6 +510,9 @@ func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request) (status
 	if dst == src {
 		return http.StatusForbidden, errDestinationEqualsSource
 	}
+	if isTaskFolderPath(dst) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 
 	ctx := r.Context()
 	user := ctx.Value(conf.UserKey).(*model.User)
@@ -715,6 +721,7 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	if err != nil {
 		return status, err
 	}
+	isRoot := path.Clean("/"+reqPath) == "/"
 	ctx := r.Context()
 	userAgent := r.Header.Get("User-Agent")
 	ctx = context.WithValue(ctx, conf.UserAgentKey, userAgent)
@@ -728,7 +735,7 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 		return http.StatusInternalServerError, err
 	}
-	if !common.CanAccess(user, meta, reqPath, password) {
+	if !common.CanAccessVia(user, meta, reqPath, password, model.ProtocolWebdav) {
 		return http.StatusForbidden, errs.PermissionDenied
 	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
@@ -751,8 +758,29 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	}
 
 	mw := multistatusWriter{w: w}
+	walkFn := h.propfindWalkFn(ctx, user, pf, &mw)
 
-	walkFn := func(reqPath string, info model.Obj, err error) error {
+	walkErr := walkFS(ctx, depth, reqPath, fi, walkFn)
+	if walkErr == nil && depth != 0 && isRoot && setting.GetBool(conf.WebdavTaskFolder) {
+		childDepth := depth
+		if depth == 1 {
+			childDepth = 0
+		}
+		walkErr = walkTaskFolder(user, childDepth, path.Join(reqPath, taskFolder), walkFn)
+	}
+	closeErr := mw.close()
+	if walkErr != nil {
+		return http.StatusInternalServerError, walkErr
+	}
+	if closeErr != nil {
+		return http.StatusInternalServerError, closeErr
+	}
+	return 0, nil
+}
+
+// propfindWalkFn returns the walkFn which writes the properties of every visited node of a PROPFIND into mw
+func (h *Handler) propfindWalkFn(ctx context.Context, user *model.User, pf propfind, mw *multistatusWriter) func(reqPath string, info model.Obj, err error) error {
+	return func(reqPath string, info model.Obj, err error) error {
 		if err != nil {
 			return err
 		}
@@ -781,16 +809,6 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 		}
 		return mw.write(makePropstatResponse(href, pstats))
 	}
-
-	walkErr := walkFS(ctx, depth, reqPath, fi, walkFn)
-	closeErr := mw.close()
-	if walkErr != nil {
-		return http.StatusInternalServerError, walkErr
-	}
-	if closeErr != nil {
-		return http.StatusInternalServerError, closeErr
-	}
-	return 0, nil
 }
 
 func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request) (status int, err error) {